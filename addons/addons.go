@@ -9,6 +9,7 @@ package addons
 
 import (
 	// Import all the addons to ensure they're ready to use.
+	_ "github.com/invopop/gobl/addons/be/peppol"
 	_ "github.com/invopop/gobl/addons/br/nfse"
 	_ "github.com/invopop/gobl/addons/co/dian"
 	_ "github.com/invopop/gobl/addons/de/xrechnung"
@@ -20,9 +21,14 @@ import (
 	_ "github.com/invopop/gobl/addons/fr/choruspro"
 	_ "github.com/invopop/gobl/addons/fr/facturx"
 	_ "github.com/invopop/gobl/addons/gr/mydata"
+	_ "github.com/invopop/gobl/addons/in/irp"
 	_ "github.com/invopop/gobl/addons/it/sdi"
 	_ "github.com/invopop/gobl/addons/it/ticket"
+	_ "github.com/invopop/gobl/addons/ke/etims"
 	_ "github.com/invopop/gobl/addons/mx/cfdi"
+	_ "github.com/invopop/gobl/addons/no/ehf"
 	_ "github.com/invopop/gobl/addons/pl/favat"
 	_ "github.com/invopop/gobl/addons/pt/saft"
+	_ "github.com/invopop/gobl/addons/ro/efactura"
+	_ "github.com/invopop/gobl/addons/sa/zatca"
 )
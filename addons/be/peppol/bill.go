@@ -0,0 +1,24 @@
+package peppol
+
+import (
+	"github.com/invopop/gobl/addons/internal/bis"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/validation"
+)
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Supplier,
+			validation.By(bis.ValidateParty),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.By(bis.ValidateParty),
+			validation.Skip,
+		),
+		validation.Field(&inv.Ordering,
+			validation.By(bis.ValidateOrdering),
+			validation.Skip,
+		),
+	)
+}
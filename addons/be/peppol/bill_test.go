@@ -0,0 +1,157 @@
+package peppol_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/be/peppol"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Addons:   tax.WithAddons(peppol.V3),
+		Currency: "EUR",
+		Series:   "2024",
+		Code:     "1000",
+		Ordering: &bill.Ordering{
+			Code: "PO-0042",
+		},
+		Supplier: &org.Party{
+			Name: "Leverancier BV",
+			TaxID: &tax.Identity{
+				Country: "BE",
+				Code:    "0897223868",
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Wetstraat 16",
+					Locality: "Brussel",
+					Code:     "1000",
+					Country:  "BE",
+				},
+			},
+		},
+		Customer: &org.Party{
+			Name: "FOD BOSA",
+			TaxID: &tax.Identity{
+				Country: "BE",
+			},
+			Identities: []*org.Identity{
+				{
+					Type: peppol.IdentityTypeKBO,
+					Code: "0897.223.868",
+				},
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Simon Bolivarlaan 30",
+					Locality: "Brussel",
+					Code:     "1000",
+					Country:  "BE",
+				},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+				Ref: "+++123/4567/89002+++",
+				CreditTransfer: []*pay.CreditTransfer{
+					{IBAN: "BE71096123456769"},
+				},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(10, 0),
+				Item: &org.Item{
+					Name:  "Consultancy",
+					Price: num.NewAmount(10000, 2),
+					Unit:  "h",
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceValidation(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "123456789002", inv.Payment.Instructions.Ref.String())
+		id := inv.Customer.Identities[0]
+		assert.Equal(t, "0897223868", id.Code.String())
+		assert.Equal(t, peppol.SchemeEnterpriseNumber, id.Ext[iso.ExtKeySchemeID])
+		assert.Equal(t, "0208:0897223868", inv.Supplier.Endpoint.String())
+		assert.Equal(t, "0208:0897223868", inv.Customer.Endpoint.String())
+	})
+
+	t.Run("foreign customer endpoint", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "NL", Code: "000099995B57"}
+		inv.Customer.Identities = nil
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "9944:NL000099995B57", inv.Customer.Endpoint.String())
+	})
+
+	t.Run("missing electronic address", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = nil
+		inv.Customer.Identities = nil
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "customer: electronic address required")
+
+		inv.Customer.Inboxes = []*org.Inbox{
+			{Key: org.InboxKeyPeppol, Code: "0088:5790000435951"},
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Empty(t, inv.Customer.Endpoint)
+	})
+
+	t.Run("missing buyer reference", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Ordering = nil
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "ordering: buyer reference code or purchase order required")
+
+		inv.Ordering = &bill.Ordering{
+			Purchases: []*org.DocumentRef{{Code: "PO-0042"}},
+		}
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("short enterprise number", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.Identities[0] = &org.Identity{
+			Type: peppol.IdentityTypeCBE,
+			Code: "897 223 868",
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "0897223868", inv.Customer.Identities[0].Code.String())
+	})
+
+	t.Run("invalid enterprise number", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.Identities[0].Code = "0897.223.869"
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "must be a valid KBO-BCE")
+	})
+}
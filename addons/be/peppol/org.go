@@ -0,0 +1,62 @@
+package peppol
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/invopop/gobl/addons/internal/bis"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Identity types commonly used for the Belgian enterprise number, from
+// the Crossroads Bank for Enterprises (CBE), known locally as the KBO
+// (Kruispuntbank van Ondernemingen) or BCE (Banque-Carrefour des
+// Entreprises).
+const (
+	IdentityTypeCBE cbc.Code = "CBE"
+	IdentityTypeKBO cbc.Code = "KBO"
+	IdentityTypeBCE cbc.Code = "BCE"
+)
+
+// SchemeEnterpriseNumber is the ISO 6523 scheme used for Belgian
+// enterprise numbers.
+const SchemeEnterpriseNumber cbc.Code = "0208"
+
+var nonDigitsRegexp = regexp.MustCompile(`\D`)
+
+// normalizeOrgIdentity cleans up enterprise numbers, which are often written
+// with dots such as "0897.223.868", and ensures they use the Peppol scheme.
+// Validation of the check digits is then performed by the ISO scheme.
+func normalizeOrgIdentity(id *org.Identity) {
+	if id == nil {
+		return
+	}
+	if !id.Type.In(IdentityTypeCBE, IdentityTypeKBO, IdentityTypeBCE) && id.Ext.Get(iso.ExtKeySchemeID) != SchemeEnterpriseNumber {
+		return
+	}
+	code := nonDigitsRegexp.ReplaceAllString(id.Code.String(), "")
+	if len(code) == 9 {
+		// older numbers were issued with 9 digits
+		code = fmt.Sprintf("0%s", code)
+	}
+	id.Code = cbc.Code(code)
+	id.Ext = id.Ext.Merge(tax.Extensions{
+		iso.ExtKeySchemeID: SchemeEnterpriseNumber,
+	})
+}
+
+// normalizeParty assigns a default endpoint to parties without an electronic
+// address, preferring the enterprise number.
+func normalizeParty(p *org.Party) {
+	if p == nil {
+		return
+	}
+	for _, id := range p.Identities {
+		normalizeOrgIdentity(id)
+	}
+	bis.NormalizeEndpoint(p, l10n.BE.Tax(), SchemeEnterpriseNumber)
+}
@@ -0,0 +1,82 @@
+package peppol
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/validation"
+)
+
+var (
+	// structured communications may be written as "+++123/4567/89002+++",
+	// "***123/4567/89002***", or just the digits. The delimiters will have
+	// already been removed during normalization of the code.
+	structuredRefNormRegexp = regexp.MustCompile(`^(\d{3})[/.\- ]?(\d{4})[/.\- ]?(\d{5})$`)
+	structuredRefRegexp     = regexp.MustCompile(`^\d{12}$`)
+)
+
+// StructuredReference generates the Belgian structured communication for
+// the base number provided, which must have up to 10 digits, by appending
+// the check digits.
+func StructuredReference(base uint64) (cbc.Code, error) {
+	if base > 9999999999 {
+		return cbc.CodeEmpty, errors.New("base number must have up to 10 digits")
+	}
+	return cbc.Code(fmt.Sprintf("%010d%02d", base, structuredRefCheck(base))), nil
+}
+
+// FormatStructuredReference provides the structured communication in the
+// format used on paper invoices and payment slips, "+++123/4567/89002+++".
+func FormatStructuredReference(code cbc.Code) string {
+	s := code.String()
+	if !structuredRefRegexp.MatchString(s) {
+		return s
+	}
+	return fmt.Sprintf("+++%s/%s/%s+++", s[:3], s[3:7], s[7:])
+}
+
+func structuredRefCheck(base uint64) uint64 {
+	check := base % 97
+	if check == 0 {
+		return 97
+	}
+	return check
+}
+
+func normalizePayInstructions(instr *pay.Instructions) {
+	if instr == nil {
+		return
+	}
+	instr.Ref = cbc.Code(structuredRefNormRegexp.ReplaceAllString(instr.Ref.String(), "$1$2$3"))
+}
+
+func validatePayInstructions(instr *pay.Instructions) error {
+	if instr == nil {
+		return nil
+	}
+	return validation.ValidateStruct(instr,
+		validation.Field(&instr.Ref,
+			validation.By(validateStructuredRef),
+		),
+	)
+}
+
+// validateStructuredRef checks the check digits of references that look like
+// a structured communication.
+func validateStructuredRef(value any) error {
+	code, _ := value.(cbc.Code)
+	s := code.String()
+	if !structuredRefRegexp.MatchString(s) {
+		return nil
+	}
+	base, _ := strconv.ParseUint(s[:10], 10, 64)  //nolint:errcheck
+	check, _ := strconv.ParseUint(s[10:], 10, 64) //nolint:errcheck
+	if structuredRefCheck(base) != check {
+		return errors.New("invalid structured communication check digits")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package peppol_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/be/peppol"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredReference(t *testing.T) {
+	code, err := peppol.StructuredReference(1234567890)
+	require.NoError(t, err)
+	assert.Equal(t, "123456789002", code.String())
+	assert.Equal(t, "+++123/4567/89002+++", peppol.FormatStructuredReference(code))
+
+	code, err = peppol.StructuredReference(97)
+	require.NoError(t, err)
+	assert.Equal(t, "000000009797", code.String())
+
+	_, err = peppol.StructuredReference(12345678901)
+	assert.ErrorContains(t, err, "base number must have up to 10 digits")
+
+	assert.Equal(t, "INV-1", peppol.FormatStructuredReference("INV-1"))
+}
+
+func TestPayInstructions(t *testing.T) {
+	ad := tax.AddonForKey(peppol.V3)
+	tests := []struct {
+		ref  cbc.Code
+		want cbc.Code
+		err  string
+	}{
+		{ref: "+++123/4567/89002+++", want: "123456789002"},
+		{ref: "***123/4567/89002***", want: "123456789002"},
+		{ref: "123456789002", want: "123456789002"},
+		{ref: "123/4567/89003", want: "123456789003", err: "ref: invalid structured communication check digits"},
+		{ref: "INV-2024-001", want: "INV-2024-001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref.String(), func(t *testing.T) {
+			instr := &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+				Ref: tt.ref,
+			}
+			instr.Normalize()
+			ad.Normalizer(instr)
+			assert.Equal(t, tt.want, instr.Ref)
+			err := ad.Validator(instr)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
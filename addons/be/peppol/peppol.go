@@ -0,0 +1,93 @@
+// Package peppol provides the Belgian national rules for Peppol BIS Billing
+// 3.0 invoices, as used by the Mercurius platform.
+package peppol
+
+import (
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V3 is the key for the Belgian Peppol BIS Billing 3.0 rules.
+	V3 cbc.Key = "be-peppol-v3"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V3, newAddon)
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V3,
+		Name: i18n.String{
+			i18n.EN: "Belgium Peppol BIS 3.0",
+			i18n.NL: "België Peppol BIS 3.0",
+			i18n.FR: "Belgique Peppol BIS 3.0",
+		},
+		Requires: []cbc.Key{
+			en16931.V2017,
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the Belgian rules applied to Peppol BIS Billing 3.0 invoices, as
+				required to send invoices to public entities through the Mercurius platform,
+				and to Belgian businesses through the Peppol network.
+
+				This addon builds on the EN 16931 addon and covers:
+
+				- Structured communication (OGM/VCS) payment references, normalized from the
+				  "+++123/4567/89002+++" format to 12 digits, with the check digits validated.
+				- Belgian enterprise numbers (KBO/BCE) in party identities, normalized and
+				  assigned the "0208" ISO 6523 scheme used in Peppol.
+				- Suppliers and customers must provide an electronic address. When missing,
+				  the party's endpoint is set from the enterprise number, or the VAT number
+				  for parties from other countries.
+				- Invoices must include a buyer or purchase order reference, used by public
+				  entities to route invoices internally.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "Peppol BIS Billing 3.0",
+				},
+				URL: "https://docs.peppol.eu/poacc/billing/3.0/",
+			},
+			{
+				Title: i18n.String{
+					i18n.EN: "Mercurius - e-Invoicing for Belgian public authorities",
+				},
+				URL: "https://bosa.belgium.be/en/services/mercurius",
+			},
+		},
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *org.Party:
+		normalizeParty(obj)
+	case *org.Identity:
+		normalizeOrgIdentity(obj)
+	case *pay.Instructions:
+		normalizePayInstructions(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	case *pay.Instructions:
+		return validatePayInstructions(obj)
+	}
+	return nil
+}
@@ -21,7 +21,7 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V2, newAddon)
 }
 
 func newAddon() *tax.AddonDef {
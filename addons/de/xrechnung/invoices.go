@@ -1,9 +1,12 @@
 package xrechnung
 
 import (
+	"errors"
+
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/catalogues/untdid"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/validation"
 )
@@ -28,6 +31,14 @@ func validateInvoice(inv *bill.Invoice) error {
 			validation.By(validateInvoiceTax),
 			validation.Skip,
 		),
+		validation.Field(&inv.Supplier,
+			validation.By(validateParty),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.By(validateParty),
+			validation.Skip,
+		),
 		validation.Field(&inv.Preceding,
 			validation.When(
 				inv.Type.In(
@@ -53,3 +64,18 @@ func validateInvoiceTax(value any) error {
 		),
 	)
 }
+
+// validateParty ensures the party provides an electronic address (BT-34,
+// BT-49), either as an endpoint, an inbox with a scheme, or an email inbox.
+func validateParty(value any) error {
+	p, _ := value.(*org.Party)
+	if p == nil || !p.ElectronicAddress().IsEmpty() {
+		return nil
+	}
+	for _, ib := range p.Inboxes {
+		if ib != nil && ib.Email != "" {
+			return nil
+		}
+	}
+	return errors.New("electronic address required (BT-34, BT-49)")
+}
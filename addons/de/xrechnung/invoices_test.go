@@ -53,6 +53,11 @@ func testInvoiceStandard(t *testing.T) *bill.Invoice {
 					Address: "billing@cursor.com",
 				},
 			},
+			Inboxes: []*org.Inbox{
+				{
+					Email: "invoices@cursor.com",
+				},
+			},
 			Telephones: []*org.Telephone{
 				{
 					Number: "+49100200300",
@@ -60,7 +65,8 @@ func testInvoiceStandard(t *testing.T) *bill.Invoice {
 			},
 		},
 		Customer: &org.Party{
-			Name: "Sample Consumer",
+			Name:     "Sample Consumer",
+			Endpoint: "0204:991-33333TEST-33",
 			TaxID: &tax.Identity{
 				Country: "DE",
 				Code:    "449674701",
@@ -149,5 +155,22 @@ func TestInvoiceValidation(t *testing.T) {
 		err := inv.Validate()
 		assert.NoError(t, err)
 	})
-
+	t.Run("missing customer electronic address", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Customer.Endpoint = ""
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "customer: electronic address required (BT-34, BT-49)")
+	})
+	t.Run("supplier with peppol inbox", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Supplier.Inboxes = []*org.Inbox{
+			{
+				Key:  org.InboxKeyPeppol,
+				Code: "9930:DE505898911",
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+	})
 }
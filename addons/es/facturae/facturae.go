@@ -14,7 +14,7 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V3, newAddon)
 }
 
 func newAddon() *tax.AddonDef {
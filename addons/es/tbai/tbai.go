@@ -4,6 +4,7 @@ package tbai
 import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
@@ -24,7 +25,15 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V1, newAddon)
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key: StampCode,
+		Name: i18n.String{
+			i18n.EN: "TicketBAI Code",
+			i18n.ES: "Código TicketBAI",
+		},
+		Pattern: `^TBAI-[0-9A-Z]{9}-\d{6}-[A-Za-z0-9+/=]{13}-\d{3}$`,
+	})
 }
 
 func newAddon() *tax.AddonDef {
@@ -0,0 +1,16 @@
+package tbai_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/es/tbai"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampFormat(t *testing.T) {
+	s := &head.Stamp{Provider: tbai.StampCode, Value: "TBAI-B98602642-130622-btFpwP8dcLGAF-237"}
+	assert.NoError(t, s.Validate())
+	s.Value = "TBAI-123"
+	assert.ErrorContains(t, s.Validate(), "val: invalid format")
+}
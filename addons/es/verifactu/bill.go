@@ -6,6 +6,7 @@ import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/es"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/validation"
 )
@@ -74,6 +75,24 @@ func normalizeInvoice(inv *bill.Invoice) {
 		})
 	}
 
+	// Cash basis invoices use the special regime for VAT and IGIC lines,
+	// unless a different regime has already been chosen.
+	if inv.HasTags(tax.TagCashBasis) {
+		for _, l := range inv.Lines {
+			if l == nil {
+				continue
+			}
+			for _, tc := range l.Taxes {
+				if tc == nil || !tc.Category.In(tax.CategoryVAT, es.TaxCategoryIGIC) {
+					continue
+				}
+				if tc.Ext.Get(ExtKeyRegime).In("", "01") {
+					tc.Ext = tc.Ext.Set(ExtKeyRegime, "07")
+				}
+			}
+		}
+	}
+
 	normalizeInvoicePartyIdentity(inv.Customer)
 }
 
@@ -133,6 +133,15 @@ func TestInvoicePartyNormalization(t *testing.T) {
 		assert.Equal(t, verifactu.ExtCodeIssuerTypeCustomer, inv.Tax.Ext[verifactu.ExtKeyIssuerType])
 	})
 
+	t.Run("cash basis", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.SetTags(tax.TagCashBasis)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "07", inv.Lines[0].Taxes[0].Ext[verifactu.ExtKeyRegime].String())
+		assert.True(t, inv.Totals.CashBasis)
+	})
+
 	t.Run("with issuer", func(t *testing.T) {
 		inv := testInvoiceStandard(t)
 		inv.Ordering = &bill.Ordering{
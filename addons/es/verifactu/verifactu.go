@@ -21,7 +21,7 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V1, newAddon)
 }
 
 func newAddon() *tax.AddonDef {
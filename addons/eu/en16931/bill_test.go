@@ -6,6 +6,7 @@ import (
 	_ "github.com/invopop/gobl"
 	"github.com/invopop/gobl/addons/eu/en16931"
 	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/cef"
 	"github.com/invopop/gobl/catalogues/untdid"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/org"
@@ -116,6 +117,24 @@ func testInvoiceStandard(t *testing.T) *bill.Invoice {
 	return inv
 }
 
+func TestInvoiceMarginScheme(t *testing.T) {
+	inv := testInvoiceStandard(t)
+	inv.Lines[0].Taxes[0].Key = tax.KeyMargin
+	inv.Lines[0].Taxes[0].Rate = ""
+	inv.Lines[0].Margin = &bill.LineMargin{
+		Key:  bill.MarginKeySecondHand,
+		Cost: num.MakeAmount(80000, 2),
+	}
+	require.NoError(t, inv.Calculate())
+	require.NoError(t, inv.Validate())
+	tc := inv.Lines[0].Taxes[0]
+	assert.Equal(t, "E", tc.Ext.Get(untdid.ExtKeyTaxCategory).String())
+	assert.Equal(t, "VATEX-EU-F", tc.Ext.Get(cef.ExtKeyVATEX).String())
+	assert.Equal(t, "200.00", inv.Lines[0].Margin.Amount.String())
+	assert.Equal(t, "0.00", inv.Totals.Tax.String())
+	assert.Equal(t, "1000.00", inv.Totals.Payable.String())
+}
+
 func TestNormalizeBillLineDiscount(t *testing.T) {
 	ad := tax.AddonForKey(en16931.V2017)
 	t.Run("with key", func(t *testing.T) {
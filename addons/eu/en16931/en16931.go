@@ -18,7 +18,7 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V2017, newAddon)
 }
 
 func newAddon() *tax.AddonDef {
@@ -50,6 +50,8 @@ func normalize(doc any) {
 		normalizeBillInvoice(obj)
 	case *pay.Instructions:
 		normalizePayInstructions(obj)
+	case *bill.Line:
+		normalizeBillLineMargin(obj)
 	case *tax.Combo:
 		normalizeTaxCombo(obj)
 	case *bill.Discount:
@@ -98,6 +98,10 @@ func validateOrgParty(p *org.Party) error {
 	return validation.ValidateStruct(p,
 		validation.Field(&p.Inboxes,
 			validation.Length(0, 1).Error("cannot have more than one inbox (BT-34, BT-49)"),
+			validation.When(
+				!p.Endpoint.IsEmpty(),
+				validation.Empty.Error("must be blank with endpoint (BT-34, BT-49)"),
+			),
 			validation.Skip,
 		),
 	)
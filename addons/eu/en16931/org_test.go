@@ -135,6 +135,25 @@ func TestOrgPartyValidate(t *testing.T) {
 		assert.NoError(t, ad.Validator(p))
 	})
 
+	t.Run("endpoint", func(t *testing.T) {
+		p := &org.Party{
+			Endpoint: "0088:5790000435951",
+		}
+		assert.NoError(t, ad.Validator(p))
+	})
+
+	t.Run("endpoint and inbox", func(t *testing.T) {
+		p := &org.Party{
+			Endpoint: "0088:5790000435951",
+			Inboxes: []*org.Inbox{
+				{
+					Email: "billing@example.com",
+				},
+			},
+		}
+		assert.ErrorContains(t, ad.Validator(p), "inboxes: must be blank with endpoint (BT-34, BT-49).")
+	})
+
 	t.Run("multiple inboxes", func(t *testing.T) {
 		p := &org.Party{
 			Inboxes: []*org.Inbox{
@@ -43,3 +43,12 @@ func TestPayInstructions(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestPaymentMeansCodes(t *testing.T) {
+	list := pay.MeansCodesFor(untdid.ExtKeyPaymentMeans)
+	assert.NotEmpty(t, list)
+	m := &pay.Instructions{
+		Key: pay.MeansKeyCard.With(pay.MeansKeyCredit),
+	}
+	assert.Equal(t, "54", m.MeansCode(untdid.ExtKeyPaymentMeans).String())
+}
@@ -1,6 +1,8 @@
 package en16931
 
 import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/cef"
 	"github.com/invopop/gobl/catalogues/untdid"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/regimes/es"
@@ -32,9 +34,23 @@ var vatKeyMap = tax.Extensions{
 	tax.KeyOutsideScope:   TaxCategoryOutsideScope,
 }
 
+// Margin scheme keys mapped to the CEF VATEX exemption codes expected
+// alongside the exempt category.
+var marginKeyMap = tax.Extensions{
+	bill.MarginKeySecondHand: "VATEX-EU-F",
+	bill.MarginKeyArt:        "VATEX-EU-I",
+	bill.MarginKeyAntiques:   "VATEX-EU-J",
+	bill.MarginKeyTravel:     "VATEX-EU-D",
+}
+
 func normalizeTaxCombo(tc *tax.Combo) {
 	switch tc.Category {
 	case tax.CategoryVAT:
+		if tc.Key == tax.KeyMargin {
+			// Margin schemes are reported as exempt with a VATEX code
+			tc.Ext = tc.Ext.Set(untdid.ExtKeyTaxCategory, TaxCategoryExempt)
+			return
+		}
 		if tc.Key.IsEmpty() {
 			// Try doing a reverse map of the VAT category key
 			k := vatKeyMap.Lookup(tc.Ext.Get(untdid.ExtKeyTaxCategory))
@@ -77,7 +93,27 @@ func validateTaxCombo(tc *tax.Combo) error {
 				!tc.Category.In(tax.CategoryVAT, es.TaxCategoryIGIC, es.TaxCategoryIPSI),
 				tax.ExtensionsHasCodes(untdid.ExtKeyTaxCategory, TaxCategoryOutsideScope),
 			),
+			validation.When(
+				tc.Key == tax.KeyMargin,
+				tax.ExtensionsRequire(cef.ExtKeyVATEX),
+				tax.ExtensionsHasCodes(cef.ExtKeyVATEX, marginKeyMap.Values()...),
+			),
 			validation.Skip,
 		),
 	)
 }
+
+// normalizeBillLineMargin sets the VATEX code for the margin scheme in the
+// line's VAT combo.
+func normalizeBillLineMargin(l *bill.Line) {
+	if l.Margin == nil {
+		return
+	}
+	code, ok := marginKeyMap[l.Margin.Key]
+	if !ok {
+		return
+	}
+	if tc := l.Taxes.Get(tax.CategoryVAT); tc != nil && tc.Key == tax.KeyMargin {
+		tc.Ext = tc.Ext.Set(cef.ExtKeyVATEX, code)
+	}
+}
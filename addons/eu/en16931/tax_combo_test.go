@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/catalogues/cef"
 	"github.com/invopop/gobl/catalogues/untdid"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/num"
@@ -147,6 +148,19 @@ func TestTaxComboValidation(t *testing.T) {
 		assert.Equal(t, "G", c.Ext[untdid.ExtKeyTaxCategory].String())
 		assert.Nil(t, c.Percent)
 	})
+	t.Run("margin", func(t *testing.T) {
+		c := &tax.Combo{
+			Category: tax.CategoryVAT,
+			Key:      tax.KeyMargin,
+		}
+		ad.Normalizer(c)
+		assert.Equal(t, "E", c.Ext[untdid.ExtKeyTaxCategory].String())
+		assert.ErrorContains(t, ad.Validator(c), "ext: (cef-vatex: required.)")
+		c.Ext = c.Ext.Set(cef.ExtKeyVATEX, "VATEX-EU-G")
+		assert.ErrorContains(t, ad.Validator(c), "ext: (cef-vatex: invalid value.)")
+		c.Ext = c.Ext.Set(cef.ExtKeyVATEX, "VATEX-EU-F")
+		assert.NoError(t, ad.Validator(c))
+	})
 	t.Run("outside-scope", func(t *testing.T) {
 		c := &tax.Combo{
 			Category: tax.CategoryVAT,
@@ -2,6 +2,7 @@ package choruspro
 
 import (
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/l10n"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/regimes/fr"
@@ -9,6 +10,31 @@ import (
 	"github.com/invopop/validation"
 )
 
+// Validation errors with stable codes and messages in English and French.
+var (
+	// ErrSIRETMissing is used when the scheme requires a SIRET identity.
+	ErrSIRETMissing = i18n.NewError("validation_choruspro_siret_missing", i18n.String{
+		i18n.EN: "No SIRET identity found",
+		i18n.FR: "Aucune identité SIRET trouvée",
+	})
+	// ErrSIRETNotAllowed is used when the scheme does not accept SIRET
+	// identities.
+	ErrSIRETNotAllowed = i18n.NewError("validation_choruspro_siret_not_allowed", i18n.String{
+		i18n.EN: "SIRET identity not allowed for this extension",
+		i18n.FR: "Identité SIRET non autorisée pour cette extension",
+	})
+	// ErrCustomerNotEU is used when the customer must be in the EU.
+	ErrCustomerNotEU = i18n.NewError("validation_choruspro_customer_not_eu", i18n.String{
+		i18n.EN: "Customer must be a member of the EU",
+		i18n.FR: "Le client doit être membre de l'UE",
+	})
+	// ErrCustomerEU is used when the customer must be outside the EU.
+	ErrCustomerEU = i18n.NewError("validation_choruspro_customer_eu", i18n.String{
+		i18n.EN: "Customer must be a non-EU company",
+		i18n.FR: "Le client doit être une entreprise hors UE",
+	})
+)
+
 func normalizeOrgParty(party *org.Party) {
 	if party == nil {
 		return
@@ -101,10 +127,10 @@ func validateOrgIdentities(scheme cbc.Code) validation.RuleFunc {
 		}
 
 		if scheme == "1" && !foundSIRET {
-			return validation.NewError("identities", "No SIRET identity found")
+			return ErrSIRETMissing
 		}
 		if scheme != "1" && foundSIRET {
-			return validation.NewError("identities", "SIRET identity not allowed for this extension")
+			return ErrSIRETNotAllowed
 		}
 		return nil
 	}
@@ -156,7 +182,7 @@ func validateEUCompany(value interface{}) error {
 	}
 
 	if !l10n.Unions().Code(l10n.EU).HasMember(l10n.Code(country)) {
-		return validation.NewError("taxID", "Customer must be a member of the EU")
+		return ErrCustomerNotEU
 	}
 
 	return nil
@@ -169,7 +195,7 @@ func validateNonEUCompany(value interface{}) error {
 	}
 
 	if l10n.Unions().Code(l10n.EU).HasMember(l10n.Code(country)) {
-		return validation.NewError("taxID", "Customer must be a non-EU company")
+		return ErrCustomerEU
 	}
 
 	return nil
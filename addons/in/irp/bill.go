@@ -0,0 +1,122 @@
+package irp
+
+import (
+	"regexp"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// Document numbers, including the series, must be at most 16 characters long and may not start
+// with a zero, slash, or hyphen.
+var invoiceCodeRegexp = regexp.MustCompile(`^[A-Za-z1-9][A-Za-z0-9/-]{0,15}$`)
+
+func normalizeInvoice(inv *bill.Invoice) {
+	if inv.Customer == nil || inv.Customer.TaxID == nil {
+		return
+	}
+	if inv.Customer.TaxID.Country == l10n.IN.Tax() {
+		// SEZ and deemed export supplies cannot be detected, so only
+		// set a default if nothing was provided.
+		if inv.Tax == nil || !inv.Tax.Ext.Has(ExtKeySupplyType) {
+			inv.Tax = inv.Tax.MergeExtensions(tax.Extensions{
+				ExtKeySupplyType: SupplyTypeB2B,
+			})
+		}
+	}
+}
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Type,
+			validation.In(
+				bill.InvoiceTypeStandard,
+				bill.InvoiceTypeCreditNote,
+				bill.InvoiceTypeDebitNote,
+			),
+		),
+		validation.Field(&inv.Code,
+			validation.By(validateInvoiceCode(inv)),
+			validation.Skip,
+		),
+		validation.Field(&inv.Tax,
+			validation.Required,
+			validation.By(validateInvoiceTax),
+			validation.Skip,
+		),
+		validation.Field(&inv.Supplier,
+			validation.By(validateSupplier),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.Required,
+			validation.By(validateCustomer(inv)),
+			validation.Skip,
+		),
+	)
+}
+
+// validateInvoiceCode checks the document number reported to the IRP,
+// which includes the series.
+func validateInvoiceCode(inv *bill.Invoice) func(value any) error {
+	return func(_ any) error {
+		code := inv.Series.Join(inv.Code)
+		if code == "" {
+			return nil
+		}
+		return validation.Validate(code.String(), validation.Match(invoiceCodeRegexp))
+	}
+}
+
+func validateInvoiceTax(value any) error {
+	t, ok := value.(*bill.Tax)
+	if !ok || t == nil {
+		return nil
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Ext,
+			tax.ExtensionsRequire(ExtKeySupplyType, ExtKeyDocType),
+			validation.Skip,
+		),
+	)
+}
+
+func validateSupplier(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TaxID,
+			validation.Required,
+			tax.RequireIdentityCode,
+			validation.Skip,
+		),
+	)
+}
+
+func validateCustomer(inv *bill.Invoice) func(value any) error {
+	return func(value any) error {
+		p, ok := value.(*org.Party)
+		if !ok || p == nil {
+			return nil
+		}
+		export := inv.Tax != nil && inv.Tax.Ext.Get(ExtKeySupplyType).In(
+			SupplyTypeExpWithPay,
+			SupplyTypeExpNoPay,
+		)
+		return validation.ValidateStruct(p,
+			validation.Field(&p.TaxID,
+				validation.When(
+					!export,
+					validation.Required,
+					tax.RequireIdentityCode,
+				),
+				validation.Skip,
+			),
+		)
+	}
+}
@@ -0,0 +1,118 @@
+package irp_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/in/irp"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/in"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Regime:   tax.WithRegime("IN"),
+		Addons:   tax.WithAddons(irp.V1),
+		Series:   "INV",
+		Code:     "0002",
+		Currency: "INR",
+		Supplier: &org.Party{
+			Name: "Test Supplier",
+			TaxID: &tax.Identity{
+				Country: "IN",
+				Code:    "27AAPFU0939F1ZV",
+			},
+		},
+		Customer: &org.Party{
+			Name: "Test Customer",
+			TaxID: &tax.Identity{
+				Country: "IN",
+				Code:    "29AAPFU0939F1ZR",
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Development services",
+					Price: num.NewAmount(10000, 2),
+					Identities: []*org.Identity{
+						{Type: in.IdentityTypeHSN, Code: "998314"},
+					},
+				},
+				Taxes: tax.Set{
+					{Category: in.TaxCategoryIGST, Percent: num.NewPercentage(18, 2)},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceNormalize(t *testing.T) {
+	t.Run("domestic", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, irp.SupplyTypeB2B, inv.Tax.Ext[irp.ExtKeySupplyType])
+		assert.Equal(t, irp.DocTypeInvoice, inv.Tax.Ext[irp.ExtKeyDocType])
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("existing supply type", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Tax = &bill.Tax{Ext: tax.Extensions{irp.ExtKeySupplyType: irp.SupplyTypeSEZWithPay}}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, irp.SupplyTypeSEZWithPay, inv.Tax.Ext[irp.ExtKeySupplyType])
+	})
+
+	t.Run("credit note", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeCreditNote
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, irp.DocTypeCreditNote, inv.Tax.Ext[irp.ExtKeyDocType])
+	})
+}
+
+func TestInvoiceValidate(t *testing.T) {
+	t.Run("export", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "US"}
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "tax: (ext: (in-irp-supply-type: required.).)")
+
+		inv.Tax.Ext[irp.ExtKeySupplyType] = irp.SupplyTypeExpWithPay
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("missing customer tax code", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Tax = &bill.Tax{Ext: tax.Extensions{irp.ExtKeySupplyType: irp.SupplyTypeSEZNoPay}}
+		inv.Customer.TaxID = nil
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "customer: (tax_id: cannot be blank.)")
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Series = ""
+		inv.Code = "0123456789ABCDEFG"
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "code: must be in a valid format")
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeProforma
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "type: must be a valid value")
+	})
+}
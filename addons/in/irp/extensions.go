@@ -0,0 +1,116 @@
+package irp
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
+)
+
+// Extension keys for the IRP e-invoice.
+const (
+	ExtKeySupplyType cbc.Key = "in-irp-supply-type"
+	ExtKeyDocType    cbc.Key = "in-irp-doc-type"
+)
+
+// Supply type codes that may be re-used.
+const (
+	SupplyTypeB2B        cbc.Code = "B2B"
+	SupplyTypeSEZWithPay cbc.Code = "SEZWP"
+	SupplyTypeSEZNoPay   cbc.Code = "SEZWOP"
+	SupplyTypeExpWithPay cbc.Code = "EXPWP"
+	SupplyTypeExpNoPay   cbc.Code = "EXPWOP"
+	SupplyTypeDeemedExp  cbc.Code = "DEXP"
+)
+
+// Document type codes set by the invoice scenarios.
+const (
+	DocTypeInvoice    cbc.Code = "INV"
+	DocTypeCreditNote cbc.Code = "CRN"
+	DocTypeDebitNote  cbc.Code = "DBN"
+)
+
+var extensions = []*cbc.Definition{
+	{
+		Key: ExtKeySupplyType,
+		Name: i18n.String{
+			i18n.EN: "Supply Type",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Category of the supply reported to the IRP in the "SupTyp" field. Domestic
+				supplies will default to "B2B", while supplies to Special Economic Zones
+				and exports must be set explicitly as they depend on whether IGST was paid.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: SupplyTypeB2B,
+				Name: i18n.String{
+					i18n.EN: "Business to Business",
+				},
+			},
+			{
+				Code: SupplyTypeSEZWithPay,
+				Name: i18n.String{
+					i18n.EN: "SEZ with payment of IGST",
+				},
+			},
+			{
+				Code: SupplyTypeSEZNoPay,
+				Name: i18n.String{
+					i18n.EN: "SEZ without payment of IGST",
+				},
+			},
+			{
+				Code: SupplyTypeExpWithPay,
+				Name: i18n.String{
+					i18n.EN: "Export with payment of IGST",
+				},
+			},
+			{
+				Code: SupplyTypeExpNoPay,
+				Name: i18n.String{
+					i18n.EN: "Export without payment of IGST",
+				},
+			},
+			{
+				Code: SupplyTypeDeemedExp,
+				Name: i18n.String{
+					i18n.EN: "Deemed Export",
+				},
+			},
+		},
+	},
+	{
+		Key: ExtKeyDocType,
+		Name: i18n.String{
+			i18n.EN: "Document Type",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Type of document reported to the IRP in the "Typ" field, determined
+				automatically from the invoice type.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: DocTypeInvoice,
+				Name: i18n.String{
+					i18n.EN: "Tax Invoice",
+				},
+			},
+			{
+				Code: DocTypeCreditNote,
+				Name: i18n.String{
+					i18n.EN: "Credit Note",
+				},
+			},
+			{
+				Code: DocTypeDebitNote,
+				Name: i18n.String{
+					i18n.EN: "Debit Note",
+				},
+			},
+		},
+	},
+}
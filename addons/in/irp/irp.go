@@ -0,0 +1,68 @@
+// Package irp provides the extensions and validations required to issue
+// Indian GST e-invoices through the Invoice Registration Portal (IRP).
+package irp
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V1 for the IRP e-invoice schema version 1.1.
+	V1 cbc.Key = "in-irp-v1"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V1, newAddon)
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V1,
+		Name: i18n.String{
+			i18n.EN: "India IRP e-Invoice",
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the Indian GST e-invoicing system, where B2B invoices, credit
+				and debit notes, and exports must be reported to the Invoice Registration
+				Portal (IRP) in order to obtain an Invoice Reference Number (IRN) before
+				being sent to the customer.
+
+				Documents will be assigned the IRP supply type and document type categories,
+				and validated to ensure both the supplier and customer GSTINs are available
+				when required.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "e-Invoice Schema (FORM GST INV-01)",
+				},
+				URL: "https://einvoice1.gst.gov.in/Others/EinvoiceSchema",
+			},
+		},
+		Extensions: extensions,
+		Scenarios:  scenarios,
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		normalizeInvoice(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package irp
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+)
+
+var scenarios = []*tax.ScenarioSet{
+	{
+		Schema: bill.ShortSchemaInvoice,
+		List: []*tax.Scenario{
+			{
+				Types: []cbc.Key{bill.InvoiceTypeStandard},
+				Ext: tax.Extensions{
+					ExtKeyDocType: DocTypeInvoice,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeCreditNote},
+				Ext: tax.Extensions{
+					ExtKeyDocType: DocTypeCreditNote,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeDebitNote},
+				Ext: tax.Extensions{
+					ExtKeyDocType: DocTypeDebitNote,
+				},
+			},
+		},
+	},
+}
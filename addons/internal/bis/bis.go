@@ -0,0 +1,59 @@
+// Package bis provides the Peppol BIS Billing 3.0 rules shared by the
+// addons for national profiles, each of which identifies local parties
+// with their own ISO 6523 scheme.
+package bis
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+)
+
+// NormalizeEndpoint assigns a default endpoint to parties without an
+// electronic address. The first identity with the ISO 6523 scheme is
+// preferred, followed by the tax ID code for parties from the country,
+// or the VAT scheme of the party's country otherwise. Identities are
+// expected to have been assigned the scheme beforehand.
+func NormalizeEndpoint(p *org.Party, country l10n.TaxCountryCode, scheme cbc.Code) {
+	if p == nil || !p.Endpoint.IsEmpty() || len(p.Inboxes) > 0 {
+		return
+	}
+	for _, id := range p.Identities {
+		if id != nil && id.Code != cbc.CodeEmpty && id.Ext.Get(iso.ExtKeySchemeID) == scheme {
+			p.Endpoint = cbc.NewSchemeCode(scheme, id.Code)
+			return
+		}
+	}
+	if p.TaxID == nil || p.TaxID.Code == cbc.CodeEmpty {
+		return
+	}
+	if p.TaxID.Country == country {
+		p.Endpoint = cbc.NewSchemeCode(scheme, p.TaxID.Code)
+	} else if sd := iso.VATSchemeFor(p.TaxID.Country); sd != nil {
+		p.Endpoint = cbc.NewSchemeCode(sd.Code, sd.VATCode(p.TaxID.Code))
+	}
+}
+
+// ValidateParty ensures the party can be reached through the Peppol
+// network with an electronic address (PEPPOL-EN16931-R010, R020).
+func ValidateParty(value any) error {
+	p, _ := value.(*org.Party)
+	if p == nil || !p.ElectronicAddress().IsEmpty() {
+		return nil
+	}
+	return errors.New("electronic address required")
+}
+
+// ValidateOrdering ensures either a buyer reference or purchase order
+// reference is provided (PEPPOL-EN16931-R003).
+func ValidateOrdering(value any) error {
+	o, _ := value.(*bill.Ordering)
+	if o == nil || (o.Code == "" && len(o.Purchases) == 0) {
+		return errors.New("buyer reference code or purchase order required")
+	}
+	return nil
+}
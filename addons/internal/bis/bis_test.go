@@ -0,0 +1,64 @@
+package bis_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/internal/bis"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEndpoint(t *testing.T) {
+	const scheme cbc.Code = "0192"
+	t.Run("from identity", func(t *testing.T) {
+		p := &org.Party{
+			TaxID: &tax.Identity{Country: "NO", Code: "974760673"},
+			Identities: []*org.Identity{
+				{Code: "923609016", Ext: tax.Extensions{iso.ExtKeySchemeID: scheme}},
+			},
+		}
+		bis.NormalizeEndpoint(p, l10n.NO.Tax(), scheme)
+		assert.Equal(t, cbc.NewSchemeCode(scheme, "923609016"), p.Endpoint)
+	})
+	t.Run("from local tax ID", func(t *testing.T) {
+		p := &org.Party{TaxID: &tax.Identity{Country: "NO", Code: "974760673"}}
+		bis.NormalizeEndpoint(p, l10n.NO.Tax(), scheme)
+		assert.Equal(t, cbc.NewSchemeCode(scheme, "974760673"), p.Endpoint)
+	})
+	t.Run("from foreign VAT number", func(t *testing.T) {
+		p := &org.Party{TaxID: &tax.Identity{Country: "DE", Code: "111111125"}}
+		bis.NormalizeEndpoint(p, l10n.NO.Tax(), scheme)
+		assert.Equal(t, cbc.NewSchemeCode("9930", "DE111111125"), p.Endpoint)
+	})
+	t.Run("with inbox", func(t *testing.T) {
+		p := &org.Party{
+			TaxID:   &tax.Identity{Country: "NO", Code: "974760673"},
+			Inboxes: []*org.Inbox{{Email: "invoices@example.com"}},
+		}
+		bis.NormalizeEndpoint(p, l10n.NO.Tax(), scheme)
+		assert.True(t, p.Endpoint.IsEmpty())
+	})
+	t.Run("nil", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			bis.NormalizeEndpoint(nil, l10n.NO.Tax(), scheme)
+		})
+	})
+}
+
+func TestValidateParty(t *testing.T) {
+	assert.NoError(t, bis.ValidateParty(nil))
+	assert.ErrorContains(t, bis.ValidateParty(&org.Party{Name: "Test"}), "electronic address required")
+	p := &org.Party{Endpoint: cbc.NewSchemeCode("0192", "974760673")}
+	assert.NoError(t, bis.ValidateParty(p))
+}
+
+func TestValidateOrdering(t *testing.T) {
+	assert.ErrorContains(t, bis.ValidateOrdering(nil), "buyer reference code or purchase order required")
+	assert.ErrorContains(t, bis.ValidateOrdering(&bill.Ordering{}), "buyer reference code or purchase order required")
+	assert.NoError(t, bis.ValidateOrdering(&bill.Ordering{Code: "PO-0042"}))
+}
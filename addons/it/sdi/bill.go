@@ -11,6 +11,8 @@ import (
 	"github.com/invopop/validation"
 )
 
+var postCodeRegexp = regexp.MustCompile(`^\d{5}$`)
+
 func normalizeInvoice(inv *bill.Invoice) {
 	normalizeSupplier(inv.Supplier)
 }
@@ -278,7 +280,7 @@ func validateAddress(value interface{}) error {
 		validation.Field(&v.Code,
 			validation.When(v.Country.In("IT"),
 				validation.Required,
-				validation.Match(regexp.MustCompile(`^\d{5}$`)),
+				validation.Match(postCodeRegexp),
 			),
 		),
 	)
@@ -377,7 +377,7 @@ func TestPaymentValidation(t *testing.T) {
 				DueDates: []*pay.DueDate{
 					{
 						Date:    cal.NewDate(2022, 6, 13),
-						Percent: num.NewPercentage(100, 3),
+						Percent: num.NewPercentage(100, 2),
 					},
 				},
 			},
@@ -406,7 +406,7 @@ func TestPaymentValidation(t *testing.T) {
 				DueDates: []*pay.DueDate{
 					{
 						Date:    cal.NewDate(2022, 6, 13),
-						Percent: num.NewPercentage(100, 3),
+						Percent: num.NewPercentage(100, 2),
 					},
 				},
 			},
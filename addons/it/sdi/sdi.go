@@ -5,6 +5,7 @@ package sdi
 import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/pay"
@@ -19,8 +20,20 @@ const (
 	KeyFundContribution cbc.Key = "fund-contribution"
 )
 
+// StampID contains the transmission identifier assigned by SDI to each
+// file received.
+const StampID cbc.Key = "sdi-id"
+
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V1, newAddon)
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key: StampID,
+		Name: i18n.String{
+			i18n.EN: "SDI Transmission ID",
+			i18n.IT: "Identificativo SdI",
+		},
+		Pattern: `^\d{1,18}$`,
+	})
 }
 
 func newAddon() *tax.AddonDef {
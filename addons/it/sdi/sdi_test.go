@@ -0,0 +1,16 @@
+package sdi_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/it/sdi"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampFormat(t *testing.T) {
+	s := &head.Stamp{Provider: sdi.StampID, Value: "12345678901"}
+	assert.NoError(t, s.Validate())
+	s.Value = "ABC123"
+	assert.ErrorContains(t, s.Validate(), "val: invalid format")
+}
@@ -27,7 +27,7 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V1, newAddon)
 }
 
 // This validation follows the rules of the Italian Agenzia delle Entrate
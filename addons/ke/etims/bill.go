@@ -0,0 +1,82 @@
+package etims
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// maxTraderInvoiceLength is the maximum length of the trader's own invoice
+// number sent in the eTIMS "trdInvcNo" field.
+const maxTraderInvoiceLength = 50
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Type,
+			validation.In(
+				bill.InvoiceTypeStandard,
+				bill.InvoiceTypeCreditNote,
+			),
+		),
+		validation.Field(&inv.Code,
+			validation.Required,
+			validation.By(validateTraderInvoiceNumber(inv)),
+		),
+		validation.Field(&inv.Preceding,
+			validation.When(
+				inv.Type.In(bill.InvoiceTypeCreditNote),
+				validation.Required,
+			),
+			validation.Skip,
+		),
+		validation.Field(&inv.Tax,
+			validation.Required,
+			validation.By(validateInvoiceTax),
+			validation.Skip,
+		),
+		validation.Field(&inv.Supplier,
+			validation.By(validateSupplier),
+			validation.Skip,
+		),
+	)
+}
+
+func validateTraderInvoiceNumber(inv *bill.Invoice) func(any) error {
+	return func(_ any) error {
+		if len(inv.Series.Join(inv.Code)) > maxTraderInvoiceLength {
+			return errors.New("series and code must be no more than 50 characters")
+		}
+		return nil
+	}
+}
+
+func validateInvoiceTax(value any) error {
+	t, ok := value.(*bill.Tax)
+	if !ok || t == nil {
+		return nil
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Ext,
+			tax.ExtensionsRequire(ExtKeyReceiptType),
+			validation.Skip,
+		),
+	)
+}
+
+// validateSupplier ensures the supplier's KRA PIN is provided.
+func validateSupplier(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TaxID,
+			validation.Required,
+			tax.RequireIdentityCode,
+			validation.Skip,
+		),
+	)
+}
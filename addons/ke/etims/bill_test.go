@@ -0,0 +1,196 @@
+package etims_test
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/ke/etims"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Addons:    tax.WithAddons(etims.V1),
+		Currency:  "KES",
+		IssueDate: cal.MakeDate(2024, 6, 1),
+		Series:    "INV",
+		Code:      "0001",
+		Supplier: &org.Party{
+			Name: "Duka Bora Ltd",
+			TaxID: &tax.Identity{
+				Country: "KE",
+				Code:    "P051234567Q",
+			},
+		},
+		Customer: &org.Party{
+			Name: "Mteja Ltd",
+			TaxID: &tax.Identity{
+				Country: "KE",
+				Code:    "P051765432X",
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyOnline.With(etims.MeansKeyMobileMoney),
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item: &org.Item{
+					Name:  "Unga wa Ngano",
+					Price: num.NewAmount(25000, 2),
+					Ext: tax.Extensions{
+						etims.ExtKeyItemClass:   "5010190100",
+						etims.ExtKeyProductType: etims.ProductTypeFinished,
+					},
+				},
+				Taxes: tax.Set{
+					{
+						Category: tax.CategoryVAT,
+						Rate:     tax.RateGeneral,
+					},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Maziwa",
+					Price: num.NewAmount(6000, 2),
+					Ext: tax.Extensions{
+						etims.ExtKeyItemClass:   "5011150100",
+						etims.ExtKeyProductType: etims.ProductTypeFinished,
+					},
+				},
+				Taxes: tax.Set{
+					{
+						Category: tax.CategoryVAT,
+						Key:      tax.KeyExempt,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceNormalization(t *testing.T) {
+	inv := testInvoice(t)
+	require.NoError(t, inv.Calculate())
+	assert.Equal(t, etims.ReceiptTypeSale, inv.Tax.Ext[etims.ExtKeyReceiptType])
+	assert.Equal(t, etims.TaxTypeGeneral, inv.Lines[0].Taxes[0].Ext[etims.ExtKeyTaxType])
+	assert.Equal(t, etims.TaxTypeExempt, inv.Lines[1].Taxes[0].Ext[etims.ExtKeyTaxType])
+	assert.Equal(t, "06", inv.Payment.Instructions.Ext[etims.ExtKeyPaymentType].String())
+	assert.Equal(t, "640.00", inv.Totals.Payable.String())
+	require.NoError(t, inv.Validate())
+}
+
+func TestTaxComboNormalization(t *testing.T) {
+	tests := []struct {
+		key  cbc.Key
+		rate cbc.Key
+		code cbc.Code
+	}{
+		{rate: tax.RateGeneral, code: etims.TaxTypeGeneral},
+		{key: tax.KeyStandard, rate: tax.RateReduced, code: etims.TaxTypeReduced},
+		{key: tax.KeyZero, code: etims.TaxTypeZero},
+		{key: tax.KeyExport, code: etims.TaxTypeZero},
+		{key: tax.KeyExempt, code: etims.TaxTypeExempt},
+		{key: tax.KeyOutsideScope, code: etims.TaxTypeNonVAT},
+		{key: tax.KeyReverseCharge},
+	}
+	ad := tax.AddonForKey(etims.V1)
+	for _, tt := range tests {
+		tc := &tax.Combo{Category: tax.CategoryVAT, Key: tt.key, Rate: tt.rate}
+		ad.Normalizer(tc)
+		assert.Equal(t, tt.code, tc.Ext[etims.ExtKeyTaxType], "key %s rate %s", tt.key, tt.rate)
+	}
+}
+
+func TestInvoiceValidation(t *testing.T) {
+	t.Run("credit note", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeCreditNote
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "preceding: cannot be blank")
+
+		inv.Preceding = []*org.DocumentRef{
+			{
+				Series:    "INV",
+				Code:      "0000",
+				IssueDate: cal.NewDate(2024, 5, 1),
+				Reason:    "Damaged goods",
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, etims.ReceiptTypeRefund, inv.Tax.Ext[etims.ExtKeyReceiptType])
+		assert.NoError(t, inv.Validate())
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeProforma
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "type: must be a valid value")
+	})
+
+	t.Run("long code", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Code = cbc.Code(strings.Repeat("1", 48))
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "code: series and code must be no more than 50 characters")
+	})
+
+	t.Run("missing supplier PIN", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Supplier.TaxID.Code = ""
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "supplier: (tax_id: (code: cannot be blank.).)")
+	})
+
+	t.Run("invalid customer PIN", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID.Code = "X051765432X"
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "customer: (tax_id: (code: must be an 'A' or 'P' followed by 9 digits and a letter.).)")
+	})
+
+	t.Run("missing item codes", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Lines[0].Item.Ext = nil
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "ke-etims-item-class: required")
+		assert.ErrorContains(t, err, "ke-etims-product-type: required")
+	})
+
+	t.Run("invalid item class", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Lines[0].Item.Ext[etims.ExtKeyItemClass] = "50101901"
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "ke-etims-item-class")
+	})
+
+	t.Run("missing tax type", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Lines[0].Taxes[0] = &tax.Combo{Category: tax.CategoryVAT, Key: tax.KeyReverseCharge}
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "ke-etims-tax-type: required")
+	})
+
+	t.Run("missing payment type", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Payment.Instructions.Key = pay.MeansKeyDirectDebit
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "ke-etims-payment-type: required")
+	})
+}
@@ -0,0 +1,129 @@
+// Package etims provides the extensions and validations required to prepare
+// Kenyan invoices for the KRA electronic Tax Invoice Management System
+// (eTIMS).
+package etims
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V1 for eTIMS invoices submitted through the OSCU or VSCU APIs.
+	V1 cbc.Key = "ke-etims-v1"
+)
+
+// eTIMS stamp keys for the control fields returned once an invoice has
+// been signed by the Control Unit, which must be printed on the invoice.
+const (
+	// StampSCUID contains the identifier of the Sales Control Unit (SDC ID).
+	StampSCUID cbc.Key = "etims-scu-id"
+	// StampReceiptNumber contains the receipt number assigned by the
+	// Control Unit.
+	StampReceiptNumber cbc.Key = "etims-receipt-number"
+	// StampInternalData contains the internal data used to verify the
+	// receipt.
+	StampInternalData cbc.Key = "etims-internal-data"
+	// StampSignature contains the receipt signature.
+	StampSignature cbc.Key = "etims-signature"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V1, newAddon)
+	for _, sp := range stampProviders {
+		head.RegisterStampProviderDef(sp)
+	}
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V1,
+		Name: i18n.String{
+			i18n.EN: "Kenya eTIMS",
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the Kenya Revenue Authority's electronic Tax Invoice Management
+				System (eTIMS), used by VAT registered businesses to fiscalize their sales
+				through an Online or Virtual Sales Control Unit (OSCU or VSCU).
+
+				Invoices will be assigned the eTIMS receipt type, and tax combos the tax
+				type code according to their rate. Items must include their eTIMS item
+				classification and product type codes, and the supplier must include
+				their KRA PIN.
+
+				The control fields returned by eTIMS once the invoice has been signed
+				should be added to the envelope's header as stamps, so that they may be
+				printed on the invoice.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "eTIMS | Kenya Revenue Authority",
+				},
+				URL: "https://www.kra.go.ke/etims",
+			},
+		},
+		Extensions: extensions,
+		Scenarios:  scenarios,
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+var stampProviders = []*head.StampProviderDef{
+	{
+		Key: StampSCUID,
+		Name: i18n.String{
+			i18n.EN: "eTIMS SCU ID",
+		},
+	},
+	{
+		Key: StampReceiptNumber,
+		Name: i18n.String{
+			i18n.EN: "eTIMS Receipt Number",
+		},
+	},
+	{
+		Key: StampInternalData,
+		Name: i18n.String{
+			i18n.EN: "eTIMS Internal Data",
+		},
+	},
+	{
+		Key: StampSignature,
+		Name: i18n.String{
+			i18n.EN: "eTIMS Receipt Signature",
+		},
+	},
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *tax.Combo:
+		normalizeTaxCombo(obj)
+	case *pay.Instructions:
+		normalizePayInstructions(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	case *tax.Combo:
+		return validateTaxCombo(obj)
+	case *org.Item:
+		return validateItem(obj)
+	case *pay.Instructions:
+		return validatePayInstructions(obj)
+	}
+	return nil
+}
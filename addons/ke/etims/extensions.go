@@ -0,0 +1,212 @@
+package etims
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
+)
+
+// Extension keys used by eTIMS.
+const (
+	ExtKeyReceiptType cbc.Key = "ke-etims-receipt-type"
+	ExtKeyTaxType     cbc.Key = "ke-etims-tax-type"
+	ExtKeyItemClass   cbc.Key = "ke-etims-item-class"
+	ExtKeyProductType cbc.Key = "ke-etims-product-type"
+	ExtKeyPaymentType cbc.Key = "ke-etims-payment-type"
+)
+
+// Receipt type codes.
+const (
+	ReceiptTypeSale   cbc.Code = "S"
+	ReceiptTypeRefund cbc.Code = "R"
+)
+
+// Tax type codes.
+const (
+	TaxTypeExempt  cbc.Code = "A"
+	TaxTypeGeneral cbc.Code = "B"
+	TaxTypeZero    cbc.Code = "C"
+	TaxTypeNonVAT  cbc.Code = "D"
+	TaxTypeReduced cbc.Code = "E"
+)
+
+// Product type codes.
+const (
+	ProductTypeRawMaterial cbc.Code = "1"
+	ProductTypeFinished    cbc.Code = "2"
+	ProductTypeService     cbc.Code = "3"
+)
+
+var extensions = []*cbc.Definition{
+	{
+		Key: ExtKeyReceiptType,
+		Name: i18n.String{
+			i18n.EN: "Receipt Type Code",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Code used in the eTIMS "rcptTyCd" field, determined automatically from
+				the invoice type.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: ReceiptTypeSale,
+				Name: i18n.String{
+					i18n.EN: "Sale",
+				},
+			},
+			{
+				Code: ReceiptTypeRefund,
+				Name: i18n.String{
+					i18n.EN: "Credit Note",
+				},
+			},
+		},
+	},
+	{
+		Key: ExtKeyTaxType,
+		Name: i18n.String{
+			i18n.EN: "Tax Type Code",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Code used in the eTIMS "taxTyCd" field of each item, determined
+				automatically from the tax combo's key and rate.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: TaxTypeExempt,
+				Name: i18n.String{
+					i18n.EN: "Exempted",
+				},
+			},
+			{
+				Code: TaxTypeGeneral,
+				Name: i18n.String{
+					i18n.EN: "16% VAT",
+				},
+			},
+			{
+				Code: TaxTypeZero,
+				Name: i18n.String{
+					i18n.EN: "Zero Rated",
+				},
+			},
+			{
+				Code: TaxTypeNonVAT,
+				Name: i18n.String{
+					i18n.EN: "Non-VAT",
+				},
+			},
+			{
+				Code: TaxTypeReduced,
+				Name: i18n.String{
+					i18n.EN: "8% VAT",
+				},
+			},
+		},
+	},
+	{
+		Key: ExtKeyItemClass,
+		Name: i18n.String{
+			i18n.EN: "Item Classification Code",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Ten digit code from the eTIMS item classification list, based on the
+				UNSPSC, used in the "itemClsCd" field.
+			`),
+		},
+		Pattern: `^\d{10}$`,
+	},
+	{
+		Key: ExtKeyProductType,
+		Name: i18n.String{
+			i18n.EN: "Product Type Code",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Code used in the eTIMS "itemTyCd" field to indicate if the item is a raw
+				material, finished product, or a service.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: ProductTypeRawMaterial,
+				Name: i18n.String{
+					i18n.EN: "Raw Material",
+				},
+			},
+			{
+				Code: ProductTypeFinished,
+				Name: i18n.String{
+					i18n.EN: "Finished Product",
+				},
+			},
+			{
+				Code: ProductTypeService,
+				Name: i18n.String{
+					i18n.EN: "Service",
+				},
+			},
+		},
+	},
+	{
+		Key: ExtKeyPaymentType,
+		Name: i18n.String{
+			i18n.EN: "Payment Type Code",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Code used in the eTIMS "pmtTyCd" field, determined automatically from
+				the payment instructions' means key when possible.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: "01",
+				Name: i18n.String{
+					i18n.EN: "Cash",
+				},
+			},
+			{
+				Code: "02",
+				Name: i18n.String{
+					i18n.EN: "Credit",
+				},
+			},
+			{
+				Code: "03",
+				Name: i18n.String{
+					i18n.EN: "Cash and Credit",
+				},
+			},
+			{
+				Code: "04",
+				Name: i18n.String{
+					i18n.EN: "Bank Cheque",
+				},
+			},
+			{
+				Code: "05",
+				Name: i18n.String{
+					i18n.EN: "Debit and Credit Card",
+				},
+			},
+			{
+				Code: "06",
+				Name: i18n.String{
+					i18n.EN: "Mobile Money",
+				},
+			},
+			{
+				Code: "07",
+				Name: i18n.String{
+					i18n.EN: "Other",
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,18 @@
+package etims
+
+import (
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// validateItem ensures items include the classification and product type
+// codes required to register them in eTIMS.
+func validateItem(item *org.Item) error {
+	return validation.ValidateStruct(item,
+		validation.Field(&item.Ext,
+			tax.ExtensionsRequire(ExtKeyItemClass, ExtKeyProductType),
+			validation.Skip,
+		),
+	)
+}
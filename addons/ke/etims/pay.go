@@ -0,0 +1,41 @@
+package etims
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// MeansKeyMobileMoney is used with the online payment means key to identify
+// payments made using mobile money services such as M-Pesa.
+const MeansKeyMobileMoney cbc.Key = "mobile-money"
+
+var paymentTypeMap = tax.Extensions{
+	pay.MeansKeyCash:   "01",
+	pay.MeansKeyCheque: "04",
+	pay.MeansKeyCard:   "05",
+	pay.MeansKeyOnline.With(MeansKeyMobileMoney): "06",
+	pay.MeansKeyCreditTransfer:                   "07",
+	pay.MeansKeyOther:                            "07",
+}
+
+func normalizePayInstructions(instr *pay.Instructions) {
+	if instr == nil {
+		return
+	}
+	if code := paymentTypeMap[instr.Key]; code != "" {
+		instr.Ext = instr.Ext.Merge(tax.Extensions{
+			ExtKeyPaymentType: code,
+		})
+	}
+}
+
+func validatePayInstructions(instr *pay.Instructions) error {
+	return validation.ValidateStruct(instr,
+		validation.Field(&instr.Ext,
+			tax.ExtensionsRequire(ExtKeyPaymentType),
+			validation.Skip,
+		),
+	)
+}
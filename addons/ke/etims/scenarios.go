@@ -0,0 +1,27 @@
+package etims
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+)
+
+var scenarios = []*tax.ScenarioSet{
+	{
+		Schema: bill.ShortSchemaInvoice,
+		List: []*tax.Scenario{
+			{
+				Types: []cbc.Key{bill.InvoiceTypeStandard},
+				Ext: tax.Extensions{
+					ExtKeyReceiptType: ReceiptTypeSale,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeCreditNote},
+				Ext: tax.Extensions{
+					ExtKeyReceiptType: ReceiptTypeRefund,
+				},
+			},
+		},
+	},
+}
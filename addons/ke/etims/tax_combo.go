@@ -0,0 +1,49 @@
+package etims
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+var taxTypeKeyMap = map[cbc.Key]cbc.Code{
+	tax.KeyZero:         TaxTypeZero,
+	tax.KeyExport:       TaxTypeZero,
+	tax.KeyExempt:       TaxTypeExempt,
+	tax.KeyOutsideScope: TaxTypeNonVAT,
+}
+
+var taxTypeRateMap = map[cbc.Key]cbc.Code{
+	tax.RateGeneral: TaxTypeGeneral,
+	tax.RateReduced: TaxTypeReduced,
+}
+
+// normalizeTaxCombo sets the eTIMS tax type from the combo's key, or rate
+// for standard supplies.
+func normalizeTaxCombo(tc *tax.Combo) {
+	if tc == nil || tc.Category != tax.CategoryVAT {
+		return
+	}
+	if code, ok := taxTypeKeyMap[tc.Key]; ok {
+		tc.Ext = tc.Ext.Set(ExtKeyTaxType, code)
+		return
+	}
+	if tc.Key != cbc.KeyEmpty && tc.Key != tax.KeyStandard {
+		return
+	}
+	if code, ok := taxTypeRateMap[tc.Rate]; ok {
+		tc.Ext = tc.Ext.Set(ExtKeyTaxType, code)
+	}
+}
+
+func validateTaxCombo(tc *tax.Combo) error {
+	if tc == nil || tc.Category != tax.CategoryVAT {
+		return nil
+	}
+	return validation.ValidateStruct(tc,
+		validation.Field(&tc.Ext,
+			tax.ExtensionsRequire(ExtKeyTaxType),
+			validation.Skip,
+		),
+	)
+}
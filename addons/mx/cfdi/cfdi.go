@@ -30,7 +30,8 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddon())
+	tax.RegisterAddonDefLoader(V4, newAddon)
+	pay.RegisterMeansCodes(ExtKeyPaymentMeans, paymentMeansCodes())
 
 	// TODO: rename complements to use cfdi in schema path.
 	schema.Register(schema.GOBL.Add("regimes/mx"),
@@ -357,6 +357,9 @@ func TestPrecedingValidation(t *testing.T) {
 	assertValidationError(t, inv, "preceding: (0: (stamps: missing sat-uuid stamp.).)")
 
 	inv.Preceding[0].Stamps[0].Provider = "sat-uuid"
+	assertValidationError(t, inv, "preceding: (0: (stamps: (0: (val: invalid format for 'sat-uuid'.).).).)")
+
+	inv.Preceding[0].Stamps[0].Value = "1fac4464-1111-0000-1111-cd37179db12e"
 	require.NoError(t, inv.Validate())
 }
 
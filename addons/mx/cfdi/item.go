@@ -4,6 +4,7 @@ import (
 	"regexp"
 
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/validation"
@@ -15,6 +16,13 @@ var (
 	itemExtensionNormalizableCodeRegexp = regexp.MustCompile(`^\d{6}$`)
 )
 
+// ErrProdServDigits is used when the product or service code does not
+// have the expected 8 digits.
+var ErrProdServDigits = i18n.NewError("validation_cfdi_prod_serv_digits", i18n.String{
+	i18n.EN: "must have 8 digits",
+	i18n.ES: "debe tener 8 dígitos",
+})
+
 func validItemExtensions(value interface{}) error {
 	ext, ok := value.(tax.Extensions)
 	if !ok {
@@ -26,7 +34,7 @@ func validItemExtensions(value interface{}) error {
 				return nil
 			}
 			return validation.Errors{
-				k.String(): validation.NewError("invalid", "must have 8 digits"),
+				k.String(): ErrProdServDigits,
 			}
 		}
 	}
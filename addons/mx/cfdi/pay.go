@@ -1,6 +1,8 @@
 package cfdi
 
 import (
+	"sort"
+
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
@@ -56,6 +58,19 @@ var paymentMeansKeyMap = tax.Extensions{
 	pay.MeansKeyOther.With(MeansKeyIntermediary):    "31",
 }
 
+// paymentMeansCodes provides the key map as an ordered list of payment
+// means codes so that converters can look up the CFDI FormaPago codes.
+func paymentMeansCodes() pay.MeansCodes {
+	list := make(pay.MeansCodes, 0, len(paymentMeansKeyMap))
+	for k, c := range paymentMeansKeyMap {
+		list = append(list, &pay.MeansCode{Key: k, Code: c})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
 func normalizePayInstructions(instr *pay.Instructions) {
 	if instr == nil {
 		return
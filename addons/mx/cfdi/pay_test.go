@@ -14,6 +14,13 @@ func TestPaymentMeansExtensions(t *testing.T) {
 	assert.Equal(t, "01", ext.Get(pay.MeansKeyCash).String())
 }
 
+func TestPaymentMeansCodes(t *testing.T) {
+	list := pay.MeansCodesFor(cfdi.ExtKeyPaymentMeans)
+	assert.Len(t, list, len(cfdi.PaymentMeansExtensions()))
+	assert.Equal(t, "05", list.Code(pay.MeansKeyOnline.With(cfdi.MeansKeyWallet)).String())
+	assert.Equal(t, pay.MeansKeyCash, list.Key("01"))
+}
+
 func TestNormalizePayInstructions(t *testing.T) {
 	ad := tax.AddonForKey(cfdi.V4)
 
@@ -0,0 +1,39 @@
+package ehf
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/addons/internal/bis"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/validation"
+)
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Supplier,
+			validation.By(validateOrgNumber),
+			validation.By(bis.ValidateParty),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.By(validateOrgNumber),
+			validation.By(bis.ValidateParty),
+			validation.Skip,
+		),
+		validation.Field(&inv.Ordering,
+			validation.By(bis.ValidateOrdering),
+			validation.Skip,
+		),
+	)
+}
+
+// validateOrgNumber ensures Norwegian parties provide their organisation
+// number, which EHF uses as the legal entity identifier (NO-R-001).
+func validateOrgNumber(value any) error {
+	p, _ := value.(*org.Party)
+	if isNorwegian(p) && !hasOrgNumber(p) {
+		return errors.New("organisation number required in tax ID or identities")
+	}
+	return nil
+}
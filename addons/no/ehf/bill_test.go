@@ -0,0 +1,141 @@
+package ehf_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/no/ehf"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/no"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Addons:   tax.WithAddons(ehf.V3),
+		Currency: "NOK",
+		Series:   "2024",
+		Code:     "1000",
+		Ordering: &bill.Ordering{
+			Code: "PO-0042",
+		},
+		Supplier: &org.Party{
+			Name: "Leverandør AS",
+			TaxID: &tax.Identity{
+				Country: "NO",
+				Code:    "974760673",
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Karl Johans gate 1",
+					Locality: "Oslo",
+					Code:     "0154",
+					Country:  "NO",
+				},
+			},
+		},
+		Customer: &org.Party{
+			Name: "Digitaliseringsdirektoratet",
+			TaxID: &tax.Identity{
+				Country: "NO",
+			},
+			Identities: []*org.Identity{
+				{
+					Type: no.IdentityTypeOrgNr,
+					Code: "991 825 827",
+				},
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Postboks 1382 Vika",
+					Locality: "Oslo",
+					Code:     "0114",
+					Country:  "NO",
+				},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(10, 0),
+				Item: &org.Item{
+					Name:  "Konsulenttjenester",
+					Price: num.NewAmount(120000, 2),
+					Unit:  "h",
+				},
+				Taxes: tax.Set{
+					{
+						Category: tax.CategoryVAT,
+						Rate:     tax.RateGeneral,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceValidation(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		id := inv.Customer.Identities[0]
+		assert.Equal(t, "991825827", id.Code.String())
+		assert.Equal(t, ehf.SchemeOrgNumber, id.Ext[iso.ExtKeySchemeID])
+		assert.Equal(t, "0192:991825827", inv.Customer.Endpoint.String())
+	})
+
+	t.Run("invalid organisation number", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.Identities[0].Code = "991825828"
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "identities: (0: (code: must be a valid code for scheme 0192.).)")
+	})
+
+	t.Run("missing organisation number", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.Identities = nil
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "customer: organisation number required in tax ID or identities")
+	})
+
+	t.Run("foreign customer", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "DE", Code: "282741168"}
+		inv.Customer.Identities = nil
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+		assert.Equal(t, "9930:DE282741168", inv.Customer.Endpoint.String())
+	})
+
+	t.Run("missing electronic address", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "SE", Code: "556036079301"}
+		inv.Customer.Identities = nil
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "customer: electronic address required")
+
+		inv.Customer.Inboxes = []*org.Inbox{
+			{Key: org.InboxKeyPeppol, Code: "0007:5560360793"},
+		}
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+	})
+
+	t.Run("missing buyer reference", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Ordering = nil
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "ordering: buyer reference code or purchase order required")
+
+		inv.Ordering = &bill.Ordering{
+			Purchases: []*org.DocumentRef{{Code: "PO-0042"}},
+		}
+		assert.NoError(t, inv.Validate())
+	})
+}
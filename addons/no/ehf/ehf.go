@@ -0,0 +1,91 @@
+// Package ehf provides the Norwegian national rules for EHF Billing 3.0
+// invoices, based on Peppol BIS Billing 3.0.
+package ehf
+
+import (
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V3 is the key for the Norwegian EHF Billing 3.0 rules.
+	V3 cbc.Key = "no-ehf-v3"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V3, newAddon)
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V3,
+		Name: i18n.String{
+			i18n.EN: "Norway EHF Billing 3.0",
+			i18n.NB: "Norge EHF Billing 3.0",
+		},
+		Requires: []cbc.Key{
+			en16931.V2017,
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the Norwegian rules applied to EHF Billing 3.0 invoices, the
+				Peppol BIS Billing 3.0 profile required to send invoices to the Norwegian
+				public sector and widely used between Norwegian businesses.
+
+				This addon builds on the EN 16931 addon and covers:
+
+				- Norwegian organisation numbers (organisasjonsnummer) in party identities,
+				  normalized and assigned the "0192" ISO 6523 scheme used in Peppol.
+				- Norwegian suppliers and customers must be identified with their
+				  organisation number, either in the tax ID or as an identity.
+				- Suppliers and customers must provide an electronic address. When missing,
+				  the party's endpoint is set from the organisation number, or the VAT
+				  number for parties from other countries.
+				- Invoices must include a buyer reference or purchase order number, which
+				  Norwegian public sector buyers require before accepting an invoice.
+
+				The "Foretaksregisteret" legal note required for suppliers registered in the
+				Register of Business Enterprises is handled by the Norwegian tax regime.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "Peppol BIS Billing 3.0 - Norwegian rules",
+				},
+				URL: "https://docs.peppol.eu/poacc/billing/3.0/rules/ubl-peppol/",
+			},
+			{
+				Title: i18n.String{
+					i18n.EN: "EHF Billing 3.0 | Anskaffelser.no",
+					i18n.NB: "EHF Billing 3.0 | Anskaffelser.no",
+				},
+				URL: "https://anskaffelser.dev/postaward/g3/",
+			},
+		},
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *org.Party:
+		normalizeParty(obj)
+	case *org.Identity:
+		normalizeOrgIdentity(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	}
+	return nil
+}
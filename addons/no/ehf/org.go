@@ -0,0 +1,64 @@
+package ehf
+
+import (
+	"github.com/invopop/gobl/addons/internal/bis"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/no"
+	"github.com/invopop/gobl/tax"
+)
+
+// SchemeOrgNumber is the ISO 6523 scheme used for Norwegian organisation
+// numbers.
+const SchemeOrgNumber cbc.Code = "0192"
+
+// normalizeOrgIdentity ensures organisation numbers use the Peppol scheme.
+// Validation of the check digit is then performed by the ISO scheme.
+func normalizeOrgIdentity(id *org.Identity) {
+	if id == nil {
+		return
+	}
+	if id.Type != no.IdentityTypeOrgNr && id.Ext.Get(iso.ExtKeySchemeID) != SchemeOrgNumber {
+		return
+	}
+	id.Code = cbc.NormalizeNumericalCode(id.Code)
+	id.Ext = id.Ext.Merge(tax.Extensions{
+		iso.ExtKeySchemeID: SchemeOrgNumber,
+	})
+}
+
+// isNorwegian returns true if the party has a Norwegian tax ID.
+func isNorwegian(p *org.Party) bool {
+	return p != nil && p.TaxID != nil && p.TaxID.Country == l10n.NO.Tax()
+}
+
+// hasOrgNumber returns true if the party provides an organisation number,
+// either as the tax ID code or in the list of identities.
+func hasOrgNumber(p *org.Party) bool {
+	if p.TaxID.Code != cbc.CodeEmpty {
+		return true
+	}
+	for _, id := range p.Identities {
+		if id == nil || id.Code == cbc.CodeEmpty {
+			continue
+		}
+		if id.Type == no.IdentityTypeOrgNr || id.Ext.Get(iso.ExtKeySchemeID) == SchemeOrgNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeParty assigns a default endpoint to parties without an electronic
+// address, preferring the organisation number.
+func normalizeParty(p *org.Party) {
+	if p == nil {
+		return
+	}
+	for _, id := range p.Identities {
+		normalizeOrgIdentity(id)
+	}
+	bis.NormalizeEndpoint(p, l10n.NO.Tax(), SchemeOrgNumber)
+}
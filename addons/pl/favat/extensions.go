@@ -3,6 +3,7 @@ package favat
 import (
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
 )
 
 // Regime extension codes for local electronic formats.
@@ -12,9 +13,64 @@ const (
 	ExtKeyEffectiveDate cbc.Key = "pl-favat-effective-date"
 	ExtKeyPaymentMeans  cbc.Key = "pl-favat-payment-means" // for mapping to TFormaPlatnosci's codes
 	ExtKeyInvoiceType   cbc.Key = "pl-favat-invoice-type"  // for mapping to TRodzajFaktury's codes
+	ExtKeyExemption     cbc.Key = "pl-favat-exemption"     // for mapping to P_19A, P_19B, or P_19C
 )
 
 var extensionKeys = []*cbc.Definition{
+	{
+		Key: ExtKeyExemption,
+		Name: i18n.String{
+			i18n.EN: "VAT Exemption Basis",
+			i18n.PL: "Podstawa zwolnienia z VAT",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Type of legal basis for an exempt supply, which determines the FA(2) field
+				used to report it. The text of the provision itself must be included in
+				a legal note with the "pl-favat-exemption" source.
+			`),
+			i18n.PL: here.Doc(`
+				Rodzaj podstawy prawnej zwolnienia, określający pole FA(2) w którym jest
+				ona wskazywana. Treść przepisu należy podać w nocie prawnej ze źródłem
+				"pl-favat-exemption".
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: "A",
+				Name: i18n.String{
+					i18n.EN: "Polish VAT Act",
+					i18n.PL: "Ustawa o VAT",
+				},
+				Desc: i18n.String{
+					i18n.EN: "Provision of the VAT Act or secondary legislation (P_19A).",
+					i18n.PL: "Przepis ustawy albo aktu wydanego na podstawie ustawy (P_19A).",
+				},
+			},
+			{
+				Code: "B",
+				Name: i18n.String{
+					i18n.EN: "VAT Directive",
+					i18n.PL: "Dyrektywa VAT",
+				},
+				Desc: i18n.String{
+					i18n.EN: "Provision of Directive 2006/112/EC (P_19B).",
+					i18n.PL: "Przepis dyrektywy 2006/112/WE (P_19B).",
+				},
+			},
+			{
+				Code: "C",
+				Name: i18n.String{
+					i18n.EN: "Other Legal Basis",
+					i18n.PL: "Inna podstawa prawna",
+				},
+				Desc: i18n.String{
+					i18n.EN: "Other legal basis for the exemption (P_19C).",
+					i18n.PL: "Inna podstawa prawna zwolnienia (P_19C).",
+				},
+			},
+		},
+	},
 	{
 		Key: ExtKeyVATSpecial,
 		Name: i18n.String{
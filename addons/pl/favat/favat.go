@@ -4,6 +4,7 @@ package favat
 import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
@@ -23,7 +24,15 @@ const (
 )
 
 func init() {
-	tax.RegisterAddonDef(newAddonV2())
+	tax.RegisterAddonDefLoader(V2, newAddonV2)
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key: StampID,
+		Name: i18n.String{
+			i18n.EN: "KSeF Number",
+			i18n.PL: "Numer KSeF",
+		},
+		Pattern: `^\d{10}-\d{8}-[0-9A-F]{12}-[0-9A-F]{2}$`,
+	})
 	// V3 coming soon...
 }
 
@@ -46,6 +55,8 @@ func newAddonV2() *tax.AddonDef {
 
 func normalize(doc any) {
 	switch obj := doc.(type) {
+	case *tax.Combo:
+		normalizeTaxCombo(obj)
 	case *pay.Instructions:
 		normalizePayInstructions(obj)
 	case *pay.Advance:
@@ -57,6 +68,8 @@ func validate(doc any) error {
 	switch obj := doc.(type) {
 	case *bill.Invoice:
 		return validateInvoice(obj)
+	case *tax.Combo:
+		return validateTaxCombo(obj)
 	case *pay.Instructions:
 		return validatePayInstructions(obj)
 	case *pay.Advance:
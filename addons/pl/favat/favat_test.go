@@ -0,0 +1,16 @@
+package favat_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/pl/favat"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampFormat(t *testing.T) {
+	s := &head.Stamp{Provider: favat.StampID, Value: "5265877635-20230921-0100A0B3B1C0-5C"}
+	assert.NoError(t, s.Validate())
+	s.Value = "5265877635-2023"
+	assert.ErrorContains(t, s.Validate(), "val: invalid format")
+}
@@ -1,6 +1,8 @@
 package favat
 
 import (
+	"errors"
+
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
@@ -48,9 +50,46 @@ func (v *invoiceValidator) validate() error {
 			),
 			validation.Skip,
 		),
+		validation.Field(&inv.Notes,
+			validation.When(
+				v.hasExemptions(),
+				validation.Required.Error(errExemptionNote.Error()),
+				validation.By(v.exemptionNote),
+			),
+			validation.Skip,
+		),
 	)
 }
 
+var errExemptionNote = errors.New("legal note with exemption basis required")
+
+// hasExemptions returns true if any line applies an exempt VAT rate.
+func (v *invoiceValidator) hasExemptions() bool {
+	for _, l := range v.inv.Lines {
+		if l == nil {
+			continue
+		}
+		for _, tc := range l.Taxes {
+			if tc != nil && tc.Category == tax.CategoryVAT && tc.Key == tax.KeyExempt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exemptionNote ensures the legal basis for exemptions has been provided, as
+// FA(2) requires the text of the provision to be included.
+func (v *invoiceValidator) exemptionNote(value interface{}) error {
+	notes, _ := value.([]*org.Note)
+	for _, n := range notes {
+		if n != nil && n.Key == org.NoteKeyLegal && n.Src == ExtKeyExemption && n.Text != "" {
+			return nil
+		}
+	}
+	return errExemptionNote
+}
+
 func (v *invoiceValidator) supplier(value interface{}) error {
 	obj, _ := value.(*org.Party)
 	if obj == nil {
@@ -0,0 +1,30 @@
+package favat
+
+import (
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+func normalizeTaxCombo(tc *tax.Combo) {
+	if tc == nil || tc.Category != tax.CategoryVAT {
+		return
+	}
+	if tc.Key != tax.KeyExempt {
+		tc.Ext = tc.Ext.Delete(ExtKeyExemption)
+	}
+}
+
+func validateTaxCombo(tc *tax.Combo) error {
+	if tc == nil || tc.Category != tax.CategoryVAT {
+		return nil
+	}
+	return validation.ValidateStruct(tc,
+		validation.Field(&tc.Ext,
+			validation.When(
+				tc.Key == tax.KeyExempt,
+				tax.ExtensionsRequire(ExtKeyExemption),
+			),
+			validation.Skip,
+		),
+	)
+}
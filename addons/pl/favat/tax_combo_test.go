@@ -0,0 +1,70 @@
+package favat_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+
+	"github.com/invopop/gobl/addons/pl/favat"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxComboExemption(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		inv := creditNote()
+		inv.Preceding[0].Reason = "Correcting an error"
+		inv.Lines[0].Taxes = tax.Set{
+			{
+				Category: tax.CategoryVAT,
+				Key:      tax.KeyExempt,
+				Ext: tax.Extensions{
+					favat.ExtKeyExemption: "A",
+				},
+			},
+		}
+		inv.Notes = []*org.Note{
+			{
+				Key:  org.NoteKeyLegal,
+				Src:  favat.ExtKeyExemption,
+				Text: "Art. 43 ust. 1 pkt 37 ustawy o VAT",
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+	})
+
+	t.Run("missing extension and note", func(t *testing.T) {
+		inv := creditNote()
+		inv.Preceding[0].Reason = "Correcting an error"
+		inv.Lines[0].Taxes = tax.Set{
+			{Category: tax.CategoryVAT, Key: tax.KeyExempt},
+		}
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "lines: (0: (taxes: (0: (ext: (pl-favat-exemption: required.).).).).)")
+
+		inv.Lines[0].Taxes[0].Ext = tax.Extensions{favat.ExtKeyExemption: "B"}
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "notes: legal note with exemption basis required")
+	})
+
+	t.Run("removed when not exempt", func(t *testing.T) {
+		inv := creditNote()
+		inv.Preceding[0].Reason = "Correcting an error"
+		inv.Lines[0].Taxes = tax.Set{
+			{
+				Category: tax.CategoryVAT,
+				Rate:     tax.RateGeneral,
+				Ext: tax.Extensions{
+					favat.ExtKeyExemption: "A",
+				},
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		assert.False(t, inv.Lines[0].Taxes[0].Ext.Has(favat.ExtKeyExemption))
+		assert.NoError(t, inv.Validate())
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
@@ -38,6 +39,22 @@ var invoiceWorkTypes = []cbc.Code{
 	WorkTypeConsignmentCredit,
 }
 
+// Validation errors with stable codes and messages in English and Portuguese.
+var (
+	// ErrDocTypeMissing is used when none of the document type extensions
+	// `a` or `b` have been set.
+	ErrDocTypeMissing = i18n.NewError("validation_saft_doc_type_missing", i18n.String{
+		i18n.EN: "either `{{.a}}` or `{{.b}}` must be set",
+		i18n.PT: "deve ser definido `{{.a}}` ou `{{.b}}`",
+	})
+	// ErrDocTypeBoth is used when both document type extensions `a` and
+	// `b` have been set.
+	ErrDocTypeBoth = i18n.NewError("validation_saft_doc_type_both", i18n.String{
+		i18n.EN: "either `{{.a}}` or `{{.b}}` must be set, but not both",
+		i18n.PT: "deve ser definido `{{.a}}` ou `{{.b}}`, mas não ambos",
+	})
+)
+
 func normalizeInvoice(inv *bill.Invoice) {
 	if inv == nil {
 		return
@@ -217,14 +234,14 @@ func validateDocType(val any) error {
 		ext = make(tax.Extensions) // Empty temporary map to return meaningful errors
 	}
 
-	msg := fmt.Sprintf("either `%s` or `%s` must be set", ExtKeyWorkType, ExtKeyInvoiceType)
+	params := map[string]any{"a": ExtKeyWorkType, "b": ExtKeyInvoiceType}
 
 	if !ext.Has(ExtKeyWorkType) && !ext.Has(ExtKeyInvoiceType) {
-		return validation.NewError("invalid", msg)
+		return ErrDocTypeMissing.WithParams(params)
 	}
 
 	if ext.Has(ExtKeyWorkType, ExtKeyInvoiceType) {
-		return validation.NewError("invalid", msg+", but not both")
+		return ErrDocTypeBoth.WithParams(params)
 	}
 
 	if wt, ok := ext[ExtKeyWorkType]; ok {
@@ -0,0 +1,115 @@
+package efactura
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/validation"
+)
+
+// CountyBucharest is the code used for the municipality of Bucharest, where
+// the sector must be provided as the locality.
+const CountyBucharest cbc.Code = "B"
+
+// Counties contains the ISO 3166-2:RO subdivision codes, without the
+// country prefix, expected in the state field of Romanian addresses.
+var Counties = map[cbc.Code]string{
+	"AB": "Alba",
+	"AG": "Argeș",
+	"AR": "Arad",
+	"B":  "București",
+	"BC": "Bacău",
+	"BH": "Bihor",
+	"BN": "Bistrița-Năsăud",
+	"BR": "Brăila",
+	"BT": "Botoșani",
+	"BV": "Brașov",
+	"BZ": "Buzău",
+	"CJ": "Cluj",
+	"CL": "Călărași",
+	"CS": "Caraș-Severin",
+	"CT": "Constanța",
+	"CV": "Covasna",
+	"DB": "Dâmbovița",
+	"DJ": "Dolj",
+	"GJ": "Gorj",
+	"GL": "Galați",
+	"GR": "Giurgiu",
+	"HD": "Hunedoara",
+	"HR": "Harghita",
+	"IF": "Ilfov",
+	"IL": "Ialomița",
+	"IS": "Iași",
+	"MH": "Mehedinți",
+	"MM": "Maramureș",
+	"MS": "Mureș",
+	"NT": "Neamț",
+	"OT": "Olt",
+	"PH": "Prahova",
+	"SB": "Sibiu",
+	"SJ": "Sălaj",
+	"SM": "Satu Mare",
+	"SV": "Suceava",
+	"TL": "Tulcea",
+	"TM": "Timiș",
+	"TR": "Teleorman",
+	"VL": "Vâlcea",
+	"VN": "Vrancea",
+	"VS": "Vaslui",
+}
+
+var (
+	bucharestSectorRegexp     = regexp.MustCompile(`^SECTOR[1-6]$`)
+	bucharestSectorNormRegexp = regexp.MustCompile(`(?i)^\s*sector\s*([1-6])\s*$`)
+)
+
+func normalizeAddress(addr *org.Address) {
+	if addr == nil || addr.Country != l10n.RO.ISO() {
+		return
+	}
+	// remove the country prefix from ISO 3166-2 codes, which will already
+	// have lost the separator, e.g. "RO-CJ" will be "ROCJ".
+	if s := strings.TrimPrefix(addr.State.String(), "RO"); s != addr.State.String() {
+		if _, ok := Counties[cbc.Code(s)]; ok {
+			addr.State = cbc.Code(s)
+		}
+	}
+	if addr.State == CountyBucharest {
+		addr.Locality = bucharestSectorNormRegexp.ReplaceAllString(addr.Locality, "SECTOR$1")
+	}
+}
+
+func validateAddress(value any) error {
+	addr, ok := value.(*org.Address)
+	if !ok || addr == nil || addr.Country != l10n.RO.ISO() {
+		return nil
+	}
+	return validation.ValidateStruct(addr,
+		validation.Field(&addr.State,
+			validation.Required,
+			validation.By(validateCounty),
+		),
+		validation.Field(&addr.Locality,
+			validation.Required,
+			validation.When(
+				addr.State == CountyBucharest,
+				validation.Match(bucharestSectorRegexp).Error("must be a Bucharest sector from SECTOR1 to SECTOR6"),
+			),
+		),
+	)
+}
+
+func validateCounty(value any) error {
+	code, _ := value.(cbc.Code)
+	if code == cbc.CodeEmpty {
+		return nil
+	}
+	if _, ok := Counties[code]; !ok {
+		return errors.New("must be a valid county code")
+	}
+	return nil
+}
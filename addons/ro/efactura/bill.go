@@ -0,0 +1,139 @@
+package efactura
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// maxNotes is the maximum number of notes allowed by BR-RO-A020.
+const maxNotes = 20
+
+// documentTypes lists the UNTDID 1001 codes accepted by CIUS-RO (BR-RO-020).
+var documentTypes = []cbc.Code{
+	"380", // Commercial invoice
+	"381", // Credit note
+	"384", // Corrected invoice
+	"389", // Self-billed invoice
+	"751", // Invoice information for accounting purposes
+}
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Tax,
+			validation.By(validateInvoiceTax),
+			validation.Skip,
+		),
+		validation.Field(&inv.ExchangeRates,
+			validation.When(
+				inv.Currency != currency.RON,
+				validation.By(validateExchangeRates(inv.Currency)),
+			),
+			validation.Skip,
+		),
+		validation.Field(&inv.Supplier,
+			validation.By(validateSupplier),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.By(validateCustomer),
+			validation.Skip,
+		),
+		validation.Field(&inv.Notes,
+			validation.Length(0, maxNotes),
+			validation.Skip,
+		),
+	)
+}
+
+func validateInvoiceTax(value any) error {
+	t, ok := value.(*bill.Tax)
+	if !ok || t == nil {
+		return nil
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Ext,
+			tax.ExtensionsHasCodes(untdid.ExtKeyDocumentType, documentTypes...),
+			validation.Skip,
+		),
+	)
+}
+
+// validateExchangeRates ensures a rate to RON is available when invoicing
+// in other currencies, as the VAT amounts must also be reported in RON
+// (BR-RO-030).
+func validateExchangeRates(cur currency.Code) func(value any) error {
+	return func(value any) error {
+		rates, _ := value.([]*currency.ExchangeRate)
+		for _, r := range rates {
+			if r != nil && r.From == cur && r.To == currency.RON {
+				return nil
+			}
+		}
+		return errors.New("must include rate from invoice currency to RON")
+	}
+}
+
+func validateSupplier(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	ro := isRomanian(p)
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TaxID,
+			validation.When(
+				ro,
+				tax.RequireIdentityCode,
+				validation.By(validateTaxIdentity),
+			),
+			validation.Skip,
+		),
+		validation.Field(&p.Addresses,
+			validation.Required,
+			validation.Each(validation.By(validateAddress)),
+			validation.Skip,
+		),
+	)
+}
+
+func validateCustomer(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TaxID,
+			validation.When(
+				isRomanian(p),
+				validation.By(validateTaxIdentity),
+			),
+			validation.Skip,
+		),
+		validation.Field(&p.Addresses,
+			validation.Each(validation.By(validateAddress)),
+			validation.Skip,
+		),
+	)
+}
+
+func validateTaxIdentity(value any) error {
+	tID, ok := value.(*tax.Identity)
+	if !ok || tID == nil {
+		return nil
+	}
+	return validation.ValidateStruct(tID,
+		validation.Field(&tID.Code, validation.By(validateCUI)),
+	)
+}
+
+func isRomanian(p *org.Party) bool {
+	return p.TaxID != nil && p.TaxID.Country == l10n.RO.Tax()
+}
@@ -0,0 +1,164 @@
+package efactura_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/ro/efactura"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Addons:    tax.WithAddons(efactura.V1),
+		IssueDate: cal.MakeDate(2024, 6, 1),
+		Currency:  currency.RON,
+		Series:    "RO",
+		Code:      "0001",
+		Supplier: &org.Party{
+			Name: "Furnizor SRL",
+			TaxID: &tax.Identity{
+				Country: "RO",
+				Code:    "RO14399840",
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Strada Principală 10",
+					Locality: "Sector 3",
+					State:    "ro-b",
+					Code:     "030000",
+					Country:  "RO",
+				},
+			},
+		},
+		Customer: &org.Party{
+			Name: "Client SRL",
+			TaxID: &tax.Identity{
+				Country: "RO",
+				Code:    "18547290",
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Strada Memorandumului 28",
+					Locality: "Cluj-Napoca",
+					State:    "CJ",
+					Code:     "400114",
+					Country:  "RO",
+				},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Servicii",
+					Price: num.NewAmount(10000, 2),
+					Unit:  org.UnitPackage,
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Percent: num.NewPercentage(19, 2)},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceValidation(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		addr := inv.Supplier.Addresses[0]
+		assert.Equal(t, efactura.CountyBucharest, addr.State)
+		assert.Equal(t, "SECTOR3", addr.Locality)
+		assert.Equal(t, "14399840", inv.Supplier.TaxID.Code.String())
+	})
+
+	t.Run("invalid sector", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Supplier.Addresses[0].Locality = "București"
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "supplier: (addresses: (0: (locality: must be a Bucharest sector from SECTOR1 to SECTOR6.).).)")
+	})
+
+	t.Run("invalid county", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.Addresses[0].State = "XX"
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "customer: (addresses: (0: (state: must be a valid county code.).).)")
+
+		inv.Customer.Addresses[0].State = ""
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "customer: (addresses: (0: (state: cannot be blank.).).)")
+	})
+
+	t.Run("foreign customer address", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "DE", Code: "505898911"}
+		inv.Customer.Addresses[0].State = ""
+		inv.Customer.Addresses[0].Country = "DE"
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("invalid CUI", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Supplier.TaxID.Code = "14399841"
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "supplier: (tax_id: (code: invalid CUI check digit.).)")
+
+		inv.Supplier.TaxID.Code = "0123"
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "supplier: (tax_id: (code: must be a CUI with 2 to 10 digits.).)")
+
+		inv.Supplier.TaxID.Code = ""
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "supplier: (tax_id: (code: cannot be blank.).)")
+	})
+
+	t.Run("document type", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		inv.Tax.Ext[untdid.ExtKeyDocumentType] = "326"
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "tax: (ext: (untdid-document-type: invalid value.).)")
+	})
+
+	t.Run("foreign currency", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Currency = currency.EUR
+		inv.ExchangeRates = []*currency.ExchangeRate{
+			{From: currency.RON, To: currency.EUR, Amount: num.MakeAmount(201, 3)},
+		}
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "exchange_rates: must include rate from invoice currency to RON")
+
+		inv.ExchangeRates = append(inv.ExchangeRates, &currency.ExchangeRate{
+			From: currency.EUR, To: currency.RON, Amount: num.MakeAmount(4977, 3),
+		})
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("too many notes", func(t *testing.T) {
+		inv := testInvoice(t)
+		for range 21 {
+			inv.Notes = append(inv.Notes, &org.Note{Text: "Note"})
+		}
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "notes: the length must be no more than 20")
+	})
+}
@@ -0,0 +1,81 @@
+// Package efactura provides the validations required by the Romanian CIUS-RO
+// specification for the RO e-Factura electronic invoicing system.
+package efactura
+
+import (
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V1 is the key for the CIUS-RO 1.0.x specification.
+	V1 cbc.Key = "ro-efactura-v1"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V1, newAddon)
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V1,
+		Name: i18n.String{
+			i18n.EN: "Romania e-Factura (CIUS-RO)",
+			i18n.RO: "România e-Factura (CIUS-RO)",
+		},
+		Requires: []cbc.Key{
+			en16931.V2017,
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the Romanian CIUS-RO specification, used to issue invoices through
+				the national RO e-Factura system managed by ANAF. CIUS-RO extends the European
+				EN 16931 standard with additional business rules, including:
+
+				- Romanian addresses must include the county code in the "state" field, using
+				  the ISO 3166-2:RO codes without the "RO-" prefix, e.g. "CJ" for Cluj.
+				- Addresses in Bucharest ("B") must use the sector as the locality, from
+				  "SECTOR1" to "SECTOR6".
+				- Romanian suppliers must provide a valid CUI (fiscal identification code)
+				  in their tax ID.
+				- Only a restricted set of UNTDID 1001 document types may be used.
+			`),
+			i18n.RO: here.Doc(`
+				Suport pentru specificația română CIUS-RO, utilizată pentru emiterea facturilor
+				prin sistemul național RO e-Factura administrat de ANAF. CIUS-RO extinde
+				standardul european EN 16931 cu reguli de business suplimentare.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "RO e-Factura Technical Specifications",
+					i18n.RO: "Specificații tehnice RO e-Factura",
+				},
+				URL: "https://mfinante.gov.ro/ro/web/efactura/informatii-tehnice",
+			},
+		},
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *org.Address:
+		normalizeAddress(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package efactura
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/cbc"
+)
+
+// CUI codes contain between 2 and 10 digits, with the last one used as a
+// check digit.
+var cuiRegexp = regexp.MustCompile(`^[1-9]\d{1,9}$`)
+
+// cuiWeights are applied to the digits of the code, excluding the check
+// digit, padded with zeros to the left.
+var cuiWeights = []int{7, 5, 3, 2, 1, 7, 5, 3, 2}
+
+// validateCUI checks the format and check digit of a Romanian fiscal
+// identification code (Cod Unic de Înregistrare).
+func validateCUI(value any) error {
+	code, _ := value.(cbc.Code)
+	if code == cbc.CodeEmpty {
+		return nil
+	}
+	s := code.String()
+	if !cuiRegexp.MatchString(s) {
+		return errors.New("must be a CUI with 2 to 10 digits")
+	}
+	body := s[:len(s)-1]
+	offset := len(cuiWeights) - len(body)
+	sum := 0
+	for i, r := range body {
+		sum += int(r-'0') * cuiWeights[offset+i]
+	}
+	check := sum * 10 % 11
+	if check == 10 {
+		check = 0
+	}
+	if int(s[len(s)-1]-'0') != check {
+		return errors.New("invalid CUI check digit")
+	}
+	return nil
+}
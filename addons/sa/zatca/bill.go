@@ -0,0 +1,161 @@
+package zatca
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// MetaKeyPreviousHash is used in the invoice's tax meta data to store the
+// hash of the previous invoice issued, as required to chain documents.
+const MetaKeyPreviousHash cbc.Key = "zatca-pih"
+
+// InitialPreviousHash is the value to use as the previous invoice hash
+// for the first invoice issued, defined by ZATCA as the base64 encoded
+// hexadecimal SHA-256 hash of "0".
+const InitialPreviousHash = "NWZlY2ViNjZmZmM4NmYzOGQ5NTI3ODZjNmQ2OTZjNzljMmRiYzIzOWRkNGU5MWI0NjcyOWQ3M2EyN2ZiNTdlOQ=="
+
+// Invoice hashes are base64 encoded SHA-256 digests.
+const hashPattern = `^[A-Za-z0-9+/]{43}=$`
+
+var hashRegexp = regexp.MustCompile(hashPattern)
+
+func normalizeInvoice(inv *bill.Invoice) {
+	if inv.IssueTime == nil {
+		// empty time will be set to the current time during calculation
+		inv.IssueTime = new(cal.Time)
+	}
+	inv.Tax = inv.Tax.MergeExtensions(tax.Extensions{
+		ExtKeyInvoiceSubtype: invoiceSubtype(inv),
+	})
+}
+
+// invoiceSubtype determines the "NNPNESB" subtype code from the invoice's
+// tags and details.
+func invoiceSubtype(inv *bill.Invoice) cbc.Code {
+	code := []byte("0100000")
+	if inv.HasTags(tax.TagSimplified) {
+		code[1] = '2'
+	}
+	if inv.Ordering != nil && inv.Ordering.Issuer != nil {
+		code[2] = '1'
+	}
+	if inv.HasTags(TagNominal) {
+		code[3] = '1'
+	}
+	if inv.HasTags(tax.TagExport) || isForeignCustomer(inv.Customer) {
+		code[4] = '1'
+	}
+	if inv.HasTags(TagSummary) {
+		code[5] = '1'
+	}
+	if inv.HasTags(tax.TagSelfBilled) {
+		code[6] = '1'
+	}
+	return cbc.Code(code)
+}
+
+func isForeignCustomer(p *org.Party) bool {
+	return p != nil && p.TaxID != nil && p.TaxID.Country != "" && p.TaxID.Country != l10n.SA.Tax()
+}
+
+func validateInvoice(inv *bill.Invoice) error {
+	simplified := inv.HasTags(tax.TagSimplified)
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Type,
+			validation.In(
+				bill.InvoiceTypeStandard,
+				bill.InvoiceTypeCreditNote,
+				bill.InvoiceTypeDebitNote,
+			),
+		),
+		validation.Field(&inv.Code, validation.Required),
+		validation.Field(&inv.IssueTime, validation.Required),
+		validation.Field(&inv.Tags,
+			validation.When(
+				simplified && (inv.HasTags(tax.TagExport) || inv.HasTags(tax.TagSelfBilled)),
+				validation.By(func(_ any) error {
+					return errors.New("export and self-billed not supported for simplified invoices")
+				}),
+			),
+			validation.Skip,
+		),
+		validation.Field(&inv.Preceding,
+			validation.When(
+				inv.Type.In(bill.InvoiceTypeCreditNote, bill.InvoiceTypeDebitNote),
+				validation.Required,
+			),
+			validation.Skip,
+		),
+		validation.Field(&inv.Tax,
+			validation.Required,
+			validation.By(validateInvoiceTax),
+			validation.Skip,
+		),
+		validation.Field(&inv.Supplier,
+			validation.By(validateSupplier),
+			validation.Skip,
+		),
+		validation.Field(&inv.Customer,
+			validation.When(
+				!simplified,
+				validation.Required,
+			),
+			validation.Skip,
+		),
+	)
+}
+
+func validateInvoiceTax(value any) error {
+	t, ok := value.(*bill.Tax)
+	if !ok || t == nil {
+		return nil
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Ext,
+			tax.ExtensionsRequire(ExtKeyInvoiceType, ExtKeyInvoiceSubtype),
+			validation.Skip,
+		),
+		validation.Field(&t.Meta,
+			validation.By(validatePreviousHash),
+			validation.Skip,
+		),
+	)
+}
+
+func validatePreviousHash(value any) error {
+	meta, _ := value.(cbc.Meta)
+	pih, ok := meta[MetaKeyPreviousHash]
+	if !ok || pih == "" {
+		return validation.Errors{
+			MetaKeyPreviousHash.String(): errors.New("required"),
+		}
+	}
+	if pih != InitialPreviousHash && !hashRegexp.MatchString(pih) {
+		return validation.Errors{
+			MetaKeyPreviousHash.String(): errors.New("must be a base64 encoded SHA-256 hash"),
+		}
+	}
+	return nil
+}
+
+func validateSupplier(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TaxID,
+			validation.Required,
+			tax.RequireIdentityCode,
+			validation.Skip,
+		),
+	)
+}
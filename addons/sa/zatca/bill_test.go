@@ -0,0 +1,193 @@
+package zatca_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+
+	"github.com/invopop/gobl/addons/sa/zatca"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHash = "NzYzNjE3ZGQ5ZTRkMDRlYzEyNzdiYmE0ZGE2MjRkOTM="
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Regime:    tax.WithRegime("SA"),
+		Addons:    tax.WithAddons(zatca.V2),
+		Code:      "INV-001",
+		IssueDate: cal.MakeDate(2024, 3, 10),
+		IssueTime: cal.NewTime(14, 30, 0),
+		Currency:  "SAR",
+		Tax: &bill.Tax{
+			Meta: cbc.Meta{
+				zatca.MetaKeyPreviousHash: zatca.InitialPreviousHash,
+			},
+		},
+		Supplier: &org.Party{
+			Name: "شركة التوريدات",
+			TaxID: &tax.Identity{
+				Country: "SA",
+				Code:    "310122393500003",
+			},
+		},
+		Customer: &org.Party{
+			Name: "Test Customer",
+			TaxID: &tax.Identity{
+				Country: "SA",
+				Code:    "300000000000003",
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item: &org.Item{
+					Name:  "Consulting",
+					Price: num.NewAmount(10000, 2),
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(inv *bill.Invoice)
+		typ     cbc.Code
+		subtype cbc.Code
+	}{
+		{
+			name:    "standard",
+			prepare: func(_ *bill.Invoice) {},
+			typ:     zatca.InvoiceTypeStandard,
+			subtype: "0100000",
+		},
+		{
+			name: "simplified",
+			prepare: func(inv *bill.Invoice) {
+				inv.SetTags(tax.TagSimplified)
+				inv.Customer = nil
+			},
+			typ:     zatca.InvoiceTypeStandard,
+			subtype: "0200000",
+		},
+		{
+			name: "prepayment",
+			prepare: func(inv *bill.Invoice) {
+				inv.SetTags(tax.TagPartial)
+			},
+			typ:     zatca.InvoiceTypePrepayment,
+			subtype: "0100000",
+		},
+		{
+			name: "export",
+			prepare: func(inv *bill.Invoice) {
+				inv.Customer.TaxID = &tax.Identity{Country: "GB"}
+			},
+			typ:     zatca.InvoiceTypeStandard,
+			subtype: "0100100",
+		},
+		{
+			name: "flags",
+			prepare: func(inv *bill.Invoice) {
+				inv.SetTags(tax.TagSelfBilled, zatca.TagNominal, zatca.TagSummary)
+				inv.Ordering = &bill.Ordering{Issuer: &org.Party{Name: "Agent"}}
+			},
+			typ:     zatca.InvoiceTypeStandard,
+			subtype: "0111011",
+		},
+		{
+			name: "credit note",
+			prepare: func(inv *bill.Invoice) {
+				inv.Type = bill.InvoiceTypeCreditNote
+			},
+			typ:     zatca.InvoiceTypeCreditNote,
+			subtype: "0100000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := testInvoice(t)
+			tt.prepare(inv)
+			require.NoError(t, inv.Calculate())
+			assert.Equal(t, tt.typ, inv.Tax.Ext[zatca.ExtKeyInvoiceType])
+			assert.Equal(t, tt.subtype, inv.Tax.Ext[zatca.ExtKeyInvoiceSubtype])
+		})
+	}
+
+	t.Run("issue time", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.IssueTime = nil
+		require.NoError(t, inv.Calculate())
+		assert.NotNil(t, inv.IssueTime)
+		assert.False(t, inv.IssueTime.IsZero())
+	})
+}
+
+func TestInvoiceValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "230.00", inv.Totals.Payable.String())
+	})
+
+	t.Run("previous hash", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Tax.Meta[zatca.MetaKeyPreviousHash] = testHash
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+
+		inv.Tax.Meta[zatca.MetaKeyPreviousHash] = "invalid"
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "tax: (meta: (zatca-pih: must be a base64 encoded SHA-256 hash.).)")
+
+		delete(inv.Tax.Meta, zatca.MetaKeyPreviousHash)
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "tax: (meta: (zatca-pih: required.).)")
+	})
+
+	t.Run("missing customer", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer = nil
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "customer: cannot be blank")
+	})
+
+	t.Run("simplified export", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.SetTags(tax.TagSimplified, tax.TagExport)
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "export and self-billed not supported for simplified invoices")
+	})
+
+	t.Run("credit note without preceding", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeCreditNote
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "preceding: cannot be blank")
+	})
+
+	t.Run("missing code", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Code = ""
+		require.NoError(t, inv.Calculate())
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "code: cannot be blank")
+	})
+}
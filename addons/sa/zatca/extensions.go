@@ -0,0 +1,91 @@
+package zatca
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
+)
+
+// Extension keys used by ZATCA.
+const (
+	ExtKeyInvoiceType    cbc.Key = "sa-zatca-invoice-type"
+	ExtKeyInvoiceSubtype cbc.Key = "sa-zatca-invoice-subtype"
+)
+
+// Invoice type codes from the UNTDID 1001 list accepted by ZATCA.
+const (
+	InvoiceTypeStandard   cbc.Code = "388"
+	InvoiceTypePrepayment cbc.Code = "386"
+	InvoiceTypeCreditNote cbc.Code = "381"
+	InvoiceTypeDebitNote  cbc.Code = "383"
+)
+
+var extensions = []*cbc.Definition{
+	{
+		Key: ExtKeyInvoiceType,
+		Name: i18n.String{
+			i18n.EN: "Invoice Type Code",
+			i18n.AR: "رمز نوع الفاتورة",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				UNTDID 1001 code used in the UBL "InvoiceTypeCode" element, determined
+				automatically from the invoice type and tags.
+			`),
+		},
+		Values: []*cbc.Definition{
+			{
+				Code: InvoiceTypeStandard,
+				Name: i18n.String{
+					i18n.EN: "Tax Invoice",
+					i18n.AR: "فاتورة ضريبية",
+				},
+			},
+			{
+				Code: InvoiceTypePrepayment,
+				Name: i18n.String{
+					i18n.EN: "Prepayment Invoice",
+					i18n.AR: "فاتورة دفعة مقدمة",
+				},
+			},
+			{
+				Code: InvoiceTypeCreditNote,
+				Name: i18n.String{
+					i18n.EN: "Credit Note",
+					i18n.AR: "إشعار دائن",
+				},
+			},
+			{
+				Code: InvoiceTypeDebitNote,
+				Name: i18n.String{
+					i18n.EN: "Debit Note",
+					i18n.AR: "إشعار مدين",
+				},
+			},
+		},
+	},
+	{
+		Key: ExtKeyInvoiceSubtype,
+		Name: i18n.String{
+			i18n.EN: "Invoice Subtype",
+			i18n.AR: "النوع الفرعي للفاتورة",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Seven digit code used in the "name" attribute of the UBL "InvoiceTypeCode"
+				element, with the structure "NNPNESB":
+
+				- "NN": "01" for standard tax invoices, or "02" for simplified tax invoices.
+				- "P": third party invoice, issued on behalf of the supplier.
+				- "N": nominal supply.
+				- "E": exports.
+				- "S": summary invoice.
+				- "B": self-billed invoice.
+
+				Flags are set to "1" when they apply, or "0" otherwise. The code is
+				determined automatically from the invoice's tags and ordering details.
+			`),
+		},
+		Pattern: `^0[12][01]{5}$`,
+	},
+}
@@ -0,0 +1,98 @@
+package zatca
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+)
+
+// QR TLV tags defined by ZATCA.
+const (
+	qrTagSellerName byte = iota + 1
+	qrTagVATNumber
+	qrTagTimestamp
+	qrTagTotalWithTax
+	qrTagTax
+	qrTagHash
+	qrTagSignature
+	qrTagPublicKey
+	qrTagCertificateSignature
+)
+
+// QRSignature contains the cryptographic values added to the QR code in
+// Phase 2, once the invoice has been hashed and signed.
+type QRSignature struct {
+	// Hash of the invoice, base64 encoded.
+	Hash string
+	// Signature is the base64 encoded ECDSA signature of the hash.
+	Signature string
+	// PublicKey of the signing certificate in DER format.
+	PublicKey []byte
+	// CertificateSignature contains the signature of the certificate issued
+	// by ZATCA, required for simplified invoices only.
+	CertificateSignature []byte
+}
+
+// QR generates the base64 encoded TLV (Tag-Length-Value) payload to include
+// in the invoice's QR code from the supplier's details and the invoice's
+// totals. The signature details will be appended if provided.
+//
+// The invoice must have been calculated beforehand.
+func QR(inv *bill.Invoice, sig *QRSignature) (string, error) {
+	if inv == nil || inv.Totals == nil {
+		return "", errors.New("calculated invoice required")
+	}
+	if inv.Supplier == nil || inv.Supplier.TaxID == nil {
+		return "", errors.New("supplier tax ID required")
+	}
+	if inv.IssueTime == nil {
+		return "", errors.New("issue time required")
+	}
+	ts := fmt.Sprintf("%sT%s", inv.IssueDate, inv.IssueTime)
+	fields := []tlv{
+		{qrTagSellerName, []byte(inv.Supplier.Name)},
+		{qrTagVATNumber, []byte(inv.Supplier.TaxID.Code)},
+		{qrTagTimestamp, []byte(ts)},
+		{qrTagTotalWithTax, []byte(inv.Totals.TotalWithTax.String())},
+		{qrTagTax, []byte(inv.Totals.Tax.String())},
+	}
+	if sig != nil {
+		fields = append(fields,
+			tlv{qrTagHash, []byte(sig.Hash)},
+			tlv{qrTagSignature, []byte(sig.Signature)},
+			tlv{qrTagPublicKey, sig.PublicKey},
+		)
+		if len(sig.CertificateSignature) > 0 {
+			fields = append(fields, tlv{qrTagCertificateSignature, sig.CertificateSignature})
+		}
+	}
+	buf := new(bytes.Buffer)
+	for _, f := range fields {
+		if err := f.write(buf); err != nil {
+			return "", err
+		}
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// tlv is a single tag-length-value entry of the QR payload.
+type tlv struct {
+	tag   byte
+	value []byte
+}
+
+func (f tlv) write(buf *bytes.Buffer) error {
+	if len(f.value) == 0 {
+		return fmt.Errorf("tag %d: value required", f.tag)
+	}
+	if len(f.value) > 255 {
+		return fmt.Errorf("tag %d: value too long", f.tag)
+	}
+	buf.WriteByte(f.tag)
+	buf.WriteByte(byte(len(f.value)))
+	buf.Write(f.value)
+	return nil
+}
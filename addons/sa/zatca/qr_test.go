@@ -0,0 +1,76 @@
+package zatca_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/invopop/gobl/addons/sa/zatca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeTLV(t *testing.T, payload string) map[byte]string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(payload)
+	require.NoError(t, err)
+	out := make(map[byte]string)
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 2)
+		tag, l := data[0], int(data[1])
+		require.GreaterOrEqual(t, len(data), 2+l)
+		out[tag] = string(data[2 : 2+l])
+		data = data[2+l:]
+	}
+	return out
+}
+
+func TestQR(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		inv := testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		qr, err := zatca.QR(inv, nil)
+		require.NoError(t, err)
+		fields := decodeTLV(t, qr)
+		assert.Len(t, fields, 5)
+		assert.Equal(t, "شركة التوريدات", fields[1])
+		assert.Equal(t, "310122393500003", fields[2])
+		assert.Equal(t, "2024-03-10T14:30:00", fields[3])
+		assert.Equal(t, "230.00", fields[4])
+		assert.Equal(t, "30.00", fields[5])
+	})
+
+	t.Run("with signature", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.SetTags("simplified")
+		inv.Customer = nil
+		require.NoError(t, inv.Calculate())
+		qr, err := zatca.QR(inv, &zatca.QRSignature{
+			Hash:                 testHash,
+			Signature:            "MEUCIQD=",
+			PublicKey:            []byte{0x30, 0x56},
+			CertificateSignature: []byte{0x30, 0x45},
+		})
+		require.NoError(t, err)
+		fields := decodeTLV(t, qr)
+		assert.Len(t, fields, 9)
+		assert.Equal(t, testHash, fields[6])
+		assert.Equal(t, "MEUCIQD=", fields[7])
+		assert.Equal(t, "\x30\x56", fields[8])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		inv := testInvoice(t)
+		_, err := zatca.QR(inv, nil)
+		assert.ErrorContains(t, err, "calculated invoice required")
+
+		require.NoError(t, inv.Calculate())
+		inv.Supplier.Name = ""
+		_, err = zatca.QR(inv, nil)
+		assert.ErrorContains(t, err, "tag 1: value required")
+
+		inv = testInvoice(t)
+		require.NoError(t, inv.Calculate())
+		_, err = zatca.QR(inv, &zatca.QRSignature{Hash: testHash})
+		assert.ErrorContains(t, err, "tag 7: value required")
+	})
+}
@@ -0,0 +1,74 @@
+package zatca
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/tax"
+)
+
+// Invoice tag keys used to determine the invoice subtype.
+const (
+	TagNominal cbc.Key = "nominal"
+	TagSummary cbc.Key = "summary"
+)
+
+var invoiceTags = &tax.TagSet{
+	Schema: bill.ShortSchemaInvoice,
+	List: []*cbc.Definition{
+		{
+			Key: tax.TagExport,
+			Name: i18n.String{
+				i18n.EN: "Export",
+				i18n.AR: "تصدير",
+			},
+		},
+		{
+			Key: TagNominal,
+			Name: i18n.String{
+				i18n.EN: "Nominal Supply",
+				i18n.AR: "توريد اعتباري",
+			},
+		},
+		{
+			Key: TagSummary,
+			Name: i18n.String{
+				i18n.EN: "Summary Invoice",
+				i18n.AR: "فاتورة ملخصة",
+			},
+		},
+	},
+}
+
+var scenarios = []*tax.ScenarioSet{
+	{
+		Schema: bill.ShortSchemaInvoice,
+		List: []*tax.Scenario{
+			{
+				Types: []cbc.Key{bill.InvoiceTypeStandard},
+				Ext: tax.Extensions{
+					ExtKeyInvoiceType: InvoiceTypeStandard,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeStandard},
+				Tags:  []cbc.Key{tax.TagPartial},
+				Ext: tax.Extensions{
+					ExtKeyInvoiceType: InvoiceTypePrepayment,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeCreditNote},
+				Ext: tax.Extensions{
+					ExtKeyInvoiceType: InvoiceTypeCreditNote,
+				},
+			},
+			{
+				Types: []cbc.Key{bill.InvoiceTypeDebitNote},
+				Ext: tax.Extensions{
+					ExtKeyInvoiceType: InvoiceTypeDebitNote,
+				},
+			},
+		},
+	},
+}
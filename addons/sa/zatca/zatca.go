@@ -0,0 +1,100 @@
+// Package zatca provides the extensions and validations required to prepare
+// Saudi Arabian invoices for ZATCA (Fatoora) Phase 2 clearance and reporting.
+package zatca
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	// V2 for ZATCA Phase 2 (Integration Phase) e-invoicing.
+	V2 cbc.Key = "sa-zatca-v2"
+)
+
+// ZATCA stamp keys
+const (
+	// StampHash contains the base64 encoded SHA-256 hash of the cleared or
+	// reported invoice, which must be included as the previous invoice
+	// hash of the next document issued.
+	StampHash cbc.Key = "zatca-hash"
+	// StampQR contains the base64 encoded TLV payload of the QR code.
+	StampQR cbc.Key = "zatca-qr"
+)
+
+func init() {
+	tax.RegisterAddonDefLoader(V2, newAddon)
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key: StampHash,
+		Name: i18n.String{
+			i18n.EN: "ZATCA Invoice Hash",
+			i18n.AR: "تجزئة الفاتورة",
+		},
+		Pattern: hashPattern,
+	})
+}
+
+func newAddon() *tax.AddonDef {
+	return &tax.AddonDef{
+		Key: V2,
+		Name: i18n.String{
+			i18n.EN: "Saudi Arabia ZATCA Phase 2",
+			i18n.AR: "المرحلة الثانية من الفوترة الإلكترونية",
+		},
+		Description: i18n.String{
+			i18n.EN: here.Doc(`
+				Support for the second phase of the Saudi Arabian e-invoicing system
+				(Fatoora), where standard tax invoices are cleared and simplified tax
+				invoices reported to the Zakat, Tax and Customs Authority (ZATCA).
+
+				Invoices will be assigned the UBL invoice type code and the ZATCA
+				invoice subtype according to their type and tags, and must include
+				the hash of the previous invoice issued by the same device in the
+				"zatca-pih" meta field of the tax object so that documents are chained.
+
+				The QR code payload may be generated from a calculated invoice using
+				the "QR" function.
+			`),
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "E-Invoicing Detailed Guidelines",
+				},
+				URL: "https://zatca.gov.sa/en/E-Invoicing/Introduction/Guidelines/Documents/E-invoicing-Detailed-Guideline.pdf",
+			},
+			{
+				Title: i18n.String{
+					i18n.EN: "E-Invoicing XML Implementation Standard",
+				},
+				URL: "https://zatca.gov.sa/en/E-Invoicing/SystemsDevelopers/Documents/20230519_ZATCA_Electronic_Invoice_XML_Implementation_Standard_%20vF.pdf",
+			},
+		},
+		Tags: []*tax.TagSet{
+			invoiceTags,
+		},
+		Extensions: extensions,
+		Scenarios:  scenarios,
+		Normalizer: normalize,
+		Validator:  validate,
+	}
+}
+
+func normalize(doc any) {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		normalizeInvoice(obj)
+	}
+}
+
+func validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package gobl
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/validation"
+)
+
+// Attachment contains the data of an auxiliary file carried inside the
+// envelope, like the original PDF or signature artifacts. Details about the
+// file and the digest of its data are kept in the header's attachment
+// references so that they are covered by the envelope's signatures.
+type Attachment struct {
+	// Key of the attachment reference in the header.
+	Key cbc.Key `json:"key" jsonschema:"title=Key"`
+	// Contents of the file, encoded in base64 when serialized.
+	Data []byte `json:"data" jsonschema:"title=Data"`
+}
+
+// Validate checks the attachment's basic details.
+func (a *Attachment) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.Key, validation.Required),
+		validation.Field(&a.Data, validation.Required),
+	)
+}
+
+// Attach adds the file to the envelope and a reference to it, including
+// the digest of the data, to the header. Existing attachments with the same
+// key will be replaced. Attachments cannot be modified once the envelope
+// has been signed.
+func (e *Envelope) Attach(key cbc.Key, name, mime string, data []byte) error {
+	if e.Head == nil {
+		return ErrInternal.WithReason("missing head")
+	}
+	if e.Signed() {
+		return ErrSignature.WithReason("cannot attach to signed envelope")
+	}
+	if len(data) == 0 {
+		return ErrValidation.WithReason("attachment '%s': no data", key)
+	}
+	e.Head.Attachments = head.AppendAttachmentRef(e.Head.Attachments, &head.AttachmentRef{
+		Key:    key,
+		Name:   name,
+		MIME:   mime,
+		Size:   int64(len(data)),
+		Digest: dsig.NewSHA256Digest(data),
+	})
+	a := &Attachment{Key: key, Data: data}
+	for i, v := range e.Attachments {
+		if v.Key == key {
+			e.Attachments[i] = a
+			return nil
+		}
+	}
+	e.Attachments = append(e.Attachments, a)
+	return nil
+}
+
+// Detach removes the attachment with the matching key from the envelope and
+// the header.
+func (e *Envelope) Detach(key cbc.Key) error {
+	if e.Head == nil {
+		return ErrInternal.WithReason("missing head")
+	}
+	if e.Signed() {
+		return ErrSignature.WithReason("cannot detach from signed envelope")
+	}
+	if e.Head.Attachment(key) == nil {
+		return ErrValidation.WithReason("attachment '%s': not found", key)
+	}
+	e.Head.Attachments = head.RemoveAttachmentRef(e.Head.Attachments, key)
+	list := make([]*Attachment, 0, len(e.Attachments))
+	for _, a := range e.Attachments {
+		if a.Key != key {
+			list = append(list, a)
+		}
+	}
+	if len(list) == 0 {
+		list = nil
+	}
+	e.Attachments = list
+	return nil
+}
+
+// Attachment provides the attachment with the matching key, or nil.
+func (e *Envelope) Attachment(key cbc.Key) *Attachment {
+	for _, a := range e.Attachments {
+		if a != nil && a.Key == key {
+			return a
+		}
+	}
+	return nil
+}
+
+// verifyAttachments ensures that every attachment is referenced in the
+// header with a matching digest and size, and that no referenced attachments
+// are missing.
+func (e *Envelope) verifyAttachments() error {
+	if e.Head == nil {
+		return nil
+	}
+	seen := make(map[cbc.Key]bool)
+	for _, a := range e.Attachments {
+		if a == nil {
+			continue
+		}
+		if seen[a.Key] {
+			return ErrDigest.WithReason("attachment '%s': duplicate key", a.Key)
+		}
+		seen[a.Key] = true
+		ref := e.Head.Attachment(a.Key)
+		if ref == nil {
+			return ErrDigest.WithReason("attachment '%s': not referenced in header", a.Key)
+		}
+		if ref.Size != int64(len(a.Data)) {
+			return ErrDigest.WithReason("attachment '%s': size mismatch", a.Key)
+		}
+		if ref.Digest == nil {
+			return ErrDigest.WithReason("attachment '%s': missing digest", a.Key)
+		}
+		if err := ref.Digest.Equals(dsig.NewSHA256Digest(a.Data)); err != nil {
+			return ErrDigest.WithReason("attachment '%s': %s", a.Key, err.Error())
+		}
+	}
+	for _, ref := range e.Head.Attachments {
+		if ref != nil && !seen[ref.Key] {
+			return ErrDigest.WithReason("attachment '%s': missing", ref.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAttachmentEnvelope(t *testing.T) *gobl.Envelope {
+	t.Helper()
+	env := gobl.NewEnvelope()
+	data, err := os.ReadFile("./examples/es/invoice-es-es.env.yaml")
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, env))
+	env.Unsign()
+	require.NoError(t, env.Calculate())
+	return env
+}
+
+func TestEnvelopeAttach(t *testing.T) {
+	t.Run("attach and sign", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7")))
+		ref := env.Head.Attachment("pdf")
+		require.NotNil(t, ref)
+		assert.Equal(t, "invoice.pdf", ref.Name)
+		assert.Equal(t, int64(8), ref.Size)
+		assert.Equal(t, "sha256", string(ref.Digest.Algorithm))
+		require.NotNil(t, env.Attachment("pdf"))
+		assert.Nil(t, env.Attachment("xml"))
+		require.NoError(t, env.Sign(testKey))
+		assert.NoError(t, env.Verify(testKey.Public()))
+
+		err := env.Attach("xml", "invoice.xml", "application/xml", []byte("<x/>"))
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+		err = env.Detach("pdf")
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+	})
+	t.Run("replace", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("one")))
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("three")))
+		assert.Len(t, env.Attachments, 1)
+		assert.Len(t, env.Head.Attachments, 1)
+		assert.Equal(t, int64(5), env.Head.Attachment("pdf").Size)
+		assert.NoError(t, env.Validate())
+	})
+	t.Run("detach", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("one")))
+		require.NoError(t, env.Detach("pdf"))
+		assert.Empty(t, env.Attachments)
+		assert.Empty(t, env.Head.Attachments)
+		assert.ErrorContains(t, env.Detach("pdf"), "attachment 'pdf': not found")
+	})
+	t.Run("no data", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		err := env.Attach("pdf", "invoice.pdf", "application/pdf", nil)
+		assert.ErrorIs(t, err, gobl.ErrValidation)
+	})
+}
+
+func TestEnvelopeAttachmentValidation(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7")))
+		require.NoError(t, env.Sign(testKey))
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+		env2 := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env2))
+		assert.NoError(t, env2.Validate())
+		assert.Equal(t, []byte("%PDF-1.7"), env2.Attachment("pdf").Data)
+	})
+	t.Run("tampered data", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7")))
+		require.NoError(t, env.Sign(testKey))
+		env.Attachment("pdf").Data = []byte("%PDF-1.6")
+		err := env.Validate()
+		assert.ErrorIs(t, err, gobl.ErrDigest)
+		assert.ErrorContains(t, err, "attachment 'pdf': mismatch")
+	})
+	t.Run("size mismatch", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7")))
+		env.Attachment("pdf").Data = []byte("%PDF")
+		assert.ErrorContains(t, env.Validate(), "attachment 'pdf': size mismatch")
+	})
+	t.Run("missing", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7")))
+		env.Attachments = nil
+		assert.ErrorContains(t, env.Validate(), "attachment 'pdf': missing")
+	})
+	t.Run("not referenced", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		env.Attachments = append(env.Attachments, &gobl.Attachment{
+			Key:  cbc.Key("xml"),
+			Data: []byte("<x/>"),
+		})
+		assert.ErrorContains(t, env.Validate(), "attachment 'xml': not referenced in header")
+	})
+}
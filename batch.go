@@ -0,0 +1,244 @@
+package gobl
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strconv"
+
+	"github.com/invopop/validation"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/internal"
+	"github.com/invopop/gobl/schema"
+	"github.com/invopop/gobl/uuid"
+)
+
+// Batch groups together a set of envelopes so that they can be signed and
+// transmitted as a single unit, for example a day's receipts or a submission
+// to a clearance platform. The batch header's digest is the root of a digest
+// tree built from the digests of each of the envelopes, in order, so that a
+// single signature covers the complete set.
+type Batch struct {
+	// Schema identifies the schema that should be used to understand this batch
+	Schema schema.ID `json:"$schema" jsonschema:"title=JSON Schema ID"`
+	// Details on what the contents are
+	Head *head.Header `json:"head" jsonschema:"title=Header"`
+	// Envelopes contained inside the batch
+	Envelopes []*Envelope `json:"envs" jsonschema:"title=Envelopes"`
+	// JSON Web Signatures of the header
+	Signatures []*dsig.Signature `json:"sigs,omitempty" jsonschema:"title=Signatures"`
+}
+
+// BatchSchema sets the general definition of the schema ID for this version
+// of the batch.
+var BatchSchema = schema.GOBL.Add("batch")
+
+// NewBatch builds a new empty batch ready for envelopes to be added.
+func NewBatch() *Batch {
+	b := new(Batch)
+	b.Schema = BatchSchema
+	b.Head = head.NewHeader()
+	b.Envelopes = make([]*Envelope, 0)
+	return b
+}
+
+// Add appends the envelopes to the batch and recalculates the batch's
+// digest. Envelopes are expected to have been calculated already, and
+// may be signed independently.
+func (b *Batch) Add(envs ...*Envelope) error {
+	for _, e := range envs {
+		if e == nil || e.Head == nil || e.Head.Digest == nil {
+			return ErrValidation.WithReason("envelope must be calculated before adding to batch")
+		}
+	}
+	b.Envelopes = append(b.Envelopes, envs...)
+	return b.calculate()
+}
+
+// Insert wraps the document in a new envelope and adds it to the batch.
+func (b *Batch) Insert(doc any) (*Envelope, error) {
+	e, err := Envelop(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Add(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Calculate performs the calculations on each of the envelopes in the batch
+// and refreshes the batch header's digest.
+func (b *Batch) Calculate() error {
+	if len(b.Envelopes) == 0 {
+		return ErrNoDocument
+	}
+	for i, e := range b.Envelopes {
+		if e == nil {
+			return ErrValidation.WithReason("envelope %d missing", i)
+		}
+		if e.Signed() || e.Encrypted() {
+			// contents cannot be modified
+			continue
+		}
+		if err := e.Calculate(); err != nil {
+			return err
+		}
+	}
+	return b.calculate()
+}
+
+func (b *Batch) calculate() error {
+	b.Schema = BatchSchema
+	if b.Head == nil {
+		b.Head = head.NewHeader()
+	}
+	if b.Head.UUID.IsZero() {
+		b.Head.UUID = uuid.V7()
+	}
+	var err error
+	b.Head.Digest, err = b.Digest()
+	return err
+}
+
+// Digest calculates the root of the digest tree built from the digests
+// in the headers of each envelope.
+func (b *Batch) Digest() (*dsig.Digest, error) {
+	list := make([]*dsig.Digest, len(b.Envelopes))
+	for i, e := range b.Envelopes {
+		if e == nil || e.Head == nil || e.Head.Digest == nil {
+			return nil, ErrDigest.WithReason("envelope %d missing digest", i)
+		}
+		list[i] = e.Head.Digest
+	}
+	d, err := dsig.NewSHA256DigestTree(list)
+	if err != nil {
+		return nil, ErrDigest.WithCause(err)
+	}
+	return d, nil
+}
+
+// Validate ensures the batch and each of the envelopes it contains are
+// valid and that the batch digest matches.
+func (b *Batch) Validate() error {
+	return b.ValidateWithContext(context.Background())
+}
+
+// ValidateWithContext ensures the batch and each of the envelopes it
+// contains are valid and that the batch digest matches.
+func (b *Batch) ValidateWithContext(ctx context.Context) error {
+	if len(b.Signatures) > 0 {
+		ctx = internal.SignedContext(ctx)
+	}
+	err := validation.ValidateStructWithContext(ctx, b,
+		validation.Field(&b.Schema, validation.Required),
+		validation.Field(&b.Head, validation.Required),
+		validation.Field(&b.Envelopes,
+			validation.Required,
+			validation.Each(validation.NotNil),
+		),
+		validation.Field(&b.Signatures),
+	)
+	if err != nil {
+		return wrapError(err)
+	}
+	return wrapError(b.verifyDigest())
+}
+
+func (b *Batch) verifyDigest() error {
+	d, err := b.Digest()
+	if err != nil {
+		return err
+	}
+	if err := b.Head.Digest.Equals(d); err != nil {
+		return ErrDigest.WithCause(err)
+	}
+	return nil
+}
+
+// Sign uses the private key to sign the batch header, which will cover all
+// of the envelopes contained in the batch. If the resulting batch is not
+// valid, the signature will be removed.
+func (b *Batch) Sign(key *dsig.PrivateKey) error {
+	if b.Head == nil {
+		return ErrValidation.WithReason("header required")
+	}
+	sig, err := key.Sign(b.Head)
+	if err != nil {
+		return ErrSignature.WithCause(err)
+	}
+	b.Signatures = append(b.Signatures, sig)
+	if err := b.Validate(); err != nil {
+		b.Signatures = nil
+		return err
+	}
+	return nil
+}
+
+// Signed returns true if the batch has signatures.
+func (b *Batch) Signed() bool {
+	return len(b.Signatures) > 0
+}
+
+// Verify checks the batch signatures to ensure the header they contain
+// still matches the current header. As with envelopes, if public keys are
+// provided, at least one of them must have been used to create each
+// signature.
+func (b *Batch) Verify(keys ...*dsig.PublicKey) error {
+	if len(b.Signatures) == 0 {
+		return errors.New("no signatures to verify")
+	}
+	ve := make(validation.Errors)
+	for i, s := range b.Signatures {
+		if err := verifyHeaderSignature(b.Head, s, keys...); err != nil {
+			ve[strconv.Itoa(i)] = err
+		}
+	}
+	if len(ve) > 0 {
+		return ErrValidation.WithCause(validation.Errors{
+			"signatures": ve,
+		})
+	}
+	return nil
+}
+
+// Len provides the number of envelopes in the batch.
+func (b *Batch) Len() int {
+	return len(b.Envelopes)
+}
+
+// All provides an iterator over each of the envelopes in the batch.
+func (b *Batch) All() iter.Seq2[int, *Envelope] {
+	return func(yield func(int, *Envelope) bool) {
+		for i, e := range b.Envelopes {
+			if !yield(i, e) {
+				return
+			}
+		}
+	}
+}
+
+// Envelope provides the envelope in the batch with the matching header
+// UUID, or nil.
+func (b *Batch) Envelope(id uuid.UUID) *Envelope {
+	for _, e := range b.Envelopes {
+		if e != nil && e.Head != nil && e.Head.UUID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Extract provides the documents contained in each of the envelopes, in
+// order.
+func (b *Batch) Extract() []any {
+	docs := make([]any, len(b.Envelopes))
+	for i, e := range b.Envelopes {
+		if e != nil {
+			docs[i] = e.Extract()
+		}
+	}
+	return docs
+}
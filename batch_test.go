@@ -0,0 +1,135 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBatch(t *testing.T, contents ...string) *gobl.Batch {
+	t.Helper()
+	b := gobl.NewBatch()
+	for _, c := range contents {
+		_, err := b.Insert(&note.Message{Content: c})
+		require.NoError(t, err)
+	}
+	return b
+}
+
+func TestNewBatch(t *testing.T) {
+	b := gobl.NewBatch()
+	assert.Equal(t, gobl.BatchSchema, b.Schema)
+	assert.NotNil(t, b.Head)
+	assert.Equal(t, 0, b.Len())
+	assert.ErrorIs(t, b.Calculate(), gobl.ErrNoDocument)
+}
+
+func TestBatchAdd(t *testing.T) {
+	b := testBatch(t, "one", "two")
+	assert.Equal(t, 2, b.Len())
+	d1 := b.Head.Digest.String()
+
+	env, err := gobl.Envelop(&note.Message{Content: "three"})
+	require.NoError(t, err)
+	require.NoError(t, b.Add(env))
+	assert.Equal(t, 3, b.Len())
+	assert.NotEqual(t, d1, b.Head.Digest.String())
+
+	err = b.Add(gobl.NewEnvelope())
+	assert.ErrorContains(t, err, "envelope must be calculated before adding to batch")
+	assert.Equal(t, 3, b.Len())
+}
+
+func TestBatchDigest(t *testing.T) {
+	b := testBatch(t, "one", "two", "three")
+	list := make([]*dsig.Digest, 0)
+	for _, e := range b.All() {
+		list = append(list, e.Head.Digest)
+	}
+	d, err := dsig.NewSHA256DigestTree(list)
+	require.NoError(t, err)
+	assert.NoError(t, b.Head.Digest.Equals(d))
+}
+
+func TestBatchValidate(t *testing.T) {
+	b := testBatch(t, "one", "two")
+	require.NoError(t, b.Validate())
+
+	t.Run("modified envelope", func(t *testing.T) {
+		b := testBatch(t, "one", "two")
+		msg := b.Envelopes[1].Extract().(*note.Message)
+		msg.Content = "changed"
+		require.NoError(t, b.Envelopes[1].Calculate())
+		err := b.Validate()
+		assert.ErrorIs(t, err, gobl.ErrDigest)
+		require.NoError(t, b.Calculate())
+		assert.NoError(t, b.Validate())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		b := gobl.NewBatch()
+		err := b.Validate()
+		assert.ErrorContains(t, err, "envs: cannot be blank")
+	})
+}
+
+func TestBatchSignAndVerify(t *testing.T) {
+	b := testBatch(t, "one", "two")
+	require.NoError(t, b.Sign(testKey))
+	assert.True(t, b.Signed())
+	assert.NoError(t, b.Verify())
+	assert.NoError(t, b.Verify(testKey.Public()))
+
+	rk := dsig.NewES256Key()
+	err := b.Verify(rk.Public())
+	assert.ErrorContains(t, err, "signatures: (0: no key match found.)")
+
+	_, err = b.Insert(&note.Message{Content: "three"})
+	require.NoError(t, err)
+	err = b.Verify(testKey.Public())
+	assert.ErrorContains(t, err, "signatures: (0: header mismatch.)")
+
+	assert.ErrorContains(t, gobl.NewBatch().Verify(), "no signatures to verify")
+}
+
+func TestBatchExtraction(t *testing.T) {
+	b := testBatch(t, "one", "two", "three")
+
+	id := b.Envelopes[1].Head.UUID
+	env := b.Envelope(id)
+	require.NotNil(t, env)
+	assert.Equal(t, "two", env.Extract().(*note.Message).Content)
+	assert.Nil(t, b.Envelope(b.Head.UUID))
+
+	docs := b.Extract()
+	require.Len(t, docs, 3)
+	assert.Equal(t, "three", docs[2].(*note.Message).Content)
+
+	count := 0
+	for i := range b.All() {
+		if i == 1 {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestBatchJSON(t *testing.T) {
+	b := testBatch(t, "one", "two")
+	require.NoError(t, b.Sign(testKey))
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+
+	b2 := new(gobl.Batch)
+	require.NoError(t, json.Unmarshal(data, b2))
+	assert.Equal(t, gobl.BatchSchema, b2.Schema)
+	assert.Equal(t, 2, b2.Len())
+	assert.NoError(t, b2.Validate())
+	assert.NoError(t, b2.Verify(testKey.Public()))
+}
@@ -25,6 +25,7 @@ type billable interface {
 
 	getIssueDate() cal.Date
 	getIssueTime() *cal.Time
+	getOperationDate() *cal.Date
 	getValueDate() *cal.Date
 	getTax() *Tax
 	getPreceding() []*org.DocumentRef
@@ -45,13 +46,63 @@ type billable interface {
 }
 
 func calculate(doc billable) error {
+	c, err := prepareCalculation(doc)
+	if err != nil {
+		return err
+	}
+	t := c.totals
+
+	// Lines
+	if err := calculateLines(doc.getLines(), c.cur, doc.getExchangeRates(), c.rr); err != nil {
+		return validation.Errors{"lines": err}
+	}
+	t.Sum = calculateLineSum(doc.getLines(), c.cur)
+	t.Total = t.Sum
+
+	c.calculateDiscountsAndCharges(doc)
+
+	tls := prepareTaxableLines(doc)
+	if len(tls) == 0 {
+		// This applies for orders and deliveries that might not have
+		// any pricing details.
+		doc.setTotals(nil)
+		return nil
+	}
+
+	// Now figure out the tax totals
+	t.Taxes = new(tax.Total)
+	tc := c.taxCalculator()
+	tc.Lines = tls
+	if err := tc.Calculate(t.Taxes); err != nil {
+		return err
+	}
+
+	c.complete(doc)
+
+	return nil
+}
+
+// calculation contains the details determined from the document that are
+// needed to calculate its lines and totals.
+type calculation struct {
+	regime *tax.RegimeDef // may be nil!
+	date   cal.Date
+	cur    currency.Code
+	pit    cbc.Code // prices include tax
+	rr     cbc.Key  // rounding rule
+	totals *Totals
+}
+
+// prepareCalculation performs the calculations required on the document
+// before any lines can be calculated.
+func prepareCalculation(doc billable) (*calculation, error) {
 	r := doc.RegimeDef() // may be nil!
 	date := calculateIssueDateAndTime(r, doc)
 
 	// Convert empty or invalid currency to the regime's currency
 	if doc.getCurrency() == currency.CodeEmpty || doc.getCurrency().Def() == nil {
 		if r == nil {
-			return validation.Errors{"currency": errors.New("missing")}
+			return nil, validation.Errors{"currency": errors.New("missing")}
 		}
 		doc.setCurrency(r.Currency)
 	}
@@ -87,57 +138,59 @@ func calculate(doc billable) error {
 
 	// Complements
 	if err := calculateComplements(doc.getComplements()); err != nil {
-		return validation.Errors{"complements": err}
+		return nil, validation.Errors{"complements": err}
 	}
 
 	// Preceding
 	calculateOrgDocumentRefs(doc.getPreceding(), cur, rr)
 
-	// Lines
-	if err := calculateLines(doc.getLines(), cur, doc.getExchangeRates(), rr); err != nil {
-		return validation.Errors{"lines": err}
-	}
-	t.Sum = calculateLineSum(doc.getLines(), cur)
-	t.Total = t.Sum
+	return &calculation{
+		regime: r,
+		date:   *date,
+		cur:    cur,
+		pit:    pit,
+		rr:     rr,
+		totals: t,
+	}, nil
+}
+
+// calculateDiscountsAndCharges applies the document level discounts and
+// charges once the sum of all the lines is known.
+func (c *calculation) calculateDiscountsAndCharges(doc billable) {
+	t := c.totals
 
 	// Discount Lines
-	calculateDiscounts(doc.getDiscounts(), cur, t.Sum, rr)
-	if discounts := calculateDiscountSum(doc.getDiscounts(), cur); discounts != nil {
+	calculateDiscounts(doc.getDiscounts(), c.cur, t.Sum, c.rr)
+	if discounts := calculateDiscountSum(doc.getDiscounts(), c.cur); discounts != nil {
 		t.Discount = discounts
 		t.Total = t.Total.Subtract(*discounts)
 	}
 
 	// Charge Lines
-	calculateCharges(doc.getCharges(), cur, t.Sum, rr)
-	if charges := calculateChargeSum(doc.getCharges(), cur); charges != nil {
+	calculateCharges(doc.getCharges(), c.cur, t.Sum, c.rr)
+	if charges := calculateChargeSum(doc.getCharges(), c.cur); charges != nil {
 		t.Charge = charges
 		t.Total = t.Total.Add(*charges)
 	}
+}
 
-	tls := prepareTaxableLines(doc)
-	if len(tls) == 0 {
-		// This applies for orders and deliveries that might not have
-		// any pricing details.
-		doc.setTotals(nil)
-		return nil
+func (c *calculation) taxCalculator() *tax.TotalCalculator {
+	return &tax.TotalCalculator{
+		Currency: c.cur,
+		Rounding: c.rr,
+		Country:  c.regime.GetCountry(),
+		Date:     c.date,
+		Includes: c.pit,
 	}
+}
 
-	// Now figure out the tax totals
-	t.Taxes = new(tax.Total)
-	tc := &tax.TotalCalculator{
-		Currency: doc.getCurrency(),
-		Rounding: rr,
-		Country:  r.GetCountry(),
-		Date:     *date,
-		Lines:    tls,
-		Includes: pit,
-	}
-	if err := tc.Calculate(t.Taxes); err != nil {
-		return err
-	}
+// complete determines the final totals once the tax totals have been
+// calculated.
+func (c *calculation) complete(doc billable) {
+	t := c.totals
 
 	// Remove any included taxes from the total.
-	ct := t.Taxes.Category(pit)
+	ct := t.Taxes.Category(c.pit)
 	if ct != nil {
 		ti := ct.Amount
 		t.TaxIncluded = &ti
@@ -150,6 +203,8 @@ func calculate(doc billable) error {
 	if t.Taxes.Retained != nil {
 		t.RetainedTax = t.Taxes.Retained
 	}
+	t.calculateRetainedTaxes()
+	t.CashBasis = doc.HasTags(tax.TagCashBasis)
 	t.Payable = t.TotalWithTax
 	if t.RetainedTax != nil {
 		t.Payable = t.Payable.Subtract(*t.RetainedTax)
@@ -159,9 +214,7 @@ func calculate(doc billable) error {
 		t.Taxes = nil
 	}
 
-	roundTotalsAndPreparePayments(doc, cur, t)
-
-	return nil
+	roundTotalsAndPreparePayments(doc, c.cur, t)
 }
 
 func roundTotalsAndPreparePayments(doc billable, cur currency.Code, t *Totals) {
@@ -226,8 +279,13 @@ func calculateIssueDateAndTime(r *tax.RegimeDef, doc billable) *cal.Date {
 		doc.setIssueDate(cal.TodayIn(tz))
 	}
 
-	// Get the date used for tax calculations
+	// Get the date used for tax calculations, which will be the operation
+	// date if no value date is set, so that historical rates are used
+	// for operations that took place before they changed.
 	date := doc.getValueDate()
+	if date == nil {
+		date = doc.getOperationDate()
+	}
 	if date == nil {
 		id := doc.getIssueDate()
 		date = &id
@@ -215,6 +215,49 @@ func TestCalculate(t *testing.T) {
 		assert.Equal(t, "53.00", inv.Totals.Due.String())
 	})
 
+	t.Run("with retained tax breakdown", func(t *testing.T) {
+		line := func(price int64, irpf int64) *bill.Line {
+			return &bill.Line{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "test item",
+					Price: num.NewAmount(price, 2),
+				},
+				Taxes: tax.Set{
+					{
+						Category: tax.CategoryVAT,
+						Percent:  num.NewPercentage(21, 2),
+					},
+					{
+						Category: es.TaxCategoryIRPF,
+						Percent:  num.NewPercentage(irpf, 2),
+					},
+				},
+			}
+		}
+		inv := baseInvoice(t, line(10000, 15), line(20000, 7))
+		inv.Tax.PricesInclude = ""
+		inv.Totals = &bill.Totals{Rounding: num.NewAmount(-1, 2)}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "29.00", inv.Totals.RetainedTax.String())
+		require.Len(t, inv.Totals.RetainedTaxes, 1)
+		rt := inv.Totals.RetainedTaxTotal(es.TaxCategoryIRPF)
+		require.NotNil(t, rt)
+		assert.Equal(t, "300.00", rt.Base.String())
+		assert.Equal(t, "29.00", rt.Amount.String())
+		assert.Nil(t, inv.Totals.RetainedTaxTotal(tax.CategoryVAT))
+
+		assert.Equal(t, "333.99", inv.Totals.Payable.String())
+		assert.Equal(t, "333.99", inv.Totals.NetPayable().String())
+		assert.Equal(t, "333.99", inv.Totals.NetPayable(es.TaxCategoryIRPF).String())
+		assert.Equal(t, "362.99", inv.Totals.NetPayable(tax.CategoryVAT).String())
+
+		inv.Lines = inv.Lines[:1]
+		inv.Lines[0].Taxes = inv.Lines[0].Taxes[:1]
+		require.NoError(t, inv.Calculate())
+		assert.Nil(t, inv.Totals.RetainedTaxes)
+	})
+
 	t.Run("with multiple informative taxes", func(t *testing.T) {
 		inv := baseInvoice(t,
 			&bill.Line{
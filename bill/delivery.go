@@ -208,6 +208,8 @@ func (dlv *Delivery) ValidateWithContext(ctx context.Context) error {
 		),
 		validation.Field(&dlv.ExchangeRates,
 			validation.Each(validation.NotNil),
+			currency.DetectConflictingExchangeRates,
+			currency.CanTriangulateInto(dlv.ExchangeRates, dlv.Currency, r.GetCurrency()),
 		),
 
 		validation.Field(&dlv.Ordering),
@@ -219,7 +221,9 @@ func (dlv *Delivery) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&dlv.DespatchDate),
 		validation.Field(&dlv.ReceiveDate),
 
-		validation.Field(&dlv.Tax),
+		validation.Field(&dlv.Tax,
+			checkRoundingRule(dlv.RegimeDef(), dlv.AddonDefs()),
+		),
 
 		validation.Field(&dlv.Supplier, validation.Required),
 		validation.Field(&dlv.Customer),
@@ -361,6 +365,9 @@ func (dlv *Delivery) getIssueDate() cal.Date {
 func (dlv *Delivery) getIssueTime() *cal.Time {
 	return dlv.IssueTime
 }
+func (dlv *Delivery) getOperationDate() *cal.Date {
+	return nil
+}
 func (dlv *Delivery) getValueDate() *cal.Date {
 	return dlv.ValueDate
 }
@@ -0,0 +1,216 @@
+package bill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/uuid"
+)
+
+// QuantityMismatch describes an item whose quantity in a delivery does not
+// match the quantity requested in the orders being fulfilled.
+type QuantityMismatch struct {
+	// Reference code of the item, if any.
+	Ref cbc.Code `json:"ref,omitempty" jsonschema:"title=Ref"`
+	// Name of the item.
+	Name string `json:"name" jsonschema:"title=Name"`
+	// Total quantity requested in the orders.
+	Ordered num.Amount `json:"ordered" jsonschema:"title=Ordered"`
+	// Total quantity included in the delivery.
+	Delivered num.Amount `json:"delivered" jsonschema:"title=Delivered"`
+}
+
+// Invoice prepares a new draft invoice from the delivery, copying the parties,
+// lines, discounts, and charges, and adding a reference to the delivery in
+// the invoice's despatch ordering data. The delivery itself will not be
+// modified.
+//
+// The orders fulfilled by the delivery may optionally be provided so that
+// they are referenced in the invoice, their payment details and ordering
+// parties copied, and prices or taxes missing from the delivery's lines
+// taken from the order lines with the same item reference or name. Any
+// items whose delivered quantities do not match those ordered will be
+// returned as mismatches so that they can be reviewed before issuing.
+func (dlv *Delivery) Invoice(ords ...*Order) (*Invoice, []*QuantityMismatch, error) {
+	if err := dlv.Calculate(); err != nil {
+		return nil, nil, err
+	}
+	if dlv.Code == "" {
+		return nil, nil, errors.New("cannot invoice a delivery without a code")
+	}
+	for i, ord := range ords {
+		if ord == nil {
+			return nil, nil, fmt.Errorf("order %d: missing", i)
+		}
+		if err := ord.Calculate(); err != nil {
+			return nil, nil, fmt.Errorf("order %d: %w", i, err)
+		}
+		if ord.Currency != dlv.Currency {
+			return nil, nil, fmt.Errorf("order %d: currency '%s' does not match '%s'", i, ord.Currency, dlv.Currency)
+		}
+		if !sameParty(ord.Supplier, dlv.Supplier) {
+			return nil, nil, fmt.Errorf("order %d: supplier does not match", i)
+		}
+	}
+	d, err := dlv.clone()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inv := &Invoice{
+		Regime:        d.Regime,
+		Addons:        d.Addons,
+		Type:          InvoiceTypeStandard,
+		Currency:      d.Currency,
+		ExchangeRates: d.ExchangeRates,
+		Tax:           d.Tax,
+		Supplier:      d.Supplier,
+		Customer:      d.Customer,
+		Lines:         d.Lines,
+		Discounts:     d.Discounts,
+		Charges:       d.Charges,
+		Ordering:      d.Ordering,
+		Notes:         d.Notes,
+	}
+	if inv.Ordering == nil {
+		inv.Ordering = new(Ordering)
+	}
+	inv.Ordering.Despatch = append(inv.Ordering.Despatch, &org.DocumentRef{
+		Identify:  uuid.Identify{UUID: dlv.UUID},
+		Type:      dlv.Type,
+		Series:    dlv.Series,
+		Code:      dlv.Code,
+		IssueDate: dlv.IssueDate.Clone(),
+	})
+	if d.Receiver != nil {
+		date := d.ReceiveDate
+		if date == nil {
+			date = d.DespatchDate
+		}
+		inv.Delivery = &DeliveryDetails{
+			Receiver: d.Receiver,
+			Date:     date,
+		}
+	}
+
+	ordered := make(map[string]*Line)
+	for _, ord := range ords {
+		o, err := ord.clone()
+		if err != nil {
+			return nil, nil, err
+		}
+		inv.Ordering.addOrderRef(ord)
+		if inv.Ordering.Buyer == nil {
+			inv.Ordering.Buyer = o.Buyer
+		}
+		if inv.Ordering.Seller == nil {
+			inv.Ordering.Seller = o.Seller
+		}
+		if inv.Payment == nil {
+			inv.Payment = o.Payment
+		}
+		for _, l := range o.Lines {
+			if l == nil || l.Item == nil {
+				continue
+			}
+			if _, ok := ordered[lineItemKey(l)]; !ok {
+				ordered[lineItemKey(l)] = l
+			}
+		}
+	}
+	for _, l := range inv.Lines {
+		if l == nil || l.Item == nil {
+			continue
+		}
+		ol := ordered[lineItemKey(l)]
+		if ol == nil {
+			continue
+		}
+		if l.Item.Price == nil && ol.Item.Price != nil {
+			l.Item.Currency = ol.Item.Currency
+			l.Item.Price = ol.Item.Price
+		}
+		if len(l.Taxes) == 0 {
+			l.Taxes = ol.Taxes
+		}
+	}
+
+	if err := inv.Calculate(); err != nil {
+		return nil, nil, err
+	}
+	var mismatches []*QuantityMismatch
+	if len(ords) > 0 {
+		mismatches = deliveryQuantityMismatches(ords, dlv.Lines)
+	}
+	return inv, mismatches, nil
+}
+
+// deliveryQuantityMismatches compares the total quantity of each item in the
+// orders with the total delivered.
+func deliveryQuantityMismatches(ords []*Order, lines []*Line) []*QuantityMismatch {
+	keys := make([]string, 0)
+	totals := make(map[string]*QuantityMismatch)
+	add := func(l *Line, delivered bool) {
+		if l == nil || l.Item == nil {
+			return
+		}
+		k := lineItemKey(l)
+		qm, ok := totals[k]
+		if !ok {
+			qm = &QuantityMismatch{
+				Ref:       l.Item.Ref,
+				Name:      l.Item.Name,
+				Ordered:   num.AmountZero,
+				Delivered: num.AmountZero,
+			}
+			totals[k] = qm
+			keys = append(keys, k)
+		}
+		if delivered {
+			qm.Delivered = qm.Delivered.MatchPrecision(l.Quantity).Add(l.Quantity)
+		} else {
+			qm.Ordered = qm.Ordered.MatchPrecision(l.Quantity).Add(l.Quantity)
+		}
+	}
+	for _, ord := range ords {
+		for _, l := range ord.Lines {
+			add(l, false)
+		}
+	}
+	for _, l := range lines {
+		add(l, true)
+	}
+
+	var list []*QuantityMismatch
+	for _, k := range keys {
+		if qm := totals[k]; !qm.Ordered.Equals(qm.Delivered) {
+			list = append(list, qm)
+		}
+	}
+	return list
+}
+
+// lineItemKey provides the key used to match the items of lines in different
+// documents, using the item's reference if available, or its name otherwise.
+func lineItemKey(l *Line) string {
+	if l.Item.Ref != cbc.CodeEmpty {
+		return "ref:" + l.Item.Ref.String()
+	}
+	return "name:" + l.Item.Name
+}
+
+func (dlv *Delivery) clone() (*Delivery, error) {
+	data, err := json.Marshal(dlv)
+	if err != nil {
+		return nil, err
+	}
+	out := new(Delivery)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deliveryForInvoice(t *testing.T) *bill.Delivery {
+	t.Helper()
+	dlv := baseDelivery(t,
+		&bill.Line{
+			Quantity: num.MakeAmount(8, 0),
+			Item:     &org.Item{Name: "Widget"},
+		},
+		&bill.Line{
+			Quantity: num.MakeAmount(1, 0),
+			Item:     &org.Item{Ref: "GDG-01", Name: "Gadget (blue)"},
+		},
+		&bill.Line{
+			Quantity: num.MakeAmount(2, 0),
+			Item:     &org.Item{Name: "Sample"},
+		},
+	)
+	dlv.UUID = uuid.V7()
+	dlv.Type = bill.DeliveryTypeNote
+	dlv.Code = "DN-1"
+	dlv.Receiver = &org.Party{Name: "Warehouse"}
+	dlv.DespatchDate = cal.NewDate(2022, 6, 14)
+	dlv.ReceiveDate = cal.NewDate(2022, 6, 15)
+	return dlv
+}
+
+func TestDeliveryInvoice(t *testing.T) {
+	t.Run("with order", func(t *testing.T) {
+		dlv := deliveryForInvoice(t)
+		ord := orderForInvoice(t)
+		ord.Buyer = &org.Party{Name: "Buyer"}
+		inv, mm, err := dlv.Invoice(ord)
+		require.NoError(t, err)
+		assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+		assert.Empty(t, inv.Code)
+		assert.Equal(t, "Test Customer", inv.Customer.Name)
+
+		require.Len(t, inv.Lines, 3)
+		assert.Equal(t, "80.00", inv.Lines[0].Total.String())
+		assert.Equal(t, "25.00", inv.Lines[1].Total.String())
+		assert.Equal(t, "Gadget (blue)", inv.Lines[1].Item.Name)
+		assert.Nil(t, inv.Lines[2].Total)
+		assert.Equal(t, "105.00", inv.Totals.Sum.String())
+		assert.Equal(t, "127.05", inv.Totals.Payable.String())
+		assert.Equal(t, pay.TermKeyInstant, inv.Payment.Terms.Key)
+
+		require.NotNil(t, inv.Delivery)
+		assert.Equal(t, "Warehouse", inv.Delivery.Receiver.Name)
+		assert.Equal(t, "2022-06-15", inv.Delivery.Date.String())
+
+		require.Len(t, inv.Ordering.Despatch, 1)
+		ref := inv.Ordering.Despatch[0]
+		assert.Equal(t, dlv.UUID, ref.UUID)
+		assert.Equal(t, bill.DeliveryTypeNote, ref.Type)
+		assert.Equal(t, "DN-1", ref.Code.String())
+		require.Len(t, inv.Ordering.Purchases, 1)
+		assert.Equal(t, ord.UUID, inv.Ordering.Purchases[0].UUID)
+		assert.Equal(t, "Buyer", inv.Ordering.Buyer.Name)
+
+		require.Len(t, mm, 2)
+		assert.Equal(t, "Widget", mm[0].Name)
+		assert.Equal(t, "10", mm[0].Ordered.String())
+		assert.Equal(t, "8", mm[0].Delivered.String())
+		assert.Equal(t, "Sample", mm[1].Name)
+		assert.Equal(t, "0", mm[1].Ordered.String())
+		assert.Equal(t, "2", mm[1].Delivered.String())
+
+		// delivery unmodified
+		assert.Nil(t, dlv.Lines[0].Item.Price)
+	})
+
+	t.Run("already referenced order", func(t *testing.T) {
+		dlv := deliveryForInvoice(t)
+		dlv.Lines = dlv.Lines[:2]
+		dlv.Lines[0].Quantity = num.MakeAmount(10, 0)
+		ord := orderForInvoice(t)
+		dlv.Ordering = &bill.Ordering{
+			Purchases: []*org.DocumentRef{{Series: "TEST", Code: "00123"}},
+		}
+		inv, mm, err := dlv.Invoice(ord)
+		require.NoError(t, err)
+		assert.Len(t, inv.Ordering.Purchases, 1)
+		assert.Empty(t, mm)
+	})
+
+	t.Run("without orders", func(t *testing.T) {
+		dlv := deliveryForInvoice(t)
+		for _, l := range dlv.Lines {
+			l.Item.Price = num.NewAmount(500, 2)
+			l.Taxes = tax.Set{{Category: tax.CategoryVAT, Rate: tax.RateGeneral}}
+		}
+		inv, mm, err := dlv.Invoice()
+		require.NoError(t, err)
+		assert.Nil(t, mm)
+		assert.Equal(t, "55.00", inv.Totals.Sum.String())
+		assert.Empty(t, inv.Ordering.Purchases)
+		assert.Nil(t, inv.Payment)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		dlv := deliveryForInvoice(t)
+		_, _, err := dlv.Invoice(nil)
+		assert.ErrorContains(t, err, "order 0: missing")
+
+		ord := orderForInvoice(t)
+		ord.Supplier.TaxID.Code = "B85905495"
+		_, _, err = dlv.Invoice(ord)
+		assert.ErrorContains(t, err, "order 0: supplier does not match")
+
+		dlv.Code = ""
+		_, _, err = dlv.Invoice()
+		assert.ErrorContains(t, err, "cannot invoice a delivery without a code")
+	})
+}
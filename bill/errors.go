@@ -0,0 +1,39 @@
+package bill
+
+import "github.com/invopop/gobl/i18n"
+
+// Validation errors with stable codes and translatable messages.
+var (
+	// ErrAdvancesExceedPayable is used when the advances are greater than
+	// the amount payable.
+	ErrAdvancesExceedPayable = i18n.NewError("validation_advances_exceed_payable", i18n.String{
+		i18n.EN: "must not exceed payable amount",
+		i18n.ES: "no debe superar el importe a pagar",
+		i18n.FR: "ne doit pas dépasser le montant à payer",
+		i18n.DE: "darf den zu zahlenden Betrag nicht überschreiten",
+	})
+	// ErrMissingExchangeRate is used when there is no exchange rate to
+	// convert amounts, with the `from` and `to` currency parameters.
+	ErrMissingExchangeRate = i18n.NewError("validation_missing_exchange_rate", i18n.String{
+		i18n.EN: "missing exchange rate from {{.from}} to {{.to}}",
+		i18n.ES: "falta el tipo de cambio de {{.from}} a {{.to}}",
+		i18n.FR: "taux de change manquant de {{.from}} à {{.to}}",
+		i18n.DE: "fehlender Wechselkurs von {{.from}} nach {{.to}}",
+	})
+	// ErrCurrencyUndetermined is used when a currency is required but
+	// could not be determined from the context.
+	ErrCurrencyUndetermined = i18n.NewError("validation_currency_undetermined", i18n.String{
+		i18n.EN: "required, unable to determine",
+		i18n.ES: "obligatorio, no se ha podido determinar",
+		i18n.FR: "obligatoire, impossible à déterminer",
+		i18n.DE: "erforderlich, kann nicht ermittelt werden",
+	})
+	// ErrRateChangeUpcoming is used to warn that the value of a rate will
+	// change shortly, with the `date` and new `percent` parameters.
+	ErrRateChangeUpcoming = i18n.NewError("warning_rate_change_upcoming", i18n.String{
+		i18n.EN: "rate changes to {{.percent}} on {{.date}}",
+		i18n.ES: "el tipo cambia a {{.percent}} el {{.date}}",
+		i18n.FR: "le taux passe à {{.percent}} le {{.date}}",
+		i18n.DE: "der Satz ändert sich am {{.date}} auf {{.percent}}",
+	})
+)
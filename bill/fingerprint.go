@@ -0,0 +1,144 @@
+package bill
+
+import (
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// trackChanges determines if documents should keep a fingerprint of their
+// contents after being calculated.
+var trackChanges atomic.Bool
+
+// SetTrackChanges enables fingerprinting documents after they have been
+// calculated so that calling Calculate again on a document that has not been
+// modified will return immediately. This is useful when documents are
+// defensively recalculated at several stages of a process, but adds the cost
+// of fingerprinting to each calculation, which is why it is disabled by default.
+func SetTrackChanges(on bool) {
+	trackChanges.Store(on)
+}
+
+// TrackChanges returns true if change tracking is enabled.
+func TrackChanges() bool {
+	return trackChanges.Load()
+}
+
+// fingerprint provides a hash of the document's contents that can be used
+// to determine if it was modified since it was last calculated. Values are
+// read directly using reflection instead of being serialized, which is
+// considerably cheaper than the calculations it helps avoid. Fingerprints
+// are only comparable within the same process.
+func fingerprint(doc any) uint64 {
+	h := fingerprinter(fingerprintOffset)
+	h.value(reflect.ValueOf(doc))
+	return uint64(h)
+}
+
+const (
+	fingerprintOffset = 14695981039346656037
+	fingerprintPrime  = 1099511628211
+)
+
+var fingerprintSeed = maphash.MakeSeed()
+
+// fingerprinter combines the values it is provided into a single hash
+// one word at a time.
+type fingerprinter uint64
+
+func (h *fingerprinter) uint(v uint64) {
+	*h = (*h ^ fingerprinter(v)) * fingerprintPrime
+	*h ^= *h >> 32
+}
+
+func (h *fingerprinter) string(s string) {
+	h.uint(uint64(len(s)))
+	if len(s) > 0 {
+		h.uint(maphash.String(fingerprintSeed, s))
+	}
+}
+
+func (h *fingerprinter) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.uint(1)
+		} else {
+			h.uint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.uint(math.Float64bits(v.Float()))
+	case reflect.String:
+		h.string(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			h.uint(0)
+			return
+		}
+		h.uint(1)
+		h.value(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			h.uint(0)
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			h.string(string(v.Bytes()))
+			return
+		}
+		fallthrough
+	case reflect.Array:
+		h.uint(uint64(v.Len()) + 1)
+		for i := 0; i < v.Len(); i++ {
+			h.value(v.Index(i))
+		}
+	case reflect.Map:
+		// Map iteration order is random, so entries are combined in a
+		// way that does not depend on it.
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			e := fingerprinter(fingerprintOffset)
+			e.value(iter.Key())
+			e.value(iter.Value())
+			sum += uint64(e)
+		}
+		h.uint(uint64(v.Len()))
+		h.uint(sum)
+	case reflect.Struct:
+		for _, i := range fingerprintFields(v.Type()) {
+			h.value(v.Field(i))
+		}
+	}
+}
+
+var fingerprintFieldCache sync.Map // reflect.Type -> []int
+
+// fingerprintFields provides the indexes of the struct fields to include in
+// a fingerprint. Unexported references are skipped as they usually point to
+// shared or derived data, while unexported values, like those used by
+// numbers, are needed.
+func fingerprintFields(t reflect.Type) []int {
+	if f, ok := fingerprintFieldCache.Load(t); ok {
+		return f.([]int)
+	}
+	fields := make([]int, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			switch f.Type.Kind() {
+			case reflect.Pointer, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+				continue
+			}
+		}
+		fields = append(fields, i)
+	}
+	fingerprintFieldCache.Store(t, fields)
+	return fields
+}
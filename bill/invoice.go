@@ -53,7 +53,8 @@ type Invoice struct {
 	IssueTime *cal.Time `json:"issue_time,omitempty" jsonschema:"title=Issue Time" jsonschema_extras:"calculated=true"`
 	// Date when the operation defined by the invoice became effective.
 	OperationDate *cal.Date `json:"op_date,omitempty" jsonschema:"title=Operation Date"`
-	// When the taxes of this invoice become accountable, if none set, the issue date is used.
+	// When the taxes of this invoice become accountable, if none set, the operation
+	// date or the issue date will be used to determine tax rates.
 	ValueDate *cal.Date `json:"value_date,omitempty" jsonschema:"title=Value Date"`
 	// Currency for all invoice amounts and totals, unless explicitly stated otherwise.
 	Currency currency.Code `json:"currency" jsonschema:"title=Currency" jsonschema_extras:"calculated=true"`
@@ -103,6 +104,10 @@ type Invoice struct {
 	// in the main document. It is important that attachments are not used for alternative
 	// versions of the PDF, for that, see "links" inside the envelope headers.
 	Attachments []*org.Attachment `json:"attachments,omitempty" jsonschema:"title=Attachments"`
+
+	// fingerprint of the invoice's contents after the last successful
+	// calculation, used to avoid repeating work.
+	calculated uint64
 }
 
 // Validate checks to ensure the invoice is valid and contains all the information we need.
@@ -115,11 +120,13 @@ func (inv *Invoice) Validate() error {
 func (inv *Invoice) ValidateWithContext(ctx context.Context) error {
 	ctx = inv.validationContext(ctx)
 
-	var exRule validation.Rule
+	var exRule, exRatesRule validation.Rule
 	exRule = validation.Skip
+	exRatesRule = validation.Skip
 	if r := inv.RegimeDef(); r != nil {
 		// regime specific additions for validation
 		exRule = currency.CanConvertInto(inv.ExchangeRates, r.Currency)
+		exRatesRule = currency.CanTriangulateInto(inv.ExchangeRates, inv.Currency, r.Currency)
 	}
 
 	return tax.ValidateStructWithContext(ctx, inv,
@@ -149,11 +156,15 @@ func (inv *Invoice) ValidateWithContext(ctx context.Context) error {
 		),
 		validation.Field(&inv.ExchangeRates,
 			validation.Each(validation.NotNil),
+			currency.DetectConflictingExchangeRates,
+			exRatesRule,
 		),
 		validation.Field(&inv.Preceding,
 			validation.Each(validation.NotNil),
 		),
-		validation.Field(&inv.Tax),
+		validation.Field(&inv.Tax,
+			checkRoundingRule(inv.RegimeDef(), inv.AddonDefs()),
+		),
 		validation.Field(&inv.Supplier,
 			validation.Required,
 			validation.By(validateInvoiceSupplier),
@@ -162,14 +173,15 @@ func (inv *Invoice) ValidateWithContext(ctx context.Context) error {
 			validation.By(validateInvoiceCustomer),
 		),
 		validation.Field(&inv.Lines,
-			validation.Each(
+			validateLines{rules: []validation.Rule{
 				validation.NotNil,
 				validation.By(lineItemHasPrice),
-			),
+			}},
 			validation.When(
 				len(inv.Discounts) == 0 && len(inv.Charges) == 0,
 				validation.Required.Error("cannot be empty without discounts or charges"),
 			),
+			validation.Skip, // lines already validated
 		),
 		validation.Field(&inv.Discounts,
 			validation.Each(validation.NotNil),
@@ -178,7 +190,9 @@ func (inv *Invoice) ValidateWithContext(ctx context.Context) error {
 			validation.Each(validation.NotNil),
 		),
 		validation.Field(&inv.Ordering),
-		validation.Field(&inv.Payment),
+		validation.Field(&inv.Payment,
+			paymentDueDatesMatchPayable(inv.Totals, inv.Currency),
+		),
 		validation.Field(&inv.Delivery),
 		validation.Field(&inv.Totals,
 			validation.Required,
@@ -282,12 +296,27 @@ func (inv *Invoice) Empty() {
 // Calculate performs all the normalizations and calculations required for the invoice
 // totals and taxes. If the original invoice only includes partial calculations, this
 // will figure out what's missing.
+//
+// Calculations are idempotent, so calling Calculate multiple times will always provide
+// the same results. If change tracking is enabled (see SetTrackChanges), invoices that
+// have not been modified since they were last calculated will be skipped.
 func (inv *Invoice) Calculate() error {
+	if TrackChanges() && inv.unchanged() {
+		return nil
+	}
+	inv.calculated = 0
+
 	// Try to set Regime if not already prepared from the supplier's tax ID
 	if inv.Regime.IsEmpty() {
 		inv.SetRegime(partyTaxCountry(inv.Supplier))
 	}
 
+	// Tax combos need to know the customer's country before normalization
+	// so that any extensions they depend on are set on the first pass.
+	if inv.HasTags(tax.TagCustomerRates) {
+		applyCustomerRates(inv)
+	}
+
 	inv.Normalize(tax.ExtractNormalizers(inv))
 
 	if err := calculate(inv); err != nil {
@@ -298,9 +327,27 @@ func (inv *Invoice) Calculate() error {
 		return err
 	}
 
+	if TrackChanges() {
+		inv.calculated = fingerprint(inv)
+	}
 	return nil
 }
 
+// unchanged returns true if the invoice has not been modified since it was
+// last calculated with change tracking enabled.
+func (inv *Invoice) unchanged() bool {
+	fp := inv.calculated
+	if fp == 0 {
+		return false
+	}
+	inv.calculated = 0 // exclude from fingerprint
+	if fingerprint(inv) != fp {
+		return false
+	}
+	inv.calculated = fp
+	return true
+}
+
 // Normalize is run as part of the Calculate method to ensure that the invoice
 // is in a consistent state before calculations are performed. This will leverage
 // any add-ons alongside the tax regime.
@@ -368,6 +415,9 @@ func (inv *Invoice) getIssueDate() cal.Date {
 func (inv *Invoice) getIssueTime() *cal.Time {
 	return inv.IssueTime
 }
+func (inv *Invoice) getOperationDate() *cal.Date {
+	return inv.OperationDate
+}
 func (inv *Invoice) getValueDate() *cal.Date {
 	return inv.ValueDate
 }
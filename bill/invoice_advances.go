@@ -0,0 +1,155 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+)
+
+// SettleAdvances adds an advance to the invoice for each of the advance
+// invoices provided, such as those issued for down payments before the
+// final invoice, and recalculates the totals.
+//
+// The amount payable by each advance invoice is assumed to have been
+// paid, and will be deducted from the amount due. References to the
+// advance invoices include the taxes they already settled, which are
+// available from SettledTaxes.
+//
+// Advance invoices must be standard invoices between the same parties
+// using the same currency, and may only be settled once. An error will
+// be returned and the invoice left unmodified if the advances would
+// exceed the amount payable.
+func (inv *Invoice) SettleAdvances(advs ...*Invoice) error {
+	if err := inv.Calculate(); err != nil {
+		return err
+	}
+	settled := make(map[string]bool)
+	for _, a := range inv.advanceInvoiceRefs() {
+		settled[refChainKey(a)] = true
+	}
+
+	list := make([]*pay.Advance, 0, len(advs))
+	for i, adv := range advs {
+		if adv == nil {
+			return fmt.Errorf("advance invoice %d: missing", i)
+		}
+		if err := adv.Calculate(); err != nil {
+			return fmt.Errorf("advance invoice %d: %w", i, err)
+		}
+		if err := checkAdvanceInvoice(inv, adv); err != nil {
+			return fmt.Errorf("advance invoice %d: %w", i, err)
+		}
+		key := invoiceChainKey(adv)
+		if settled[key] {
+			return fmt.Errorf("advance invoice %d: '%s' already settled", i, key)
+		}
+		settled[key] = true
+
+		date := adv.IssueDate
+		payable := adv.Totals.Payable
+		list = append(list, &pay.Advance{
+			Date:        &date,
+			Description: fmt.Sprintf("Advance invoice %s", adv.Series.Join(adv.Code)),
+			Amount:      payable,
+			Invoice: &org.DocumentRef{
+				Identify:  uuid.Identify{UUID: adv.UUID},
+				Type:      adv.Type,
+				Series:    adv.Series,
+				Code:      adv.Code,
+				IssueDate: adv.IssueDate.Clone(),
+				Tax:       adv.Totals.Taxes.Clone(),
+				Payable:   &payable,
+			},
+		})
+	}
+
+	pd := inv.Payment
+	if pd == nil {
+		inv.Payment = new(PaymentDetails)
+	}
+	prev := inv.Payment.Advances
+	inv.Payment.Advances = append(prev[:len(prev):len(prev)], list...)
+	restore := func() {
+		inv.Payment.Advances = prev
+		if pd == nil {
+			inv.Payment = nil
+		}
+	}
+	if err := inv.Calculate(); err != nil {
+		restore()
+		return err
+	}
+	if err := inv.Totals.checkAdvancesWithinPayable(nil); err != nil {
+		restore()
+		if cerr := inv.Calculate(); cerr != nil {
+			return cerr
+		}
+		return err
+	}
+	return nil
+}
+
+// SettledTaxes provides the sum of the taxes already settled by the advance
+// invoices referenced in the invoice's advances, or nil if there are none.
+func (inv *Invoice) SettledTaxes() *tax.Total {
+	var total *tax.Total
+	for _, ref := range inv.advanceInvoiceRefs() {
+		if ref.Tax == nil {
+			continue
+		}
+		if total == nil {
+			total = ref.Tax.Clone()
+			continue
+		}
+		total = total.Merge(ref.Tax.Clone())
+	}
+	return total
+}
+
+// OutstandingTaxes provides the invoice's tax totals after deducting the
+// taxes already settled by advance invoices, as required when reporting
+// final invoices in countries like Germany or Spain. The invoice must
+// have been calculated.
+func (inv *Invoice) OutstandingTaxes() *tax.Total {
+	if inv.Totals == nil || inv.Totals.Taxes == nil {
+		return nil
+	}
+	st := inv.SettledTaxes()
+	if st == nil {
+		return inv.Totals.Taxes.Clone()
+	}
+	return inv.Totals.Taxes.Merge(st.Negate())
+}
+
+func (inv *Invoice) advanceInvoiceRefs() []*org.DocumentRef {
+	if inv.Payment == nil {
+		return nil
+	}
+	var refs []*org.DocumentRef
+	for _, a := range inv.Payment.Advances {
+		if a != nil && a.Invoice != nil {
+			refs = append(refs, a.Invoice)
+		}
+	}
+	return refs
+}
+
+func checkAdvanceInvoice(inv, adv *Invoice) error {
+	if adv.Type != InvoiceTypeStandard {
+		return fmt.Errorf("type '%s' cannot be settled", adv.Type)
+	}
+	if adv.Currency != inv.Currency {
+		return fmt.Errorf("currency '%s' does not match '%s'", adv.Currency, inv.Currency)
+	}
+	if !sameParty(adv.Supplier, inv.Supplier) {
+		return errors.New("supplier does not match")
+	}
+	if !sameParty(adv.Customer, inv.Customer) {
+		return errors.New("customer does not match")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func advanceInvoices(t *testing.T) (*bill.Invoice, *bill.Invoice) {
+	t.Helper()
+	adv := baseInvoice(t, splitLine("Down payment", 30000, tax.RateGeneral))
+	adv.Tax = nil
+	adv.UUID = uuid.V7()
+	adv.Series = "ADV"
+	adv.Code = "001"
+	adv.IssueDate = cal.MakeDate(2024, 1, 10)
+	adv.SetTags(tax.TagPartial)
+
+	inv := baseInvoice(t, splitLine("Project", 100000, tax.RateGeneral))
+	inv.Tax = nil
+	inv.IssueDate = cal.MakeDate(2024, 3, 10)
+	return inv, adv
+}
+
+func TestInvoiceSettleAdvances(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		inv, adv := advanceInvoices(t)
+		require.NoError(t, inv.SettleAdvances(adv))
+		require.NoError(t, inv.Validate())
+		require.Len(t, inv.Payment.Advances, 1)
+		a := inv.Payment.Advances[0]
+		assert.Equal(t, "Advance invoice ADV-001", a.Description)
+		assert.Equal(t, "2024-01-10", a.Date.String())
+		assert.Equal(t, "363.00", a.Amount.String())
+		require.NotNil(t, a.Invoice)
+		assert.Equal(t, adv.UUID, a.Invoice.UUID)
+		assert.Equal(t, "001", a.Invoice.Code.String())
+		assert.Equal(t, "363.00", a.Invoice.Payable.String())
+		assert.Equal(t, "63.00", a.Invoice.Tax.Sum.String())
+
+		assert.Equal(t, "1210.00", inv.Totals.Payable.String())
+		assert.Equal(t, "363.00", inv.Totals.Advances.String())
+		assert.Equal(t, "847.00", inv.Totals.Due.String())
+
+		st := inv.SettledTaxes()
+		require.NotNil(t, st)
+		assert.Equal(t, "63.00", st.Sum.String())
+		ot := inv.OutstandingTaxes()
+		require.NotNil(t, ot)
+		assert.Equal(t, "147.00", ot.Sum.String())
+		rt := ot.Category(tax.CategoryVAT).Rates[0]
+		assert.Equal(t, "700.00", rt.Base.String())
+		assert.Equal(t, "147.00", rt.Amount.String())
+
+		// the complete invoice taxes are untouched
+		assert.Equal(t, "210.00", inv.Totals.Taxes.Sum.String())
+	})
+
+	t.Run("multiple advance invoices", func(t *testing.T) {
+		inv, adv := advanceInvoices(t)
+		_, adv2 := advanceInvoices(t)
+		adv2.UUID = uuid.V7()
+		adv2.Code = "002"
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Cash", Amount: num.MakeAmount(1000, 2)},
+			},
+		}
+		require.NoError(t, inv.SettleAdvances(adv, adv2))
+		require.Len(t, inv.Payment.Advances, 3)
+		assert.Equal(t, "736.00", inv.Totals.Advances.String())
+		assert.Equal(t, "126.00", inv.SettledTaxes().Sum.String())
+		assert.Equal(t, "84.00", inv.OutstandingTaxes().Sum.String())
+
+		err := inv.SettleAdvances(adv2)
+		assert.ErrorContains(t, err, "advance invoice 0: '"+adv2.UUID.String()+"' already settled")
+	})
+
+	t.Run("exceeds payable", func(t *testing.T) {
+		inv, adv := advanceInvoices(t)
+		inv.Lines[0].Item.Price = num.NewAmount(20000, 2)
+		err := inv.SettleAdvances(adv)
+		assert.ErrorContains(t, err, "must not exceed payable amount")
+		assert.Nil(t, inv.Payment)
+		assert.Nil(t, inv.Totals.Advances)
+	})
+
+	t.Run("mismatches", func(t *testing.T) {
+		inv, adv := advanceInvoices(t)
+		assert.ErrorContains(t, inv.SettleAdvances(nil), "advance invoice 0: missing")
+
+		adv.Type = bill.InvoiceTypeCreditNote
+		err := inv.SettleAdvances(adv)
+		assert.ErrorContains(t, err, "advance invoice 0: type 'credit-note' cannot be settled")
+
+		inv, adv = advanceInvoices(t)
+		adv.Customer.TaxID.Code = "B85905495"
+		err = inv.SettleAdvances(adv)
+		assert.ErrorContains(t, err, "advance invoice 0: customer does not match")
+
+		inv, adv = advanceInvoices(t)
+		adv.Supplier.TaxID.Code = "B85905495"
+		err = inv.SettleAdvances(adv)
+		assert.ErrorContains(t, err, "advance invoice 0: supplier does not match")
+
+		inv, adv = advanceInvoices(t)
+		adv.Currency = currency.USD
+		adv.ExchangeRates = []*currency.ExchangeRate{
+			{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(875, 3)},
+		}
+		err = inv.SettleAdvances(adv)
+		assert.ErrorContains(t, err, "advance invoice 0: currency 'USD' does not match 'EUR'")
+	})
+
+	t.Run("no advance invoices", func(t *testing.T) {
+		inv, _ := advanceInvoices(t)
+		assert.Nil(t, inv.OutstandingTaxes())
+		require.NoError(t, inv.Calculate())
+		assert.Nil(t, inv.SettledTaxes())
+		assert.Equal(t, "210.00", inv.OutstandingTaxes().Sum.String())
+	})
+}
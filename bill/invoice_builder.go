@@ -0,0 +1,245 @@
+package bill
+
+import (
+	"strconv"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// InvoiceBuilder provides a fluent alternative to struct literals for
+// preparing invoices. Each component is normalized as it is added using
+// the regime and add-ons already defined, so these are best set first.
+// Problems found along the way are collected and reported by Build
+// alongside any calculation or validation errors.
+//
+// A builder prepares a single invoice and should not be used after Build.
+type InvoiceBuilder struct {
+	inv  *Invoice
+	errs validation.Errors
+}
+
+// NewInvoiceBuilder starts preparing a new standard invoice.
+func NewInvoiceBuilder() *InvoiceBuilder {
+	return &InvoiceBuilder{
+		inv: &Invoice{
+			Type: InvoiceTypeStandard,
+		},
+		errs: make(validation.Errors),
+	}
+}
+
+// Regime sets the tax regime of the invoice.
+func (b *InvoiceBuilder) Regime(country l10n.TaxCountryCode) *InvoiceBuilder {
+	b.inv.SetRegime(country)
+	return b
+}
+
+// Addons adds the add-ons to those applied to the invoice.
+func (b *InvoiceBuilder) Addons(keys ...cbc.Key) *InvoiceBuilder {
+	b.inv.SetAddons(append(b.inv.GetAddons(), keys...)...)
+	return b
+}
+
+// Tags adds the tags to those applied to the invoice.
+func (b *InvoiceBuilder) Tags(keys ...cbc.Key) *InvoiceBuilder {
+	b.inv.SetTags(append(b.inv.GetTags(), keys...)...)
+	return b
+}
+
+// Type sets the invoice type, which is standard by default.
+func (b *InvoiceBuilder) Type(typ cbc.Key) *InvoiceBuilder {
+	b.inv.Type = typ
+	return b
+}
+
+// Series sets the invoice series.
+func (b *InvoiceBuilder) Series(series cbc.Code) *InvoiceBuilder {
+	b.inv.Series = cbc.NormalizeCode(series)
+	return b
+}
+
+// Code sets the invoice's sequential code.
+func (b *InvoiceBuilder) Code(code cbc.Code) *InvoiceBuilder {
+	b.inv.Code = cbc.NormalizeCode(code)
+	return b
+}
+
+// IssueDate sets the date the invoice was issued, which is today by default.
+func (b *InvoiceBuilder) IssueDate(date cal.Date) *InvoiceBuilder {
+	b.inv.IssueDate = date
+	return b
+}
+
+// Currency sets the invoice's currency, which is otherwise taken from the
+// regime.
+func (b *InvoiceBuilder) Currency(cur currency.Code) *InvoiceBuilder {
+	b.inv.Currency = cur
+	return b
+}
+
+// PricesInclude indicates that line prices include the tax category.
+func (b *InvoiceBuilder) PricesInclude(cat cbc.Code) *InvoiceBuilder {
+	if b.inv.Tax == nil {
+		b.inv.Tax = new(Tax)
+	}
+	b.inv.Tax.PricesInclude = cat
+	return b
+}
+
+// Supplier sets the party supplying the goods or services.
+func (b *InvoiceBuilder) Supplier(p *org.Party) *InvoiceBuilder {
+	if p == nil {
+		b.fail(validation.ErrRequired, "supplier")
+		return b
+	}
+	p.Normalize(b.normalizers())
+	b.inv.Supplier = p
+	return b
+}
+
+// Customer sets the party receiving the goods or services.
+func (b *InvoiceBuilder) Customer(p *org.Party) *InvoiceBuilder {
+	if p == nil {
+		b.fail(validation.ErrRequired, "customer")
+		return b
+	}
+	p.Normalize(b.normalizers())
+	b.inv.Customer = p
+	return b
+}
+
+// Line adds a complete line to the invoice.
+func (b *InvoiceBuilder) Line(l *Line) *InvoiceBuilder {
+	if l == nil {
+		b.fail(validation.ErrRequired, "lines", strconv.Itoa(len(b.inv.Lines)))
+		return b
+	}
+	l.Normalize(b.normalizers())
+	b.inv.Lines = append(b.inv.Lines, l)
+	return b
+}
+
+// Item adds a line for the quantity of an item with the name and price
+// provided, parsing the numbers from their textual representations,
+// such as "2" and "10.50".
+func (b *InvoiceBuilder) Item(name, quantity, price string, taxes ...*tax.Combo) *InvoiceBuilder {
+	idx := strconv.Itoa(len(b.inv.Lines))
+	l := &Line{
+		Item:  &org.Item{Name: name},
+		Taxes: taxes,
+	}
+	q, err := num.AmountFromString(quantity)
+	if err != nil {
+		b.fail(err, "lines", idx, "quantity")
+	}
+	l.Quantity = q
+	p, err := num.AmountFromString(price)
+	if err != nil {
+		b.fail(err, "lines", idx, "item", "price")
+	}
+	l.Item.Price = &p
+	return b.Line(l)
+}
+
+// Discount adds a discount to the complete invoice.
+func (b *InvoiceBuilder) Discount(d *Discount) *InvoiceBuilder {
+	if d == nil {
+		b.fail(validation.ErrRequired, "discounts", strconv.Itoa(len(b.inv.Discounts)))
+		return b
+	}
+	d.Normalize(b.normalizers())
+	b.inv.Discounts = append(b.inv.Discounts, d)
+	return b
+}
+
+// Charge adds a charge to the complete invoice.
+func (b *InvoiceBuilder) Charge(c *Charge) *InvoiceBuilder {
+	if c == nil {
+		b.fail(validation.ErrRequired, "charges", strconv.Itoa(len(b.inv.Charges)))
+		return b
+	}
+	c.Normalize(b.normalizers())
+	b.inv.Charges = append(b.inv.Charges, c)
+	return b
+}
+
+// Terms sets the payment terms.
+func (b *InvoiceBuilder) Terms(t *pay.Terms) *InvoiceBuilder {
+	b.payment().Terms = t
+	return b
+}
+
+// Instructions sets the details on how payment should be made.
+func (b *InvoiceBuilder) Instructions(i *pay.Instructions) *InvoiceBuilder {
+	b.payment().Instructions = i
+	return b
+}
+
+// Preceding adds a reference to a previous document.
+func (b *InvoiceBuilder) Preceding(ref *org.DocumentRef) *InvoiceBuilder {
+	if ref == nil {
+		b.fail(validation.ErrRequired, "preceding", strconv.Itoa(len(b.inv.Preceding)))
+		return b
+	}
+	b.inv.Preceding = append(b.inv.Preceding, ref)
+	return b
+}
+
+// Note adds a note to the invoice.
+func (b *InvoiceBuilder) Note(n *org.Note) *InvoiceBuilder {
+	if n == nil {
+		b.fail(validation.ErrRequired, "notes", strconv.Itoa(len(b.inv.Notes)))
+		return b
+	}
+	b.inv.Notes = append(b.inv.Notes, n)
+	return b
+}
+
+// Build calculates and validates the invoice. Problems found while
+// building are returned using the same structure as validation errors,
+// before any attempt is made to calculate the invoice.
+func (b *InvoiceBuilder) Build() (*Invoice, error) {
+	if len(b.errs) > 0 {
+		return nil, b.errs
+	}
+	if err := b.inv.Calculate(); err != nil {
+		return nil, err
+	}
+	if err := b.inv.Validate(); err != nil {
+		return nil, err
+	}
+	return b.inv, nil
+}
+
+func (b *InvoiceBuilder) normalizers() tax.Normalizers {
+	return tax.ExtractNormalizers(b.inv)
+}
+
+func (b *InvoiceBuilder) payment() *PaymentDetails {
+	if b.inv.Payment == nil {
+		b.inv.Payment = new(PaymentDetails)
+	}
+	return b.inv.Payment
+}
+
+// fail records the error at the path of the invoice's property.
+func (b *InvoiceBuilder) fail(err error, path ...string) {
+	errs := b.errs
+	for _, p := range path[:len(path)-1] {
+		sub, ok := errs[p].(validation.Errors)
+		if !ok {
+			sub = make(validation.Errors)
+			errs[p] = sub
+		}
+		errs = sub
+	}
+	errs[path[len(path)-1]] = err
+}
@@ -0,0 +1,103 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func builderParties(b *bill.InvoiceBuilder) *bill.InvoiceBuilder {
+	return b.
+		Supplier(&org.Party{
+			Name: " Test Supplier ",
+			TaxID: &tax.Identity{
+				Country: "ES",
+				Code:    "B-98602642",
+			},
+		}).
+		Customer(&org.Party{
+			Name: "Test Customer",
+			TaxID: &tax.Identity{
+				Country: "ES",
+				Code:    "54387763P",
+			},
+		})
+}
+
+func TestInvoiceBuilder(t *testing.T) {
+	t.Run("complete invoice", func(t *testing.T) {
+		vat := &tax.Combo{Category: tax.CategoryVAT, Rate: tax.RateGeneral}
+		inv, err := builderParties(
+			bill.NewInvoiceBuilder().
+				Regime("ES").
+				Series(" TEST ").
+				Code("00123").
+				IssueDate(cal.MakeDate(2024, 6, 13)),
+		).
+			Item("Development services", "10", "100.00", vat).
+			Line(&bill.Line{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Support",
+					Price: num.NewAmount(5000, 2),
+				},
+				Taxes: tax.Set{{Category: tax.CategoryVAT, Rate: tax.RateGeneral}},
+			}).
+			Discount(&bill.Discount{
+				Reason: "Loyalty",
+				Amount: num.MakeAmount(5000, 2),
+				Taxes:  tax.Set{{Category: tax.CategoryVAT, Rate: tax.RateGeneral}},
+			}).
+			Terms(&pay.Terms{Key: pay.TermKeyInstant}).
+			Note(&org.Note{Text: "Thank you"}).
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, "Test Supplier", inv.Supplier.Name, "normalized as added")
+		assert.Equal(t, cbc.Code("B98602642"), inv.Supplier.TaxID.Code)
+		assert.Equal(t, cbc.Code("TEST"), inv.Series)
+		assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+		assert.Len(t, inv.Lines, 2)
+		assert.Equal(t, "1050.00", inv.Totals.Sum.String())
+		assert.Equal(t, "1000.00", inv.Totals.Total.String())
+		assert.Equal(t, "1210.00", inv.Totals.Payable.String())
+	})
+
+	t.Run("prices include tax", func(t *testing.T) {
+		inv, err := builderParties(bill.NewInvoiceBuilder().Regime("ES")).
+			PricesInclude(tax.CategoryVAT).
+			Item("Widget", "1", "121.00", &tax.Combo{Category: tax.CategoryVAT, Rate: tax.RateGeneral}).
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, "121.00", inv.Totals.Payable.String())
+		assert.Equal(t, "21.00", inv.Totals.Tax.String())
+	})
+
+	t.Run("builder errors", func(t *testing.T) {
+		_, err := bill.NewInvoiceBuilder().
+			Regime("ES").
+			Supplier(nil).
+			Item("Widget", "one", "10.00").
+			Item("Widget", "1", "ten").
+			Build()
+		assert.ErrorContains(t, err, "lines: (0: (quantity: invalid major number 'one'")
+		assert.ErrorContains(t, err, "1: (item: (price: invalid major number 'ten'")
+		assert.ErrorContains(t, err, "supplier: cannot be blank")
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		_, err := bill.NewInvoiceBuilder().
+			Regime("ES").
+			Customer(&org.Party{Name: "Test Customer"}).
+			Item("Widget", "1", "10.00", &tax.Combo{Category: tax.CategoryVAT, Rate: tax.RateGeneral}).
+			Build()
+		assert.ErrorContains(t, err, "supplier: cannot be blank")
+	})
+}
@@ -0,0 +1,140 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+)
+
+// InvoiceResolver is used to find the invoice identified by a document
+// reference, typically by looking up the envelope in a repository using
+// the UUID or series and code. If the document cannot be found, nil should
+// be returned without an error.
+type InvoiceResolver func(ref *org.DocumentRef) (*Invoice, error)
+
+// CorrectionBalance contains the net amounts of an original invoice after
+// applying the effect of all the corrections issued over it.
+type CorrectionBalance struct {
+	// Currency used in all the documents.
+	Currency currency.Code `json:"currency"`
+	// Net total excluding tax.
+	Total num.Amount `json:"total"`
+	// Net indirect tax.
+	Tax num.Amount `json:"tax"`
+	// Net total including tax.
+	TotalWithTax num.Amount `json:"total_with_tax"`
+	// Net amount payable.
+	Payable num.Amount `json:"payable"`
+}
+
+// CorrectionHistory walks through the preceding documents of the invoice
+// using the resolver, and provides the ordered list of invoices starting
+// with the original and ending with the current invoice. Each document
+// will only be included once, after all the documents it refers to.
+func (inv *Invoice) CorrectionHistory(resolve InvoiceResolver) ([]*Invoice, error) {
+	w := &chainWalker{
+		resolve: resolve,
+		state:   make(map[string]bool),
+	}
+	if err := w.visit(inv); err != nil {
+		return nil, err
+	}
+	return w.list, nil
+}
+
+type chainWalker struct {
+	resolve InvoiceResolver
+	state   map[string]bool // true when complete
+	list    []*Invoice
+}
+
+func (w *chainWalker) visit(inv *Invoice) error {
+	key := invoiceChainKey(inv)
+	if done, ok := w.state[key]; ok {
+		if !done {
+			return fmt.Errorf("correction chain loop at '%s'", key)
+		}
+		return nil
+	}
+	w.state[key] = false
+	for _, ref := range inv.Preceding {
+		if ref == nil {
+			continue
+		}
+		p, err := w.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("preceding document '%s' not found", refChainKey(ref))
+		}
+		if err := w.visit(p); err != nil {
+			return err
+		}
+	}
+	w.state[key] = true
+	w.list = append(w.list, inv)
+	return nil
+}
+
+func invoiceChainKey(inv *Invoice) string {
+	if !inv.UUID.IsZero() {
+		return inv.UUID.String()
+	}
+	return inv.Series.Join(inv.Code).String()
+}
+
+func refChainKey(ref *org.DocumentRef) string {
+	if !ref.UUID.IsZero() {
+		return ref.UUID.String()
+	}
+	return ref.Series.Join(ref.Code).String()
+}
+
+// NetCorrectionBalance determines the net effect of the ordered list of
+// invoices, as provided by CorrectionHistory. Standard and corrective
+// invoices set the balance, credit notes are subtracted, and debit notes
+// added. All the invoices must have been calculated and use the same
+// currency.
+func NetCorrectionBalance(history []*Invoice) (*CorrectionBalance, error) {
+	var b *CorrectionBalance
+	for _, inv := range history {
+		if inv.Totals == nil {
+			return nil, fmt.Errorf("invoice '%s' has no totals", invoiceChainKey(inv))
+		}
+		t := inv.Totals
+		if b == nil {
+			if inv.Type.In(InvoiceTypeCreditNote, InvoiceTypeDebitNote) {
+				return nil, fmt.Errorf("invoice '%s' cannot be the original", invoiceChainKey(inv))
+			}
+			b = &CorrectionBalance{Currency: inv.Currency}
+		}
+		if inv.Currency != b.Currency {
+			return nil, fmt.Errorf("invoice '%s' currency '%s' does not match '%s'", invoiceChainKey(inv), inv.Currency, b.Currency)
+		}
+		switch inv.Type {
+		case InvoiceTypeCreditNote:
+			b.Total = b.Total.Subtract(t.Total)
+			b.Tax = b.Tax.Subtract(t.Tax)
+			b.TotalWithTax = b.TotalWithTax.Subtract(t.TotalWithTax)
+			b.Payable = b.Payable.Subtract(t.Payable)
+		case InvoiceTypeDebitNote:
+			b.Total = b.Total.Add(t.Total)
+			b.Tax = b.Tax.Add(t.Tax)
+			b.TotalWithTax = b.TotalWithTax.Add(t.TotalWithTax)
+			b.Payable = b.Payable.Add(t.Payable)
+		default:
+			b.Total = t.Total
+			b.Tax = t.Tax
+			b.TotalWithTax = t.TotalWithTax
+			b.Payable = t.Payable
+		}
+	}
+	if b == nil {
+		return nil, errors.New("no invoices provided")
+	}
+	return b, nil
+}
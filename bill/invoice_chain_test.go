@@ -0,0 +1,120 @@
+package bill_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testChainInvoice(typ cbc.Key, code cbc.Code, payable int64, preceding ...*bill.Invoice) *bill.Invoice {
+	inv := &bill.Invoice{
+		Type:     typ,
+		Series:   "TEST",
+		Code:     code,
+		Currency: "EUR",
+		Totals: &bill.Totals{
+			Total:        num.MakeAmount(payable*100, 2),
+			Tax:          num.MakeAmount(payable*21, 2),
+			TotalWithTax: num.MakeAmount(payable*121, 2),
+			Payable:      num.MakeAmount(payable*121, 2),
+		},
+	}
+	inv.UUID = uuid.V7()
+	for _, p := range preceding {
+		inv.Preceding = append(inv.Preceding, &org.DocumentRef{
+			Series: p.Series,
+			Code:   p.Code,
+		})
+	}
+	return inv
+}
+
+func testChainResolver(invs ...*bill.Invoice) bill.InvoiceResolver {
+	return func(ref *org.DocumentRef) (*bill.Invoice, error) {
+		for _, inv := range invs {
+			if ref.Series == inv.Series && ref.Code == inv.Code {
+				return inv, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func TestInvoiceCorrectionHistory(t *testing.T) {
+	orig := testChainInvoice(bill.InvoiceTypeStandard, "001", 100)
+	cn1 := testChainInvoice(bill.InvoiceTypeCreditNote, "002", 20, orig)
+	dn := testChainInvoice(bill.InvoiceTypeDebitNote, "003", 5, orig)
+	cn2 := testChainInvoice(bill.InvoiceTypeCreditNote, "004", 10, cn1, dn)
+	resolve := testChainResolver(orig, cn1, dn, cn2)
+
+	list, err := cn2.CorrectionHistory(resolve)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	assert.Equal(t, orig, list[0])
+	assert.Equal(t, cn1, list[1])
+	assert.Equal(t, dn, list[2])
+	assert.Equal(t, cn2, list[3])
+
+	b, err := bill.NetCorrectionBalance(list)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", b.Currency.String())
+	assert.Equal(t, "75.00", b.Total.String())
+	assert.Equal(t, "15.75", b.Tax.String())
+	assert.Equal(t, "90.75", b.TotalWithTax.String())
+	assert.Equal(t, "90.75", b.Payable.String())
+
+	t.Run("corrective replaces", func(t *testing.T) {
+		cor := testChainInvoice(bill.InvoiceTypeCorrective, "005", 50, cn2)
+		list, err := cor.CorrectionHistory(testChainResolver(orig, cn1, dn, cn2))
+		require.NoError(t, err)
+		require.Len(t, list, 5)
+		b, err := bill.NetCorrectionBalance(list)
+		require.NoError(t, err)
+		assert.Equal(t, "60.50", b.Payable.String())
+	})
+
+	t.Run("missing document", func(t *testing.T) {
+		_, err := cn2.CorrectionHistory(testChainResolver(cn1, dn))
+		assert.ErrorContains(t, err, "preceding document 'TEST-001' not found")
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		_, err := cn1.CorrectionHistory(func(_ *org.DocumentRef) (*bill.Invoice, error) {
+			return nil, errors.New("lookup failed")
+		})
+		assert.ErrorContains(t, err, "lookup failed")
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		a := testChainInvoice(bill.InvoiceTypeStandard, "010", 10)
+		b := testChainInvoice(bill.InvoiceTypeCreditNote, "011", 10, a)
+		a.Preceding = []*org.DocumentRef{{Series: "TEST", Code: "011"}}
+		_, err := b.CorrectionHistory(testChainResolver(a, b))
+		assert.ErrorContains(t, err, "correction chain loop")
+	})
+}
+
+func TestNetCorrectionBalanceErrors(t *testing.T) {
+	_, err := bill.NetCorrectionBalance(nil)
+	assert.ErrorContains(t, err, "no invoices provided")
+
+	cn := testChainInvoice(bill.InvoiceTypeCreditNote, "002", 20)
+	_, err = bill.NetCorrectionBalance([]*bill.Invoice{cn})
+	assert.ErrorContains(t, err, "cannot be the original")
+
+	orig := testChainInvoice(bill.InvoiceTypeStandard, "001", 100)
+	cn.Currency = "USD"
+	_, err = bill.NetCorrectionBalance([]*bill.Invoice{orig, cn})
+	assert.ErrorContains(t, err, "currency 'USD' does not match 'EUR'")
+
+	cn.Totals = nil
+	_, err = bill.NetCorrectionBalance([]*bill.Invoice{orig, cn})
+	assert.ErrorContains(t, err, "has no totals")
+}
@@ -0,0 +1,197 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/uuid"
+)
+
+// ConsolidateOptions defines the details of the summary invoice prepared
+// by ConsolidateInvoices.
+type ConsolidateOptions struct {
+	// Series to use for the new invoice, otherwise the series of the
+	// first invoice will be used.
+	Series cbc.Code
+	// Code to assign to the new invoice, if already known.
+	Code cbc.Code
+	// IssueDate of the new invoice, which will be today if empty.
+	IssueDate *cal.Date
+	// Sequence to assign the next code to the new invoice when no code
+	// was provided.
+	Sequence SequenceProvider
+}
+
+// ConsolidateInvoices merges the lines of multiple standard invoices issued
+// by the same supplier to the same customer into a single summary invoice,
+// as typically required for periodic billing.
+//
+// The new invoice is based on the first in the list, whose payment terms,
+// notes, and other details will be maintained. Discounts, charges, and
+// advances from all the invoices are included with fixed amounts so that
+// their original effect is preserved. Each of the originals is referenced
+// in the list of preceding documents, and the ordering period will cover
+// the issue dates of all of them.
+//
+// All the invoices must use the same regime, currency, and tax options.
+// They will be calculated as part of the process.
+func ConsolidateInvoices(invs []*Invoice, opts *ConsolidateOptions) (*Invoice, error) {
+	if len(invs) == 0 {
+		return nil, errors.New("no invoices to consolidate")
+	}
+	if opts == nil {
+		opts = new(ConsolidateOptions)
+	}
+	for i, inv := range invs {
+		if inv == nil {
+			return nil, fmt.Errorf("invoice %d: missing", i)
+		}
+		if err := inv.Calculate(); err != nil {
+			return nil, fmt.Errorf("invoice %d: %w", i, err)
+		}
+		if err := checkConsolidatable(invs[0], inv); err != nil {
+			return nil, fmt.Errorf("invoice %d: %w", i, err)
+		}
+	}
+
+	out, err := invs[0].clone()
+	if err != nil {
+		return nil, err
+	}
+	out.UUID = uuid.Empty
+	if opts.Series != "" {
+		out.Series = opts.Series
+	}
+	out.Code = opts.Code
+	if opts.IssueDate != nil {
+		out.IssueDate = *opts.IssueDate
+	} else {
+		out.IssueDate = cal.Today()
+	}
+	out.OperationDate = nil
+	out.ValueDate = nil
+	out.Totals = nil
+	out.Lines = nil
+	out.Discounts = nil
+	out.Charges = nil
+	out.Preceding = nil
+	if out.Payment != nil {
+		out.Payment.Advances = nil
+	}
+
+	period := new(cal.Period)
+	for i, inv := range invs {
+		c, err := inv.clone()
+		if err != nil {
+			return nil, err
+		}
+		out.Lines = append(out.Lines, c.Lines...)
+		out.Discounts = append(out.Discounts, consolidateDiscounts(c)...)
+		out.Charges = append(out.Charges, consolidateCharges(c)...)
+		if c.Payment != nil && len(c.Payment.Advances) > 0 {
+			if out.Payment == nil {
+				out.Payment = new(PaymentDetails)
+			}
+			for _, a := range c.Payment.Advances {
+				// amounts were calculated from the original invoice
+				a.Percent = nil
+				out.Payment.Advances = append(out.Payment.Advances, a)
+			}
+		}
+		out.Preceding = append(out.Preceding, &org.DocumentRef{
+			Identify:  uuid.Identify{UUID: inv.UUID},
+			Type:      inv.Type,
+			Series:    inv.Series,
+			Code:      inv.Code,
+			IssueDate: inv.IssueDate.Clone(),
+		})
+		if i == 0 || inv.IssueDate.Before(period.Start.Date) {
+			period.Start = inv.IssueDate
+		}
+		if i == 0 || inv.IssueDate.After(period.End.Date) {
+			period.End = inv.IssueDate
+		}
+	}
+	if out.Ordering == nil {
+		out.Ordering = new(Ordering)
+	}
+	out.Ordering.Period = period
+
+	if err := out.Calculate(); err != nil {
+		return nil, err
+	}
+	if out.Code == "" && opts.Sequence != nil {
+		if err := out.AssignCode(opts.Sequence); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func checkConsolidatable(first, inv *Invoice) error {
+	if inv.Type != InvoiceTypeStandard {
+		return fmt.Errorf("type '%s' cannot be consolidated", inv.Type)
+	}
+	if inv.GetRegime() != first.GetRegime() {
+		return fmt.Errorf("regime '%s' does not match '%s'", inv.GetRegime(), first.GetRegime())
+	}
+	if inv.Currency != first.Currency {
+		return fmt.Errorf("currency '%s' does not match '%s'", inv.Currency, first.Currency)
+	}
+	if pricesInclude(inv) != pricesInclude(first) {
+		return errors.New("tax prices include does not match")
+	}
+	if !sameParty(inv.Supplier, first.Supplier) {
+		return errors.New("supplier does not match")
+	}
+	if !sameParty(inv.Customer, first.Customer) {
+		return errors.New("customer does not match")
+	}
+	return nil
+}
+
+func pricesInclude(inv *Invoice) cbc.Code {
+	if inv.Tax == nil {
+		return cbc.CodeEmpty
+	}
+	return inv.Tax.PricesInclude
+}
+
+// sameParty compares parties using their tax identities when available,
+// or their names otherwise.
+func sameParty(a, b *org.Party) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.TaxID != nil && b.TaxID != nil && a.TaxID.Code != cbc.CodeEmpty {
+		return a.TaxID.Country == b.TaxID.Country && a.TaxID.Code == b.TaxID.Code
+	}
+	return a.Name == b.Name
+}
+
+// consolidateDiscounts provides the invoice's discounts ensuring that any
+// percentages are applied to the original invoice's sum.
+func consolidateDiscounts(inv *Invoice) []*Discount {
+	for _, d := range inv.Discounts {
+		if d.Percent != nil && d.Base == nil {
+			sum := inv.Totals.Sum
+			d.Base = &sum
+		}
+	}
+	return inv.Discounts
+}
+
+// consolidateCharges provides the invoice's charges ensuring that any
+// percentages are applied to the original invoice's sum.
+func consolidateCharges(inv *Invoice) []*Charge {
+	for _, c := range inv.Charges {
+		if c.Percent != nil && c.Base == nil {
+			sum := inv.Totals.Sum
+			c.Base = &sum
+		}
+	}
+	return inv.Charges
+}
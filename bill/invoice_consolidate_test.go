@@ -0,0 +1,147 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func consolidateInvoices(t *testing.T) []*bill.Invoice {
+	t.Helper()
+	inv1 := baseInvoice(t,
+		splitLine("A", 10000, tax.RateGeneral),
+		splitLine("B", 5000, tax.RateReduced),
+	)
+	inv1.Tax = nil
+	inv1.UUID = uuid.V7()
+	inv1.Code = "001"
+	inv1.IssueDate = cal.MakeDate(2024, 3, 5)
+	inv2 := baseInvoice(t,
+		splitLine("C", 8000, tax.RateGeneral),
+	)
+	inv2.Tax = nil
+	inv2.Code = "002"
+	inv2.IssueDate = cal.MakeDate(2024, 3, 20)
+	return []*bill.Invoice{inv1, inv2}
+}
+
+func TestConsolidateInvoices(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		invs := consolidateInvoices(t)
+		date := cal.MakeDate(2024, 3, 31)
+		inv, err := bill.ConsolidateInvoices(invs, &bill.ConsolidateOptions{
+			Series:    "SUM",
+			IssueDate: &date,
+		})
+		require.NoError(t, err)
+		require.NoError(t, inv.Validate())
+		assert.Empty(t, inv.UUID)
+		assert.Equal(t, "SUM", inv.Series.String())
+		assert.Empty(t, inv.Code)
+		assert.Equal(t, "2024-03-31", inv.IssueDate.String())
+		require.Len(t, inv.Lines, 3)
+		assert.Equal(t, "C", inv.Lines[2].Item.Name)
+		assert.Equal(t, 3, inv.Lines[2].Index)
+		assert.Equal(t, "230.00", inv.Totals.Sum.String())
+		assert.Equal(t, "272.80", inv.Totals.Payable.String())
+
+		require.Len(t, inv.Preceding, 2)
+		assert.Equal(t, invs[0].UUID, inv.Preceding[0].UUID)
+		assert.Equal(t, "001", inv.Preceding[0].Code.String())
+		assert.Equal(t, "TEST", inv.Preceding[0].Series.String())
+		assert.Equal(t, "2024-03-05", inv.Preceding[0].IssueDate.String())
+		assert.Equal(t, "002", inv.Preceding[1].Code.String())
+		assert.Equal(t, "2024-03-05", inv.Ordering.Period.Start.String())
+		assert.Equal(t, "2024-03-20", inv.Ordering.Period.End.String())
+
+		// originals unchanged
+		assert.Len(t, invs[0].Lines, 2)
+		assert.Equal(t, "001", invs[0].Code.String())
+	})
+
+	t.Run("with sequence", func(t *testing.T) {
+		invs := consolidateInvoices(t)
+		seq := bill.NewMemorySequence("")
+		inv, err := bill.ConsolidateInvoices(invs, &bill.ConsolidateOptions{
+			Series:   "SUM",
+			Sequence: seq,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "1", inv.Code.String())
+
+		inv, err = bill.ConsolidateInvoices(invs, &bill.ConsolidateOptions{
+			Series:   "SUM",
+			Code:     "100",
+			Sequence: seq,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "100", inv.Code.String())
+	})
+
+	t.Run("discounts, charges, and advances", func(t *testing.T) {
+		invs := consolidateInvoices(t)
+		invs[0].Discounts = []*bill.Discount{
+			{Reason: "Volume", Percent: num.NewPercentage(10, 2)},
+		}
+		invs[1].Charges = []*bill.Charge{
+			{Reason: "Handling", Amount: num.MakeAmount(500, 2)},
+		}
+		invs[1].Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Half", Percent: num.NewPercentage(50, 2)},
+			},
+		}
+		inv, err := bill.ConsolidateInvoices(invs, nil)
+		require.NoError(t, err)
+		assert.Equal(t, cal.Today(), inv.IssueDate)
+		require.Len(t, inv.Discounts, 1)
+		assert.Equal(t, "150.00", inv.Discounts[0].Base.String())
+		assert.Equal(t, "15.00", inv.Discounts[0].Amount.String())
+		require.Len(t, inv.Charges, 1)
+		assert.Equal(t, "5.00", inv.Charges[0].Amount.String())
+		require.Len(t, inv.Payment.Advances, 1)
+		assert.Nil(t, inv.Payment.Advances[0].Percent)
+		assert.Equal(t, invs[1].Payment.Advances[0].Amount, inv.Payment.Advances[0].Amount)
+	})
+
+	t.Run("mismatches", func(t *testing.T) {
+		_, err := bill.ConsolidateInvoices(nil, nil)
+		assert.ErrorContains(t, err, "no invoices to consolidate")
+
+		invs := consolidateInvoices(t)
+		invs[1].Type = bill.InvoiceTypeCreditNote
+		_, err = bill.ConsolidateInvoices(invs, nil)
+		assert.ErrorContains(t, err, "invoice 1: type 'credit-note' cannot be consolidated")
+
+		invs = consolidateInvoices(t)
+		invs[1].Customer.TaxID.Code = "B85905495"
+		_, err = bill.ConsolidateInvoices(invs, nil)
+		assert.ErrorContains(t, err, "invoice 1: customer does not match")
+
+		invs = consolidateInvoices(t)
+		invs[1].Supplier.TaxID.Code = "B85905495"
+		_, err = bill.ConsolidateInvoices(invs, nil)
+		assert.ErrorContains(t, err, "invoice 1: supplier does not match")
+
+		invs = consolidateInvoices(t)
+		invs[1].Currency = currency.USD
+		invs[1].ExchangeRates = []*currency.ExchangeRate{
+			{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(875, 3)},
+		}
+		_, err = bill.ConsolidateInvoices(invs, nil)
+		assert.ErrorContains(t, err, "invoice 1: currency 'USD' does not match 'EUR'")
+
+		invs = consolidateInvoices(t)
+		invs[1].Tax = &bill.Tax{PricesInclude: tax.CategoryVAT}
+		_, err = bill.ConsolidateInvoices(invs, nil)
+		assert.ErrorContains(t, err, "invoice 1: tax prices include does not match")
+	})
+}
@@ -40,6 +40,9 @@ type CorrectionOptions struct {
 	// preceding document data.
 	CopyTax bool `json:"copy_tax,omitempty" jsonschema:"title=Copy Tax Totals"`
 
+	// Sequence used to assign a code to the corrective invoice, if any.
+	Sequence SequenceProvider `json:"-"`
+
 	// In case we want to use a raw json object as a source of the options.
 	data json.RawMessage `json:"-"`
 }
@@ -120,6 +123,15 @@ func WithCopyTax() schema.Option {
 	}
 }
 
+// WithSequence provides a sequence that will be used to assign the next
+// code to the corrective invoice once it has been prepared.
+func WithSequence(seq SequenceProvider) schema.Option {
+	return func(o interface{}) {
+		opts := o.(*CorrectionOptions)
+		opts.Sequence = seq
+	}
+}
+
 // Corrective is used for creating corrective or rectified invoices
 // that completely replace a previous document.
 var Corrective schema.Option = func(o interface{}) {
@@ -273,7 +285,9 @@ func (inv *Invoice) CorrectionOptionsSchema() (interface{}, error) {
 // structure and performs any regime specific actions defined by the
 // regime's configuration.
 // If the existing document doesn't have a code, we'll raise an error, for
-// most use cases this will prevent looping over the same invoice.
+// most use cases this will prevent looping over the same invoice. When a
+// sequence is provided, the next code will be assigned to the corrective
+// invoice after it has been validated.
 func (inv *Invoice) Correct(opts ...schema.Option) error {
 	o := new(CorrectionOptions)
 	if err := prepareCorrectionOptions(o, opts...); err != nil {
@@ -319,7 +333,13 @@ func (inv *Invoice) Correct(opts ...schema.Option) error {
 	// Running a Calculate feels a bit out of place, but not performing
 	// this operation on the corrected invoice results in potentially
 	// conflicting or incomplete data.
-	return inv.Calculate()
+	if err := inv.Calculate(); err != nil {
+		return err
+	}
+	if o.Sequence != nil {
+		return inv.AssignCode(o.Sequence)
+	}
+	return nil
 }
 
 // correctionDef tries to determine a final correction definition
@@ -88,6 +88,15 @@ func TestInvoiceCorrect(t *testing.T) {
 		assert.Equal(t, inv.Preceding[0].Series.String(), "TEST")
 	})
 
+	t.Run("with sequence", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		seq := bill.NewMemorySequence("%03d")
+		err := inv.Correct(bill.Credit, bill.WithSeries("R-TEST"), bill.WithSequence(seq))
+		require.NoError(t, err)
+		assert.Equal(t, "R-TEST", inv.Series.String())
+		assert.Equal(t, "001", inv.Code.String())
+	})
+
 	t.Run("with taxes", func(t *testing.T) {
 		inv := testInvoiceESForCorrection(t)
 		require.NoError(t, inv.Calculate())
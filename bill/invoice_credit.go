@@ -0,0 +1,100 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/schema"
+)
+
+// CreditLine identifies a line of an invoice to include in a credit note.
+type CreditLine struct {
+	// Index of the line in the original invoice, as assigned during
+	// calculation and starting from 1.
+	Index int `json:"i" jsonschema:"title=Index"`
+	// Quantity to credit, which must not be more than the original line's
+	// quantity. The complete quantity will be credited if empty.
+	Quantity *num.Amount `json:"quantity,omitempty" jsonschema:"title=Quantity"`
+}
+
+// CreditLines prepares a new credit note that will partially cancel the
+// invoice by including only the selected lines and quantities. The
+// invoice itself will not be modified.
+//
+// Amounts in the credit note will remain positive as the invoice type
+// already implies their effect. Discounts and charges with percentages
+// are maintained so that they apply to the credited lines in the same
+// way as before, while fixed amounts and advances are removed as they
+// cannot be divided between lines.
+//
+// Correction options are handled in the same way as for Correct, so the
+// credit note will reference the invoice as a preceding document along
+// with the indexes of the credited lines, and regime or add-on specific
+// extensions will be assigned during calculation. Tax totals will also
+// be copied to the preceding data if the correction definition requests
+// them.
+func (inv *Invoice) CreditLines(sel []*CreditLine, opts ...schema.Option) (*Invoice, error) {
+	if len(sel) == 0 {
+		return nil, errors.New("no lines selected to credit")
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	cn, err := inv.clone()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int]*Line, len(cn.Lines))
+	for _, l := range cn.Lines {
+		lines[l.Index] = l
+	}
+	indexes := make([]int, 0, len(sel))
+	cn.Lines = make([]*Line, 0, len(sel))
+	for _, s := range sel {
+		if s == nil {
+			return nil, errors.New("credit line missing")
+		}
+		l, ok := lines[s.Index]
+		if !ok {
+			return nil, fmt.Errorf("line %d: not found", s.Index)
+		}
+		if l == nil {
+			return nil, fmt.Errorf("line %d: already selected", s.Index)
+		}
+		lines[s.Index] = nil
+		if s.Quantity != nil {
+			if !s.Quantity.IsPositive() {
+				return nil, fmt.Errorf("line %d: quantity must be positive", s.Index)
+			}
+			if s.Quantity.Compare(l.Quantity) > 0 {
+				return nil, fmt.Errorf("line %d: quantity exceeds original %s", s.Index, l.Quantity)
+			}
+			l.Quantity = *s.Quantity
+		}
+		cn.Lines = append(cn.Lines, l)
+		indexes = append(indexes, s.Index)
+	}
+
+	cn.Discounts = filterSplitDiscounts(cn.Discounts)
+	cn.Charges = filterSplitCharges(cn.Charges)
+	if cn.Payment != nil {
+		cn.Payment.ResetAdvances()
+	}
+
+	if cd := inv.correctionDef(); cd != nil && cd.CopyTax {
+		opts = append([]schema.Option{WithCopyTax()}, opts...)
+	}
+	// credit type is always enforced
+	opts = append(opts, Credit)
+	if err := cn.Correct(opts...); err != nil {
+		return nil, err
+	}
+	if cn.Type != InvoiceTypeCreditNote {
+		return nil, fmt.Errorf("invalid correction type: %v", cn.Type.String())
+	}
+	cn.Preceding[0].Lines = indexes
+
+	return cn, nil
+}
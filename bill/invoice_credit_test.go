@@ -0,0 +1,146 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/es/facturae"
+	"github.com/invopop/gobl/addons/es/verifactu"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoiceESForCredit(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := testInvoiceESForCorrection(t)
+	inv.Lines = append(inv.Lines,
+		&bill.Line{
+			Quantity: num.MakeAmount(4, 0),
+			Item: &org.Item{
+				Name:  "Second Item",
+				Price: num.NewAmount(5000, 2),
+			},
+			Taxes: tax.Set{
+				{
+					Category: "VAT",
+					Rate:     "reduced",
+				},
+			},
+		},
+	)
+	return inv
+}
+
+func TestInvoiceCreditLines(t *testing.T) {
+	t.Run("partial quantity", func(t *testing.T) {
+		inv := testInvoiceESForCredit(t)
+		cn, err := inv.CreditLines(
+			[]*bill.CreditLine{
+				{Index: 2, Quantity: num.NewAmount(1, 0)},
+			},
+			bill.WithReason("returned item"),
+			bill.WithExtension(facturae.ExtKeyCorrection, "10"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, bill.InvoiceTypeCreditNote, cn.Type)
+		assert.Empty(t, cn.Code)
+		require.Len(t, cn.Lines, 1)
+		assert.Equal(t, 1, cn.Lines[0].Index)
+		assert.Equal(t, "Second Item", cn.Lines[0].Item.Name)
+		assert.Equal(t, "1", cn.Lines[0].Quantity.String())
+		assert.Equal(t, "50.00", cn.Totals.Payable.String())
+
+		require.Len(t, cn.Preceding, 1)
+		pre := cn.Preceding[0]
+		assert.Equal(t, "123", pre.Code.String())
+		assert.Equal(t, "returned item", pre.Reason)
+		assert.Equal(t, []int{2}, pre.Lines)
+		assert.Equal(t, "10", pre.Ext[facturae.ExtKeyCorrection].String())
+		assert.Nil(t, pre.Tax)
+
+		// original unchanged
+		assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+		assert.Equal(t, "123", inv.Code.String())
+		assert.Len(t, inv.Lines, 2)
+		assert.Equal(t, "4", inv.Lines[1].Quantity.String())
+	})
+
+	t.Run("complete lines", func(t *testing.T) {
+		inv := testInvoiceESForCredit(t)
+		inv.Discounts = []*bill.Discount{
+			{Reason: "Volume", Percent: num.NewPercentage(10, 2)},
+			{Reason: "Loyalty", Amount: num.MakeAmount(1000, 2)},
+		}
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Deposit", Amount: num.MakeAmount(10000, 2)},
+			},
+		}
+		cn, err := inv.CreditLines(
+			[]*bill.CreditLine{{Index: 2}, {Index: 1}},
+			bill.WithExtension(facturae.ExtKeyCorrection, "01"),
+		)
+		require.NoError(t, err)
+		require.Len(t, cn.Lines, 2)
+		assert.Equal(t, "Second Item", cn.Lines[0].Item.Name)
+		assert.Equal(t, "4", cn.Lines[0].Quantity.String())
+		assert.Equal(t, "10", cn.Lines[1].Quantity.String())
+		require.Len(t, cn.Discounts, 1)
+		assert.Equal(t, "Volume", cn.Discounts[0].Reason)
+		assert.Empty(t, cn.Payment.Advances)
+		assert.Equal(t, []int{2, 1}, cn.Preceding[0].Lines)
+	})
+
+	t.Run("copy tax from definition", func(t *testing.T) {
+		inv := testInvoiceESForCredit(t)
+		inv.Addons = tax.WithAddons(verifactu.V1)
+		cn, err := inv.CreditLines(
+			[]*bill.CreditLine{{Index: 1}},
+			bill.WithExtension(verifactu.ExtKeyDocType, "R1"),
+		)
+		require.NoError(t, err)
+		pre := cn.Preceding[0]
+		require.NotNil(t, pre.Tax)
+		assert.Equal(t, inv.Totals.Taxes.Sum, pre.Tax.Sum)
+		assert.Equal(t, "R1", cn.Tax.Ext[verifactu.ExtKeyDocType].String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		inv := testInvoiceESForCredit(t)
+		_, err := inv.CreditLines(nil)
+		assert.ErrorContains(t, err, "no lines selected to credit")
+
+		_, err = inv.CreditLines([]*bill.CreditLine{nil})
+		assert.ErrorContains(t, err, "credit line missing")
+
+		_, err = inv.CreditLines([]*bill.CreditLine{{Index: 3}})
+		assert.ErrorContains(t, err, "line 3: not found")
+
+		_, err = inv.CreditLines([]*bill.CreditLine{{Index: 1}, {Index: 1}})
+		assert.ErrorContains(t, err, "line 1: already selected")
+
+		_, err = inv.CreditLines([]*bill.CreditLine{
+			{Index: 1, Quantity: num.NewAmount(0, 0)},
+		})
+		assert.ErrorContains(t, err, "line 1: quantity must be positive")
+
+		_, err = inv.CreditLines([]*bill.CreditLine{
+			{Index: 2, Quantity: num.NewAmount(5, 0)},
+		})
+		assert.ErrorContains(t, err, "line 2: quantity exceeds original 4")
+
+		_, err = inv.CreditLines(
+			[]*bill.CreditLine{{Index: 1}},
+			bill.WithData([]byte(`{"type":"corrective"}`)),
+		)
+		assert.ErrorContains(t, err, "invalid correction type: corrective")
+
+		inv.Code = ""
+		_, err = inv.CreditLines([]*bill.CreditLine{{Index: 1}})
+		assert.ErrorContains(t, err, "cannot correct an invoice without a code")
+	})
+}
@@ -0,0 +1,381 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Change keys describe what happened to the value at a path.
+const (
+	ChangeKeyAdded    cbc.Key = "added"
+	ChangeKeyRemoved  cbc.Key = "removed"
+	ChangeKeyModified cbc.Key = "modified"
+)
+
+// Change describes a single difference between two invoices.
+type Change struct {
+	// Key describing the type of change.
+	Key cbc.Key `json:"key"`
+	// Path to the value that changed, using the JSON field names and
+	// zero based array positions, e.g. "lines[0].quantity".
+	Path string `json:"path"`
+	// From contains the original value, if any.
+	From string `json:"from,omitempty"`
+	// To contains the new value, if any.
+	To string `json:"to,omitempty"`
+}
+
+// Changes contains the differences found between two invoices, grouped
+// by the part of the document they affect.
+type Changes struct {
+	// Fields of the document header and parties.
+	Fields []*Change `json:"fields,omitempty"`
+	// Lines added, removed, or modified.
+	Lines []*Change `json:"lines,omitempty"`
+	// Taxes contains the changes in the tax totals for each rate.
+	Taxes []*Change `json:"taxes,omitempty"`
+	// Totals contains the changes in the document's totals.
+	Totals []*Change `json:"totals,omitempty"`
+}
+
+// Diff compares two invoices and provides the set of changes required to
+// go from a to b, in terms that make sense for invoices: document details,
+// lines added, removed, or modified, and the resulting changes in taxes and
+// totals.
+//
+// Lines are matched using their item's reference, or the name if no
+// reference is available, so that the order of lines does not matter.
+// Paths for added or modified lines use the position in b, while those
+// removed use the position in a.
+//
+// Both invoices are calculated beforehand using copies, so neither will be
+// modified.
+func Diff(a, b *Invoice) (*Changes, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("two invoices required")
+	}
+	a, err := a.clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Calculate(); err != nil {
+		return nil, fmt.Errorf("a: %w", err)
+	}
+	b, err = b.clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Calculate(); err != nil {
+		return nil, fmt.Errorf("b: %w", err)
+	}
+
+	ch := new(Changes)
+	ch.diffFields(a, b)
+	ch.diffLines(a.Lines, b.Lines)
+	ch.diffTotals(a.Totals, b.Totals)
+	return ch, nil
+}
+
+// Empty returns true if no changes were found.
+func (ch *Changes) Empty() bool {
+	return len(ch.All()) == 0
+}
+
+// All provides a single list with all the changes.
+func (ch *Changes) All() []*Change {
+	if ch == nil {
+		return nil
+	}
+	list := make([]*Change, 0, len(ch.Fields)+len(ch.Lines)+len(ch.Taxes)+len(ch.Totals))
+	list = append(list, ch.Fields...)
+	list = append(list, ch.Lines...)
+	list = append(list, ch.Taxes...)
+	list = append(list, ch.Totals...)
+	return list
+}
+
+// String renders the changes with one per line.
+func (ch *Changes) String() string {
+	list := ch.All()
+	rows := make([]string, len(list))
+	for i, c := range list {
+		rows[i] = c.String()
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Note provides the rendered changes inside a general note, ready to be
+// included in a corrective document.
+func (ch *Changes) Note() *org.Note {
+	return &org.Note{
+		Key:  org.NoteKeyGeneral,
+		Text: ch.String(),
+	}
+}
+
+// String provides a human readable description of the change.
+func (c *Change) String() string {
+	switch c.Key {
+	case ChangeKeyAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, c.To)
+	case ChangeKeyRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, c.From)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.From, c.To)
+}
+
+func (ch *Changes) diffFields(a, b *Invoice) {
+	list := &ch.Fields
+	compareValues(list, "type", a.Type.String(), b.Type.String())
+	compareValues(list, "series", a.Series.String(), b.Series.String())
+	compareValues(list, "code", a.Code.String(), b.Code.String())
+	compareValues(list, "issue_date", a.IssueDate.String(), b.IssueDate.String())
+	compareValues(list, "operation_date", diffDate(a.OperationDate), diffDate(b.OperationDate))
+	compareValues(list, "currency", a.Currency.String(), b.Currency.String())
+	diffParty(list, "supplier", a.Supplier, b.Supplier)
+	diffParty(list, "customer", a.Customer, b.Customer)
+}
+
+func (ch *Changes) diffLines(al, bl []*Line) {
+	matched := make([]bool, len(al))
+	for bi, l := range bl {
+		if l == nil || l.Item == nil {
+			continue
+		}
+		path := fmt.Sprintf("lines[%d]", bi)
+		ai := findDiffLine(al, matched, lineItemKey(l))
+		if ai < 0 {
+			ch.Lines = append(ch.Lines, &Change{
+				Key:  ChangeKeyAdded,
+				Path: path,
+				To:   l.Item.Name,
+			})
+			continue
+		}
+		matched[ai] = true
+		ch.diffLine(path, al[ai], l)
+	}
+	for ai, l := range al {
+		if matched[ai] || l == nil || l.Item == nil {
+			continue
+		}
+		ch.Lines = append(ch.Lines, &Change{
+			Key:  ChangeKeyRemoved,
+			Path: fmt.Sprintf("lines[%d]", ai),
+			From: l.Item.Name,
+		})
+	}
+}
+
+func (ch *Changes) diffLine(path string, a, b *Line) {
+	list := &ch.Lines
+	compareValues(list, path+".quantity", a.Quantity.String(), b.Quantity.String())
+	compareValues(list, path+".item.name", a.Item.Name, b.Item.Name)
+	compareValues(list, path+".item.price", diffAmount(a.Item.Price), diffAmount(b.Item.Price))
+	compareValues(list, path+".item.unit", string(a.Item.Unit), string(b.Item.Unit))
+	compareValues(list, path+".sum", diffAmount(a.Sum), diffAmount(b.Sum))
+	compareValues(list, path+".discounts", diffLineDiscounts(a), diffLineDiscounts(b))
+	compareValues(list, path+".charges", diffLineCharges(a), diffLineCharges(b))
+	compareValues(list, path+".taxes", diffTaxSet(a.Taxes), diffTaxSet(b.Taxes))
+	compareValues(list, path+".total", diffAmount(a.Total), diffAmount(b.Total))
+}
+
+func (ch *Changes) diffTotals(a, b *Totals) {
+	if a == nil || b == nil {
+		return
+	}
+	list := &ch.Totals
+	compareValues(list, "totals.sum", a.Sum.String(), b.Sum.String())
+	compareValues(list, "totals.discount", diffAmount(a.Discount), diffAmount(b.Discount))
+	compareValues(list, "totals.charge", diffAmount(a.Charge), diffAmount(b.Charge))
+	compareValues(list, "totals.total", a.Total.String(), b.Total.String())
+	compareValues(list, "totals.tax", a.Tax.String(), b.Tax.String())
+	compareValues(list, "totals.total_with_tax", a.TotalWithTax.String(), b.TotalWithTax.String())
+	compareValues(list, "totals.retained_tax", diffAmount(a.RetainedTax), diffAmount(b.RetainedTax))
+	compareValues(list, "totals.payable", a.Payable.String(), b.Payable.String())
+	compareValues(list, "totals.advance", diffAmount(a.Advances), diffAmount(b.Advances))
+	compareValues(list, "totals.due", diffAmount(a.Due), diffAmount(b.Due))
+	ch.diffTaxes(a.Taxes, b.Taxes)
+}
+
+func (ch *Changes) diffTaxes(a, b *tax.Total) {
+	ar := diffRateTotals(a)
+	br := diffRateTotals(b)
+	for _, k := range ar.keys {
+		path := "totals.taxes." + k
+		art := ar.rates[k]
+		brt, ok := br.rates[k]
+		if !ok {
+			ch.Taxes = append(ch.Taxes, &Change{
+				Key:  ChangeKeyRemoved,
+				Path: path,
+				From: art.Amount.String(),
+			})
+			continue
+		}
+		compareValues(&ch.Taxes, path+".base", art.Base.String(), brt.Base.String())
+		compareValues(&ch.Taxes, path+".amount", art.Amount.String(), brt.Amount.String())
+	}
+	for _, k := range br.keys {
+		if _, ok := ar.rates[k]; ok {
+			continue
+		}
+		ch.Taxes = append(ch.Taxes, &Change{
+			Key:  ChangeKeyAdded,
+			Path: "totals.taxes." + k,
+			To:   br.rates[k].Amount.String(),
+		})
+	}
+}
+
+// compareValues adds a change to the list if the values are different.
+func compareValues(list *[]*Change, path, from, to string) {
+	if from == to {
+		return
+	}
+	c := &Change{Key: ChangeKeyModified, Path: path, From: from, To: to}
+	if from == "" {
+		c.Key = ChangeKeyAdded
+	} else if to == "" {
+		c.Key = ChangeKeyRemoved
+	}
+	*list = append(*list, c)
+}
+
+func diffParty(list *[]*Change, path string, a, b *org.Party) {
+	var an, bn, at, bt string
+	if a != nil {
+		an = a.Name
+		if a.TaxID != nil {
+			at = a.TaxID.String()
+		}
+	}
+	if b != nil {
+		bn = b.Name
+		if b.TaxID != nil {
+			bt = b.TaxID.String()
+		}
+	}
+	compareValues(list, path+".name", an, bn)
+	compareValues(list, path+".tax_id", at, bt)
+}
+
+func findDiffLine(lines []*Line, matched []bool, key string) int {
+	for i, l := range lines {
+		if !matched[i] && l != nil && l.Item != nil && lineItemKey(l) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+type diffRates struct {
+	keys  []string
+	rates map[string]*tax.RateTotal
+}
+
+// diffRateTotals indexes the rate totals using the category code along with
+// the rate's country, key, and percent, e.g. "VAT[standard:21.0%]".
+func diffRateTotals(t *tax.Total) *diffRates {
+	dr := &diffRates{rates: make(map[string]*tax.RateTotal)}
+	if t == nil {
+		return dr
+	}
+	for _, ct := range t.Categories {
+		for _, rt := range ct.Rates {
+			k := ct.Code.String() + diffRateKey(rt)
+			if _, ok := dr.rates[k]; !ok {
+				dr.keys = append(dr.keys, k)
+			}
+			dr.rates[k] = rt
+		}
+	}
+	return dr
+}
+
+func diffRateKey(rt *tax.RateTotal) string {
+	parts := make([]string, 0, 3)
+	if rt.Country != "" {
+		parts = append(parts, rt.Country.String())
+	}
+	if rt.Key != cbc.KeyEmpty {
+		parts = append(parts, rt.Key.String())
+	}
+	if rt.Percent != nil {
+		parts = append(parts, rt.Percent.String())
+	}
+	if len(parts) == 0 {
+		return "[exempt]"
+	}
+	return "[" + strings.Join(parts, ":") + "]"
+}
+
+func diffTaxSet(set tax.Set) string {
+	rows := make([]string, 0, len(set))
+	for _, c := range set {
+		if c == nil {
+			continue
+		}
+		r := c.Category.String()
+		if c.Key != cbc.KeyEmpty {
+			r += " " + c.Key.String()
+		}
+		if c.Rate != cbc.KeyEmpty {
+			r += " " + c.Rate.String()
+		}
+		if c.Percent != nil {
+			r += " " + c.Percent.String()
+		}
+		rows = append(rows, r)
+	}
+	return strings.Join(rows, ", ")
+}
+
+func diffLineDiscounts(l *Line) string {
+	var sum *num.Amount
+	for _, d := range l.Discounts {
+		if d != nil {
+			sum = addDiffAmount(sum, d.Amount)
+		}
+	}
+	return diffAmount(sum)
+}
+
+func diffLineCharges(l *Line) string {
+	var sum *num.Amount
+	for _, c := range l.Charges {
+		if c != nil {
+			sum = addDiffAmount(sum, c.Amount)
+		}
+	}
+	return diffAmount(sum)
+}
+
+func addDiffAmount(sum *num.Amount, a num.Amount) *num.Amount {
+	if sum == nil {
+		return &a
+	}
+	r := sum.Add(a)
+	return &r
+}
+
+func diffAmount(a *num.Amount) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func diffDate(d *cal.Date) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,87 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diffInvoices(t *testing.T) (*bill.Invoice, *bill.Invoice) {
+	t.Helper()
+	a := baseInvoice(t,
+		splitLine("A", 10000, tax.RateGeneral),
+		splitLine("B", 5000, tax.RateGeneral),
+		splitLine("C", 2000, tax.RateGeneral),
+	)
+	a.Tax = nil
+	b := baseInvoice(t,
+		splitLine("C", 2000, tax.RateGeneral),
+		splitLine("A", 10000, tax.RateGeneral),
+		splitLine("D", 1000, tax.RateReduced),
+	)
+	b.Tax = nil
+	return a, b
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		a, _ := diffInvoices(t)
+		ch, err := bill.Diff(a, a)
+		require.NoError(t, err)
+		assert.True(t, ch.Empty())
+		assert.Empty(t, ch.String())
+	})
+
+	t.Run("lines and totals", func(t *testing.T) {
+		a, b := diffInvoices(t)
+		b.Code = "00124"
+		b.Lines[1].Quantity = num.MakeAmount(2, 0)
+		b.Lines[1].Discounts = []*bill.LineDiscount{
+			{Percent: num.NewPercentage(10, 2)},
+		}
+		ch, err := bill.Diff(a, b)
+		require.NoError(t, err)
+		assert.False(t, ch.Empty())
+
+		require.Len(t, ch.Fields, 1)
+		assert.Equal(t, &bill.Change{
+			Key:  bill.ChangeKeyModified,
+			Path: "code",
+			From: "00123",
+			To:   "00124",
+		}, ch.Fields[0])
+
+		require.Len(t, ch.Lines, 6)
+		assert.Equal(t, "~ lines[1].quantity: 1 -> 2", ch.Lines[0].String())
+		assert.Equal(t, "~ lines[1].sum: 100.00 -> 200.00", ch.Lines[1].String())
+		assert.Equal(t, "+ lines[1].discounts: 20.00", ch.Lines[2].String())
+		assert.Equal(t, "~ lines[1].total: 100.00 -> 180.00", ch.Lines[3].String())
+		assert.Equal(t, "+ lines[2]: D", ch.Lines[4].String())
+		assert.Equal(t, "- lines[1]: B", ch.Lines[5].String())
+
+		require.Len(t, ch.Taxes, 3)
+		assert.Equal(t, "~ totals.taxes.VAT[standard:21.0%].base: 170.00 -> 200.00", ch.Taxes[0].String())
+		assert.Equal(t, "~ totals.taxes.VAT[standard:21.0%].amount: 35.70 -> 42.00", ch.Taxes[1].String())
+		assert.Equal(t, "+ totals.taxes.VAT[standard:10.0%]: 1.00", ch.Taxes[2].String())
+
+		assert.Contains(t, ch.String(), "~ totals.payable: 205.70 -> 253.00")
+
+		n := ch.Note()
+		assert.Equal(t, org.NoteKeyGeneral, n.Key)
+		assert.Equal(t, ch.String(), n.Text)
+
+		// originals unchanged
+		assert.Nil(t, a.Totals)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		a, _ := diffInvoices(t)
+		_, err := bill.Diff(a, nil)
+		assert.ErrorContains(t, err, "two invoices required")
+	})
+}
@@ -0,0 +1,84 @@
+package bill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/internal"
+	"github.com/invopop/gobl/uuid"
+)
+
+// FinalizeOptions defines how a proforma invoice will be issued as a final
+// invoice.
+type FinalizeOptions struct {
+	// Sequence provides the code for the final invoice, and is required.
+	Sequence SequenceProvider
+	// Series to use for the final invoice instead of the proforma's.
+	Series cbc.Code
+	// IssueDate of the final invoice, or today if empty.
+	IssueDate *cal.Date
+	// OperationDate to set in the final invoice, if any.
+	OperationDate *cal.Date
+}
+
+// Finalize prepares a new standard invoice from the proforma, with a new
+// issue date and a code assigned from the sequence provided. The value date
+// is reset so that it may be determined again from the new issue date. The
+// proforma itself will not be modified.
+//
+// Extensions assigned to the proforma by regime or add-on scenarios are
+// removed so that those for the standard invoice can be applied during
+// calculation. The final invoice is then validated as if it were about to
+// be signed, including any rules from add-ons that only apply to issued
+// documents. The next code will only be requested from the sequence once
+// the rest of the invoice is known to be valid, to avoid gaps.
+func (inv *Invoice) Finalize(opts *FinalizeOptions) (*Invoice, error) {
+	if opts == nil || opts.Sequence == nil {
+		return nil, errors.New("sequence provider required")
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	if inv.Type != InvoiceTypeProforma {
+		return nil, fmt.Errorf("invoice type '%s' cannot be finalized", inv.Type)
+	}
+	out, err := inv.clone()
+	if err != nil {
+		return nil, err
+	}
+	if ss := inv.scenarioSummary(); ss != nil && out.Tax != nil {
+		for k, v := range ss.Ext {
+			if out.Tax.Ext.Get(k) == v {
+				out.Tax.Ext = out.Tax.Ext.Delete(k)
+			}
+		}
+	}
+
+	out.UUID = uuid.Empty
+	out.Type = InvoiceTypeStandard
+	if opts.Series != "" {
+		out.Series = opts.Series
+	}
+	out.Code = ""
+	if opts.IssueDate != nil {
+		out.IssueDate = *opts.IssueDate
+	} else {
+		out.IssueDate = cal.Date{}
+	}
+	out.IssueTime = nil
+	if opts.OperationDate != nil {
+		od := *opts.OperationDate
+		out.OperationDate = &od
+	}
+	out.ValueDate = nil
+	if err := out.AssignCode(opts.Sequence); err != nil {
+		return nil, err
+	}
+	if err := out.ValidateWithContext(internal.SignedContext(context.Background())); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
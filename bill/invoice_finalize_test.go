@@ -0,0 +1,129 @@
+package bill_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/invopop/gobl/addons/pt/saft"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSequence struct {
+	last map[cbc.Code]int
+	err  error
+}
+
+func (s *testSequence) Next(series cbc.Code) (cbc.Code, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.last == nil {
+		s.last = make(map[cbc.Code]int)
+	}
+	s.last[series]++
+	return cbc.Code(strconv.Itoa(s.last[series])), nil
+}
+
+func proformaInvoicePT(t *testing.T) *bill.Invoice {
+	t.Helper()
+	return &bill.Invoice{
+		Addons:    tax.WithAddons(saft.V1),
+		Identify:  uuid.Identify{UUID: uuid.V7()},
+		Type:      bill.InvoiceTypeProforma,
+		Series:    "PF SERIES-A",
+		Code:      "123",
+		IssueDate: cal.MakeDate(2023, 1, 30),
+		Supplier: &org.Party{
+			Name: "Hotelzinho",
+			TaxID: &tax.Identity{
+				Country: "PT",
+				Code:    "545259045",
+			},
+		},
+		Customer: &org.Party{
+			Name: "Maria Santos Silva",
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Noite em quarto duplo",
+					Price: num.NewAmount(10000, 2),
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceFinalize(t *testing.T) {
+	t.Run("proforma with addon", func(t *testing.T) {
+		pf := proformaInvoicePT(t)
+		seq := new(testSequence)
+		inv, err := pf.Finalize(&bill.FinalizeOptions{
+			Sequence:      seq,
+			Series:        "FT SERIES-A",
+			IssueDate:     cal.NewDate(2023, 2, 3),
+			OperationDate: cal.NewDate(2023, 2, 1),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+		assert.Empty(t, inv.UUID)
+		assert.Equal(t, "FT SERIES-A", inv.Series.String())
+		assert.Equal(t, "1", inv.Code.String())
+		assert.Equal(t, "2023-02-03", inv.IssueDate.String())
+		assert.Equal(t, "2023-02-01", inv.OperationDate.String())
+		assert.Equal(t, "2023-02-01", inv.ValueDate.String())
+		assert.Equal(t, saft.InvoiceTypeStandard, inv.Tax.Ext[saft.ExtKeyInvoiceType])
+		assert.False(t, inv.Tax.Ext.Has(saft.ExtKeyWorkType))
+		assert.Equal(t, "123.00", inv.Totals.Payable.String())
+
+		// proforma unchanged
+		assert.Equal(t, bill.InvoiceTypeProforma, pf.Type)
+		assert.Equal(t, "123", pf.Code.String())
+		assert.Equal(t, saft.WorkTypeProforma, pf.Tax.Ext[saft.ExtKeyWorkType])
+
+		inv, err = pf.Finalize(&bill.FinalizeOptions{
+			Sequence: seq,
+			Series:   "FT SERIES-A",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2", inv.Code.String())
+		assert.Equal(t, cal.Today().String(), inv.IssueDate.String())
+	})
+
+	t.Run("invalid final invoice", func(t *testing.T) {
+		pf := proformaInvoicePT(t)
+		seq := new(testSequence)
+		_, err := pf.Finalize(&bill.FinalizeOptions{Sequence: seq})
+		assert.ErrorContains(t, err, "series: must start with 'FT '")
+		assert.Empty(t, seq.last, "sequence should not be used")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pf := proformaInvoicePT(t)
+		_, err := pf.Finalize(nil)
+		assert.ErrorContains(t, err, "sequence provider required")
+
+		_, err = pf.Finalize(&bill.FinalizeOptions{
+			Sequence: &testSequence{err: errors.New("unavailable")},
+			Series:   "FT SERIES-A",
+		})
+		assert.ErrorContains(t, err, "sequence: unavailable")
+
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		_, err = inv.Finalize(&bill.FinalizeOptions{Sequence: new(testSequence)})
+		assert.ErrorContains(t, err, "invoice type 'standard' cannot be finalized")
+	})
+}
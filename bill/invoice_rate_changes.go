@@ -0,0 +1,139 @@
+package bill
+
+import (
+	"strconv"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// RateChangeWarningDays is the number of days after the issue date in which
+// scheduled rate changes will be reported by the invoice's warnings.
+const RateChangeWarningDays = 30
+
+// UpcomingRateChanges provides the scheduled changes to the rates used by the
+// invoice that will take place within the given number of days after its issue
+// date, according to the invoice's regime.
+//
+// Upcoming changes do not make the invoice invalid, so they are not reported
+// during validation, but are included in the invoice's warnings.
+func (inv *Invoice) UpcomingRateChanges(days int) []*tax.RateChange {
+	r := inv.RegimeDef()
+	if r == nil || days <= 0 || inv.IssueDate.IsZero() {
+		return nil
+	}
+	combos := inv.rateCombos()
+	from := inv.IssueDate.Add(0, 0, 1)
+	to := inv.IssueDate.Add(0, 0, days)
+
+	var list []*tax.RateChange
+	cats := make([]cbc.Code, 0)
+	for _, c := range combos {
+		if c.Category.In(cats...) {
+			continue
+		}
+		cats = append(cats, c.Category)
+		for _, rc := range r.RateChanges(c.Category, from, to) {
+			for _, c2 := range combos {
+				if rc.Applies(c2) {
+					list = append(list, rc)
+					break
+				}
+			}
+		}
+	}
+	return list
+}
+
+// Warnings provides the issues found in the invoice that do not make it
+// invalid but should be reviewed, structured in the same way as validation
+// errors so that they may be reported as problems. This includes the rates
+// used by the lines, discounts, and charges that will change within
+// RateChangeWarningDays of the issue date.
+func (inv *Invoice) Warnings() error {
+	changes := inv.UpcomingRateChanges(RateChangeWarningDays)
+	if len(changes) == 0 {
+		return nil
+	}
+	return validation.Errors{
+		"lines":     rateChangeWarnings(inv.lineTaxSets(), changes),
+		"discounts": rateChangeWarnings(inv.discountTaxSets(), changes),
+		"charges":   rateChangeWarnings(inv.chargeTaxSets(), changes),
+	}.Filter()
+}
+
+// rateChangeWarnings provides the warnings for each of the combos in the sets
+// whose rates are affected by the changes, indexed in the same way as the
+// lines, discounts, or charges they were taken from.
+func rateChangeWarnings(sets []tax.Set, changes []*tax.RateChange) error {
+	errs := validation.Errors{}
+	for i, set := range sets {
+		ce := validation.Errors{}
+		for j, c := range set {
+			for _, rc := range changes {
+				if rc.Applies(c) {
+					ce[strconv.Itoa(j)] = ErrRateChangeUpcoming.WithParams(map[string]any{
+						"date":    rc.Date.String(),
+						"percent": rc.To.Percent.String(),
+					})
+					break
+				}
+			}
+		}
+		if len(ce) > 0 {
+			errs[strconv.Itoa(i)] = validation.Errors{"taxes": ce}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (inv *Invoice) lineTaxSets() []tax.Set {
+	sets := make([]tax.Set, len(inv.Lines))
+	for i, l := range inv.Lines {
+		if l != nil {
+			sets[i] = l.Taxes
+		}
+	}
+	return sets
+}
+
+func (inv *Invoice) discountTaxSets() []tax.Set {
+	sets := make([]tax.Set, len(inv.Discounts))
+	for i, d := range inv.Discounts {
+		if d != nil {
+			sets[i] = d.Taxes
+		}
+	}
+	return sets
+}
+
+func (inv *Invoice) chargeTaxSets() []tax.Set {
+	sets := make([]tax.Set, len(inv.Charges))
+	for i, c := range inv.Charges {
+		if c != nil {
+			sets[i] = c.Taxes
+		}
+	}
+	return sets
+}
+
+// rateCombos provides the tax combos with rates used in the lines, discounts,
+// and charges of the invoice.
+func (inv *Invoice) rateCombos() []*tax.Combo {
+	sets := inv.lineTaxSets()
+	sets = append(sets, inv.discountTaxSets()...)
+	sets = append(sets, inv.chargeTaxSets()...)
+	combos := make([]*tax.Combo, 0)
+	for _, set := range sets {
+		for _, c := range set {
+			if c != nil && c.Rate != cbc.KeyEmpty {
+				combos = append(combos, c)
+			}
+		}
+	}
+	return combos
+}
@@ -0,0 +1,63 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceUpcomingRateChanges(t *testing.T) {
+	t.Run("change within window", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 8, 20)
+		require.NoError(t, inv.Calculate())
+		list := inv.UpcomingRateChanges(15)
+		require.Len(t, list, 1)
+		assert.Equal(t, tax.RateGeneral, list[0].Rate)
+		assert.Equal(t, "2012-09-01", list[0].Date.String())
+		assert.Equal(t, "18.0%", list[0].From.Percent.String())
+		assert.Equal(t, "21.0%", list[0].To.Percent.String())
+		require.NoError(t, inv.Validate())
+	})
+
+	t.Run("change outside window", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 8, 1)
+		require.NoError(t, inv.Calculate())
+		assert.Empty(t, inv.UpcomingRateChanges(15))
+	})
+
+	t.Run("change already applied", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 9, 1)
+		require.NoError(t, inv.Calculate())
+		assert.Empty(t, inv.UpcomingRateChanges(15))
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 8, 20)
+		require.NoError(t, inv.Calculate())
+		assert.Nil(t, inv.UpcomingRateChanges(0))
+	})
+}
+
+func TestInvoiceWarnings(t *testing.T) {
+	t.Run("upcoming rate change", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 8, 20)
+		require.NoError(t, inv.Calculate())
+		err := inv.Warnings()
+		assert.ErrorContains(t, err, "lines: (0: (taxes: (0: rate changes to 21.0% on 2012-09-01.).).)")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.IssueDate = cal.MakeDate(2012, 6, 1)
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Warnings())
+	})
+}
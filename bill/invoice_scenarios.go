@@ -1,6 +1,8 @@
 package bill
 
 import (
+	"slices"
+
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
@@ -62,17 +64,20 @@ func (inv *Invoice) ScenarioSummary() *tax.ScenarioSummary {
 }
 
 func (inv *Invoice) scenarioSummary() *tax.ScenarioSummary {
-	ss := tax.NewScenarioSet(ShortSchemaInvoice)
+	ss := inv.scenarioSet()
+	inv.removePreviousScenarioNotes(ss)
+	return ss.SummaryFor(inv)
+}
 
+func (inv *Invoice) scenarioSet() *tax.ScenarioSet {
+	ss := tax.NewScenarioSet(ShortSchemaInvoice)
 	if r := inv.RegimeDef(); r != nil {
 		ss.Merge(r.Scenarios)
 	}
 	for _, a := range inv.AddonDefs() {
 		ss.Merge(a.Scenarios)
 	}
-
-	inv.removePreviousScenarioNotes(ss)
-	return ss.SummaryFor(inv)
+	return ss
 }
 
 func (inv *Invoice) removePreviousScenarioNotes(ss *tax.ScenarioSet) {
@@ -89,10 +94,9 @@ func (inv *Invoice) removePreviousScenarioNotes(ss *tax.ScenarioSet) {
 
 func (inv *Invoice) prepareScenarios() error {
 	// Use the scenario summary to add any notes to the invoice
-	ss := inv.scenarioSummary()
-	if ss == nil {
-		return nil
-	}
+	set := inv.scenarioSet()
+	inv.removePreviousScenarioNotes(set)
+	ss := set.SummaryFor(inv)
 
 	for _, sn := range ss.Notes {
 		n := org.NoteFromScenario(sn)
@@ -113,5 +117,45 @@ func (inv *Invoice) prepareScenarios() error {
 		inv.Tax = inv.Tax.MergeExtensions(ss.Ext)
 	}
 
+	inv.prepareLineScenarios(set)
 	return nil
 }
+
+// prepareLineScenarios applies the line scoped scenarios to each of the
+// invoice's lines, replacing any notes added previously.
+func (inv *Invoice) prepareLineScenarios(set *tax.ScenarioSet) {
+	notes := set.LineNotes()
+	for _, l := range inv.Lines {
+		if l == nil {
+			continue
+		}
+		l.Notes = removeScenarioNotes(l.Notes, notes)
+		ss := set.LineSummaryFor(inv, l)
+		for _, sn := range ss.Notes {
+			l.Notes = append(l.Notes, org.NoteFromScenario(sn))
+		}
+		if len(ss.Ext) > 0 {
+			l.Ext = l.Ext.Merge(ss.Ext)
+		}
+	}
+}
+
+// removeScenarioNotes provides the list of notes without those that could
+// have been added by the scenarios.
+func removeScenarioNotes(list []*org.Note, notes []*tax.ScenarioNote) []*org.Note {
+	if len(notes) == 0 || len(list) == 0 {
+		return list
+	}
+	out := make([]*org.Note, 0, len(list))
+	for _, n := range list {
+		if !slices.ContainsFunc(notes, func(sn *tax.ScenarioNote) bool {
+			return org.NoteFromScenario(sn).SameAs(n)
+		}) {
+			out = append(out, n)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/invopop/gobl/addons/it/sdi"
 	"github.com/invopop/gobl/addons/pt/saft"
 	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
 	"github.com/stretchr/testify/assert"
@@ -105,6 +107,63 @@ func TestScenarios(t *testing.T) {
 	})
 }
 
+func TestInvoiceLineScenarios(t *testing.T) {
+	tax.RegisterAddonDef(&tax.AddonDef{
+		Key:  "xx-lines-v1",
+		Name: i18n.NewString("Line Scenarios Test"),
+		Scenarios: []*tax.ScenarioSet{
+			{
+				Schema: bill.ShortSchemaInvoice,
+				List: []*tax.Scenario{
+					{
+						Scope:   tax.ScenarioScopeLine,
+						ExtKey:  "xx-exempt",
+						ExtCode: "E1",
+						Note: &tax.ScenarioNote{
+							Key:  org.NoteKeyLegal,
+							Src:  "xx-exempt",
+							Text: "Exempt under article 1",
+						},
+						Ext: tax.Extensions{"xx-reason": "art-1"},
+					},
+				},
+			},
+		},
+	})
+	inv := baseInvoiceWithLines(t)
+	inv.Addons = tax.WithAddons("xx-lines-v1")
+	inv.Lines = append(inv.Lines, &bill.Line{
+		Quantity: num.MakeAmount(1, 0),
+		Item: &org.Item{
+			Name:  "Exempt Item",
+			Price: num.NewAmount(5000, 2),
+		},
+		Taxes: tax.Set{
+			{
+				Category: "VAT",
+				Key:      tax.KeyExempt,
+				Ext:      tax.Extensions{"xx-exempt": "E1"},
+			},
+		},
+	})
+	require.NoError(t, inv.Calculate())
+	assert.Empty(t, inv.Notes)
+	assert.Empty(t, inv.Lines[0].Notes)
+	assert.Empty(t, inv.Lines[0].Ext)
+	l := inv.Lines[1]
+	require.Len(t, l.Notes, 1)
+	assert.Equal(t, "Exempt under article 1", l.Notes[0].Text)
+	assert.Equal(t, "E1", l.Notes[0].Code.String())
+	assert.Equal(t, "art-1", l.Ext["xx-reason"].String())
+
+	require.NoError(t, inv.Calculate())
+	assert.Len(t, l.Notes, 1, "not duplicated")
+
+	l.Taxes[0].Ext = tax.Extensions{"xx-exempt": "E2"}
+	require.NoError(t, inv.Calculate())
+	assert.Empty(t, l.Notes, "removed when no longer matching")
+}
+
 func TestInvoiceGetExtensions(t *testing.T) {
 	t.Run("with lines", func(t *testing.T) {
 		inv := baseInvoiceWithLines(t)
@@ -0,0 +1,309 @@
+package bill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/uuid"
+)
+
+// SplitOptions defines how an invoice should be divided. Exactly one of
+// the options must be provided.
+type SplitOptions struct {
+	// Groups contains the indexes of the lines, starting from zero, to
+	// include in each of the resulting invoices. Every line must appear in
+	// exactly one group.
+	Groups [][]int
+	// MaxTotal when set will place lines in order into invoices whose sum
+	// of line totals does not exceed the amount.
+	MaxTotal *num.Amount
+	// ByRate will prepare an invoice for each distinct combination of
+	// tax rates used by lines.
+	ByRate bool
+	// Sequence when provided will be used to assign codes to each of the
+	// resulting invoices in order.
+	Sequence SequenceProvider
+}
+
+// Split divides the invoice into multiple invoices according to the
+// options, for example to respect the maximum amount a system will accept
+// in a single document or to separate lines taxed at different rates.
+//
+// Each resulting invoice is a copy of the original with a subset of the
+// lines, and will have been recalculated so that line indexes and totals
+// reflect its own contents. Identifiers are removed from the copies so
+// that they may be assigned new codes, either afterwards or using the
+// sequence provided in the options.
+//
+// Discounts and charges defined as a percentage of the invoice sum will
+// be applied to every part, while those with a fixed amount or base will
+// only be included in the first. Advances with a percentage are also
+// copied to every part, and fixed advance amounts are distributed in order
+// up to the amount payable of each.
+func (inv *Invoice) Split(opts *SplitOptions) ([]*Invoice, error) {
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	groups, err := splitGroups(inv, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*Invoice, len(groups))
+	for i, g := range groups {
+		p, err := inv.splitPart(g, i == 0)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+		parts[i] = p
+	}
+
+	if err := splitAdvances(inv, parts); err != nil {
+		return nil, err
+	}
+
+	if opts.Sequence != nil {
+		// validate all the parts before using any codes
+		for i, p := range parts {
+			if err := p.Validate(); err != nil {
+				return nil, fmt.Errorf("part %d: %w", i, err)
+			}
+		}
+		for i, p := range parts {
+			if err := p.AssignCode(opts.Sequence); err != nil {
+				return nil, fmt.Errorf("part %d: %w", i, err)
+			}
+		}
+	}
+
+	return parts, nil
+}
+
+func splitGroups(inv *Invoice, opts *SplitOptions) ([][]int, error) {
+	if opts == nil {
+		return nil, errors.New("split options required")
+	}
+	n := 0
+	if len(opts.Groups) > 0 {
+		n++
+	}
+	if opts.MaxTotal != nil {
+		n++
+	}
+	if opts.ByRate {
+		n++
+	}
+	if n != 1 {
+		return nil, errors.New("exactly one split option must be provided")
+	}
+	if len(inv.Lines) == 0 {
+		return nil, errors.New("invoice has no lines to split")
+	}
+
+	switch {
+	case len(opts.Groups) > 0:
+		return checkSplitGroups(inv, opts.Groups)
+	case opts.MaxTotal != nil:
+		return splitGroupsByTotal(inv, *opts.MaxTotal)
+	default:
+		return splitGroupsByRate(inv), nil
+	}
+}
+
+func checkSplitGroups(inv *Invoice, groups [][]int) ([][]int, error) {
+	seen := make(map[int]bool, len(inv.Lines))
+	for i, g := range groups {
+		if len(g) == 0 {
+			return nil, fmt.Errorf("group %d: no lines", i)
+		}
+		for _, li := range g {
+			if li < 0 || li >= len(inv.Lines) {
+				return nil, fmt.Errorf("group %d: line %d does not exist", i, li)
+			}
+			if seen[li] {
+				return nil, fmt.Errorf("group %d: line %d already included", i, li)
+			}
+			seen[li] = true
+		}
+	}
+	if len(seen) != len(inv.Lines) {
+		return nil, errors.New("all lines must be included in a group")
+	}
+	return groups, nil
+}
+
+func splitGroupsByTotal(inv *Invoice, limit num.Amount) ([][]int, error) {
+	if !limit.IsPositive() {
+		return nil, errors.New("max total must be positive")
+	}
+	var groups [][]int
+	var current []int
+	sum := num.AmountZero
+	for i, l := range inv.Lines {
+		total := lineTotal(l)
+		if total.Compare(limit) > 0 {
+			return nil, fmt.Errorf("line %d: total %s exceeds max total", i, total)
+		}
+		if len(current) > 0 && sum.Add(total).Compare(limit) > 0 {
+			groups = append(groups, current)
+			current = nil
+			sum = num.AmountZero
+		}
+		current = append(current, i)
+		sum = sum.Add(total)
+	}
+	return append(groups, current), nil
+}
+
+func splitGroupsByRate(inv *Invoice) [][]int {
+	var groups [][]int
+	idx := make(map[string]int)
+	for i, l := range inv.Lines {
+		k := lineRatesKey(l)
+		gi, ok := idx[k]
+		if !ok {
+			gi = len(groups)
+			idx[k] = gi
+			groups = append(groups, nil)
+		}
+		groups[gi] = append(groups[gi], i)
+	}
+	return groups
+}
+
+func lineTotal(l *Line) num.Amount {
+	if l.Total != nil {
+		return *l.Total
+	}
+	return num.AmountZero
+}
+
+// lineRatesKey provides a string that identifies the combination of
+// rates applied to the line.
+func lineRatesKey(l *Line) string {
+	keys := make([]string, len(l.Taxes))
+	for i, c := range l.Taxes {
+		p := ""
+		if c.Percent != nil {
+			p = c.Percent.String()
+		}
+		keys[i] = fmt.Sprintf("%s:%s:%s:%s", c.Category, c.Key, c.Rate, p)
+	}
+	return strings.Join(keys, "|")
+}
+
+// splitPart prepares a copy of the invoice with the lines provided.
+func (inv *Invoice) splitPart(lines []int, first bool) (*Invoice, error) {
+	p, err := inv.clone()
+	if err != nil {
+		return nil, err
+	}
+	p.UUID = uuid.Empty
+	p.Code = ""
+	p.Totals = nil
+
+	all := p.Lines
+	p.Lines = make([]*Line, len(lines))
+	for i, li := range lines {
+		p.Lines[i] = all[li]
+	}
+
+	if !first {
+		p.Discounts = filterSplitDiscounts(p.Discounts)
+		p.Charges = filterSplitCharges(p.Charges)
+	}
+	if p.Payment != nil && len(p.Payment.Advances) > 0 {
+		// fixed amounts are assigned once all the parts are known
+		advs := make([]*pay.Advance, 0, len(p.Payment.Advances))
+		for _, a := range p.Payment.Advances {
+			if a.Percent != nil {
+				advs = append(advs, a)
+			}
+		}
+		p.Payment.Advances = advs
+	}
+
+	if err := p.Calculate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func filterSplitDiscounts(list []*Discount) []*Discount {
+	var out []*Discount
+	for _, d := range list {
+		if d.Percent != nil && d.Base == nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func filterSplitCharges(list []*Charge) []*Charge {
+	var out []*Charge
+	for _, c := range list {
+		if c.Percent != nil && c.Base == nil {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// splitAdvances distributes the original invoice's fixed advance amounts
+// between the parts, in order, then recalculates any parts that were
+// updated.
+func splitAdvances(inv *Invoice, parts []*Invoice) error {
+	if inv.Payment == nil {
+		return nil
+	}
+	pi := 0
+	for _, a := range inv.Payment.Advances {
+		if a.Percent != nil {
+			continue
+		}
+		remaining := a.Amount
+		for !remaining.IsZero() {
+			if pi >= len(parts) {
+				return fmt.Errorf("advance '%s' exceeds the parts' amount payable", a.Description)
+			}
+			p := parts[pi]
+			avail := p.Totals.Payable
+			if p.Totals.Advances != nil {
+				avail = avail.Subtract(*p.Totals.Advances)
+			}
+			if !avail.IsPositive() {
+				pi++
+				continue
+			}
+			amt := remaining
+			if amt.Compare(avail) > 0 {
+				amt = avail
+			}
+			a2 := *a
+			a2.Amount = amt
+			p.Payment.Advances = append(p.Payment.Advances, &a2)
+			if err := p.Calculate(); err != nil {
+				return fmt.Errorf("part %d: %w", pi, err)
+			}
+			remaining = remaining.Subtract(amt)
+		}
+	}
+	return nil
+}
+
+// clone provides a deep copy of the invoice.
+func (inv *Invoice) clone() (*Invoice, error) {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return nil, err
+	}
+	out := new(Invoice)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
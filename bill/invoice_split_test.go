@@ -0,0 +1,199 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func splitLine(name string, price int64, rate cbc.Key) *bill.Line {
+	return &bill.Line{
+		Quantity: num.MakeAmount(1, 0),
+		Item: &org.Item{
+			Name:  name,
+			Price: num.NewAmount(price, 2),
+		},
+		Taxes: tax.Set{
+			{
+				Category: tax.CategoryVAT,
+				Rate:     rate,
+			},
+		},
+	}
+}
+
+func splitInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := baseInvoice(t,
+		splitLine("A", 10000, tax.RateGeneral),
+		splitLine("B", 5000, tax.RateReduced),
+		splitLine("C", 8000, tax.RateGeneral),
+		splitLine("D", 2000, tax.RateReduced),
+	)
+	inv.Tax = nil
+	return inv
+}
+
+func TestInvoiceSplit(t *testing.T) {
+	t.Run("by groups", func(t *testing.T) {
+		inv := splitInvoice(t)
+		parts, err := inv.Split(&bill.SplitOptions{
+			Groups: [][]int{{0, 3}, {1, 2}},
+		})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		p := parts[0]
+		require.Len(t, p.Lines, 2)
+		assert.Equal(t, "A", p.Lines[0].Item.Name)
+		assert.Equal(t, 1, p.Lines[0].Index)
+		assert.Equal(t, "D", p.Lines[1].Item.Name)
+		assert.Equal(t, 2, p.Lines[1].Index)
+		assert.Equal(t, "120.00", p.Totals.Sum.String())
+		assert.Empty(t, p.Code)
+		assert.Equal(t, "TEST", p.Series.String())
+		assert.Equal(t, "130.00", parts[1].Totals.Sum.String())
+
+		// original is left untouched
+		assert.Len(t, inv.Lines, 4)
+		assert.Equal(t, "00123", inv.Code.String())
+		assert.Equal(t, "250.00", inv.Totals.Sum.String())
+	})
+
+	t.Run("with sequence", func(t *testing.T) {
+		inv := splitInvoice(t)
+		seq := bill.NewMemorySequence("%05d")
+		seq.Set("TEST", 123)
+		parts, err := inv.Split(&bill.SplitOptions{
+			ByRate:   true,
+			Sequence: seq,
+		})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Equal(t, "00124", parts[0].Code.String())
+		assert.Equal(t, "00125", parts[1].Code.String())
+	})
+
+	t.Run("by max total", func(t *testing.T) {
+		inv := splitInvoice(t)
+		parts, err := inv.Split(&bill.SplitOptions{
+			MaxTotal: num.NewAmount(15000, 2),
+		})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Len(t, parts[0].Lines, 2)
+		assert.Equal(t, "150.00", parts[0].Totals.Sum.String())
+		assert.Len(t, parts[1].Lines, 2)
+		assert.Equal(t, "100.00", parts[1].Totals.Sum.String())
+	})
+
+	t.Run("by max total with large line", func(t *testing.T) {
+		inv := splitInvoice(t)
+		_, err := inv.Split(&bill.SplitOptions{
+			MaxTotal: num.NewAmount(9000, 2),
+		})
+		assert.ErrorContains(t, err, "line 0: total 100.00 exceeds max total")
+	})
+
+	t.Run("by rate", func(t *testing.T) {
+		inv := splitInvoice(t)
+		parts, err := inv.Split(&bill.SplitOptions{ByRate: true})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Equal(t, "C", parts[0].Lines[1].Item.Name)
+		assert.Equal(t, "180.00", parts[0].Totals.Sum.String())
+		require.Len(t, parts[0].Totals.Taxes.Categories[0].Rates, 1)
+		assert.Equal(t, "D", parts[1].Lines[1].Item.Name)
+		assert.Equal(t, "70.00", parts[1].Totals.Sum.String())
+		require.Len(t, parts[1].Totals.Taxes.Categories[0].Rates, 1)
+	})
+
+	t.Run("discounts and charges", func(t *testing.T) {
+		inv := splitInvoice(t)
+		inv.Discounts = []*bill.Discount{
+			{Reason: "Volume", Percent: num.NewPercentage(10, 2)},
+		}
+		inv.Charges = []*bill.Charge{
+			{Reason: "Handling", Amount: num.MakeAmount(500, 2)},
+		}
+		parts, err := inv.Split(&bill.SplitOptions{ByRate: true})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Len(t, parts[0].Discounts, 1)
+		assert.Len(t, parts[0].Charges, 1)
+		assert.Equal(t, "18.00", parts[0].Discounts[0].Amount.String())
+		assert.Len(t, parts[1].Discounts, 1)
+		assert.Empty(t, parts[1].Charges)
+		assert.Equal(t, "7.00", parts[1].Discounts[0].Amount.String())
+	})
+
+	t.Run("advances", func(t *testing.T) {
+		inv := splitInvoice(t)
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Deposit", Amount: num.MakeAmount(10000, 2)},
+				{Description: "Half", Percent: num.NewPercentage(50, 2)},
+			},
+		}
+		parts, err := inv.Split(&bill.SplitOptions{Groups: [][]int{{0}, {1, 2, 3}}})
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+
+		p := parts[0]
+		assert.Equal(t, "121.00", p.Totals.Payable.String())
+		require.Len(t, p.Payment.Advances, 2)
+		assert.Equal(t, "Half", p.Payment.Advances[0].Description)
+		assert.Equal(t, "60.50", p.Payment.Advances[0].Amount.String())
+		assert.Equal(t, "60.50", p.Payment.Advances[1].Amount.String())
+		assert.True(t, p.Totals.Paid())
+
+		p = parts[1]
+		assert.Equal(t, "173.80", p.Totals.Payable.String())
+		require.Len(t, p.Payment.Advances, 2)
+		assert.Equal(t, "86.90", p.Payment.Advances[0].Amount.String())
+		assert.Equal(t, "Deposit", p.Payment.Advances[1].Description)
+		assert.Equal(t, "39.50", p.Payment.Advances[1].Amount.String())
+		assert.Equal(t, "47.40", p.Totals.Due.String())
+	})
+
+	t.Run("advances exceeding payable", func(t *testing.T) {
+		inv := splitInvoice(t)
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Deposit", Amount: num.MakeAmount(30000, 2)},
+			},
+		}
+		_, err := inv.Split(&bill.SplitOptions{ByRate: true})
+		assert.ErrorContains(t, err, "advance 'Deposit' exceeds the parts' amount payable")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		inv := splitInvoice(t)
+		_, err := inv.Split(nil)
+		assert.ErrorContains(t, err, "split options required")
+		_, err = inv.Split(&bill.SplitOptions{})
+		assert.ErrorContains(t, err, "exactly one split option must be provided")
+		_, err = inv.Split(&bill.SplitOptions{ByRate: true, MaxTotal: num.NewAmount(100, 0)})
+		assert.ErrorContains(t, err, "exactly one split option must be provided")
+		_, err = inv.Split(&bill.SplitOptions{MaxTotal: num.NewAmount(0, 0)})
+		assert.ErrorContains(t, err, "max total must be positive")
+	})
+
+	t.Run("invalid groups", func(t *testing.T) {
+		inv := splitInvoice(t)
+		_, err := inv.Split(&bill.SplitOptions{Groups: [][]int{{0, 1}, {}}})
+		assert.ErrorContains(t, err, "group 1: no lines")
+		_, err = inv.Split(&bill.SplitOptions{Groups: [][]int{{0, 1}, {2, 5}}})
+		assert.ErrorContains(t, err, "group 1: line 5 does not exist")
+		_, err = inv.Split(&bill.SplitOptions{Groups: [][]int{{0, 1}, {1, 2, 3}}})
+		assert.ErrorContains(t, err, "group 1: line 1 already included")
+		_, err = inv.Split(&bill.SplitOptions{Groups: [][]int{{0, 1}, {2}}})
+		assert.ErrorContains(t, err, "all lines must be included in a group")
+	})
+}
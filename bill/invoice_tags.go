@@ -71,6 +71,21 @@ var defaultInvoiceTags = &tax.TagSet{
 			},
 		},
 
+		// Cash accounting schemes allow suppliers to account for taxes
+		// once payment is received instead of when the invoice is issued.
+		{
+			Key: tax.TagCashBasis,
+			Name: i18n.String{
+				i18n.EN: "Cash basis",
+			},
+			Desc: i18n.String{
+				i18n.EN: here.Doc(`
+					Used when the supplier applies a cash accounting scheme, so that taxes become
+					accountable once payment is received instead of when the invoice is issued.
+				`),
+			},
+		},
+
 		// Partial invoice document, implying that this is only a first part
 		// and a final invoice for the remaining amount will be made later.
 		// A few regimes use this tag to classify invoices, notably Italy.
@@ -0,0 +1,128 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/uuid"
+)
+
+// Template issue date keys, used to determine when invoices generated
+// from a template will be issued relative to the billing period.
+const (
+	TemplateIssueOnStart cbc.Key = "start"
+	TemplateIssueOnEnd   cbc.Key = "end"
+)
+
+// templateProrateExp defines the number of decimal places prorated
+// quantities will be rounded to.
+const templateProrateExp = 4
+
+// InvoiceTemplate is used to generate concrete invoices for each of the
+// periods of a recurring billing process, such as a subscription, from
+// a base invoice that contains the parties, lines, and payment details
+// that will be repeated every time.
+type InvoiceTemplate struct {
+	// Invoice with the details to include in every generated invoice.
+	Invoice *Invoice `json:"invoice" jsonschema:"title=Invoice"`
+	// CodeFormat is used with the sequence number to determine the code
+	// of each invoice, like "%05d". The plain sequence number will be
+	// used if empty.
+	CodeFormat string `json:"code_format,omitempty" jsonschema:"title=Code Format"`
+	// IssueOn determines if invoices are issued at the start or end of
+	// the period, which is the start by default.
+	IssueOn cbc.Key `json:"issue_on,omitempty" jsonschema:"title=Issue On"`
+	// CycleMonths is the number of months covered by the quantities of
+	// the template's lines, like 1 for monthly or 12 for yearly billing.
+	// When defined, quantities will be prorated according to the number
+	// of days in each period relative to a complete cycle starting on
+	// the same day.
+	CycleMonths int `json:"cycle_months,omitempty" jsonschema:"title=Cycle Months"`
+}
+
+// Generate prepares a new calculated invoice from the template for the
+// billing period and sequence number. The issue date will be determined
+// from the period, which implies that taxes will use the rates that apply
+// on that date, and the period will be assigned to the invoice's ordering
+// details and to any lines that do not already define their own.
+func (t *InvoiceTemplate) Generate(period cal.Period, seq int) (*Invoice, error) {
+	if t.Invoice == nil {
+		return nil, errors.New("template invoice required")
+	}
+	if err := period.Validate(); err != nil {
+		return nil, fmt.Errorf("period: %w", err)
+	}
+	if seq < 0 {
+		return nil, errors.New("sequence must not be negative")
+	}
+
+	inv, err := t.Invoice.clone()
+	if err != nil {
+		return nil, err
+	}
+	inv.UUID = uuid.Empty
+	inv.Code = t.code(seq)
+	inv.Totals = nil
+	inv.OperationDate = nil
+	inv.ValueDate = nil
+	switch t.IssueOn {
+	case cbc.KeyEmpty, TemplateIssueOnStart:
+		inv.IssueDate = period.Start
+	case TemplateIssueOnEnd:
+		inv.IssueDate = period.End
+	default:
+		return nil, fmt.Errorf("invalid issue on '%s'", t.IssueOn)
+	}
+	if inv.Ordering == nil {
+		inv.Ordering = new(Ordering)
+	}
+	p := period
+	inv.Ordering.Period = &p
+
+	ratio := t.prorateRatio(period)
+	for _, l := range inv.Lines {
+		if l.Period == nil {
+			lp := period
+			l.Period = &lp
+		}
+		if ratio != nil {
+			l.Quantity = l.Quantity.RescaleUp(templateProrateExp).
+				Multiply(*ratio).
+				RescaleDown(templateProrateExp)
+		}
+	}
+
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (t *InvoiceTemplate) code(seq int) cbc.Code {
+	if t.CodeFormat == "" {
+		return cbc.Code(strconv.Itoa(seq))
+	}
+	return cbc.Code(fmt.Sprintf(t.CodeFormat, seq))
+}
+
+// prorateRatio provides the proportion of a complete cycle covered by the
+// period, or nil if no proration is required.
+func (t *InvoiceTemplate) prorateRatio(period cal.Period) *num.Amount {
+	if t.CycleMonths <= 0 {
+		return nil
+	}
+	end := period.Start.Add(0, t.CycleMonths, 0)
+	full := end.DaysSince(period.Start.Date)
+	days := period.End.DaysSince(period.Start.Date) + 1
+	if days == full {
+		return nil
+	}
+	r := num.MakeAmount(int64(days), 0).
+		Upscale(templateProrateExp + 2).
+		Divide(num.MakeAmount(int64(full), 0))
+	return &r
+}
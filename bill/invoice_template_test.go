@@ -0,0 +1,115 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func invoiceTemplate(t *testing.T) *bill.InvoiceTemplate {
+	t.Helper()
+	inv := baseInvoice(t, splitLine("Subscription", 3100, tax.RateGeneral))
+	inv.Tax = nil
+	inv.UUID = uuid.V7()
+	inv.Code = ""
+	return &bill.InvoiceTemplate{
+		Invoice:     inv,
+		CodeFormat:  "%05d",
+		CycleMonths: 1,
+	}
+}
+
+func TestInvoiceTemplateGenerate(t *testing.T) {
+	t.Run("complete period", func(t *testing.T) {
+		tmpl := invoiceTemplate(t)
+		inv, err := tmpl.Generate(cal.Period{
+			Start: cal.MakeDate(2024, 2, 1),
+			End:   cal.MakeDate(2024, 2, 29),
+		}, 12)
+		require.NoError(t, err)
+		require.NoError(t, inv.Validate())
+		assert.Empty(t, inv.UUID)
+		assert.Equal(t, "00012", inv.Code.String())
+		assert.Equal(t, "2024-02-01", inv.IssueDate.String())
+		assert.Equal(t, "2024-02-29", inv.Ordering.Period.End.String())
+		assert.Equal(t, "2024-02-01", inv.Lines[0].Period.Start.String())
+		assert.Equal(t, "1", inv.Lines[0].Quantity.String())
+		assert.Equal(t, "31.00", inv.Totals.Sum.String())
+
+		// template unchanged
+		assert.Empty(t, tmpl.Invoice.Code)
+		assert.Nil(t, tmpl.Invoice.Lines[0].Period)
+	})
+
+	t.Run("prorated period", func(t *testing.T) {
+		tmpl := invoiceTemplate(t)
+		tmpl.IssueOn = bill.TemplateIssueOnEnd
+		inv, err := tmpl.Generate(cal.Period{
+			Start: cal.MakeDate(2024, 3, 16),
+			End:   cal.MakeDate(2024, 3, 31),
+		}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-31", inv.IssueDate.String())
+		assert.Equal(t, "0.5161", inv.Lines[0].Quantity.String())
+		assert.Equal(t, "16.00", inv.Totals.Sum.String())
+	})
+
+	t.Run("no proration", func(t *testing.T) {
+		tmpl := invoiceTemplate(t)
+		tmpl.CycleMonths = 0
+		tmpl.CodeFormat = ""
+		inv, err := tmpl.Generate(cal.Period{
+			Start: cal.MakeDate(2024, 3, 16),
+			End:   cal.MakeDate(2024, 3, 31),
+		}, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "7", inv.Code.String())
+		assert.Equal(t, "1", inv.Lines[0].Quantity.String())
+	})
+
+	t.Run("period tax rates", func(t *testing.T) {
+		tmpl := invoiceTemplate(t)
+		tmpl.IssueOn = bill.TemplateIssueOnEnd
+		inv, err := tmpl.Generate(cal.Period{
+			Start: cal.MakeDate(2012, 8, 1),
+			End:   cal.MakeDate(2012, 8, 31),
+		}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "18.0%", inv.Lines[0].Taxes[0].Percent.String())
+		inv, err = tmpl.Generate(cal.Period{
+			Start: cal.MakeDate(2012, 9, 1),
+			End:   cal.MakeDate(2012, 9, 30),
+		}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "21.0%", inv.Lines[0].Taxes[0].Percent.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tmpl := new(bill.InvoiceTemplate)
+		period := cal.Period{
+			Start: cal.MakeDate(2024, 3, 1),
+			End:   cal.MakeDate(2024, 3, 31),
+		}
+		_, err := tmpl.Generate(period, 1)
+		assert.ErrorContains(t, err, "template invoice required")
+
+		tmpl = invoiceTemplate(t)
+		_, err = tmpl.Generate(cal.Period{Start: period.End, End: period.Start}, 1)
+		assert.ErrorContains(t, err, "period: end: too early")
+		_, err = tmpl.Generate(period, -1)
+		assert.ErrorContains(t, err, "sequence must not be negative")
+		tmpl.IssueOn = "middle"
+		_, err = tmpl.Generate(period, 1)
+		assert.ErrorContains(t, err, "invalid issue on 'middle'")
+
+		tmpl = invoiceTemplate(t)
+		tmpl.Invoice.Lines[0].Taxes[0].Rate = "unknown"
+		_, err = tmpl.Generate(period, 1)
+		assert.ErrorContains(t, err, "'unknown' rate not defined")
+	})
+}
@@ -136,6 +136,28 @@ func TestInvoiceCurrencyValidation(t *testing.T) {
 		},
 	}
 	assert.NoError(t, inv.Validate())
+
+	inv.ExchangeRates = append(inv.ExchangeRates, &currency.ExchangeRate{
+		From:   currency.USD,
+		To:     currency.EUR,
+		Amount: num.MakeAmount(880000, 6),
+	})
+	assert.ErrorContains(t, inv.Validate(), "exchange_rates: conflicting exchange rates for 'USD' to 'EUR'")
+
+	inv.ExchangeRates = inv.ExchangeRates[:1]
+	inv.ExchangeRates = append(inv.ExchangeRates, &currency.ExchangeRate{
+		From:   currency.GBP,
+		To:     currency.USD,
+		Amount: num.MakeAmount(127, 2),
+	})
+	assert.NoError(t, inv.Validate(), "triangulated through document currency")
+
+	inv.ExchangeRates = append(inv.ExchangeRates, &currency.ExchangeRate{
+		From:   currency.MXN,
+		To:     currency.COP,
+		Amount: num.MakeAmount(2100, 1),
+	})
+	assert.ErrorContains(t, inv.Validate(), "exchange_rates: no exchange rate defined for 'MXN' to 'EUR' via 'USD'")
 }
 
 func TestInvoiceAutoSetIssueDate(t *testing.T) {
@@ -1014,6 +1036,59 @@ func TestInvoiceCalculate(t *testing.T) {
 	assert.False(t, i.Totals.Paid())
 }
 
+func TestInvoiceCalculateIdempotent(t *testing.T) {
+	inv := baseInvoiceWithLines(t)
+	inv.SetTags(tax.TagCustomerRates)
+	inv.Discounts = []*bill.Discount{
+		{Reason: "Promo", Percent: num.NewPercentage(10, 2)},
+	}
+	require.NoError(t, inv.Calculate())
+	exp, err := json.Marshal(inv)
+	require.NoError(t, err)
+
+	require.NoError(t, inv.Calculate())
+	out, err := json.Marshal(inv)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(exp), string(out))
+
+	// a fresh copy must produce the same results
+	inv2 := new(bill.Invoice)
+	require.NoError(t, json.Unmarshal(exp, inv2))
+	require.NoError(t, inv2.Calculate())
+	out, err = json.Marshal(inv2)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(exp), string(out))
+
+}
+
+func TestInvoiceTrackChanges(t *testing.T) {
+	bill.SetTrackChanges(true)
+	defer bill.SetTrackChanges(false)
+	assert.True(t, bill.TrackChanges())
+
+	inv := baseInvoiceWithLines(t)
+	require.NoError(t, inv.Calculate())
+	total := inv.Totals.Payable.String()
+	allocs := testing.AllocsPerRun(10, func() {
+		require.NoError(t, inv.Calculate())
+	})
+	assert.Zero(t, allocs, "unchanged invoice should be skipped")
+	assert.Equal(t, total, inv.Totals.Payable.String())
+
+	inv.Lines[0].Quantity = num.MakeAmount(20, 0)
+	require.NoError(t, inv.Calculate())
+	assert.NotEqual(t, total, inv.Totals.Payable.String())
+	total = inv.Totals.Payable.String()
+
+	inv.Totals.Payable = num.MakeAmount(1, 2)
+	require.NoError(t, inv.Calculate())
+	assert.Equal(t, total, inv.Totals.Payable.String())
+
+	inv.Lines[0].Taxes[0].Ext = tax.Extensions{"es-tbai-product": "goods"}
+	require.NoError(t, inv.Calculate())
+	assert.Equal(t, "goods", inv.Totals.Taxes.Categories[0].Rates[0].Ext["es-tbai-product"].String())
+}
+
 func TestCalculateInverted(t *testing.T) {
 	i := &bill.Invoice{
 		Code: "123TEST",
@@ -1277,7 +1352,7 @@ func baseInvoiceWithLines(t *testing.T) *bill.Invoice {
 	return inv
 }
 
-func baseInvoice(t *testing.T, lines ...*bill.Line) *bill.Invoice {
+func baseInvoice(t testing.TB, lines ...*bill.Line) *bill.Invoice {
 	t.Helper()
 	i := &bill.Invoice{
 		Series:    "TEST",
@@ -1365,3 +1440,111 @@ func TestInvoiceJSONSchemaExtend(t *testing.T) {
 	})
 
 }
+
+func TestInvoiceAdvancesExceedPayable(t *testing.T) {
+	inv := baseInvoiceWithLines(t)
+	inv.Payment = &bill.PaymentDetails{
+		Advances: []*pay.Advance{
+			{
+				Description: "Deposit",
+				Amount:      num.MakeAmount(40000, 2),
+				Receipt: &org.DocumentRef{
+					Code:      "R-001",
+					IssueDate: cal.NewDate(2022, 6, 1),
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	require.NoError(t, inv.Validate())
+	assert.Equal(t, "600.00", inv.Totals.Remaining().String())
+
+	inv.Payment.Advances[0].Amount = num.MakeAmount(100100, 2)
+	require.NoError(t, inv.Calculate())
+	err := inv.Validate()
+	assert.ErrorContains(t, err, "totals: (advance: must not exceed payable amount.)")
+	assert.Equal(t, "-1.00", inv.Totals.Remaining().String())
+
+	t.Run("inverted", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Description: "Deposit", Amount: num.MakeAmount(40000, 2)},
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Invert())
+		assert.NoError(t, inv.Validate())
+		assert.Equal(t, "-600.00", inv.Totals.Remaining().String())
+	})
+	t.Run("without advances", func(t *testing.T) {
+		inv := baseInvoiceWithLines(t)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "1000.00", inv.Totals.Remaining().String())
+	})
+}
+
+func BenchmarkInvoiceCalculate(b *testing.B) {
+	b.Run("full", func(b *testing.B) {
+		inv := manyLinesInvoice(b, 10000)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			inv.Totals = nil // force recalculation
+			if err := inv.Calculate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unchanged", func(b *testing.B) {
+		bill.SetTrackChanges(true)
+		defer bill.SetTrackChanges(false)
+		inv := manyLinesInvoice(b, 10000)
+		if err := inv.Calculate(); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := inv.Calculate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestInvoiceCalculateHistoricalRates(t *testing.T) {
+	rate := func(inv *bill.Invoice) string {
+		return inv.Lines[0].Taxes[0].Percent.String()
+	}
+	t.Run("issue date", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.IssueDate = cal.MakeDate(2012, 8, 31)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "18.0%", rate(inv))
+
+		inv.IssueDate = cal.MakeDate(2012, 9, 1)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "21.0%", rate(inv))
+	})
+
+	t.Run("operation date", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.IssueDate = cal.MakeDate(2012, 9, 5)
+		inv.OperationDate = cal.NewDate(2012, 8, 20)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "18.0%", rate(inv))
+
+		// value date takes priority
+		inv.ValueDate = cal.NewDate(2012, 9, 3)
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "21.0%", rate(inv))
+	})
+
+	t.Run("no coverage", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.IssueDate = cal.MakeDate(1990, 1, 1)
+		err := inv.Calculate()
+		assert.ErrorContains(t, err, "rate value unavailable for 'general' in 'VAT' on '1990-01-01'")
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
@@ -35,6 +36,11 @@ type Line struct {
 	// Breakdown of the line item for more detailed information. The sum of all lines
 	// will be used for the item price.
 	Breakdown []*SubLine `json:"breakdown,omitempty" jsonschema:"title=Breakdown"`
+	// Exchange rates used to convert the prices of this line's item and breakdown into
+	// the document's currency. These take priority over the document's exchange rates
+	// so that items sourced in different currencies or at different moments may each
+	// use their own rate.
+	ExchangeRates []*currency.ExchangeRate `json:"exchange_rates,omitempty" jsonschema:"title=Exchange Rates"`
 	// Result of quantity multiplied by the item's price (calculated)
 	Sum *num.Amount `json:"sum,omitempty" jsonschema:"title=Sum" jsonschema_extras:"calculated=true"`
 	// Discounts applied to this line
@@ -43,6 +49,9 @@ type Line struct {
 	Charges []*LineCharge `json:"charges,omitempty" jsonschema:"title=Charges"`
 	// Map of taxes to be applied and used in the invoice totals
 	Taxes tax.Set `json:"taxes,omitempty" jsonschema:"title=Taxes"`
+	// Margin scheme details when the line's VAT is only accountable on the
+	// difference between the sale price and the purchase cost.
+	Margin *LineMargin `json:"margin,omitempty" jsonschema:"title=Margin"`
 	// Total line amount after applying discounts to the sum (calculated).
 	Total *num.Amount `json:"total,omitempty" jsonschema:"title=Total"  jsonschema_extras:"calculated=true"`
 
@@ -99,6 +108,21 @@ func (l *Line) GetTaxes() tax.Set {
 	return l.Taxes
 }
 
+// GetExtensions provides the line's extensions followed by those of each of
+// its taxes, as part of the tax.ScenarioLine interface.
+func (l *Line) GetExtensions() []tax.Extensions {
+	exts := make([]tax.Extensions, 0, len(l.Taxes)+1)
+	if len(l.Ext) > 0 {
+		exts = append(exts, l.Ext)
+	}
+	for _, tc := range l.Taxes {
+		if tc != nil && len(tc.Ext) > 0 {
+			exts = append(exts, tc.Ext)
+		}
+	}
+	return exts
+}
+
 // GetTotal provides the final total for this line, excluding any tax calculations.
 // This implements the tax.TaxableLine interface.
 func (l *Line) GetTotal() num.Amount {
@@ -126,6 +150,9 @@ func (l *Line) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&l.Cost),
 		validation.Field(&l.Item, validation.Required),
 		validation.Field(&l.Breakdown),
+		validation.Field(&l.ExchangeRates,
+			currency.DetectConflictingExchangeRates,
+		),
 		validation.Field(&l.Sum,
 			validation.When(
 				l.Item != nil && l.Item.Price != nil,
@@ -135,6 +162,7 @@ func (l *Line) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&l.Discounts),
 		validation.Field(&l.Charges),
 		validation.Field(&l.Taxes),
+		validation.Field(&l.Margin, validateLineMargin(l.Taxes)),
 		validation.Field(&l.Total,
 			validation.When(
 				l.Item != nil && l.Item.Price != nil,
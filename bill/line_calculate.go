@@ -17,12 +17,16 @@ const (
 )
 
 func calculateLines(lines []*Line, cur currency.Code, rates []*currency.ExchangeRate, rr cbc.Key) error {
-	for i, l := range lines {
+	errs := eachLine(len(lines), func(i int) error {
+		l := lines[i]
 		if l == nil {
-			continue
+			return nil
 		}
 		l.Index = i + 1
-		if err := calculateLine(l, cur, rates, rr); err != nil {
+		return calculateLine(l, cur, rates, rr)
+	})
+	for i, err := range errs {
+		if err != nil {
 			return validation.Errors{strconv.Itoa(i): err}
 		}
 	}
@@ -47,6 +51,10 @@ func calculateLine(l *Line, cur currency.Code, rates []*currency.ExchangeRate, r
 		return nil
 	}
 	zero := cur.Def().Zero()
+	if len(l.ExchangeRates) > 0 {
+		// line rates are matched first
+		rates = append(l.ExchangeRates[:len(l.ExchangeRates):len(l.ExchangeRates)], rates...)
+	}
 
 	if len(l.Substituted) > 0 {
 		// Calculate the substituted line items, which have no consequence on the
@@ -118,6 +126,10 @@ func calculateLine(l *Line, cur currency.Code, rates []*currency.ExchangeRate, r
 	l.Sum = &sum
 	l.Total = &total
 
+	if l.Margin != nil {
+		l.Margin.calculate(total, cur)
+	}
+
 	return nil
 }
 
@@ -628,4 +628,62 @@ func TestLineCalculate(t *testing.T) {
 		assert.Equal(t, "37.77", lines[0].Sum.String())
 		assert.Equal(t, "37.77", lines[0].Total.String())
 	})
+	t.Run("line exchange rates", func(t *testing.T) {
+		lines := []*Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:     "Sourced in US",
+					Currency: currency.USD,
+					Price:    num.NewAmount(1000, 2),
+				},
+				ExchangeRates: []*currency.ExchangeRate{
+					{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(90, 2)},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:     "Also sourced in US",
+					Currency: currency.USD,
+					Price:    num.NewAmount(1000, 2),
+				},
+			},
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item: &org.Item{
+					Name:     "Sourced in Mexico",
+					Currency: currency.MXN,
+					Price:    num.NewAmount(10000, 2),
+				},
+				Breakdown: []*SubLine{
+					{
+						Quantity: num.MakeAmount(1, 0),
+						Item: &org.Item{
+							Name:     "Part",
+							Currency: currency.MXN,
+							Price:    num.NewAmount(10000, 2),
+						},
+					},
+				},
+				ExchangeRates: []*currency.ExchangeRate{
+					{From: currency.MXN, To: currency.EUR, Amount: num.MakeAmount(5, 2)},
+				},
+			},
+		}
+		err := calculateLines(lines, currency.EUR, exampleRates(t), tax.RoundingRuleCurrency)
+		require.NoError(t, err)
+		assert.Equal(t, "9.00", lines[0].Total.String())
+		assert.Equal(t, "8.76", lines[1].Total.String())
+		assert.Equal(t, "10.00", lines[2].Total.String())
+		assert.Equal(t, currency.EUR, lines[0].Item.Currency)
+		require.Len(t, lines[0].Item.AltPrices, 1)
+		assert.Equal(t, "10.00", lines[0].Item.AltPrices[0].Value.String())
+		assert.Equal(t, "100.00", lines[2].Breakdown[0].Item.AltPrices[0].Value.String())
+
+		// recalculating maintains the converted prices
+		err = calculateLines(lines, currency.EUR, exampleRates(t), tax.RoundingRuleCurrency)
+		require.NoError(t, err)
+		assert.Equal(t, "9.00", lines[0].Total.String())
+	})
 }
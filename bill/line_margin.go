@@ -0,0 +1,109 @@
+package bill
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/jsonschema"
+	"github.com/invopop/validation"
+)
+
+// Margin keys identify the type of goods or services sold under a VAT
+// margin scheme, as defined by the EU VAT Directive.
+const (
+	MarginKeySecondHand cbc.Key = "second-hand"
+	MarginKeyArt        cbc.Key = "art"
+	MarginKeyAntiques   cbc.Key = "antiques"
+	MarginKeyTravel     cbc.Key = "travel"
+)
+
+var marginKeyDefinitions = []*cbc.Definition{
+	{
+		Key:  MarginKeySecondHand,
+		Name: i18n.NewString("Second-hand goods"),
+	},
+	{
+		Key:  MarginKeyArt,
+		Name: i18n.NewString("Works of art"),
+	},
+	{
+		Key:  MarginKeyAntiques,
+		Name: i18n.NewString("Collector's items and antiques"),
+	},
+	{
+		Key:  MarginKeyTravel,
+		Name: i18n.NewString("Travel agents"),
+	},
+}
+
+// LineMargin contains the details of a line whose goods or services are
+// sold under a margin scheme, where VAT is only accountable on the
+// difference between the sale price and the original purchase cost, and
+// no VAT breakdown is shown to the customer.
+//
+// Lines with a margin must use the VAT "margin" key, so that no tax is
+// applied in the document's totals.
+type LineMargin struct {
+	// Key identifying the type of margin scheme.
+	Key cbc.Key `json:"key" jsonschema:"title=Key"`
+	// Cost of purchasing the goods or services included in the line.
+	Cost num.Amount `json:"cost" jsonschema:"title=Cost"`
+	// Amount of the margin between the line's total and the cost, which will
+	// be zero if the cost is greater than the total (calculated).
+	Amount num.Amount `json:"amount" jsonschema:"title=Amount" jsonschema_extras:"calculated=true"`
+}
+
+// Validate checks the line margin's fields.
+func (lm *LineMargin) Validate() error {
+	return validation.ValidateStruct(lm,
+		validation.Field(&lm.Key,
+			validation.Required,
+			cbc.InKeyDefs(marginKeyDefinitions),
+		),
+		validation.Field(&lm.Cost, num.ZeroOrPositive),
+		validation.Field(&lm.Amount, num.ZeroOrPositive),
+	)
+}
+
+// JSONSchemaExtend adds the margin key definitions to the schema.
+func (LineMargin) JSONSchemaExtend(schema *jsonschema.Schema) {
+	prop, ok := schema.Properties.Get("key")
+	if !ok {
+		return
+	}
+	prop.OneOf = make([]*jsonschema.Schema, len(marginKeyDefinitions))
+	for i, v := range marginKeyDefinitions {
+		prop.OneOf[i] = &jsonschema.Schema{
+			Const: v.Key,
+			Title: v.Name.String(),
+		}
+	}
+}
+
+func (lm *LineMargin) calculate(total num.Amount, cur currency.Code) {
+	zero := cur.Def().Zero()
+	a := total.Rescale(zero.Exp()).Subtract(lm.Cost.Rescale(zero.Exp()))
+	if a.IsNegative() {
+		a = zero
+	}
+	lm.Amount = a
+}
+
+// validateLineMargin ensures lines with a margin are taxed using the
+// margin scheme.
+func validateLineMargin(taxes tax.Set) validation.Rule {
+	return validation.By(func(value any) error {
+		lm, ok := value.(*LineMargin)
+		if !ok || lm == nil {
+			return nil
+		}
+		if c := taxes.Get(tax.CategoryVAT); c == nil || c.Key != tax.KeyMargin {
+			return errors.New("requires VAT with margin key")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marginLine(price, cost int64) *bill.Line {
+	l := splitLine("Used car", price, "")
+	l.Taxes[0].Key = tax.KeyMargin
+	l.Margin = &bill.LineMargin{
+		Key:  bill.MarginKeySecondHand,
+		Cost: num.MakeAmount(cost, 2),
+	}
+	return l
+}
+
+func TestLineMargin(t *testing.T) {
+	t.Run("calculate", func(t *testing.T) {
+		inv := baseInvoice(t,
+			marginLine(1200000, 1000000),
+			splitLine("Service", 10000, tax.RateGeneral),
+		)
+		inv.Tax = nil
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		l := inv.Lines[0]
+		assert.Equal(t, "2000.00", l.Margin.Amount.String())
+		assert.Nil(t, l.Taxes[0].Percent)
+		assert.Equal(t, "12100.00", inv.Totals.Sum.String())
+		assert.Equal(t, "21.00", inv.Totals.Tax.String())
+		assert.Equal(t, "12121.00", inv.Totals.Payable.String())
+	})
+
+	t.Run("negative margin", func(t *testing.T) {
+		inv := baseInvoice(t, marginLine(80000, 100000))
+		inv.Tax = nil
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "0.00", inv.Lines[0].Margin.Amount.String())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		inv := baseInvoice(t, marginLine(1000, 500))
+		inv.Tax = nil
+		inv.Lines[0].Taxes[0].Key = tax.KeyStandard
+		inv.Lines[0].Taxes[0].Rate = tax.RateGeneral
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "margin: requires VAT with margin key")
+
+		inv = baseInvoice(t, marginLine(1000, 500))
+		inv.Tax = nil
+		inv.Lines[0].Margin.Key = "unknown"
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "margin: (key: must be a valid value.)")
+
+		inv = baseInvoice(t, marginLine(1000, -500))
+		inv.Tax = nil
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "margin: (cost: must be no less than 0.)")
+	})
+}
@@ -95,6 +95,24 @@ func TestLineValidation(t *testing.T) {
 		lines[0].Breakdown[0].Sum = nil
 		require.ErrorContains(t, validation.Validate(lines), "0: (breakdown: (0: (sum: cannot be blank; total: cannot be blank.).).)")
 	})
+	t.Run("exchange rates: conflicting", func(t *testing.T) {
+		lines := []*Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:     "Test Item",
+					Currency: currency.USD,
+					Price:    num.NewAmount(1000, 2),
+				},
+				ExchangeRates: []*currency.ExchangeRate{
+					{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(90, 2)},
+					{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(91, 2)},
+				},
+			},
+		}
+		require.NoError(t, calculateLines(lines, currency.EUR, nil, tax.RoundingRulePrecise))
+		require.ErrorContains(t, validation.Validate(lines), "0: (exchange_rates: conflicting exchange rates for 'USD' to 'EUR'.)")
+	})
 }
 
 func TestLinePriceNormalization(t *testing.T) {
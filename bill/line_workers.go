@@ -0,0 +1,115 @@
+package bill
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/invopop/validation"
+)
+
+// minConcurrentLines is the number of lines a document must have before
+// they're processed concurrently, below which the cost of coordinating
+// goroutines outweighs any benefit.
+const minConcurrentLines = 256
+
+// lineWorkers defines the maximum number of goroutines used to process
+// lines. Zero implies lines are processed sequentially.
+var lineWorkers atomic.Int32
+
+// SetLineWorkers enables the concurrent calculation and validation of
+// lines in documents with many of them, using at most the given number of
+// goroutines. Results and errors are always reported in line order, so
+// output is identical to sequential processing. A value of zero or one
+// disables concurrency, which is the default, while a negative value will
+// use one worker per available CPU.
+func SetLineWorkers(n int) {
+	if n < 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	lineWorkers.Store(int32(n))
+}
+
+// LineWorkers provides the maximum number of goroutines that will be used
+// to process lines.
+func LineWorkers() int {
+	return int(lineWorkers.Load())
+}
+
+// eachLine calls the function with each index up to count, concurrently
+// if enabled and there are enough lines. Errors are returned in a slice
+// matching the position of each line, or nil if there were none.
+func eachLine(count int, fn func(i int) error) []error {
+	var errs []error
+	workers := LineWorkers()
+	if workers <= 1 || count < minConcurrentLines {
+		for i := 0; i < count; i++ {
+			if err := fn(i); err != nil {
+				if errs == nil {
+					errs = make([]error, count)
+				}
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	errs = make([]error, count)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= count {
+					return
+				}
+				if err := fn(i); err != nil {
+					errs[i] = err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+	return nil
+}
+
+// validateLines is a validation rule applied to the lines of a document
+// that checks each one in the same way as validation.Each, but using
+// concurrent workers if enabled.
+type validateLines struct {
+	rules []validation.Rule
+}
+
+func (v validateLines) Validate(value any) error {
+	return v.ValidateWithContext(context.Background(), value)
+}
+
+func (v validateLines) ValidateWithContext(ctx context.Context, value any) error {
+	lines, ok := value.([]*Line)
+	if !ok {
+		return nil
+	}
+	errs := eachLine(len(lines), func(i int) error {
+		return validation.ValidateWithContext(ctx, lines[i], v.rules...)
+	})
+	if errs == nil {
+		return nil
+	}
+	out := make(validation.Errors)
+	for i, err := range errs {
+		if err != nil {
+			out[strconv.Itoa(i)] = err
+		}
+	}
+	return out
+}
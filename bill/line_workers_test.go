@@ -0,0 +1,83 @@
+package bill_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func manyLinesInvoice(t testing.TB, count int) *bill.Invoice {
+	t.Helper()
+	lines := make([]*bill.Line, count)
+	for i := range lines {
+		lines[i] = &bill.Line{
+			Quantity: num.MakeAmount(int64(i%7+1), 0),
+			Item: &org.Item{
+				Name:  fmt.Sprintf("Item %d", i),
+				Price: num.NewAmount(int64(1000+i), 2),
+			},
+			Discounts: []*bill.LineDiscount{
+				{Percent: num.NewPercentage(int64(i%3), 2)},
+			},
+			Taxes: tax.Set{
+				{Category: "VAT", Rate: "general"},
+			},
+		}
+	}
+	return baseInvoice(t, lines...)
+}
+
+func TestSetLineWorkers(t *testing.T) {
+	defer bill.SetLineWorkers(0)
+
+	bill.SetLineWorkers(4)
+	assert.Equal(t, 4, bill.LineWorkers())
+	bill.SetLineWorkers(-1)
+	assert.Greater(t, bill.LineWorkers(), 0)
+	bill.SetLineWorkers(0)
+	assert.Equal(t, 0, bill.LineWorkers())
+}
+
+func TestInvoiceConcurrentLines(t *testing.T) {
+	defer bill.SetLineWorkers(0)
+
+	seq := manyLinesInvoice(t, 1000)
+	require.NoError(t, seq.Calculate())
+	require.NoError(t, seq.Validate())
+
+	bill.SetLineWorkers(8)
+	par := manyLinesInvoice(t, 1000)
+	require.NoError(t, par.Calculate())
+	require.NoError(t, par.Validate())
+
+	exp, err := json.Marshal(seq)
+	require.NoError(t, err)
+	res, err := json.Marshal(par)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(exp), string(res))
+	assert.Equal(t, 1000, par.Lines[999].Index)
+
+	t.Run("calculation errors", func(t *testing.T) {
+		inv := manyLinesInvoice(t, 1000)
+		inv.Lines[700].Item.Currency = "USD"
+		inv.Lines[300].Item.Currency = "GBP"
+		err := inv.Calculate()
+		assert.ErrorContains(t, err, "lines: (300: (item: no exchange rate found from 'GBP' to 'EUR'.).)")
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		inv := manyLinesInvoice(t, 1000)
+		require.NoError(t, inv.Calculate())
+		inv.Lines[800].Item.Name = ""
+		inv.Lines[400] = nil
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "lines: (400: is required; 800: (item: (name: cannot be blank.).).)")
+	})
+}
@@ -91,7 +91,8 @@ type Order struct {
 	IssueTime *cal.Time `json:"issue_time,omitempty" jsonschema:"title=Issue Time" jsonschema_extras:"calculated=true"`
 	// Date when the operation defined by the invoice became effective.
 	OperationDate *cal.Date `json:"op_date,omitempty" jsonschema:"title=Operation Date"`
-	// When the taxes of this invoice become accountable, if none set, the issue date is used.
+	// When the taxes of this invoice become accountable, if none set, the operation
+	// date or the issue date will be used to determine tax rates.
 	ValueDate *cal.Date `json:"value_date,omitempty" jsonschema:"title=Value Date"`
 	// Currency for all invoice totals.
 	Currency currency.Code `json:"currency" jsonschema:"title=Currency" jsonschema_extras:"calculated=true"`
@@ -185,6 +186,11 @@ func (ord *Order) ValidateWithContext(ctx context.Context) error {
 		),
 		validation.Field(&ord.ExchangeRates,
 			validation.Each(validation.NotNil),
+			currency.DetectConflictingExchangeRates,
+			currency.CanTriangulateInto(ord.ExchangeRates, ord.Currency, r.GetCurrency()),
+		),
+		validation.Field(&ord.Tax,
+			checkRoundingRule(r, ord.AddonDefs()),
 		),
 		validation.Field(&ord.Contracts),
 		validation.Field(&ord.Preceding,
@@ -331,6 +337,9 @@ func (ord *Order) getIssueDate() cal.Date {
 func (ord *Order) getIssueTime() *cal.Time {
 	return ord.IssueTime
 }
+func (ord *Order) getOperationDate() *cal.Date {
+	return ord.OperationDate
+}
 func (ord *Order) getValueDate() *cal.Date {
 	return ord.ValueDate
 }
@@ -0,0 +1,103 @@
+package bill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/uuid"
+)
+
+// Invoice prepares a new draft invoice from the order, copying the parties,
+// lines, discounts, charges, and payment and delivery details, and adding a
+// reference to the order in the invoice's ordering data. The order itself
+// will not be modified.
+//
+// Purchase orders will be referenced as purchases and sales orders as sales.
+// Quotes must first be accepted as an order and cannot be invoiced directly.
+// The invoice will be calculated, but must be given a code before it can be
+// signed.
+func (ord *Order) Invoice() (*Invoice, error) {
+	if err := ord.Calculate(); err != nil {
+		return nil, err
+	}
+	if ord.Code == "" {
+		return nil, errors.New("cannot invoice an order without a code")
+	}
+	if ord.Type == OrderTypeQuote {
+		return nil, fmt.Errorf("order type '%s' cannot be invoiced", ord.Type)
+	}
+	o, err := ord.clone()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Invoice{
+		Regime:        o.Regime,
+		Addons:        o.Addons,
+		Type:          InvoiceTypeStandard,
+		Currency:      o.Currency,
+		ExchangeRates: o.ExchangeRates,
+		Tax:           o.Tax,
+		Supplier:      o.Supplier,
+		Customer:      o.Customer,
+		Lines:         o.Lines,
+		Discounts:     o.Discounts,
+		Charges:       o.Charges,
+		Payment:       o.Payment,
+		Delivery:      o.Delivery,
+		Notes:         o.Notes,
+		Ordering: &Ordering{
+			Identities: o.Identities,
+			Period:     o.Period,
+			Buyer:      o.Buyer,
+			Seller:     o.Seller,
+			Contracts:  o.Contracts,
+		},
+	}
+	inv.Ordering.addOrderRef(ord)
+
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// documentRef provides a reference to the order for use in other documents.
+func (ord *Order) documentRef() *org.DocumentRef {
+	return &org.DocumentRef{
+		Identify:  uuid.Identify{UUID: ord.UUID},
+		Type:      ord.Type,
+		Series:    ord.Series,
+		Code:      ord.Code,
+		IssueDate: ord.IssueDate.Clone(),
+	}
+}
+
+// addOrderRef adds a reference to the order in the purchases or sales
+// list according to its type, unless already present.
+func (o *Ordering) addOrderRef(ord *Order) {
+	refs := &o.Purchases
+	if ord.Type == OrderTypeSale {
+		refs = &o.Sales
+	}
+	for _, r := range *refs {
+		if r.Series == ord.Series && r.Code == ord.Code {
+			return
+		}
+	}
+	*refs = append(*refs, ord.documentRef())
+}
+
+func (ord *Order) clone() (*Order, error) {
+	data, err := json.Marshal(ord)
+	if err != nil {
+		return nil, err
+	}
+	out := new(Order)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orderForInvoice(t *testing.T) *bill.Order {
+	t.Helper()
+	ord := baseOrder(t,
+		splitLine("Widget", 1000, tax.RateGeneral),
+		splitLine("Gadget", 2500, tax.RateGeneral),
+	)
+	ord.UUID = uuid.V7()
+	ord.Lines[0].Quantity = num.MakeAmount(10, 0)
+	ord.Lines[1].Item.Ref = "GDG-01"
+	ord.Contracts = []*org.DocumentRef{{Code: "CON-1"}}
+	ord.Period = &cal.Period{
+		Start: cal.MakeDate(2022, 6, 1),
+		End:   cal.MakeDate(2022, 6, 30),
+	}
+	ord.Payment = &bill.PaymentDetails{
+		Terms: &pay.Terms{Key: pay.TermKeyInstant},
+	}
+	return ord
+}
+
+func TestOrderInvoice(t *testing.T) {
+	t.Run("purchase order", func(t *testing.T) {
+		ord := orderForInvoice(t)
+		inv, err := ord.Invoice()
+		require.NoError(t, err)
+		assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+		assert.Empty(t, inv.UUID)
+		assert.Empty(t, inv.Code)
+		assert.Equal(t, "Test Supplier", inv.Supplier.Name)
+		assert.Equal(t, "Test Customer", inv.Customer.Name)
+		require.Len(t, inv.Lines, 2)
+		assert.Equal(t, "100.00", inv.Lines[0].Total.String())
+		assert.Equal(t, "125.00", inv.Totals.Sum.String())
+		assert.Equal(t, "151.25", inv.Totals.Payable.String())
+		assert.Equal(t, pay.TermKeyInstant, inv.Payment.Terms.Key)
+
+		require.NotNil(t, inv.Ordering)
+		assert.Equal(t, "CON-1", inv.Ordering.Contracts[0].Code.String())
+		assert.Equal(t, "2022-06-30", inv.Ordering.Period.End.String())
+		require.Len(t, inv.Ordering.Purchases, 1)
+		ref := inv.Ordering.Purchases[0]
+		assert.Equal(t, ord.UUID, ref.UUID)
+		assert.Equal(t, bill.OrderTypePurchase, ref.Type)
+		assert.Equal(t, "TEST", ref.Series.String())
+		assert.Equal(t, "00123", ref.Code.String())
+		assert.Empty(t, inv.Ordering.Sales)
+
+		// order unmodified by invoice changes
+		inv.Lines[0].Quantity = num.MakeAmount(1, 0)
+		assert.Equal(t, "10", ord.Lines[0].Quantity.String())
+	})
+
+	t.Run("sales order", func(t *testing.T) {
+		ord := orderForInvoice(t)
+		ord.Type = bill.OrderTypeSale
+		inv, err := ord.Invoice()
+		require.NoError(t, err)
+		assert.Empty(t, inv.Ordering.Purchases)
+		require.Len(t, inv.Ordering.Sales, 1)
+		assert.Equal(t, bill.OrderTypeSale, inv.Ordering.Sales[0].Type)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		ord := orderForInvoice(t)
+		ord.Type = bill.OrderTypeQuote
+		_, err := ord.Invoice()
+		assert.ErrorContains(t, err, "order type 'quote' cannot be invoiced")
+
+		ord = orderForInvoice(t)
+		ord.Code = ""
+		_, err = ord.Invoice()
+		assert.ErrorContains(t, err, "cannot invoice an order without a code")
+	})
+}
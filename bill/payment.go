@@ -172,6 +172,8 @@ func (pmt *Payment) ValidateWithContext(ctx context.Context) error {
 		),
 		validation.Field(&pmt.ExchangeRates,
 			validation.Each(validation.NotNil),
+			currency.DetectConflictingExchangeRates,
+			currency.CanTriangulateInto(pmt.ExchangeRates, pmt.Currency, r.GetCurrency()),
 		),
 		validation.Field(&pmt.Ext),
 		validation.Field(&pmt.Preceding,
@@ -273,7 +275,7 @@ func (pmt *Payment) calculate() error {
 	}
 	if pmt.Currency == currency.CodeEmpty {
 		return validation.Errors{
-			"currency": fmt.Errorf("required, unable to determine"),
+			"currency": ErrCurrencyUndetermined,
 		}
 	}
 
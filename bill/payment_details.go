@@ -3,6 +3,7 @@ package bill
 import (
 	"context"
 
+	"github.com/invopop/gobl/currency"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/pay"
@@ -44,6 +45,23 @@ func (p *PaymentDetails) ValidateWithContext(ctx context.Context) error {
 	)
 }
 
+// paymentDueDatesMatchPayable ensures that the amounts of any due dates
+// defined in the payment terms add up to the payable total.
+func paymentDueDatesMatchPayable(totals *Totals, cur currency.Code) validation.Rule {
+	return validation.By(func(value any) error {
+		p, ok := value.(*PaymentDetails)
+		if !ok || p == nil || totals == nil {
+			return nil
+		}
+		return validation.ValidateStruct(p,
+			validation.Field(&p.Terms,
+				pay.DueDatesMatchTotal(totals.Payable, cur),
+				validation.Skip,
+			),
+		)
+	})
+}
+
 // ResetAdvances clears the advances list.
 func (p *PaymentDetails) ResetAdvances() {
 	if p == nil {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
@@ -17,6 +19,24 @@ func TestPaymentDetailsValidation(t *testing.T) {
 	})
 }
 
+func TestPaymentDueDatesMatchPayable(t *testing.T) {
+	p := &PaymentDetails{
+		Terms: &pay.Terms{
+			DueDates: []*pay.DueDate{
+				{Date: cal.NewDate(2024, 1, 1), Amount: num.MakeAmount(5000, 2)},
+				{Date: cal.NewDate(2024, 2, 1), Amount: num.MakeAmount(5000, 2)},
+			},
+		},
+	}
+	totals := &Totals{Payable: num.MakeAmount(10000, 2)}
+	assert.NoError(t, paymentDueDatesMatchPayable(totals, currency.EUR).Validate(p))
+	assert.NoError(t, paymentDueDatesMatchPayable(nil, currency.EUR).Validate(p))
+
+	totals.Payable = num.MakeAmount(12100, 2)
+	err := paymentDueDatesMatchPayable(totals, currency.EUR).Validate(p)
+	assert.ErrorContains(t, err, "terms: (due_dates: sum 100.00 does not match total 121.00.)")
+}
+
 func TestPaymentDetailsResetAdvances(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		p := &PaymentDetails{
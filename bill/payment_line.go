@@ -2,7 +2,6 @@ package bill
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/currency"
@@ -106,7 +105,7 @@ func (pl *PaymentLine) calculate(rates []*currency.ExchangeRate, cur currency.Co
 			if er = currency.MatchExchangeRate(rates, dc, cur); er == nil {
 				return validation.Errors{
 					"document": validation.Errors{
-						"currency": fmt.Errorf("missing exchange rate from %s to %s", dc, cur),
+						"currency": ErrMissingExchangeRate.WithParams(map[string]any{"from": dc, "to": cur}),
 					},
 				}
 			}
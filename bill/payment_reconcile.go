@@ -0,0 +1,156 @@
+package bill
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/uuid"
+)
+
+// Reconcile allocates the amounts of the payment's lines to the invoices they
+// reference. Each allocation is recorded as an advance in the invoice with a
+// reference to the payment as its receipt, so that the invoice's totals will
+// reflect if it has been paid completely or only partially.
+//
+// Every line must reference one of the invoices provided, and every invoice
+// must be referenced by at least one line. Lines for the same invoice, such
+// as installments, will be added together. Refunds and payment requests
+// cannot be reconciled.
+//
+// An error will be returned and none of the invoices modified if the amounts
+// allocated exceed those outstanding, the currencies do not match, or the
+// payment has already been reconciled with one of the invoices.
+func (pmt *Payment) Reconcile(invs ...*Invoice) error {
+	if err := pmt.Calculate(); err != nil {
+		return err
+	}
+	if pmt.Type == PaymentTypeRequest {
+		return fmt.Errorf("payment type '%s' cannot be reconciled", pmt.Type)
+	}
+	for i, inv := range invs {
+		if inv == nil {
+			return fmt.Errorf("invoice %d: missing", i)
+		}
+		if err := inv.Calculate(); err != nil {
+			return fmt.Errorf("invoice %d: %w", i, err)
+		}
+		if inv.Currency != pmt.Currency {
+			return fmt.Errorf("invoice %d: currency '%s' does not match '%s'", i, inv.Currency, pmt.Currency)
+		}
+	}
+
+	allocs := make([]*num.Amount, len(invs))
+	for _, l := range pmt.Lines {
+		if l == nil {
+			continue
+		}
+		if l.Refund {
+			return fmt.Errorf("line %d: refunds cannot be reconciled", l.Index)
+		}
+		if l.Document == nil {
+			return fmt.Errorf("line %d: missing document", l.Index)
+		}
+		i := findReconcileInvoice(invs, l.Document)
+		if i < 0 {
+			return fmt.Errorf("line %d: invoice '%s' not provided", l.Index, refChainKey(l.Document))
+		}
+		if allocs[i] == nil {
+			a := l.Amount
+			allocs[i] = &a
+			continue
+		}
+		a := allocs[i].Add(l.Amount)
+		allocs[i] = &a
+	}
+
+	for i, inv := range invs {
+		if allocs[i] == nil {
+			return fmt.Errorf("invoice %d: not referenced by payment", i)
+		}
+		if pmt.reconciledWith(inv) {
+			return fmt.Errorf("invoice %d: already reconciled with payment", i)
+		}
+		if r := inv.Totals.Remaining(); allocs[i].Compare(r) > 0 {
+			return fmt.Errorf("invoice %d: allocated amount %s exceeds outstanding %s", i, allocs[i], r)
+		}
+	}
+
+	for i, inv := range invs {
+		if inv.Payment == nil {
+			inv.Payment = new(PaymentDetails)
+		}
+		inv.Payment.Advances = append(inv.Payment.Advances, pmt.reconcileAdvance(*allocs[i]))
+		if err := inv.Calculate(); err != nil {
+			return fmt.Errorf("invoice %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// reconcileAdvance prepares an advance for the amount allocated to an invoice
+// from the payment.
+func (pmt *Payment) reconcileAdvance(amount num.Amount) *pay.Advance {
+	date := pmt.IssueDate
+	adv := &pay.Advance{
+		Date:        &date,
+		Description: "Payment",
+		Amount:      amount,
+		Receipt:     pmt.documentRef(),
+	}
+	if pmt.Code != "" {
+		adv.Description = fmt.Sprintf("Payment %s", pmt.Series.Join(pmt.Code))
+	}
+	if pmt.Method != nil {
+		adv.Key = pmt.Method.Key
+	}
+	return adv
+}
+
+// reconciledWith returns true if the invoice already contains an advance with
+// a receipt for the payment.
+func (pmt *Payment) reconciledWith(inv *Invoice) bool {
+	if inv.Payment == nil {
+		return false
+	}
+	ref := pmt.documentRef()
+	for _, a := range inv.Payment.Advances {
+		if a != nil && a.Receipt != nil && sameDocumentRef(a.Receipt, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pmt *Payment) documentRef() *org.DocumentRef {
+	return &org.DocumentRef{
+		Identify:  uuid.Identify{UUID: pmt.UUID},
+		Type:      pmt.Type,
+		Series:    pmt.Series,
+		Code:      pmt.Code,
+		IssueDate: pmt.IssueDate.Clone(),
+	}
+}
+
+func findReconcileInvoice(invs []*Invoice, ref *org.DocumentRef) int {
+	for i, inv := range invs {
+		if sameDocumentRef(ref, &org.DocumentRef{
+			Identify: uuid.Identify{UUID: inv.UUID},
+			Series:   inv.Series,
+			Code:     inv.Code,
+		}) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameDocumentRef compares two document references using their UUIDs if
+// both are available, or their series and codes otherwise.
+func sameDocumentRef(a, b *org.DocumentRef) bool {
+	if !a.UUID.IsZero() && !b.UUID.IsZero() {
+		return a.UUID == b.UUID
+	}
+	return a.Code != "" && a.Series == b.Series && a.Code == b.Code
+}
@@ -0,0 +1,141 @@
+package bill_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reconcileInvoices(t *testing.T) (*bill.Payment, *bill.Invoice, *bill.Invoice) {
+	t.Helper()
+	inv1 := baseInvoice(t, splitLine("Item", 10000, tax.RateGeneral))
+	inv1.Tax = nil
+	inv1.UUID = uuid.V7()
+	inv1.Series = "F1"
+	inv1.Code = "001"
+	inv2 := baseInvoice(t, splitLine("Item", 20000, tax.RateGeneral))
+	inv2.Tax = nil
+	inv2.Series = "F1"
+	inv2.Code = "002"
+
+	pmt := testPaymentMinimal(t)
+	pmt.UUID = uuid.V7()
+	pmt.Lines = []*bill.PaymentLine{
+		{
+			Document: &org.DocumentRef{
+				Identify: uuid.Identify{UUID: inv1.UUID},
+			},
+			Amount: num.MakeAmount(12100, 2),
+		},
+		{
+			Document:    &org.DocumentRef{Series: "F1", Code: "002"},
+			Installment: 1,
+			Amount:      num.MakeAmount(5000, 2),
+		},
+		{
+			Document:    &org.DocumentRef{Series: "F1", Code: "002"},
+			Installment: 2,
+			Amount:      num.MakeAmount(5000, 2),
+		},
+	}
+	return pmt, inv1, inv2
+}
+
+func TestPaymentReconcile(t *testing.T) {
+	t.Run("paid and partially paid", func(t *testing.T) {
+		pmt, inv1, inv2 := reconcileInvoices(t)
+		require.NoError(t, pmt.Reconcile(inv1, inv2))
+
+		assert.True(t, inv1.Totals.Paid())
+		assert.False(t, inv1.Totals.PartiallyPaid())
+		assert.Equal(t, "0.00", inv1.Totals.Due.String())
+		require.Len(t, inv1.Payment.Advances, 1)
+		adv := inv1.Payment.Advances[0]
+		assert.Equal(t, "Payment P1-0123", adv.Description)
+		assert.Equal(t, pay.MeansKeyCard, adv.Key)
+		assert.Equal(t, "2025-01-24", adv.Date.String())
+		require.NotNil(t, adv.Receipt)
+		assert.Equal(t, pmt.UUID, adv.Receipt.UUID)
+		assert.Equal(t, bill.PaymentTypeReceipt, adv.Receipt.Type)
+		assert.Equal(t, "0123", adv.Receipt.Code.String())
+
+		assert.False(t, inv2.Totals.Paid())
+		assert.True(t, inv2.Totals.PartiallyPaid())
+		assert.Equal(t, "100.00", inv2.Totals.Advances.String())
+		assert.Equal(t, "142.00", inv2.Totals.Due.String())
+		require.NoError(t, inv2.Validate())
+
+		err := pmt.Reconcile(inv2)
+		assert.ErrorContains(t, err, "line 1: invoice '"+inv1.UUID.String()+"' not provided")
+		pmt.Lines = pmt.Lines[1:]
+		err = pmt.Reconcile(inv2)
+		assert.ErrorContains(t, err, "invoice 0: already reconciled with payment")
+	})
+
+	t.Run("exceeds outstanding", func(t *testing.T) {
+		pmt, inv1, inv2 := reconcileInvoices(t)
+		pmt.Lines[0].Amount = num.MakeAmount(13000, 2)
+		err := pmt.Reconcile(inv1, inv2)
+		assert.ErrorContains(t, err, "invoice 0: allocated amount 130.00 exceeds outstanding 121.00")
+		assert.Nil(t, inv1.Payment)
+		assert.Nil(t, inv2.Payment)
+	})
+
+	t.Run("mismatches", func(t *testing.T) {
+		pmt, inv1, inv2 := reconcileInvoices(t)
+		pmt.Lines = pmt.Lines[:1]
+		err := pmt.Reconcile(inv1, inv2)
+		assert.ErrorContains(t, err, "invoice 1: not referenced by payment")
+
+		pmt, inv1, _ = reconcileInvoices(t)
+		pmt.Lines = pmt.Lines[:1]
+		pmt.Lines[0].Refund = true
+		err = pmt.Reconcile(inv1)
+		assert.ErrorContains(t, err, "line 1: refunds cannot be reconciled")
+
+		pmt, inv1, _ = reconcileInvoices(t)
+		pmt.Lines = pmt.Lines[:1]
+		pmt.Type = bill.PaymentTypeRequest
+		err = pmt.Reconcile(inv1)
+		assert.ErrorContains(t, err, "payment type 'request' cannot be reconciled")
+
+		pmt, inv1, _ = reconcileInvoices(t)
+		pmt.Lines = pmt.Lines[:1]
+		inv1.Currency = currency.USD
+		inv1.ExchangeRates = []*currency.ExchangeRate{
+			{From: currency.USD, To: currency.EUR, Amount: num.MakeAmount(875, 3)},
+		}
+		err = pmt.Reconcile(inv1)
+		assert.ErrorContains(t, err, "invoice 0: currency 'USD' does not match 'EUR'")
+
+		pmt, _, _ = reconcileInvoices(t)
+		assert.ErrorContains(t, pmt.Reconcile(nil), "invoice 0: missing")
+	})
+
+	t.Run("existing advances", func(t *testing.T) {
+		pmt, inv1, _ := reconcileInvoices(t)
+		pmt.Lines = pmt.Lines[:1]
+		pmt.Lines[0].Amount = num.MakeAmount(10100, 2)
+		inv1.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{
+					Date:        cal.NewDate(2025, 1, 1),
+					Description: "Deposit",
+					Amount:      num.MakeAmount(2000, 2),
+				},
+			},
+		}
+		require.NoError(t, pmt.Reconcile(inv1))
+		assert.Len(t, inv1.Payment.Advances, 2)
+		assert.True(t, inv1.Totals.Paid())
+	})
+}
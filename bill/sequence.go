@@ -0,0 +1,100 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/invopop/gobl/cbc"
+)
+
+// SequenceProvider is implemented by services able to assign the next code
+// from a series to documents as they are issued, ensuring that codes are
+// consecutive and never repeated.
+type SequenceProvider interface {
+	// Next provides the code to use for the next document in the series.
+	Next(series cbc.Code) (cbc.Code, error)
+}
+
+// MemorySequence is a reference implementation of a SequenceProvider that
+// keeps the last number of each series in memory. It is safe for concurrent
+// use, but numbers will be lost when the process ends, so it is mainly
+// useful for testing or when the state can be restored with Set.
+type MemorySequence struct {
+	format string
+	last   map[cbc.Code]int
+	mu     sync.Mutex
+}
+
+// NewMemorySequence prepares a new in-memory sequence provider that will
+// output codes using the format provided, such as "%05d", or just the
+// number if empty.
+func NewMemorySequence(format string) *MemorySequence {
+	if format == "" {
+		format = "%d"
+	}
+	return &MemorySequence{
+		format: format,
+		last:   make(map[cbc.Code]int),
+	}
+}
+
+// Set defines the last number issued in the series, so that the next code
+// will use the following number.
+func (s *MemorySequence) Set(series cbc.Code, last int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last[series] = last
+}
+
+// Next provides the code for the next number in the series.
+func (s *MemorySequence) Next(series cbc.Code) (cbc.Code, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.last[series] + 1
+	code := cbc.NormalizeCode(cbc.Code(fmt.Sprintf(s.format, n)))
+	if err := code.Validate(); err != nil {
+		return cbc.CodeEmpty, fmt.Errorf("invalid code '%s': %w", code, err)
+	}
+	s.last[series] = n
+	return code, nil
+}
+
+// AssignCode uses the sequence provider to assign the next code from the
+// invoice's series. The invoice is calculated and validated before the code
+// is requested, so that numbers are not consumed by documents that could
+// not be issued, helping to ensure there are no gaps in regimes that do not
+// allow them.
+//
+// The code is then checked against the rules of the regime and add-ons in
+// use, such as specific formats. If not valid, the code will be removed and
+// an error returned, in which case the sequence provider will most likely
+// need to be reviewed as the number will have been consumed.
+func (inv *Invoice) AssignCode(seq SequenceProvider) error {
+	if seq == nil {
+		return errors.New("sequence provider required")
+	}
+	if inv.Code != "" {
+		return fmt.Errorf("code already assigned: '%s'", inv.Code)
+	}
+	if err := inv.Calculate(); err != nil {
+		return err
+	}
+	if err := inv.Validate(); err != nil {
+		return err
+	}
+	code, err := seq.Next(inv.Series)
+	if err != nil {
+		return fmt.Errorf("sequence: %w", err)
+	}
+	inv.Code = code
+	if err := inv.Calculate(); err != nil {
+		inv.Code = cbc.CodeEmpty
+		return err
+	}
+	if err := inv.Validate(); err != nil {
+		inv.Code = cbc.CodeEmpty
+		return fmt.Errorf("sequence code '%s': %w", code, err)
+	}
+	return nil
+}
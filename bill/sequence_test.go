@@ -0,0 +1,97 @@
+package bill_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySequence(t *testing.T) {
+	t.Run("next", func(t *testing.T) {
+		seq := bill.NewMemorySequence("")
+		c, err := seq.Next("A")
+		require.NoError(t, err)
+		assert.Equal(t, "1", c.String())
+		c, err = seq.Next("A")
+		require.NoError(t, err)
+		assert.Equal(t, "2", c.String())
+		c, err = seq.Next("B")
+		require.NoError(t, err)
+		assert.Equal(t, "1", c.String())
+	})
+
+	t.Run("with format and set", func(t *testing.T) {
+		seq := bill.NewMemorySequence("%05d")
+		seq.Set("A", 122)
+		c, err := seq.Next("A")
+		require.NoError(t, err)
+		assert.Equal(t, "00123", c.String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		seq := bill.NewMemorySequence("%d--")
+		_, err := seq.Next("A")
+		assert.ErrorContains(t, err, "invalid code '1-'")
+		seq = bill.NewMemorySequence("A-%d")
+		c, err := seq.Next("A")
+		require.NoError(t, err)
+		assert.Equal(t, "A-1", c.String())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		seq := bill.NewMemorySequence("")
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = seq.Next("A")
+			}()
+		}
+		wg.Wait()
+		c, err := seq.Next("A")
+		require.NoError(t, err)
+		assert.Equal(t, "51", c.String())
+	})
+}
+
+func TestInvoiceAssignCode(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.Code = ""
+		seq := bill.NewMemorySequence("%05d")
+		require.NoError(t, inv.AssignCode(seq))
+		assert.Equal(t, "00001", inv.Code.String())
+
+		err := inv.AssignCode(seq)
+		assert.ErrorContains(t, err, "code already assigned: '00001'")
+	})
+
+	t.Run("invalid invoice", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.Code = ""
+		inv.Customer.Name = ""
+		seq := new(testSequence)
+		assert.ErrorContains(t, inv.AssignCode(seq), "customer: (name: cannot be blank.)")
+		assert.Empty(t, seq.last, "sequence should not be used")
+	})
+
+	t.Run("invalid code format", func(t *testing.T) {
+		pf := proformaInvoicePT(t)
+		pf.Code = ""
+		seq := bill.NewMemorySequence("X%d")
+		err := pf.AssignCode(seq)
+		assert.ErrorContains(t, err, "sequence code 'X1'")
+		assert.Empty(t, pf.Code)
+	})
+
+	t.Run("missing sequence", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("Item", 1000, tax.RateGeneral))
+		inv.Code = ""
+		assert.ErrorContains(t, inv.AssignCode(nil), "sequence provider required")
+	})
+}
@@ -3,6 +3,7 @@ package bill
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/tax"
@@ -95,6 +96,30 @@ func (t *Tax) ValidateWithContext(ctx context.Context) error {
 	)
 }
 
+// checkRoundingRule ensures the tax's rounding rule, if any, is allowed by
+// the regime and add-ons of the document.
+func checkRoundingRule(r *tax.RegimeDef, addons []*tax.AddonDef) validation.Rule {
+	return validation.By(func(value any) error {
+		t, ok := value.(*Tax)
+		if !ok || t == nil || t.Rounding == cbc.KeyEmpty {
+			return nil
+		}
+		if !r.AllowsRoundingRule(t.Rounding) {
+			return validation.Errors{
+				"rounding": fmt.Errorf("not allowed by regime '%s'", r.GetCountry()),
+			}
+		}
+		for _, ad := range addons {
+			if !ad.AllowsRoundingRule(t.Rounding) {
+				return validation.Errors{
+					"rounding": fmt.Errorf("not allowed by addon '%s'", ad.Key),
+				}
+			}
+		}
+		return nil
+	})
+}
+
 // UnmarshalJSON helps migrate the desc field to description.
 func (t *Tax) UnmarshalJSON(data []byte) error {
 	type Alias Tax
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/jsonschema"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,45 @@ func TestTaxValidation(t *testing.T) {
 	})
 }
 
+func TestTaxRoundingRules(t *testing.T) {
+	t.Run("line", func(t *testing.T) {
+		inv := baseInvoice(t,
+			splitLine("A", 5, tax.RateReduced),
+			splitLine("B", 5, tax.RateReduced),
+			splitLine("C", 5, tax.RateReduced),
+		)
+		inv.Tax = &bill.Tax{Rounding: tax.RoundingRuleLine}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Equal(t, "0.15", inv.Totals.Sum.String())
+		assert.Equal(t, "0.03", inv.Totals.Tax.String())
+		assert.Equal(t, "0.18", inv.Totals.Payable.String())
+	})
+	t.Run("half even", func(t *testing.T) {
+		inv := baseInvoice(t, splitLine("A", 25, tax.RateReduced))
+		inv.Tax = &bill.Tax{Rounding: tax.RoundingRuleHalfEven}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "0.02", inv.Totals.Tax.String())
+		inv.Tax.Rounding = tax.RoundingRuleCurrency
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, "0.03", inv.Totals.Tax.String())
+	})
+	t.Run("not allowed by regime", func(t *testing.T) {
+		r := tax.RegimeDefFor("ES")
+		rules := r.RoundingRules
+		defer func() { r.RoundingRules = rules }()
+		r.RoundingRules = []cbc.Key{tax.RoundingRulePrecise, tax.RoundingRuleCurrency}
+
+		inv := baseInvoice(t, splitLine("A", 1000, tax.RateGeneral))
+		inv.Tax = &bill.Tax{Rounding: tax.RoundingRuleCurrency}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		inv.Tax.Rounding = tax.RoundingRuleLine
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "tax: (rounding: not allowed by regime 'ES'.)")
+	})
+}
+
 func TestTaxNormalize(t *testing.T) {
 	t.Run("switch rounding, sum-then-round", func(t *testing.T) {
 		tx := &bill.Tax{
@@ -215,9 +255,11 @@ func TestTaxJSONSchemaExtend(t *testing.T) {
 
 	prop, ok := schema.Properties.Get("rounding")
 	require.True(t, ok)
-	assert.Len(t, prop.OneOf, 2)
+	assert.Len(t, prop.OneOf, 4)
 	assert.Equal(t, "precise", prop.OneOf[0].Const)
 	assert.Equal(t, "currency", prop.OneOf[1].Const)
+	assert.Equal(t, "line", prop.OneOf[2].Const)
+	assert.Equal(t, "half-even", prop.OneOf[3].Const)
 }
 
 func TestTaxGetExt(t *testing.T) {
@@ -3,6 +3,7 @@ package bill
 import (
 	"context"
 
+	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/validation"
@@ -28,6 +29,11 @@ type Totals struct {
 	TotalWithTax num.Amount `json:"total_with_tax" jsonschema:"title=Total with Tax"`
 	// Total tax amount retained or withheld by the customer to be paid to the tax authority.
 	RetainedTax *num.Amount `json:"retained_tax,omitempty" jsonschema:"title=Retained Tax"`
+	// Breakdown of the retained tax amount by category (calculated).
+	RetainedTaxes []*RetainedTaxTotal `json:"retained_taxes,omitempty" jsonschema:"title=Retained Taxes" jsonschema_extras:"calculated=true"`
+	// CashBasis is true when taxes only become accountable once payment is received,
+	// as indicated by the "cash-basis" tag (calculated).
+	CashBasis bool `json:"cash_basis,omitempty" jsonschema:"title=Cash Basis" jsonschema_extras:"calculated=true"`
 	// Adjustment amount applied to the invoice totals to meet rounding rules or expectations.
 	Rounding *num.Amount `json:"rounding,omitempty" jsonschema:"title=Rounding"`
 	// Final amount to be paid after retained taxes and rounding adjustments.
@@ -38,6 +44,17 @@ type Totals struct {
 	Due *num.Amount `json:"due,omitempty" jsonschema:"title=Due"`
 }
 
+// RetainedTaxTotal summarises the amounts of a single tax category retained
+// or withheld by the customer.
+type RetainedTaxTotal struct {
+	// Code of the retained tax category.
+	Category cbc.Code `json:"category" jsonschema:"title=Category"`
+	// Sum of the base amounts the category was applied to.
+	Base num.Amount `json:"base" jsonschema:"title=Base"`
+	// Amount retained for the category, including any surcharges.
+	Amount num.Amount `json:"amount" jsonschema:"title=Amount"`
+}
+
 // ValidateWithContext checks the totals calculated for the invoice.
 func (t *Totals) ValidateWithContext(ctx context.Context) error {
 	return validation.ValidateStructWithContext(ctx, t,
@@ -49,9 +66,13 @@ func (t *Totals) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&t.Taxes),
 		validation.Field(&t.Tax),
 		validation.Field(&t.TotalWithTax),
+		validation.Field(&t.RetainedTax),
+		validation.Field(&t.RetainedTaxes),
 		validation.Field(&t.Rounding),
 		validation.Field(&t.Payable),
-		validation.Field(&t.Advances),
+		validation.Field(&t.Advances,
+			validation.By(t.checkAdvancesWithinPayable),
+		),
 		validation.Field(&t.Due),
 	)
 }
@@ -68,6 +89,8 @@ func (t *Totals) reset(zero num.Amount) {
 	t.Tax = zero
 	t.TotalWithTax = zero
 	t.RetainedTax = nil
+	t.RetainedTaxes = nil
+	t.CashBasis = false
 	// t.Rounding = nil // may have been provided externally
 	t.Payable = zero
 	t.Advances = nil
@@ -80,6 +103,107 @@ func (t *Totals) Paid() bool {
 	return t != nil && t.Due != nil && t.Due.IsZero()
 }
 
+// PartiallyPaid determines if some, but not all, of the payable amount
+// has been paid in advance.
+func (t *Totals) PartiallyPaid() bool {
+	return t != nil && t.Due != nil && !t.Due.IsZero() &&
+		t.Advances != nil && !t.Advances.IsZero()
+}
+
+// Remaining provides the balance that remains to be paid after subtracting
+// any advances from the payable amount.
+func (t *Totals) Remaining() num.Amount {
+	if t.Due != nil {
+		return *t.Due
+	}
+	if t.Advances != nil {
+		return t.Payable.Subtract(*t.Advances)
+	}
+	return t.Payable
+}
+
+// RetainedTaxTotal provides the retained tax total for the category, or nil
+// if the category was not retained.
+func (t *Totals) RetainedTaxTotal(cat cbc.Code) *RetainedTaxTotal {
+	if t == nil {
+		return nil
+	}
+	for _, rt := range t.RetainedTaxes {
+		if rt.Category == cat {
+			return rt
+		}
+	}
+	return nil
+}
+
+// NetPayable provides the amount that would be payable if only the retained
+// tax categories provided were withheld from the total with tax, including
+// any rounding adjustment. This is useful to present intermediate balances
+// when multiple categories are retained, such as ISR and IVA retentions in
+// Mexico. The payable amount is returned when no categories are provided.
+func (t *Totals) NetPayable(cats ...cbc.Code) num.Amount {
+	if len(cats) == 0 {
+		return t.Payable
+	}
+	p := t.TotalWithTax
+	for _, rt := range t.RetainedTaxes {
+		if rt.Category.In(cats...) {
+			p = p.Subtract(rt.Amount)
+		}
+	}
+	if t.Rounding != nil {
+		p = p.Add(*t.Rounding)
+	}
+	return p
+}
+
+// calculateRetainedTaxes prepares the breakdown of retained taxes from the
+// tax totals.
+func (t *Totals) calculateRetainedTaxes() {
+	t.RetainedTaxes = nil
+	if t.Taxes == nil {
+		return
+	}
+	for _, ct := range t.Taxes.Categories {
+		if !ct.Retained || ct.Informative {
+			continue
+		}
+		rt := &RetainedTaxTotal{
+			Category: ct.Code,
+			Amount:   ct.Amount,
+		}
+		for i, r := range ct.Rates {
+			if i == 0 {
+				rt.Base = r.Base
+				continue
+			}
+			rt.Base = rt.Base.MatchPrecision(r.Base).Add(r.Base)
+		}
+		if ct.Surcharge != nil {
+			rt.Amount = rt.Amount.Add(*ct.Surcharge)
+		}
+		t.RetainedTaxes = append(t.RetainedTaxes, rt)
+	}
+}
+
+// checkAdvancesWithinPayable ensures the advances never exceed the amount
+// payable, taking into account that inverted documents may use negative
+// amounts.
+func (t *Totals) checkAdvancesWithinPayable(_ any) error {
+	if t.Advances == nil {
+		return nil
+	}
+	a := *t.Advances
+	if t.Payable.IsNegative() {
+		a = a.Invert()
+	}
+	p := t.Payable.Abs()
+	if a.Compare(p) > 0 {
+		return ErrAdvancesExceedPayable
+	}
+	return nil
+}
+
 // round goes through each value that is set and rescales to match
 // the zero's exponent
 func (t *Totals) round(zero num.Amount) {
@@ -103,6 +227,10 @@ func (t *Totals) round(zero num.Amount) {
 	if t.RetainedTax != nil {
 		*t.RetainedTax = t.RetainedTax.Rescale(e)
 	}
+	for _, rt := range t.RetainedTaxes {
+		rt.Base = rt.Base.Rescale(e)
+		rt.Amount = rt.Amount.Rescale(e)
+	}
 	t.Payable = t.Payable.Rescale(e)
 	if t.Advances != nil {
 		*t.Advances = t.Advances.Rescale(e)
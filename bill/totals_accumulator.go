@@ -0,0 +1,137 @@
+package bill
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// TotalsAccumulator calculates the totals of an invoice from lines that are
+// provided one at a time, instead of being kept in the invoice, so that
+// documents with very large numbers of lines can be processed within
+// limited memory.
+//
+// Each line is normalized, calculated, and rounded as it is added, exactly
+// as if it was part of the invoice, after which it may be written out
+// and discarded. Normalizers that operate on the complete invoice will
+// however not be able to see or update the lines.
+type TotalsAccumulator struct {
+	inv         *Invoice
+	calc        *calculation
+	tc          *tax.TotalCalculator
+	normalizers tax.Normalizers
+	count       int
+	taxable     bool
+	done        bool
+}
+
+// NewTotalsAccumulator prepares an accumulator for the invoice, which must
+// not contain any lines, performing the same normalizations and header
+// calculations as Calculate.
+func NewTotalsAccumulator(inv *Invoice) (*TotalsAccumulator, error) {
+	if len(inv.Lines) > 0 {
+		return nil, errors.New("invoice must not contain lines")
+	}
+	if inv.Regime.IsEmpty() {
+		inv.SetRegime(partyTaxCountry(inv.Supplier))
+	}
+	normalizers := tax.ExtractNormalizers(inv)
+	inv.Normalize(normalizers)
+
+	c, err := prepareCalculation(inv)
+	if err != nil {
+		return nil, err
+	}
+	c.totals.Taxes = new(tax.Total)
+	tc := c.taxCalculator()
+	tc.Reset(c.totals.Taxes)
+
+	return &TotalsAccumulator{
+		inv:         inv,
+		calc:        c,
+		tc:          tc,
+		normalizers: normalizers,
+	}, nil
+}
+
+// Add normalizes and calculates the line, and includes it in the totals.
+// Lines are indexed in the order they are added.
+func (ta *TotalsAccumulator) Add(l *Line) error {
+	if ta.done {
+		return errors.New("totals already completed")
+	}
+	if l == nil {
+		return nil
+	}
+	i := ta.count
+	ta.count++
+	l.Index = ta.count
+
+	if ta.inv.HasTags(tax.TagCustomerRates) && ta.inv.Customer != nil && ta.inv.Customer.TaxID != nil {
+		addCountryToTaxes(l.Taxes, ta.inv.Customer.TaxID.Country)
+	}
+	tax.Normalize(ta.normalizers, l)
+	c := ta.calc
+	if err := calculateLine(l, c.cur, ta.inv.ExchangeRates, c.rr); err != nil {
+		return validation.Errors{"lines": validation.Errors{strconv.Itoa(i): err}}
+	}
+	if l.Total != nil {
+		t := c.totals
+		t.Sum = t.Sum.MatchPrecision(*l.Total)
+		t.Sum = t.Sum.Add(*l.Total)
+		if err := ta.tc.Add(t.Taxes, l); err != nil {
+			return err
+		}
+		ta.taxable = true
+	}
+	l.round()
+	return nil
+}
+
+// Count provides the number of lines added so far.
+func (ta *TotalsAccumulator) Count() int {
+	return ta.count
+}
+
+// Totals completes the calculations using the lines added, alongside the
+// invoice's discounts, charges, and payment details, and updates the
+// invoice's totals. No more lines may be added afterwards.
+func (ta *TotalsAccumulator) Totals() (*Totals, error) {
+	if ta.done {
+		return ta.inv.Totals, nil
+	}
+	ta.done = true
+	inv := ta.inv
+	c := ta.calc
+	t := c.totals
+	t.Total = t.Sum
+
+	c.calculateDiscountsAndCharges(inv)
+	if !ta.taxable && len(inv.Discounts) == 0 && len(inv.Charges) == 0 {
+		inv.setTotals(nil)
+		return nil, nil
+	}
+	for _, d := range inv.Discounts {
+		if d != nil {
+			if err := ta.tc.Add(t.Taxes, d); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, ch := range inv.Charges {
+		if ch != nil {
+			if err := ta.tc.Add(t.Taxes, ch); err != nil {
+				return nil, err
+			}
+		}
+	}
+	ta.tc.Complete(t.Taxes)
+	c.complete(inv)
+
+	if err := inv.prepareScenarios(); err != nil {
+		return nil, err
+	}
+	return inv.Totals, nil
+}
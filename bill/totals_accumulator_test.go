@@ -0,0 +1,87 @@
+package bill_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalsAccumulator(t *testing.T) {
+	exp := manyLinesInvoice(t, 500)
+	exp.Discounts = []*bill.Discount{
+		{
+			Reason:  "Loyalty",
+			Percent: num.NewPercentage(5, 2),
+			Taxes:   tax.Set{{Category: "VAT", Rate: "general"}},
+		},
+	}
+	require.NoError(t, exp.Calculate())
+
+	src := manyLinesInvoice(t, 500)
+	inv := baseInvoice(t)
+	inv.Discounts = []*bill.Discount{
+		{
+			Reason:  "Loyalty",
+			Percent: num.NewPercentage(5, 2),
+			Taxes:   tax.Set{{Category: "VAT", Rate: "general"}},
+		},
+	}
+	ta, err := bill.NewTotalsAccumulator(inv)
+	require.NoError(t, err)
+	for i, l := range src.Lines {
+		require.NoError(t, ta.Add(l))
+		// compare each line as it would be written out
+		assert.Equal(t, exp.Lines[i], l)
+	}
+	assert.Equal(t, 500, ta.Count())
+
+	totals, err := ta.Totals()
+	require.NoError(t, err)
+	assert.Same(t, inv.Totals, totals)
+
+	et, err := json.Marshal(exp.Totals)
+	require.NoError(t, err)
+	rt, err := json.Marshal(totals)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(et), string(rt))
+	assert.Equal(t, exp.Discounts, inv.Discounts)
+
+	t.Run("completed", func(t *testing.T) {
+		err := ta.Add(&bill.Line{})
+		assert.ErrorContains(t, err, "totals already completed")
+	})
+}
+
+func TestTotalsAccumulatorErrors(t *testing.T) {
+	t.Run("with lines", func(t *testing.T) {
+		_, err := bill.NewTotalsAccumulator(baseInvoiceWithLines(t))
+		assert.ErrorContains(t, err, "invoice must not contain lines")
+	})
+	t.Run("invalid line", func(t *testing.T) {
+		ta, err := bill.NewTotalsAccumulator(baseInvoice(t))
+		require.NoError(t, err)
+		require.NoError(t, ta.Add(manyLinesInvoice(t, 1).Lines[0]))
+		err = ta.Add(&bill.Line{
+			Quantity: num.MakeAmount(1, 0),
+			Item: &org.Item{
+				Name:     "Foreign item",
+				Currency: "USD",
+				Price:    num.NewAmount(1000, 2),
+			},
+		})
+		assert.ErrorContains(t, err, "lines: (1: ")
+	})
+	t.Run("no lines", func(t *testing.T) {
+		ta, err := bill.NewTotalsAccumulator(baseInvoice(t))
+		require.NoError(t, err)
+		totals, err := ta.Totals()
+		assert.NoError(t, err)
+		assert.Nil(t, totals)
+	})
+}
@@ -0,0 +1,179 @@
+package c14n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JCS parses the JSON source and provides the output according to the JSON
+// Canonicalization Scheme defined in RFC 8785. Unlike GOBL's own canonical
+// format, JCS retains null values, serializes numbers as in ECMAScript, and
+// sorts object keys by their UTF-16 code units, making it straightforward to
+// reproduce in other languages.
+func JCS(src io.Reader) ([]byte, error) {
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil // nothing to marshal
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	buf := new(bytes.Buffer)
+	if err := jcsEncode(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalJCS serializes the Go object to JSON and provides the RFC 8785
+// canonical representation.
+func MarshalJCS(src any) ([]byte, error) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return nil, fmt.Errorf("encoding: %w", err)
+	}
+	return JCS(bytes.NewReader(data))
+}
+
+func jcsEncode(buf *bytes.Buffer, v any) error {
+	switch tv := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if tv {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		return jcsString(buf, tv)
+	case json.Number:
+		f, err := tv.Float64()
+		if err != nil {
+			return fmt.Errorf("number '%s': %w", tv, err)
+		}
+		s, err := jcsNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case []any:
+		buf.WriteByte('[')
+		for i, sv := range tv {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := jcsEncode(buf, sv); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return jcsLess(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := jcsString(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := jcsEncode(buf, tv[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
+
+// jcsLess compares the strings using their UTF-16 code units.
+func jcsLess(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// jcsNumber formats the number as ECMAScript's Number.prototype.toString.
+func jcsNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("invalid number")
+	}
+	if f == 0 {
+		return "0", nil // includes negative zero
+	}
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// remove leading zero from exponent: 1e-07 becomes 1e-7
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s, nil
+}
+
+// jcsString writes the string using the minimal set of escape sequences.
+func jcsString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return errors.New("invalid UTF-8 string")
+	}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
@@ -0,0 +1,74 @@
+package c14n_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl/c14n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJCS(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "empty",
+			in:   ``,
+			out:  ``,
+		},
+		{
+			name: "sorted keys with null",
+			in:   `{ "b": null, "a": [1, true, false], "c": {} }`,
+			out:  `{"a":[1,true,false],"b":null,"c":{}}`,
+		},
+		{
+			// Example from RFC 8785 section 3.2.2
+			name: "rfc example",
+			in: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'B\u0022\u005c\u005c\u0022\u002f",
+				"literals": [null, true, false]
+			}`,
+			out: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name: "utf16 key order",
+			in:   `{"😀": 1, "ﬁ": 2, "a": 3}`,
+			out:  `{"a":3,"😀":1,"ﬁ":2}`,
+		},
+		{
+			name: "numbers",
+			in:   `[0, -0, 1.0, 100, 1e21, 1e-7, 0.000001, 123456789012345680000]`,
+			out:  `[0,0,1,100,1e+21,1e-7,0.000001,123456789012345680000]`,
+		},
+		{
+			name: "html characters not escaped",
+			in:   `{"html":"<a href=\"x\">&</a>"}`,
+			out:  `{"html":"<a href=\"x\">&</a>"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := c14n.JCS(strings.NewReader(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, string(out))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := c14n.JCS(strings.NewReader(`{"a":`))
+		assert.Error(t, err)
+		_, err = c14n.JCS(strings.NewReader(`{} {}`))
+		assert.ErrorContains(t, err, "unexpected data")
+	})
+}
+
+func TestMarshalJCS(t *testing.T) {
+	out, err := c14n.MarshalJCS(map[string]any{"b": "2", "a": 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1.5,"b":"2"}`, string(out))
+}
@@ -0,0 +1,350 @@
+package iso
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/validation"
+)
+
+// SchemeDef describes an ISO 6523 ICD (International Code Designator) or
+// Peppol EAS (Electronic Address Scheme) code used to identify the
+// scheme of party identifiers and electronic addresses.
+type SchemeDef struct {
+	// Four digit scheme code
+	Code cbc.Code `json:"code" jsonschema:"title=Code"`
+	// Short label or acronym commonly used for the identifiers, if any
+	Label string `json:"label,omitempty" jsonschema:"title=Label"`
+	// Name of the scheme or the organization that issues the identifiers
+	Name i18n.String `json:"name" jsonschema:"title=Name"`
+	// Country whose VAT numbers are used as identifiers in the scheme, if any
+	VAT l10n.TaxCountryCode `json:"vat,omitempty" jsonschema:"title=VAT Country"`
+
+	// Check validates the identifier's code, if needed.
+	Check func(code string) bool `json:"-"`
+}
+
+// schemeDefs contains the most widely used ICD codes alongside the EAS
+// codes defined for VAT numbers by Peppol.
+var schemeDefs = []*SchemeDef{
+	{Code: "0002", Label: "SIRENE", Name: i18n.NewString("System Information et Repertoire des Entreprise et des Etablissements: SIRENE"), Check: checkLuhn(9, 14)},
+	{Code: "0007", Name: i18n.NewString("Organisationsnummer (Swedish legal entities)"), Check: checkLuhn(10)},
+	{Code: "0009", Label: "SIRET", Name: i18n.NewString("SIRET-CODE"), Check: checkLuhn(14)},
+	{Code: "0037", Name: i18n.NewString("LY-tunnus (Finnish Organization Identifier)")},
+	{Code: "0060", Label: "DUNS", Name: i18n.NewString("Data Universal Numbering System (D-U-N-S Number)"), Check: checkDigits(9)},
+	{Code: "0088", Label: "GLN", Name: i18n.NewString("Global Location Number (GLN)"), Check: checkGS1(13)},
+	{Code: "0096", Name: i18n.NewString("The Danish Business Authority - P-number (DK:P)"), Check: checkDigits(10)},
+	{Code: "0097", Name: i18n.NewString("FTI - Ediforum Italia (EDIRA compliant)")},
+	{Code: "0106", Name: i18n.NewString("Vereniging van Kamers van Koophandel en Fabrieken in Nederland (KvK)"), Check: checkDigits(8)},
+	{Code: "0130", Name: i18n.NewString("Directorates of the European Commission")},
+	{Code: "0135", Name: i18n.NewString("SIA Object Identifiers")},
+	{Code: "0142", Name: i18n.NewString("SECETI Object Identifiers")},
+	{Code: "0151", Label: "ABN", Name: i18n.NewString("Australian Business Number (ABN) Scheme"), Check: checkABN},
+	{Code: "0160", Label: "GTIN", Name: i18n.NewString("GS1 Global Trade Item Number (GTIN)"), Check: checkGS1(8, 12, 13, 14)},
+	{Code: "0183", Name: i18n.NewString("Swiss Unique Business Identification Number (UIDB)")},
+	{Code: "0184", Label: "CVR", Name: i18n.NewString("DIGSTORG (Danish CVR number)"), Check: checkDigits(8)},
+	{Code: "0188", Name: i18n.NewString("Corporate Number of The Social Security and Tax Number System (Japan)"), Check: checkDigits(13)},
+	{Code: "0190", Label: "OIN", Name: i18n.NewString("Dutch Originator's Identification Number (OIN)"), Check: checkDigits(20)},
+	{Code: "0191", Name: i18n.NewString("Centre of Registers and Information Systems of the Ministry of Justice (Estonia)"), Check: checkDigits(8)},
+	{Code: "0192", Name: i18n.NewString("Enhetsregisteret ved Bronnoysundregisterne (Norway)"), Check: checkNorwegianOrg},
+	{Code: "0193", Name: i18n.NewString("UBL.BE party identifier")},
+	{Code: "0195", Name: i18n.NewString("Singapore UEN identifier")},
+	{Code: "0196", Name: i18n.NewString("Kennitala - Iceland legal id for individuals and legal entities"), Check: checkDigits(10)},
+	{Code: "0198", Name: i18n.NewString("ERSTORG (Danish)")},
+	{Code: "0199", Label: "LEI", Name: i18n.NewString("Legal Entity Identifier (LEI)"), Check: checkLEI},
+	{Code: "0200", Name: i18n.NewString("Legal entity code (Lithuania)"), Check: checkDigits(9)},
+	{Code: "0201", Name: i18n.NewString("Codice Univoco Unità Organizzativa iPA (Italy)")},
+	{Code: "0202", Name: i18n.NewString("Indirizzo di Posta Elettronica Certificata (Italy)")},
+	{Code: "0204", Label: "Leitweg-ID", Name: i18n.NewString("Leitweg-ID (Germany)")},
+	{Code: "0208", Label: "KBO-BCE", Name: i18n.NewString("Numero d'entreprise / ondernemingsnummer / Unternehmensnummer (Belgium)"), Check: checkBelgianEnterprise},
+	{Code: "0209", Name: i18n.NewString("GS1 identification keys")},
+	{Code: "0210", Name: i18n.NewString("Codice Fiscale (Italy)")},
+	{Code: "0211", Name: i18n.NewString("Partita IVA (Italy)"), VAT: "IT"},
+	{Code: "0212", Name: i18n.NewString("Finnish Organization Identifier")},
+	{Code: "0213", Name: i18n.NewString("Finnish Organization Value Add Tax Identifier")},
+	{Code: "0215", Name: i18n.NewString("Net service ID (Finland)")},
+	{Code: "0216", Name: i18n.NewString("OVTcode (Finland)")},
+	{Code: "0218", Name: i18n.NewString("Unified registration number (Latvia)"), Check: checkDigits(11)},
+	{Code: "0221", Label: "T-number", Name: i18n.NewString("The registered number of the qualified invoice issuer (Japan)"), Check: checkJapanInvoiceIssuer},
+	{Code: "0230", Name: i18n.NewString("National e-Invoicing Framework (Malaysia)")},
+	{Code: "9910", Name: i18n.NewString("Hungary VAT number"), VAT: "HU"},
+	{Code: "9913", Name: i18n.NewString("Business Registers Network")},
+	{Code: "9914", Name: i18n.NewString("Österreichische Umsatzsteuer-Identifikationsnummer"), VAT: "AT"},
+	{Code: "9915", Name: i18n.NewString("Österreichisches Verwaltungs bzw. Organisationskennzeichen")},
+	{Code: "9918", Name: i18n.NewString("Society for Worldwide Interbank Financial Telecommunication (SWIFT)")},
+	{Code: "9919", Name: i18n.NewString("Kennziffer des Unternehmensregisters")},
+	{Code: "9920", Name: i18n.NewString("Agencia Española de Administración Tributaria"), VAT: "ES"},
+	{Code: "9922", Name: i18n.NewString("Andorra VAT number"), VAT: "AD"},
+	{Code: "9923", Name: i18n.NewString("Albania VAT number"), VAT: "AL"},
+	{Code: "9924", Name: i18n.NewString("Bosnia and Herzegovina VAT number"), VAT: "BA"},
+	{Code: "9925", Name: i18n.NewString("Belgium VAT number"), VAT: "BE"},
+	{Code: "9926", Name: i18n.NewString("Bulgaria VAT number"), VAT: "BG"},
+	{Code: "9927", Name: i18n.NewString("Switzerland VAT number"), VAT: "CH"},
+	{Code: "9928", Name: i18n.NewString("Cyprus VAT number"), VAT: "CY"},
+	{Code: "9929", Name: i18n.NewString("Czech Republic VAT number"), VAT: "CZ"},
+	{Code: "9930", Name: i18n.NewString("Germany VAT number"), VAT: "DE"},
+	{Code: "9931", Name: i18n.NewString("Estonia VAT number"), VAT: "EE"},
+	{Code: "9932", Name: i18n.NewString("United Kingdom VAT number"), VAT: "GB"},
+	{Code: "9933", Name: i18n.NewString("Greece VAT number"), VAT: "GR"},
+	{Code: "9934", Name: i18n.NewString("Croatia VAT number"), VAT: "HR"},
+	{Code: "9935", Name: i18n.NewString("Ireland VAT number"), VAT: "IE"},
+	{Code: "9936", Name: i18n.NewString("Liechtenstein VAT number"), VAT: "LI"},
+	{Code: "9937", Name: i18n.NewString("Lithuania VAT number"), VAT: "LT"},
+	{Code: "9938", Name: i18n.NewString("Luxemburg VAT number"), VAT: "LU"},
+	{Code: "9939", Name: i18n.NewString("Latvia VAT number"), VAT: "LV"},
+	{Code: "9940", Name: i18n.NewString("Monaco VAT number"), VAT: "MC"},
+	{Code: "9941", Name: i18n.NewString("Montenegro VAT number"), VAT: "ME"},
+	{Code: "9942", Name: i18n.NewString("North Macedonia VAT number"), VAT: "MK"},
+	{Code: "9943", Name: i18n.NewString("Malta VAT number"), VAT: "MT"},
+	{Code: "9944", Name: i18n.NewString("Netherlands VAT number"), VAT: "NL"},
+	{Code: "9945", Name: i18n.NewString("Poland VAT number"), VAT: "PL"},
+	{Code: "9946", Name: i18n.NewString("Portugal VAT number"), VAT: "PT"},
+	{Code: "9947", Name: i18n.NewString("Romania VAT number"), VAT: "RO"},
+	{Code: "9948", Name: i18n.NewString("Serbia VAT number"), VAT: "RS"},
+	{Code: "9949", Name: i18n.NewString("Slovenia VAT number"), VAT: "SI"},
+	{Code: "9950", Name: i18n.NewString("Slovakia VAT number"), VAT: "SK"},
+	{Code: "9951", Name: i18n.NewString("San Marino VAT number"), VAT: "SM"},
+	{Code: "9952", Name: i18n.NewString("Turkey VAT number"), VAT: "TR"},
+	{Code: "9953", Name: i18n.NewString("Holy See (Vatican City State) VAT number"), VAT: "VA"},
+	{Code: "9957", Name: i18n.NewString("French VAT number"), VAT: "FR"},
+	{Code: "9959", Label: "EIN", Name: i18n.NewString("Employer Identification Number (EIN, USA)"), Check: checkDigits(9)},
+}
+
+// SchemeDefs provides the list of known scheme definitions.
+func SchemeDefs() []*SchemeDef {
+	return schemeDefs
+}
+
+// SchemeDefFor provides the scheme definition for the code, or nil if
+// the scheme is not known.
+func SchemeDefFor(code cbc.Code) *SchemeDef {
+	for _, sd := range schemeDefs {
+		if sd.Code == code {
+			return sd
+		}
+	}
+	return nil
+}
+
+// VATSchemeFor provides the scheme used to identify parties from the
+// country using their VAT number, or nil if there is none.
+func VATSchemeFor(country l10n.TaxCountryCode) *SchemeDef {
+	for _, sd := range schemeDefs {
+		if sd.VAT == country {
+			return sd
+		}
+	}
+	return nil
+}
+
+// VATCode provides the identifier for the tax code from the scheme's
+// country, which includes the country prefix.
+func (sd *SchemeDef) VATCode(code cbc.Code) cbc.Code {
+	return cbc.Code(sd.VAT.String()) + code
+}
+
+// ValidateCode checks the identifier's code using the scheme's rules, if
+// any. Codes from VAT schemes are not checked as they are used both with
+// and without the country prefix.
+func (sd *SchemeDef) ValidateCode(code cbc.Code) error {
+	if code == cbc.CodeEmpty || sd.Check == nil {
+		return nil
+	}
+	if !sd.Check(code.String()) {
+		if sd.Label != "" {
+			return errors.New("must be a valid " + sd.Label)
+		}
+		return errors.New("must be a valid code for scheme " + sd.Code.String())
+	}
+	return nil
+}
+
+// InSchemeFormat provides a validation rule that checks a code matches
+// the rules of the scheme. Unknown schemes are ignored.
+func InSchemeFormat(scheme cbc.Code) validation.Rule {
+	return schemeCodeRule{scheme: scheme}
+}
+
+type schemeCodeRule struct {
+	scheme cbc.Code
+}
+
+func (r schemeCodeRule) Validate(value any) error {
+	code, ok := value.(cbc.Code)
+	if !ok {
+		return nil
+	}
+	sd := SchemeDefFor(r.scheme)
+	if sd == nil {
+		return nil
+	}
+	return sd.ValidateCode(code)
+}
+
+// ValidSchemeCode provides a validation rule for scheme codes, such as
+// electronic addresses, that checks the scheme is known and the code
+// matches its rules.
+var ValidSchemeCode = validSchemeCodeRule{}
+
+type validSchemeCodeRule struct{}
+
+func (validSchemeCodeRule) Validate(value any) error {
+	sc, ok := value.(cbc.SchemeCode)
+	if !ok || sc.IsEmpty() {
+		return nil
+	}
+	if err := sc.Validate(); err != nil {
+		return err
+	}
+	sd := SchemeDefFor(sc.Scheme())
+	if sd == nil {
+		return fmt.Errorf("scheme '%s' not recognized", sc.Scheme())
+	}
+	return sd.ValidateCode(sc.Code())
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func hasLength(s string, lengths []int) bool {
+	for _, l := range lengths {
+		if len(s) == l {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDigits(lengths ...int) func(string) bool {
+	return func(s string) bool {
+		return hasLength(s, lengths) && isDigits(s)
+	}
+}
+
+// checkLuhn applies the Luhn algorithm used by SIREN, SIRET, and Swedish
+// organisation numbers.
+func checkLuhn(lengths ...int) func(string) bool {
+	return func(s string) bool {
+		if !hasLength(s, lengths) || !isDigits(s) {
+			return false
+		}
+		sum := 0
+		for i := 0; i < len(s); i++ {
+			n := int(s[len(s)-1-i] - '0')
+			if i%2 == 1 {
+				n *= 2
+				if n > 9 {
+					n -= 9
+				}
+			}
+			sum += n
+		}
+		return sum%10 == 0
+	}
+}
+
+// checkGS1 validates the modulo 10 check digit used in GS1 keys such as
+// GLNs and GTINs.
+func checkGS1(lengths ...int) func(string) bool {
+	return func(s string) bool {
+		if !hasLength(s, lengths) || !isDigits(s) {
+			return false
+		}
+		sum := 0
+		for i := 1; i < len(s); i++ {
+			n := int(s[len(s)-1-i] - '0')
+			if i%2 == 1 {
+				n *= 3
+			}
+			sum += n
+		}
+		return int(s[len(s)-1]-'0') == (10-sum%10)%10
+	}
+}
+
+// checkABN uses the modulo 89 check of Australian Business Numbers.
+func checkABN(s string) bool {
+	if len(s) != 11 || !isDigits(s) {
+		return false
+	}
+	weights := []int{10, 1, 3, 5, 7, 9, 11, 13, 15, 17, 19}
+	sum := 0
+	for i, w := range weights {
+		n := int(s[i] - '0')
+		if i == 0 {
+			n--
+		}
+		sum += n * w
+	}
+	return sum%89 == 0
+}
+
+// checkNorwegianOrg uses the modulo 11 check of Norwegian organisation
+// numbers.
+func checkNorwegianOrg(s string) bool {
+	if len(s) != 9 || !isDigits(s) {
+		return false
+	}
+	weights := []int{3, 2, 7, 6, 5, 4, 3, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	return check != 10 && check == int(s[8]-'0')
+}
+
+// checkBelgianEnterprise uses the modulo 97 check of Belgian enterprise
+// numbers.
+func checkBelgianEnterprise(s string) bool {
+	if len(s) != 10 || !isDigits(s) {
+		return false
+	}
+	base := 0
+	for _, c := range s[:8] {
+		base = base*10 + int(c-'0')
+	}
+	check := int(s[8]-'0')*10 + int(s[9]-'0')
+	return 97-base%97 == check
+}
+
+// checkLEI uses the ISO 7064 modulo 97-10 check of Legal Entity
+// Identifiers.
+func checkLEI(s string) bool {
+	if len(s) != 20 {
+		return false
+	}
+	rem := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
+
+// checkJapanInvoiceIssuer expects the "T" prefix followed by the 13 digit
+// corporate number.
+func checkJapanInvoiceIssuer(s string) bool {
+	return len(s) == 14 && s[0] == 'T' && isDigits(s[1:])
+}
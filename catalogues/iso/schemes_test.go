@@ -0,0 +1,100 @@
+package iso_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeDefs(t *testing.T) {
+	codes := make(map[cbc.Code]bool)
+	for _, sd := range iso.SchemeDefs() {
+		assert.Regexp(t, `^\d{4}$`, sd.Code.String())
+		assert.False(t, codes[sd.Code], "duplicate scheme %s", sd.Code)
+		assert.NotEmpty(t, sd.Name)
+		codes[sd.Code] = true
+	}
+}
+
+func TestSchemeDefFor(t *testing.T) {
+	sd := iso.SchemeDefFor("0088")
+	require.NotNil(t, sd)
+	assert.Equal(t, "GLN", sd.Label)
+	assert.Nil(t, iso.SchemeDefFor("0000"))
+}
+
+func TestVATSchemeFor(t *testing.T) {
+	sd := iso.VATSchemeFor("DE")
+	require.NotNil(t, sd)
+	assert.Equal(t, cbc.Code("9930"), sd.Code)
+	assert.Equal(t, cbc.Code("DE111111125"), sd.VATCode("111111125"))
+	assert.Equal(t, cbc.Code("0211"), iso.VATSchemeFor("IT").Code)
+	assert.Nil(t, iso.VATSchemeFor("US"))
+}
+
+func TestSchemeDefValidateCode(t *testing.T) {
+	tests := []struct {
+		scheme cbc.Code
+		code   cbc.Code
+		err    string
+	}{
+		{scheme: "0002", code: "732829320"},
+		{scheme: "0002", code: "73282932000074"},
+		{scheme: "0002", code: "732829321", err: "must be a valid SIRENE"},
+		{scheme: "0007", code: "5560360793"},
+		{scheme: "0009", code: "73282932000074"},
+		{scheme: "0009", code: "732829320", err: "must be a valid SIRET"},
+		{scheme: "0060", code: "123456789"},
+		{scheme: "0060", code: "12345678A", err: "must be a valid DUNS"},
+		{scheme: "0088", code: "5790000435951"},
+		{scheme: "0088", code: "5790000435952", err: "must be a valid GLN"},
+		{scheme: "0151", code: "51824753556"},
+		{scheme: "0151", code: "51824753557", err: "must be a valid ABN"},
+		{scheme: "0160", code: "4006381333931"},
+		{scheme: "0160", code: "96385074"},
+		{scheme: "0192", code: "974760673"},
+		{scheme: "0192", code: "974760674", err: "must be a valid code for scheme 0192"},
+		{scheme: "0199", code: "5493001KJTIIGC8Y1R12"},
+		{scheme: "0199", code: "5493001KJTIIGC8Y1R13", err: "must be a valid LEI"},
+		{scheme: "0208", code: "0897223868"},
+		{scheme: "0208", code: "0897223869", err: "must be a valid KBO-BCE"},
+		{scheme: "0221", code: "T1234567890123"},
+		{scheme: "0221", code: "1234567890123", err: "must be a valid T-number"},
+		{scheme: "9930", code: "DE111111125"},
+		{scheme: "9920", code: "B12345678"},
+		{scheme: "0204", code: "04011000-12345-03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme.String()+":"+tt.code.String(), func(t *testing.T) {
+			sd := iso.SchemeDefFor(tt.scheme)
+			require.NotNil(t, sd)
+			err := sd.ValidateCode(tt.code)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestInSchemeFormat(t *testing.T) {
+	assert.NoError(t, validation.Validate(cbc.Code("5790000435951"), iso.InSchemeFormat("0088")))
+	assert.ErrorContains(t, validation.Validate(cbc.Code("1234"), iso.InSchemeFormat("0088")), "must be a valid GLN")
+	assert.NoError(t, validation.Validate(cbc.Code("1234"), iso.InSchemeFormat("1234")))
+	assert.NoError(t, validation.Validate(cbc.Code("1234"), iso.InSchemeFormat("")))
+	assert.NoError(t, validation.Validate(cbc.CodeEmpty, iso.InSchemeFormat("0088")))
+}
+
+func TestValidSchemeCode(t *testing.T) {
+	assert.NoError(t, validation.Validate(cbc.SchemeCode("0088:5790000435951"), iso.ValidSchemeCode))
+	assert.NoError(t, validation.Validate(cbc.SchemeCode("9920:B12345678"), iso.ValidSchemeCode))
+	assert.NoError(t, validation.Validate(cbc.SchemeCodeEmpty, iso.ValidSchemeCode))
+	assert.ErrorContains(t, validation.Validate(cbc.SchemeCode("0088:1234"), iso.ValidSchemeCode), "must be a valid GLN")
+	assert.ErrorContains(t, validation.Validate(cbc.SchemeCode("1234:5678"), iso.ValidSchemeCode), "scheme '1234' not recognized")
+	assert.ErrorContains(t, validation.Validate(cbc.SchemeCode("5790000435951"), iso.ValidSchemeCode), "missing scheme separator")
+}
@@ -3,11 +3,13 @@ package untdid
 
 import (
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
 )
 
 func init() {
 	tax.RegisterCatalogueDef("untdid.json")
+	pay.RegisterMeansCodes(ExtKeyPaymentMeans, pay.UNTDID4461)
 }
 
 const (
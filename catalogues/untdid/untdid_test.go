@@ -4,8 +4,12 @@ import (
 	"testing"
 
 	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/tax"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInit(t *testing.T) {
@@ -18,3 +22,36 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, "AAS", ed.Code.String())
 	assert.Equal(t, "Acceptance", ed.Name.String())
 }
+
+func TestDocumentTypes(t *testing.T) {
+	ext := tax.ExtensionForKey(untdid.ExtKeyDocumentType)
+	require.NotNil(t, ext)
+	for _, c := range []cbc.Code{"380", "381", "220", "231", "270", "351"} {
+		assert.NotNil(t, ext.CodeDef(c), "missing %s", c)
+	}
+	cd := ext.CodeDef("270")
+	assert.Equal(t, "Delivery note", cd.Name.String())
+	assert.Equal(t, "Albarán", cd.Name.In(i18n.ES))
+}
+
+func TestTranslatedNames(t *testing.T) {
+	tests := []struct {
+		key  cbc.Key
+		code cbc.Code
+		lang i18n.Lang
+		name string
+	}{
+		{untdid.ExtKeyDocumentType, "381", i18n.FR, "Avoir"},
+		{untdid.ExtKeyPaymentMeans, "58", i18n.DE, "SEPA-Überweisung"},
+		{untdid.ExtKeyAllowance, "95", i18n.ES, "Descuento"},
+		{untdid.ExtKeyTaxCategory, "AE", i18n.ES, "Inversión del sujeto pasivo"},
+		{untdid.ExtKeyTaxCategory, "AC", i18n.EN, "Value Added Tax (VAT) not now due for payment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key.String()+"-"+tt.code.String(), func(t *testing.T) {
+			cd := tax.ExtensionForKey(tt.key).CodeDef(tt.code)
+			require.NotNil(t, cd)
+			assert.Equal(t, tt.name, cd.Name.In(tt.lang))
+		})
+	}
+}
@@ -12,6 +12,7 @@ func init() {
 		Definition{},
 		Key(""),
 		Meta{},
+		SchemeCode(""),
 		Source{},
 	)
 }
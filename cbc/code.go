@@ -127,6 +127,12 @@ func (c Code) JoinWith(separator Code, c2 Code) Code {
 	return c + separator + c2
 }
 
+// Qualify provides a scheme code using the provided scheme identifier
+// as a namespace for the current code.
+func (c Code) Qualify(scheme Code) SchemeCode {
+	return NewSchemeCode(scheme, c)
+}
+
 // JSONSchema provides a representation of the struct for usage in Schema.
 func (Code) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
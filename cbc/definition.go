@@ -1,9 +1,8 @@
 package cbc
 
 import (
-	"regexp"
-
 	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/pattern"
 	"github.com/invopop/validation"
 )
 
@@ -160,10 +159,10 @@ func InCodeDefs(list []*Definition) validation.Rule {
 }
 
 func validRegexpPattern(value any) error {
-	pattern, ok := value.(string)
-	if !ok || pattern == "" {
+	p, ok := value.(string)
+	if !ok || p == "" {
 		return nil
 	}
-	_, err := regexp.Compile(pattern)
+	_, err := pattern.Compile(p)
 	return err
 }
@@ -2,8 +2,8 @@ package cbc
 
 import (
 	"errors"
-	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/invopop/jsonschema"
@@ -22,6 +22,16 @@ const (
 	KeyPatternExtensions = `(\+` + KeyPatternWord + `)`
 	KeyPatternWordOnly   = `^` + KeyPatternWord + `$`
 	KeyPatternFull       = `^` + KeyPatternWord + KeyPatternExtensions + `*$`
+
+	// KeyMatchPattern is used to validate keys that may contain wildcards
+	// and alternatives for use with the Match method.
+	KeyMatchPattern = `^[a-z0-9*][a-z0-9-+|*]*$`
+)
+
+// Key pattern symbols used for matching.
+const (
+	KeyPatternWildcard    = "*"
+	KeyPatternAlternative = "|"
 )
 
 var (
@@ -69,7 +79,7 @@ func KeyStrings(keys []Key) []string {
 // With provides a new key that combines another joining them together
 // with a `+` symbol.
 func (k Key) With(ke Key) Key {
-	return Key(fmt.Sprintf("%s%s%s", k, KeySeparator, ke))
+	return Key(string(k) + KeySeparator + string(ke))
 }
 
 // Has returns true if the key contains the provided key.
@@ -97,8 +107,8 @@ func (k Key) Pop() Key {
 // As per `Has`, only the complete key between `+` symbols are
 // matched.
 func (k Key) HasPrefix(ke Key) bool {
-	ks := strings.SplitN(k.String(), KeySeparator, 2)
-	return ks[0] == ke.String()
+	s, _, _ := strings.Cut(k.String(), KeySeparator)
+	return s == ke.String()
 }
 
 // In returns true if the key's value matches one of those
@@ -128,6 +138,95 @@ func AppendUniqueKeys(keys []Key, key ...Key) []Key {
 	return keys
 }
 
+// Match returns true if the key matches the pattern, which may contain
+// wildcards and alternatives to avoid having to list every possible
+// combination of keys. Patterns are compared one `+` separated part at a
+// time and must have the same number of parts as the key. Each part may
+// contain a list of alternatives separated by `|`, and each alternative may
+// contain `*` wildcards that match any characters inside the part.
+//
+// Examples:
+//
+//	Key("standard+reduced").Match("standard+*") => true
+//	Key("export").Match("reverse-charge|export") => true
+//	Key("standard+b2g").Match("standard+b2b|b2g") => true
+//	Key("standard").Match("standard+*") => false
+func (k Key) Match(pattern Key) bool {
+	if !pattern.IsPattern() {
+		return k == pattern
+	}
+	ks := k.String()
+	ps := pattern.String()
+	for {
+		kp, kr, kok := strings.Cut(ks, KeySeparator)
+		pp, pr, pok := strings.Cut(ps, KeySeparator)
+		if !matchKeyPart(kp, pp) {
+			return false
+		}
+		if !kok || !pok {
+			return kok == pok
+		}
+		ks, ps = kr, pr
+	}
+}
+
+// MatchAny returns true if the key matches any of the provided patterns.
+func (k Key) MatchAny(patterns ...Key) bool {
+	for _, p := range patterns {
+		if k.Match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPattern returns true if the key contains wildcards or alternatives
+// and should be used with Match.
+func (k Key) IsPattern() bool {
+	return strings.ContainsAny(k.String(), KeyPatternWildcard+KeyPatternAlternative)
+}
+
+// matchKeyPart checks the key part against each of the pattern's
+// alternatives.
+func matchKeyPart(kp, pp string) bool {
+	for {
+		alt, rest, more := strings.Cut(pp, KeyPatternAlternative)
+		if matchKeyWildcard(kp, alt) {
+			return true
+		}
+		if !more {
+			return false
+		}
+		pp = rest
+	}
+}
+
+// matchKeyWildcard performs a simple glob match where `*` matches any
+// sequence of characters, backtracking only to the last wildcard seen.
+func matchKeyWildcard(s, p string) bool {
+	si, pi := 0, 0
+	star, mark := -1, 0
+	for si < len(s) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case pi < len(p) && p[pi] == s[si]:
+			si++
+			pi++
+		case star >= 0:
+			mark++
+			si, pi = mark, star+1
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
 // HasValidKeyIn provides a validator to check the Key's
 // value is within the provided known set.
 func HasValidKeyIn(keys ...Key) validation.Rule {
@@ -151,6 +250,47 @@ func (r hasKeyRule) Validate(v interface{}) error {
 	return errors.New("must be or start with a valid key")
 }
 
+// InKeyMatchFormat provides a validation rule to check that a key or list
+// of keys are either regular keys or valid match patterns.
+var InKeyMatchFormat validation.Rule = keyMatchRule{}
+
+var keyMatchRegexp = regexp.MustCompile(KeyMatchPattern)
+
+type keyMatchRule struct{}
+
+func (keyMatchRule) Validate(v interface{}) error {
+	switch k := v.(type) {
+	case Key:
+		return k.validateMatch()
+	case []Key:
+		errs := validation.Errors{}
+		for i, kk := range k {
+			if err := kk.validateMatch(); err != nil {
+				errs[strconv.Itoa(i)] = err
+			}
+		}
+		return errs.Filter()
+	}
+	return nil
+}
+
+func (k Key) validateMatch() error {
+	if !k.IsPattern() {
+		return k.Validate()
+	}
+	for _, p := range strings.Split(k.String(), KeySeparator) {
+		for _, alt := range strings.Split(p, KeyPatternAlternative) {
+			if alt == "" || strings.HasPrefix(alt, "-") || strings.HasSuffix(alt, "-") {
+				return errors.New("must be a valid key pattern")
+			}
+		}
+	}
+	return validation.Validate(string(k),
+		validation.Match(keyMatchRegexp).Error("must be a valid key pattern"),
+		validation.Length(int(KeyMinLength), int(KeyMaxLength)),
+	)
+}
+
 // JSONSchema provides a representation of the struct for usage in Schema.
 func (Key) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
@@ -107,6 +107,81 @@ func TestHasValidKeyIn(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestKeyMatch(t *testing.T) {
+	tests := []struct {
+		key     cbc.Key
+		pattern cbc.Key
+		want    bool
+	}{
+		{"standard", "standard", true},
+		{"standard", "reduced", false},
+		{"standard+reduced", "standard+*", true},
+		{"standard", "standard+*", false},
+		{"standard+reduced+eqs", "standard+*", false},
+		{"standard+reduced+eqs", "standard+*+eqs", true},
+		{"export", "reverse-charge|export", true},
+		{"reverse-charge", "reverse-charge|export", true},
+		{"simplified", "reverse-charge|export", false},
+		{"standard+b2g", "standard+b2b|b2g", true},
+		{"standard+b2c", "standard+b2b|b2g", false},
+		{"super-reduced", "*reduced", true},
+		{"reduced", "*reduced", true},
+		{"reduced-eqs", "reduced*", true},
+		{"intermediate", "in*med*te", true},
+		{"intermediate", "in*med*tx", false},
+		{"standard+reduced", "*", false},
+		{"standard+reduced", "*+*", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key.String()+" "+tt.pattern.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key.Match(tt.pattern))
+		})
+	}
+
+	k := cbc.Key("export")
+	assert.True(t, k.MatchAny("standard", "ex*"))
+	assert.False(t, k.MatchAny("standard", "reduced"))
+	assert.False(t, k.MatchAny())
+}
+
+func TestKeyIsPattern(t *testing.T) {
+	assert.False(t, cbc.Key("standard+reduced").IsPattern())
+	assert.True(t, cbc.Key("standard+*").IsPattern())
+	assert.True(t, cbc.Key("b2b|b2g").IsPattern())
+}
+
+func TestInKeyMatchFormat(t *testing.T) {
+	tests := []struct {
+		key cbc.Key
+		err string
+	}{
+		{key: "standard"},
+		{key: "standard+*"},
+		{key: "reverse-charge|export"},
+		{key: "*-reduced+b2b|b2g"},
+		{key: "Standard", err: "must be in a valid format"},
+		{key: "b2b||b2g", err: "must be a valid key pattern"},
+		{key: "standard+*+", err: "must be a valid key pattern"},
+		{key: "-reduced|export", err: "must be a valid key pattern"},
+		{key: "standard+B*", err: "must be a valid key pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key.String(), func(t *testing.T) {
+			err := validation.Validate(tt.key, cbc.InKeyMatchFormat, validation.Skip)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+	t.Run("list", func(t *testing.T) {
+		keys := []cbc.Key{"standard", "b2b||b2g"}
+		err := validation.Validate(keys, cbc.InKeyMatchFormat, validation.Skip)
+		assert.ErrorContains(t, err, "1: must be a valid key pattern")
+	})
+}
+
 func TestKeyJSONSchema(t *testing.T) {
 	data := []byte(`{"description":"Text identifier to be used instead of a code for a more verbose but readable identifier.", "maxLength":64, "minLength":1, "pattern":"^(?:[a-z]|[a-z0-9][a-z0-9-+]*[a-z0-9])$", "title":"Key", "type":"string"}`)
 	k := cbc.Key("standard")
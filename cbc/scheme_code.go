@@ -0,0 +1,132 @@
+package cbc
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/gobl/pkg/here"
+	"github.com/invopop/jsonschema"
+	"github.com/invopop/validation"
+)
+
+// SchemeCode is a code qualified by the identifier of the scheme or
+// namespace it belongs to, in the format `scheme:code`. Scheme identifiers
+// are typically the ISO 6523 ICD or Peppol EAS codes used to describe
+// electronic addresses and party identifiers, such as `0088:5790000435951`
+// for a GLN. The code part follows the same rules as a regular Code, so may
+// itself contain colons.
+type SchemeCode string
+
+// SchemeCodeSeparator is used between the scheme and code.
+const SchemeCodeSeparator = ":"
+
+// SchemeCodeEmpty is used when no scheme code is defined.
+const SchemeCodeEmpty SchemeCode = ""
+
+// Scheme code pattern constants.
+var (
+	SchemeCodeSchemePattern        = `[A-Za-z0-9]{1,16}`
+	SchemeCodePattern              = `^` + SchemeCodeSchemePattern + SchemeCodeSeparator + `[A-Za-z0-9]+([` + CodeSeparators + `]?[A-Za-z0-9]+)*$`
+	SchemeCodePatternRegexp        = regexp.MustCompile(SchemeCodePattern)
+	schemeCodeSchemeRegexp         = regexp.MustCompile(`^` + SchemeCodeSchemePattern + `$`)
+	SchemeCodeMaxLength     uint64 = 16 + 1 + CodeMaxLength
+)
+
+// NewSchemeCode joins the scheme and code into a scheme qualified code. An
+// empty code is returned if either of the parts is empty.
+func NewSchemeCode(scheme, code Code) SchemeCode {
+	if scheme == CodeEmpty || code == CodeEmpty {
+		return SchemeCodeEmpty
+	}
+	return SchemeCode(scheme.String() + SchemeCodeSeparator + code.String())
+}
+
+// ParseSchemeCode splits the string at the first separator into its scheme
+// and code, normalizing each part, and ensures the result is valid.
+func ParseSchemeCode(s string) (SchemeCode, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, SchemeCodeSeparator) {
+		return SchemeCodeEmpty, errors.New("missing scheme separator")
+	}
+	sc := NormalizeSchemeCode(SchemeCode(s))
+	if err := sc.Validate(); err != nil {
+		return SchemeCodeEmpty, err
+	}
+	return sc, nil
+}
+
+// NormalizeSchemeCode cleans the scheme and code parts using the same rules
+// as alphanumerical and regular codes respectively. Values without a
+// separator are only trimmed so that validation can report the problem.
+func NormalizeSchemeCode(sc SchemeCode) SchemeCode {
+	s := strings.TrimSpace(string(sc))
+	scheme, code, ok := strings.Cut(s, SchemeCodeSeparator)
+	if !ok {
+		return SchemeCode(s)
+	}
+	return SchemeCode(
+		NormalizeAlphanumericalCode(Code(scheme)).String() +
+			SchemeCodeSeparator +
+			NormalizeCode(Code(code)).String(),
+	)
+}
+
+// Scheme provides the scheme identifier part of the code.
+func (sc SchemeCode) Scheme() Code {
+	s, _, _ := strings.Cut(string(sc), SchemeCodeSeparator)
+	return Code(s)
+}
+
+// Code provides the code without the scheme identifier.
+func (sc SchemeCode) Code() Code {
+	_, c, _ := strings.Cut(string(sc), SchemeCodeSeparator)
+	return Code(c)
+}
+
+// In returns true if the scheme matches one of those provided.
+func (sc SchemeCode) In(schemes ...Code) bool {
+	return sc.Scheme().In(schemes...)
+}
+
+// IsEmpty returns true if no scheme code is specified.
+func (sc SchemeCode) IsEmpty() bool {
+	return sc == SchemeCodeEmpty
+}
+
+// String returns string representation of the scheme code.
+func (sc SchemeCode) String() string {
+	return string(sc)
+}
+
+// Validate ensures the scheme and code parts comply with the expected rules.
+func (sc SchemeCode) Validate() error {
+	if sc == SchemeCodeEmpty {
+		return nil
+	}
+	if !strings.Contains(string(sc), SchemeCodeSeparator) {
+		return errors.New("missing scheme separator")
+	}
+	return validation.Errors{
+		"scheme": validation.Validate(string(sc.Scheme()),
+			validation.Required,
+			validation.Match(schemeCodeSchemeRegexp),
+		),
+		"code": validation.Validate(sc.Code(), validation.Required),
+	}.Filter()
+}
+
+// JSONSchema provides a representation of the struct for usage in Schema.
+func (SchemeCode) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:      "string",
+		Pattern:   SchemeCodePattern,
+		Title:     "Scheme Code",
+		MaxLength: &SchemeCodeMaxLength,
+		Description: here.Doc(`
+			Code qualified by the identifier of the scheme it belongs to, separated
+			by a colon, such as an ISO 6523 ICD or Peppol EAS code followed by the
+			identifier.
+		`),
+	}
+}
@@ -0,0 +1,94 @@
+package cbc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeCode(t *testing.T) {
+	sc := cbc.NewSchemeCode("0088", "5790000435951")
+	assert.Equal(t, "0088:5790000435951", sc.String())
+	assert.Equal(t, cbc.Code("0088"), sc.Scheme())
+	assert.Equal(t, cbc.Code("5790000435951"), sc.Code())
+	assert.True(t, sc.In("0088", "0060"))
+	assert.False(t, sc.In("9920"))
+	assert.False(t, sc.IsEmpty())
+
+	assert.Equal(t, sc, cbc.Code("5790000435951").Qualify("0088"))
+	assert.True(t, cbc.NewSchemeCode("", "1234").IsEmpty())
+	assert.True(t, cbc.NewSchemeCode("0088", "").IsEmpty())
+
+	t.Run("code with colons", func(t *testing.T) {
+		sc := cbc.SchemeCode("0204:991-1234:5")
+		assert.Equal(t, cbc.Code("0204"), sc.Scheme())
+		assert.Equal(t, cbc.Code("991-1234:5"), sc.Code())
+		assert.NoError(t, sc.Validate())
+	})
+}
+
+func TestParseSchemeCode(t *testing.T) {
+	sc, err := cbc.ParseSchemeCode(" 0088: 5790000435951 ")
+	require.NoError(t, err)
+	assert.Equal(t, cbc.SchemeCode("0088:5790000435951"), sc)
+
+	sc, err = cbc.ParseSchemeCode("iso6523-actorid-upis:9915--B12345678")
+	assert.ErrorContains(t, err, "scheme: must be in a valid format")
+	assert.Empty(t, sc)
+
+	_, err = cbc.ParseSchemeCode("5790000435951")
+	assert.ErrorContains(t, err, "missing scheme separator")
+
+	_, err = cbc.ParseSchemeCode("0088:")
+	assert.ErrorContains(t, err, "code: cannot be blank")
+}
+
+func TestNormalizeSchemeCode(t *testing.T) {
+	assert.Equal(t, cbc.SchemeCode("0088:5790000435951"), cbc.NormalizeSchemeCode(" 0088 : 5790000435951 "))
+	assert.Equal(t, cbc.SchemeCode("9920:ESB12345678"), cbc.NormalizeSchemeCode("9920:ESB12345678"))
+	assert.Equal(t, cbc.SchemeCode("5790000435951"), cbc.NormalizeSchemeCode(" 5790000435951"))
+	assert.Equal(t, cbc.SchemeCodeEmpty, cbc.NormalizeSchemeCode(""))
+}
+
+func TestSchemeCodeValidate(t *testing.T) {
+	tests := []struct {
+		code cbc.SchemeCode
+		err  string
+	}{
+		{code: ""},
+		{code: "0088:5790000435951"},
+		{code: "9920:ESB12345678"},
+		{code: "EM:billing@example.com", err: "code: must be in a valid format"},
+		{code: "0088", err: "missing scheme separator"},
+		{code: ":1234", err: "scheme: cannot be blank"},
+		{code: "00-88:1234", err: "scheme: must be in a valid format"},
+		{code: "0088:12  34", err: "code: must be in a valid format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			err := tt.code.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSchemeCodeJSON(t *testing.T) {
+	var v struct {
+		ID cbc.SchemeCode `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"0088:5790000435951"}`), &v))
+	assert.Equal(t, cbc.Code("0088"), v.ID.Scheme())
+
+	s := cbc.SchemeCode("").JSONSchema()
+	assert.Equal(t, "string", s.Type)
+	assert.Equal(t, cbc.SchemeCodePattern, s.Pattern)
+	assert.Regexp(t, s.Pattern, "0088:5790000435951")
+	assert.NotRegexp(t, s.Pattern, "5790000435951")
+}
@@ -18,6 +18,7 @@ const defaultKeyFilename = "~/.gobl/id_es256.jwk"
 type keygenOpts struct {
 	*rootOpts
 	overwrite bool
+	alg       string
 }
 
 func keygen(root *rootOpts) *keygenOpts {
@@ -37,6 +38,7 @@ func (k *keygenOpts) cmd() *cobra.Command {
 	f := cmd.Flags()
 
 	f.BoolVarP(&k.overwrite, "force", "f", false, "force writing output file, even if it exists")
+	f.StringVar(&k.alg, "alg", "ES256", "signature algorithm of the key: ES256, RS256, PS256, or EdDSA")
 
 	return cmd
 }
@@ -79,7 +81,10 @@ func pubfileFromPriv(priv string) string {
 }
 
 func (k *keygenOpts) runE(cmd *cobra.Command, args []string) error {
-	key := dsig.NewES256Key()
+	key, err := dsig.NewKey(k.alg)
+	if err != nil {
+		return err
+	}
 	marshal := json.Marshal
 	if k.indent {
 		marshal = func(i interface{}) ([]byte, error) {
@@ -50,6 +50,15 @@ func Test_keygen(t *testing.T) {
 		},
 		args: []string{"-"},
 	})
+	tests.Add("eddsa", tt{
+		opts: &keygenOpts{alg: "EdDSA"},
+		args: []string{"-"},
+	})
+	tests.Add("unsupported alg", tt{
+		opts: &keygenOpts{alg: "HS256"},
+		args: []string{"-"},
+		err:  "dsig: unsupported signature algorithm: HS256",
+	})
 	tests.Add("target does not exist", tt{
 		args: []string{"/some/path/that/does/not/exist"},
 		err:  "open /some/path/that/does/not/.exist-.*: no such file or directory",
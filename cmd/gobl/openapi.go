@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/schema"
+	"github.com/spf13/cobra"
+)
+
+type openapiOpts struct {
+	*rootOpts
+}
+
+func openapi(root *rootOpts) *openapiOpts {
+	return &openapiOpts{
+		rootOpts: root,
+	}
+}
+
+func (o *openapiOpts) cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Output an OpenAPI document with GOBL's component schemas",
+		Args:  cobra.NoArgs,
+		RunE:  o.runE,
+	}
+	return cmd
+}
+
+func (o *openapiOpts) runE(cmd *cobra.Command, _ []string) error {
+	comps, err := gobl.OpenAPIComponents()
+	if err != nil {
+		return err
+	}
+	doc := map[string]any{
+		"openapi": schema.OpenAPIVersion,
+		"info": map[string]any{
+			"title":   "GOBL",
+			"version": string(gobl.VERSION),
+		},
+		"components": map[string]any{
+			"schemas": comps,
+		},
+	}
+	return encode(doc, writeCloser{cmd.OutOrStdout()}, o.indent)
+}
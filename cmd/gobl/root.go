@@ -36,6 +36,7 @@ func (o *rootOpts) cmd() *cobra.Command {
 	cmd.AddCommand(versionCmd())
 	cmd.AddCommand(serve().cmd())
 	cmd.AddCommand(keygen(o).cmd())
+	cmd.AddCommand(openapi(o).cmd())
 	return cmd
 }
 
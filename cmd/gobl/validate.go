@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/invopop/gobl/internal/cli"
+	"github.com/invopop/gobl/tax"
 	"github.com/spf13/cobra"
 )
 
@@ -9,8 +10,10 @@ type validateOpts struct {
 	*rootOpts
 
 	// Command options
-	use   string
-	short string
+	use       string
+	short     string
+	failFast  bool
+	maxErrors int
 }
 
 func validate(root *rootOpts) *validateOpts {
@@ -29,6 +32,10 @@ func (opts *validateOpts) cmd() *cobra.Command {
 		Short: opts.short,
 	}
 
+	f := cmd.Flags()
+	f.BoolVar(&opts.failFast, "fail-fast", false, "stop validating at the first error")
+	f.IntVar(&opts.maxErrors, "max-errors", 0, "stop validating after the number of errors")
+
 	return cmd
 }
 
@@ -47,5 +54,12 @@ func (opts *validateOpts) runE(cmd *cobra.Command, args []string) error {
 	}
 	defer out.Close() // nolint:errcheck
 
+	switch {
+	case opts.failFast:
+		ctx = tax.ContextWithFailFast(ctx)
+	case opts.maxErrors > 0:
+		ctx = tax.ContextWithMaxErrors(ctx, opts.maxErrors)
+	}
+
 	return cli.Validate(ctx, input)
 }
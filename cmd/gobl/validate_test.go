@@ -43,6 +43,14 @@ func Test_validate_args(t *testing.T) {
 			name: "in-place short",
 			args: []string{"-w"},
 		},
+		{
+			name: "fail fast",
+			args: []string{"--fail-fast"},
+		},
+		{
+			name: "max errors",
+			args: []string{"--max-errors", "5"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,213 @@
+// Package cfdi converts GOBL invoices with the `mx-cfdi-v4` addon into
+// CFDI 4.0 XML documents, ready to be sealed and stamped by a PAC
+// (Proveedor Autorizado de Certificación), and parses stamped CFDIs back
+// into GOBL envelopes.
+//
+// Extensions defined by the CFDI addon are mapped directly to their
+// attributes: `mx-cfdi-doc-type` to TipoDeComprobante, `mx-cfdi-issue-place`
+// to LugarExpedicion, `mx-cfdi-payment-method` to MetodoPago,
+// `mx-cfdi-payment-means` to FormaPago, `mx-cfdi-rel-type` to TipoRelacion,
+// `mx-cfdi-fiscal-regime` to RegimenFiscal, `mx-cfdi-use` to UsoCFDI, and
+// `mx-cfdi-prod-serv` to ClaveProdServ.
+//
+// Generated documents are not sealed. The issuer is expected to sign the
+// cadena original provided by the document with their CSD (Certificado de
+// Sello Digital) and set the resulting value in the Sello attribute before
+// sending the document to be stamped.
+package cfdi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/observe"
+)
+
+// Namespaces used in CFDI documents.
+const (
+	NamespaceCFDI = "http://www.sat.gob.mx/cfd/4"
+	NamespaceTFD  = "http://www.sat.gob.mx/TimbreFiscalDigital"
+	NamespaceXSI  = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Version of the CFDI documents generated.
+const Version = "4.0"
+
+const schemaLocation = NamespaceCFDI + " http://www.sat.gob.mx/sitio_internet/cfd/4/cfdv40.xsd"
+
+var prefixes = xmlns.Prefixes{
+	NamespaceCFDI: "cfdi",
+	NamespaceTFD:  "tfd",
+}
+
+// Option is used to customize the document generated from an invoice.
+type Option func(*options)
+
+type options struct {
+	certNumber string
+	cert       string
+}
+
+// WithCertificate sets the serial number and base64 encoded contents of
+// the CSD certificate that will be used to seal the document. The serial
+// number is part of the cadena original, so it must be set before
+// signing.
+func WithCertificate(number, cert string) Option {
+	return func(o *options) {
+		o.certNumber = number
+		o.cert = cert
+	}
+}
+
+// Bytes provides the indented XML representation of the document.
+func (d *Document) Bytes() ([]byte, error) {
+	return xmlns.Marshal(d)
+}
+
+// ConvertInvoice is a convenience method to generate the CFDI XML for the
+// calculated invoice.
+func ConvertInvoice(inv *bill.Invoice, opts ...Option) (out []byte, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpExport, "cfdi")
+	defer func() { s.End(inv, err) }()
+	doc, err := FromInvoice(inv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+// Parse decodes the CFDI XML data.
+func Parse(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := xmlns.Unmarshal(data, prefixes, doc); err != nil {
+		return nil, fmt.Errorf("parsing cfdi: %w", err)
+	}
+	if doc.Emisor == nil || doc.Conceptos == nil {
+		return nil, fmt.Errorf("parsing cfdi: missing issuer or concepts")
+	}
+	return doc, nil
+}
+
+// ParseInvoice is a convenience method to parse the CFDI XML data and
+// convert it into a calculated GOBL invoice.
+func ParseInvoice(data []byte) (inv *bill.Invoice, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpImport, "cfdi")
+	defer func() { s.End(inv, err) }()
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToInvoice()
+}
+
+// ParseEnvelope is a convenience method to parse the CFDI XML data and
+// convert it into a GOBL envelope including the SAT stamps.
+func ParseEnvelope(data []byte) (*gobl.Envelope, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToEnvelope()
+}
+
+// CadenaOriginal provides the original string of the document as defined
+// by the SAT's XSLT transformation, which is the input used to generate
+// the document's seal.
+func (d *Document) CadenaOriginal() string {
+	c := new(chain)
+	c.add(d.Version, d.Serie, d.Folio, d.Fecha, d.FormaPago, d.NoCertificado,
+		d.CondicionesDePago, d.SubTotal, d.Descuento, d.Moneda, d.TipoCambio,
+		d.Total, d.TipoDeComprobante, d.Exportacion, d.MetodoPago,
+		d.LugarExpedicion)
+	if ig := d.InformacionGlobal; ig != nil {
+		c.add(ig.Periodicidad, ig.Meses, ig.Anio)
+	}
+	for _, rel := range d.CfdiRelacionados {
+		c.add(rel.TipoRelacion)
+		for _, r := range rel.CfdiRelacionado {
+			c.add(r.UUID)
+		}
+	}
+	if e := d.Emisor; e != nil {
+		c.add(e.Rfc, e.Nombre, e.RegimenFiscal)
+	}
+	if r := d.Receptor; r != nil {
+		c.add(r.Rfc, r.Nombre, r.DomicilioFiscalReceptor, r.ResidenciaFiscal,
+			r.NumRegIdTrib, r.RegimenFiscalReceptor, r.UsoCFDI)
+	}
+	if d.Conceptos != nil {
+		for _, cp := range d.Conceptos.Concepto {
+			c.add(cp.ClaveProdServ, cp.NoIdentificacion, cp.Cantidad,
+				cp.ClaveUnidad, cp.Unidad, cp.Descripcion, cp.ValorUnitario,
+				cp.Importe, cp.Descuento, cp.ObjetoImp)
+			if ci := cp.Impuestos; ci != nil {
+				if ci.Traslados != nil {
+					c.addTaxes(ci.Traslados.Traslado)
+				}
+				if ci.Retenciones != nil {
+					c.addTaxes(ci.Retenciones.Retencion)
+				}
+			}
+		}
+	}
+	if im := d.Impuestos; im != nil {
+		if im.Retenciones != nil {
+			c.addTaxes(im.Retenciones.Retencion)
+		}
+		c.add(im.TotalImpuestosRetenidos)
+		if im.Traslados != nil {
+			c.addTaxes(im.Traslados.Traslado)
+		}
+		c.add(im.TotalImpuestosTrasladados)
+	}
+	return c.String()
+}
+
+// CadenaOriginal provides the original string of the digital stamp, as
+// signed by the SAT.
+func (t *TimbreFiscalDigital) CadenaOriginal() string {
+	c := new(chain)
+	c.add(t.Version, t.UUID, t.FechaTimbrado, t.RfcProvCertif, t.Leyenda,
+		t.SelloCFD, t.NoCertificadoSAT)
+	return c.String()
+}
+
+// chain builds cadena original strings, skipping empty values and
+// normalizing whitespace as the SAT's XSLT does.
+type chain struct {
+	values []string
+}
+
+func (c *chain) add(values ...string) {
+	for _, v := range values {
+		v = strings.Join(strings.Fields(v), " ")
+		if v != "" {
+			c.values = append(c.values, v)
+		}
+	}
+}
+
+func (c *chain) addTaxes(list []*Impuesto) {
+	for _, t := range list {
+		c.add(t.Base, t.Impuesto, t.TipoFactor, t.TasaOCuota, t.Importe)
+	}
+}
+
+func (c *chain) String() string {
+	return "||" + strings.Join(c.values, "|") + "||"
+}
+
+func newDocument() *Document {
+	return &Document{
+		XMLName:        xml.Name{Local: "cfdi:Comprobante"},
+		CFDINS:         NamespaceCFDI,
+		XSINS:          NamespaceXSI,
+		SchemaLocation: schemaLocation,
+		Version:        Version,
+	}
+}
@@ -0,0 +1,297 @@
+package cfdi_test
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/mx/cfdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	convcfdi "github.com/invopop/gobl/convert/cfdi"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/regimes/mx"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUUID = "5B0C6A0E-8D4B-4C56-9F71-3C2E1B1A7D42"
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(cfdi.V4),
+		Series:    "TEST",
+		Code:      "00001",
+		Currency:  "MXN",
+		IssueDate: cal.MakeDate(2023, 7, 10),
+		IssueTime: cal.NewTime(12, 0, 0),
+		Supplier: &org.Party{
+			Name:  "KARLA FUENTE NOLASCO",
+			TaxID: &tax.Identity{Country: "MX", Code: "FUNK671228PH6"},
+			Addresses: []*org.Address{
+				{Code: "01160"},
+			},
+			Ext: tax.Extensions{cfdi.ExtKeyFiscalRegime: "612"},
+		},
+		Customer: &org.Party{
+			Name:  "UNIVERSIDAD ROBOTICA ESPAÑOLA",
+			TaxID: &tax.Identity{Country: "MX", Code: "URE180429TM6"},
+			Addresses: []*org.Address{
+				{Code: "65000"},
+			},
+			Ext: tax.Extensions{
+				cfdi.ExtKeyFiscalRegime: "601",
+				cfdi.ExtKeyUse:          "G01",
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Honorarios de notario",
+					Price: num.NewAmount(123000, 2),
+					Ext:   tax.Extensions{cfdi.ExtKeyProdServ: "80121603"},
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+					{Category: mx.TaxCategoryRVAT, Percent: num.NewPercentage(106667, 6)},
+					{Category: mx.TaxCategoryISR, Percent: num.NewPercentage(100, 3)},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item: &org.Item{
+					Name:  "Copias certificadas",
+					Price: num.NewAmount(10000, 2),
+					Unit:  org.UnitPiece,
+					Ext:   tax.Extensions{cfdi.ExtKeyProdServ: "82121500"},
+				},
+				Discounts: []*bill.LineDiscount{
+					{Percent: num.NewPercentage(10, 2)},
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Key: tax.KeyExempt},
+				},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Terms: &pay.Terms{Notes: "Condiciones de pago"},
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	require.NoError(t, inv.Validate())
+	return inv
+}
+
+func TestFromInvoice(t *testing.T) {
+	t.Run("standard", func(t *testing.T) {
+		inv := testInvoice(t)
+		doc, err := convcfdi.FromInvoice(inv, convcfdi.WithCertificate("30001000000400002434", "MIIF"))
+		require.NoError(t, err)
+		assert.Equal(t, "4.0", doc.Version)
+		assert.Equal(t, "TEST", doc.Serie)
+		assert.Equal(t, "00001", doc.Folio)
+		assert.Equal(t, "2023-07-10T12:00:00", doc.Fecha)
+		assert.Equal(t, "30001000000400002434", doc.NoCertificado)
+		assert.Equal(t, "I", doc.TipoDeComprobante)
+		assert.Equal(t, "PPD", doc.MetodoPago)
+		assert.Equal(t, "99", doc.FormaPago)
+		assert.Equal(t, "01160", doc.LugarExpedicion)
+		assert.Equal(t, "Condiciones de pago", doc.CondicionesDePago)
+		assert.Equal(t, "1430.00", doc.SubTotal)
+		assert.Equal(t, "20.00", doc.Descuento)
+		assert.Equal(t, inv.Totals.Payable.String(), doc.Total)
+
+		assert.Equal(t, "FUNK671228PH6", doc.Emisor.Rfc)
+		assert.Equal(t, "612", doc.Emisor.RegimenFiscal)
+		assert.Equal(t, "URE180429TM6", doc.Receptor.Rfc)
+		assert.Equal(t, "65000", doc.Receptor.DomicilioFiscalReceptor)
+		assert.Equal(t, "G01", doc.Receptor.UsoCFDI)
+
+		cs := doc.Conceptos.Concepto
+		require.Len(t, cs, 2)
+		assert.Equal(t, "80121603", cs[0].ClaveProdServ)
+		assert.Equal(t, "ZZ", cs[0].ClaveUnidad)
+		assert.Equal(t, "02", cs[0].ObjetoImp)
+		tr := cs[0].Impuestos.Traslados.Traslado[0]
+		assert.Equal(t, "002", tr.Impuesto)
+		assert.Equal(t, "0.160000", tr.TasaOCuota)
+		assert.Equal(t, "196.80", tr.Importe)
+		rt := cs[0].Impuestos.Retenciones.Retencion
+		require.Len(t, rt, 2)
+		assert.Equal(t, "0.106667", rt[0].TasaOCuota)
+		assert.Equal(t, "131.20", rt[0].Importe)
+		assert.Equal(t, "001", rt[1].Impuesto)
+		assert.Equal(t, "H87", cs[1].ClaveUnidad)
+		assert.Equal(t, "20.00", cs[1].Descuento)
+		assert.Equal(t, "Exento", cs[1].Impuestos.Traslados.Traslado[0].TipoFactor)
+
+		im := doc.Impuestos
+		assert.Equal(t, "196.80", im.TotalImpuestosTrasladados)
+		assert.Equal(t, "254.20", im.TotalImpuestosRetenidos)
+		require.Len(t, im.Traslados.Traslado, 2)
+		require.Len(t, im.Retenciones.Retencion, 2)
+	})
+	t.Run("foreign customer", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Customer = &org.Party{
+			Name:  "Example Inc.",
+			TaxID: &tax.Identity{Country: "US", Code: "123456789"},
+		}
+		doc, err := convcfdi.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "XEXX010101000", doc.Receptor.Rfc)
+		assert.Equal(t, "USA", doc.Receptor.ResidenciaFiscal)
+		assert.Equal(t, "123456789", doc.Receptor.NumRegIdTrib)
+		assert.Equal(t, "01160", doc.Receptor.DomicilioFiscalReceptor)
+		assert.Equal(t, "S01", doc.Receptor.UsoCFDI)
+	})
+	t.Run("global", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Tags = tax.WithTags(cfdi.TagGlobal)
+		inv.Tax.Ext[cfdi.ExtKeyGlobalPeriod] = "01"
+		inv.Tax.Ext[cfdi.ExtKeyGlobalMonth] = "07"
+		inv.Tax.Ext[cfdi.ExtKeyGlobalYear] = "2023"
+		inv.Lines = inv.Lines[1:]
+		inv.Lines[0].Item.Ref = "T-123"
+		inv.Payment = &bill.PaymentDetails{
+			Advances: []*pay.Advance{
+				{Key: pay.MeansKeyCash, Description: "Pago", Percent: num.NewPercentage(100, 2)},
+			},
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		doc, err := convcfdi.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "PUE", doc.MetodoPago)
+		assert.Equal(t, "01", doc.FormaPago)
+		assert.Equal(t, "2023", doc.InformacionGlobal.Anio)
+		assert.Equal(t, "XAXX010101000", doc.Receptor.Rfc)
+		assert.Equal(t, "PUBLICO EN GENERAL", doc.Receptor.Nombre)
+		assert.Equal(t, "01010101", doc.Conceptos.Concepto[0].ClaveProdServ)
+		assert.Equal(t, "ACT", doc.Conceptos.Concepto[0].ClaveUnidad)
+		assert.Equal(t, "T-123", doc.Conceptos.Concepto[0].NoIdentificacion)
+		assert.Empty(t, doc.Impuestos.TotalImpuestosTrasladados)
+	})
+	t.Run("missing addon", func(t *testing.T) {
+		_, err := convcfdi.FromInvoice(&bill.Invoice{Totals: new(bill.Totals)})
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		_, err := convcfdi.FromInvoice(&bill.Invoice{})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+	})
+}
+
+func TestCadenaOriginal(t *testing.T) {
+	doc, err := convcfdi.FromInvoice(testInvoice(t), convcfdi.WithCertificate("30001000000400002434", "MIIF"))
+	require.NoError(t, err)
+	co := doc.CadenaOriginal()
+	assert.True(t, strings.HasPrefix(co, "||4.0|TEST|00001|2023-07-10T12:00:00|99|30001000000400002434|Condiciones de pago|1430.00|20.00|MXN|"))
+	assert.Contains(t, co, "|FUNK671228PH6|KARLA FUENTE NOLASCO|612|URE180429TM6|UNIVERSIDAD ROBOTICA ESPAÑOLA|65000|601|G01|")
+	assert.Contains(t, co, "|80121603|1|ZZ|Honorarios de notario|1230.00|1230.00|02|1230.00|002|Tasa|0.160000|196.80|")
+	assert.True(t, strings.HasSuffix(co, "|254.20|1230.00|002|Tasa|0.160000|196.80|180.00|002|Exento|196.80||"))
+}
+
+func TestRoundTrip(t *testing.T) {
+	inv := testInvoice(t)
+	data, err := convcfdi.ConvertInvoice(inv)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<cfdi:Comprobante xmlns:cfdi="`+convcfdi.NamespaceCFDI+`"`)
+
+	out, err := convcfdi.ParseInvoice(data)
+	require.NoError(t, err)
+	require.NoError(t, out.Validate())
+	assert.Equal(t, []cbc.Key{cfdi.V4}, out.GetAddons())
+	assert.Equal(t, bill.InvoiceTypeStandard, out.Type)
+	assert.Equal(t, inv.IssueDate, out.IssueDate)
+	assert.Equal(t, cbc.Code("01160"), out.Tax.Ext.Get(cfdi.ExtKeyIssuePlace))
+	assert.Equal(t, cbc.Code("612"), out.Supplier.Ext.Get(cfdi.ExtKeyFiscalRegime))
+	assert.Equal(t, cbc.Code("65000"), out.Customer.Addresses[0].Code)
+	assert.Equal(t, "10.6667%", out.Lines[0].Taxes[1].Percent.String())
+	assert.Equal(t, "Condiciones de pago", out.Payment.Terms.Notes)
+
+	assert.Equal(t, inv.Totals.Sum.String(), out.Totals.Sum.String())
+	assert.Equal(t, inv.Totals.Tax.String(), out.Totals.Tax.String())
+	assert.Equal(t, inv.Totals.RetainedTax.String(), out.Totals.RetainedTax.String())
+	assert.Equal(t, inv.Totals.Payable.String(), out.Totals.Payable.String())
+}
+
+func TestParseEnvelope(t *testing.T) {
+	inv := testInvoice(t)
+	inv.Type = bill.InvoiceTypeCreditNote
+	inv.Preceding = []*org.DocumentRef{
+		{
+			Code:   "TEST-00000",
+			Stamps: []*head.Stamp{{Provider: mx.StampSATUUID, Value: "1FAC3A5B-4C2E-4E0B-9F1D-2B7A6C5D4E3F"}},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	doc, err := convcfdi.FromInvoice(inv, convcfdi.WithCertificate("30001000000400002434", "MIIF"))
+	require.NoError(t, err)
+	doc.Sello = "c2VsbG8gZGUgcHJ1ZWJhIGNmZGk="
+	doc.Complemento = &convcfdi.Complemento{
+		TimbreFiscalDigital: &convcfdi.TimbreFiscalDigital{
+			TFDNS:            convcfdi.NamespaceTFD,
+			Version:          "1.1",
+			UUID:             testUUID,
+			FechaTimbrado:    "2023-07-10T12:00:05",
+			RfcProvCertif:    "SPR190613I52",
+			SelloCFD:         doc.Sello,
+			NoCertificadoSAT: "30001000000400002495",
+			SelloSAT:         "c2VsbG8gc2F0",
+		},
+	}
+	data, err := doc.Bytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<tfd:TimbreFiscalDigital xmlns:tfd="`+convcfdi.NamespaceTFD+`"`)
+
+	env, err := convcfdi.ParseEnvelope(data)
+	require.NoError(t, err)
+	require.NoError(t, env.Sign(dsig.NewES256Key()))
+	require.NoError(t, env.Validate())
+	assert.Equal(t, doc.Sello, env.Head.Stamp(cfdi.StampSignature).Value)
+	assert.Equal(t, "30001000000400002434", env.Head.Stamp(cfdi.StampSerial).Value)
+	assert.Equal(t, testUUID, env.Head.Stamp(mx.StampSATUUID).Value)
+	assert.Equal(t, "SPR190613I52", env.Head.Stamp(mx.StampSATProviderRFC).Value)
+	assert.Equal(t,
+		"||1.1|"+testUUID+"|2023-07-10T12:00:05|SPR190613I52|c2VsbG8gZGUgcHJ1ZWJhIGNmZGk=|30001000000400002495||",
+		env.Head.Stamp(mx.StampSATChain).Value,
+	)
+	assert.Contains(t, env.Head.Stamp(mx.StampSATURL).Value, "id="+testUUID)
+	assert.Contains(t, env.Head.Stamp(mx.StampSATURL).Value, "fe=IGNmZGk%3D")
+
+	out, ok := env.Extract().(*bill.Invoice)
+	require.True(t, ok)
+	assert.Equal(t, bill.InvoiceTypeCreditNote, out.Type)
+	assert.Equal(t, cbc.Code("01"), out.Tax.Ext.Get(cfdi.ExtKeyRelType))
+	require.Len(t, out.Preceding, 1)
+	assert.Equal(t, "1FAC3A5B-4C2E-4E0B-9F1D-2B7A6C5D4E3F", out.Preceding[0].Stamps[0].Value)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := convcfdi.Parse([]byte(`<cfdi:Comprobante xmlns:cfdi="` + convcfdi.NamespaceCFDI + `" Version="4.0"></cfdi:Comprobante>`))
+		assert.ErrorContains(t, err, "parsing cfdi: missing issuer or concepts")
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		data, err := convcfdi.ConvertInvoice(testInvoice(t))
+		require.NoError(t, err)
+		doc, err := convcfdi.Parse(data)
+		require.NoError(t, err)
+		doc.TipoDeComprobante = "P"
+		_, err = doc.ToInvoice()
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+}
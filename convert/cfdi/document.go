@@ -0,0 +1,149 @@
+package cfdi
+
+import "encoding/xml"
+
+// Document is the CFDI Comprobante root element.
+type Document struct {
+	XMLName        xml.Name `xml:"cfdi:Comprobante"`
+	CFDINS         string   `xml:"xmlns:cfdi,attr,omitempty"`
+	XSINS          string   `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr,omitempty"`
+
+	Version           string `xml:"Version,attr"`
+	Serie             string `xml:"Serie,attr,omitempty"`
+	Folio             string `xml:"Folio,attr,omitempty"`
+	Fecha             string `xml:"Fecha,attr"`
+	Sello             string `xml:"Sello,attr"`
+	FormaPago         string `xml:"FormaPago,attr,omitempty"`
+	NoCertificado     string `xml:"NoCertificado,attr"`
+	Certificado       string `xml:"Certificado,attr"`
+	CondicionesDePago string `xml:"CondicionesDePago,attr,omitempty"`
+	SubTotal          string `xml:"SubTotal,attr"`
+	Descuento         string `xml:"Descuento,attr,omitempty"`
+	Moneda            string `xml:"Moneda,attr"`
+	TipoCambio        string `xml:"TipoCambio,attr,omitempty"`
+	Total             string `xml:"Total,attr"`
+	TipoDeComprobante string `xml:"TipoDeComprobante,attr"`
+	Exportacion       string `xml:"Exportacion,attr"`
+	MetodoPago        string `xml:"MetodoPago,attr,omitempty"`
+	LugarExpedicion   string `xml:"LugarExpedicion,attr"`
+
+	InformacionGlobal *InformacionGlobal  `xml:"cfdi:InformacionGlobal,omitempty"`
+	CfdiRelacionados  []*CfdiRelacionados `xml:"cfdi:CfdiRelacionados,omitempty"`
+	Emisor            *Emisor             `xml:"cfdi:Emisor"`
+	Receptor          *Receptor           `xml:"cfdi:Receptor"`
+	Conceptos         *Conceptos          `xml:"cfdi:Conceptos"`
+	Impuestos         *Impuestos          `xml:"cfdi:Impuestos,omitempty"`
+	Complemento       *Complemento        `xml:"cfdi:Complemento,omitempty"`
+}
+
+// InformacionGlobal describes the period covered by a global invoice.
+type InformacionGlobal struct {
+	Periodicidad string `xml:"Periodicidad,attr"`
+	Meses        string `xml:"Meses,attr"`
+	Anio         string `xml:"Año,attr"`
+}
+
+// CfdiRelacionados lists the UUIDs of related CFDIs.
+type CfdiRelacionados struct {
+	TipoRelacion    string             `xml:"TipoRelacion,attr"`
+	CfdiRelacionado []*CfdiRelacionado `xml:"cfdi:CfdiRelacionado"`
+}
+
+// CfdiRelacionado is a reference to another CFDI.
+type CfdiRelacionado struct {
+	UUID string `xml:"UUID,attr"`
+}
+
+// Emisor is the issuer of the document.
+type Emisor struct {
+	Rfc           string `xml:"Rfc,attr"`
+	Nombre        string `xml:"Nombre,attr"`
+	RegimenFiscal string `xml:"RegimenFiscal,attr"`
+}
+
+// Receptor is the recipient of the document.
+type Receptor struct {
+	Rfc                     string `xml:"Rfc,attr"`
+	Nombre                  string `xml:"Nombre,attr"`
+	DomicilioFiscalReceptor string `xml:"DomicilioFiscalReceptor,attr"`
+	ResidenciaFiscal        string `xml:"ResidenciaFiscal,attr,omitempty"`
+	NumRegIdTrib            string `xml:"NumRegIdTrib,attr,omitempty"`
+	RegimenFiscalReceptor   string `xml:"RegimenFiscalReceptor,attr"`
+	UsoCFDI                 string `xml:"UsoCFDI,attr"`
+}
+
+// Conceptos contains the lines of the document.
+type Conceptos struct {
+	Concepto []*Concepto `xml:"cfdi:Concepto"`
+}
+
+// Concepto is a single line.
+type Concepto struct {
+	ClaveProdServ    string `xml:"ClaveProdServ,attr"`
+	NoIdentificacion string `xml:"NoIdentificacion,attr,omitempty"`
+	Cantidad         string `xml:"Cantidad,attr"`
+	ClaveUnidad      string `xml:"ClaveUnidad,attr"`
+	Unidad           string `xml:"Unidad,attr,omitempty"`
+	Descripcion      string `xml:"Descripcion,attr"`
+	ValorUnitario    string `xml:"ValorUnitario,attr"`
+	Importe          string `xml:"Importe,attr"`
+	Descuento        string `xml:"Descuento,attr,omitempty"`
+	ObjetoImp        string `xml:"ObjetoImp,attr"`
+
+	Impuestos *ConceptoImpuestos `xml:"cfdi:Impuestos,omitempty"`
+}
+
+// ConceptoImpuestos contains the taxes applied to a line.
+type ConceptoImpuestos struct {
+	Traslados   *Traslados   `xml:"cfdi:Traslados,omitempty"`
+	Retenciones *Retenciones `xml:"cfdi:Retenciones,omitempty"`
+}
+
+// Traslados lists transferred taxes.
+type Traslados struct {
+	Traslado []*Impuesto `xml:"cfdi:Traslado"`
+}
+
+// Retenciones lists retained taxes.
+type Retenciones struct {
+	Retencion []*Impuesto `xml:"cfdi:Retencion"`
+}
+
+// Impuesto is a transferred or retained tax. Document level retentions
+// only include the tax type and amount.
+type Impuesto struct {
+	Base       string `xml:"Base,attr,omitempty"`
+	Impuesto   string `xml:"Impuesto,attr"`
+	TipoFactor string `xml:"TipoFactor,attr,omitempty"`
+	TasaOCuota string `xml:"TasaOCuota,attr,omitempty"`
+	Importe    string `xml:"Importe,attr,omitempty"`
+}
+
+// Impuestos contains the document tax summary.
+type Impuestos struct {
+	TotalImpuestosRetenidos   string `xml:"TotalImpuestosRetenidos,attr,omitempty"`
+	TotalImpuestosTrasladados string `xml:"TotalImpuestosTrasladados,attr,omitempty"`
+
+	Retenciones *Retenciones `xml:"cfdi:Retenciones,omitempty"`
+	Traslados   *Traslados   `xml:"cfdi:Traslados,omitempty"`
+}
+
+// Complemento contains the complements added to the document, of which
+// only the digital stamp is supported.
+type Complemento struct {
+	TimbreFiscalDigital *TimbreFiscalDigital `xml:"tfd:TimbreFiscalDigital,omitempty"`
+}
+
+// TimbreFiscalDigital is the stamp added by the certification provider.
+type TimbreFiscalDigital struct {
+	TFDNS            string `xml:"xmlns:tfd,attr,omitempty"`
+	Version          string `xml:"Version,attr"`
+	UUID             string `xml:"UUID,attr"`
+	FechaTimbrado    string `xml:"FechaTimbrado,attr"`
+	RfcProvCertif    string `xml:"RfcProvCertif,attr"`
+	Leyenda          string `xml:"Leyenda,attr,omitempty"`
+	SelloCFD         string `xml:"SelloCFD,attr"`
+	NoCertificadoSAT string `xml:"NoCertificadoSAT,attr"`
+	SelloSAT         string `xml:"SelloSAT,attr"`
+}
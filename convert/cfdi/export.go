@@ -0,0 +1,328 @@
+package cfdi
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/addons/mx/cfdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/mx"
+	"github.com/invopop/gobl/tax"
+)
+
+// Codes with special meaning in CFDI documents.
+const (
+	exportNotApplicable = "01"
+	objectNotTaxable    = "01"
+	objectTaxable       = "02"
+	factorRate          = "Tasa"
+	factorExempt        = "Exento"
+	paymentMeansUndef   = "99"
+	regimeNoObligations = "616"
+	useNoEffects        = "S01"
+	genericName         = "PUBLICO EN GENERAL"
+	globalProdServ      = "01010101"
+	globalUnit          = "ACT"
+	globalDescription   = "Venta"
+	defaultUnit         = "ZZ"
+	factorExp           = 6
+)
+
+// taxCodes maps the tax categories to the SAT's Impuesto codes.
+var taxCodes = map[cbc.Code]string{
+	mx.TaxCategoryISR:   "001",
+	tax.CategoryVAT:     "002",
+	mx.TaxCategoryRVAT:  "002",
+	mx.TaxCategoryIEPS:  "003",
+	mx.TaxCategoryRIEPS: "003",
+}
+
+// retainedCategories are those included as Retenciones.
+var retainedCategories = []cbc.Code{
+	mx.TaxCategoryISR,
+	mx.TaxCategoryRVAT,
+	mx.TaxCategoryRIEPS,
+}
+
+// FromInvoice converts the calculated GOBL invoice into a CFDI document.
+func FromInvoice(inv *bill.Invoice, opts ...Option) (*Document, error) {
+	if inv == nil || inv.Totals == nil {
+		return nil, convert.ErrNotCalculated
+	}
+	if inv.Tax == nil || inv.Tax.Ext.Get(cfdi.ExtKeyDocType) == cbc.CodeEmpty {
+		return nil, fmt.Errorf("%w: missing %s addon extensions", convert.ErrUnsupported, cfdi.V4)
+	}
+	if inv.Tax.PricesInclude != cbc.CodeEmpty {
+		return nil, fmt.Errorf("%w: prices including tax, remove them first", convert.ErrUnsupported)
+	}
+	if inv.Supplier == nil || inv.Supplier.TaxID == nil {
+		return nil, fmt.Errorf("%w: supplier tax ID required", convert.ErrUnsupported)
+	}
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	global := inv.HasTags(cfdi.TagGlobal)
+	ext := inv.Tax.Ext
+	doc := newDocument()
+	doc.Serie = inv.Series.String()
+	doc.Folio = inv.Code.String()
+	doc.Fecha = issueDateTime(inv)
+	doc.NoCertificado = o.certNumber
+	doc.Certificado = o.cert
+	doc.Moneda = inv.Currency.String()
+	doc.TipoCambio = exchangeRate(inv)
+	doc.TipoDeComprobante = ext.Get(cfdi.ExtKeyDocType).String()
+	doc.Exportacion = exportNotApplicable
+	doc.MetodoPago = ext.Get(cfdi.ExtKeyPaymentMethod).String()
+	doc.FormaPago = paymentMeans(inv)
+	doc.LugarExpedicion = ext.Get(cfdi.ExtKeyIssuePlace).String()
+	if inv.Payment != nil && inv.Payment.Terms != nil {
+		doc.CondicionesDePago = inv.Payment.Terms.Notes
+	}
+	if ext.Has(cfdi.ExtKeyGlobalPeriod) {
+		doc.InformacionGlobal = &InformacionGlobal{
+			Periodicidad: ext.Get(cfdi.ExtKeyGlobalPeriod).String(),
+			Meses:        ext.Get(cfdi.ExtKeyGlobalMonth).String(),
+			Anio:         ext.Get(cfdi.ExtKeyGlobalYear).String(),
+		}
+	}
+	doc.CfdiRelacionados = newRelated(inv.Preceding, ext.Get(cfdi.ExtKeyRelType))
+	doc.Emisor = &Emisor{
+		Rfc:           inv.Supplier.TaxID.Code.String(),
+		Nombre:        inv.Supplier.Name,
+		RegimenFiscal: inv.Supplier.Ext.Get(cfdi.ExtKeyFiscalRegime).String(),
+	}
+	doc.Receptor = newReceptor(inv.Customer, doc.LugarExpedicion)
+
+	sum := inv.Currency.Def().Zero()
+	discount := sum
+	doc.Conceptos = new(Conceptos)
+	for _, l := range inv.Lines {
+		c := newConcepto(l, global)
+		doc.Conceptos.Concepto = append(doc.Conceptos.Concepto, c)
+		sum = sum.Add(*l.Sum)
+		discount = discount.Add(l.Sum.Subtract(*l.Total))
+	}
+	doc.SubTotal = sum.String()
+	if !discount.IsZero() {
+		doc.Descuento = discount.String()
+	}
+	doc.Impuestos = newImpuestos(inv.Totals)
+	doc.Total = inv.Totals.Payable.String()
+	return doc, nil
+}
+
+func issueDateTime(inv *bill.Invoice) string {
+	t := "00:00:00"
+	if inv.IssueTime != nil {
+		t = fmt.Sprintf("%02d:%02d:%02d", inv.IssueTime.Hour, inv.IssueTime.Minute, inv.IssueTime.Second)
+	}
+	return inv.IssueDate.String() + "T" + t
+}
+
+func exchangeRate(inv *bill.Invoice) string {
+	if inv.Currency == currency.MXN {
+		return ""
+	}
+	for _, r := range inv.ExchangeRates {
+		if r.From == inv.Currency && r.To == currency.MXN {
+			return r.Amount.String()
+		}
+	}
+	return ""
+}
+
+// paymentMeans determines the FormaPago from the advances or instructions,
+// which is always undefined for payments in installments.
+func paymentMeans(inv *bill.Invoice) string {
+	if inv.Tax.Ext.Get(cfdi.ExtKeyPaymentMethod) == cfdi.ExtCodePaymentMethodPPD || inv.Payment == nil {
+		return paymentMeansUndef
+	}
+	if len(inv.Payment.Advances) > 0 {
+		if code := inv.Payment.Advances[0].Ext.Get(cfdi.ExtKeyPaymentMeans); code != cbc.CodeEmpty {
+			return code.String()
+		}
+	}
+	if inv.Payment.Instructions != nil {
+		if code := inv.Payment.Instructions.Ext.Get(cfdi.ExtKeyPaymentMeans); code != cbc.CodeEmpty {
+			return code.String()
+		}
+	}
+	return paymentMeansUndef
+}
+
+func newRelated(refs []*org.DocumentRef, relType cbc.Code) []*CfdiRelacionados {
+	if len(refs) == 0 {
+		return nil
+	}
+	rel := &CfdiRelacionados{TipoRelacion: relType.String()}
+	for _, ref := range refs {
+		if s := head.GetStamp(ref.Stamps, mx.StampSATUUID); s != nil {
+			rel.CfdiRelacionado = append(rel.CfdiRelacionado, &CfdiRelacionado{UUID: s.Value})
+		}
+	}
+	return []*CfdiRelacionados{rel}
+}
+
+// newReceptor prepares the recipient, using the generic public or foreign
+// identities when the customer is missing or not Mexican.
+func newReceptor(p *org.Party, issuePlace string) *Receptor {
+	if p == nil || p.TaxID == nil {
+		return &Receptor{
+			Rfc:                     mx.TaxIdentityCodeGeneric.String(),
+			Nombre:                  genericName,
+			DomicilioFiscalReceptor: issuePlace,
+			RegimenFiscalReceptor:   regimeNoObligations,
+			UsoCFDI:                 useNoEffects,
+		}
+	}
+	if p.TaxID.Country != l10n.MX.Tax() {
+		return &Receptor{
+			Rfc:                     mx.TaxIdentityCodeForeign.String(),
+			Nombre:                  p.Name,
+			DomicilioFiscalReceptor: issuePlace,
+			ResidenciaFiscal:        p.TaxID.Country.Code().ISO().Alpha3(),
+			NumRegIdTrib:            p.TaxID.Code.String(),
+			RegimenFiscalReceptor:   regimeNoObligations,
+			UsoCFDI:                 useNoEffects,
+		}
+	}
+	r := &Receptor{
+		Rfc:                   p.TaxID.Code.String(),
+		Nombre:                p.Name,
+		RegimenFiscalReceptor: p.Ext.Get(cfdi.ExtKeyFiscalRegime).String(),
+		UsoCFDI:               p.Ext.Get(cfdi.ExtKeyUse).String(),
+	}
+	if len(p.Addresses) > 0 {
+		r.DomicilioFiscalReceptor = p.Addresses[0].Code.String()
+	}
+	return r
+}
+
+func newConcepto(l *bill.Line, global bool) *Concepto {
+	c := &Concepto{
+		ClaveProdServ:    l.Item.Ext.Get(cfdi.ExtKeyProdServ).String(),
+		NoIdentificacion: l.Item.Ref.String(),
+		Cantidad:         l.Quantity.String(),
+		ClaveUnidad:      defaultUnit,
+		Descripcion:      l.Item.Name,
+		ValorUnitario:    l.Item.Price.String(),
+		Importe:          l.Sum.String(),
+		ObjetoImp:        objectNotTaxable,
+	}
+	if code := l.Item.Unit.UNECE(); code != cbc.CodeEmpty {
+		c.ClaveUnidad = code.String()
+	}
+	if global {
+		c.ClaveProdServ = globalProdServ
+		c.ClaveUnidad = globalUnit
+		c.Descripcion = globalDescription
+	}
+	if d := l.Sum.Subtract(*l.Total); !d.IsZero() {
+		c.Descuento = d.String()
+	}
+	if len(l.Taxes) == 0 {
+		return c
+	}
+	c.ObjetoImp = objectTaxable
+	ci := new(ConceptoImpuestos)
+	for _, combo := range l.Taxes {
+		t := newLineImpuesto(combo, *l.Total)
+		if t == nil {
+			continue
+		}
+		if combo.Category.In(retainedCategories...) {
+			if ci.Retenciones == nil {
+				ci.Retenciones = new(Retenciones)
+			}
+			ci.Retenciones.Retencion = append(ci.Retenciones.Retencion, t)
+		} else {
+			if ci.Traslados == nil {
+				ci.Traslados = new(Traslados)
+			}
+			ci.Traslados.Traslado = append(ci.Traslados.Traslado, t)
+		}
+	}
+	c.Impuestos = ci
+	return c
+}
+
+func newLineImpuesto(combo *tax.Combo, base num.Amount) *Impuesto {
+	code, ok := taxCodes[combo.Category]
+	if !ok {
+		return nil
+	}
+	t := &Impuesto{
+		Base:     base.String(),
+		Impuesto: code,
+	}
+	if combo.Percent == nil {
+		t.TipoFactor = factorExempt
+		return t
+	}
+	t.TipoFactor = factorRate
+	t.TasaOCuota = combo.Percent.Base().Rescale(factorExp).String()
+	t.Importe = combo.Percent.Of(base).Rescale(base.Exp()).String()
+	return t
+}
+
+// newImpuestos summarizes the taxes from the invoice totals, grouping
+// retentions by tax type.
+func newImpuestos(totals *bill.Totals) *Impuestos {
+	if totals.Taxes == nil || len(totals.Taxes.Categories) == 0 {
+		return nil
+	}
+	im := new(Impuestos)
+	rated := false
+	for _, ct := range totals.Taxes.Categories {
+		code, ok := taxCodes[ct.Code]
+		if !ok {
+			continue
+		}
+		if ct.Code.In(retainedCategories...) {
+			if im.Retenciones == nil {
+				im.Retenciones = new(Retenciones)
+			}
+			im.Retenciones.Retencion = append(im.Retenciones.Retencion, &Impuesto{
+				Impuesto: code,
+				Importe:  ct.Amount.String(),
+			})
+			continue
+		}
+		for _, rt := range ct.Rates {
+			t := &Impuesto{
+				Base:       rt.Base.String(),
+				Impuesto:   code,
+				TipoFactor: factorExempt,
+			}
+			if rt.Percent != nil {
+				rated = true
+				t.TipoFactor = factorRate
+				t.TasaOCuota = rt.Percent.Base().Rescale(factorExp).String()
+				t.Importe = rt.Amount.String()
+			}
+			if im.Traslados == nil {
+				im.Traslados = new(Traslados)
+			}
+			im.Traslados.Traslado = append(im.Traslados.Traslado, t)
+		}
+	}
+	if im.Retenciones != nil && totals.RetainedTax != nil {
+		im.TotalImpuestosRetenidos = totals.RetainedTax.String()
+	}
+	if rated {
+		im.TotalImpuestosTrasladados = totals.Tax.String()
+	}
+	if im.Retenciones == nil && im.Traslados == nil {
+		return nil
+	}
+	return im
+}
@@ -0,0 +1,319 @@
+package cfdi
+
+import (
+	"fmt"
+	"net/url"
+
+	"cloud.google.com/go/civil"
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/mx/cfdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/regimes/mx"
+	"github.com/invopop/gobl/tax"
+)
+
+const (
+	verifyURL          = "https://verificacfdi.facturaelectronica.sat.gob.mx/default.aspx"
+	singlePaymentDesc  = "Pago en una sola exhibición"
+	sealSuffixLength   = 8
+	percentMinimumExp  = 2
+	docTypeIncome      = "I"
+	docTypeExpenditure = "E"
+)
+
+// ToInvoice converts the CFDI document into a calculated GOBL invoice.
+// Only income and expenditure documents are supported.
+func (d *Document) ToInvoice() (*bill.Invoice, error) {
+	inv := &bill.Invoice{
+		Addons:   tax.WithAddons(cfdi.V4),
+		Series:   cbc.Code(d.Serie),
+		Code:     cbc.Code(d.Folio),
+		Currency: currency.Code(d.Moneda),
+		Tax: &bill.Tax{
+			Ext: tax.Extensions{
+				cfdi.ExtKeyDocType:       cbc.Code(d.TipoDeComprobante),
+				cfdi.ExtKeyIssuePlace:    cbc.Code(d.LugarExpedicion),
+				cfdi.ExtKeyPaymentMethod: cbc.Code(d.MetodoPago),
+			},
+		},
+	}
+	switch d.TipoDeComprobante {
+	case docTypeIncome:
+		inv.Type = bill.InvoiceTypeStandard
+	case docTypeExpenditure:
+		inv.Type = bill.InvoiceTypeCreditNote
+	default:
+		return nil, fmt.Errorf("%w: document type '%s'", convert.ErrUnsupported, d.TipoDeComprobante)
+	}
+	if err := d.parseIssueDateTime(inv); err != nil {
+		return nil, err
+	}
+	if d.TipoCambio != "" && inv.Currency != currency.MXN {
+		rate, err := convert.ParseAmount(d.TipoCambio)
+		if err != nil {
+			return nil, err
+		}
+		inv.ExchangeRates = []*currency.ExchangeRate{
+			{From: inv.Currency, To: currency.MXN, Amount: rate},
+		}
+	}
+	if ig := d.InformacionGlobal; ig != nil {
+		inv.Tags = tax.WithTags(cfdi.TagGlobal)
+		inv.Tax.Ext[cfdi.ExtKeyGlobalPeriod] = cbc.Code(ig.Periodicidad)
+		inv.Tax.Ext[cfdi.ExtKeyGlobalMonth] = cbc.Code(ig.Meses)
+		inv.Tax.Ext[cfdi.ExtKeyGlobalYear] = cbc.Code(ig.Anio)
+	}
+	for _, rel := range d.CfdiRelacionados {
+		inv.Tax.Ext[cfdi.ExtKeyRelType] = cbc.Code(rel.TipoRelacion)
+		for _, r := range rel.CfdiRelacionado {
+			inv.Preceding = append(inv.Preceding, &org.DocumentRef{
+				Code:   cbc.Code(r.UUID),
+				Stamps: []*head.Stamp{{Provider: mx.StampSATUUID, Value: r.UUID}},
+			})
+		}
+	}
+
+	inv.Supplier = &org.Party{
+		Name:  d.Emisor.Nombre,
+		TaxID: &tax.Identity{Country: l10n.MX.Tax(), Code: cbc.Code(d.Emisor.Rfc)},
+		Ext:   tax.Extensions{cfdi.ExtKeyFiscalRegime: cbc.Code(d.Emisor.RegimenFiscal)},
+	}
+	if d.InformacionGlobal == nil {
+		inv.Customer = parseReceptor(d.Receptor)
+	}
+
+	for _, c := range d.Conceptos.Concepto {
+		line, err := parseConcepto(c)
+		if err != nil {
+			return nil, err
+		}
+		inv.Lines = append(inv.Lines, line)
+	}
+	if err := d.parsePayment(inv); err != nil {
+		return nil, err
+	}
+
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ToEnvelope converts the CFDI document into a GOBL envelope containing
+// the invoice, with stamps for the issuer's seal and, when present, the
+// details of the SAT's digital stamp. The envelope will need to be signed
+// before it can be validated, as stamps are only accepted in signed
+// envelopes.
+func (d *Document) ToEnvelope() (*gobl.Envelope, error) {
+	inv, err := d.ToInvoice()
+	if err != nil {
+		return nil, err
+	}
+	env, err := gobl.Envelop(inv)
+	if err != nil {
+		return nil, err
+	}
+	addStamp(env.Head, cfdi.StampSignature, d.Sello)
+	addStamp(env.Head, cfdi.StampSerial, d.NoCertificado)
+	if d.Complemento == nil || d.Complemento.TimbreFiscalDigital == nil {
+		return env, nil
+	}
+	tfd := d.Complemento.TimbreFiscalDigital
+	addStamp(env.Head, mx.StampSATUUID, tfd.UUID)
+	addStamp(env.Head, mx.StampSATTimestamp, tfd.FechaTimbrado)
+	addStamp(env.Head, mx.StampSATProviderRFC, tfd.RfcProvCertif)
+	addStamp(env.Head, mx.StampSATSignature, tfd.SelloSAT)
+	addStamp(env.Head, mx.StampSATSerial, tfd.NoCertificadoSAT)
+	addStamp(env.Head, mx.StampSATChain, tfd.CadenaOriginal())
+	addStamp(env.Head, mx.StampSATURL, d.verificationURL())
+	return env, nil
+}
+
+func addStamp(h *head.Header, provider cbc.Key, value string) {
+	if value == "" {
+		return
+	}
+	h.AddStamp(&head.Stamp{Provider: provider, Value: value})
+}
+
+// verificationURL provides the link used to check the status of the
+// stamped document with the SAT, usually presented as a QR code.
+func (d *Document) verificationURL() string {
+	q := url.Values{}
+	q.Set("id", d.Complemento.TimbreFiscalDigital.UUID)
+	q.Set("re", d.Emisor.Rfc)
+	if d.Receptor != nil {
+		q.Set("rr", d.Receptor.Rfc)
+	}
+	q.Set("tt", d.Total)
+	fe := d.Sello
+	if len(fe) > sealSuffixLength {
+		fe = fe[len(fe)-sealSuffixLength:]
+	}
+	q.Set("fe", fe)
+	return verifyURL + "?" + q.Encode()
+}
+
+func (d *Document) parseIssueDateTime(inv *bill.Invoice) error {
+	dt, err := civil.ParseDateTime(d.Fecha)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s'", d.Fecha)
+	}
+	inv.IssueDate = cal.Date{Date: dt.Date}
+	inv.IssueTime = &cal.Time{Time: dt.Time}
+	return nil
+}
+
+func parseReceptor(r *Receptor) *org.Party {
+	if r == nil {
+		return nil
+	}
+	if r.Rfc == mx.TaxIdentityCodeForeign.String() {
+		return &org.Party{
+			Name: r.Nombre,
+			TaxID: &tax.Identity{
+				Country: countryFromAlpha3(r.ResidenciaFiscal),
+				Code:    cbc.Code(r.NumRegIdTrib),
+			},
+		}
+	}
+	p := &org.Party{
+		Name:  r.Nombre,
+		TaxID: &tax.Identity{Country: l10n.MX.Tax(), Code: cbc.Code(r.Rfc)},
+		Ext: tax.Extensions{
+			cfdi.ExtKeyFiscalRegime: cbc.Code(r.RegimenFiscalReceptor),
+			cfdi.ExtKeyUse:          cbc.Code(r.UsoCFDI),
+		},
+	}
+	if r.DomicilioFiscalReceptor != "" {
+		p.Addresses = []*org.Address{{Code: cbc.Code(r.DomicilioFiscalReceptor)}}
+	}
+	return p
+}
+
+func countryFromAlpha3(code string) l10n.TaxCountryCode {
+	for _, c := range l10n.Countries() {
+		if c.Alpha3 == code {
+			return c.Code.Tax()
+		}
+	}
+	return l10n.TaxCountryCode(code)
+}
+
+func parseConcepto(c *Concepto) (*bill.Line, error) {
+	qty, err := convert.ParseAmount(c.Cantidad)
+	if err != nil {
+		return nil, err
+	}
+	price, err := convert.ParseAmount(c.ValorUnitario)
+	if err != nil {
+		return nil, err
+	}
+	line := &bill.Line{
+		Quantity: qty,
+		Item: &org.Item{
+			Ref:   cbc.Code(c.NoIdentificacion),
+			Name:  c.Descripcion,
+			Price: &price,
+			Unit:  convert.UnitFromUNECE(c.ClaveUnidad),
+			Ext:   tax.Extensions{cfdi.ExtKeyProdServ: cbc.Code(c.ClaveProdServ)},
+		},
+	}
+	if c.ClaveUnidad == defaultUnit {
+		line.Item.Unit = org.UnitEmpty
+	}
+	if c.Descuento != "" {
+		amount, err := convert.ParseAmount(c.Descuento)
+		if err != nil {
+			return nil, err
+		}
+		line.Discounts = []*bill.LineDiscount{{Amount: amount}}
+	}
+	if c.Impuestos == nil {
+		return line, nil
+	}
+	if c.Impuestos.Traslados != nil {
+		for _, t := range c.Impuestos.Traslados.Traslado {
+			combo, err := parseImpuesto(t, false)
+			if err != nil {
+				return nil, err
+			}
+			line.Taxes = append(line.Taxes, combo)
+		}
+	}
+	if c.Impuestos.Retenciones != nil {
+		for _, t := range c.Impuestos.Retenciones.Retencion {
+			combo, err := parseImpuesto(t, true)
+			if err != nil {
+				return nil, err
+			}
+			line.Taxes = append(line.Taxes, combo)
+		}
+	}
+	return line, nil
+}
+
+func parseImpuesto(t *Impuesto, retained bool) (*tax.Combo, error) {
+	combo := new(tax.Combo)
+	for cat, code := range taxCodes {
+		if code == t.Impuesto && cat.In(retainedCategories...) == retained {
+			combo.Category = cat
+			break
+		}
+	}
+	if combo.Category == cbc.CodeEmpty {
+		return nil, fmt.Errorf("%w: tax type '%s'", convert.ErrUnsupported, t.Impuesto)
+	}
+	if t.TipoFactor == factorExempt {
+		combo.Key = tax.KeyExempt
+		return combo, nil
+	}
+	factor, err := convert.ParseAmount(t.TasaOCuota)
+	if err != nil {
+		return nil, err
+	}
+	p := num.MakePercentage(factor.Value(), factor.Exp())
+	for p.Exp() > percentMinimumExp && p.Value()%10 == 0 {
+		p = p.Rescale(p.Exp() - 1)
+	}
+	combo.Percent = &p
+	return combo, nil
+}
+
+// parsePayment adds an advance for documents paid in a single payment,
+// as expected by the CFDI addon, or the payment instructions otherwise.
+func (d *Document) parsePayment(inv *bill.Invoice) error {
+	pd := new(bill.PaymentDetails)
+	if d.CondicionesDePago != "" {
+		pd.Terms = &pay.Terms{Notes: d.CondicionesDePago}
+	}
+	means := cbc.Code(d.FormaPago)
+	if means != cbc.CodeEmpty && means != paymentMeansUndef {
+		key := cfdi.PaymentMeansExtensions().Lookup(means)
+		ext := tax.Extensions{cfdi.ExtKeyPaymentMeans: means}
+		if d.MetodoPago == cfdi.ExtCodePaymentMethodPUE.String() {
+			total, err := convert.ParseAmount(d.Total)
+			if err != nil {
+				return err
+			}
+			pd.Advances = []*pay.Advance{
+				{Key: key, Description: singlePaymentDesc, Amount: total, Ext: ext},
+			}
+		} else {
+			pd.Instructions = &pay.Instructions{Key: key, Ext: ext}
+		}
+	}
+	if pd.Terms != nil || pd.Advances != nil || pd.Instructions != nil {
+		inv.Payment = pd
+	}
+	return nil
+}
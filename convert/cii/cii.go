@@ -0,0 +1,117 @@
+// Package cii converts GOBL invoices to and from UN/CEFACT Cross Industry
+// Invoice (D16B) documents following the EN 16931 CII syntax binding, as
+// required by XRechnung and Factur-X.
+//
+// Extensions defined by the EN 16931 addon are mapped directly to their
+// business terms: `untdid-document-type` to the type code (BT-3),
+// `untdid-tax-category` to the VAT category codes (BT-95, BT-102, BT-118,
+// BT-151), `cef-vatex` to the exemption reason code (BT-121),
+// `untdid-allowance` and `untdid-charge` to the reason codes (BT-98,
+// BT-105, BT-140, BT-145), and `untdid-payment-means` to the payment means
+// type code (BT-81). Invoices with the `de-xrechnung-v3` addon will use the
+// XRechnung specification identifier (BT-24), and their direct debit
+// creditor ID (BT-90), mandate reference (BT-89), and debited account
+// (BT-91) are mapped to the settlement.
+package cii
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/observe"
+)
+
+// Namespaces used in CII documents.
+const (
+	NamespaceRSM = "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"
+	NamespaceRAM = "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100"
+	NamespaceQDT = "urn:un:unece:uncefact:data:standard:QualifiedDataType:100"
+	NamespaceUDT = "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100"
+)
+
+// dateFormat is the UNTDID 2379 code for dates in the YYYYMMDD format.
+const dateFormat = "102"
+
+var prefixes = xmlns.Prefixes{
+	NamespaceRSM: "rsm",
+	NamespaceRAM: "ram",
+	NamespaceQDT: "qdt",
+	NamespaceUDT: "udt",
+}
+
+// Option is used to customize the document generated from an invoice.
+type Option func(*options)
+
+type options struct {
+	guidelineID       string
+	businessProcessID string
+}
+
+// WithGuidelineID overrides the specification identifier determined from
+// the invoice's addons.
+func WithGuidelineID(id string) Option {
+	return func(o *options) {
+		o.guidelineID = id
+	}
+}
+
+// WithBusinessProcessID sets the business process type of the document.
+func WithBusinessProcessID(id string) Option {
+	return func(o *options) {
+		o.businessProcessID = id
+	}
+}
+
+// Bytes provides the indented XML representation of the document.
+func (d *Document) Bytes() ([]byte, error) {
+	return xmlns.Marshal(d)
+}
+
+// ConvertInvoice is a convenience method to generate the CII XML for the
+// calculated invoice.
+func ConvertInvoice(inv *bill.Invoice, opts ...Option) (out []byte, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpExport, "cii")
+	defer func() { s.End(inv, err) }()
+	doc, err := FromInvoice(inv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+// Parse decodes the CII XML data.
+func Parse(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := xmlns.Unmarshal(data, prefixes, doc); err != nil {
+		return nil, fmt.Errorf("parsing cii: %w", err)
+	}
+	if doc.Header == nil || doc.Transaction == nil {
+		return nil, fmt.Errorf("parsing cii: missing document or transaction")
+	}
+	return doc, nil
+}
+
+// ParseInvoice is a convenience method to parse the CII XML data and
+// convert it into a calculated GOBL invoice.
+func ParseInvoice(data []byte) (inv *bill.Invoice, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpImport, "cii")
+	defer func() { s.End(inv, err) }()
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToInvoice()
+}
+
+func newDocument() *Document {
+	return &Document{
+		XMLName: xml.Name{Local: "rsm:CrossIndustryInvoice"},
+		RSMNS:   NamespaceRSM,
+		RAMNS:   NamespaceRAM,
+		QDTNS:   NamespaceQDT,
+		UDTNS:   NamespaceUDT,
+	}
+}
@@ -0,0 +1,226 @@
+package cii_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/de/xrechnung"
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/cii"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T, addon cbc.Key) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(addon),
+		Series:    "SAMPLE",
+		Code:      "001",
+		Currency:  "EUR",
+		IssueDate: cal.MakeDate(2024, 2, 13),
+		Supplier: &org.Party{
+			Name:  "Provide One GmbH",
+			Alias: "Provide One",
+			TaxID: &tax.Identity{Country: "DE", Code: "111111125"},
+			Inboxes: []*org.Inbox{
+				{Email: "inbox@example.com"},
+			},
+			People: []*org.Person{
+				{Name: &org.Name{Given: "Max", Surname: "Muster"}},
+			},
+			Telephones: []*org.Telephone{{Number: "+49100200300"}},
+			Emails:     []*org.Email{{Address: "billing@example.com"}},
+			Addresses: []*org.Address{
+				{
+					Street:   "Dietmar-Hopp-Allee",
+					Locality: "Walldorf",
+					Code:     "69190",
+					Country:  "DE",
+				},
+			},
+		},
+		Customer: &org.Party{
+			Name:  "Sample Consumer",
+			TaxID: &tax.Identity{Country: "DE", Code: "282741168"},
+			Inboxes: []*org.Inbox{
+				{Email: "buyer@example.com"},
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Werner-Heisenberg-Allee",
+					Locality: "München",
+					Code:     "80939",
+					Country:  "DE",
+				},
+			},
+		},
+		Ordering: &bill.Ordering{
+			Code: "04011000-12345-03",
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(20, 0),
+				Item: &org.Item{
+					Name:  "Development services",
+					Price: num.NewAmount(9000, 2),
+					Unit:  org.UnitHour,
+				},
+				Discounts: []*bill.LineDiscount{
+					{Percent: num.NewPercentage(10, 2), Reason: "Special discount"},
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+		Discounts: []*bill.Discount{
+			{
+				Reason: "Loyalty",
+				Amount: num.MakeAmount(2000, 2),
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Terms: &pay.Terms{
+				Notes: "Payment due in 30 days",
+				DueDates: []*pay.DueDate{
+					{Date: cal.NewDate(2024, 3, 14), Percent: num.NewPercentage(100, 2)},
+				},
+			},
+			Instructions: &pay.Instructions{
+				Key: "direct-debit+sepa",
+				DirectDebit: &pay.DirectDebit{
+					Ref:      "MANDATE-1",
+					Creditor: "DE98ZZZ09999999999",
+					Account:  "DE89370400440532013000",
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func TestFromInvoice(t *testing.T) {
+	t.Run("xrechnung", func(t *testing.T) {
+		inv := testInvoice(t, xrechnung.V3)
+		doc, err := cii.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.Equal(t, convert.GuidelineXRechnung, doc.Context.Guideline.ID)
+		assert.Equal(t, "SAMPLE-001", doc.Header.ID)
+		assert.Equal(t, "380", doc.Header.TypeCode)
+		assert.Equal(t, "20240213", doc.Header.IssueDateTime.DateString.Value)
+		assert.Equal(t, "102", doc.Header.IssueDateTime.DateString.Format)
+
+		a := doc.Transaction.Agreement
+		assert.Equal(t, "04011000-12345-03", a.BuyerReference)
+		assert.Equal(t, "Provide One GmbH", a.Seller.Name)
+		assert.Equal(t, "Provide One", a.Seller.LegalOrganization.TradingBusinessName)
+		assert.Equal(t, "Max Muster", a.Seller.Contact.PersonName)
+		assert.Equal(t, "EM", a.Seller.URI.ID.SchemeID)
+		assert.Equal(t, "DE111111125", a.Seller.TaxRegistrations[0].ID.Value)
+		assert.Equal(t, "VA", a.Seller.TaxRegistrations[0].ID.SchemeID)
+
+		s := doc.Transaction.Settlement
+		assert.Equal(t, "DE98ZZZ09999999999", s.CreditorReferenceID)
+		assert.Equal(t, "59", s.PaymentMeans[0].TypeCode)
+		assert.Equal(t, "DE89370400440532013000", s.PaymentMeans[0].PayerAccount.IBAN)
+		assert.Equal(t, "MANDATE-1", s.PaymentTerms.MandateID)
+		assert.Equal(t, "20240314", s.PaymentTerms.DueDate.DateString.Value)
+		require.Len(t, s.AllowanceCharges, 1)
+		assert.False(t, s.AllowanceCharges[0].ChargeIndicator.Value)
+		assert.Equal(t, "S", s.AllowanceCharges[0].Tax.CategoryCode)
+		require.Len(t, s.Taxes, 1)
+		assert.Equal(t, "19", s.Taxes[0].RatePercent)
+		assert.Equal(t, inv.Totals.Sum.String(), s.Summation.LineTotal)
+		assert.Equal(t, "EUR", s.Summation.TaxTotal.CurrencyID)
+		assert.Equal(t, inv.Totals.Payable.String(), s.Summation.DuePayable)
+
+		require.Len(t, doc.Transaction.Lines, 1)
+		l := doc.Transaction.Lines[0]
+		assert.Equal(t, "1", l.LineDoc.LineID)
+		assert.Equal(t, "HUR", l.Delivery.BilledQuantity.UnitCode)
+		assert.Equal(t, "1620.00", l.Settlement.Summation.LineTotalAmount)
+	})
+	t.Run("en16931", func(t *testing.T) {
+		doc, err := cii.FromInvoice(testInvoice(t, en16931.V2017),
+			cii.WithBusinessProcessID("urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, convert.GuidelineEN16931, doc.Context.Guideline.ID)
+		assert.Equal(t, "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0", doc.Context.BusinessProcess.ID)
+	})
+	t.Run("guideline option", func(t *testing.T) {
+		doc, err := cii.FromInvoice(testInvoice(t, en16931.V2017), cii.WithGuidelineID("urn:custom"))
+		require.NoError(t, err)
+		assert.Equal(t, "urn:custom", doc.Context.Guideline.ID)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		_, err := cii.FromInvoice(&bill.Invoice{})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	inv := testInvoice(t, xrechnung.V3)
+	data, err := cii.ConvertInvoice(inv)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<rsm:CrossIndustryInvoice xmlns:rsm="`+cii.NamespaceRSM+`"`)
+	assert.Contains(t, string(data), `<udt:DateTimeString format="102">20240213</udt:DateTimeString>`)
+
+	out, err := cii.ParseInvoice(data)
+	require.NoError(t, err)
+	assert.Equal(t, []cbc.Key{en16931.V2017, xrechnung.V3}, out.GetAddons())
+	assert.Equal(t, cbc.Code("SAMPLE-001"), out.Code)
+	assert.Equal(t, inv.IssueDate, out.IssueDate)
+	assert.Equal(t, cbc.Code("04011000-12345-03"), out.Ordering.Code)
+	assert.Equal(t, "Provide One", out.Supplier.Alias)
+	assert.Equal(t, "inbox@example.com", out.Supplier.Inboxes[0].Email)
+	assert.Equal(t, cbc.Code("111111125"), out.Supplier.TaxID.Code)
+	assert.Equal(t, "Special discount", out.Lines[0].Discounts[0].Reason)
+	assert.Equal(t, "Loyalty", out.Discounts[0].Reason)
+
+	dd := out.Payment.Instructions.DirectDebit
+	require.NotNil(t, dd)
+	assert.Equal(t, "MANDATE-1", dd.Ref)
+	assert.Equal(t, "DE98ZZZ09999999999", dd.Creditor)
+	assert.Equal(t, "DE89370400440532013000", dd.Account)
+	assert.Equal(t, "Payment due in 30 days", out.Payment.Terms.Notes)
+
+	assert.Equal(t, inv.Totals.Sum.String(), out.Totals.Sum.String())
+	assert.Equal(t, inv.Totals.Discount.String(), out.Totals.Discount.String())
+	assert.Equal(t, inv.Totals.Tax.String(), out.Totals.Tax.String())
+	assert.Equal(t, inv.Totals.Payable.String(), out.Totals.Payable.String())
+}
+
+func TestParse(t *testing.T) {
+	t.Run("missing transaction", func(t *testing.T) {
+		_, err := cii.Parse([]byte(`<rsm:CrossIndustryInvoice xmlns:rsm="` + cii.NamespaceRSM + `"></rsm:CrossIndustryInvoice>`))
+		assert.ErrorContains(t, err, "missing document or transaction")
+	})
+	t.Run("wrong root", func(t *testing.T) {
+		_, err := cii.Parse([]byte(`<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"></Invoice>`))
+		assert.ErrorContains(t, err, "parsing cii")
+	})
+	t.Run("unsupported date format", func(t *testing.T) {
+		data := []byte(`<x:CrossIndustryInvoice xmlns:x="` + cii.NamespaceRSM + `" xmlns:r="` + cii.NamespaceRAM + `" xmlns:u="` + cii.NamespaceUDT + `">
+			<x:ExchangedDocument><r:ID>1</r:ID><r:TypeCode>380</r:TypeCode>
+				<r:IssueDateTime><u:DateTimeString format="203">202402131200</u:DateTimeString></r:IssueDateTime>
+			</x:ExchangedDocument>
+			<x:SupplyChainTradeTransaction></x:SupplyChainTradeTransaction>
+		</x:CrossIndustryInvoice>`)
+		_, err := cii.ParseInvoice(data)
+		assert.ErrorContains(t, err, "issue date: unsupported date format '203'")
+	})
+}
@@ -0,0 +1,336 @@
+package cii
+
+import "encoding/xml"
+
+// Document represents a UN/CEFACT Cross Industry Invoice (D16B) as used
+// by the EN 16931 CII syntax binding.
+type Document struct {
+	XMLName xml.Name `xml:"rsm:CrossIndustryInvoice"`
+	RSMNS   string   `xml:"xmlns:rsm,attr,omitempty"`
+	RAMNS   string   `xml:"xmlns:ram,attr,omitempty"`
+	QDTNS   string   `xml:"xmlns:qdt,attr,omitempty"`
+	UDTNS   string   `xml:"xmlns:udt,attr,omitempty"`
+
+	Context     *Context     `xml:"rsm:ExchangedDocumentContext"`
+	Header      *Header      `xml:"rsm:ExchangedDocument"`
+	Transaction *Transaction `xml:"rsm:SupplyChainTradeTransaction"`
+}
+
+// Context identifies the business process and specification the
+// document conforms to.
+type Context struct {
+	BusinessProcess *IDParam `xml:"ram:BusinessProcessSpecifiedDocumentContextParameter,omitempty"`
+	Guideline       *IDParam `xml:"ram:GuidelineSpecifiedDocumentContextParameter"`
+}
+
+// IDParam wraps a single ID.
+type IDParam struct {
+	ID string `xml:"ram:ID"`
+}
+
+// Header contains the document's identification details.
+type Header struct {
+	ID            string  `xml:"ram:ID"`
+	TypeCode      string  `xml:"ram:TypeCode"`
+	IssueDateTime *Date   `xml:"ram:IssueDateTime"`
+	Notes         []*Note `xml:"ram:IncludedNote,omitempty"`
+}
+
+// Note is a free text note with an optional UNTDID 4451 subject code.
+type Note struct {
+	Content     string `xml:"ram:Content"`
+	SubjectCode string `xml:"ram:SubjectCode,omitempty"`
+}
+
+// Date wraps a date in the `102` (YYYYMMDD) format.
+type Date struct {
+	DateString *DateString `xml:"udt:DateTimeString"`
+}
+
+// FormattedDate wraps a date using the qualified data type, as used for
+// references to other documents.
+type FormattedDate struct {
+	DateString *DateString `xml:"qdt:DateTimeString"`
+}
+
+// DateString contains the date value and its format.
+type DateString struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// Transaction contains the lines, agreement, delivery, and settlement.
+type Transaction struct {
+	Lines      []*Line     `xml:"ram:IncludedSupplyChainTradeLineItem"`
+	Agreement  *Agreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+	Delivery   *Delivery   `xml:"ram:ApplicableHeaderTradeDelivery"`
+	Settlement *Settlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+}
+
+// Line is an invoice line.
+type Line struct {
+	LineDoc    *LineDoc        `xml:"ram:AssociatedDocumentLineDocument"`
+	Product    *Product        `xml:"ram:SpecifiedTradeProduct"`
+	Agreement  *LineAgreement  `xml:"ram:SpecifiedLineTradeAgreement"`
+	Delivery   *LineDelivery   `xml:"ram:SpecifiedLineTradeDelivery"`
+	Settlement *LineSettlement `xml:"ram:SpecifiedLineTradeSettlement"`
+}
+
+// LineDoc contains the line ID and note.
+type LineDoc struct {
+	LineID string `xml:"ram:LineID"`
+	Note   *Note  `xml:"ram:IncludedNote,omitempty"`
+}
+
+// Product describes the item.
+type Product struct {
+	GlobalID      *Identifier `xml:"ram:GlobalID,omitempty"`
+	SellerID      string      `xml:"ram:SellerAssignedID,omitempty"`
+	Name          string      `xml:"ram:Name"`
+	Description   string      `xml:"ram:Description,omitempty"`
+	OriginCountry *CountryID  `xml:"ram:OriginTradeCountry,omitempty"`
+}
+
+// CountryID wraps an ISO 3166-1 alpha-2 country code.
+type CountryID struct {
+	ID string `xml:"ram:ID"`
+}
+
+// Identifier is a code with an optional scheme.
+type Identifier struct {
+	SchemeID string `xml:"schemeID,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// LineAgreement contains the order reference and net price.
+type LineAgreement struct {
+	OrderRef *LineOrderRef `xml:"ram:BuyerOrderReferencedDocument,omitempty"`
+	NetPrice *TradePrice   `xml:"ram:NetPriceProductTradePrice"`
+}
+
+// LineOrderRef points to the line in the purchase order.
+type LineOrderRef struct {
+	LineID string `xml:"ram:LineID"`
+}
+
+// TradePrice contains the item price for an optional base quantity.
+type TradePrice struct {
+	ChargeAmount  string    `xml:"ram:ChargeAmount"`
+	BasisQuantity *Quantity `xml:"ram:BasisQuantity,omitempty"`
+}
+
+// Quantity is a value with a unit code.
+type Quantity struct {
+	UnitCode string `xml:"unitCode,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// LineDelivery contains the billed quantity.
+type LineDelivery struct {
+	BilledQuantity *Quantity `xml:"ram:BilledQuantity"`
+}
+
+// LineSettlement contains the line's tax, allowances, charges, and total.
+type LineSettlement struct {
+	Tax              *TradeTax          `xml:"ram:ApplicableTradeTax"`
+	Period           *Period            `xml:"ram:BillingSpecifiedPeriod,omitempty"`
+	AllowanceCharges []*AllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+	Summation        *LineSummation     `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation"`
+	AccountingCost   *IDParam           `xml:"ram:ReceivableSpecifiedTradeAccountingAccount,omitempty"`
+}
+
+// LineSummation contains the line's net amount.
+type LineSummation struct {
+	LineTotalAmount string `xml:"ram:LineTotalAmount"`
+}
+
+// TradeTax describes a tax category, and when used in the header, the
+// amounts of the tax breakdown.
+type TradeTax struct {
+	CalculatedAmount    string `xml:"ram:CalculatedAmount,omitempty"`
+	TypeCode            string `xml:"ram:TypeCode"`
+	ExemptionReason     string `xml:"ram:ExemptionReason,omitempty"`
+	BasisAmount         string `xml:"ram:BasisAmount,omitempty"`
+	CategoryCode        string `xml:"ram:CategoryCode"`
+	ExemptionReasonCode string `xml:"ram:ExemptionReasonCode,omitempty"`
+	RatePercent         string `xml:"ram:RateApplicablePercent,omitempty"`
+}
+
+// Period defines a start and end date.
+type Period struct {
+	Start *Date `xml:"ram:StartDateTime,omitempty"`
+	End   *Date `xml:"ram:EndDateTime,omitempty"`
+}
+
+// AllowanceCharge is a discount or charge at document or line level.
+type AllowanceCharge struct {
+	ChargeIndicator *Indicator `xml:"ram:ChargeIndicator"`
+	Percent         string     `xml:"ram:CalculationPercent,omitempty"`
+	BasisAmount     string     `xml:"ram:BasisAmount,omitempty"`
+	ActualAmount    string     `xml:"ram:ActualAmount"`
+	ReasonCode      string     `xml:"ram:ReasonCode,omitempty"`
+	Reason          string     `xml:"ram:Reason,omitempty"`
+	Tax             *TradeTax  `xml:"ram:CategoryTradeTax,omitempty"`
+}
+
+// Indicator wraps a boolean value.
+type Indicator struct {
+	Value bool `xml:"udt:Indicator"`
+}
+
+// Agreement contains the parties and references agreed by them.
+type Agreement struct {
+	BuyerReference string       `xml:"ram:BuyerReference,omitempty"`
+	Seller         *Party       `xml:"ram:SellerTradeParty"`
+	Buyer          *Party       `xml:"ram:BuyerTradeParty"`
+	OrderRef       *DocumentRef `xml:"ram:BuyerOrderReferencedDocument,omitempty"`
+	ContractRef    *DocumentRef `xml:"ram:ContractReferencedDocument,omitempty"`
+	Project        *Project     `xml:"ram:SpecifiedProcuringProject,omitempty"`
+}
+
+// DocumentRef refers to another document by the ID its issuer assigned.
+type DocumentRef struct {
+	IssuerAssignedID string         `xml:"ram:IssuerAssignedID"`
+	IssueDate        *FormattedDate `xml:"ram:FormattedIssueDateTime,omitempty"`
+}
+
+// Project identifies the procurement project.
+type Project struct {
+	ID   string `xml:"ram:ID"`
+	Name string `xml:"ram:Name"`
+}
+
+// Party contains the details of the seller or buyer.
+type Party struct {
+	IDs               []string           `xml:"ram:ID,omitempty"`
+	GlobalIDs         []*Identifier      `xml:"ram:GlobalID,omitempty"`
+	Name              string             `xml:"ram:Name"`
+	LegalOrganization *LegalOrganization `xml:"ram:SpecifiedLegalOrganization,omitempty"`
+	Contact           *Contact           `xml:"ram:DefinedTradeContact,omitempty"`
+	Address           *Address           `xml:"ram:PostalTradeAddress,omitempty"`
+	URI               *URI               `xml:"ram:URIUniversalCommunication,omitempty"`
+	TaxRegistrations  []*TaxRegistration `xml:"ram:SpecifiedTaxRegistration,omitempty"`
+}
+
+// LegalOrganization contains the registration ID and trading name.
+type LegalOrganization struct {
+	ID                  *Identifier `xml:"ram:ID,omitempty"`
+	TradingBusinessName string      `xml:"ram:TradingBusinessName,omitempty"`
+}
+
+// Contact contains the party's contact details.
+type Contact struct {
+	PersonName string          `xml:"ram:PersonName,omitempty"`
+	Telephone  *CompleteNumber `xml:"ram:TelephoneUniversalCommunication,omitempty"`
+	Email      *URIID          `xml:"ram:EmailURIUniversalCommunication,omitempty"`
+}
+
+// CompleteNumber wraps a telephone number.
+type CompleteNumber struct {
+	Number string `xml:"ram:CompleteNumber"`
+}
+
+// URIID wraps an email address.
+type URIID struct {
+	URIID string `xml:"ram:URIID"`
+}
+
+// URI contains the party's electronic address.
+type URI struct {
+	ID *Identifier `xml:"ram:URIID"`
+}
+
+// Address is a postal address.
+type Address struct {
+	Postcode    string `xml:"ram:PostcodeCode,omitempty"`
+	LineOne     string `xml:"ram:LineOne,omitempty"`
+	LineTwo     string `xml:"ram:LineTwo,omitempty"`
+	City        string `xml:"ram:CityName,omitempty"`
+	CountryID   string `xml:"ram:CountryID"`
+	Subdivision string `xml:"ram:CountrySubDivisionName,omitempty"`
+}
+
+// TaxRegistration contains a tax ID, using the `VA` scheme for VAT numbers
+// and `FC` for local tax numbers.
+type TaxRegistration struct {
+	ID *Identifier `xml:"ram:ID"`
+}
+
+// Delivery contains the actual delivery date.
+type Delivery struct {
+	Event *DeliveryEvent `xml:"ram:ActualDeliverySupplyChainEvent,omitempty"`
+}
+
+// DeliveryEvent wraps the delivery date.
+type DeliveryEvent struct {
+	OccurrenceDateTime *Date `xml:"ram:OccurrenceDateTime"`
+}
+
+// Settlement contains the payment and tax details along with the totals.
+type Settlement struct {
+	CreditorReferenceID string             `xml:"ram:CreditorReferenceID,omitempty"`
+	PaymentReference    string             `xml:"ram:PaymentReference,omitempty"`
+	Currency            string             `xml:"ram:InvoiceCurrencyCode"`
+	PaymentMeans        []*PaymentMeans    `xml:"ram:SpecifiedTradeSettlementPaymentMeans,omitempty"`
+	Taxes               []*TradeTax        `xml:"ram:ApplicableTradeTax"`
+	Period              *Period            `xml:"ram:BillingSpecifiedPeriod,omitempty"`
+	AllowanceCharges    []*AllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+	PaymentTerms        *PaymentTerms      `xml:"ram:SpecifiedTradePaymentTerms,omitempty"`
+	Summation           *Summation         `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+	InvoiceRefs         []*DocumentRef     `xml:"ram:InvoiceReferencedDocument,omitempty"`
+}
+
+// PaymentMeans describes how the payment should be made.
+type PaymentMeans struct {
+	TypeCode     string            `xml:"ram:TypeCode"`
+	Information  string            `xml:"ram:Information,omitempty"`
+	Card         *Card             `xml:"ram:ApplicableTradeSettlementFinancialCard,omitempty"`
+	PayerAccount *FinancialAccount `xml:"ram:PayerPartyDebtorFinancialAccount,omitempty"`
+	PayeeAccount *FinancialAccount `xml:"ram:PayeePartyCreditorFinancialAccount,omitempty"`
+	PayeeBank    *Institution      `xml:"ram:PayeeSpecifiedCreditorFinancialInstitution,omitempty"`
+}
+
+// Card contains the payment card details.
+type Card struct {
+	ID         string `xml:"ram:ID"`
+	HolderName string `xml:"ram:CardholderName,omitempty"`
+}
+
+// FinancialAccount contains bank account details.
+type FinancialAccount struct {
+	IBAN          string `xml:"ram:IBANID,omitempty"`
+	AccountName   string `xml:"ram:AccountName,omitempty"`
+	ProprietaryID string `xml:"ram:ProprietaryID,omitempty"`
+}
+
+// Institution identifies the bank by its BIC.
+type Institution struct {
+	BIC string `xml:"ram:BICID"`
+}
+
+// PaymentTerms contains the terms description, due date, and direct
+// debit mandate.
+type PaymentTerms struct {
+	Description string `xml:"ram:Description,omitempty"`
+	DueDate     *Date  `xml:"ram:DueDateDateTime,omitempty"`
+	MandateID   string `xml:"ram:DirectDebitMandateID,omitempty"`
+}
+
+// Summation contains the document totals.
+type Summation struct {
+	LineTotal      string  `xml:"ram:LineTotalAmount"`
+	ChargeTotal    string  `xml:"ram:ChargeTotalAmount,omitempty"`
+	AllowanceTotal string  `xml:"ram:AllowanceTotalAmount,omitempty"`
+	TaxBasisTotal  string  `xml:"ram:TaxBasisTotalAmount"`
+	TaxTotal       *Amount `xml:"ram:TaxTotalAmount,omitempty"`
+	Rounding       string  `xml:"ram:RoundingAmount,omitempty"`
+	GrandTotal     string  `xml:"ram:GrandTotalAmount"`
+	Prepaid        string  `xml:"ram:TotalPrepaidAmount,omitempty"`
+	DuePayable     string  `xml:"ram:DuePayableAmount"`
+}
+
+// Amount is a monetary value with currency.
+type Amount struct {
+	CurrencyID string `xml:"currencyID,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
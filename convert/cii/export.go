@@ -0,0 +1,441 @@
+package cii
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/cef"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// Tax related codes used by CII.
+const (
+	taxTypeVAT         = "VAT"
+	taxSchemeVAT       = "VA"
+	taxSchemeTaxNumber = "FC"
+	uriSchemeEmail     = "EM"
+)
+
+// FromInvoice converts the calculated GOBL invoice into a CII document.
+func FromInvoice(inv *bill.Invoice, opts ...Option) (*Document, error) {
+	if inv == nil || inv.Totals == nil {
+		return nil, convert.ErrNotCalculated
+	}
+	if inv.Totals.RetainedTax != nil {
+		return nil, fmt.Errorf("%w: retained taxes", convert.ErrUnsupported)
+	}
+	o := &options{guidelineID: convert.GuidelineID(inv)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	doc := newDocument()
+	doc.Context = &Context{Guideline: &IDParam{ID: o.guidelineID}}
+	if o.businessProcessID != "" {
+		doc.Context.BusinessProcess = &IDParam{ID: o.businessProcessID}
+	}
+	doc.Header = &Header{
+		ID:            inv.Series.Join(inv.Code).String(),
+		TypeCode:      convert.DocumentTypeCode(inv).String(),
+		IssueDateTime: newDate(&inv.IssueDate),
+	}
+	for _, n := range inv.Notes {
+		doc.Header.Notes = append(doc.Header.Notes, &Note{
+			Content:     n.Text,
+			SubjectCode: n.Ext.Get(untdid.ExtKeyTextSubject).String(),
+		})
+	}
+
+	cur := inv.Currency
+	tx := &Transaction{
+		Agreement:  newAgreement(inv),
+		Delivery:   new(Delivery),
+		Settlement: newSettlement(inv, cur),
+	}
+	for _, l := range inv.Lines {
+		tx.Lines = append(tx.Lines, newLine(l))
+	}
+	if inv.Delivery != nil && inv.Delivery.Date != nil {
+		tx.Delivery.Event = &DeliveryEvent{OccurrenceDateTime: newDate(inv.Delivery.Date)}
+	} else if inv.OperationDate != nil {
+		tx.Delivery.Event = &DeliveryEvent{OccurrenceDateTime: newDate(inv.OperationDate)}
+	}
+	doc.Transaction = tx
+	return doc, nil
+}
+
+func newAgreement(inv *bill.Invoice) *Agreement {
+	a := &Agreement{
+		Seller: newParty(inv.Supplier),
+		Buyer:  newParty(inv.Customer),
+	}
+	if a.Buyer == nil {
+		a.Buyer = new(Party)
+	}
+	if o := inv.Ordering; o != nil {
+		a.BuyerReference = o.Code.String()
+		if len(o.Purchases) > 0 {
+			a.OrderRef = newDocumentRef(o.Purchases[0])
+		}
+		if len(o.Contracts) > 0 {
+			a.ContractRef = newDocumentRef(o.Contracts[0])
+		}
+		if len(o.Projects) > 0 {
+			p := o.Projects[0]
+			a.Project = &Project{ID: p.Series.Join(p.Code).String(), Name: p.Description}
+			if a.Project.Name == "" {
+				// the name is mandatory
+				a.Project.Name = a.Project.ID
+			}
+		}
+	}
+	return a
+}
+
+func newSettlement(inv *bill.Invoice, cur currency.Code) *Settlement {
+	s := &Settlement{
+		Currency: cur.String(),
+		Taxes:    newTaxBreakdown(inv.Totals),
+	}
+	if inv.Ordering != nil {
+		s.Period = newPeriod(inv.Ordering.Period)
+	}
+	if p := inv.Payment; p != nil {
+		if instr := p.Instructions; instr != nil {
+			s.PaymentReference = instr.Ref.String()
+			s.PaymentMeans = newPaymentMeans(instr)
+			if dd := instr.DirectDebit; dd != nil {
+				s.CreditorReferenceID = dd.Creditor
+			}
+		}
+		s.PaymentTerms = newPaymentTerms(p)
+	}
+	for _, d := range inv.Discounts {
+		s.AllowanceCharges = append(s.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator: &Indicator{Value: false},
+			Percent:         percentString(d.Percent),
+			BasisAmount:     amountString(d.Base),
+			ActualAmount:    d.Amount.String(),
+			ReasonCode:      d.Ext.Get(untdid.ExtKeyAllowance).String(),
+			Reason:          d.Reason,
+			Tax:             newCategoryTax(d.Taxes),
+		})
+	}
+	for _, c := range inv.Charges {
+		s.AllowanceCharges = append(s.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator: &Indicator{Value: true},
+			Percent:         percentString(c.Percent),
+			BasisAmount:     amountString(c.Base),
+			ActualAmount:    c.Amount.String(),
+			ReasonCode:      c.Ext.Get(untdid.ExtKeyCharge).String(),
+			Reason:          c.Reason,
+			Tax:             newCategoryTax(c.Taxes),
+		})
+	}
+	s.Summation = newSummation(inv.Totals, cur)
+	for _, p := range inv.Preceding {
+		s.InvoiceRefs = append(s.InvoiceRefs, newDocumentRef(p))
+	}
+	return s
+}
+
+func newPaymentMeans(instr *pay.Instructions) []*PaymentMeans {
+	pm := &PaymentMeans{
+		TypeCode:    convert.PaymentMeansCode(instr).String(),
+		Information: instr.Detail,
+	}
+	if c := instr.Card; c != nil {
+		pm.Card = &Card{ID: c.Last4, HolderName: c.Holder}
+	}
+	if dd := instr.DirectDebit; dd != nil && dd.Account != "" {
+		pm.PayerAccount = &FinancialAccount{IBAN: dd.Account}
+	}
+	if len(instr.CreditTransfer) == 0 {
+		return []*PaymentMeans{pm}
+	}
+	list := make([]*PaymentMeans, len(instr.CreditTransfer))
+	for i, ct := range instr.CreditTransfer {
+		m := *pm
+		m.PayeeAccount = &FinancialAccount{
+			IBAN:          ct.IBAN,
+			AccountName:   ct.Name,
+			ProprietaryID: ct.Number,
+		}
+		if ct.BIC != "" {
+			m.PayeeBank = &Institution{BIC: ct.BIC}
+		}
+		list[i] = &m
+	}
+	return list
+}
+
+func newPaymentTerms(p *bill.PaymentDetails) *PaymentTerms {
+	pt := new(PaymentTerms)
+	if t := p.Terms; t != nil {
+		pt.Description = t.Notes
+		if pt.Description == "" {
+			pt.Description = t.Detail
+		}
+		if len(t.DueDates) > 0 && t.DueDates[0].Date != nil {
+			pt.DueDate = newDate(t.DueDates[0].Date)
+		}
+	}
+	if p.Instructions != nil && p.Instructions.DirectDebit != nil {
+		pt.MandateID = p.Instructions.DirectDebit.Ref
+	}
+	if *pt == (PaymentTerms{}) {
+		return nil
+	}
+	return pt
+}
+
+func newTaxBreakdown(t *bill.Totals) []*TradeTax {
+	var list []*TradeTax
+	if t.Taxes == nil {
+		return list
+	}
+	for _, ct := range t.Taxes.Categories {
+		if ct.Retained || ct.Informative {
+			continue
+		}
+		for _, rt := range ct.Rates {
+			tt := newTradeTax(ct.Code, rt.Key, rt.Percent, rt.Ext)
+			tt.CalculatedAmount = rt.Amount.String()
+			tt.BasisAmount = rt.Base.String()
+			list = append(list, tt)
+		}
+	}
+	return list
+}
+
+func newTradeTax(cat cbc.Code, key cbc.Key, percent *num.Percentage, ext tax.Extensions) *TradeTax {
+	tt := &TradeTax{
+		TypeCode:            taxTypeVAT,
+		CategoryCode:        convert.TaxCategoryCode(cat, key, ext).String(),
+		ExemptionReasonCode: ext.Get(cef.ExtKeyVATEX).String(),
+	}
+	if percent != nil {
+		tt.RatePercent = percent.StringWithoutSymbol()
+	} else if tt.CategoryCode == convert.TaxCategoryZero.String() {
+		tt.RatePercent = "0"
+	}
+	return tt
+}
+
+func newCategoryTax(ts tax.Set) *TradeTax {
+	if vat := ts.Get(tax.CategoryVAT); vat != nil {
+		return newTradeTax(vat.Category, vat.Key, vat.Percent, vat.Ext)
+	}
+	return nil
+}
+
+func newSummation(t *bill.Totals, cur currency.Code) *Summation {
+	s := &Summation{
+		LineTotal:      t.Sum.String(),
+		ChargeTotal:    amountString(t.Charge),
+		AllowanceTotal: amountString(t.Discount),
+		TaxBasisTotal:  t.Total.String(),
+		TaxTotal:       &Amount{CurrencyID: cur.String(), Value: t.Tax.String()},
+		Rounding:       amountString(t.Rounding),
+		GrandTotal:     t.TotalWithTax.String(),
+		Prepaid:        amountString(t.Advances),
+		DuePayable:     t.Payable.String(),
+	}
+	if t.Due != nil {
+		s.DuePayable = t.Due.String()
+	}
+	return s
+}
+
+func newLine(l *bill.Line) *Line {
+	line := &Line{
+		LineDoc:    &LineDoc{LineID: fmt.Sprintf("%d", l.Index)},
+		Product:    new(Product),
+		Agreement:  &LineAgreement{NetPrice: new(TradePrice)},
+		Delivery:   &LineDelivery{BilledQuantity: &Quantity{Value: l.Quantity.String()}},
+		Settlement: new(LineSettlement),
+	}
+	if len(l.Notes) > 0 {
+		line.LineDoc.Note = &Note{Content: l.Notes[0].Text}
+	}
+	if l.Order != cbc.CodeEmpty {
+		line.Agreement.OrderRef = &LineOrderRef{LineID: l.Order.String()}
+	}
+	if it := l.Item; it != nil {
+		line.Product.Name = it.Name
+		line.Product.Description = it.Description
+		line.Product.SellerID = it.Ref.String()
+		for _, id := range it.Identities {
+			if s := id.Ext.Get(iso.ExtKeySchemeID); s != cbc.CodeEmpty {
+				line.Product.GlobalID = &Identifier{SchemeID: s.String(), Value: id.Code.String()}
+				break
+			}
+		}
+		if it.Origin != "" {
+			line.Product.OriginCountry = &CountryID{ID: it.Origin.String()}
+		}
+		if it.Price != nil {
+			line.Agreement.NetPrice.ChargeAmount = it.Price.String()
+		}
+		line.Delivery.BilledQuantity.UnitCode = it.Unit.UNECE().String()
+	}
+
+	ls := line.Settlement
+	if ls.Tax = newCategoryTax(l.Taxes); ls.Tax == nil {
+		ls.Tax = &TradeTax{
+			TypeCode:     taxTypeVAT,
+			CategoryCode: convert.TaxCategoryOutsideScope.String(),
+		}
+	}
+	ls.Period = newPeriod(l.Period)
+	for _, d := range l.Discounts {
+		ls.AllowanceCharges = append(ls.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator: &Indicator{Value: false},
+			Percent:         percentString(d.Percent),
+			BasisAmount:     amountString(d.Base),
+			ActualAmount:    d.Amount.String(),
+			ReasonCode:      d.Ext.Get(untdid.ExtKeyAllowance).String(),
+			Reason:          d.Reason,
+		})
+	}
+	for _, c := range l.Charges {
+		ls.AllowanceCharges = append(ls.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator: &Indicator{Value: true},
+			Percent:         percentString(c.Percent),
+			BasisAmount:     amountString(c.Base),
+			ActualAmount:    c.Amount.String(),
+			ReasonCode:      c.Ext.Get(untdid.ExtKeyCharge).String(),
+			Reason:          c.Reason,
+		})
+	}
+	ls.Summation = new(LineSummation)
+	if l.Total != nil {
+		ls.Summation.LineTotalAmount = l.Total.String()
+	}
+	if l.Cost != cbc.CodeEmpty {
+		ls.AccountingCost = &IDParam{ID: l.Cost.String()}
+	}
+	return line
+}
+
+func newParty(p *org.Party) *Party {
+	if p == nil {
+		return nil
+	}
+	party := &Party{Name: p.Name}
+	for _, id := range p.Identities {
+		if s := id.Ext.Get(iso.ExtKeySchemeID); s != cbc.CodeEmpty {
+			party.GlobalIDs = append(party.GlobalIDs, &Identifier{SchemeID: s.String(), Value: id.Code.String()})
+		} else {
+			party.IDs = append(party.IDs, id.Code.String())
+		}
+	}
+	if p.Alias != "" {
+		party.LegalOrganization = &LegalOrganization{TradingBusinessName: p.Alias}
+	}
+	party.Contact = newContact(p)
+	if len(p.Addresses) > 0 {
+		a := p.Addresses[0]
+		party.Address = &Address{
+			Postcode:    a.Code.String(),
+			LineOne:     a.LineOne(),
+			LineTwo:     a.LineTwo(),
+			City:        a.Locality,
+			CountryID:   a.Country.String(),
+			Subdivision: a.Region,
+		}
+	}
+	if party.Address == nil && p.TaxID != nil && p.TaxID.Country != "" {
+		// the country is required by EN 16931
+		party.Address = &Address{CountryID: p.TaxID.Country.String()}
+	}
+	if ea := p.ElectronicAddress(); !ea.IsEmpty() {
+		party.URI = &URI{ID: &Identifier{SchemeID: ea.Scheme().String(), Value: ea.Code().String()}}
+	} else {
+		for _, ib := range p.Inboxes {
+			if ib.Email != "" {
+				party.URI = &URI{ID: &Identifier{SchemeID: uriSchemeEmail, Value: ib.Email}}
+				break
+			}
+		}
+	}
+	if code := convert.TaxIdentityCode(p.TaxID); code != "" {
+		scheme := taxSchemeVAT
+		if p.TaxID.Scheme != cbc.CodeEmpty && p.TaxID.Scheme != "VAT" {
+			scheme = taxSchemeTaxNumber
+			code = p.TaxID.Code.String()
+		}
+		party.TaxRegistrations = []*TaxRegistration{
+			{ID: &Identifier{SchemeID: scheme, Value: code}},
+		}
+	}
+	return party
+}
+
+func newContact(p *org.Party) *Contact {
+	c := new(Contact)
+	if len(p.People) > 0 && p.People[0].Name != nil {
+		n := p.People[0].Name
+		c.PersonName = strings.TrimSpace(n.Given + " " + n.Surname)
+	}
+	if len(p.Telephones) > 0 {
+		c.Telephone = &CompleteNumber{Number: p.Telephones[0].Number}
+	}
+	if len(p.Emails) > 0 {
+		c.Email = &URIID{URIID: p.Emails[0].Address}
+	}
+	if c.PersonName == "" && c.Telephone == nil && c.Email == nil {
+		return nil
+	}
+	return c
+}
+
+func newDocumentRef(ref *org.DocumentRef) *DocumentRef {
+	dr := &DocumentRef{IssuerAssignedID: ref.Series.Join(ref.Code).String()}
+	if ref.IssueDate != nil {
+		dr.IssueDate = &FormattedDate{DateString: newDateString(ref.IssueDate)}
+	}
+	return dr
+}
+
+func newPeriod(p *cal.Period) *Period {
+	if p == nil {
+		return nil
+	}
+	return &Period{Start: newDate(&p.Start), End: newDate(&p.End)}
+}
+
+func newDate(d *cal.Date) *Date {
+	return &Date{DateString: newDateString(d)}
+}
+
+func newDateString(d *cal.Date) *DateString {
+	return &DateString{
+		Format: dateFormat,
+		Value:  strings.ReplaceAll(d.String(), "-", ""),
+	}
+}
+
+func amountString(a *num.Amount) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func percentString(p *num.Percentage) string {
+	if p == nil {
+		return ""
+	}
+	return p.StringWithoutSymbol()
+}
@@ -0,0 +1,541 @@
+package cii
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/addons/de/xrechnung"
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// ToInvoice converts the CII document into a GOBL invoice using the
+// EN 16931 addon, or XRechnung if indicated by the guideline, and
+// calculates the result.
+func (d *Document) ToInvoice() (*bill.Invoice, error) {
+	inv, err := d.invoice()
+	if err != nil {
+		return nil, err
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, fmt.Errorf("calculating invoice: %w", err)
+	}
+	return inv, nil
+}
+
+func (d *Document) invoice() (*bill.Invoice, error) {
+	inv := &bill.Invoice{
+		Addons: tax.WithAddons(en16931.V2017),
+		Code:   cbc.Code(d.Header.ID),
+	}
+	if d.Context != nil && d.Context.Guideline != nil &&
+		d.Context.Guideline.ID == convert.GuidelineXRechnung {
+		inv.Addons = tax.WithAddons(xrechnung.V3)
+	}
+	code := cbc.Code(d.Header.TypeCode)
+	inv.Type = convert.InvoiceType(code)
+	if code != cbc.CodeEmpty {
+		inv.Tax = &bill.Tax{
+			Ext: tax.Extensions{untdid.ExtKeyDocumentType: code},
+		}
+	}
+	date, err := parseDate(d.Header.IssueDateTime)
+	if err != nil {
+		return nil, fmt.Errorf("issue date: %w", err)
+	}
+	if date != nil {
+		inv.IssueDate = *date
+	}
+	for _, n := range d.Header.Notes {
+		note := &org.Note{Text: n.Content}
+		if n.SubjectCode != "" {
+			note.Ext = tax.Extensions{untdid.ExtKeyTextSubject: cbc.Code(n.SubjectCode)}
+		}
+		inv.Notes = append(inv.Notes, note)
+	}
+
+	tx := d.Transaction
+	if a := tx.Agreement; a != nil {
+		inv.Supplier = goblParty(a.Seller)
+		inv.Customer = goblParty(a.Buyer)
+		if inv.Ordering, err = goblOrdering(a); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Delivery != nil && tx.Delivery.Event != nil {
+		dd, err := parseDate(tx.Delivery.Event.OccurrenceDateTime)
+		if err != nil {
+			return nil, fmt.Errorf("delivery date: %w", err)
+		}
+		if dd != nil {
+			inv.Delivery = &bill.DeliveryDetails{Date: dd}
+		}
+	}
+	if s := tx.Settlement; s != nil {
+		if err := applySettlement(inv, s); err != nil {
+			return nil, err
+		}
+	}
+	for _, l := range tx.Lines {
+		line, err := goblLine(l)
+		if err != nil {
+			id := ""
+			if l.LineDoc != nil {
+				id = l.LineDoc.LineID
+			}
+			return nil, fmt.Errorf("line %s: %w", id, err)
+		}
+		inv.Lines = append(inv.Lines, line)
+	}
+	return inv, nil
+}
+
+func goblOrdering(a *Agreement) (*bill.Ordering, error) {
+	o := &bill.Ordering{Code: cbc.Code(a.BuyerReference)}
+	if a.OrderRef != nil && a.OrderRef.IssuerAssignedID != "" {
+		o.Purchases = []*org.DocumentRef{{Code: cbc.Code(a.OrderRef.IssuerAssignedID)}}
+	}
+	if ref, err := goblDocumentRef(a.ContractRef); err != nil {
+		return nil, fmt.Errorf("contract reference: %w", err)
+	} else if ref != nil {
+		o.Contracts = []*org.DocumentRef{ref}
+	}
+	if p := a.Project; p != nil && p.ID != "" {
+		ref := &org.DocumentRef{Code: cbc.Code(p.ID)}
+		if p.Name != p.ID {
+			ref.Description = p.Name
+		}
+		o.Projects = []*org.DocumentRef{ref}
+	}
+	if o.Code == cbc.CodeEmpty && o.Purchases == nil && o.Contracts == nil && o.Projects == nil {
+		return nil, nil
+	}
+	return o, nil
+}
+
+func applySettlement(inv *bill.Invoice, s *Settlement) error {
+	inv.Currency = currency.Code(s.Currency)
+	if s.Period != nil {
+		p, err := goblPeriod(s.Period)
+		if err != nil {
+			return fmt.Errorf("billing period: %w", err)
+		}
+		if p != nil {
+			if inv.Ordering == nil {
+				inv.Ordering = new(bill.Ordering)
+			}
+			inv.Ordering.Period = p
+		}
+	}
+	for _, r := range s.InvoiceRefs {
+		ref, err := goblDocumentRef(r)
+		if err != nil {
+			return fmt.Errorf("invoice reference: %w", err)
+		}
+		if ref != nil {
+			inv.Preceding = append(inv.Preceding, ref)
+		}
+	}
+	for _, ac := range s.AllowanceCharges {
+		if err := addAllowanceCharge(inv, ac); err != nil {
+			return err
+		}
+	}
+	p, err := goblPayment(s)
+	if err != nil {
+		return err
+	}
+	inv.Payment = p
+	if sum := s.Summation; sum != nil && sum.Rounding != "" {
+		r, err := convert.ParseAmount(sum.Rounding)
+		if err != nil {
+			return fmt.Errorf("rounding: %w", err)
+		}
+		inv.Totals = &bill.Totals{Rounding: &r}
+	}
+	return nil
+}
+
+func goblPayment(s *Settlement) (*bill.PaymentDetails, error) {
+	p := new(bill.PaymentDetails)
+	if pt := s.PaymentTerms; pt != nil {
+		due, err := parseDate(pt.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("due date: %w", err)
+		}
+		if due != nil || pt.Description != "" {
+			p.Terms = &pay.Terms{Notes: pt.Description}
+			if due != nil {
+				p.Terms.DueDates = []*pay.DueDate{
+					{Date: due, Percent: num.NewPercentage(100, 2)},
+				}
+			}
+		}
+	}
+	if len(s.PaymentMeans) > 0 {
+		p.Instructions = goblInstructions(s)
+	}
+	if sum := s.Summation; sum != nil && sum.Prepaid != "" {
+		a, err := convert.ParseAmount(sum.Prepaid)
+		if err != nil {
+			return nil, fmt.Errorf("prepaid amount: %w", err)
+		}
+		if !a.IsZero() {
+			p.Advances = []*pay.Advance{
+				{Description: "Prepaid amount", Amount: a},
+			}
+		}
+	}
+	if p.Terms == nil && p.Instructions == nil && p.Advances == nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+func goblInstructions(s *Settlement) *pay.Instructions {
+	pm := s.PaymentMeans[0]
+	code := cbc.Code(pm.TypeCode)
+	instr := &pay.Instructions{
+		Key:    convert.PaymentMeansKey(code),
+		Ref:    cbc.Code(s.PaymentReference),
+		Detail: pm.Information,
+	}
+	if code != cbc.CodeEmpty {
+		instr.Ext = tax.Extensions{untdid.ExtKeyPaymentMeans: code}
+	}
+	if c := pm.Card; c != nil {
+		instr.Card = &pay.Card{Last4: lastDigits(c.ID, 4), Holder: c.HolderName}
+	}
+	mandate := ""
+	if s.PaymentTerms != nil {
+		mandate = s.PaymentTerms.MandateID
+	}
+	if pm.PayerAccount != nil || mandate != "" || s.CreditorReferenceID != "" {
+		instr.DirectDebit = &pay.DirectDebit{
+			Ref:      mandate,
+			Creditor: s.CreditorReferenceID,
+		}
+		if pm.PayerAccount != nil {
+			instr.DirectDebit.Account = pm.PayerAccount.IBAN
+		}
+	}
+	for _, m := range s.PaymentMeans {
+		fa := m.PayeeAccount
+		if fa == nil {
+			continue
+		}
+		ct := &pay.CreditTransfer{
+			IBAN:   fa.IBAN,
+			Number: fa.ProprietaryID,
+			Name:   fa.AccountName,
+		}
+		if m.PayeeBank != nil {
+			ct.BIC = m.PayeeBank.BIC
+		}
+		instr.CreditTransfer = append(instr.CreditTransfer, ct)
+	}
+	return instr
+}
+
+func addAllowanceCharge(inv *bill.Invoice, ac *AllowanceCharge) error {
+	amount, base, percent, err := parseAllowanceCharge(ac)
+	if err != nil {
+		return err
+	}
+	var taxes tax.Set
+	if ac.Tax != nil {
+		c, err := goblTaxCombo(ac.Tax)
+		if err != nil {
+			return fmt.Errorf("allowance or charge tax: %w", err)
+		}
+		if c != nil {
+			taxes = tax.Set{c}
+		}
+	}
+	if isCharge(ac) {
+		c := &bill.Charge{
+			Reason:  ac.Reason,
+			Amount:  amount,
+			Base:    base,
+			Percent: percent,
+			Taxes:   taxes,
+		}
+		if ac.ReasonCode != "" {
+			c.Ext = tax.Extensions{untdid.ExtKeyCharge: cbc.Code(ac.ReasonCode)}
+		}
+		inv.Charges = append(inv.Charges, c)
+		return nil
+	}
+	dc := &bill.Discount{
+		Reason:  ac.Reason,
+		Amount:  amount,
+		Base:    base,
+		Percent: percent,
+		Taxes:   taxes,
+	}
+	if ac.ReasonCode != "" {
+		dc.Ext = tax.Extensions{untdid.ExtKeyAllowance: cbc.Code(ac.ReasonCode)}
+	}
+	inv.Discounts = append(inv.Discounts, dc)
+	return nil
+}
+
+func isCharge(ac *AllowanceCharge) bool {
+	return ac.ChargeIndicator != nil && ac.ChargeIndicator.Value
+}
+
+func parseAllowanceCharge(ac *AllowanceCharge) (num.Amount, *num.Amount, *num.Percentage, error) {
+	amount, err := convert.ParseAmount(ac.ActualAmount)
+	if err != nil {
+		return amount, nil, nil, fmt.Errorf("allowance or charge: %w", err)
+	}
+	var base *num.Amount
+	if ac.BasisAmount != "" {
+		b, err := convert.ParseAmount(ac.BasisAmount)
+		if err != nil {
+			return amount, nil, nil, fmt.Errorf("allowance or charge base: %w", err)
+		}
+		base = &b
+	}
+	percent, err := convert.ParsePercent(ac.Percent)
+	if err != nil {
+		return amount, nil, nil, fmt.Errorf("allowance or charge: %w", err)
+	}
+	return amount, base, percent, nil
+}
+
+func goblTaxCombo(tt *TradeTax) (*tax.Combo, error) {
+	if tt == nil || tt.TypeCode != taxTypeVAT {
+		return nil, nil
+	}
+	return convert.VATCombo(cbc.Code(tt.CategoryCode), tt.RatePercent, cbc.Code(tt.ExemptionReasonCode))
+}
+
+func goblLine(l *Line) (*bill.Line, error) {
+	line := &bill.Line{Item: new(org.Item)}
+	if ld := l.LineDoc; ld != nil && ld.Note != nil {
+		line.Notes = []*org.Note{{Text: ld.Note.Content}}
+	}
+	if p := l.Product; p != nil {
+		line.Item.Name = p.Name
+		line.Item.Description = p.Description
+		line.Item.Ref = cbc.Code(p.SellerID)
+		if p.GlobalID != nil && p.GlobalID.Value != "" {
+			line.Item.Identities = []*org.Identity{goblIdentity(p.GlobalID)}
+		}
+		if p.OriginCountry != nil {
+			line.Item.Origin = l10n.ISOCountryCode(p.OriginCountry.ID)
+		}
+	}
+	if d := l.Delivery; d != nil && d.BilledQuantity != nil {
+		q, err := convert.ParseAmount(d.BilledQuantity.Value)
+		if err != nil {
+			return nil, fmt.Errorf("quantity: %w", err)
+		}
+		line.Quantity = q
+		line.Item.Unit = convert.UnitFromUNECE(d.BilledQuantity.UnitCode)
+	}
+	if a := l.Agreement; a != nil {
+		if a.OrderRef != nil {
+			line.Order = cbc.Code(a.OrderRef.LineID)
+		}
+		if np := a.NetPrice; np != nil && np.ChargeAmount != "" {
+			p, err := convert.ParseAmount(np.ChargeAmount)
+			if err != nil {
+				return nil, fmt.Errorf("price: %w", err)
+			}
+			if bq := np.BasisQuantity; bq != nil && bq.Value != "" {
+				// prices may be provided for a base quantity other than 1
+				b, err := convert.ParseAmount(bq.Value)
+				if err != nil {
+					return nil, fmt.Errorf("basis quantity: %w", err)
+				}
+				if !b.IsZero() {
+					p = p.Divide(b)
+				}
+			}
+			line.Item.Price = &p
+		}
+	}
+	if s := l.Settlement; s != nil {
+		c, err := goblTaxCombo(s.Tax)
+		if err != nil {
+			return nil, fmt.Errorf("tax: %w", err)
+		}
+		if c != nil {
+			line.Taxes = tax.Set{c}
+		}
+		if s.Period != nil {
+			if line.Period, err = goblPeriod(s.Period); err != nil {
+				return nil, fmt.Errorf("period: %w", err)
+			}
+		}
+		if s.AccountingCost != nil {
+			line.Cost = cbc.Code(s.AccountingCost.ID)
+		}
+		for _, ac := range s.AllowanceCharges {
+			amount, base, percent, err := parseAllowanceCharge(ac)
+			if err != nil {
+				return nil, err
+			}
+			if isCharge(ac) {
+				lc := &bill.LineCharge{
+					Reason:  ac.Reason,
+					Amount:  amount,
+					Base:    base,
+					Percent: percent,
+				}
+				if ac.ReasonCode != "" {
+					lc.Ext = tax.Extensions{untdid.ExtKeyCharge: cbc.Code(ac.ReasonCode)}
+				}
+				line.Charges = append(line.Charges, lc)
+				continue
+			}
+			ld := &bill.LineDiscount{
+				Reason:  ac.Reason,
+				Amount:  amount,
+				Base:    base,
+				Percent: percent,
+			}
+			if ac.ReasonCode != "" {
+				ld.Ext = tax.Extensions{untdid.ExtKeyAllowance: cbc.Code(ac.ReasonCode)}
+			}
+			line.Discounts = append(line.Discounts, ld)
+		}
+	}
+	return line, nil
+}
+
+func goblParty(p *Party) *org.Party {
+	if p == nil || (p.Name == "" && p.Address == nil && len(p.TaxRegistrations) == 0) {
+		return nil
+	}
+	party := &org.Party{Name: p.Name}
+	for _, id := range p.IDs {
+		party.Identities = append(party.Identities, &org.Identity{Code: cbc.Code(id)})
+	}
+	for _, id := range p.GlobalIDs {
+		party.Identities = append(party.Identities, goblIdentity(id))
+	}
+	if lo := p.LegalOrganization; lo != nil {
+		party.Alias = lo.TradingBusinessName
+	}
+	if c := p.Contact; c != nil {
+		if c.PersonName != "" {
+			party.People = []*org.Person{{Name: &org.Name{Given: c.PersonName}}}
+		}
+		if c.Telephone != nil && c.Telephone.Number != "" {
+			party.Telephones = []*org.Telephone{{Number: c.Telephone.Number}}
+		}
+		if c.Email != nil && c.Email.URIID != "" {
+			party.Emails = []*org.Email{{Address: c.Email.URIID}}
+		}
+	}
+	var country l10n.TaxCountryCode
+	if a := p.Address; a != nil {
+		country = l10n.TaxCountryCode(a.CountryID)
+		if a.LineOne != "" || a.City != "" || a.Postcode != "" {
+			party.Addresses = []*org.Address{{
+				Street:      a.LineOne,
+				StreetExtra: a.LineTwo,
+				Locality:    a.City,
+				Code:        cbc.Code(a.Postcode),
+				Region:      a.Subdivision,
+				Country:     l10n.ISOCountryCode(a.CountryID),
+			}}
+		}
+	}
+	if u := p.URI; u != nil && u.ID != nil && u.ID.Value != "" {
+		if u.ID.SchemeID == uriSchemeEmail {
+			party.Inboxes = []*org.Inbox{{Email: u.ID.Value}}
+		} else if iso.SchemeDefFor(cbc.Code(u.ID.SchemeID)) != nil {
+			party.Endpoint = cbc.NewSchemeCode(cbc.Code(u.ID.SchemeID), cbc.Code(u.ID.Value))
+		} else {
+			party.Inboxes = []*org.Inbox{{
+				Scheme: cbc.Code(u.ID.SchemeID),
+				Code:   cbc.Code(u.ID.Value),
+			}}
+		}
+	}
+	for _, tr := range p.TaxRegistrations {
+		if tr.ID == nil || tr.ID.Value == "" {
+			continue
+		}
+		if tr.ID.SchemeID == taxSchemeVAT {
+			party.TaxID = convert.ParseTaxIdentity(tr.ID.Value, country)
+			break
+		}
+		if party.TaxID == nil {
+			party.TaxID = &tax.Identity{Country: country, Code: cbc.Code(tr.ID.Value)}
+		}
+	}
+	if party.TaxID == nil && country != "" {
+		party.TaxID = &tax.Identity{Country: country}
+	}
+	return party
+}
+
+func goblIdentity(id *Identifier) *org.Identity {
+	oid := &org.Identity{Code: cbc.Code(id.Value)}
+	if id.SchemeID != "" {
+		oid.Ext = tax.Extensions{iso.ExtKeySchemeID: cbc.Code(id.SchemeID)}
+	}
+	return oid
+}
+
+func goblDocumentRef(ref *DocumentRef) (*org.DocumentRef, error) {
+	if ref == nil || ref.IssuerAssignedID == "" {
+		return nil, nil
+	}
+	dr := &org.DocumentRef{Code: cbc.Code(ref.IssuerAssignedID)}
+	if ref.IssueDate != nil && ref.IssueDate.DateString != nil {
+		d, err := convert.ParseDate(ref.IssueDate.DateString.Value)
+		if err != nil {
+			return nil, err
+		}
+		dr.IssueDate = d
+	}
+	return dr, nil
+}
+
+func goblPeriod(p *Period) (*cal.Period, error) {
+	start, err := parseDate(p.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(p.End)
+	if err != nil {
+		return nil, err
+	}
+	if start == nil || end == nil {
+		return nil, nil
+	}
+	return &cal.Period{Start: *start, End: *end}, nil
+}
+
+func parseDate(d *Date) (*cal.Date, error) {
+	if d == nil || d.DateString == nil {
+		return nil, nil
+	}
+	if f := d.DateString.Format; f != "" && f != dateFormat {
+		return nil, fmt.Errorf("unsupported date format '%s'", f)
+	}
+	return convert.ParseDate(d.DateString.Value)
+}
+
+func lastDigits(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
@@ -0,0 +1,305 @@
+// Package convert contains the common tools used to convert GOBL documents
+// to and from other structured formats. Each format is implemented in its own
+// sub-package, such as `convert/ubl`, and will normally rely on the EN 16931
+// semantic model and its UNTDID code lists as the common ground between
+// formats.
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/civil"
+	"github.com/invopop/gobl/addons/de/xrechnung"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/cef"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// Common errors returned by converters.
+var (
+	// ErrNotCalculated is returned when trying to export an invoice whose
+	// totals have not been calculated.
+	ErrNotCalculated = errors.New("invoice must be calculated")
+	// ErrUnsupported is returned when the source document uses a
+	// feature or type that cannot be represented in the target format.
+	ErrUnsupported = errors.New("unsupported")
+)
+
+// Report lists the parts of a source document that could not be mapped
+// into GOBL during an import, so that they can be reviewed instead of
+// being silently dropped.
+type Report struct {
+	// Unmapped data found in the source document.
+	Unmapped []*Unmapped `json:"unmapped,omitempty"`
+}
+
+// Unmapped describes a single part of the source document that was not
+// mapped.
+type Unmapped struct {
+	// Location of the data in the source document, such as a segment
+	// position or element path.
+	Location string `json:"location"`
+	// Content found at the location, as it was received.
+	Content string `json:"content,omitempty"`
+}
+
+// Add records the content found at the location as unmapped.
+func (r *Report) Add(location, content string) {
+	r.Unmapped = append(r.Unmapped, &Unmapped{Location: location, Content: content})
+}
+
+// Empty returns true if all of the source document was mapped.
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Unmapped) == 0
+}
+
+// Specification identifiers (BT-24) used to indicate the rules an
+// invoice complies with.
+const (
+	GuidelineEN16931   = "urn:cen.eu:en16931:2017"
+	GuidelineXRechnung = "urn:cen.eu:en16931:2017#compliant#urn:xeinkauf.de:kosit:xrechnung_3.0"
+)
+
+// UNTDID 5305 tax category codes as used by EN 16931.
+const (
+	TaxCategoryStandard       cbc.Code = "S"
+	TaxCategoryZero           cbc.Code = "Z"
+	TaxCategoryExempt         cbc.Code = "E"
+	TaxCategoryReverseCharge  cbc.Code = "AE"
+	TaxCategoryIntraCommunity cbc.Code = "K"
+	TaxCategoryExport         cbc.Code = "G"
+	TaxCategoryOutsideScope   cbc.Code = "O"
+)
+
+var taxCategoryKeys = tax.Extensions{
+	tax.KeyStandard:       TaxCategoryStandard,
+	tax.KeyZero:           TaxCategoryZero,
+	tax.KeyExempt:         TaxCategoryExempt,
+	tax.KeyReverseCharge:  TaxCategoryReverseCharge,
+	tax.KeyIntraCommunity: TaxCategoryIntraCommunity,
+	tax.KeyExport:         TaxCategoryExport,
+	tax.KeyOutsideScope:   TaxCategoryOutsideScope,
+}
+
+// GuidelineID provides the specification identifier for the invoice
+// according to its addons, defaulting to plain EN 16931.
+func GuidelineID(inv *bill.Invoice) string {
+	for _, k := range inv.GetAddons() {
+		if k == xrechnung.V3 {
+			return GuidelineXRechnung
+		}
+	}
+	return GuidelineEN16931
+}
+
+// DocumentTypeCode provides the UNTDID 1001 code for the invoice, using
+// the tax extensions if available, or the type's default code.
+func DocumentTypeCode(inv *bill.Invoice) cbc.Code {
+	if inv.Tax != nil {
+		if c := inv.Tax.Ext.Get(untdid.ExtKeyDocumentType); c != cbc.CodeEmpty {
+			return c
+		}
+	}
+	return inv.UNTDID1001()
+}
+
+// InvoiceType provides the invoice type key that best matches the UNTDID
+// 1001 code, defaulting to a standard invoice.
+func InvoiceType(code cbc.Code) cbc.Key {
+	switch code {
+	case "381", "396", "532":
+		return bill.InvoiceTypeCreditNote
+	case "383", "527":
+		return bill.InvoiceTypeDebitNote
+	case "384", "471", "472", "473":
+		return bill.InvoiceTypeCorrective
+	case "325":
+		return bill.InvoiceTypeProforma
+	}
+	return bill.InvoiceTypeStandard
+}
+
+// TaxCategoryCode provides the UNTDID 5305 code for the tax category,
+// key, and extensions of a tax combo or rate total. Extensions take
+// priority, followed by the VAT key. Categories other than VAT are
+// assumed to be outside the scope.
+func TaxCategoryCode(cat cbc.Code, key cbc.Key, ext tax.Extensions) cbc.Code {
+	if c := ext.Get(untdid.ExtKeyTaxCategory); c != cbc.CodeEmpty {
+		return c
+	}
+	if cat != tax.CategoryVAT {
+		return TaxCategoryOutsideScope
+	}
+	if key == cbc.KeyEmpty {
+		return TaxCategoryStandard
+	}
+	if c := taxCategoryKeys.Get(key); c != cbc.CodeEmpty {
+		return c
+	}
+	return TaxCategoryStandard
+}
+
+// TaxKey provides the GOBL VAT key for the UNTDID 5305 tax category code.
+func TaxKey(code cbc.Code) cbc.Key {
+	return taxCategoryKeys.Lookup(code)
+}
+
+// VATCombo builds a VAT tax combo from the UNTDID 5305 category code,
+// rate percentage, and VATEX exemption reason code. Only the standard
+// category will include a percentage.
+func VATCombo(code cbc.Code, percent string, vatex cbc.Code) (*tax.Combo, error) {
+	if code == cbc.CodeEmpty {
+		return nil, nil
+	}
+	c := &tax.Combo{
+		Category: tax.CategoryVAT,
+		Key:      TaxKey(code),
+		Ext:      tax.Extensions{untdid.ExtKeyTaxCategory: code},
+	}
+	if code == TaxCategoryStandard {
+		p, err := ParsePercent(percent)
+		if err != nil {
+			return nil, err
+		}
+		c.Percent = p
+	}
+	if vatex != cbc.CodeEmpty {
+		c.Ext = c.Ext.Set(cef.ExtKeyVATEX, vatex)
+	}
+	return c, nil
+}
+
+// PaymentMeansCode provides the UNTDID 4461 code for the payment
+// instructions, using the extensions if available.
+func PaymentMeansCode(instr *pay.Instructions) cbc.Code {
+	if instr == nil {
+		return cbc.CodeEmpty
+	}
+	if c := instr.Ext.Get(untdid.ExtKeyPaymentMeans); c != cbc.CodeEmpty {
+		return c
+	}
+	return pay.UNTDID4461.Code(instr.Key)
+}
+
+// PaymentMeansKey provides the payment means key for the UNTDID 4461 code,
+// falling back to "any" when the code is unknown.
+func PaymentMeansKey(code cbc.Code) cbc.Key {
+	if k := pay.UNTDID4461.Key(code); k != cbc.KeyEmpty {
+		return k
+	}
+	return pay.MeansKeyAny
+}
+
+// ParseDate parses an ISO 8601 date in the `YYYY-MM-DD` format, or the
+// compact `YYYYMMDD` format, returning nil if the string is empty.
+func ParseDate(s string) (*cal.Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if len(s) == 8 && !strings.Contains(s, "-") {
+		s = s[:4] + "-" + s[4:6] + "-" + s[6:]
+	}
+	d, err := civil.ParseDate(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date '%s'", s)
+	}
+	return &cal.Date{Date: d}, nil
+}
+
+// ParseAmount parses a decimal amount, returning zero if the string
+// is empty.
+func ParseAmount(s string) (num.Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return num.AmountZero, nil
+	}
+	a, err := num.AmountFromString(s)
+	if err != nil {
+		return a, fmt.Errorf("invalid amount '%s'", s)
+	}
+	return a, nil
+}
+
+// ParsePercent parses a numeric percentage value where "21" implies
+// 21%, returning nil if the string is empty.
+func ParsePercent(s string) (*num.Percentage, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	p, err := num.PercentageFromString(s + "%")
+	if err != nil {
+		return nil, fmt.Errorf("invalid percent '%s'", s)
+	}
+	return &p, nil
+}
+
+// IsIBAN returns true if the account number looks like an IBAN, with a
+// country code and check digits, as opposed to a local account number.
+func IsIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	return s[0] >= 'A' && s[0] <= 'Z' && s[1] >= 'A' && s[1] <= 'Z' &&
+		s[2] >= '0' && s[2] <= '9' && s[3] >= '0' && s[3] <= '9'
+}
+
+// UnitFromUNECE provides the GOBL unit for the UN/ECE code, or the code
+// itself if there is no equivalent.
+func UnitFromUNECE(code string) org.Unit {
+	if code == "" {
+		return org.UnitEmpty
+	}
+	for _, d := range org.UnitDefinitions {
+		if d.UNECE.String() == code {
+			return d.Unit
+		}
+	}
+	return org.Unit(code)
+}
+
+// TaxIdentityCode provides the tax identity with the country prefix used
+// for VAT numbers in the EU, or an empty string if there is no code.
+func TaxIdentityCode(id *tax.Identity) string {
+	if id == nil || id.Code == cbc.CodeEmpty {
+		return ""
+	}
+	c := id.Country.String()
+	if c == "GR" {
+		c = "EL" // Greece uses ISO 639-1 code
+	}
+	return c + id.Code.String()
+}
+
+// ParseTaxIdentity builds a tax identity from a VAT code prefixed with
+// the country code. If no valid prefix is found, the default country will
+// be used.
+func ParseTaxIdentity(code string, def l10n.TaxCountryCode) *tax.Identity {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+	id := &tax.Identity{Country: def, Code: cbc.Code(code)}
+	if len(code) > 2 {
+		p := code[:2]
+		if p == "EL" {
+			p = "GR"
+		}
+		if l10n.Countries().Code(l10n.Code(p)) != nil {
+			id.Country = l10n.TaxCountryCode(p)
+			id.Code = cbc.Code(code[2:])
+		}
+	}
+	return id
+}
@@ -0,0 +1,144 @@
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/addons/de/xrechnung"
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/cef"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	var r *convert.Report
+	assert.True(t, r.Empty())
+	r = new(convert.Report)
+	assert.True(t, r.Empty())
+	r.Add("12", "FTX+ZZZ+++Note")
+	assert.False(t, r.Empty())
+	require.Len(t, r.Unmapped, 1)
+	assert.Equal(t, "12", r.Unmapped[0].Location)
+	assert.Equal(t, "FTX+ZZZ+++Note", r.Unmapped[0].Content)
+}
+
+func TestDocumentTypeCode(t *testing.T) {
+	inv := &bill.Invoice{Type: bill.InvoiceTypeCreditNote}
+	assert.Equal(t, cbc.Code("381"), convert.DocumentTypeCode(inv))
+	inv.Tax = &bill.Tax{Ext: tax.Extensions{untdid.ExtKeyDocumentType: "396"}}
+	assert.Equal(t, cbc.Code("396"), convert.DocumentTypeCode(inv))
+}
+
+func TestInvoiceType(t *testing.T) {
+	assert.Equal(t, bill.InvoiceTypeCreditNote, convert.InvoiceType("381"))
+	assert.Equal(t, bill.InvoiceTypeDebitNote, convert.InvoiceType("383"))
+	assert.Equal(t, bill.InvoiceTypeCorrective, convert.InvoiceType("384"))
+	assert.Equal(t, bill.InvoiceTypeStandard, convert.InvoiceType("380"))
+	assert.Equal(t, bill.InvoiceTypeStandard, convert.InvoiceType(""))
+}
+
+func TestTaxCategoryCode(t *testing.T) {
+	assert.Equal(t, convert.TaxCategoryStandard, convert.TaxCategoryCode(tax.CategoryVAT, "", nil))
+	assert.Equal(t, convert.TaxCategoryExempt, convert.TaxCategoryCode(tax.CategoryVAT, tax.KeyExempt, nil))
+	assert.Equal(t, convert.TaxCategoryOutsideScope, convert.TaxCategoryCode("IRPF", "", nil))
+	ext := tax.Extensions{untdid.ExtKeyTaxCategory: "AE"}
+	assert.Equal(t, convert.TaxCategoryReverseCharge, convert.TaxCategoryCode(tax.CategoryVAT, tax.KeyStandard, ext))
+	assert.Equal(t, tax.KeyReverseCharge, convert.TaxKey("AE"))
+	assert.Equal(t, cbc.KeyEmpty, convert.TaxKey("X"))
+}
+
+func TestPaymentMeans(t *testing.T) {
+	instr := &pay.Instructions{Key: pay.MeansKeyCreditTransfer.With(pay.MeansKeySEPA)}
+	assert.Equal(t, cbc.Code("58"), convert.PaymentMeansCode(instr))
+	instr.Ext = tax.Extensions{untdid.ExtKeyPaymentMeans: "30"}
+	assert.Equal(t, cbc.Code("30"), convert.PaymentMeansCode(instr))
+	assert.Equal(t, cbc.CodeEmpty, convert.PaymentMeansCode(nil))
+	assert.Equal(t, pay.MeansKeyCreditTransfer, convert.PaymentMeansKey("30"))
+	assert.Equal(t, pay.MeansKeyAny, convert.PaymentMeansKey("XX"))
+}
+
+func TestParseDate(t *testing.T) {
+	d, err := convert.ParseDate("2024-02-13")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-13", d.String())
+	d, err = convert.ParseDate("20240213")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-13", d.String())
+	d, err = convert.ParseDate("")
+	assert.NoError(t, err)
+	assert.Nil(t, d)
+	_, err = convert.ParseDate("13.02.2024")
+	assert.ErrorContains(t, err, "invalid date '13.02.2024'")
+}
+
+func TestParseAmountAndPercent(t *testing.T) {
+	a, err := convert.ParseAmount(" 12.50 ")
+	require.NoError(t, err)
+	assert.Equal(t, "12.50", a.String())
+	_, err = convert.ParseAmount("12,50")
+	assert.ErrorContains(t, err, "invalid amount")
+	p, err := convert.ParsePercent("21")
+	require.NoError(t, err)
+	assert.Equal(t, "21%", p.String())
+	p, err = convert.ParsePercent("")
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestUnitFromUNECE(t *testing.T) {
+	assert.Equal(t, org.UnitHour, convert.UnitFromUNECE("HUR"))
+	assert.Equal(t, org.Unit("ZZZ"), convert.UnitFromUNECE("ZZZ"))
+	assert.Equal(t, org.UnitEmpty, convert.UnitFromUNECE(""))
+}
+
+func TestTaxIdentity(t *testing.T) {
+	assert.Equal(t, "EL123456789", convert.TaxIdentityCode(&tax.Identity{Country: "GR", Code: "123456789"}))
+	assert.Empty(t, convert.TaxIdentityCode(&tax.Identity{Country: "ES"}))
+
+	id := convert.ParseTaxIdentity("el123456789", "")
+	assert.Equal(t, l10n.TaxCountryCode("GR"), id.Country)
+	assert.Equal(t, cbc.Code("123456789"), id.Code)
+	id = convert.ParseTaxIdentity("123456789", "US")
+	assert.Equal(t, l10n.TaxCountryCode("US"), id.Country)
+	assert.Equal(t, cbc.Code("123456789"), id.Code)
+	assert.Nil(t, convert.ParseTaxIdentity(" ", "ES"))
+}
+
+func TestGuidelineID(t *testing.T) {
+	inv := &bill.Invoice{Addons: tax.WithAddons(en16931.V2017)}
+	assert.Equal(t, convert.GuidelineEN16931, convert.GuidelineID(inv))
+	inv.Addons = tax.WithAddons(xrechnung.V3)
+	assert.Equal(t, convert.GuidelineXRechnung, convert.GuidelineID(inv))
+}
+
+func TestVATCombo(t *testing.T) {
+	c, err := convert.VATCombo("S", "21", "")
+	require.NoError(t, err)
+	assert.Equal(t, tax.KeyStandard, c.Key)
+	assert.Equal(t, "21%", c.Percent.String())
+	c, err = convert.VATCombo("E", "0", "VATEX-EU-132")
+	require.NoError(t, err)
+	assert.Equal(t, tax.KeyExempt, c.Key)
+	assert.Nil(t, c.Percent)
+	assert.Equal(t, cbc.Code("VATEX-EU-132"), c.Ext.Get(cef.ExtKeyVATEX))
+	c, err = convert.VATCombo("", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+	_, err = convert.VATCombo("S", "x", "")
+	assert.ErrorContains(t, err, "invalid percent 'x'")
+}
+
+func TestIsIBAN(t *testing.T) {
+	assert.True(t, convert.IsIBAN("DE89370400440532013000"))
+	assert.True(t, convert.IsIBAN("DE89 3704 0044 0532 0130 00"))
+	assert.False(t, convert.IsIBAN("0532013000"))
+	assert.False(t, convert.IsIBAN("12345678901234567"))
+}
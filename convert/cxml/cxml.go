@@ -0,0 +1,41 @@
+// Package cxml imports cXML InvoiceDetailRequest documents, used by
+// procurement networks such as SAP Ariba and Coupa, into GOBL invoices.
+//
+// Supplier and customer details are taken from the invoice partners with
+// the "issuerOfInvoice" or "from", and "soldTo" or "billTo" roles. The
+// "remitTo" partner's bank details are used for the payment instructions.
+// Elements that are not mapped, including any extrinsic data, are listed
+// in a report returned alongside the invoice, identified by their path in
+// the document.
+package cxml
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/observe"
+)
+
+// Parse decodes the cXML document.
+func Parse(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := xml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("parsing cXML: %w", err)
+	}
+	return doc, nil
+}
+
+// ConvertInvoice is a convenience method to parse the cXML data and
+// convert it into a GOBL invoice.
+func ConvertInvoice(data []byte) (inv *bill.Invoice, rep *convert.Report, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpImport, "cxml")
+	defer func() { s.End(inv, err) }()
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc.ToInvoice()
+}
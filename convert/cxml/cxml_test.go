@@ -0,0 +1,243 @@
+package cxml_test
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/cxml"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvoice = `<?xml version="1.0" encoding="UTF-8"?>
+<cXML payloadID="1717236000.42@supplier.example" timestamp="2024-06-01T12:00:00+02:00">
+  <Header>
+    <From><Credential domain="NetworkID"><Identity>AN01000000001</Identity></Credential></From>
+  </Header>
+  <Request deploymentMode="production">
+    <InvoiceDetailRequest>
+      <InvoiceDetailRequestHeader invoiceID="INV-2024-001" purpose="standard" operation="new" invoiceDate="2024-06-01T12:00:00+02:00">
+        <InvoiceDetailHeaderIndicator/>
+        <InvoiceDetailLineIndicator isTaxInLine="yes"/>
+        <InvoicePartner>
+          <Contact role="issuerOfInvoice">
+            <Name xml:lang="de">Lieferant GmbH</Name>
+            <PostalAddress>
+              <Street>Hauptstraße 1</Street>
+              <City>Berlin</City>
+              <PostalCode>10115</PostalCode>
+              <Country isoCountryCode="DE">Germany</Country>
+            </PostalAddress>
+            <Email>billing@lieferant.example</Email>
+          </Contact>
+          <IdReference identifier="DE111111125" domain="vatID"/>
+        </InvoicePartner>
+        <InvoicePartner>
+          <Contact role="soldTo">
+            <Name xml:lang="de">Kunde AG</Name>
+            <PostalAddress>
+              <Street>Marktplatz 5</Street>
+              <City>München</City>
+              <PostalCode>80331</PostalCode>
+              <Country isoCountryCode="DE">Germany</Country>
+            </PostalAddress>
+          </Contact>
+          <IdReference identifier="DE282741168" domain="vatID"/>
+          <IdReference identifier="4000001000002" domain="gln"/>
+        </InvoicePartner>
+        <InvoicePartner>
+          <Contact role="remitTo">
+            <Name xml:lang="de">Lieferant GmbH</Name>
+          </Contact>
+          <IdReference identifier="DE89370400440532013000" domain="ibanID"/>
+          <IdReference identifier="COBADEFFXXX" domain="swiftID"/>
+        </InvoicePartner>
+        <InvoicePartner>
+          <Contact role="shipFrom">
+            <Name xml:lang="de">Lager Nord</Name>
+          </Contact>
+        </InvoicePartner>
+        <PaymentTerm payInNumberOfDays="30"/>
+        <Comments xml:lang="de">Vielen Dank</Comments>
+        <Extrinsic name="CostCenter">CC-42</Extrinsic>
+      </InvoiceDetailRequestHeader>
+      <InvoiceDetailOrder>
+        <InvoiceDetailOrderInfo>
+          <OrderReference orderID="PO-998" orderDate="2024-05-15">
+            <DocumentReference payloadID="po-998@buyer.example"/>
+          </OrderReference>
+        </InvoiceDetailOrderInfo>
+        <InvoiceDetailItem invoiceLineNumber="1" quantity="10">
+          <UnitOfMeasure>EA</UnitOfMeasure>
+          <UnitPrice><Money currency="EUR">100.00</Money></UnitPrice>
+          <InvoiceDetailItemReference lineNumber="1">
+            <ItemID><SupplierPartID>WID-1</SupplierPartID></ItemID>
+            <Description xml:lang="en">Widget</Description>
+            <Classification domain="UNSPSC">43211503</Classification>
+          </InvoiceDetailItemReference>
+          <SubtotalAmount><Money currency="EUR">1000.00</Money></SubtotalAmount>
+          <Tax>
+            <Money currency="EUR">190.00</Money>
+            <Description xml:lang="en">VAT</Description>
+            <TaxDetail purpose="tax" category="vat" percentageRate="19">
+              <TaxableAmount><Money currency="EUR">1000.00</Money></TaxableAmount>
+              <TaxAmount><Money currency="EUR">190.00</Money></TaxAmount>
+            </TaxDetail>
+          </Tax>
+          <InvoiceDetailDiscount percentageRate="10">
+            <Money currency="EUR">100.00</Money>
+          </InvoiceDetailDiscount>
+        </InvoiceDetailItem>
+        <InvoiceDetailServiceItem invoiceLineNumber="2" quantity="2">
+          <InvoiceDetailServiceItemReference lineNumber="2">
+            <Description xml:lang="en">Installation</Description>
+          </InvoiceDetailServiceItemReference>
+          <SubtotalAmount><Money currency="EUR">300.00</Money></SubtotalAmount>
+          <UnitOfMeasure>HUR</UnitOfMeasure>
+          <UnitPrice><Money currency="EUR">150.00</Money></UnitPrice>
+          <Tax>
+            <Money currency="EUR">57.00</Money>
+            <Description xml:lang="en">VAT</Description>
+            <TaxDetail purpose="tax" category="vat" percentageRate="19"/>
+          </Tax>
+          <Extrinsic name="ProjectCode">P-7</Extrinsic>
+        </InvoiceDetailServiceItem>
+      </InvoiceDetailOrder>
+      <InvoiceDetailSummary>
+        <SubtotalAmount><Money currency="EUR">1200.00</Money></SubtotalAmount>
+        <Tax>
+          <Money currency="EUR">237.50</Money>
+          <Description xml:lang="en">VAT</Description>
+        </Tax>
+        <ShippingAmount><Money currency="EUR">50.00</Money></ShippingAmount>
+        <GrossAmount><Money currency="EUR">1487.50</Money></GrossAmount>
+        <NetAmount><Money currency="EUR">1487.50</Money></NetAmount>
+        <DepositAmount><Money currency="EUR">100.00</Money></DepositAmount>
+        <DueAmount><Money currency="EUR">1387.50</Money></DueAmount>
+      </InvoiceDetailSummary>
+    </InvoiceDetailRequest>
+  </Request>
+</cXML>`
+
+func TestConvertInvoice(t *testing.T) {
+	inv, rep, err := cxml.ConvertInvoice([]byte(testInvoice))
+	require.NoError(t, err)
+
+	assert.Equal(t, cbc.Code("INV-2024-001"), inv.Code)
+	assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+	assert.Equal(t, "2024-06-01", inv.IssueDate.String())
+	assert.Equal(t, "EUR", inv.Currency.String())
+	require.Len(t, inv.Notes, 1)
+	assert.Equal(t, "Vielen Dank", inv.Notes[0].Text)
+
+	assert.Equal(t, "Lieferant GmbH", inv.Supplier.Name)
+	assert.Equal(t, cbc.Code("111111125"), inv.Supplier.TaxID.Code)
+	assert.Equal(t, "Berlin", inv.Supplier.Addresses[0].Locality)
+	assert.Equal(t, "billing@lieferant.example", inv.Supplier.Emails[0].Address)
+	assert.Equal(t, "Kunde AG", inv.Customer.Name)
+	assert.Equal(t, cbc.Code("282741168"), inv.Customer.TaxID.Code)
+	require.Len(t, inv.Customer.Identities, 1)
+	assert.Equal(t, "gln", inv.Customer.Identities[0].Label)
+
+	require.NotNil(t, inv.Ordering)
+	assert.Equal(t, cbc.Code("PO-998"), inv.Ordering.Purchases[0].Code)
+	assert.Equal(t, "2024-05-15", inv.Ordering.Purchases[0].IssueDate.String())
+
+	require.Len(t, inv.Lines, 2)
+	l := inv.Lines[0]
+	assert.Equal(t, "Widget", l.Item.Name)
+	assert.Equal(t, cbc.Code("WID-1"), l.Item.Ref)
+	assert.Equal(t, cbc.Code("1"), l.Order)
+	assert.Equal(t, "900.00", l.Total.String())
+	assert.Equal(t, "19%", l.Taxes[0].Percent.String())
+	assert.Equal(t, "Installation", inv.Lines[1].Item.Name)
+	assert.Equal(t, "300.00", inv.Lines[1].Total.String())
+
+	require.Len(t, inv.Charges, 1)
+	assert.Equal(t, bill.ChargeKeyDelivery, inv.Charges[0].Key)
+	assert.Equal(t, "1200.00", inv.Totals.Sum.String())
+	assert.Equal(t, "50.00", inv.Totals.Charge.String())
+	assert.Equal(t, "237.50", inv.Totals.Tax.String())
+	assert.Equal(t, "1487.50", inv.Totals.Payable.String())
+	assert.Equal(t, "1387.50", inv.Totals.Due.String())
+
+	require.NotNil(t, inv.Payment)
+	assert.Nil(t, inv.Payment.Payee, "same name as supplier")
+	assert.Equal(t, pay.MeansKeyCreditTransfer, inv.Payment.Instructions.Key)
+	assert.Equal(t, "DE89370400440532013000", inv.Payment.Instructions.CreditTransfer[0].IBAN)
+	assert.Equal(t, "COBADEFFXXX", inv.Payment.Instructions.CreditTransfer[0].BIC)
+	assert.Equal(t, "2024-07-01", inv.Payment.Terms.DueDates[0].Date.String())
+
+	locations := make([]string, len(rep.Unmapped))
+	for i, u := range rep.Unmapped {
+		locations[i] = u.Location
+	}
+	assert.Contains(t, locations, "cXML/Request/InvoiceDetailRequest/InvoiceDetailRequestHeader/InvoicePartner[4][@role='shipFrom']")
+	assert.Contains(t, locations, "cXML/Request/InvoiceDetailRequest/InvoiceDetailRequestHeader/Extrinsic[@name='CostCenter']")
+	assert.Contains(t, locations, "cXML/Request/InvoiceDetailRequest/InvoiceDetailOrder[1]/InvoiceDetailItem[1]/InvoiceDetailItemReference/Classification")
+	assert.Contains(t, locations, "cXML/Request/InvoiceDetailRequest/InvoiceDetailOrder[1]/InvoiceDetailServiceItem[1]/Extrinsic[@name='ProjectCode']")
+	for _, u := range rep.Unmapped {
+		if strings.HasSuffix(u.Location, "Classification") {
+			assert.Equal(t, "43211503", u.Content)
+		}
+	}
+}
+
+func TestConvertInvoiceCreditMemo(t *testing.T) {
+	data := strings.NewReplacer(
+		`purpose="standard"`, `purpose="creditMemo"`,
+		`quantity="10"`, `quantity="-10"`,
+		`<Money currency="EUR">150.00</Money></UnitPrice>`, `<Money currency="EUR">-150.00</Money></UnitPrice>`,
+		`<ShippingAmount><Money currency="EUR">50.00</Money>`, `<ShippingAmount><Money currency="EUR">-50.00</Money>`,
+		`<DepositAmount><Money currency="EUR">100.00</Money>`, `<DepositAmount><Money currency="EUR">0.00</Money>`,
+	).Replace(testInvoice)
+	inv, _, err := cxml.ConvertInvoice([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, bill.InvoiceTypeCreditNote, inv.Type)
+	assert.Equal(t, "10", inv.Lines[0].Quantity.String())
+	assert.Equal(t, "150.00", inv.Lines[1].Item.Price.String())
+	assert.Equal(t, "50.00", inv.Charges[0].Amount.String())
+	assert.Equal(t, "1487.50", inv.Totals.Payable.String())
+}
+
+func TestConvertInvoiceTaxes(t *testing.T) {
+	data := strings.Replace(testInvoice,
+		`<TaxDetail purpose="tax" category="vat" percentageRate="19"/>`,
+		`<TaxDetail purpose="tax" category="vat" percentageRate="0" exemptDetail="exempt"/>`, 1)
+	inv, _, err := cxml.ConvertInvoice([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, tax.KeyExempt, inv.Lines[1].Taxes[0].Key)
+	assert.Nil(t, inv.Charges[0].Taxes, "lines with different taxes")
+}
+
+func TestConvertInvoiceErrors(t *testing.T) {
+	t.Run("invalid XML", func(t *testing.T) {
+		_, _, err := cxml.ConvertInvoice([]byte("<cXML>"))
+		assert.ErrorContains(t, err, "parsing cXML")
+	})
+	t.Run("other request", func(t *testing.T) {
+		_, _, err := cxml.ConvertInvoice([]byte(`<cXML><Request><OrderRequest/></Request></cXML>`))
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("purpose", func(t *testing.T) {
+		data := strings.Replace(testInvoice, `purpose="standard"`, `purpose="proforma"`, 1)
+		_, _, err := cxml.ConvertInvoice([]byte(data))
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("delete", func(t *testing.T) {
+		data := strings.Replace(testInvoice, `operation="new"`, `operation="delete"`, 1)
+		_, _, err := cxml.ConvertInvoice([]byte(data))
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("quantity", func(t *testing.T) {
+		data := strings.Replace(testInvoice, `quantity="10"`, `quantity="ten"`, 1)
+		_, _, err := cxml.ConvertInvoice([]byte(data))
+		assert.ErrorContains(t, err, "line 1 quantity")
+	})
+}
@@ -0,0 +1,259 @@
+package cxml
+
+import "encoding/xml"
+
+// Document is the root cXML element containing an InvoiceDetailRequest.
+type Document struct {
+	XMLName   xml.Name `xml:"cXML"`
+	PayloadID string   `xml:"payloadID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Header    *Any     `xml:"Header"`
+	Request   *Request `xml:"Request"`
+	Other     []*Any   `xml:",any"`
+}
+
+// Any captures elements that are not mapped, so they can be reported.
+type Any struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+// Request wraps the invoice detail request.
+type Request struct {
+	DeploymentMode string                `xml:"deploymentMode,attr,omitempty"`
+	Invoice        *InvoiceDetailRequest `xml:"InvoiceDetailRequest"`
+	Other          []*Any                `xml:",any"`
+}
+
+// InvoiceDetailRequest contains the invoice header, the orders being
+// invoiced with their items, and the summary.
+type InvoiceDetailRequest struct {
+	Header  *InvoiceHeader  `xml:"InvoiceDetailRequestHeader"`
+	Orders  []*InvoiceOrder `xml:"InvoiceDetailOrder"`
+	Summary *InvoiceSummary `xml:"InvoiceDetailSummary"`
+	Other   []*Any          `xml:",any"`
+}
+
+// InvoiceHeader contains the invoice details that apply to all items.
+type InvoiceHeader struct {
+	InvoiceID       string            `xml:"invoiceID,attr"`
+	Purpose         string            `xml:"purpose,attr"`
+	Operation       string            `xml:"operation,attr"`
+	InvoiceDate     string            `xml:"invoiceDate,attr"`
+	InvoiceOrigin   string            `xml:"invoiceOrigin,attr,omitempty"`
+	HeaderIndicator *Any              `xml:"InvoiceDetailHeaderIndicator"`
+	LineIndicator   *Any              `xml:"InvoiceDetailLineIndicator"`
+	Partners        []*InvoicePartner `xml:"InvoicePartner"`
+	PaymentTerms    []*PaymentTerm    `xml:"PaymentTerm"`
+	Period          *Period           `xml:"Period"`
+	Comments        []*Text           `xml:"Comments"`
+	Extrinsics      []*Extrinsic      `xml:"Extrinsic"`
+	Other           []*Any            `xml:",any"`
+}
+
+// InvoicePartner is a party involved in the invoice, identified by the
+// role of its contact.
+type InvoicePartner struct {
+	Contact      *Contact       `xml:"Contact"`
+	IDReferences []*IDReference `xml:"IdReference"`
+	Other        []*Any         `xml:",any"`
+}
+
+// Contact describes a party.
+type Contact struct {
+	Role          string         `xml:"role,attr"`
+	AddressID     string         `xml:"addressID,attr,omitempty"`
+	Name          *Text          `xml:"Name"`
+	PostalAddress *PostalAddress `xml:"PostalAddress"`
+	Emails        []string       `xml:"Email"`
+	Phones        []*Phone       `xml:"Phone"`
+	Other         []*Any         `xml:",any"`
+}
+
+// Text is a translatable text.
+type Text struct {
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+	Other []*Any `xml:",any"`
+}
+
+// PostalAddress of a contact.
+type PostalAddress struct {
+	Name       string   `xml:"name,attr,omitempty"`
+	DeliverTo  []string `xml:"DeliverTo"`
+	Street     []string `xml:"Street"`
+	City       string   `xml:"City"`
+	State      string   `xml:"State"`
+	PostalCode string   `xml:"PostalCode"`
+	Country    *Country `xml:"Country"`
+	Other      []*Any   `xml:",any"`
+}
+
+// Country with its ISO 3166 code.
+type Country struct {
+	Code string `xml:"isoCountryCode,attr"`
+	Name string `xml:",chardata"`
+}
+
+// Phone number of a contact.
+type Phone struct {
+	Name   string           `xml:"name,attr,omitempty"`
+	Number *TelephoneNumber `xml:"TelephoneNumber"`
+}
+
+// TelephoneNumber split into its parts.
+type TelephoneNumber struct {
+	CountryCode    *Country `xml:"CountryCode"`
+	AreaOrCityCode string   `xml:"AreaOrCityCode"`
+	Number         string   `xml:"Number"`
+	Extension      string   `xml:"Extension"`
+}
+
+// IDReference is an identifier of a partner in a given domain.
+type IDReference struct {
+	Identifier string `xml:"identifier,attr"`
+	Domain     string `xml:"domain,attr"`
+}
+
+// PaymentTerm defines when the invoice must be paid.
+type PaymentTerm struct {
+	PayInNumberOfDays string `xml:"payInNumberOfDays,attr"`
+	Other             []*Any `xml:",any"`
+}
+
+// Period with start and end dates.
+type Period struct {
+	StartDate string `xml:"startDate,attr"`
+	EndDate   string `xml:"endDate,attr"`
+}
+
+// Extrinsic contains custom data.
+type Extrinsic struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",innerxml"`
+}
+
+// InvoiceOrder groups the items invoiced for an order.
+type InvoiceOrder struct {
+	Info         *OrderInfo `xml:"InvoiceDetailOrderInfo"`
+	Items        []*Item    `xml:"InvoiceDetailItem"`
+	ServiceItems []*Item    `xml:"InvoiceDetailServiceItem"`
+	Other        []*Any     `xml:",any"`
+}
+
+// OrderInfo identifies the order and agreement being invoiced.
+type OrderInfo struct {
+	OrderReference    *DocumentInfo `xml:"OrderReference"`
+	OrderIDInfo       *DocumentInfo `xml:"OrderIDInfo"`
+	AgreementRef      *DocumentInfo `xml:"MasterAgreementReference"`
+	AgreementIDInfo   *DocumentInfo `xml:"MasterAgreementIDInfo"`
+	SupplierOrderInfo *DocumentInfo `xml:"SupplierOrderInfo"`
+	Other             []*Any        `xml:",any"`
+}
+
+// DocumentInfo identifies a related document.
+type DocumentInfo struct {
+	OrderID           string `xml:"orderID,attr"`
+	OrderDate         string `xml:"orderDate,attr"`
+	AgreementID       string `xml:"agreementID,attr"`
+	AgreementDate     string `xml:"agreementDate,attr"`
+	DocumentReference *Any   `xml:"DocumentReference"`
+}
+
+// Item is an invoiced product or service.
+type Item struct {
+	LineNumber           string         `xml:"invoiceLineNumber,attr"`
+	Quantity             string         `xml:"quantity,attr"`
+	ReferenceDate        string         `xml:"referenceDate,attr,omitempty"`
+	UnitOfMeasure        string         `xml:"UnitOfMeasure"`
+	UnitPrice            *Amount        `xml:"UnitPrice"`
+	ItemReference        *ItemReference `xml:"InvoiceDetailItemReference"`
+	ServiceItemReference *ItemReference `xml:"InvoiceDetailServiceItemReference"`
+	SubtotalAmount       *Amount        `xml:"SubtotalAmount"`
+	Period               *Period        `xml:"Period"`
+	Tax                  *Tax           `xml:"Tax"`
+	GrossAmount          *Amount        `xml:"GrossAmount"`
+	Discount             *Discount      `xml:"InvoiceDetailDiscount"`
+	NetAmount            *Amount        `xml:"NetAmount"`
+	Comments             []*Text        `xml:"Comments"`
+	Extrinsics           []*Extrinsic   `xml:"Extrinsic"`
+	Other                []*Any         `xml:",any"`
+}
+
+// ItemReference identifies the item and the order line.
+type ItemReference struct {
+	LineNumber  string  `xml:"lineNumber,attr"`
+	ItemID      *ItemID `xml:"ItemID"`
+	Description *Text   `xml:"Description"`
+	Other       []*Any  `xml:",any"`
+}
+
+// ItemID contains the part numbers of the item.
+type ItemID struct {
+	SupplierPartID          string `xml:"SupplierPartID"`
+	SupplierPartAuxiliaryID string `xml:"SupplierPartAuxiliaryID"`
+	BuyerPartID             string `xml:"BuyerPartID"`
+	Other                   []*Any `xml:",any"`
+}
+
+// Amount wraps money.
+type Amount struct {
+	Money *Money `xml:"Money"`
+	Other []*Any `xml:",any"`
+}
+
+// Money is an amount in a currency.
+type Money struct {
+	Currency string `xml:"currency,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// Tax contains the total tax and the details of each tax applied.
+type Tax struct {
+	Money       *Money       `xml:"Money"`
+	Description *Text        `xml:"Description"`
+	Details     []*TaxDetail `xml:"TaxDetail"`
+	Other       []*Any       `xml:",any"`
+}
+
+// TaxDetail describes a single tax.
+type TaxDetail struct {
+	Purpose        string  `xml:"purpose,attr"`
+	Category       string  `xml:"category,attr"`
+	PercentageRate string  `xml:"percentageRate,attr"`
+	ExemptDetail   string  `xml:"exemptDetail,attr,omitempty"`
+	TaxPointDate   string  `xml:"taxPointDate,attr,omitempty"`
+	TaxableAmount  *Amount `xml:"TaxableAmount"`
+	TaxAmount      *Amount `xml:"TaxAmount"`
+	TaxLocation    *Text   `xml:"TaxLocation"`
+	Description    *Text   `xml:"Description"`
+	Other          []*Any  `xml:",any"`
+}
+
+// Discount is either a percentage rate or an amount of money.
+type Discount struct {
+	PercentageRate string `xml:"percentageRate,attr,omitempty"`
+	Money          *Money `xml:"Money"`
+	Other          []*Any `xml:",any"`
+}
+
+// HandlingAmount is a special handling charge.
+type HandlingAmount struct {
+	Money       *Money `xml:"Money"`
+	Description *Text  `xml:"Description"`
+	Other       []*Any `xml:",any"`
+}
+
+// InvoiceSummary contains the totals of the invoice.
+type InvoiceSummary struct {
+	SubtotalAmount  *Amount         `xml:"SubtotalAmount"`
+	Tax             *Tax            `xml:"Tax"`
+	SpecialHandling *HandlingAmount `xml:"SpecialHandlingAmount"`
+	ShippingAmount  *Amount         `xml:"ShippingAmount"`
+	GrossAmount     *Amount         `xml:"GrossAmount"`
+	Discount        *Discount       `xml:"InvoiceDetailDiscount"`
+	NetAmount       *Amount         `xml:"NetAmount"`
+	DepositAmount   *Amount         `xml:"DepositAmount"`
+	DueAmount       *Amount         `xml:"DueAmount"`
+	Other           []*Any          `xml:",any"`
+}
@@ -0,0 +1,666 @@
+package cxml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// Invoice purposes.
+const (
+	PurposeStandard            = "standard"
+	PurposeCreditMemo          = "creditMemo"
+	PurposeLineLevelCreditMemo = "lineLevelCreditMemo"
+	PurposeDebitMemo           = "debitMemo"
+	PurposeLineLevelDebitMemo  = "lineLevelDebitMemo"
+)
+
+// Partner roles.
+const (
+	RoleIssuerOfInvoice = "issuerOfInvoice"
+	RoleFrom            = "from"
+	RoleSoldTo          = "soldTo"
+	RoleBillTo          = "billTo"
+	RoleRemitTo         = "remitTo"
+)
+
+// Identifier domains.
+const (
+	domainVAT         = "vatID"
+	domainIBAN        = "ibanID"
+	domainBankAccount = "bankAccountID"
+	domainSWIFT       = "swiftID"
+	domainAccountName = "accountName"
+)
+
+const (
+	operationDelete      = "delete"
+	taxCategoryVAT       = "vat"
+	taxPurposeShipping   = "shippingTax"
+	exemptDetailZero     = "zeroRated"
+	exemptDetailExempt   = "exempt"
+	prepaidDescription   = "Deposit"
+	shippingDescription  = "Shipping"
+	handlingDescription  = "Special handling"
+	pathSeparator        = "/"
+	pathInvoiceHeader    = "InvoiceDetailRequestHeader"
+	pathInvoiceSummary   = "InvoiceDetailSummary"
+	pathInvoiceRequest   = "cXML/Request/InvoiceDetailRequest"
+	pathInvoicePartner   = "InvoicePartner"
+	pathTaxDetail        = "TaxDetail"
+	pathInvoiceOrder     = "InvoiceDetailOrder"
+	pathInvoiceOrderInfo = "InvoiceDetailOrderInfo"
+)
+
+// importer keeps track of the report while mapping the document.
+type importer struct {
+	report *convert.Report
+	credit bool
+}
+
+// ToInvoice converts the InvoiceDetailRequest into a GOBL invoice and
+// calculates the result. Amounts in credit memos, which are negative in
+// cXML, are converted to the positive amounts expected in GOBL credit
+// notes. The report lists the elements that could not be mapped.
+func (d *Document) ToInvoice() (*bill.Invoice, *convert.Report, error) {
+	if d.Request == nil || d.Request.Invoice == nil || d.Request.Invoice.Header == nil {
+		return nil, nil, fmt.Errorf("%w: InvoiceDetailRequest required", convert.ErrUnsupported)
+	}
+	req := d.Request.Invoice
+	hdr := req.Header
+	inv := &bill.Invoice{
+		Code: cbc.Code(hdr.InvoiceID),
+	}
+	im := &importer{report: new(convert.Report)}
+	switch hdr.Purpose {
+	case PurposeStandard, "":
+		inv.Type = bill.InvoiceTypeStandard
+	case PurposeCreditMemo, PurposeLineLevelCreditMemo:
+		inv.Type = bill.InvoiceTypeCreditNote
+		im.credit = true
+	case PurposeDebitMemo, PurposeLineLevelDebitMemo:
+		inv.Type = bill.InvoiceTypeDebitNote
+	default:
+		return nil, nil, fmt.Errorf("%w: purpose '%s'", convert.ErrUnsupported, hdr.Purpose)
+	}
+	if hdr.Operation == operationDelete {
+		return nil, nil, fmt.Errorf("%w: delete operation", convert.ErrUnsupported)
+	}
+	im.others("cXML", d.Other)
+	im.others("cXML/Request", d.Request.Other)
+	im.others(pathInvoiceRequest, req.Other)
+
+	if err := im.header(inv, hdr); err != nil {
+		return nil, nil, err
+	}
+	for i, o := range req.Orders {
+		if err := im.order(inv, o, im.indexed(pathInvoiceRequest, pathInvoiceOrder, i)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := im.summary(inv, req.Summary); err != nil {
+		return nil, nil, err
+	}
+	if inv.Currency == currency.CodeEmpty {
+		return nil, nil, fmt.Errorf("%w: currency required", convert.ErrUnsupported)
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, nil, fmt.Errorf("calculating invoice: %w", err)
+	}
+	return inv, im.report, nil
+}
+
+func (im *importer) header(inv *bill.Invoice, hdr *InvoiceHeader) error {
+	path := pathInvoiceRequest + pathSeparator + pathInvoiceHeader
+	date, err := parseDate(hdr.InvoiceDate)
+	if err != nil {
+		return fmt.Errorf("invoice date: %w", err)
+	}
+	if date != nil {
+		inv.IssueDate = *date
+	}
+	for _, c := range hdr.Comments {
+		if t := strings.TrimSpace(c.Value); t != "" {
+			inv.Notes = append(inv.Notes, &org.Note{Text: t})
+		}
+		im.others(path+"/Comments", c.Other)
+	}
+	if hdr.Period != nil {
+		p, err := parsePeriod(hdr.Period)
+		if err != nil {
+			return fmt.Errorf("period: %w", err)
+		}
+		if p != nil {
+			ordering(inv).Period = p
+		}
+	}
+	parties := make(map[string]*InvoicePartner)
+	for i, ip := range hdr.Partners {
+		ppath := im.indexed(path, pathInvoicePartner, i)
+		im.others(ppath, ip.Other)
+		if ip.Contact == nil {
+			continue
+		}
+		switch role := ip.Contact.Role; role {
+		case RoleIssuerOfInvoice, RoleFrom, RoleSoldTo, RoleBillTo, RoleRemitTo:
+			if _, ok := parties[role]; !ok {
+				parties[role] = ip
+			}
+		default:
+			im.report.Add(ppath+"[@role='"+role+"']", contactName(ip.Contact))
+		}
+	}
+	inv.Supplier = im.party(first(parties, RoleIssuerOfInvoice, RoleFrom), path)
+	inv.Customer = im.party(first(parties, RoleSoldTo, RoleBillTo), path)
+	if err := im.payment(inv, hdr, parties[RoleRemitTo], path); err != nil {
+		return err
+	}
+	for _, e := range hdr.Extrinsics {
+		im.report.Add(path+"/Extrinsic[@name='"+e.Name+"']", strings.TrimSpace(e.Value))
+	}
+	im.others(path, hdr.Other)
+	return nil
+}
+
+func (im *importer) payment(inv *bill.Invoice, hdr *InvoiceHeader, remit *InvoicePartner, path string) error {
+	p := new(bill.PaymentDetails)
+	for i, pt := range hdr.PaymentTerms {
+		im.others(im.indexed(path, "PaymentTerm", i), pt.Other)
+		if pt.PayInNumberOfDays == "" || p.Terms != nil || inv.IssueDate.IsZero() {
+			continue
+		}
+		days, err := strconv.Atoi(pt.PayInNumberOfDays)
+		if err != nil {
+			return fmt.Errorf("payment term: invalid days '%s'", pt.PayInNumberOfDays)
+		}
+		due := inv.IssueDate.Add(0, 0, days)
+		p.Terms = &pay.Terms{
+			DueDates: []*pay.DueDate{{Date: &due, Percent: num.NewPercentage(100, 2)}},
+		}
+	}
+	if remit != nil {
+		ct := new(pay.CreditTransfer)
+		for _, ref := range remit.IDReferences {
+			switch ref.Domain {
+			case domainIBAN:
+				ct.IBAN = ref.Identifier
+			case domainBankAccount:
+				ct.Number = ref.Identifier
+			case domainSWIFT:
+				ct.BIC = ref.Identifier
+			case domainAccountName:
+				ct.Name = ref.Identifier
+			}
+		}
+		if ct.IBAN != "" || ct.Number != "" {
+			p.Instructions = &pay.Instructions{
+				Key:            pay.MeansKeyCreditTransfer,
+				CreditTransfer: []*pay.CreditTransfer{ct},
+			}
+		}
+		payee := im.party(remit, path)
+		if inv.Supplier == nil || payee.Name != inv.Supplier.Name {
+			p.Payee = payee
+		}
+	}
+	if p.Terms != nil || p.Instructions != nil || p.Payee != nil {
+		inv.Payment = p
+	}
+	return nil
+}
+
+func (im *importer) party(ip *InvoicePartner, path string) *org.Party {
+	if ip == nil {
+		return nil
+	}
+	c := ip.Contact
+	path = path + "/InvoicePartner[@role='" + c.Role + "']"
+	p := &org.Party{Name: contactName(c)}
+	var country l10n.TaxCountryCode
+	if a := c.PostalAddress; a != nil {
+		addr := &org.Address{
+			Locality: a.City,
+			Region:   a.State,
+			Code:     cbc.Code(a.PostalCode),
+		}
+		if len(a.Street) > 0 {
+			addr.Street = a.Street[0]
+			addr.StreetExtra = strings.Join(a.Street[1:], ", ")
+		}
+		if a.Country != nil {
+			addr.Country = l10n.ISOCountryCode(a.Country.Code)
+			country = l10n.TaxCountryCode(a.Country.Code)
+		}
+		p.Addresses = []*org.Address{addr}
+		im.others(path+"/Contact/PostalAddress", a.Other)
+	}
+	for _, e := range c.Emails {
+		p.Emails = append(p.Emails, &org.Email{Address: strings.TrimSpace(e)})
+	}
+	for _, ph := range c.Phones {
+		if t := telephone(ph.Number); t != "" {
+			p.Telephones = append(p.Telephones, &org.Telephone{Label: ph.Name, Number: t})
+		}
+	}
+	im.others(path+"/Contact", c.Other)
+	for _, ref := range ip.IDReferences {
+		switch ref.Domain {
+		case domainVAT:
+			p.TaxID = convert.ParseTaxIdentity(ref.Identifier, country)
+		case domainIBAN, domainBankAccount, domainSWIFT, domainAccountName:
+			// used for payment instructions
+		default:
+			p.Identities = append(p.Identities, &org.Identity{
+				Label: ref.Domain,
+				Code:  cbc.Code(ref.Identifier),
+			})
+		}
+	}
+	if p.TaxID == nil && country != "" {
+		p.TaxID = &tax.Identity{Country: country}
+	}
+	return p
+}
+
+func (im *importer) order(inv *bill.Invoice, o *InvoiceOrder, path string) error {
+	im.others(path, o.Other)
+	if oi := o.Info; oi != nil {
+		im.others(path+pathSeparator+pathInvoiceOrderInfo, oi.Other)
+		if ref, err := orderRef(oi.OrderReference, oi.OrderIDInfo); err != nil {
+			return fmt.Errorf("order reference: %w", err)
+		} else if ref != nil {
+			ordering(inv).Purchases = appendRef(ordering(inv).Purchases, ref)
+		}
+		if ref, err := agreementRef(oi.AgreementRef, oi.AgreementIDInfo); err != nil {
+			return fmt.Errorf("agreement reference: %w", err)
+		} else if ref != nil {
+			ordering(inv).Contracts = appendRef(ordering(inv).Contracts, ref)
+		}
+		if s := oi.SupplierOrderInfo; s != nil && s.OrderID != "" {
+			ordering(inv).Sales = appendRef(ordering(inv).Sales, &org.DocumentRef{Code: cbc.Code(s.OrderID)})
+		}
+	}
+	for i, it := range o.Items {
+		if err := im.line(inv, it, im.indexed(path, "InvoiceDetailItem", i)); err != nil {
+			return err
+		}
+	}
+	for i, it := range o.ServiceItems {
+		if err := im.line(inv, it, im.indexed(path, "InvoiceDetailServiceItem", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) line(inv *bill.Invoice, it *Item, path string) error {
+	line := &bill.Line{Item: new(org.Item)}
+	q, err := convert.ParseAmount(it.Quantity)
+	if err != nil {
+		return fmt.Errorf("line %s quantity: %w", it.LineNumber, err)
+	}
+	line.Quantity = im.amount(q)
+	line.Item.Unit = convert.UnitFromUNECE(strings.TrimSpace(it.UnitOfMeasure))
+	if it.UnitPrice != nil {
+		im.others(path+"/UnitPrice", it.UnitPrice.Other)
+		if it.UnitPrice.Money != nil {
+			p, err := convert.ParseAmount(it.UnitPrice.Money.Value)
+			if err != nil {
+				return fmt.Errorf("line %s price: %w", it.LineNumber, err)
+			}
+			p = im.amount(p)
+			line.Item.Price = &p
+			if inv.Currency == currency.CodeEmpty {
+				inv.Currency = currency.Code(it.UnitPrice.Money.Currency)
+			}
+		}
+	}
+	ref := it.ItemReference
+	rpath := path + "/InvoiceDetailItemReference"
+	if ref == nil {
+		ref = it.ServiceItemReference
+		rpath = path + "/InvoiceDetailServiceItemReference"
+	}
+	if ref != nil {
+		im.others(rpath, ref.Other)
+		line.Order = cbc.Code(ref.LineNumber)
+		if ref.Description != nil {
+			line.Item.Name = strings.TrimSpace(ref.Description.Value)
+			im.others(rpath+"/Description", ref.Description.Other)
+		}
+		if id := ref.ItemID; id != nil {
+			im.others(rpath+"/ItemID", id.Other)
+			line.Item.Ref = cbc.Code(strings.TrimSpace(id.SupplierPartID))
+			if b := strings.TrimSpace(id.BuyerPartID); b != "" {
+				line.Item.Identities = append(line.Item.Identities, &org.Identity{
+					Label: "BuyerPartID",
+					Code:  cbc.Code(b),
+				})
+			}
+		}
+	}
+	if it.Period != nil {
+		if line.Period, err = parsePeriod(it.Period); err != nil {
+			return fmt.Errorf("line %s period: %w", it.LineNumber, err)
+		}
+	}
+	if it.Tax != nil {
+		if line.Taxes, err = im.taxes(it.Tax, path+"/Tax"); err != nil {
+			return fmt.Errorf("line %s: %w", it.LineNumber, err)
+		}
+	}
+	if dc := it.Discount; dc != nil {
+		im.others(path+"/InvoiceDetailDiscount", dc.Other)
+		amount, percent, err := im.discount(dc)
+		if err != nil {
+			return fmt.Errorf("line %s discount: %w", it.LineNumber, err)
+		}
+		line.Discounts = []*bill.LineDiscount{{Amount: amount, Percent: percent}}
+	}
+	for _, c := range it.Comments {
+		if t := strings.TrimSpace(c.Value); t != "" {
+			line.Notes = append(line.Notes, &org.Note{Text: t})
+		}
+	}
+	for _, e := range it.Extrinsics {
+		im.report.Add(path+"/Extrinsic[@name='"+e.Name+"']", strings.TrimSpace(e.Value))
+	}
+	im.others(path, it.Other)
+	inv.Lines = append(inv.Lines, line)
+	return nil
+}
+
+// taxes maps the VAT details, reporting any other taxes.
+func (im *importer) taxes(t *Tax, path string) (tax.Set, error) {
+	im.others(path, t.Other)
+	var set tax.Set
+	for i, td := range t.Details {
+		dpath := im.indexed(path, pathTaxDetail, i)
+		im.others(dpath, td.Other)
+		if !strings.EqualFold(td.Category, taxCategoryVAT) || td.Purpose == taxPurposeShipping {
+			if td.Purpose != taxPurposeShipping {
+				im.report.Add(dpath+"[@category='"+td.Category+"']", td.PercentageRate)
+			}
+			continue
+		}
+		c, err := vatCombo(td)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, c)
+	}
+	return set, nil
+}
+
+func (im *importer) summary(inv *bill.Invoice, s *InvoiceSummary) error {
+	if s == nil {
+		return nil
+	}
+	path := pathInvoiceRequest + pathSeparator + pathInvoiceSummary
+	im.others(path, s.Other)
+	if inv.Currency == currency.CodeEmpty && s.SubtotalAmount != nil && s.SubtotalAmount.Money != nil {
+		inv.Currency = currency.Code(s.SubtotalAmount.Money.Currency)
+	}
+	taxes := commonTaxes(inv.Lines)
+	shippingTaxes := taxes
+	if s.Tax != nil {
+		// taxes are calculated, but shipping tax rates are needed
+		for _, td := range s.Tax.Details {
+			if td.Purpose == taxPurposeShipping && strings.EqualFold(td.Category, taxCategoryVAT) {
+				c, err := vatCombo(td)
+				if err != nil {
+					return fmt.Errorf("shipping tax: %w", err)
+				}
+				shippingTaxes = tax.Set{c}
+			}
+		}
+	}
+	if a := s.ShippingAmount; a != nil && a.Money != nil {
+		amount, err := convert.ParseAmount(a.Money.Value)
+		if err != nil {
+			return fmt.Errorf("shipping amount: %w", err)
+		}
+		if !amount.IsZero() {
+			inv.Charges = append(inv.Charges, &bill.Charge{
+				Key:    bill.ChargeKeyDelivery,
+				Reason: shippingDescription,
+				Amount: im.amount(amount),
+				Taxes:  shippingTaxes,
+			})
+		}
+	}
+	if h := s.SpecialHandling; h != nil && h.Money != nil {
+		amount, err := convert.ParseAmount(h.Money.Value)
+		if err != nil {
+			return fmt.Errorf("special handling amount: %w", err)
+		}
+		reason := handlingDescription
+		if h.Description != nil && strings.TrimSpace(h.Description.Value) != "" {
+			reason = strings.TrimSpace(h.Description.Value)
+		}
+		if !amount.IsZero() {
+			inv.Charges = append(inv.Charges, &bill.Charge{
+				Key:    bill.ChargeKeyHandling,
+				Reason: reason,
+				Amount: im.amount(amount),
+				Taxes:  taxes,
+			})
+		}
+	}
+	if dc := s.Discount; dc != nil {
+		im.others(path+"/InvoiceDetailDiscount", dc.Other)
+		amount, percent, err := im.discount(dc)
+		if err != nil {
+			return fmt.Errorf("discount: %w", err)
+		}
+		inv.Discounts = append(inv.Discounts, &bill.Discount{
+			Amount:  amount,
+			Percent: percent,
+			Taxes:   taxes,
+		})
+	}
+	if a := s.DepositAmount; a != nil && a.Money != nil {
+		amount, err := convert.ParseAmount(a.Money.Value)
+		if err != nil {
+			return fmt.Errorf("deposit amount: %w", err)
+		}
+		if !amount.IsZero() {
+			if inv.Payment == nil {
+				inv.Payment = new(bill.PaymentDetails)
+			}
+			inv.Payment.Advances = append(inv.Payment.Advances, &pay.Advance{
+				Description: prepaidDescription,
+				Amount:      im.amount(amount),
+			})
+		}
+	}
+	return nil
+}
+
+func (im *importer) discount(dc *Discount) (num.Amount, *num.Percentage, error) {
+	percent, err := convert.ParsePercent(dc.PercentageRate)
+	if err != nil {
+		return num.AmountZero, nil, err
+	}
+	var amount num.Amount
+	if dc.Money != nil {
+		if amount, err = convert.ParseAmount(dc.Money.Value); err != nil {
+			return num.AmountZero, nil, err
+		}
+	}
+	if percent != nil {
+		// amounts are calculated from the percentage
+		return num.AmountZero, percent, nil
+	}
+	return amount.Abs(), nil, nil
+}
+
+// amount removes the negative sign used for credit memos.
+func (im *importer) amount(a num.Amount) num.Amount {
+	if im.credit {
+		return a.Abs()
+	}
+	return a
+}
+
+// others reports the unmapped elements found at the path.
+func (im *importer) others(path string, list []*Any) {
+	for _, a := range list {
+		im.report.Add(path+pathSeparator+a.XMLName.Local, strings.TrimSpace(a.Inner))
+	}
+}
+
+// indexed provides the path to an element that may be repeated, using
+// XPath's 1-based indexes.
+func (im *importer) indexed(path, name string, i int) string {
+	return fmt.Sprintf("%s/%s[%d]", path, name, i+1)
+}
+
+func vatCombo(td *TaxDetail) (*tax.Combo, error) {
+	c := &tax.Combo{Category: tax.CategoryVAT}
+	switch td.ExemptDetail {
+	case exemptDetailZero:
+		c.Key = tax.KeyZero
+		return c, nil
+	case exemptDetailExempt:
+		c.Key = tax.KeyExempt
+		return c, nil
+	}
+	p, err := convert.ParsePercent(td.PercentageRate)
+	if err != nil {
+		return nil, fmt.Errorf("tax: %w", err)
+	}
+	c.Percent = p
+	return c, nil
+}
+
+// commonTaxes provides the taxes of the lines if they are all the same,
+// so that they can be applied to document level charges and discounts.
+func commonTaxes(lines []*bill.Line) tax.Set {
+	var set tax.Set
+	for i, l := range lines {
+		if i == 0 {
+			set = l.Taxes
+			continue
+		}
+		if !l.Taxes.Equals(set) {
+			return nil
+		}
+	}
+	return set
+}
+
+func first(parties map[string]*InvoicePartner, roles ...string) *InvoicePartner {
+	for _, r := range roles {
+		if p, ok := parties[r]; ok {
+			return p
+		}
+	}
+	return nil
+}
+
+func contactName(c *Contact) string {
+	if c.Name == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Name.Value)
+}
+
+func telephone(n *TelephoneNumber) string {
+	if n == nil || strings.TrimSpace(n.Number) == "" {
+		return ""
+	}
+	parts := make([]string, 0, 4)
+	if n.CountryCode != nil && strings.TrimSpace(n.CountryCode.Name) != "" {
+		parts = append(parts, "+"+strings.TrimSpace(n.CountryCode.Name))
+	}
+	if a := strings.TrimSpace(n.AreaOrCityCode); a != "" {
+		parts = append(parts, a)
+	}
+	parts = append(parts, strings.TrimSpace(n.Number))
+	if e := strings.TrimSpace(n.Extension); e != "" {
+		parts = append(parts, "ext. "+e)
+	}
+	return strings.Join(parts, " ")
+}
+
+func ordering(inv *bill.Invoice) *bill.Ordering {
+	if inv.Ordering == nil {
+		inv.Ordering = new(bill.Ordering)
+	}
+	return inv.Ordering
+}
+
+// appendRef adds the document reference if not already present, as items
+// from the same order may be split across several groups.
+func appendRef(list []*org.DocumentRef, ref *org.DocumentRef) []*org.DocumentRef {
+	for _, r := range list {
+		if r.Code == ref.Code {
+			return list
+		}
+	}
+	return append(list, ref)
+}
+
+func orderRef(refs ...*DocumentInfo) (*org.DocumentRef, error) {
+	for _, r := range refs {
+		if r == nil || r.OrderID == "" {
+			continue
+		}
+		d, err := parseDate(r.OrderDate)
+		if err != nil {
+			return nil, err
+		}
+		return &org.DocumentRef{Code: cbc.Code(r.OrderID), IssueDate: d}, nil
+	}
+	return nil, nil
+}
+
+func agreementRef(refs ...*DocumentInfo) (*org.DocumentRef, error) {
+	for _, r := range refs {
+		if r == nil || r.AgreementID == "" {
+			continue
+		}
+		d, err := parseDate(r.AgreementDate)
+		if err != nil {
+			return nil, err
+		}
+		return &org.DocumentRef{Code: cbc.Code(r.AgreementID), IssueDate: d}, nil
+	}
+	return nil, nil
+}
+
+// parseDate reads the date from a cXML date and time value.
+func parseDate(s string) (*cal.Date, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 10 {
+		s = s[:10]
+	}
+	return convert.ParseDate(s)
+}
+
+func parsePeriod(p *Period) (*cal.Period, error) {
+	start, err := parseDate(p.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(p.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if start == nil || end == nil {
+		return nil, nil
+	}
+	return &cal.Period{Start: *start, End: *end}, nil
+}
@@ -0,0 +1,268 @@
+// Package edifact imports UN/EDIFACT INVOIC messages, in the D96A and
+// D01B directories, into GOBL invoices using the EN 16931 addon.
+//
+// EDIFACT is still widely used between large retailers and their
+// suppliers, so the mapping follows the segments used by EANCOM and the
+// EN 16931 EDIFACT syntax binding. Segments that are not understood, or
+// whose qualifiers have no equivalent in GOBL, are not silently dropped
+// but listed in a report returned alongside the invoice, identified by
+// their position in the message.
+package edifact
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Service segment tags.
+const (
+	tagUNA = "UNA"
+	tagUNB = "UNB"
+	tagUNZ = "UNZ"
+	tagUNG = "UNG"
+	tagUNE = "UNE"
+	tagUNH = "UNH"
+	tagUNT = "UNT"
+)
+
+// MessageTypeINVOIC is the type of the invoice messages supported.
+const MessageTypeINVOIC = "INVOIC"
+
+// SupportedReleases lists the directory releases of INVOIC messages that
+// can be converted.
+var SupportedReleases = []string{"96A", "01B"}
+
+// ErrSyntax is returned when the interchange cannot be parsed.
+var ErrSyntax = errors.New("invalid EDIFACT syntax")
+
+// separators used to parse the interchange, which may be overridden by a
+// UNA service string advice.
+type separators struct {
+	component  byte
+	element    byte
+	decimal    byte
+	release    byte
+	terminator byte
+}
+
+var defaultSeparators = separators{
+	component:  ':',
+	element:    '+',
+	decimal:    '.',
+	release:    '?',
+	terminator: '\'',
+}
+
+// Interchange contains the messages sent between two parties.
+type Interchange struct {
+	// Syntax identifier, such as "UNOC".
+	Syntax string
+	// Sender identification.
+	Sender string
+	// Recipient identification.
+	Recipient string
+	// Reference is the interchange control reference.
+	Reference string
+	// Messages in the interchange.
+	Messages []*Message
+}
+
+// Message is a single EDIFACT message, from its UNH header to the UNT
+// trailer.
+type Message struct {
+	// Reference is the message reference number.
+	Reference string
+	// Type of message, such as "INVOIC".
+	Type string
+	// Version of the directory, usually "D".
+	Version string
+	// Release of the directory, such as "96A".
+	Release string
+	// Segments in the message, including the header and trailer.
+	Segments []*Segment
+
+	decimal byte
+}
+
+// Segment is a tagged list of data elements, each of which may contain
+// several components.
+type Segment struct {
+	// Tag identifying the segment, such as "BGM".
+	Tag string
+	// Elements following the tag, each with its components.
+	Elements [][]string
+	// Position of the segment in the message, starting with 1 for UNH.
+	Position int
+}
+
+// Value provides the component of the element at the given indexes,
+// or an empty string if not present.
+func (s *Segment) Value(element, component int) string {
+	if element >= len(s.Elements) {
+		return ""
+	}
+	e := s.Elements[element]
+	if component >= len(e) {
+		return ""
+	}
+	return e[component]
+}
+
+// Qualifier is a shortcut to the first component of the first element,
+// which is used by most segments to qualify their contents.
+func (s *Segment) Qualifier() string {
+	return s.Value(0, 0)
+}
+
+// String provides the segment using the default separators, without the
+// terminator.
+func (s *Segment) String() string {
+	parts := []string{s.Tag}
+	for _, e := range s.Elements {
+		parts = append(parts, strings.Join(e, string(defaultSeparators.component)))
+	}
+	return strings.Join(parts, string(defaultSeparators.element))
+}
+
+// Parse reads the EDIFACT interchange, with or without the UNB and UNZ
+// service segments.
+func Parse(data []byte) (*Interchange, error) {
+	sep := defaultSeparators
+	s := string(data)
+	s = strings.TrimPrefix(s, "\ufeff")
+	s = strings.TrimLeft(s, " \t\r\n")
+	if strings.HasPrefix(s, tagUNA) {
+		if len(s) < 9 {
+			return nil, fmt.Errorf("%w: incomplete UNA", ErrSyntax)
+		}
+		sep = separators{
+			component:  s[3],
+			element:    s[4],
+			decimal:    s[5],
+			release:    s[6],
+			terminator: s[8],
+		}
+		s = s[9:]
+	}
+	segs, err := splitSegments(s, sep)
+	if err != nil {
+		return nil, err
+	}
+	ic := new(Interchange)
+	var msg *Message
+	for _, seg := range segs {
+		switch seg.Tag {
+		case tagUNB:
+			ic.Syntax = seg.Value(0, 0)
+			ic.Sender = seg.Value(1, 0)
+			ic.Recipient = seg.Value(2, 0)
+			ic.Reference = seg.Value(4, 0)
+			continue
+		case tagUNZ, tagUNG, tagUNE:
+			continue
+		case tagUNH:
+			if msg != nil {
+				return nil, fmt.Errorf("%w: message %s not terminated", ErrSyntax, msg.Reference)
+			}
+			msg = &Message{
+				Reference: seg.Value(0, 0),
+				Type:      seg.Value(1, 0),
+				Version:   seg.Value(1, 1),
+				Release:   seg.Value(1, 2),
+				decimal:   sep.decimal,
+			}
+		}
+		if msg == nil {
+			return nil, fmt.Errorf("%w: segment %s outside of message", ErrSyntax, seg.Tag)
+		}
+		seg.Position = len(msg.Segments) + 1
+		msg.Segments = append(msg.Segments, seg)
+		if seg.Tag == tagUNT {
+			ic.Messages = append(ic.Messages, msg)
+			msg = nil
+		}
+	}
+	if msg != nil {
+		return nil, fmt.Errorf("%w: message %s not terminated", ErrSyntax, msg.Reference)
+	}
+	if len(ic.Messages) == 0 {
+		return nil, fmt.Errorf("%w: no messages", ErrSyntax)
+	}
+	return ic, nil
+}
+
+// splitSegments tokenizes the data, taking into account the release
+// character used to escape separators.
+func splitSegments(s string, sep separators) ([]*Segment, error) {
+	var segs []*Segment
+	var elements [][]string
+	var components []string
+	var buf strings.Builder
+	released := false
+	endComponent := func() {
+		components = append(components, buf.String())
+		buf.Reset()
+	}
+	endElement := func() {
+		endComponent()
+		elements = append(elements, components)
+		components = nil
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if released {
+			buf.WriteByte(c)
+			released = false
+			continue
+		}
+		switch c {
+		case sep.release:
+			released = true
+		case sep.component:
+			endComponent()
+		case sep.element:
+			endElement()
+		case sep.terminator:
+			endElement()
+			segs = append(segs, newSegment(elements))
+			elements = nil
+		case '\r', '\n':
+			// line breaks between segments are common, but not part of
+			// the data
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if released {
+		return nil, fmt.Errorf("%w: dangling release character", ErrSyntax)
+	}
+	if strings.TrimSpace(buf.String()) != "" || len(elements) > 0 {
+		return nil, fmt.Errorf("%w: segment not terminated", ErrSyntax)
+	}
+	return segs, nil
+}
+
+func newSegment(elements [][]string) *Segment {
+	seg := &Segment{Tag: strings.TrimSpace(elements[0][0])}
+	if len(elements) > 1 {
+		seg.Elements = elements[1:]
+	}
+	return seg
+}
+
+// Supported returns true if the message is an INVOIC in one of the
+// supported directory releases.
+func (m *Message) Supported() bool {
+	return m.Type == MessageTypeINVOIC && m.Version == "D" &&
+		slices.Contains(SupportedReleases, m.Release)
+}
+
+// number normalizes the decimal mark of a numeric value.
+func (m *Message) number(s string) string {
+	if m.decimal != '.' {
+		s = strings.ReplaceAll(s, string(m.decimal), ".")
+	}
+	return s
+}
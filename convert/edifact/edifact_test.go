@@ -0,0 +1,63 @@
+package edifact_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/convert/edifact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("interchange", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte(testINVOIC))
+		require.NoError(t, err)
+		assert.Equal(t, "UNOC", ic.Syntax)
+		assert.Equal(t, "4000001000002", ic.Sender)
+		assert.Equal(t, "5412345000013", ic.Recipient)
+		assert.Equal(t, "IC0001", ic.Reference)
+		require.Len(t, ic.Messages, 1)
+		m := ic.Messages[0]
+		assert.Equal(t, "M1", m.Reference)
+		assert.Equal(t, "INVOIC", m.Type)
+		assert.Equal(t, "96A", m.Release)
+		assert.True(t, m.Supported())
+		assert.Equal(t, "UNH", m.Segments[0].Tag)
+		assert.Equal(t, 1, m.Segments[0].Position)
+		assert.Equal(t, "UNT", m.Segments[len(m.Segments)-1].Tag)
+	})
+	t.Run("release character and UNA", func(t *testing.T) {
+		data := "UNA:+,? 'UNH+1+INVOIC:D:01B:UN'FTX+AAI+++Price?: 10?+ tax? ?'?'s'MOA+86:10,50'UNT+4+1'"
+		ic, err := edifact.Parse([]byte(data))
+		require.NoError(t, err)
+		m := ic.Messages[0]
+		assert.True(t, m.Supported())
+		ftx := m.Segments[1]
+		assert.Equal(t, "Price: 10+ tax ''s", ftx.Value(3, 0))
+		assert.Equal(t, "AAI", ftx.Qualifier())
+		assert.Equal(t, "", ftx.Value(9, 0))
+		assert.Equal(t, "10,50", m.Segments[2].Value(0, 1))
+	})
+	t.Run("segment string", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'DTM+137:20240601:102'UNT+3+1'"))
+		require.NoError(t, err)
+		assert.Equal(t, "DTM+137:20240601:102", ic.Messages[0].Segments[1].String())
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'BGM+380+1'"))
+		assert.ErrorIs(t, err, edifact.ErrSyntax)
+		assert.ErrorContains(t, err, "message 1 not terminated")
+		_, err = edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'UNH+2+INVOIC:D:96A:UN'"))
+		assert.ErrorContains(t, err, "message 1 not terminated")
+		_, err = edifact.Parse([]byte("BGM+380+1'"))
+		assert.ErrorContains(t, err, "segment BGM outside of message")
+		_, err = edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'UNT+2+1"))
+		assert.ErrorContains(t, err, "segment not terminated")
+		_, err = edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN?"))
+		assert.ErrorContains(t, err, "dangling release character")
+		_, err = edifact.Parse([]byte("UNA:+"))
+		assert.ErrorContains(t, err, "incomplete UNA")
+		_, err = edifact.Parse([]byte(""))
+		assert.ErrorContains(t, err, "no messages")
+	})
+}
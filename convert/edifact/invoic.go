@@ -0,0 +1,843 @@
+package edifact
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// ISO 6523 schemes of the identifiers used in EANCOM messages.
+const (
+	schemeGLN  cbc.Code = "0088"
+	schemeGTIN cbc.Code = "0160"
+)
+
+// Code list responsible agency for GS1 codes.
+const agencyGS1 = "9"
+
+// Date formats (UNTDID 2379).
+const (
+	dateFormatDate   = "102" // CCYYMMDD
+	dateFormatPeriod = "718" // CCYYMMDD-CCYYMMDD
+)
+
+// sections of an INVOIC message.
+const (
+	sectionHeader = iota
+	sectionDetail
+	sectionSummary
+)
+
+// contexts define which segment group subsequent segments belong to.
+const (
+	contextNone = iota
+	contextParty
+	contextReference
+	contextTerms
+	contextAllowanceCharge
+	contextLine
+)
+
+// summaryAmounts are the MOA qualifiers of totals that will be calculated
+// by GOBL.
+var summaryAmounts = []string{
+	"9",   // amount due
+	"77",  // invoice amount
+	"79",  // total line items amount
+	"86",  // message total
+	"124", // tax amount
+	"125", // taxable amount
+	"128", // total amount
+	"129", // total amount subject to payment discount
+	"131", // total charges and allowances
+	"150", // tax amount in reporting currency
+	"176", // message total tax
+	"259", // total charges
+	"260", // total allowances
+	"388", // tax inclusive amount
+	"389", // tax exclusive amount
+}
+
+// lineAmounts are the MOA qualifiers in lines that will be calculated by
+// GOBL.
+var lineAmounts = []string{
+	"38",  // invoice item amount
+	"66",  // goods item total
+	"124", // tax amount
+	"125", // taxable amount
+	"203", // line item amount
+}
+
+// allowanceCharge collects the segments of an ALC group until they can be
+// added to the invoice or line.
+type allowanceCharge struct {
+	charge  bool
+	code    cbc.Code
+	reason  string
+	amount  num.Amount
+	base    *num.Amount
+	percent *num.Percentage
+	taxes   tax.Set
+	line    *bill.Line
+}
+
+// reader keeps track of the state while mapping the segments of a
+// message.
+type reader struct {
+	msg     *Message
+	inv     *bill.Invoice
+	report  *convert.Report
+	section int
+	context int
+
+	parties   map[string]*org.Party
+	party     *org.Party
+	ref       *org.DocumentRef
+	ac        *allowanceCharge
+	acs       []*allowanceCharge
+	line      *bill.Line
+	payment   *bill.PaymentDetails
+	dueDays   int
+	countries map[*org.Party]l10n.TaxCountryCode
+}
+
+// ToInvoice converts the INVOIC message into a GOBL invoice using the
+// EN 16931 addon and calculates the result. The report lists the segments
+// that could not be mapped.
+func (m *Message) ToInvoice() (*bill.Invoice, *convert.Report, error) {
+	if !m.Supported() {
+		return nil, nil, fmt.Errorf("%w: message %s:%s:%s", convert.ErrUnsupported, m.Type, m.Version, m.Release)
+	}
+	r := &reader{
+		msg: m,
+		inv: &bill.Invoice{
+			Addons: tax.WithAddons(en16931.V2017),
+		},
+		report:    new(convert.Report),
+		parties:   make(map[string]*org.Party),
+		payment:   new(bill.PaymentDetails),
+		countries: make(map[*org.Party]l10n.TaxCountryCode),
+	}
+	for _, seg := range m.Segments {
+		if err := r.read(seg); err != nil {
+			return nil, nil, fmt.Errorf("segment %d (%s): %w", seg.Position, seg.Tag, err)
+		}
+	}
+	r.complete()
+	if err := r.inv.Calculate(); err != nil {
+		return nil, nil, fmt.Errorf("calculating invoice: %w", err)
+	}
+	return r.inv, r.report, nil
+}
+
+// ToInvoices converts all the INVOIC messages in the interchange,
+// providing a report for each invoice.
+func (ic *Interchange) ToInvoices() ([]*bill.Invoice, []*convert.Report, error) {
+	invs := make([]*bill.Invoice, len(ic.Messages))
+	reports := make([]*convert.Report, len(ic.Messages))
+	for i, m := range ic.Messages {
+		inv, rep, err := m.ToInvoice()
+		if err != nil {
+			return nil, nil, fmt.Errorf("message %s: %w", m.Reference, err)
+		}
+		invs[i] = inv
+		reports[i] = rep
+	}
+	return invs, reports, nil
+}
+
+func (r *reader) unmapped(seg *Segment) {
+	r.report.Add(strconv.Itoa(seg.Position), seg.String())
+}
+
+func (r *reader) read(seg *Segment) error {
+	switch seg.Tag {
+	case tagUNH, tagUNT, "CNT":
+		return nil
+	case "BGM":
+		r.readBGM(seg)
+	case "DTM":
+		return r.readDTM(seg)
+	case "FTX":
+		r.readFTX(seg)
+	case "RFF":
+		r.readRFF(seg)
+	case "NAD":
+		r.readNAD(seg)
+	case "CTA":
+		r.readCTA(seg)
+	case "COM":
+		r.readCOM(seg)
+	case "CUX":
+		r.readCUX(seg)
+	case "PAT":
+		r.readPAT(seg)
+	case "PAI":
+		r.readPAI(seg)
+	case "FII":
+		r.readFII(seg)
+	case "ALC":
+		r.readALC(seg)
+	case "PCD":
+		return r.readPCD(seg)
+	case "MOA":
+		return r.readMOA(seg)
+	case "TAX":
+		return r.readTAX(seg)
+	case "LIN":
+		r.readLIN(seg)
+	case "PIA":
+		r.readPIA(seg)
+	case "IMD":
+		r.readIMD(seg)
+	case "QTY":
+		return r.readQTY(seg)
+	case "PRI":
+		return r.readPRI(seg)
+	case "UNS":
+		r.section = sectionSummary
+		r.context = contextNone
+		r.line = nil
+	default:
+		r.unmapped(seg)
+	}
+	return nil
+}
+
+func (r *reader) readBGM(seg *Segment) {
+	code := cbc.Code(seg.Value(0, 0))
+	r.inv.Type = convert.InvoiceType(code)
+	if code != cbc.CodeEmpty {
+		r.inv.Tax = &bill.Tax{
+			Ext: tax.Extensions{untdid.ExtKeyDocumentType: code},
+		}
+	}
+	r.inv.Code = cbc.Code(seg.Value(1, 0))
+}
+
+func (r *reader) readDTM(seg *Segment) error {
+	qual := seg.Value(0, 0)
+	value := seg.Value(0, 1)
+	format := seg.Value(0, 2)
+	if qual == "263" || format == dateFormatPeriod {
+		p, err := parsePeriod(value)
+		if err != nil {
+			return err
+		}
+		switch {
+		case r.line != nil:
+			r.line.Period = p
+		case r.section == sectionHeader:
+			r.ordering().Period = p
+		default:
+			r.unmapped(seg)
+		}
+		return nil
+	}
+	d, err := parseDate(value, format)
+	if err != nil {
+		return err
+	}
+	switch {
+	case r.context == contextReference && qual == "171":
+		r.ref.IssueDate = d
+	case qual == "13":
+		r.terms().DueDates = []*pay.DueDate{
+			{Date: d, Percent: num.NewPercentage(100, 2)},
+		}
+	case r.section != sectionHeader:
+		r.unmapped(seg)
+	case qual == "137" || qual == "3":
+		r.inv.IssueDate = *d
+	case qual == "131":
+		r.inv.OperationDate = d
+	case qual == "35":
+		if r.inv.Delivery == nil {
+			r.inv.Delivery = new(bill.DeliveryDetails)
+		}
+		r.inv.Delivery.Date = d
+	case qual == "194":
+		r.period().Start = *d
+	case qual == "206":
+		r.period().End = *d
+	default:
+		r.unmapped(seg)
+	}
+	return nil
+}
+
+func (r *reader) readFTX(seg *Segment) {
+	text := joinNonEmpty(seg.Elements, 3)
+	if text == "" {
+		r.unmapped(seg)
+		return
+	}
+	note := &org.Note{Text: text}
+	switch {
+	case r.line != nil:
+		r.line.Notes = append(r.line.Notes, note)
+	case seg.Qualifier() == "AAB":
+		t := r.terms()
+		t.Notes = strings.TrimSpace(t.Notes + " " + text)
+	default:
+		r.inv.Notes = append(r.inv.Notes, note)
+	}
+}
+
+func (r *reader) readRFF(seg *Segment) {
+	qual := seg.Value(0, 0)
+	code := cbc.Code(seg.Value(0, 1))
+	if code == cbc.CodeEmpty {
+		r.unmapped(seg)
+		return
+	}
+	if r.line != nil {
+		if qual == "ON" && seg.Value(0, 2) != "" {
+			r.line.Order = cbc.Code(seg.Value(0, 2))
+			return
+		}
+		r.unmapped(seg)
+		return
+	}
+	if r.context == contextParty {
+		switch qual {
+		case "VA":
+			r.party.TaxID = convert.ParseTaxIdentity(code.String(), r.countries[r.party])
+		case "XA", "FC", "GN":
+			r.party.Identities = append(r.party.Identities, &org.Identity{
+				Type: cbc.Code(qual),
+				Code: code,
+			})
+		default:
+			r.unmapped(seg)
+		}
+		return
+	}
+	if r.section != sectionHeader {
+		r.unmapped(seg)
+		return
+	}
+	ref := &org.DocumentRef{Code: code}
+	switch qual {
+	case "ON":
+		r.ordering().Purchases = append(r.ordering().Purchases, ref)
+	case "VN":
+		r.ordering().Sales = append(r.ordering().Sales, ref)
+	case "CT":
+		r.ordering().Contracts = append(r.ordering().Contracts, ref)
+	case "AAK", "DQ":
+		r.ordering().Despatch = append(r.ordering().Despatch, ref)
+	case "ALO":
+		r.ordering().Receiving = append(r.ordering().Receiving, ref)
+	case "IV", "OI":
+		r.inv.Preceding = append(r.inv.Preceding, ref)
+	case "PQ":
+		r.instructions().Ref = code
+		r.context = contextNone
+		return
+	default:
+		r.unmapped(seg)
+		return
+	}
+	r.ref = ref
+	r.context = contextReference
+}
+
+func (r *reader) readNAD(seg *Segment) {
+	r.line = nil
+	qual := seg.Qualifier()
+	switch qual {
+	case "SU", "SE", "BY", "IV", "PE", "DP":
+	default:
+		r.unmapped(seg)
+		r.context = contextNone
+		return
+	}
+	p := new(org.Party)
+	if id := seg.Value(1, 0); id != "" {
+		oid := &org.Identity{Code: cbc.Code(id)}
+		if seg.Value(1, 2) == agencyGS1 {
+			oid.Ext = tax.Extensions{iso.ExtKeySchemeID: schemeGLN}
+		}
+		p.Identities = append(p.Identities, oid)
+	}
+	p.Name = joinNonEmpty(seg.Elements, 3)
+	if p.Name == "" {
+		p.Name = seg.Value(2, 0)
+	}
+	addr := &org.Address{
+		Street:      seg.Value(4, 0),
+		StreetExtra: seg.Value(4, 1),
+		Locality:    seg.Value(5, 0),
+		Region:      seg.Value(6, 0),
+		Code:        cbc.Code(seg.Value(7, 0)),
+		Country:     l10n.ISOCountryCode(seg.Value(8, 0)),
+	}
+	if addr.Street != "" || addr.Locality != "" || addr.Code != cbc.CodeEmpty {
+		p.Addresses = []*org.Address{addr}
+	}
+	r.countries[p] = l10n.TaxCountryCode(addr.Country)
+	r.parties[qual] = p
+	r.party = p
+	r.context = contextParty
+}
+
+func (r *reader) readCTA(seg *Segment) {
+	if r.context != contextParty {
+		r.unmapped(seg)
+		return
+	}
+	if name := seg.Value(1, 1); name != "" {
+		r.party.People = append(r.party.People, &org.Person{
+			Name: &org.Name{Given: name},
+		})
+	}
+}
+
+func (r *reader) readCOM(seg *Segment) {
+	if r.context != contextParty {
+		r.unmapped(seg)
+		return
+	}
+	value := seg.Value(0, 0)
+	switch seg.Value(0, 1) {
+	case "TE":
+		r.party.Telephones = append(r.party.Telephones, &org.Telephone{Number: value})
+	case "EM":
+		r.party.Emails = append(r.party.Emails, &org.Email{Address: value})
+	default:
+		r.unmapped(seg)
+	}
+}
+
+func (r *reader) readCUX(seg *Segment) {
+	c := currency.Code(seg.Value(0, 1))
+	if c == currency.CodeEmpty || r.inv.Currency != currency.CodeEmpty {
+		r.unmapped(seg)
+		return
+	}
+	r.inv.Currency = c
+	r.context = contextNone
+}
+
+func (r *reader) readPAT(seg *Segment) {
+	r.context = contextTerms
+	r.terms()
+	// payment due a number of days after the invoice date
+	if seg.Value(2, 0) == "5" && seg.Value(2, 1) == "3" && seg.Value(2, 2) == "D" {
+		if n, err := strconv.Atoi(seg.Value(2, 3)); err == nil {
+			r.dueDays = n
+		}
+	}
+}
+
+func (r *reader) readPAI(seg *Segment) {
+	code := cbc.Code(seg.Value(0, 2))
+	if code == cbc.CodeEmpty {
+		r.unmapped(seg)
+		return
+	}
+	instr := r.instructions()
+	instr.Key = convert.PaymentMeansKey(code)
+	instr.Ext = instr.Ext.Set(untdid.ExtKeyPaymentMeans, code)
+}
+
+func (r *reader) readFII(seg *Segment) {
+	qual := seg.Qualifier()
+	account := seg.Value(1, 0)
+	if (qual != "RB" && qual != "BF") || account == "" {
+		r.unmapped(seg)
+		return
+	}
+	ct := &pay.CreditTransfer{
+		Name: seg.Value(1, 1),
+		BIC:  seg.Value(2, 0),
+	}
+	if convert.IsIBAN(account) {
+		ct.IBAN = account
+	} else {
+		ct.Number = account
+	}
+	instr := r.instructions()
+	if instr.Key == cbc.KeyEmpty {
+		instr.Key = pay.MeansKeyCreditTransfer
+	}
+	instr.CreditTransfer = append(instr.CreditTransfer, ct)
+}
+
+func (r *reader) readALC(seg *Segment) {
+	qual := seg.Qualifier()
+	if (qual != "A" && qual != "C") || r.section == sectionSummary {
+		r.unmapped(seg)
+		return
+	}
+	r.ac = &allowanceCharge{
+		charge: qual == "C",
+		code:   cbc.Code(seg.Value(4, 0)),
+		reason: seg.Value(4, 3),
+		line:   r.line,
+	}
+	r.acs = append(r.acs, r.ac)
+	r.context = contextAllowanceCharge
+}
+
+func (r *reader) readPCD(seg *Segment) error {
+	if r.context != contextAllowanceCharge {
+		r.unmapped(seg)
+		return nil
+	}
+	p, err := convert.ParsePercent(r.msg.number(seg.Value(0, 1)))
+	if err != nil {
+		return err
+	}
+	r.ac.percent = p
+	return nil
+}
+
+func (r *reader) readMOA(seg *Segment) error {
+	qual := seg.Value(0, 0)
+	amount, err := convert.ParseAmount(r.msg.number(seg.Value(0, 1)))
+	if err != nil {
+		return err
+	}
+	switch {
+	case r.context == contextAllowanceCharge:
+		switch qual {
+		case "8", "23", "204", "131":
+			r.ac.amount = amount
+		case "25":
+			r.ac.base = &amount
+		default:
+			r.unmapped(seg)
+		}
+	case r.section == sectionSummary && qual == "113":
+		if !amount.IsZero() {
+			r.payment.Advances = append(r.payment.Advances, &pay.Advance{
+				Description: "Prepaid amount",
+				Amount:      amount,
+			})
+		}
+	case r.section == sectionSummary && slices.Contains(summaryAmounts, qual):
+		// totals are calculated
+	case r.line != nil && slices.Contains(lineAmounts, qual):
+		// line amounts are calculated
+	default:
+		r.unmapped(seg)
+	}
+	return nil
+}
+
+func (r *reader) readTAX(seg *Segment) error {
+	if r.section == sectionSummary {
+		// tax totals are calculated
+		return nil
+	}
+	c, err := taxCombo(seg, r.msg)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		r.unmapped(seg)
+		return nil
+	}
+	switch {
+	case r.context == contextAllowanceCharge:
+		r.ac.taxes = append(r.ac.taxes, c)
+	case r.line != nil:
+		r.line.Taxes = append(r.line.Taxes, c)
+		r.context = contextLine
+	default:
+		r.unmapped(seg)
+	}
+	return nil
+}
+
+func (r *reader) readLIN(seg *Segment) {
+	r.section = sectionDetail
+	r.context = contextLine
+	r.line = &bill.Line{Item: new(org.Item)}
+	if code := seg.Value(2, 0); code != "" {
+		oid := &org.Identity{Code: cbc.Code(code)}
+		switch seg.Value(2, 1) {
+		case "EN", "SRV":
+			oid.Ext = tax.Extensions{iso.ExtKeySchemeID: schemeGTIN}
+		default:
+			oid.Type = cbc.Code(seg.Value(2, 1))
+		}
+		r.line.Item.Identities = append(r.line.Item.Identities, oid)
+	}
+	r.inv.Lines = append(r.inv.Lines, r.line)
+}
+
+func (r *reader) readPIA(seg *Segment) {
+	if r.line == nil || len(seg.Elements) < 2 {
+		r.unmapped(seg)
+		return
+	}
+	for _, e := range seg.Elements[1:] {
+		if len(e) == 0 || e[0] == "" {
+			continue
+		}
+		code := cbc.Code(e[0])
+		typ := ""
+		if len(e) > 1 {
+			typ = e[1]
+		}
+		if typ == "SA" && r.line.Item.Ref == cbc.CodeEmpty {
+			r.line.Item.Ref = code
+			continue
+		}
+		r.line.Item.Identities = append(r.line.Item.Identities, &org.Identity{
+			Type: cbc.Code(typ),
+			Code: code,
+		})
+	}
+}
+
+func (r *reader) readIMD(seg *Segment) {
+	text := strings.TrimSpace(seg.Value(2, 3) + " " + seg.Value(2, 4))
+	if r.line == nil || text == "" {
+		r.unmapped(seg)
+		return
+	}
+	if r.line.Item.Name == "" {
+		r.line.Item.Name = text
+		return
+	}
+	r.line.Item.Description = strings.TrimSpace(r.line.Item.Description + " " + text)
+}
+
+func (r *reader) readQTY(seg *Segment) error {
+	if r.line == nil || seg.Value(0, 0) != "47" {
+		r.unmapped(seg)
+		return nil
+	}
+	q, err := convert.ParseAmount(r.msg.number(seg.Value(0, 1)))
+	if err != nil {
+		return fmt.Errorf("quantity: %w", err)
+	}
+	r.line.Quantity = q
+	r.line.Item.Unit = convert.UnitFromUNECE(seg.Value(0, 2))
+	return nil
+}
+
+func (r *reader) readPRI(seg *Segment) error {
+	qual := seg.Value(0, 0)
+	if r.line == nil || (qual != "AAA" && qual != "AAB") {
+		r.unmapped(seg)
+		return nil
+	}
+	if qual == "AAB" && r.line.Item.Price != nil {
+		// net price takes priority over the gross price
+		return nil
+	}
+	p, err := convert.ParseAmount(r.msg.number(seg.Value(0, 1)))
+	if err != nil {
+		return fmt.Errorf("price: %w", err)
+	}
+	if b := seg.Value(0, 4); b != "" {
+		// prices may be provided for a base quantity other than 1
+		base, err := convert.ParseAmount(r.msg.number(b))
+		if err != nil {
+			return fmt.Errorf("price basis: %w", err)
+		}
+		if !base.IsZero() {
+			p = p.Divide(base)
+		}
+	}
+	r.line.Item.Price = &p
+	return nil
+}
+
+// complete assigns the parties, payment details, and allowances and
+// charges collected from the segments.
+func (r *reader) complete() {
+	inv := r.inv
+	inv.Supplier = r.parties["SU"]
+	if inv.Supplier == nil {
+		inv.Supplier = r.parties["SE"]
+	}
+	inv.Customer = r.parties["BY"]
+	if inv.Customer == nil {
+		inv.Customer = r.parties["IV"]
+	}
+	for _, p := range []*org.Party{inv.Supplier, inv.Customer} {
+		if p != nil && p.TaxID == nil && r.countries[p] != "" {
+			p.TaxID = &tax.Identity{Country: r.countries[p]}
+		}
+	}
+	if p := r.parties["DP"]; p != nil {
+		if inv.Delivery == nil {
+			inv.Delivery = new(bill.DeliveryDetails)
+		}
+		inv.Delivery.Receiver = p
+	}
+	if p := r.parties["PE"]; p != nil {
+		r.payment.Payee = p
+	}
+	if r.dueDays > 0 && !inv.IssueDate.IsZero() {
+		t := r.terms()
+		if len(t.DueDates) == 0 {
+			d := inv.IssueDate.Add(0, 0, r.dueDays)
+			t.DueDates = []*pay.DueDate{
+				{Date: &d, Percent: num.NewPercentage(100, 2)},
+			}
+		}
+	}
+	if r.payment.Terms != nil || r.payment.Instructions != nil ||
+		r.payment.Advances != nil || r.payment.Payee != nil {
+		inv.Payment = r.payment
+	}
+	for _, ac := range r.acs {
+		ac.add(inv)
+	}
+}
+
+func (ac *allowanceCharge) add(inv *bill.Invoice) {
+	if ac.line != nil {
+		if ac.charge {
+			c := &bill.LineCharge{
+				Reason: ac.reason, Amount: ac.amount, Base: ac.base, Percent: ac.percent,
+			}
+			if ac.code != cbc.CodeEmpty {
+				c.Ext = tax.Extensions{untdid.ExtKeyCharge: ac.code}
+			}
+			ac.line.Charges = append(ac.line.Charges, c)
+			return
+		}
+		d := &bill.LineDiscount{
+			Reason: ac.reason, Amount: ac.amount, Base: ac.base, Percent: ac.percent,
+		}
+		if ac.code != cbc.CodeEmpty {
+			d.Ext = tax.Extensions{untdid.ExtKeyAllowance: ac.code}
+		}
+		ac.line.Discounts = append(ac.line.Discounts, d)
+		return
+	}
+	if ac.charge {
+		c := &bill.Charge{
+			Reason: ac.reason, Amount: ac.amount, Base: ac.base, Percent: ac.percent, Taxes: ac.taxes,
+		}
+		if ac.code != cbc.CodeEmpty {
+			c.Ext = tax.Extensions{untdid.ExtKeyCharge: ac.code}
+		}
+		inv.Charges = append(inv.Charges, c)
+		return
+	}
+	d := &bill.Discount{
+		Reason: ac.reason, Amount: ac.amount, Base: ac.base, Percent: ac.percent, Taxes: ac.taxes,
+	}
+	if ac.code != cbc.CodeEmpty {
+		d.Ext = tax.Extensions{untdid.ExtKeyAllowance: ac.code}
+	}
+	inv.Discounts = append(inv.Discounts, d)
+}
+
+func (r *reader) ordering() *bill.Ordering {
+	if r.inv.Ordering == nil {
+		r.inv.Ordering = new(bill.Ordering)
+	}
+	return r.inv.Ordering
+}
+
+func (r *reader) period() *cal.Period {
+	o := r.ordering()
+	if o.Period == nil {
+		o.Period = new(cal.Period)
+	}
+	return o.Period
+}
+
+func (r *reader) terms() *pay.Terms {
+	if r.payment.Terms == nil {
+		r.payment.Terms = new(pay.Terms)
+	}
+	return r.payment.Terms
+}
+
+func (r *reader) instructions() *pay.Instructions {
+	if r.payment.Instructions == nil {
+		r.payment.Instructions = new(pay.Instructions)
+	}
+	return r.payment.Instructions
+}
+
+// taxCombo maps the TAX segment into a VAT combo, or nil if another type
+// of tax is used.
+func taxCombo(seg *Segment, m *Message) (*tax.Combo, error) {
+	if seg.Value(1, 0) != "VAT" {
+		return nil, nil
+	}
+	code := cbc.Code(seg.Value(5, 0))
+	rate := m.number(seg.Value(4, 3))
+	if code == cbc.CodeEmpty {
+		code = convert.TaxCategoryStandard
+	}
+	c, err := convert.VATCombo(code, rate, cbc.CodeEmpty)
+	if err != nil {
+		return nil, fmt.Errorf("tax: %w", err)
+	}
+	return c, nil
+}
+
+func parseDate(value, format string) (*cal.Date, error) {
+	if format != "" && format != dateFormatDate && len(value) > 8 {
+		// date and time formats start with the date
+		value = value[:8]
+	}
+	d, err := convert.ParseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.New("date required")
+	}
+	return d, nil
+}
+
+func parsePeriod(value string) (*cal.Period, error) {
+	parts := strings.SplitN(value, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid period '%s'", value)
+	}
+	start, err := parseDate(parts[0], dateFormatDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(parts[1], dateFormatDate)
+	if err != nil {
+		return nil, err
+	}
+	return &cal.Period{Start: *start, End: *end}, nil
+}
+
+// joinNonEmpty joins the non-empty components of the element at the
+// index.
+func joinNonEmpty(elements [][]string, i int) string {
+	if i >= len(elements) {
+		return ""
+	}
+	var parts []string
+	for _, c := range elements[i] {
+		if c = strings.TrimSpace(c); c != "" {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,203 @@
+package edifact_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/edifact"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testINVOIC = `UNA:+.? '
+UNB+UNOC:3+4000001000002:14+5412345000013:14+240601:1200+IC0001'
+UNH+M1+INVOIC:D:96A:UN:EAN008'
+BGM+380+INV-2024-001+9'
+DTM+137:20240601:102'
+DTM+131:20240531:102'
+DTM+263:20240501-20240531:718'
+FTX+AAI+++Thank you for your business'
+LOC+22+DEPOT1'
+RFF+ON:PO-998'
+DTM+171:20240515:102'
+RFF+CT:CON-1'
+NAD+SU+4000001000002::9++Provide One GmbH+Dietmar-Hopp-Allee 16+Walldorf++69190+DE'
+RFF+VA:DE111111125'
+CTA+IC+:John Smith'
+COM+billing@example.com:EM'
+NAD+BY+5412345000013::9++Sample Consumer+Werner-Heisenberg-Allee 25+Muenchen++80939+DE'
+RFF+VA:DE282741168'
+NAD+DP+5412345000020::9'
+CUX+2:EUR:4'
+PAT+1++5:3:D:30'
+PAI+::58'
+FII+RB+DE89370400440532013000:Provide One GmbH+COBADEFFXXX:25:17'
+ALC+C++++FC:::Freight'
+MOA+23:10'
+TAX+7+VAT+++:::19+S'
+LIN+1++4000862141404:SRV'
+PIA+1+DEV-01:SA'
+IMD+F++:::Development services'
+QTY+47:20:HUR'
+MOA+203:1620'
+PRI+AAA:90'
+TAX+7+VAT+++:::19+S'
+ALC+A++++95:::Special discount'
+PCD+1:10'
+LIN+2'
+IMD+F++:::Training materials'
+QTY+47:1:C62'
+QTY+46:1'
+PRI+AAA:500:::10'
+TAX+7+VAT+++:::7+S'
+UNS+S'
+MOA+79:1670'
+MOA+86:1993.20'
+TAX+7+VAT+++:::19+S'
+MOA+124:309.70'
+MOA+113:100'
+MOA+999:1'
+CNT+2:2'
+UNT+48+M1'
+UNZ+1+IC0001'
+`
+
+func TestMessageToInvoice(t *testing.T) {
+	ic, err := edifact.Parse([]byte(testINVOIC))
+	require.NoError(t, err)
+	inv, rep, err := ic.Messages[0].ToInvoice()
+	require.NoError(t, err)
+
+	assert.Equal(t, bill.InvoiceTypeStandard, inv.Type)
+	assert.Equal(t, cbc.Code("380"), inv.Tax.Ext.Get(untdid.ExtKeyDocumentType))
+	assert.Equal(t, "INV-2024-001", inv.Code.String())
+	assert.Equal(t, "2024-06-01", inv.IssueDate.String())
+	assert.Equal(t, "2024-05-31", inv.OperationDate.String())
+	assert.Equal(t, "EUR", inv.Currency.String())
+	require.Len(t, inv.Notes, 1)
+	assert.Equal(t, "Thank you for your business", inv.Notes[0].Text)
+
+	require.NotNil(t, inv.Ordering)
+	assert.Equal(t, "2024-05-01", inv.Ordering.Period.Start.String())
+	assert.Equal(t, "PO-998", inv.Ordering.Purchases[0].Code.String())
+	assert.Equal(t, "2024-05-15", inv.Ordering.Purchases[0].IssueDate.String())
+	assert.Equal(t, "CON-1", inv.Ordering.Contracts[0].Code.String())
+
+	sup := inv.Supplier
+	assert.Equal(t, "Provide One GmbH", sup.Name)
+	assert.Equal(t, "DE", sup.TaxID.Country.String())
+	assert.Equal(t, "111111125", sup.TaxID.Code.String())
+	assert.Equal(t, "4000001000002", sup.Identities[0].Code.String())
+	assert.Equal(t, cbc.Code("0088"), sup.Identities[0].Ext.Get(iso.ExtKeySchemeID))
+	assert.Equal(t, "Walldorf", sup.Addresses[0].Locality)
+	assert.Equal(t, "John Smith", sup.People[0].Name.Given)
+	assert.Equal(t, "billing@example.com", sup.Emails[0].Address)
+	assert.Equal(t, "Sample Consumer", inv.Customer.Name)
+	assert.Equal(t, "282741168", inv.Customer.TaxID.Code.String())
+	assert.Equal(t, "5412345000020", inv.Delivery.Receiver.Identities[0].Code.String())
+
+	require.NotNil(t, inv.Payment)
+	assert.Equal(t, "2024-07-01", inv.Payment.Terms.DueDates[0].Date.String())
+	instr := inv.Payment.Instructions
+	assert.Equal(t, pay.MeansKeyCreditTransfer.With(pay.MeansKeySEPA), instr.Key)
+	assert.Equal(t, "DE89370400440532013000", instr.CreditTransfer[0].IBAN)
+	assert.Equal(t, "COBADEFFXXX", instr.CreditTransfer[0].BIC)
+
+	require.Len(t, inv.Charges, 1)
+	assert.Equal(t, "Freight", inv.Charges[0].Reason)
+	assert.Equal(t, "10.00", inv.Charges[0].Amount.String())
+	assert.Equal(t, cbc.Code("FC"), inv.Charges[0].Ext.Get(untdid.ExtKeyCharge))
+
+	require.Len(t, inv.Lines, 2)
+	l1 := inv.Lines[0]
+	assert.Equal(t, "Development services", l1.Item.Name)
+	assert.Equal(t, "DEV-01", l1.Item.Ref.String())
+	assert.Equal(t, cbc.Code("0160"), l1.Item.Identities[0].Ext.Get(iso.ExtKeySchemeID))
+	assert.Equal(t, org.UnitHour, l1.Item.Unit)
+	assert.Equal(t, "20", l1.Quantity.String())
+	assert.Equal(t, "1620.00", l1.Total.String())
+	assert.Equal(t, "Special discount", l1.Discounts[0].Reason)
+	l2 := inv.Lines[1]
+	assert.Equal(t, "50.00", l2.Item.Price.String())
+	assert.Equal(t, "7%", l2.Taxes[0].Percent.String())
+
+	assert.Equal(t, "1670.00", inv.Totals.Sum.String())
+	assert.Equal(t, "313.20", inv.Totals.Tax.String())
+	assert.Equal(t, "1993.20", inv.Totals.Payable.String())
+	assert.Equal(t, "1893.20", inv.Totals.Due.String())
+
+	require.Len(t, rep.Unmapped, 3)
+	assert.Equal(t, &convert.Unmapped{Location: "7", Content: "LOC+22+DEPOT1"}, rep.Unmapped[0])
+	assert.Equal(t, "QTY+46:1", rep.Unmapped[1].Content)
+	assert.Equal(t, "MOA+999:1", rep.Unmapped[2].Content)
+}
+
+func TestMessageToInvoiceCreditNote(t *testing.T) {
+	data := `UNH+1+INVOIC:D:01B:UN'
+BGM+381+CN-1'
+DTM+137:202406011200:203'
+RFF+IV:INV-2024-001'
+DTM+171:20240601:102'
+NAD+SE+++Provide One GmbH+Dietmar-Hopp-Allee 16+Walldorf++69190+DE'
+NAD+IV+++Sample Consumer+Werner-Heisenberg-Allee 25+Muenchen++80939+DE'
+CUX+2:EUR:4'
+DTM+13:20240630:102'
+LIN+1'
+IMD+F++:::Returned item'
+QTY+47:1:C62'
+PRI+AAB:100'
+TAX+7+VAT+++:::19+S'
+UNS+S'
+UNT+16+1'`
+	ic, err := edifact.Parse([]byte(data))
+	require.NoError(t, err)
+	invs, reps, err := ic.ToInvoices()
+	require.NoError(t, err)
+	require.Len(t, invs, 1)
+	inv := invs[0]
+	assert.Equal(t, bill.InvoiceTypeCreditNote, inv.Type)
+	assert.Equal(t, "2024-06-01", inv.IssueDate.String())
+	assert.Equal(t, "INV-2024-001", inv.Preceding[0].Code.String())
+	assert.Equal(t, "Provide One GmbH", inv.Supplier.Name)
+	assert.Equal(t, "DE", inv.Supplier.TaxID.Country.String())
+	assert.Equal(t, "Sample Consumer", inv.Customer.Name)
+	assert.Equal(t, "2024-06-30", inv.Payment.Terms.DueDates[0].Date.String())
+	assert.Equal(t, "119.00", inv.Totals.Payable.String())
+	assert.True(t, reps[0].Empty())
+}
+
+func TestMessageToInvoiceErrors(t *testing.T) {
+	t.Run("unsupported message", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte("UNH+1+ORDERS:D:96A:UN'UNT+2+1'"))
+		require.NoError(t, err)
+		_, _, err = ic.Messages[0].ToInvoice()
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		_, _, err = ic.ToInvoices()
+		assert.ErrorContains(t, err, "message 1")
+	})
+	t.Run("unsupported release", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte("UNH+1+INVOIC:D:93A:UN'UNT+2+1'"))
+		require.NoError(t, err)
+		_, _, err = ic.Messages[0].ToInvoice()
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("invalid date", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'DTM+137:2024XX01:102'UNT+3+1'"))
+		require.NoError(t, err)
+		_, _, err = ic.Messages[0].ToInvoice()
+		assert.ErrorContains(t, err, "segment 2 (DTM): invalid date")
+	})
+	t.Run("invalid amount", func(t *testing.T) {
+		ic, err := edifact.Parse([]byte("UNH+1+INVOIC:D:96A:UN'LIN+1'QTY+47:abc'UNT+4+1'"))
+		require.NoError(t, err)
+		_, _, err = ic.Messages[0].ToInvoice()
+		assert.ErrorContains(t, err, "segment 3 (QTY): quantity: invalid amount")
+	})
+}
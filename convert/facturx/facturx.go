@@ -0,0 +1,275 @@
+// Package facturx embeds CII invoices into PDF documents to produce
+// Factur-X / ZUGFeRD hybrid invoices, and extracts them again from
+// received PDFs.
+//
+// The embedded XML is attached as an associated file of the document
+// catalog with the `Alternative` relationship, and the XMP metadata is
+// replaced with a PDF/A-3 description including the Factur-X extension
+// schema. Changes are appended as an incremental update so the original
+// content and any previous revisions remain untouched. Ensuring the rest
+// of the PDF complies with PDF/A-3 (fonts, color profiles, etc.) is the
+// responsibility of whoever generated it.
+package facturx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/cii"
+)
+
+// FileName is the name used for the embedded XML document.
+const FileName = "factur-x.xml"
+
+// Version of the Factur-X standard described in the XMP metadata.
+const Version = "1.0"
+
+// Conformance levels described in the XMP metadata.
+const (
+	ConformanceEN16931   = "EN 16931"
+	ConformanceXRechnung = "XRECHNUNG"
+)
+
+// fileNames lists the attachment names used by Factur-X and the different
+// ZUGFeRD versions, in order of preference.
+var fileNames = []string{
+	FileName,
+	"zugferd-invoice.xml",
+	"xrechnung.xml",
+}
+
+// ErrNotFound is returned when the PDF does not contain an embedded
+// invoice.
+var ErrNotFound = errors.New("embedded invoice not found")
+
+// now provides the time used for modification dates.
+var now = time.Now
+
+// Embed converts the calculated invoice into CII and embeds it into the
+// PDF. The conformance level is determined from the invoice's addons.
+func Embed(pdf []byte, inv *bill.Invoice, opts ...cii.Option) ([]byte, error) {
+	data, err := cii.ConvertInvoice(inv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	level := ConformanceEN16931
+	if convert.GuidelineID(inv) == convert.GuidelineXRechnung {
+		level = ConformanceXRechnung
+	}
+	return EmbedXML(pdf, data, level)
+}
+
+// EmbedXML appends the CII XML data to the PDF as the Factur-X attachment
+// with the given conformance level.
+func EmbedXML(pdf []byte, data []byte, level string) ([]byte, error) {
+	f, err := parsePDF(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	rootRef, root, err := f.catalog()
+	if err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	ts := now().UTC()
+	w := newPDFWriter(f)
+
+	// Embedded file stream
+	compressed := new(bytes.Buffer)
+	zw := zlib.NewWriter(compressed)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	fileRef := w.reserve()
+	w.write(fileRef, pdfDict{
+		{key: "Type", raw: []byte("/EmbeddedFile")},
+		{key: "Subtype", raw: []byte("/text#2Fxml")},
+		{key: "Filter", raw: []byte("/FlateDecode")},
+		{key: "Length", raw: []byte(strconv.Itoa(compressed.Len()))},
+		{key: "Params", raw: pdfDict{
+			{key: "Size", raw: []byte(strconv.Itoa(len(data)))},
+			{key: "ModDate", raw: encodeString(pdfDate(ts))},
+		}.bytes()},
+	}.bytes(), compressed.Bytes())
+
+	// File specification
+	specRef := w.reserve()
+	name := encodeString(FileName)
+	w.write(specRef, pdfDict{
+		{key: "Type", raw: []byte("/Filespec")},
+		{key: "F", raw: name},
+		{key: "UF", raw: name},
+		{key: "Desc", raw: encodeString("Factur-X invoice")},
+		{key: "AFRelationship", raw: []byte("/Alternative")},
+		{key: "EF", raw: pdfDict{
+			{key: "F", raw: []byte(fileRef.String())},
+			{key: "UF", raw: []byte(fileRef.String())},
+		}.bytes()},
+	}.bytes(), nil)
+
+	// Metadata
+	xmp, err := buildXMP(&xmpData{
+		FileName:         FileName,
+		Version:          Version,
+		ConformanceLevel: level,
+		ModifyDate:       ts.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	metaRef := w.reserve()
+	w.write(metaRef, pdfDict{
+		{key: "Type", raw: []byte("/Metadata")},
+		{key: "Subtype", raw: []byte("/XML")},
+		{key: "Length", raw: []byte(strconv.Itoa(len(xmp)))},
+	}.bytes(), xmp)
+
+	// Name tree, which may be inline in the catalog or a separate object
+	embedded := pdfDict{
+		{key: "Names", raw: []byte("[" + string(name) + " " + specRef.String() + "]")},
+	}.bytes()
+	if raw := root.get("Names"); raw != nil {
+		names := f.resolveDict(raw)
+		if names == nil {
+			return nil, fmt.Errorf("facturx: %w: names dictionary", errPDFInvalid)
+		}
+		if names.get("EmbeddedFiles") != nil {
+			return nil, fmt.Errorf("facturx: %w: document already has embedded files", convert.ErrUnsupported)
+		}
+		names = names.set("EmbeddedFiles", embedded)
+		if ref, ok := parseRef(raw); ok {
+			w.write(ref, names.bytes(), nil)
+		} else {
+			root = root.set("Names", names.bytes())
+		}
+	} else {
+		root = root.set("Names", pdfDict{{key: "EmbeddedFiles", raw: embedded}}.bytes())
+	}
+
+	// Catalog
+	root = root.set("Metadata", []byte(metaRef.String()))
+	root = root.set("AF", []byte("["+specRef.String()+"]"))
+	w.write(rootRef, root.bytes(), nil)
+
+	return w.finish(documentID(f, data, ts)), nil
+}
+
+// Extract provides the XML data of the invoice embedded in the PDF.
+func Extract(pdf []byte) ([]byte, error) {
+	f, err := parsePDF(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+	_, root, err := f.catalog()
+	if err != nil {
+		return nil, fmt.Errorf("facturx: %w", err)
+	}
+
+	specs := make(map[string]pdfDict)
+	if names := f.resolveDict(root.get("Names")); names != nil {
+		f.collectNameTree(f.resolveDict(names.get("EmbeddedFiles")), specs, 0)
+	}
+	if list, err := parseArray(root.get("AF")); err == nil {
+		for _, raw := range list {
+			if spec := f.resolveDict(raw); spec != nil {
+				specs[strings.ToLower(specFileName(spec))] = spec
+			}
+		}
+	}
+
+	for _, fn := range fileNames {
+		spec, ok := specs[fn]
+		if !ok {
+			continue
+		}
+		ef := f.resolveDict(spec.get("EF"))
+		if ef == nil {
+			continue
+		}
+		ref, ok := ef.ref("UF")
+		if !ok {
+			if ref, ok = ef.ref("F"); !ok {
+				continue
+			}
+		}
+		obj, ok := f.objects[ref.num]
+		if !ok || obj.stream == nil {
+			continue
+		}
+		data, err := decodeStream(obj.dict, obj.stream)
+		if err != nil {
+			return nil, fmt.Errorf("facturx: %s: %w", fn, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("facturx: %w", ErrNotFound)
+}
+
+// ParseInvoice extracts the embedded CII document from the PDF and
+// converts it into a calculated GOBL invoice.
+func ParseInvoice(pdf []byte) (*bill.Invoice, error) {
+	data, err := Extract(pdf)
+	if err != nil {
+		return nil, err
+	}
+	return cii.ParseInvoice(data)
+}
+
+// collectNameTree adds the file specifications found in the name tree node
+// to the map using their lower case names.
+func (f *pdfFile) collectNameTree(node pdfDict, specs map[string]pdfDict, depth int) {
+	if node == nil || depth > 32 {
+		return
+	}
+	if list, err := parseArray(node.get("Names")); err == nil {
+		for i := 0; i+1 < len(list); i += 2 {
+			spec := f.resolveDict(list[i+1])
+			if spec == nil {
+				continue
+			}
+			name := specFileName(spec)
+			if name == "" {
+				name = decodeString(list[i])
+			}
+			specs[strings.ToLower(name)] = spec
+		}
+	}
+	if kids, err := parseArray(node.get("Kids")); err == nil {
+		for _, raw := range kids {
+			f.collectNameTree(f.resolveDict(raw), specs, depth+1)
+		}
+	}
+}
+
+func specFileName(spec pdfDict) string {
+	if raw := spec.get("UF"); raw != nil {
+		return decodeString(raw)
+	}
+	return decodeString(spec.get("F"))
+}
+
+// documentID keeps the permanent identifier of the original document and
+// generates a new one for this revision.
+func documentID(f *pdfFile, data []byte, ts time.Time) []byte {
+	sum := md5.Sum(append(append([]byte{}, data...), ts.String()...))
+	changing := fmt.Sprintf("<%x>", sum)
+	permanent := changing
+	if list, err := parseArray(f.trailer.get("ID")); err == nil && len(list) == 2 {
+		permanent = string(list[0])
+	}
+	return []byte("[" + permanent + " " + changing + "]")
+}
+
+func pdfDate(ts time.Time) string {
+	return "D:" + ts.Format("20060102150405") + "Z"
+}
@@ -0,0 +1,263 @@
+package facturx_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/de/xrechnung"
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/facturx"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T, addon cbc.Key) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(addon),
+		Series:    "SAMPLE",
+		Code:      "001",
+		Currency:  "EUR",
+		IssueDate: cal.MakeDate(2024, 2, 13),
+		Supplier: &org.Party{
+			Name:  "Provide One GmbH",
+			TaxID: &tax.Identity{Country: "DE", Code: "111111125"},
+			Inboxes: []*org.Inbox{
+				{Email: "inbox@example.com"},
+			},
+			Addresses: []*org.Address{
+				{Street: "Dietmar-Hopp-Allee", Locality: "Walldorf", Code: "69190", Country: "DE"},
+			},
+		},
+		Customer: &org.Party{
+			Name:  "Sample Consumer",
+			TaxID: &tax.Identity{Country: "DE", Code: "282741168"},
+			Addresses: []*org.Address{
+				{Street: "Werner-Heisenberg-Allee", Locality: "München", Code: "80939", Country: "DE"},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(20, 0),
+				Item: &org.Item{
+					Name:  "Development services",
+					Price: num.NewAmount(9000, 2),
+					Unit:  org.UnitHour,
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+// buildPDF generates a minimal single page PDF with a classic
+// cross-reference table, using the provided catalog entries.
+func buildPDF(extra string, objs ...string) []byte {
+	objects := append([]string{
+		"<< /Type /Catalog /Pages 2 0 R" + extra + " >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+	}, objs...)
+	buf := new(bytes.Buffer)
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	start := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n\r\n", off)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R /ID [<0102> <0102>] >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, start)
+	return buf.Bytes()
+}
+
+// buildCompressedPDF generates a PDF whose catalog is stored inside an
+// object stream, referenced from a cross-reference stream.
+func buildCompressedPDF(t *testing.T) []byte {
+	t.Helper()
+	inner := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Lang (en) >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	}
+	header := new(bytes.Buffer)
+	body := new(bytes.Buffer)
+	for i, o := range inner {
+		fmt.Fprintf(header, "%d %d ", i+1, body.Len())
+		body.WriteString(o + "\n")
+	}
+	compressed := new(bytes.Buffer)
+	zw := zlib.NewWriter(compressed)
+	_, err := zw.Write(append(header.Bytes(), body.Bytes()...))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("%PDF-1.7\n")
+	off3 := buf.Len()
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>\nendobj\n")
+	off4 := buf.Len()
+	fmt.Fprintf(buf, "4 0 obj\n<< /Type /ObjStm /N 2 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", header.Len(), compressed.Len())
+	buf.Write(compressed.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+	off5 := buf.Len()
+	xref := []byte{
+		0, 0, 0, 255,
+		2, 0, 4, 0,
+		2, 0, 4, 1,
+		1, byte(off3 >> 8), byte(off3), 0,
+		1, byte(off4 >> 8), byte(off4), 0,
+		1, byte(off5 >> 8), byte(off5), 0,
+	}
+	fmt.Fprintf(buf, "5 0 obj\n<< /Type /XRef /Size 6 /W [1 2 1] /Root 1 0 R /Length %d >>\nstream\n", len(xref))
+	buf.Write(xref)
+	fmt.Fprintf(buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", off5)
+	return buf.Bytes()
+}
+
+func TestEmbed(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		inv := testInvoice(t, en16931.V2017)
+		pdf := buildPDF("")
+		out, err := facturx.Embed(pdf, inv)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out, pdf), "should append an incremental update")
+		assert.Contains(t, string(out), "/AFRelationship /Alternative")
+		assert.Contains(t, string(out), "<fx:ConformanceLevel>EN 16931</fx:ConformanceLevel>")
+		assert.Contains(t, string(out), "/Prev ")
+		assert.Contains(t, string(out), "/ID [<0102> <")
+
+		res, err := facturx.ParseInvoice(out)
+		require.NoError(t, err)
+		assert.Equal(t, cbc.Code("SAMPLE-001"), res.Code)
+		assert.Equal(t, inv.Totals.Payable.String(), res.Totals.Payable.String())
+	})
+	t.Run("xrechnung", func(t *testing.T) {
+		out, err := facturx.Embed(buildPDF(""), testInvoice(t, xrechnung.V3))
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "<fx:ConformanceLevel>XRECHNUNG</fx:ConformanceLevel>")
+	})
+	t.Run("referenced names", func(t *testing.T) {
+		pdf := buildPDF(" /Names 4 0 R", "<< /Dests 5 0 R >>", "<< /Names [] >>")
+		out, err := facturx.EmbedXML(pdf, []byte("<xml/>"), facturx.ConformanceEN16931)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "4 0 obj\n<< /Dests 5 0 R /EmbeddedFiles")
+		data, err := facturx.Extract(out)
+		require.NoError(t, err)
+		assert.Equal(t, "<xml/>", string(data))
+	})
+	t.Run("existing attachments", func(t *testing.T) {
+		pdf := buildPDF(" /Names << /EmbeddedFiles << /Names [] >> >>")
+		_, err := facturx.EmbedXML(pdf, []byte("<xml/>"), facturx.ConformanceEN16931)
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("object streams", func(t *testing.T) {
+		pdf := buildCompressedPDF(t)
+		out, err := facturx.EmbedXML(pdf, []byte("<xml/>"), facturx.ConformanceEN16931)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "/Type /XRef /W [1 4 2]")
+		assert.Contains(t, string(out), "/Lang (en)")
+		data, err := facturx.Extract(out)
+		require.NoError(t, err)
+		assert.Equal(t, "<xml/>", string(data))
+	})
+	t.Run("invalid pdf", func(t *testing.T) {
+		_, err := facturx.EmbedXML([]byte("not a pdf"), []byte("<xml/>"), facturx.ConformanceEN16931)
+		assert.ErrorContains(t, err, "facturx: invalid pdf: missing header")
+	})
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("zugferd name", func(t *testing.T) {
+		pdf := buildPDF(
+			" /Names << /EmbeddedFiles << /Names [(ZUGFeRD-invoice.xml) 4 0 R] >> >>",
+			"<< /Type /Filespec /F (ZUGFeRD-invoice.xml) /EF << /F 5 0 R >> >>",
+			"<< /Type /EmbeddedFile /Length 6 >>\nstream\n<xml/>\nendstream",
+		)
+		data, err := facturx.Extract(pdf)
+		require.NoError(t, err)
+		assert.Equal(t, "<xml/>", string(data))
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := facturx.Extract(buildPDF(""))
+		assert.ErrorIs(t, err, facturx.ErrNotFound)
+	})
+	t.Run("negative offsets", func(t *testing.T) {
+		pdf := buildPDF(
+			"",
+			"<< /Type /ObjStm /N 1 /First -5 /Length 4 >>\nstream\n1 0 \nendstream",
+			"<< /Type /ObjStm /N 1 /First 4 /Length 8 >>\nstream\n1 -9 <<>>\nendstream",
+			"<< /Length -20 >>\nstream\n<xml/>\nendstream",
+		)
+		_, err := facturx.Extract(pdf)
+		assert.ErrorIs(t, err, facturx.ErrNotFound)
+	})
+	t.Run("stream too large", func(t *testing.T) {
+		compressed := new(bytes.Buffer)
+		zw := zlib.NewWriter(compressed)
+		_, err := zw.Write(make([]byte, 33<<20))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		pdf := buildPDF(
+			" /Names << /EmbeddedFiles << /Names [(factur-x.xml) 4 0 R] >> >>",
+			"<< /Type /Filespec /F (factur-x.xml) /EF << /F 5 0 R >> >>",
+			fmt.Sprintf("<< /Type /EmbeddedFile /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()),
+		)
+		_, err = facturx.Extract(pdf)
+		assert.ErrorContains(t, err, "stream too large")
+	})
+}
+
+func TestExtractCraftedObjects(t *testing.T) {
+	// each of these would be scanned to the end of the data once per
+	// object header without limits
+	tests := map[string]string{
+		"unterminated arrays":  strings.Repeat("1 0 obj [", 20000),
+		"nested arrays":        strings.Repeat("1 0 obj [", 20000) + ")",
+		"nested dictionaries":  strings.Repeat("1 0 obj <<", 20000) + ")",
+		"unterminated strings": strings.Repeat("1 0 obj (", 20000),
+		"missing endstream":    strings.Repeat("1 0 obj << >> stream\n", 20000),
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			pdf := []byte("%PDF-1.7\n" + body + "\nstartxref\n9\n%%EOF\n")
+			_, err := facturx.Extract(pdf)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// FuzzExtract ensures that invalid PDF data provides an error instead of
+// causing a panic.
+func FuzzExtract(f *testing.F) {
+	f.Add(buildPDF(""))
+	f.Add(buildPDF(
+		" /Names << /EmbeddedFiles << /Names [(factur-x.xml) 4 0 R] >> >>",
+		"<< /Type /Filespec /F (factur-x.xml) /EF << /F 5 0 R >> >>",
+		"<< /Type /EmbeddedFile /Length 6 >>\nstream\n<xml/>\nendstream",
+	))
+	f.Add(buildPDF("", "<< /Type /ObjStm /N 1 /First -5 /Length 4 >>\nstream\n1 0 \nendstream"))
+	f.Fuzz(func(t *testing.T, pdf []byte) {
+		data, err := facturx.Extract(pdf)
+		if err != nil {
+			assert.Nil(t, data)
+		}
+	})
+}
@@ -0,0 +1,832 @@
+package facturx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// This file contains a minimal PDF reader and incremental writer, with just
+// enough support to locate the document catalog, find embedded files, and
+// append new objects without rewriting the original content.
+
+const (
+	// maxStreamSize is the largest decoded stream that will be accepted, to
+	// avoid exhausting memory with highly compressed data.
+	maxStreamSize = 32 << 20 // 32 MiB
+	// maxNesting is the deepest level of arrays and dictionaries that will be
+	// parsed, so that the work required to skip a value is bounded.
+	maxNesting = 64
+)
+
+var (
+	errPDFInvalid   = errors.New("invalid pdf")
+	errPDFTruncated = errors.New("unexpected end of data")
+	errPDFNesting   = errors.New("too deeply nested")
+
+	objHeaderRegexp = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	refRegexp       = regexp.MustCompile(`^(\d+)\s+(\d+)\s+R$`)
+	startXRefRegexp = regexp.MustCompile(`startxref\s+(\d+)\s*%%EOF\s*$`)
+)
+
+// pdfRef is an indirect object reference.
+type pdfRef struct {
+	num int
+	gen int
+}
+
+func (r pdfRef) String() string {
+	return fmt.Sprintf("%d %d R", r.num, r.gen)
+}
+
+// pdfEntry is a dictionary key and its value as it appears in the source.
+type pdfEntry struct {
+	key string
+	raw []byte
+}
+
+// pdfDict is an ordered dictionary whose values are kept in their raw
+// form, so that entries that are not modified are written back as is.
+type pdfDict []*pdfEntry
+
+func (d pdfDict) get(key string) []byte {
+	for _, e := range d {
+		if e.key == key {
+			return e.raw
+		}
+	}
+	return nil
+}
+
+func (d pdfDict) set(key string, raw []byte) pdfDict {
+	for _, e := range d {
+		if e.key == key {
+			e.raw = raw
+			return d
+		}
+	}
+	return append(d, &pdfEntry{key: key, raw: raw})
+}
+
+func (d pdfDict) without(keys ...string) pdfDict {
+	out := make(pdfDict, 0, len(d))
+	for _, e := range d {
+		skip := false
+		for _, k := range keys {
+			if e.key == k {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (d pdfDict) ref(key string) (pdfRef, bool) {
+	return parseRef(d.get(key))
+}
+
+func (d pdfDict) name(key string) string {
+	raw := bytes.TrimSpace(d.get(key))
+	if len(raw) > 0 && raw[0] == '/' {
+		return string(raw[1:])
+	}
+	return ""
+}
+
+func (d pdfDict) int(key string) (int, bool) {
+	n, err := strconv.Atoi(string(bytes.TrimSpace(d.get(key))))
+	return n, err == nil
+}
+
+func (d pdfDict) bytes() []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<<")
+	for _, e := range d {
+		buf.WriteString(" /")
+		buf.WriteString(e.key)
+		buf.WriteByte(' ')
+		buf.Write(e.raw)
+	}
+	buf.WriteString(" >>")
+	return buf.Bytes()
+}
+
+// pdfObject is an indirect object found in the file, with its dictionary
+// when the value is one, and the stream data when available.
+type pdfObject struct {
+	raw    []byte
+	dict   pdfDict
+	stream []byte
+}
+
+// pdfFile provides access to the objects of a parsed PDF.
+type pdfFile struct {
+	data      []byte
+	objects   map[int]*pdfObject
+	trailer   pdfDict
+	startXRef int
+	xrefRef   bool // true when the file uses cross-reference streams
+}
+
+func parsePDF(data []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing header", errPDFInvalid)
+	}
+	f := &pdfFile{
+		data:    data,
+		objects: make(map[int]*pdfObject),
+	}
+	if err := f.index(); err != nil {
+		return nil, err
+	}
+	if err := f.loadTrailer(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// index scans the file sequentially for objects, so that objects defined
+// later in incremental updates replace earlier versions, and expands any
+// object streams.
+func (f *pdfFile) index() error {
+	var objStreams []*pdfObject
+	lastEndStream := bytes.LastIndex(f.data, []byte("endstream"))
+	pos := 0
+	for {
+		loc := objHeaderRegexp.FindSubmatchIndex(f.data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(f.data[pos+loc[2] : pos+loc[3]]))
+		start := pos + loc[1]
+		end, err := skipValue(f.data, start)
+		if errors.Is(err, errPDFTruncated) {
+			// any later headers are inside the unterminated value, and
+			// would fail in the same way
+			break
+		}
+		if err != nil {
+			// not a real object header, keep looking
+			pos = start
+			continue
+		}
+		obj := &pdfObject{raw: bytes.TrimSpace(f.data[start:end])}
+		if d, err := parseDict(obj.raw); err == nil {
+			obj.dict = d
+		}
+		pos = end
+		if s, next, ok := readStream(f.data, end, lastEndStream, obj.dict); ok {
+			obj.stream = s
+			pos = next
+			if obj.dict.name("Type") == "ObjStm" {
+				objStreams = append(objStreams, obj)
+			}
+		}
+		f.objects[num] = obj
+	}
+	for _, os := range objStreams {
+		f.expandObjectStream(os)
+	}
+	if len(f.objects) == 0 {
+		return fmt.Errorf("%w: no objects found", errPDFInvalid)
+	}
+	return nil
+}
+
+func (f *pdfFile) expandObjectStream(os *pdfObject) {
+	data, err := decodeStream(os.dict, os.stream)
+	if err != nil {
+		return
+	}
+	n, _ := os.dict.int("N")
+	first, _ := os.dict.int("First")
+	if n < 0 || first < 0 || first > len(data) {
+		return
+	}
+	header := bytes.Fields(data[:first])
+	if 2*n < len(header) {
+		header = header[:2*n]
+	}
+	// offsets must be in increasing order, and each object is only parsed
+	// up to the start of the next, so the stream is scanned once.
+	prev := -1
+	for i := 0; 2*i+1 < len(header); i++ {
+		num, err1 := strconv.Atoi(string(header[2*i]))
+		off, err2 := strconv.Atoi(string(header[2*i+1]))
+		if err1 != nil || err2 != nil || off <= prev || first+off > len(data) {
+			return
+		}
+		prev = off
+		limit := len(data)
+		if 2*i+3 < len(header) {
+			if next, err := strconv.Atoi(string(header[2*i+3])); err == nil && next > off && first+next <= len(data) {
+				limit = first + next
+			}
+		}
+		if _, exists := f.objects[num]; exists {
+			// objects defined directly take priority
+			continue
+		}
+		end, err := skipValue(data[:limit], first+off)
+		if err != nil {
+			continue
+		}
+		obj := &pdfObject{raw: bytes.TrimSpace(data[first+off : end])}
+		if d, err := parseDict(obj.raw); err == nil {
+			obj.dict = d
+		}
+		f.objects[num] = obj
+	}
+}
+
+func (f *pdfFile) loadTrailer() error {
+	m := startXRefRegexp.FindSubmatch(f.data)
+	if m == nil {
+		return fmt.Errorf("%w: missing startxref", errPDFInvalid)
+	}
+	f.startXRef, _ = strconv.Atoi(string(m[1]))
+	if f.startXRef >= len(f.data) {
+		return fmt.Errorf("%w: startxref out of range", errPDFInvalid)
+	}
+	rest := f.data[f.startXRef:]
+	if bytes.HasPrefix(rest, []byte("xref")) {
+		i := bytes.Index(rest, []byte("trailer"))
+		if i < 0 {
+			return fmt.Errorf("%w: missing trailer", errPDFInvalid)
+		}
+		start := f.startXRef + i + len("trailer")
+		end, err := skipValue(f.data, start)
+		if err != nil {
+			return fmt.Errorf("%w: trailer: %s", errPDFInvalid, err)
+		}
+		f.trailer, err = parseDict(f.data[start:end])
+		if err != nil {
+			return fmt.Errorf("%w: trailer: %s", errPDFInvalid, err)
+		}
+		return nil
+	}
+	// cross-reference stream, whose dictionary doubles as the trailer
+	loc := objHeaderRegexp.FindIndex(rest)
+	if loc == nil || loc[0] != 0 {
+		return fmt.Errorf("%w: invalid cross-reference", errPDFInvalid)
+	}
+	start := f.startXRef + loc[1]
+	end, err := skipValue(f.data, start)
+	if err != nil {
+		return fmt.Errorf("%w: cross-reference: %s", errPDFInvalid, err)
+	}
+	f.trailer, err = parseDict(f.data[start:end])
+	if err != nil {
+		return fmt.Errorf("%w: cross-reference: %s", errPDFInvalid, err)
+	}
+	f.xrefRef = true
+	return nil
+}
+
+// catalog provides the reference and dictionary of the document catalog.
+func (f *pdfFile) catalog() (pdfRef, pdfDict, error) {
+	ref, ok := f.trailer.ref("Root")
+	if !ok {
+		return ref, nil, fmt.Errorf("%w: missing root", errPDFInvalid)
+	}
+	d := f.dict(ref)
+	if d == nil {
+		return ref, nil, fmt.Errorf("%w: missing catalog", errPDFInvalid)
+	}
+	return ref, d, nil
+}
+
+// dict provides the dictionary of the referenced object.
+func (f *pdfFile) dict(ref pdfRef) pdfDict {
+	if obj, ok := f.objects[ref.num]; ok {
+		return obj.dict
+	}
+	return nil
+}
+
+// resolveDict provides the dictionary for the raw value, following the
+// reference if needed.
+func (f *pdfFile) resolveDict(raw []byte) pdfDict {
+	if ref, ok := parseRef(raw); ok {
+		return f.dict(ref)
+	}
+	d, err := parseDict(raw)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// size provides the number to use for the next new object.
+func (f *pdfFile) size() int {
+	size, _ := f.trailer.int("Size")
+	for num := range f.objects {
+		if num >= size {
+			size = num + 1
+		}
+	}
+	return size
+}
+
+// readStream checks if the value ending at pos is followed by stream data,
+// and returns the raw data and position after the `endstream` keyword. The
+// position of the last `endstream` keyword in the data avoids searching for
+// keywords that are not present.
+func readStream(data []byte, pos, lastEndStream int, dict pdfDict) ([]byte, int, bool) {
+	i := skipSpace(data, pos)
+	if !bytes.HasPrefix(data[i:], []byte("stream")) {
+		return nil, pos, false
+	}
+	i += len("stream")
+	if i < len(data) && data[i] == '\r' {
+		i++
+	}
+	if i < len(data) && data[i] == '\n' {
+		i++
+	}
+	if n, ok := dict.int("Length"); ok && n >= 0 && i+n <= len(data) {
+		end := skipSpace(data, i+n)
+		if bytes.HasPrefix(data[end:], []byte("endstream")) {
+			return data[i : i+n], end + len("endstream"), true
+		}
+	}
+	// indirect or incorrect length, so look for the keyword
+	if i > lastEndStream {
+		return nil, pos, false
+	}
+	j := bytes.Index(data[i:], []byte("endstream"))
+	if j < 0 {
+		return nil, pos, false
+	}
+	s := bytes.TrimRight(data[i:i+j], "\r\n")
+	return s, i + j + len("endstream"), true
+}
+
+// decodeStream applies the stream's filter, of which only FlateDecode
+// without predictors is supported. Decoded data larger than maxStreamSize
+// will be rejected.
+func decodeStream(dict pdfDict, data []byte) ([]byte, error) {
+	filter := bytes.TrimSpace(dict.get("Filter"))
+	switch string(filter) {
+	case "":
+		return data, nil
+	case "/FlateDecode", "[/FlateDecode]", "[ /FlateDecode ]":
+		if dict.get("DecodeParms") != nil {
+			return nil, errors.New("unsupported decode parameters")
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close() //nolint:errcheck
+		out, err := io.ReadAll(io.LimitReader(r, maxStreamSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > maxStreamSize {
+			return nil, errors.New("stream too large")
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported filter %s", filter)
+}
+
+func parseRef(raw []byte) (pdfRef, bool) {
+	m := refRegexp.FindSubmatch(bytes.TrimSpace(raw))
+	if m == nil {
+		return pdfRef{}, false
+	}
+	num, _ := strconv.Atoi(string(m[1]))
+	gen, _ := strconv.Atoi(string(m[2]))
+	return pdfRef{num: num, gen: gen}, true
+}
+
+// parseDict parses the top level of a dictionary, keeping values raw.
+func parseDict(raw []byte) (pdfDict, error) {
+	raw = bytes.TrimSpace(raw)
+	if !bytes.HasPrefix(raw, []byte("<<")) || !bytes.HasSuffix(raw, []byte(">>")) {
+		return nil, errors.New("not a dictionary")
+	}
+	body := raw[2 : len(raw)-2]
+	var d pdfDict
+	pos := 0
+	for {
+		pos = skipSpace(body, pos)
+		if pos >= len(body) {
+			break
+		}
+		if body[pos] != '/' {
+			return nil, fmt.Errorf("expected name at %d", pos)
+		}
+		end := skipName(body, pos+1)
+		key := string(body[pos+1 : end])
+		start := skipSpace(body, end)
+		vend, err := skipValue(body, start)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, &pdfEntry{key: key, raw: bytes.TrimSpace(body[start:vend])})
+		pos = vend
+	}
+	return d, nil
+}
+
+// parseArray splits the top level elements of an array, keeping them raw.
+// References are kept together as a single element.
+func parseArray(raw []byte) ([][]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 2 || raw[0] != '[' || raw[len(raw)-1] != ']' {
+		return nil, errors.New("not an array")
+	}
+	body := raw[1 : len(raw)-1]
+	var list [][]byte
+	pos := 0
+	for {
+		pos = skipSpace(body, pos)
+		if pos >= len(body) {
+			break
+		}
+		end, err := skipValue(body, pos)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, bytes.TrimSpace(body[pos:end]))
+		pos = end
+	}
+	return list, nil
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func skipSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		c := data[pos]
+		if c == '%' {
+			for pos < len(data) && data[pos] != '\n' && data[pos] != '\r' {
+				pos++
+			}
+			continue
+		}
+		if !isSpace(c) {
+			break
+		}
+		pos++
+	}
+	return pos
+}
+
+func skipName(data []byte, pos int) int {
+	for pos < len(data) && !isSpace(data[pos]) && !isDelimiter(data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// skipValue returns the position just after the value starting at pos.
+// Errors caused by reaching the end of the data will wrap errPDFTruncated.
+func skipValue(data []byte, pos int) (int, error) {
+	return skipNestedValue(data, pos, 0)
+}
+
+func skipNestedValue(data []byte, pos, depth int) (int, error) {
+	pos = skipSpace(data, pos)
+	if pos >= len(data) {
+		return pos, errPDFTruncated
+	}
+	switch c := data[pos]; {
+	case c == '<' && pos+1 < len(data) && data[pos+1] == '<':
+		if depth >= maxNesting {
+			return pos, errPDFNesting
+		}
+		pos += 2
+		for {
+			pos = skipSpace(data, pos)
+			if pos+1 >= len(data) {
+				return pos, fmt.Errorf("%w: unterminated dictionary", errPDFTruncated)
+			}
+			if data[pos] == '>' && data[pos+1] == '>' {
+				return pos + 2, nil
+			}
+			var err error
+			if pos, err = skipNestedValue(data, pos, depth+1); err != nil {
+				return pos, err
+			}
+		}
+	case c == '<':
+		end := bytes.IndexByte(data[pos:], '>')
+		if end < 0 {
+			return pos, fmt.Errorf("%w: unterminated hex string", errPDFTruncated)
+		}
+		return pos + end + 1, nil
+	case c == '[':
+		if depth >= maxNesting {
+			return pos, errPDFNesting
+		}
+		pos++
+		for {
+			pos = skipSpace(data, pos)
+			if pos >= len(data) {
+				return pos, fmt.Errorf("%w: unterminated array", errPDFTruncated)
+			}
+			if data[pos] == ']' {
+				return pos + 1, nil
+			}
+			var err error
+			if pos, err = skipNestedValue(data, pos, depth+1); err != nil {
+				return pos, err
+			}
+		}
+	case c == '(':
+		depth := 0
+		for ; pos < len(data); pos++ {
+			switch data[pos] {
+			case '\\':
+				pos++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return pos + 1, nil
+				}
+			}
+		}
+		return pos, fmt.Errorf("%w: unterminated string", errPDFTruncated)
+	case c == '/':
+		return skipName(data, pos+1), nil
+	case isDelimiter(c):
+		return pos, fmt.Errorf("unexpected '%c'", c)
+	}
+	end := skipName(data, pos)
+	if end == pos {
+		return pos, errors.New("unexpected character")
+	}
+	// numbers followed by a generation and R form a reference
+	if n := refTail(data[end:]); n > 0 && isInteger(data[pos:end]) {
+		return end + n, nil
+	}
+	return end, nil
+}
+
+// refTail provides the length of the generation number and `R` keyword that
+// follow the object number of a reference, or zero if they are not present.
+// This avoids using a regular expression that could scan the rest of the
+// data for every number.
+func refTail(data []byte) int {
+	i := skipRefSpace(data, 0)
+	if i == 0 {
+		return 0
+	}
+	j := i
+	for j < len(data) && data[j] >= '0' && data[j] <= '9' {
+		j++
+	}
+	if j == i {
+		return 0
+	}
+	k := skipRefSpace(data, j)
+	if k == j || k >= len(data) || data[k] != 'R' {
+		return 0
+	}
+	k++
+	if k < len(data) && isWordChar(data[k]) {
+		return 0
+	}
+	return k
+}
+
+func skipRefSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch data[pos] {
+		case ' ', '\t', '\r', '\n', '\f':
+			pos++
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isInteger(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeString decodes a literal or hex string, including UTF-16 strings
+// with a byte order mark.
+func decodeString(raw []byte) string {
+	raw = bytes.TrimSpace(raw)
+	var out []byte
+	switch {
+	case len(raw) >= 2 && raw[0] == '(':
+		body := raw[1 : len(raw)-1]
+		for i := 0; i < len(body); i++ {
+			c := body[i]
+			if c != '\\' || i+1 >= len(body) {
+				out = append(out, c)
+				continue
+			}
+			i++
+			switch body[i] {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// line continuation
+			default:
+				if body[i] >= '0' && body[i] <= '7' {
+					n := 0
+					j := 0
+					for ; j < 3 && i+j < len(body) && body[i+j] >= '0' && body[i+j] <= '7'; j++ {
+						n = n*8 + int(body[i+j]-'0')
+					}
+					out = append(out, byte(n))
+					i += j - 1
+					continue
+				}
+				out = append(out, body[i])
+			}
+		}
+	case len(raw) >= 2 && raw[0] == '<':
+		hex := bytes.Map(func(r rune) rune {
+			if isSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, raw[1:len(raw)-1])
+		if len(hex)%2 == 1 {
+			hex = append(hex, '0')
+		}
+		for i := 0; i+1 < len(hex); i += 2 {
+			n, err := strconv.ParseUint(string(hex[i:i+2]), 16, 8)
+			if err != nil {
+				return ""
+			}
+			out = append(out, byte(n))
+		}
+	default:
+		return ""
+	}
+	if len(out) >= 2 && out[0] == 0xFE && out[1] == 0xFF {
+		u := make([]uint16, 0, len(out)/2)
+		for i := 2; i+1 < len(out); i += 2 {
+			u = append(u, uint16(out[i])<<8|uint16(out[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+	return string(out)
+}
+
+// encodeString provides a literal string with the special characters
+// escaped.
+func encodeString(s string) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+	return buf.Bytes()
+}
+
+// pdfWriter appends an incremental update to the original file.
+type pdfWriter struct {
+	f       *pdfFile
+	buf     *bytes.Buffer
+	next    int
+	offsets map[int]int
+	gens    map[int]int
+}
+
+func newPDFWriter(f *pdfFile) *pdfWriter {
+	w := &pdfWriter{
+		f:       f,
+		buf:     bytes.NewBuffer(append([]byte{}, f.data...)),
+		next:    f.size(),
+		offsets: make(map[int]int),
+		gens:    make(map[int]int),
+	}
+	if last := f.data[len(f.data)-1]; last != '\n' && last != '\r' {
+		w.buf.WriteByte('\n')
+	}
+	return w
+}
+
+// reserve provides a reference for a new object.
+func (w *pdfWriter) reserve() pdfRef {
+	ref := pdfRef{num: w.next}
+	w.next++
+	return ref
+}
+
+// write adds the object, replacing any previous version with the same
+// number.
+func (w *pdfWriter) write(ref pdfRef, value []byte, stream []byte) {
+	w.offsets[ref.num] = w.buf.Len()
+	w.gens[ref.num] = ref.gen
+	fmt.Fprintf(w.buf, "%d %d obj\n", ref.num, ref.gen)
+	w.buf.Write(value)
+	if stream != nil {
+		w.buf.WriteString("\nstream\n")
+		w.buf.Write(stream)
+		w.buf.WriteString("\nendstream")
+	}
+	w.buf.WriteString("\nendobj\n")
+}
+
+// finish writes the cross-reference section and trailer using the same
+// style as the original file.
+func (w *pdfWriter) finish(id []byte) []byte {
+	trailer := w.f.trailer.without("Size", "Prev", "XRefStm", "ID", "Type", "W", "Index", "Filter", "DecodeParms", "Length")
+	trailer = trailer.set("Prev", []byte(strconv.Itoa(w.f.startXRef)))
+	trailer = trailer.set("ID", id)
+	nums := make([]int, 0, len(w.offsets)+1)
+	for n := range w.offsets {
+		nums = append(nums, n)
+	}
+
+	if w.f.xrefRef {
+		xref := w.reserve()
+		nums = append(nums, xref.num)
+		slices.Sort(nums)
+		start := w.buf.Len()
+		w.offsets[xref.num] = start
+		data := new(bytes.Buffer)
+		index := new(bytes.Buffer)
+		for _, n := range nums {
+			off := w.offsets[n]
+			data.Write([]byte{1, byte(off >> 24), byte(off >> 16), byte(off >> 8), byte(off), 0, byte(w.gens[n])})
+			fmt.Fprintf(index, "%d 1 ", n)
+		}
+		trailer = trailer.set("Size", []byte(strconv.Itoa(w.next)))
+		trailer = append(pdfDict{
+			{key: "Type", raw: []byte("/XRef")},
+			{key: "W", raw: []byte("[1 4 2]")},
+			{key: "Index", raw: []byte("[" + string(bytes.TrimSpace(index.Bytes())) + "]")},
+			{key: "Length", raw: []byte(strconv.Itoa(data.Len()))},
+		}, trailer...)
+		w.write(xref, trailer.bytes(), data.Bytes())
+		fmt.Fprintf(w.buf, "startxref\n%d\n%%%%EOF\n", start)
+		return w.buf.Bytes()
+	}
+
+	slices.Sort(nums)
+	start := w.buf.Len()
+	w.buf.WriteString("xref\n")
+	for _, n := range nums {
+		fmt.Fprintf(w.buf, "%d 1\n%010d %05d n\r\n", n, w.offsets[n], w.gens[n])
+	}
+	trailer = trailer.set("Size", []byte(strconv.Itoa(w.next)))
+	w.buf.WriteString("trailer\n")
+	w.buf.Write(trailer.bytes())
+	fmt.Fprintf(w.buf, "\nstartxref\n%d\n%%%%EOF\n", start)
+	return w.buf.Bytes()
+}
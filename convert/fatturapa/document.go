@@ -0,0 +1,194 @@
+package fatturapa
+
+import "encoding/xml"
+
+// Document is the root FatturaElettronica element. Only the root element is
+// namespace qualified.
+type Document struct {
+	XMLName  xml.Name `xml:"p:FatturaElettronica"`
+	PNS      string   `xml:"xmlns:p,attr,omitempty"`
+	DSNS     string   `xml:"xmlns:ds,attr,omitempty"`
+	XSINS    string   `xml:"xmlns:xsi,attr,omitempty"`
+	Versione string   `xml:"versione,attr"`
+
+	Header *Header `xml:"FatturaElettronicaHeader"`
+	Bodies []*Body `xml:"FatturaElettronicaBody"`
+}
+
+// Header contains the transmission details and parties.
+type Header struct {
+	DatiTrasmissione       *DatiTrasmissione `xml:"DatiTrasmissione"`
+	CedentePrestatore      *Party            `xml:"CedentePrestatore"`
+	CessionarioCommittente *Party            `xml:"CessionarioCommittente"`
+}
+
+// DatiTrasmissione identifies the transmitter and the recipient's inbox.
+type DatiTrasmissione struct {
+	IDTrasmittente      *IDFiscale `xml:"IdTrasmittente"`
+	ProgressivoInvio    string     `xml:"ProgressivoInvio"`
+	FormatoTrasmissione string     `xml:"FormatoTrasmissione"`
+	CodiceDestinatario  string     `xml:"CodiceDestinatario"`
+	PECDestinatario     string     `xml:"PECDestinatario,omitempty"`
+}
+
+// IDFiscale is a tax identity with its country prefix.
+type IDFiscale struct {
+	IDPaese  string `xml:"IdPaese"`
+	IDCodice string `xml:"IdCodice"`
+}
+
+// Party describes both the supplier and customer.
+type Party struct {
+	DatiAnagrafici *DatiAnagrafici `xml:"DatiAnagrafici"`
+	Sede           *Sede           `xml:"Sede"`
+	Contatti       *Contatti       `xml:"Contatti,omitempty"`
+}
+
+// DatiAnagrafici contains the party's identification.
+type DatiAnagrafici struct {
+	IDFiscaleIVA  *IDFiscale  `xml:"IdFiscaleIVA,omitempty"`
+	CodiceFiscale string      `xml:"CodiceFiscale,omitempty"`
+	Anagrafica    *Anagrafica `xml:"Anagrafica"`
+	RegimeFiscale string      `xml:"RegimeFiscale,omitempty"`
+}
+
+// Anagrafica contains either the company name or a person's names.
+type Anagrafica struct {
+	Denominazione string `xml:"Denominazione,omitempty"`
+	Nome          string `xml:"Nome,omitempty"`
+	Cognome       string `xml:"Cognome,omitempty"`
+}
+
+// Sede is the address of a party.
+type Sede struct {
+	Indirizzo    string `xml:"Indirizzo"`
+	NumeroCivico string `xml:"NumeroCivico,omitempty"`
+	CAP          string `xml:"CAP"`
+	Comune       string `xml:"Comune"`
+	Provincia    string `xml:"Provincia,omitempty"`
+	Nazione      string `xml:"Nazione"`
+}
+
+// Contatti contains the supplier's contact details.
+type Contatti struct {
+	Telefono string `xml:"Telefono,omitempty"`
+	Email    string `xml:"Email,omitempty"`
+}
+
+// Body contains the details of a single invoice.
+type Body struct {
+	DatiGenerali    *DatiGenerali    `xml:"DatiGenerali"`
+	DatiBeniServizi *DatiBeniServizi `xml:"DatiBeniServizi"`
+	DatiPagamento   []*DatiPagamento `xml:"DatiPagamento,omitempty"`
+}
+
+// DatiGenerali contains the general document data and references.
+type DatiGenerali struct {
+	DatiGeneraliDocumento *DatiGeneraliDocumento `xml:"DatiGeneraliDocumento"`
+	DatiOrdineAcquisto    []*DatiDocumento       `xml:"DatiOrdineAcquisto,omitempty"`
+	DatiContratto         []*DatiDocumento       `xml:"DatiContratto,omitempty"`
+	DatiFattureCollegate  []*DatiDocumento       `xml:"DatiFattureCollegate,omitempty"`
+}
+
+// DatiGeneraliDocumento contains the main document details.
+type DatiGeneraliDocumento struct {
+	TipoDocumento          string                    `xml:"TipoDocumento"`
+	Divisa                 string                    `xml:"Divisa"`
+	Data                   string                    `xml:"Data"`
+	Numero                 string                    `xml:"Numero"`
+	DatiRitenuta           []*DatiRitenuta           `xml:"DatiRitenuta,omitempty"`
+	DatiCassaPrevidenziale []*DatiCassaPrevidenziale `xml:"DatiCassaPrevidenziale,omitempty"`
+	ScontoMaggiorazione    []*ScontoMaggiorazione    `xml:"ScontoMaggiorazione,omitempty"`
+	ImportoTotaleDocumento string                    `xml:"ImportoTotaleDocumento,omitempty"`
+	Arrotondamento         string                    `xml:"Arrotondamento,omitempty"`
+	Causale                []string                  `xml:"Causale,omitempty"`
+}
+
+// DatiRitenuta describes a retained tax total.
+type DatiRitenuta struct {
+	TipoRitenuta     string `xml:"TipoRitenuta"`
+	ImportoRitenuta  string `xml:"ImportoRitenuta"`
+	AliquotaRitenuta string `xml:"AliquotaRitenuta"`
+	CausalePagamento string `xml:"CausalePagamento"`
+}
+
+// DatiCassaPrevidenziale describes a social security fund contribution.
+type DatiCassaPrevidenziale struct {
+	TipoCassa              string `xml:"TipoCassa"`
+	AlCassa                string `xml:"AlCassa"`
+	ImportoContributoCassa string `xml:"ImportoContributoCassa"`
+	ImponibileCassa        string `xml:"ImponibileCassa,omitempty"`
+	AliquotaIVA            string `xml:"AliquotaIVA"`
+	Ritenuta               string `xml:"Ritenuta,omitempty"`
+	Natura                 string `xml:"Natura,omitempty"`
+}
+
+// ScontoMaggiorazione is a discount (SC) or charge (MG).
+type ScontoMaggiorazione struct {
+	Tipo        string `xml:"Tipo"`
+	Percentuale string `xml:"Percentuale,omitempty"`
+	Importo     string `xml:"Importo,omitempty"`
+}
+
+// DatiDocumento references another document.
+type DatiDocumento struct {
+	IDDocumento string `xml:"IdDocumento"`
+	Data        string `xml:"Data,omitempty"`
+	CodiceCUP   string `xml:"CodiceCUP,omitempty"`
+	CodiceCIG   string `xml:"CodiceCIG,omitempty"`
+}
+
+// DatiBeniServizi contains the lines and VAT summary.
+type DatiBeniServizi struct {
+	DettaglioLinee []*DettaglioLinea `xml:"DettaglioLinee"`
+	DatiRiepilogo  []*DatiRiepilogo  `xml:"DatiRiepilogo"`
+}
+
+// DettaglioLinea is a single invoice line.
+type DettaglioLinea struct {
+	NumeroLinea         int                    `xml:"NumeroLinea"`
+	CodiceArticolo      []*CodiceArticolo      `xml:"CodiceArticolo,omitempty"`
+	Descrizione         string                 `xml:"Descrizione"`
+	Quantita            string                 `xml:"Quantita,omitempty"`
+	UnitaMisura         string                 `xml:"UnitaMisura,omitempty"`
+	PrezzoUnitario      string                 `xml:"PrezzoUnitario"`
+	ScontoMaggiorazione []*ScontoMaggiorazione `xml:"ScontoMaggiorazione,omitempty"`
+	PrezzoTotale        string                 `xml:"PrezzoTotale"`
+	AliquotaIVA         string                 `xml:"AliquotaIVA"`
+	Ritenuta            string                 `xml:"Ritenuta,omitempty"`
+	Natura              string                 `xml:"Natura,omitempty"`
+}
+
+// CodiceArticolo is an item code with its type.
+type CodiceArticolo struct {
+	CodiceTipo   string `xml:"CodiceTipo"`
+	CodiceValore string `xml:"CodiceValore"`
+}
+
+// DatiRiepilogo summarizes the lines with the same VAT rate or nature.
+type DatiRiepilogo struct {
+	AliquotaIVA          string `xml:"AliquotaIVA"`
+	Natura               string `xml:"Natura,omitempty"`
+	ImponibileImporto    string `xml:"ImponibileImporto"`
+	Imposta              string `xml:"Imposta"`
+	EsigibilitaIVA       string `xml:"EsigibilitaIVA,omitempty"`
+	RiferimentoNormativo string `xml:"RiferimentoNormativo,omitempty"`
+}
+
+// DatiPagamento contains the payment conditions and instalments.
+type DatiPagamento struct {
+	CondizioniPagamento string                `xml:"CondizioniPagamento"`
+	DettaglioPagamento  []*DettaglioPagamento `xml:"DettaglioPagamento"`
+}
+
+// DettaglioPagamento is a single payment.
+type DettaglioPagamento struct {
+	Beneficiario          string `xml:"Beneficiario,omitempty"`
+	ModalitaPagamento     string `xml:"ModalitaPagamento"`
+	DataScadenzaPagamento string `xml:"DataScadenzaPagamento,omitempty"`
+	ImportoPagamento      string `xml:"ImportoPagamento"`
+	IstitutoFinanziario   string `xml:"IstitutoFinanziario,omitempty"`
+	IBAN                  string `xml:"IBAN,omitempty"`
+	BIC                   string `xml:"BIC,omitempty"`
+	CodicePagamento       string `xml:"CodicePagamento,omitempty"`
+}
@@ -0,0 +1,526 @@
+package fatturapa
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/invopop/gobl/addons/it/sdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/regimes/it"
+	"github.com/invopop/gobl/tax"
+)
+
+// Codes with special meaning in FatturaPA documents.
+const (
+	destinationNone    = "0000000"
+	destinationForeign = "XXXXXXX"
+	codeForeign        = "99999999999"
+	postCodeForeign    = "00000"
+	typeDiscount       = "SC"
+	typeCharge         = "MG"
+	flagYes            = "SI"
+	paymentInstalments = "TP01"
+	paymentFull        = "TP02"
+	causaleMaxLength   = 200
+	progressiveLength  = 10
+)
+
+// retainedTypes maps the retained tax categories to their TipoRitenuta.
+var retainedTypes = map[cbc.Code]string{
+	it.TaxCategoryIRPEF:    "RT01",
+	it.TaxCategoryIRES:     "RT02",
+	it.TaxCategoryINPS:     "RT03",
+	it.TaxCategoryENASARCO: "RT04",
+	it.TaxCategoryENPAM:    "RT05",
+	it.TaxCategoryCP:       "RT06",
+}
+
+// FromInvoice converts the calculated GOBL invoice into a FatturaPA
+// document.
+func FromInvoice(inv *bill.Invoice, opts ...Option) (*Document, error) {
+	if inv == nil || inv.Totals == nil {
+		return nil, convert.ErrNotCalculated
+	}
+	if inv.Tax == nil || inv.Tax.Ext.Get(sdi.ExtKeyDocumentType) == cbc.CodeEmpty {
+		return nil, fmt.Errorf("%w: missing %s addon extensions", convert.ErrUnsupported, sdi.V1)
+	}
+	if inv.Tax.PricesInclude != cbc.CodeEmpty {
+		return nil, fmt.Errorf("%w: prices including tax, remove them first", convert.ErrUnsupported)
+	}
+	if inv.Supplier == nil || inv.Supplier.TaxID == nil {
+		return nil, fmt.Errorf("%w: supplier tax ID required", convert.ErrUnsupported)
+	}
+	o := &options{
+		transmitterCountry: inv.Supplier.TaxID.Country,
+		transmitterCode:    inv.Supplier.TaxID.Code.String(),
+		progressive:        progressiveCode(inv.Code),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	format := inv.Tax.Ext.Get(sdi.ExtKeyFormat).String()
+	doc := newDocument(format)
+	doc.Header = &Header{
+		DatiTrasmissione: &DatiTrasmissione{
+			IDTrasmittente: &IDFiscale{
+				IDPaese:  o.transmitterCountry.String(),
+				IDCodice: o.transmitterCode,
+			},
+			ProgressivoInvio:    o.progressive,
+			FormatoTrasmissione: format,
+		},
+		CedentePrestatore:      newSupplier(inv.Supplier),
+		CessionarioCommittente: newCustomer(inv.Customer),
+	}
+	setDestination(doc.Header.DatiTrasmissione, inv.Customer)
+	doc.Bodies = []*Body{newBody(inv)}
+	return doc, nil
+}
+
+func setDestination(dt *DatiTrasmissione, customer *org.Party) {
+	dt.CodiceDestinatario = destinationNone
+	if customer == nil {
+		return
+	}
+	if customer.TaxID != nil && customer.TaxID.Country != "IT" {
+		dt.CodiceDestinatario = destinationForeign
+		return
+	}
+	for _, ib := range customer.Inboxes {
+		switch ib.Key {
+		case sdi.KeyInboxCode:
+			dt.CodiceDestinatario = ib.Code.String()
+		case sdi.KeyInboxPEC:
+			dt.PECDestinatario = ib.Email
+		}
+	}
+}
+
+func newSupplier(p *org.Party) *Party {
+	party := &Party{
+		DatiAnagrafici: &DatiAnagrafici{
+			IDFiscaleIVA: &IDFiscale{
+				IDPaese:  p.TaxID.Country.String(),
+				IDCodice: p.TaxID.Code.String(),
+			},
+			CodiceFiscale: fiscalCode(p),
+			Anagrafica:    newAnagrafica(p),
+			RegimeFiscale: p.Ext.Get(sdi.ExtKeyFiscalRegime).String(),
+		},
+		Sede: newSede(p),
+	}
+	c := new(Contatti)
+	if len(p.Telephones) > 0 {
+		c.Telefono = p.Telephones[0].Number
+	}
+	if len(p.Emails) > 0 {
+		c.Email = p.Emails[0].Address
+	}
+	if c.Telefono != "" || c.Email != "" {
+		party.Contatti = c
+	}
+	return party
+}
+
+func newCustomer(p *org.Party) *Party {
+	if p == nil {
+		return nil
+	}
+	da := &DatiAnagrafici{
+		CodiceFiscale: fiscalCode(p),
+		Anagrafica:    newAnagrafica(p),
+	}
+	if id := p.TaxID; id != nil {
+		switch {
+		case id.Code != cbc.CodeEmpty:
+			da.IDFiscaleIVA = &IDFiscale{IDPaese: id.Country.String(), IDCodice: id.Code.String()}
+		case id.Country != "IT":
+			// foreign customers without a tax code
+			da.IDFiscaleIVA = &IDFiscale{IDPaese: id.Country.String(), IDCodice: codeForeign}
+		}
+	}
+	return &Party{
+		DatiAnagrafici: da,
+		Sede:           newSede(p),
+	}
+}
+
+func fiscalCode(p *org.Party) string {
+	if id := org.IdentityForKey(p.Identities, it.IdentityKeyFiscalCode); id != nil {
+		return id.Code.String()
+	}
+	return ""
+}
+
+func newAnagrafica(p *org.Party) *Anagrafica {
+	if p.Name == "" && len(p.People) > 0 && p.People[0].Name != nil {
+		n := p.People[0].Name
+		return &Anagrafica{Nome: n.Given, Cognome: n.Surname}
+	}
+	return &Anagrafica{Denominazione: p.Name}
+}
+
+func newSede(p *org.Party) *Sede {
+	if len(p.Addresses) == 0 {
+		return nil
+	}
+	a := p.Addresses[0]
+	s := &Sede{
+		Indirizzo:    strings.TrimSpace(a.Street + " " + a.StreetExtra),
+		NumeroCivico: a.Number,
+		CAP:          a.Code.String(),
+		Comune:       a.Locality,
+		Nazione:      a.Country.String(),
+	}
+	if a.Country == "IT" {
+		s.Provincia = a.Region
+	} else if s.CAP == "" {
+		s.CAP = postCodeForeign
+	}
+	return s
+}
+
+func newBody(inv *bill.Invoice) *Body {
+	t := inv.Totals
+	dgd := &DatiGeneraliDocumento{
+		TipoDocumento:          inv.Tax.Ext.Get(sdi.ExtKeyDocumentType).String(),
+		Divisa:                 inv.Currency.String(),
+		Data:                   inv.IssueDate.String(),
+		Numero:                 inv.Series.Join(inv.Code).String(),
+		DatiRitenuta:           newRetainedTaxes(t.Taxes),
+		ImportoTotaleDocumento: formatAmount(t.TotalWithTax),
+	}
+	if t.Rounding != nil {
+		dgd.Arrotondamento = formatAmount(*t.Rounding)
+	}
+	for _, n := range inv.Notes {
+		dgd.Causale = append(dgd.Causale, splitText(n.Text, causaleMaxLength)...)
+	}
+	for _, c := range inv.Charges {
+		if c.Key.Has(sdi.KeyFundContribution) {
+			dgd.DatiCassaPrevidenziale = append(dgd.DatiCassaPrevidenziale, newFundContribution(c))
+		}
+	}
+
+	dg := &DatiGenerali{DatiGeneraliDocumento: dgd}
+	if o := inv.Ordering; o != nil {
+		for _, d := range o.Purchases {
+			dg.DatiOrdineAcquisto = append(dg.DatiOrdineAcquisto, newDatiDocumento(d))
+		}
+		for _, d := range o.Contracts {
+			dg.DatiContratto = append(dg.DatiContratto, newDatiDocumento(d))
+		}
+	}
+	for _, d := range inv.Preceding {
+		dg.DatiFattureCollegate = append(dg.DatiFattureCollegate, newDatiDocumento(d))
+	}
+
+	return &Body{
+		DatiGenerali:    dg,
+		DatiBeniServizi: newGoodsServices(inv),
+		DatiPagamento:   newPayment(inv),
+	}
+}
+
+func newRetainedTaxes(tt *tax.Total) []*DatiRitenuta {
+	if tt == nil {
+		return nil
+	}
+	var list []*DatiRitenuta
+	for _, ct := range tt.Categories {
+		code, ok := retainedTypes[ct.Code]
+		if !ok || !ct.Retained {
+			continue
+		}
+		for _, rt := range ct.Rates {
+			list = append(list, &DatiRitenuta{
+				TipoRitenuta:     code,
+				ImportoRitenuta:  formatAmount(rt.Amount),
+				AliquotaRitenuta: formatPercent(rt.Percent),
+				CausalePagamento: rt.Ext.Get(sdi.ExtKeyRetained).String(),
+			})
+		}
+	}
+	return list
+}
+
+func newFundContribution(c *bill.Charge) *DatiCassaPrevidenziale {
+	d := &DatiCassaPrevidenziale{
+		TipoCassa:              c.Ext.Get(sdi.ExtKeyFundType).String(),
+		AlCassa:                formatPercent(c.Percent),
+		ImportoContributoCassa: formatAmount(c.Amount),
+	}
+	if c.Base != nil {
+		d.ImponibileCassa = formatAmount(*c.Base)
+	}
+	d.AliquotaIVA, d.Natura = vatDetails(c.Taxes)
+	if hasRetained(c.Taxes) {
+		d.Ritenuta = flagYes
+	}
+	return d
+}
+
+func newDatiDocumento(d *org.DocumentRef) *DatiDocumento {
+	dd := &DatiDocumento{IDDocumento: d.Series.Join(d.Code).String()}
+	if d.IssueDate != nil {
+		dd.Data = d.IssueDate.String()
+	}
+	for _, id := range d.Identities {
+		switch id.Type {
+		case sdi.IdentityTypeCUP:
+			dd.CodiceCUP = id.Code.String()
+		case sdi.IdentityTypeCIG:
+			dd.CodiceCIG = id.Code.String()
+		}
+	}
+	return dd
+}
+
+func newGoodsServices(inv *bill.Invoice) *DatiBeniServizi {
+	dbs := new(DatiBeniServizi)
+	for _, l := range inv.Lines {
+		dbs.DettaglioLinee = append(dbs.DettaglioLinee, newLine(l))
+	}
+	// Document level discounts and charges are included as lines
+	n := len(dbs.DettaglioLinee)
+	for _, d := range inv.Discounts {
+		n++
+		dbs.DettaglioLinee = append(dbs.DettaglioLinee,
+			newAdjustmentLine(n, d.Reason, d.Amount.Negate(), d.Taxes))
+	}
+	for _, c := range inv.Charges {
+		if c.Key.Has(sdi.KeyFundContribution) {
+			continue
+		}
+		n++
+		dbs.DettaglioLinee = append(dbs.DettaglioLinee,
+			newAdjustmentLine(n, c.Reason, c.Amount, c.Taxes))
+	}
+	if tt := inv.Totals.Taxes; tt != nil {
+		for _, ct := range tt.Categories {
+			if ct.Code != tax.CategoryVAT {
+				continue
+			}
+			for _, rt := range ct.Rates {
+				dbs.DatiRiepilogo = append(dbs.DatiRiepilogo, newSummary(rt))
+			}
+		}
+	}
+	return dbs
+}
+
+func newLine(l *bill.Line) *DettaglioLinea {
+	dl := &DettaglioLinea{
+		NumeroLinea: l.Index,
+		Descrizione: l.Item.Name,
+		Quantita:    formatQuantity(l.Quantity),
+		UnitaMisura: string(l.Item.Unit),
+	}
+	if l.Item.Price != nil {
+		dl.PrezzoUnitario = formatPrice(*l.Item.Price)
+	}
+	if l.Total != nil {
+		dl.PrezzoTotale = formatPrice(*l.Total)
+	}
+	if l.Item.Ref != cbc.CodeEmpty {
+		dl.CodiceArticolo = append(dl.CodiceArticolo, &CodiceArticolo{
+			CodiceTipo:   "INTERNO",
+			CodiceValore: l.Item.Ref.String(),
+		})
+	}
+	for _, id := range l.Item.Identities {
+		dl.CodiceArticolo = append(dl.CodiceArticolo, &CodiceArticolo{
+			CodiceTipo:   identityType(id),
+			CodiceValore: id.Code.String(),
+		})
+	}
+	for _, d := range l.Discounts {
+		dl.ScontoMaggiorazione = append(dl.ScontoMaggiorazione, newAdjustment(typeDiscount, d.Percent, d.Amount))
+	}
+	for _, c := range l.Charges {
+		dl.ScontoMaggiorazione = append(dl.ScontoMaggiorazione, newAdjustment(typeCharge, c.Percent, c.Amount))
+	}
+	dl.AliquotaIVA, dl.Natura = vatDetails(l.Taxes)
+	if hasRetained(l.Taxes) {
+		dl.Ritenuta = flagYes
+	}
+	return dl
+}
+
+func newAdjustmentLine(n int, reason string, amount num.Amount, ts tax.Set) *DettaglioLinea {
+	dl := &DettaglioLinea{
+		NumeroLinea:    n,
+		Descrizione:    reason,
+		PrezzoUnitario: formatPrice(amount),
+		PrezzoTotale:   formatPrice(amount),
+	}
+	dl.AliquotaIVA, dl.Natura = vatDetails(ts)
+	if hasRetained(ts) {
+		dl.Ritenuta = flagYes
+	}
+	return dl
+}
+
+func newAdjustment(typ string, p *num.Percentage, amount num.Amount) *ScontoMaggiorazione {
+	sm := &ScontoMaggiorazione{Tipo: typ}
+	if p != nil {
+		sm.Percentuale = formatPercent(p)
+	} else {
+		sm.Importo = formatPrice(amount)
+	}
+	return sm
+}
+
+func newSummary(rt *tax.RateTotal) *DatiRiepilogo {
+	dr := &DatiRiepilogo{
+		AliquotaIVA:       formatPercent(rt.Percent),
+		ImponibileImporto: formatAmount(rt.Base),
+		Imposta:           formatAmount(rt.Amount),
+		EsigibilitaIVA:    rt.Ext.Get(sdi.ExtKeyVATLiability).String(),
+	}
+	if code := rt.Ext.Get(sdi.ExtKeyExempt); code != cbc.CodeEmpty {
+		dr.Natura = code.String()
+		if kd := tax.ExtensionForKey(sdi.ExtKeyExempt); kd != nil {
+			if cd := kd.CodeDef(code); cd != nil {
+				dr.RiferimentoNormativo = cd.Name.In(i18n.IT)
+			}
+		}
+	}
+	return dr
+}
+
+func newPayment(inv *bill.Invoice) []*DatiPagamento {
+	p := inv.Payment
+	if p == nil || p.Instructions == nil {
+		return nil
+	}
+	instr := p.Instructions
+	tmpl := &DettaglioPagamento{
+		ModalitaPagamento: instr.Ext.Get(sdi.ExtKeyPaymentMeans).String(),
+		CodicePagamento:   instr.Ref.String(),
+	}
+	if len(instr.CreditTransfer) > 0 {
+		ct := instr.CreditTransfer[0]
+		tmpl.IBAN = ct.IBAN
+		tmpl.BIC = ct.BIC
+		tmpl.IstitutoFinanziario = ct.Name
+	}
+
+	dp := &DatiPagamento{CondizioniPagamento: paymentFull}
+	var dues []*pay.DueDate
+	if p.Terms != nil {
+		dues = p.Terms.DueDates
+	}
+	if len(dues) == 0 {
+		d := *tmpl
+		d.ImportoPagamento = formatAmount(inv.Totals.Payable)
+		if inv.Totals.Due != nil {
+			d.ImportoPagamento = formatAmount(*inv.Totals.Due)
+		}
+		dp.DettaglioPagamento = append(dp.DettaglioPagamento, &d)
+		return []*DatiPagamento{dp}
+	}
+	if len(dues) > 1 {
+		dp.CondizioniPagamento = paymentInstalments
+	}
+	for _, dd := range dues {
+		d := *tmpl
+		d.ImportoPagamento = formatAmount(dd.Amount)
+		if dd.Date != nil {
+			d.DataScadenzaPagamento = dd.Date.String()
+		}
+		dp.DettaglioPagamento = append(dp.DettaglioPagamento, &d)
+	}
+	return []*DatiPagamento{dp}
+}
+
+// vatDetails provides the VAT percentage and nature code from the tax set.
+func vatDetails(ts tax.Set) (string, string) {
+	vat := ts.Get(tax.CategoryVAT)
+	if vat == nil {
+		return formatPercent(nil), ""
+	}
+	return formatPercent(vat.Percent), vat.Ext.Get(sdi.ExtKeyExempt).String()
+}
+
+func hasRetained(ts tax.Set) bool {
+	for _, c := range ts {
+		if _, ok := retainedTypes[c.Category]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func identityType(id *org.Identity) string {
+	switch {
+	case id.Type != cbc.CodeEmpty:
+		return id.Type.String()
+	case id.Key != cbc.KeyEmpty:
+		return id.Key.String()
+	case id.Label != "":
+		return id.Label
+	}
+	return "INTERNO"
+}
+
+// progressiveCode provides up to the last 10 alphanumeric characters of the
+// code, as required for the ProgressivoInvio.
+func progressiveCode(code cbc.Code) string {
+	var b []rune
+	for _, r := range code.String() {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b = append(b, r)
+		}
+	}
+	if len(b) > progressiveLength {
+		b = b[len(b)-progressiveLength:]
+	}
+	if len(b) == 0 {
+		return "1"
+	}
+	return string(b)
+}
+
+// splitText breaks up the text into chunks of the maximum length.
+func splitText(s string, size int) []string {
+	var out []string
+	r := []rune(s)
+	for len(r) > size {
+		out = append(out, string(r[:size]))
+		r = r[size:]
+	}
+	if len(r) > 0 {
+		out = append(out, string(r))
+	}
+	return out
+}
+
+func formatAmount(a num.Amount) string {
+	return a.Rescale(2).String()
+}
+
+func formatPrice(a num.Amount) string {
+	return a.RescaleRange(2, 8).String()
+}
+
+func formatQuantity(a num.Amount) string {
+	return a.RescaleRange(2, 8).String()
+}
+
+// formatPercent provides the percentage with two decimal places, where
+// exempt rates without a percentage are "0.00".
+func formatPercent(p *num.Percentage) string {
+	if p == nil {
+		return "0.00"
+	}
+	return p.Amount().Rescale(2).String()
+}
@@ -0,0 +1,116 @@
+// Package fatturapa converts GOBL invoices with the `it-sdi-v1` addon into
+// FatturaPA 1.2.x XML documents ready to be sent to the Italian SDI, and
+// provides a best-effort importer for received FatturaPA files.
+//
+// Extensions defined by the SDI addon are mapped directly to their
+// elements: `it-sdi-format` to the transmission format and document
+// version, `it-sdi-document-type` to TipoDocumento, `it-sdi-fiscal-regime`
+// to the supplier's RegimeFiscale, `it-sdi-exempt` to Natura,
+// `it-sdi-retained` to CausalePagamento, `it-sdi-fund-type` to TipoCassa,
+// `it-sdi-vat-liability` to EsigibilitaIVA, and `it-sdi-payment-means` to
+// ModalitaPagamento. Document level discounts and charges, other than fund
+// contributions, are added as lines so that the line totals match the VAT
+// summary. The importer only supports the subset of FatturaPA generated
+// here, which covers the majority of documents in circulation.
+package fatturapa
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/observe"
+)
+
+// Namespaces used in FatturaPA documents.
+const (
+	NamespaceFatturaPA = "http://ivaservizi.agenziaentrate.gov.it/docs/xsd/fatture/v1.2"
+	NamespaceDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	NamespaceXSI       = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+var prefixes = xmlns.Prefixes{
+	NamespaceFatturaPA: "p",
+}
+
+// Option is used to customize the document generated from an invoice.
+type Option func(*options)
+
+type options struct {
+	transmitterCountry l10n.TaxCountryCode
+	transmitterCode    string
+	progressive        string
+}
+
+// WithTransmitter sets the tax identity of the intermediary transmitting
+// the document, which defaults to the supplier's.
+func WithTransmitter(country l10n.TaxCountryCode, code string) Option {
+	return func(o *options) {
+		o.transmitterCountry = country
+		o.transmitterCode = code
+	}
+}
+
+// WithProgressive sets the transmitter's unique file sequence code
+// (ProgressivoInvio), which defaults to the last alphanumeric characters
+// of the invoice code.
+func WithProgressive(code string) Option {
+	return func(o *options) {
+		o.progressive = code
+	}
+}
+
+// Bytes provides the indented XML representation of the document.
+func (d *Document) Bytes() ([]byte, error) {
+	return xmlns.Marshal(d)
+}
+
+// ConvertInvoice is a convenience method to generate the FatturaPA XML for
+// the calculated invoice.
+func ConvertInvoice(inv *bill.Invoice, opts ...Option) (out []byte, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpExport, "fatturapa")
+	defer func() { s.End(inv, err) }()
+	doc, err := FromInvoice(inv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+// Parse decodes the FatturaPA XML data.
+func Parse(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := xmlns.Unmarshal(data, prefixes, doc); err != nil {
+		return nil, fmt.Errorf("parsing fatturapa: %w", err)
+	}
+	if doc.Header == nil || len(doc.Bodies) == 0 {
+		return nil, fmt.Errorf("parsing fatturapa: missing header or body")
+	}
+	return doc, nil
+}
+
+// ParseInvoice is a convenience method to parse the FatturaPA XML data
+// containing a single invoice and convert it into a calculated GOBL
+// invoice.
+func ParseInvoice(data []byte) (inv *bill.Invoice, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpImport, "fatturapa")
+	defer func() { s.End(inv, err) }()
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToInvoice()
+}
+
+func newDocument(format string) *Document {
+	return &Document{
+		XMLName:  xml.Name{Local: "p:FatturaElettronica"},
+		PNS:      NamespaceFatturaPA,
+		DSNS:     NamespaceDSig,
+		XSINS:    NamespaceXSI,
+		Versione: format,
+	}
+}
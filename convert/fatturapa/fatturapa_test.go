@@ -0,0 +1,225 @@
+package fatturapa_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/it/sdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/fatturapa"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/regimes/it"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(sdi.V1),
+		Tags:      tax.WithTags(sdi.TagFreelance),
+		Series:    "SAMPLE",
+		Code:      "001",
+		Currency:  "EUR",
+		IssueDate: cal.MakeDate(2023, 3, 2),
+		Supplier: &org.Party{
+			Name:  "MªF. Services",
+			TaxID: &tax.Identity{Country: "IT", Code: "12345678903"},
+			Addresses: []*org.Address{
+				{Number: "9", Street: "Via di Torrevecchia", Locality: "Roma", Region: "RM", Code: "00168", Country: "IT"},
+			},
+			Emails: []*org.Email{{Address: "billing@example.com"}},
+		},
+		Customer: &org.Party{
+			Name:  "Mela S.r.l.",
+			TaxID: &tax.Identity{Country: "IT", Code: "13029381004"},
+			Inboxes: []*org.Inbox{
+				{Key: sdi.KeyInboxCode, Code: "M5UXCR1"},
+			},
+			Addresses: []*org.Address{
+				{Number: "1", Street: "Via del Corso", Locality: "Roma", Region: "RM", Code: "00100", Country: "IT"},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(20, 0),
+				Item: &org.Item{
+					Name:  "Development services",
+					Price: num.NewAmount(9000, 2),
+					Unit:  org.UnitHour,
+				},
+				Discounts: []*bill.LineDiscount{
+					{Percent: num.NewPercentage(10, 2), Reason: "Special discount"},
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+					{Category: it.TaxCategoryIRPEF, Percent: num.NewPercentage(200, 3), Ext: tax.Extensions{sdi.ExtKeyRetained: "A"}},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Exempt training",
+					Price: num.NewAmount(10000, 2),
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Key: tax.KeyExempt, Ext: tax.Extensions{sdi.ExtKeyExempt: "N4"}},
+				},
+			},
+		},
+		Charges: []*bill.Charge{
+			{
+				Key:     sdi.KeyFundContribution,
+				Percent: num.NewPercentage(4, 2),
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+					{Category: it.TaxCategoryIRPEF, Percent: num.NewPercentage(200, 3), Ext: tax.Extensions{sdi.ExtKeyRetained: "A"}},
+				},
+				Ext: tax.Extensions{sdi.ExtKeyFundType: "TC22"},
+			},
+		},
+		Notes: []*org.Note{{Key: org.NoteKeyGeneral, Text: "Thank you"}},
+		Payment: &bill.PaymentDetails{
+			Terms: &pay.Terms{
+				DueDates: []*pay.DueDate{
+					{Date: cal.NewDate(2023, 4, 2), Percent: num.NewPercentage(50, 2)},
+					{Date: cal.NewDate(2023, 5, 2), Percent: num.NewPercentage(50, 2)},
+				},
+			},
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+				CreditTransfer: []*pay.CreditTransfer{
+					{IBAN: "IT60X0542811101000000123456", BIC: "ABCDITMM"},
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func TestFromInvoice(t *testing.T) {
+	t.Run("freelance", func(t *testing.T) {
+		inv := testInvoice(t)
+		doc, err := fatturapa.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "FPR12", doc.Versione)
+
+		dt := doc.Header.DatiTrasmissione
+		assert.Equal(t, "12345678903", dt.IDTrasmittente.IDCodice)
+		assert.Equal(t, "001", dt.ProgressivoInvio)
+		assert.Equal(t, "M5UXCR1", dt.CodiceDestinatario)
+		assert.Equal(t, "RF01", doc.Header.CedentePrestatore.DatiAnagrafici.RegimeFiscale)
+		assert.Equal(t, "billing@example.com", doc.Header.CedentePrestatore.Contatti.Email)
+		assert.Equal(t, "RM", doc.Header.CessionarioCommittente.Sede.Provincia)
+
+		body := doc.Bodies[0]
+		dgd := body.DatiGenerali.DatiGeneraliDocumento
+		assert.Equal(t, "TD06", dgd.TipoDocumento)
+		assert.Equal(t, "SAMPLE-001", dgd.Numero)
+		assert.Equal(t, "2023-03-02", dgd.Data)
+		assert.Equal(t, []string{"Thank you"}, dgd.Causale)
+		require.Len(t, dgd.DatiRitenuta, 1)
+		assert.Equal(t, "RT01", dgd.DatiRitenuta[0].TipoRitenuta)
+		assert.Equal(t, "20.00", dgd.DatiRitenuta[0].AliquotaRitenuta)
+		assert.Equal(t, "A", dgd.DatiRitenuta[0].CausalePagamento)
+		require.Len(t, dgd.DatiCassaPrevidenziale, 1)
+		assert.Equal(t, "TC22", dgd.DatiCassaPrevidenziale[0].TipoCassa)
+		assert.Equal(t, "4.00", dgd.DatiCassaPrevidenziale[0].AlCassa)
+		assert.Equal(t, "SI", dgd.DatiCassaPrevidenziale[0].Ritenuta)
+		assert.Equal(t, inv.Totals.TotalWithTax.String(), dgd.ImportoTotaleDocumento)
+
+		lines := body.DatiBeniServizi.DettaglioLinee
+		require.Len(t, lines, 2)
+		assert.Equal(t, "90.00", lines[0].PrezzoUnitario)
+		assert.Equal(t, "1620.00", lines[0].PrezzoTotale)
+		assert.Equal(t, "22.00", lines[0].AliquotaIVA)
+		assert.Equal(t, "SI", lines[0].Ritenuta)
+		assert.Equal(t, "SC", lines[0].ScontoMaggiorazione[0].Tipo)
+		assert.Equal(t, "0.00", lines[1].AliquotaIVA)
+		assert.Equal(t, "N4", lines[1].Natura)
+
+		sums := body.DatiBeniServizi.DatiRiepilogo
+		require.Len(t, sums, 2)
+		assert.Equal(t, "N4", sums[1].Natura)
+		assert.Equal(t, "Esenti", sums[1].RiferimentoNormativo)
+
+		dp := body.DatiPagamento[0]
+		assert.Equal(t, "TP01", dp.CondizioniPagamento)
+		require.Len(t, dp.DettaglioPagamento, 2)
+		assert.Equal(t, "MP05", dp.DettaglioPagamento[0].ModalitaPagamento)
+		assert.Equal(t, "2023-04-02", dp.DettaglioPagamento[0].DataScadenzaPagamento)
+		assert.Equal(t, "IT60X0542811101000000123456", dp.DettaglioPagamento[0].IBAN)
+	})
+	t.Run("options", func(t *testing.T) {
+		doc, err := fatturapa.FromInvoice(testInvoice(t),
+			fatturapa.WithTransmitter("IT", "01234567890"),
+			fatturapa.WithProgressive("00042"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "01234567890", doc.Header.DatiTrasmissione.IDTrasmittente.IDCodice)
+		assert.Equal(t, "00042", doc.Header.DatiTrasmissione.ProgressivoInvio)
+	})
+	t.Run("missing addon", func(t *testing.T) {
+		inv := &bill.Invoice{Totals: new(bill.Totals)}
+		_, err := fatturapa.FromInvoice(inv)
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		_, err := fatturapa.FromInvoice(&bill.Invoice{})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	inv := testInvoice(t)
+	data, err := fatturapa.ConvertInvoice(inv)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<p:FatturaElettronica xmlns:p="`+fatturapa.NamespaceFatturaPA+`"`)
+	assert.Contains(t, string(data), `versione="FPR12"`)
+
+	out, err := fatturapa.ParseInvoice(data)
+	require.NoError(t, err)
+	assert.Equal(t, []cbc.Key{sdi.V1}, out.GetAddons())
+	assert.True(t, out.HasTags(sdi.TagFreelance))
+	assert.Equal(t, cbc.Code("TD06"), out.Tax.Ext.Get(sdi.ExtKeyDocumentType))
+	assert.Equal(t, cbc.Code("SAMPLE-001"), out.Code)
+	assert.Equal(t, inv.IssueDate, out.IssueDate)
+	assert.Equal(t, cbc.Code("M5UXCR1"), out.Customer.Inboxes[0].Code)
+	assert.Equal(t, cbc.Code("RF01"), out.Supplier.Ext.Get(sdi.ExtKeyFiscalRegime))
+	assert.Equal(t, "Thank you", out.Notes[0].Text)
+	require.Len(t, out.Charges, 1)
+	assert.Equal(t, cbc.Code("TC22"), out.Charges[0].Ext.Get(sdi.ExtKeyFundType))
+	assert.Equal(t, cbc.Code("MP05"), out.Payment.Instructions.Ext.Get(sdi.ExtKeyPaymentMeans))
+	assert.Len(t, out.Payment.Terms.DueDates, 2)
+
+	assert.Equal(t, inv.Totals.Sum.String(), out.Totals.Sum.String())
+	assert.Equal(t, inv.Totals.Tax.String(), out.Totals.Tax.String())
+	assert.Equal(t, inv.Totals.RetainedTax.String(), out.Totals.RetainedTax.String())
+	assert.Equal(t, inv.Totals.Payable.String(), out.Totals.Payable.String())
+}
+
+func TestParse(t *testing.T) {
+	t.Run("missing body", func(t *testing.T) {
+		_, err := fatturapa.Parse([]byte(`<p:FatturaElettronica xmlns:p="` + fatturapa.NamespaceFatturaPA + `" versione="FPR12"></p:FatturaElettronica>`))
+		assert.ErrorContains(t, err, "parsing fatturapa: missing header or body")
+	})
+	t.Run("multiple bodies", func(t *testing.T) {
+		data, err := fatturapa.ConvertInvoice(testInvoice(t))
+		require.NoError(t, err)
+		doc, err := fatturapa.Parse(data)
+		require.NoError(t, err)
+		doc.Bodies = append(doc.Bodies, doc.Bodies[0])
+		_, err = doc.ToInvoice()
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		list, err := doc.ToInvoices()
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+	})
+}
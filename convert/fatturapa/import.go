@@ -0,0 +1,404 @@
+package fatturapa
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl/addons/it/sdi"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/regimes/it"
+	"github.com/invopop/gobl/tax"
+)
+
+// ToInvoice converts the FatturaPA document into a GOBL invoice using the
+// SDI addon, and calculates the result. Documents containing multiple
+// invoices should use ToInvoices instead.
+func (d *Document) ToInvoice() (*bill.Invoice, error) {
+	if len(d.Bodies) != 1 {
+		return nil, fmt.Errorf("%w: %d invoices in document", convert.ErrUnsupported, len(d.Bodies))
+	}
+	list, err := d.ToInvoices()
+	if err != nil {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// ToInvoices converts each of the bodies of the FatturaPA document into
+// a calculated GOBL invoice with the parties defined in the header.
+func (d *Document) ToInvoices() ([]*bill.Invoice, error) {
+	list := make([]*bill.Invoice, 0, len(d.Bodies))
+	for i, b := range d.Bodies {
+		inv, err := d.invoice(b)
+		if err != nil {
+			return nil, fmt.Errorf("body %d: %w", i, err)
+		}
+		if err := inv.Calculate(); err != nil {
+			return nil, fmt.Errorf("body %d: calculating invoice: %w", i, err)
+		}
+		list = append(list, inv)
+	}
+	return list, nil
+}
+
+func (d *Document) invoice(b *Body) (*bill.Invoice, error) {
+	if b.DatiGenerali == nil || b.DatiGenerali.DatiGeneraliDocumento == nil {
+		return nil, fmt.Errorf("missing general document data")
+	}
+	dgd := b.DatiGenerali.DatiGeneraliDocumento
+	inv := &bill.Invoice{
+		Addons:   tax.WithAddons(sdi.V1),
+		Code:     cbc.Code(dgd.Numero),
+		Currency: currency.Code(dgd.Divisa),
+		Tax: &bill.Tax{
+			Ext: tax.Extensions{
+				sdi.ExtKeyDocumentType: cbc.Code(dgd.TipoDocumento),
+			},
+		},
+	}
+	if dt := d.Header.DatiTrasmissione; dt != nil && dt.FormatoTrasmissione != "" {
+		inv.Tax.Ext[sdi.ExtKeyFormat] = cbc.Code(dt.FormatoTrasmissione)
+	}
+	typ, tags := documentScenario(cbc.Code(dgd.TipoDocumento))
+	inv.Type = typ
+	inv.Tags = tax.WithTags(tags...)
+
+	date, err := convert.ParseDate(dgd.Data)
+	if err != nil {
+		return nil, fmt.Errorf("issue date: %w", err)
+	}
+	if date != nil {
+		inv.IssueDate = *date
+	}
+	for _, c := range dgd.Causale {
+		// consecutive reasons are split to fit the maximum length
+		if len(inv.Notes) > 0 {
+			inv.Notes[0].Text += c
+			continue
+		}
+		inv.Notes = append(inv.Notes, &org.Note{Key: org.NoteKeyGeneral, Text: c})
+	}
+
+	inv.Supplier = goblParty(d.Header.CedentePrestatore)
+	inv.Customer = goblParty(d.Header.CessionarioCommittente)
+	if dt := d.Header.DatiTrasmissione; dt != nil && inv.Customer != nil {
+		if code := dt.CodiceDestinatario; code != "" && code != destinationNone && code != destinationForeign {
+			inv.Customer.Inboxes = append(inv.Customer.Inboxes, &org.Inbox{Key: sdi.KeyInboxCode, Code: cbc.Code(code)})
+		}
+		if dt.PECDestinatario != "" {
+			inv.Customer.Inboxes = append(inv.Customer.Inboxes, &org.Inbox{Key: sdi.KeyInboxPEC, Email: dt.PECDestinatario})
+		}
+	}
+
+	retained, err := goblRetainedTaxes(dgd.DatiRitenuta)
+	if err != nil {
+		return nil, err
+	}
+	if dbs := b.DatiBeniServizi; dbs != nil {
+		for _, dl := range dbs.DettaglioLinee {
+			line, err := goblLine(dl, retained)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", dl.NumeroLinea, err)
+			}
+			inv.Lines = append(inv.Lines, line)
+		}
+	}
+	for _, dc := range dgd.DatiCassaPrevidenziale {
+		c, err := goblFundContribution(dc, retained)
+		if err != nil {
+			return nil, fmt.Errorf("fund contribution: %w", err)
+		}
+		inv.Charges = append(inv.Charges, c)
+	}
+
+	dg := b.DatiGenerali
+	if len(dg.DatiOrdineAcquisto) > 0 || len(dg.DatiContratto) > 0 {
+		inv.Ordering = new(bill.Ordering)
+		for _, dd := range dg.DatiOrdineAcquisto {
+			ref, err := goblDocumentRef(dd)
+			if err != nil {
+				return nil, fmt.Errorf("purchase order: %w", err)
+			}
+			inv.Ordering.Purchases = append(inv.Ordering.Purchases, ref)
+		}
+		for _, dd := range dg.DatiContratto {
+			ref, err := goblDocumentRef(dd)
+			if err != nil {
+				return nil, fmt.Errorf("contract: %w", err)
+			}
+			inv.Ordering.Contracts = append(inv.Ordering.Contracts, ref)
+		}
+	}
+	for _, dd := range dg.DatiFattureCollegate {
+		ref, err := goblDocumentRef(dd)
+		if err != nil {
+			return nil, fmt.Errorf("preceding: %w", err)
+		}
+		inv.Preceding = append(inv.Preceding, ref)
+	}
+
+	if inv.Payment, err = goblPayment(b.DatiPagamento); err != nil {
+		return nil, fmt.Errorf("payment: %w", err)
+	}
+	return inv, nil
+}
+
+// documentScenario determines the invoice type and tags that will result
+// in the document type code being applied by the addon's scenarios.
+func documentScenario(code cbc.Code) (cbc.Key, []cbc.Key) {
+	if ad := tax.AddonForKey(sdi.V1); ad != nil {
+		for _, ss := range ad.Scenarios {
+			for _, s := range ss.List {
+				if s.Ext.Get(sdi.ExtKeyDocumentType) != code || len(s.Types) == 0 {
+					continue
+				}
+				return s.Types[0], s.Tags
+			}
+		}
+	}
+	return bill.InvoiceTypeStandard, nil
+}
+
+func goblParty(p *Party) *org.Party {
+	if p == nil || p.DatiAnagrafici == nil {
+		return nil
+	}
+	da := p.DatiAnagrafici
+	party := new(org.Party)
+	if a := da.Anagrafica; a != nil {
+		party.Name = a.Denominazione
+		if party.Name == "" && (a.Nome != "" || a.Cognome != "") {
+			party.Name = strings.TrimSpace(a.Nome + " " + a.Cognome)
+			party.People = []*org.Person{
+				{Name: &org.Name{Given: a.Nome, Surname: a.Cognome}},
+			}
+		}
+	}
+	if id := da.IDFiscaleIVA; id != nil {
+		party.TaxID = &tax.Identity{Country: l10n.TaxCountryCode(id.IDPaese)}
+		if id.IDCodice != codeForeign {
+			party.TaxID.Code = cbc.Code(id.IDCodice)
+		}
+	}
+	if da.CodiceFiscale != "" {
+		if party.TaxID == nil {
+			party.TaxID = &tax.Identity{Country: "IT"}
+		}
+		party.Identities = append(party.Identities, &org.Identity{
+			Key:  it.IdentityKeyFiscalCode,
+			Code: cbc.Code(da.CodiceFiscale),
+		})
+	}
+	if da.RegimeFiscale != "" {
+		party.Ext = tax.Extensions{sdi.ExtKeyFiscalRegime: cbc.Code(da.RegimeFiscale)}
+	}
+	if s := p.Sede; s != nil {
+		addr := &org.Address{
+			Street:   s.Indirizzo,
+			Number:   s.NumeroCivico,
+			Code:     cbc.Code(s.CAP),
+			Locality: s.Comune,
+			Region:   s.Provincia,
+			Country:  l10n.ISOCountryCode(s.Nazione),
+		}
+		if addr.Country != "IT" && s.CAP == postCodeForeign {
+			addr.Code = cbc.CodeEmpty
+		}
+		party.Addresses = append(party.Addresses, addr)
+	}
+	if c := p.Contatti; c != nil {
+		if c.Telefono != "" {
+			party.Telephones = append(party.Telephones, &org.Telephone{Number: c.Telefono})
+		}
+		if c.Email != "" {
+			party.Emails = append(party.Emails, &org.Email{Address: c.Email})
+		}
+	}
+	return party
+}
+
+// goblRetainedTaxes provides the retained tax combos indexed by the
+// TipoRitenuta, to be applied to lines flagged with Ritenuta.
+func goblRetainedTaxes(list []*DatiRitenuta) ([]*tax.Combo, error) {
+	var out []*tax.Combo
+	for _, dr := range list {
+		cat := cbc.CodeEmpty
+		for c, t := range retainedTypes {
+			if t == dr.TipoRitenuta {
+				cat = c
+				break
+			}
+		}
+		if cat == cbc.CodeEmpty {
+			return nil, fmt.Errorf("%w: retained tax type '%s'", convert.ErrUnsupported, dr.TipoRitenuta)
+		}
+		p, err := convert.ParsePercent(dr.AliquotaRitenuta)
+		if err != nil {
+			return nil, fmt.Errorf("retained tax: %w", err)
+		}
+		c := &tax.Combo{Category: cat, Percent: p}
+		if dr.CausalePagamento != "" {
+			c.Ext = tax.Extensions{sdi.ExtKeyRetained: cbc.Code(dr.CausalePagamento)}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func goblLine(dl *DettaglioLinea, retained []*tax.Combo) (*bill.Line, error) {
+	price, err := convert.ParseAmount(dl.PrezzoUnitario)
+	if err != nil {
+		return nil, err
+	}
+	qty := num.MakeAmount(1, 0)
+	if dl.Quantita != "" {
+		if qty, err = convert.ParseAmount(dl.Quantita); err != nil {
+			return nil, err
+		}
+	}
+	line := &bill.Line{
+		Quantity: qty,
+		Item: &org.Item{
+			Name:  dl.Descrizione,
+			Price: &price,
+			Unit:  org.Unit(dl.UnitaMisura),
+		},
+	}
+	for _, ca := range dl.CodiceArticolo {
+		line.Item.Identities = append(line.Item.Identities, &org.Identity{
+			Type: cbc.Code(ca.CodiceTipo),
+			Code: cbc.Code(ca.CodiceValore),
+		})
+	}
+	for _, sm := range dl.ScontoMaggiorazione {
+		p, err := convert.ParsePercent(sm.Percentuale)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := convert.ParseAmount(sm.Importo)
+		if err != nil {
+			return nil, err
+		}
+		if sm.Tipo == typeCharge {
+			line.Charges = append(line.Charges, &bill.LineCharge{Percent: p, Amount: amount})
+		} else {
+			line.Discounts = append(line.Discounts, &bill.LineDiscount{Percent: p, Amount: amount})
+		}
+	}
+	if line.Taxes, err = goblTaxes(dl.AliquotaIVA, dl.Natura, dl.Ritenuta, retained); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func goblFundContribution(dc *DatiCassaPrevidenziale, retained []*tax.Combo) (*bill.Charge, error) {
+	p, err := convert.ParsePercent(dc.AlCassa)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := convert.ParseAmount(dc.ImportoContributoCassa)
+	if err != nil {
+		return nil, err
+	}
+	c := &bill.Charge{
+		Key:     sdi.KeyFundContribution,
+		Percent: p,
+		Amount:  amount,
+		Ext:     tax.Extensions{sdi.ExtKeyFundType: cbc.Code(dc.TipoCassa)},
+	}
+	if dc.ImponibileCassa != "" {
+		base, err := convert.ParseAmount(dc.ImponibileCassa)
+		if err != nil {
+			return nil, err
+		}
+		c.Base = &base
+	}
+	if c.Taxes, err = goblTaxes(dc.AliquotaIVA, dc.Natura, dc.Ritenuta, retained); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func goblTaxes(percent, nature, withholding string, retained []*tax.Combo) (tax.Set, error) {
+	vat := &tax.Combo{Category: tax.CategoryVAT}
+	if nature != "" {
+		vat.Ext = tax.Extensions{sdi.ExtKeyExempt: cbc.Code(nature)}
+	} else {
+		p, err := convert.ParsePercent(percent)
+		if err != nil {
+			return nil, err
+		}
+		vat.Percent = p
+	}
+	ts := tax.Set{vat}
+	if withholding == flagYes {
+		for _, r := range retained {
+			c := *r
+			ts = append(ts, &c)
+		}
+	}
+	return ts, nil
+}
+
+func goblDocumentRef(dd *DatiDocumento) (*org.DocumentRef, error) {
+	ref := &org.DocumentRef{Code: cbc.Code(dd.IDDocumento)}
+	date, err := convert.ParseDate(dd.Data)
+	if err != nil {
+		return nil, err
+	}
+	ref.IssueDate = date
+	if dd.CodiceCUP != "" {
+		ref.Identities = append(ref.Identities, &org.Identity{Type: sdi.IdentityTypeCUP, Code: cbc.Code(dd.CodiceCUP)})
+	}
+	if dd.CodiceCIG != "" {
+		ref.Identities = append(ref.Identities, &org.Identity{Type: sdi.IdentityTypeCIG, Code: cbc.Code(dd.CodiceCIG)})
+	}
+	return ref, nil
+}
+
+func goblPayment(list []*DatiPagamento) (*bill.PaymentDetails, error) {
+	if len(list) == 0 || len(list[0].DettaglioPagamento) == 0 {
+		return nil, nil
+	}
+	dps := list[0].DettaglioPagamento
+	first := dps[0]
+	instr := &pay.Instructions{
+		Key: sdi.PaymentMeansExtensions().Lookup(cbc.Code(first.ModalitaPagamento)),
+		Ref: cbc.Code(first.CodicePagamento),
+		Ext: tax.Extensions{sdi.ExtKeyPaymentMeans: cbc.Code(first.ModalitaPagamento)},
+	}
+	if instr.Key == cbc.KeyEmpty {
+		instr.Key = pay.MeansKeyAny
+	}
+	if first.IBAN != "" {
+		instr.CreditTransfer = []*pay.CreditTransfer{
+			{IBAN: first.IBAN, BIC: first.BIC, Name: first.IstitutoFinanziario},
+		}
+	}
+	pd := &bill.PaymentDetails{Instructions: instr}
+	for _, dp := range dps {
+		date, err := convert.ParseDate(dp.DataScadenzaPagamento)
+		if err != nil {
+			return nil, err
+		}
+		if date == nil {
+			continue
+		}
+		amount, err := convert.ParseAmount(dp.ImportoPagamento)
+		if err != nil {
+			return nil, err
+		}
+		if pd.Terms == nil {
+			pd.Terms = new(pay.Terms)
+		}
+		pd.Terms.DueDates = append(pd.Terms.DueDates, &pay.DueDate{Date: date, Amount: amount})
+	}
+	return pd, nil
+}
@@ -0,0 +1,87 @@
+// Package xmlns helps decode XML documents into structures whose field tags
+// use namespace prefixes, like `cbc:ID`, so that the same structures can be
+// used to both generate and parse documents regardless of the prefixes
+// chosen by the author of the source XML.
+package xmlns
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// Prefixes maps namespace URLs to the prefixes used in struct field tags.
+// An empty prefix implies the namespace is the document's default.
+type Prefixes map[string]string
+
+// Unmarshal parses the XML data into the value, renaming each element and
+// attribute to use the prefixes defined for their namespace.
+func Unmarshal(data []byte, prefixes Prefixes, v any) error {
+	d := xml.NewTokenDecoder(&reader{
+		src:      xml.NewDecoder(bytes.NewReader(data)),
+		prefixes: prefixes,
+	})
+	return d.Decode(v)
+}
+
+type reader struct {
+	src      *xml.Decoder
+	prefixes Prefixes
+}
+
+func (r *reader) Token() (xml.Token, error) {
+	t, err := r.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch e := t.(type) {
+	case xml.StartElement:
+		e.Name = r.rename(e.Name)
+		attrs := make([]xml.Attr, 0, len(e.Attr))
+		for _, a := range e.Attr {
+			if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+				continue
+			}
+			a.Name = r.rename(a.Name)
+			attrs = append(attrs, a)
+		}
+		e.Attr = attrs
+		return e, nil
+	case xml.EndElement:
+		e.Name = r.rename(e.Name)
+		return e, nil
+	}
+	return t, nil
+}
+
+func (r *reader) rename(n xml.Name) xml.Name {
+	if n.Space == "" {
+		return n
+	}
+	p, ok := r.prefixes[n.Space]
+	if !ok || p == "" {
+		return xml.Name{Local: n.Local}
+	}
+	return xml.Name{Local: p + ":" + n.Local}
+}
+
+// Marshal generates the indented XML document for the value, including
+// the standard XML header.
+func Marshal(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	if err := Encode(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the indented XML for the value to the writer.
+func Encode(w io.Writer, v any) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,51 @@
+package xmlns_test
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doc struct {
+	XMLName xml.Name `xml:"Doc"`
+	XMLNS   string   `xml:"xmlns,attr,omitempty"`
+	XNS     string   `xml:"xmlns:x,attr,omitempty"`
+	ID      string   `xml:"x:ID"`
+	Note    *note    `xml:"Note,omitempty"`
+}
+
+type note struct {
+	Lang  string `xml:"x:lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+var prefixes = xmlns.Prefixes{
+	"urn:doc": "",
+	"urn:x":   "x",
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := []byte(`<d:Doc xmlns:d="urn:doc" xmlns:other="urn:x"><other:ID>123</other:ID><d:Note other:lang="en">Hi</d:Note></d:Doc>`)
+	d := new(doc)
+	require.NoError(t, xmlns.Unmarshal(data, prefixes, d))
+	assert.Equal(t, "123", d.ID)
+	assert.Equal(t, "Hi", d.Note.Value)
+	assert.Equal(t, "en", d.Note.Lang)
+	assert.Empty(t, d.XMLNS)
+}
+
+func TestMarshal(t *testing.T) {
+	d := &doc{XMLNS: "urn:doc", XNS: "urn:x", ID: "123"}
+	data, err := xmlns.Marshal(d)
+	require.NoError(t, err)
+	assert.Equal(t, xml.Header+`<Doc xmlns="urn:doc" xmlns:x="urn:x">
+  <x:ID>123</x:ID>
+</Doc>`, string(data))
+
+	out := new(doc)
+	require.NoError(t, xmlns.Unmarshal(data, prefixes, out))
+	assert.Equal(t, "123", out.ID)
+}
@@ -0,0 +1,150 @@
+package pain
+
+import "encoding/xml"
+
+// Document is the root of a pain.001 message.
+type Document struct {
+	XMLName  xml.Name         `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.09 Document"`
+	Initiate *CreditTransfers `xml:"CstmrCdtTrfInitn"`
+}
+
+// CreditTransfers contains the group header and the payment information
+// blocks, each with the transfers to be executed from the debtor's
+// account on a given date.
+type CreditTransfers struct {
+	GroupHeader *GroupHeader   `xml:"GrpHdr"`
+	Payments    []*PaymentInfo `xml:"PmtInf"`
+}
+
+// GroupHeader identifies the message.
+type GroupHeader struct {
+	MessageID            string `xml:"MsgId"`
+	CreationDateTime     string `xml:"CreDtTm"`
+	NumberOfTransactions int    `xml:"NbOfTxs"`
+	ControlSum           string `xml:"CtrlSum"`
+	InitiatingParty      *Party `xml:"InitgPty"`
+}
+
+// PaymentInfo groups the transfers made from the debtor's account.
+type PaymentInfo struct {
+	PaymentInfoID        string                 `xml:"PmtInfId"`
+	PaymentMethod        string                 `xml:"PmtMtd"`
+	NumberOfTransactions int                    `xml:"NbOfTxs"`
+	ControlSum           string                 `xml:"CtrlSum"`
+	PaymentTypeInfo      *PaymentTypeInfo       `xml:"PmtTpInf,omitempty"`
+	RequestedExecution   *DateChoice            `xml:"ReqdExctnDt"`
+	Debtor               *Party                 `xml:"Dbtr"`
+	DebtorAccount        *Account               `xml:"DbtrAcct"`
+	DebtorAgent          *Agent                 `xml:"DbtrAgt"`
+	ChargeBearer         string                 `xml:"ChrgBr,omitempty"`
+	Transactions         []*CreditTransferTxInf `xml:"CdtTrfTxInf"`
+}
+
+// PaymentTypeInfo defines the service level of the transfers.
+type PaymentTypeInfo struct {
+	ServiceLevel *Code `xml:"SvcLvl"`
+}
+
+// Code wraps a code from an external list.
+type Code struct {
+	Code string `xml:"Cd"`
+}
+
+// DateChoice contains a date.
+type DateChoice struct {
+	Date string `xml:"Dt"`
+}
+
+// Party identifies the debtor, creditor, or initiating party.
+type Party struct {
+	Name          string         `xml:"Nm"`
+	PostalAddress *PostalAddress `xml:"PstlAdr,omitempty"`
+}
+
+// PostalAddress of a party.
+type PostalAddress struct {
+	StreetName     string `xml:"StrtNm,omitempty"`
+	BuildingNumber string `xml:"BldgNb,omitempty"`
+	PostCode       string `xml:"PstCd,omitempty"`
+	TownName       string `xml:"TwnNm,omitempty"`
+	Country        string `xml:"Ctry,omitempty"`
+}
+
+// GenericID is an identifier defined by other schemes.
+type GenericID struct {
+	ID string `xml:"Id"`
+}
+
+// Account identifies a bank account by its IBAN, or any other number.
+type Account struct {
+	ID *AccountID `xml:"Id"`
+}
+
+// AccountID contains either the IBAN or another account number.
+type AccountID struct {
+	IBAN  string     `xml:"IBAN,omitempty"`
+	Other *GenericID `xml:"Othr,omitempty"`
+}
+
+// Agent identifies a financial institution.
+type Agent struct {
+	FinancialInstitution *FinancialInstitution `xml:"FinInstnId"`
+}
+
+// FinancialInstitution identifies the bank by its BIC, or flags it as not
+// provided.
+type FinancialInstitution struct {
+	BIC   string     `xml:"BICFI,omitempty"`
+	Other *GenericID `xml:"Othr,omitempty"`
+}
+
+// CreditTransferTxInf is a single transfer to a creditor.
+type CreditTransferTxInf struct {
+	PaymentID       *PaymentID      `xml:"PmtId"`
+	Amount          *Amount         `xml:"Amt"`
+	CreditorAgent   *Agent          `xml:"CdtrAgt,omitempty"`
+	Creditor        *Party          `xml:"Cdtr"`
+	CreditorAccount *Account        `xml:"CdtrAcct"`
+	Remittance      *RemittanceInfo `xml:"RmtInf,omitempty"`
+}
+
+// PaymentID contains the references of the transfer.
+type PaymentID struct {
+	InstructionID string `xml:"InstrId,omitempty"`
+	EndToEndID    string `xml:"EndToEndId"`
+}
+
+// Amount to transfer.
+type Amount struct {
+	Instructed *CurrencyAmount `xml:"InstdAmt"`
+}
+
+// CurrencyAmount is an amount with its currency.
+type CurrencyAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// RemittanceInfo contains either an unstructured message or a structured
+// creditor reference.
+type RemittanceInfo struct {
+	Unstructured string                `xml:"Ustrd,omitempty"`
+	Structured   *StructuredRemittance `xml:"Strd,omitempty"`
+}
+
+// StructuredRemittance contains the creditor reference.
+type StructuredRemittance struct {
+	CreditorReference *CreditorReference `xml:"CdtrRefInf"`
+}
+
+// CreditorReference is an ISO 11649 reference.
+type CreditorReference struct {
+	Type      *CreditorReferenceType `xml:"Tp"`
+	Reference string                 `xml:"Ref"`
+}
+
+// CreditorReferenceType identifies the kind of reference.
+type CreditorReferenceType struct {
+	CodeOrProprietary *Code  `xml:"CdOrPrtry"`
+	Issuer            string `xml:"Issr,omitempty"`
+}
@@ -0,0 +1,323 @@
+// Package pain builds ISO 20022 pain.001 Customer Credit Transfer
+// Initiation messages from GOBL invoices received from suppliers, so that
+// they can be paid through the debtor's bank without re-keying the
+// payment details.
+//
+// Each invoice results in a single transfer of the amount due, or the
+// payable total if there were no advances, to the first credit transfer
+// account in the invoice's payment instructions. The creditor is the
+// invoice's payee, or the supplier if no payee was defined. Payment
+// references that are valid ISO 11649 creditor references are sent as
+// structured remittance information, and any other reference, or the
+// invoice's series and code, as an unstructured message.
+//
+// Transfers are grouped into payment information blocks by currency and
+// requested execution date, which is taken from the options or the
+// invoice's first due date. Transfers in euros use the SEPA service
+// level.
+package pain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+)
+
+// Namespace of the pain.001 version generated.
+const Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+
+// Codes used in the messages.
+const (
+	paymentMethodTransfer = "TRF"
+	serviceLevelSEPA      = "SEPA"
+	chargeBearerShared    = "SLEV"
+	referenceTypeSCOR     = "SCOR"
+	notProvided           = "NOTPROVIDED"
+	maxIDLength           = 35
+	maxNameLength         = 140
+	maxRemittanceLength   = 140
+	dateTimeLayout        = "2006-01-02T15:04:05"
+)
+
+// Debtor describes the party paying the invoices and the account the
+// transfers will be made from.
+type Debtor struct {
+	// Name of the debtor.
+	Name string
+	// IBAN of the account to debit.
+	IBAN string
+	// BIC of the debtor's bank, if known.
+	BIC string
+}
+
+// Option is used to customize the message generated from the invoices.
+type Option func(*options)
+
+type options struct {
+	messageID string
+	created   time.Time
+	execution *cal.Date
+}
+
+// WithMessageID sets the identifier of the message, which defaults to one
+// based on the creation time.
+func WithMessageID(id string) Option {
+	return func(o *options) {
+		o.messageID = id
+	}
+}
+
+// WithCreationTime overrides the time the message was created, which
+// defaults to the current time.
+func WithCreationTime(t time.Time) Option {
+	return func(o *options) {
+		o.created = t
+	}
+}
+
+// WithExecutionDate sets the date on which all the transfers should be
+// executed, ignoring the invoices' due dates.
+func WithExecutionDate(d cal.Date) Option {
+	return func(o *options) {
+		o.execution = &d
+	}
+}
+
+// Bytes provides the indented XML representation of the document.
+func (d *Document) Bytes() ([]byte, error) {
+	return xmlns.Marshal(d)
+}
+
+// ConvertInvoices is a convenience method to generate the pain.001 XML for
+// the calculated invoices.
+func ConvertInvoices(debtor *Debtor, invs []*bill.Invoice, opts ...Option) ([]byte, error) {
+	doc, err := FromInvoices(debtor, invs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+// FromInvoices builds the credit transfer initiation message to pay the
+// calculated invoices from the debtor's account.
+func FromInvoices(debtor *Debtor, invs []*bill.Invoice, opts ...Option) (*Document, error) {
+	o := &options{created: time.Now()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if debtor == nil || debtor.IBAN == "" {
+		return nil, fmt.Errorf("%w: debtor account required", convert.ErrUnsupported)
+	}
+	if err := pay.CheckIBAN(debtor.IBAN); err != nil {
+		return nil, fmt.Errorf("debtor IBAN: %w", err)
+	}
+	if len(invs) == 0 {
+		return nil, fmt.Errorf("%w: no invoices", convert.ErrUnsupported)
+	}
+	if o.messageID == "" {
+		o.messageID = o.created.UTC().Format("20060102150405")
+	}
+
+	groups := make(map[string]*PaymentInfo)
+	sums := make(map[string]num.Amount)
+	var keys []string
+	total := num.AmountZero
+	for i, inv := range invs {
+		tx, amount, err := newTransaction(inv)
+		if err != nil {
+			return nil, fmt.Errorf("invoice %d: %w", i, err)
+		}
+		date := executionDate(inv, o)
+		key := inv.Currency.String() + "/" + date.String()
+		pi, ok := groups[key]
+		if !ok {
+			pi = newPaymentInfo(debtor, inv.Currency, date)
+			groups[key] = pi
+			sums[key] = num.AmountZero
+			keys = append(keys, key)
+		}
+		pi.Transactions = append(pi.Transactions, tx)
+		sums[key] = sums[key].MatchPrecision(amount).Add(amount)
+		total = total.MatchPrecision(amount).Add(amount)
+	}
+	sort.Strings(keys)
+
+	doc := &Document{
+		Initiate: &CreditTransfers{
+			GroupHeader: &GroupHeader{
+				MessageID:            truncate(o.messageID, maxIDLength),
+				CreationDateTime:     o.created.Format(dateTimeLayout),
+				NumberOfTransactions: len(invs),
+				ControlSum:           total.String(),
+				InitiatingParty:      &Party{Name: truncate(debtor.Name, maxNameLength)},
+			},
+		},
+	}
+	for i, key := range keys {
+		pi := groups[key]
+		pi.PaymentInfoID = truncate(o.messageID+"-"+strconv.Itoa(i+1), maxIDLength)
+		pi.NumberOfTransactions = len(pi.Transactions)
+		pi.ControlSum = sums[key].String()
+		doc.Initiate.Payments = append(doc.Initiate.Payments, pi)
+	}
+	return doc, nil
+}
+
+func newPaymentInfo(debtor *Debtor, cur currency.Code, date cal.Date) *PaymentInfo {
+	pi := &PaymentInfo{
+		PaymentMethod:      paymentMethodTransfer,
+		RequestedExecution: &DateChoice{Date: date.String()},
+		Debtor:             &Party{Name: truncate(debtor.Name, maxNameLength)},
+		DebtorAccount:      &Account{ID: &AccountID{IBAN: pay.NormalizeIBAN(debtor.IBAN)}},
+		DebtorAgent:        newAgent(debtor.BIC),
+		ChargeBearer:       chargeBearerShared,
+	}
+	if cur == currency.EUR {
+		pi.PaymentTypeInfo = &PaymentTypeInfo{ServiceLevel: &Code{Code: serviceLevelSEPA}}
+	}
+	return pi
+}
+
+func newAgent(bic string) *Agent {
+	if bic == "" {
+		return &Agent{FinancialInstitution: &FinancialInstitution{
+			Other: &GenericID{ID: notProvided},
+		}}
+	}
+	return &Agent{FinancialInstitution: &FinancialInstitution{BIC: pay.NormalizeBIC(bic)}}
+}
+
+// executionDate uses the date from the options, or the first due date
+// of the invoice, or the creation date.
+func executionDate(inv *bill.Invoice, o *options) cal.Date {
+	if o.execution != nil {
+		return *o.execution
+	}
+	if inv.Payment != nil && inv.Payment.Terms != nil {
+		for _, dd := range inv.Payment.Terms.DueDates {
+			if dd.Date != nil {
+				return *dd.Date
+			}
+		}
+	}
+	return cal.DateOf(o.created)
+}
+
+// newTransaction prepares the transfer of the amount due for the invoice,
+// which is also returned to calculate the control sums.
+func newTransaction(inv *bill.Invoice) (*CreditTransferTxInf, num.Amount, error) {
+	if inv.Totals == nil {
+		return nil, num.AmountZero, convert.ErrNotCalculated
+	}
+	if inv.Type.In(bill.InvoiceTypeCreditNote) {
+		return nil, num.AmountZero, fmt.Errorf("%w: credit notes cannot be paid", convert.ErrUnsupported)
+	}
+	pd := inv.Payment
+	if pd == nil || pd.Instructions == nil || len(pd.Instructions.CreditTransfer) == 0 {
+		return nil, num.AmountZero, fmt.Errorf("%w: credit transfer instructions required", convert.ErrUnsupported)
+	}
+	ct := pd.Instructions.CreditTransfer[0]
+	amount := inv.Totals.Payable
+	if inv.Totals.Due != nil {
+		amount = *inv.Totals.Due
+	}
+	if !amount.IsPositive() {
+		return nil, num.AmountZero, fmt.Errorf("%w: nothing due", convert.ErrUnsupported)
+	}
+	creditor := inv.Supplier
+	if pd.Payee != nil {
+		creditor = pd.Payee
+	}
+	if creditor == nil {
+		return nil, num.AmountZero, fmt.Errorf("%w: creditor required", convert.ErrUnsupported)
+	}
+	acc := &AccountID{IBAN: pay.NormalizeIBAN(ct.IBAN)}
+	if ct.IBAN == "" {
+		if ct.Number == "" {
+			return nil, num.AmountZero, fmt.Errorf("%w: creditor account required", convert.ErrUnsupported)
+		}
+		acc = &AccountID{Other: &GenericID{ID: ct.Number}}
+	} else if err := pay.CheckIBAN(ct.IBAN); err != nil {
+		return nil, num.AmountZero, fmt.Errorf("creditor IBAN: %w", err)
+	}
+	tx := &CreditTransferTxInf{
+		PaymentID: &PaymentID{EndToEndID: truncate(invoiceCode(inv), maxIDLength)},
+		Amount: &Amount{Instructed: &CurrencyAmount{
+			Currency: inv.Currency.String(),
+			Value:    amount.String(),
+		}},
+		Creditor:        newParty(creditor, ct.Name),
+		CreditorAccount: &Account{ID: acc},
+		Remittance:      newRemittance(inv, pd.Instructions.Ref),
+	}
+	if ct.BIC != "" {
+		tx.CreditorAgent = newAgent(ct.BIC)
+	}
+	return tx, amount, nil
+}
+
+func newParty(p *org.Party, name string) *Party {
+	if name == "" {
+		name = p.Name
+	}
+	party := &Party{Name: truncate(name, maxNameLength)}
+	if len(p.Addresses) > 0 {
+		a := p.Addresses[0]
+		party.PostalAddress = &PostalAddress{
+			StreetName:     a.Street,
+			BuildingNumber: a.Number,
+			PostCode:       a.Code.String(),
+			TownName:       a.Locality,
+			Country:        a.Country.String(),
+		}
+		if party.PostalAddress.Country == "" && p.TaxID != nil {
+			party.PostalAddress.Country = p.TaxID.Country.String()
+		}
+	}
+	return party
+}
+
+func newRemittance(inv *bill.Invoice, ref cbc.Code) *RemittanceInfo {
+	if pay.CheckCreditorReference(ref.String()) == nil {
+		return &RemittanceInfo{Structured: &StructuredRemittance{
+			CreditorReference: &CreditorReference{
+				Type: &CreditorReferenceType{
+					CodeOrProprietary: &Code{Code: referenceTypeSCOR},
+				},
+				Reference: pay.NormalizeBIC(ref.String()),
+			},
+		}}
+	}
+	msg := invoiceCode(inv)
+	if ref != cbc.CodeEmpty {
+		msg = ref.String() + " " + msg
+	}
+	return &RemittanceInfo{Unstructured: truncate(msg, maxRemittanceLength)}
+}
+
+func invoiceCode(inv *bill.Invoice) string {
+	if inv.Series == cbc.CodeEmpty {
+		return inv.Code.String()
+	}
+	return inv.Series.String() + "-" + inv.Code.String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n])
+}
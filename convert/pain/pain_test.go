@@ -0,0 +1,240 @@
+package pain_test
+
+import (
+	"testing"
+	"time"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/pain"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDebtor = &pain.Debtor{
+	Name: "Provide One S.L.",
+	IBAN: "ES91 2100 0418 4502 0005 1332",
+	BIC:  "CAIXESBBXXX",
+}
+
+func testInvoice(t *testing.T, code cbc.Code, cur currency.Code, iban string, ref cbc.Code) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Series:    "INV",
+		Code:      code,
+		Currency:  cur,
+		IssueDate: cal.MakeDate(2024, 6, 1),
+		Supplier: &org.Party{
+			Name:  "Musterlieferant GmbH",
+			TaxID: &tax.Identity{Country: "DE", Code: "111111125"},
+			Addresses: []*org.Address{
+				{Street: "Hauptstraße", Number: "1", Code: "10115", Locality: "Berlin"},
+			},
+		},
+		Customer: &org.Party{
+			Name:  "Provide One S.L.",
+			TaxID: &tax.Identity{Country: "ES", Code: "B98602642"},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item:     &org.Item{Name: "Development", Price: num.NewAmount(10000, 2)},
+				Taxes:    tax.Set{{Category: tax.CategoryVAT, Rate: "general"}},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Terms: &pay.Terms{
+				DueDates: []*pay.DueDate{
+					{Date: cal.NewDate(2024, 7, 1), Percent: num.NewPercentage(100, 2)},
+				},
+			},
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+				Ref: ref,
+				CreditTransfer: []*pay.CreditTransfer{
+					{IBAN: iban, BIC: "COBADEFFXXX"},
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func testOptions() []pain.Option {
+	return []pain.Option{
+		pain.WithMessageID("MSG-1"),
+		pain.WithCreationTime(time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC)),
+	}
+}
+
+func TestFromInvoices(t *testing.T) {
+	t.Run("single invoice", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "RF18539007547034")
+		doc, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv}, testOptions()...)
+		require.NoError(t, err)
+		hdr := doc.Initiate.GroupHeader
+		assert.Equal(t, "MSG-1", hdr.MessageID)
+		assert.Equal(t, "2024-06-10T09:30:00", hdr.CreationDateTime)
+		assert.Equal(t, 1, hdr.NumberOfTransactions)
+		assert.Equal(t, "238.00", hdr.ControlSum)
+		require.Len(t, doc.Initiate.Payments, 1)
+		pi := doc.Initiate.Payments[0]
+		assert.Equal(t, "MSG-1-1", pi.PaymentInfoID)
+		assert.Equal(t, "TRF", pi.PaymentMethod)
+		assert.Equal(t, "SEPA", pi.PaymentTypeInfo.ServiceLevel.Code)
+		assert.Equal(t, "2024-07-01", pi.RequestedExecution.Date)
+		assert.Equal(t, "ES9121000418450200051332", pi.DebtorAccount.ID.IBAN)
+		assert.Equal(t, "CAIXESBBXXX", pi.DebtorAgent.FinancialInstitution.BIC)
+		require.Len(t, pi.Transactions, 1)
+		tx := pi.Transactions[0]
+		assert.Equal(t, "INV-001", tx.PaymentID.EndToEndID)
+		assert.Equal(t, "EUR", tx.Amount.Instructed.Currency)
+		assert.Equal(t, "238.00", tx.Amount.Instructed.Value)
+		assert.Equal(t, "Musterlieferant GmbH", tx.Creditor.Name)
+		assert.Equal(t, "DE", tx.Creditor.PostalAddress.Country)
+		assert.Equal(t, "COBADEFFXXX", tx.CreditorAgent.FinancialInstitution.BIC)
+		assert.Equal(t, "DE89370400440532013000", tx.CreditorAccount.ID.IBAN)
+		require.NotNil(t, tx.Remittance.Structured)
+		assert.Equal(t, "SCOR", tx.Remittance.Structured.CreditorReference.Type.CodeOrProprietary.Code)
+		assert.Equal(t, "RF18539007547034", tx.Remittance.Structured.CreditorReference.Reference)
+	})
+	t.Run("grouped by currency and date", func(t *testing.T) {
+		inv1 := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv2 := testInvoice(t, "002", currency.EUR, "DE89370400440532013000", "PO-123")
+		inv3 := testInvoice(t, "003", currency.EUR, "DE89370400440532013000", "")
+		inv3.Payment.Terms.DueDates[0].Date = cal.NewDate(2024, 8, 1)
+		inv4 := testInvoice(t, "004", currency.USD, "DE89370400440532013000", "")
+		invs := []*bill.Invoice{inv1, inv2, inv3, inv4}
+		doc, err := pain.FromInvoices(testDebtor, invs, testOptions()...)
+		require.NoError(t, err)
+		assert.Equal(t, 4, doc.Initiate.GroupHeader.NumberOfTransactions)
+		assert.Equal(t, "952.00", doc.Initiate.GroupHeader.ControlSum)
+		require.Len(t, doc.Initiate.Payments, 3)
+		pi := doc.Initiate.Payments[0]
+		assert.Equal(t, "2024-07-01", pi.RequestedExecution.Date)
+		assert.Equal(t, 2, pi.NumberOfTransactions)
+		assert.Equal(t, "476.00", pi.ControlSum)
+		assert.Equal(t, "INV-001", pi.Transactions[0].Remittance.Unstructured)
+		assert.Equal(t, "PO-123 INV-002", pi.Transactions[1].Remittance.Unstructured)
+		assert.Equal(t, "2024-08-01", doc.Initiate.Payments[1].RequestedExecution.Date)
+		usd := doc.Initiate.Payments[2]
+		assert.Nil(t, usd.PaymentTypeInfo)
+		assert.Equal(t, "USD", usd.Transactions[0].Amount.Instructed.Currency)
+	})
+	t.Run("execution date option", func(t *testing.T) {
+		inv1 := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv2 := testInvoice(t, "002", currency.EUR, "DE89370400440532013000", "")
+		inv2.Payment.Terms = nil
+		opts := append(testOptions(), pain.WithExecutionDate(cal.MakeDate(2024, 6, 15)))
+		doc, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv1, inv2}, opts...)
+		require.NoError(t, err)
+		require.Len(t, doc.Initiate.Payments, 1)
+		assert.Equal(t, "2024-06-15", doc.Initiate.Payments[0].RequestedExecution.Date)
+	})
+	t.Run("payee and amount due", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Payee = &org.Party{Name: "Factoring GmbH"}
+		inv.Payment.Advances = []*pay.Advance{
+			{Description: "Deposit", Amount: num.MakeAmount(3800, 2)},
+		}
+		require.NoError(t, inv.Calculate())
+		doc, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv}, testOptions()...)
+		require.NoError(t, err)
+		tx := doc.Initiate.Payments[0].Transactions[0]
+		assert.Equal(t, "Factoring GmbH", tx.Creditor.Name)
+		assert.Nil(t, tx.Creditor.PostalAddress)
+		assert.Equal(t, "200.00", tx.Amount.Instructed.Value)
+	})
+	t.Run("account number without BIC", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.USD, "", "")
+		inv.Payment.Instructions.CreditTransfer[0] = &pay.CreditTransfer{Number: "123456789"}
+		debtor := &pain.Debtor{Name: "Provide One S.L.", IBAN: testDebtor.IBAN}
+		doc, err := pain.FromInvoices(debtor, []*bill.Invoice{inv}, testOptions()...)
+		require.NoError(t, err)
+		pi := doc.Initiate.Payments[0]
+		assert.Equal(t, "NOTPROVIDED", pi.DebtorAgent.FinancialInstitution.Other.ID)
+		tx := pi.Transactions[0]
+		assert.Nil(t, tx.CreditorAgent)
+		assert.Equal(t, "123456789", tx.CreditorAccount.ID.Other.ID)
+	})
+}
+
+func TestFromInvoicesErrors(t *testing.T) {
+	t.Run("missing debtor", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		_, err := pain.FromInvoices(nil, []*bill.Invoice{inv})
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		assert.ErrorContains(t, err, "debtor account required")
+	})
+	t.Run("invalid debtor IBAN", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		_, err := pain.FromInvoices(&pain.Debtor{IBAN: "ES0000"}, []*bill.Invoice{inv})
+		assert.ErrorContains(t, err, "debtor IBAN")
+	})
+	t.Run("no invoices", func(t *testing.T) {
+		_, err := pain.FromInvoices(testDebtor, nil)
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv.Totals = nil
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+		assert.ErrorContains(t, err, "invoice 0")
+	})
+	t.Run("credit note", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv.Type = bill.InvoiceTypeCreditNote
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		assert.ErrorContains(t, err, "credit notes cannot be paid")
+	})
+	t.Run("missing instructions", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Instructions = nil
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		assert.ErrorContains(t, err, "credit transfer instructions required")
+	})
+	t.Run("missing account", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "", "")
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorContains(t, err, "creditor account required")
+	})
+	t.Run("invalid creditor IBAN", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE00370400440532013000", "")
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorContains(t, err, "creditor IBAN")
+	})
+	t.Run("nothing due", func(t *testing.T) {
+		inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Advances = []*pay.Advance{
+			{Description: "Paid", Percent: num.NewPercentage(100, 2)},
+		}
+		require.NoError(t, inv.Calculate())
+		_, err := pain.FromInvoices(testDebtor, []*bill.Invoice{inv})
+		assert.ErrorContains(t, err, "nothing due")
+	})
+}
+
+func TestConvertInvoices(t *testing.T) {
+	inv := testInvoice(t, "001", currency.EUR, "DE89370400440532013000", "RF18539007547034")
+	out, err := pain.ConvertInvoices(testDebtor, []*bill.Invoice{inv}, testOptions()...)
+	require.NoError(t, err)
+	data := string(out)
+	assert.Contains(t, data, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">`)
+	assert.Contains(t, data, "<CstmrCdtTrfInitn>")
+	assert.Contains(t, data, `<InstdAmt Ccy="EUR">238.00</InstdAmt>`)
+	assert.Contains(t, data, "<Ref>RF18539007547034</Ref>")
+	assert.Contains(t, data, "<ReqdExctnDt>")
+	assert.Contains(t, data, "<Dt>2024-07-01</Dt>")
+}
@@ -0,0 +1,394 @@
+package saft
+
+import (
+	"encoding/xml"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Debit and credit indicators used in the OECD layout.
+const (
+	indicatorDebit  = "D"
+	indicatorCredit = "C"
+)
+
+// OECDAuditFile is the root of an OECD SAF-T 2.0 report containing the
+// sales invoices section.
+type OECDAuditFile struct {
+	XMLName         xml.Name             `xml:"urn:StandardAuditFile-Taxation-Financial:2.00 AuditFile"`
+	Header          *OECDHeader          `xml:"Header"`
+	MasterFiles     *OECDMasterFiles     `xml:"MasterFiles"`
+	SourceDocuments *OECDSourceDocuments `xml:"SourceDocuments"`
+}
+
+// OECDHeader contains the details of the company, software, and period.
+type OECDHeader struct {
+	AuditFileVersion     string                 `xml:"AuditFileVersion"`
+	AuditFileCountry     string                 `xml:"AuditFileCountry"`
+	AuditFileDateCreated string                 `xml:"AuditFileDateCreated"`
+	SoftwareCompanyName  string                 `xml:"SoftwareCompanyName"`
+	SoftwareID           string                 `xml:"SoftwareID"`
+	SoftwareVersion      string                 `xml:"SoftwareVersion"`
+	Company              *OECDCompany           `xml:"Company"`
+	DefaultCurrencyCode  string                 `xml:"DefaultCurrencyCode"`
+	SelectionCriteria    *OECDSelectionCriteria `xml:"SelectionCriteria"`
+	TaxAccountingBasis   string                 `xml:"TaxAccountingBasis"`
+}
+
+// OECDCompany describes the supplier or a customer.
+type OECDCompany struct {
+	RegistrationNumber string               `xml:"RegistrationNumber,omitempty"`
+	Name               string               `xml:"Name"`
+	Address            *OECDAddress         `xml:"Address,omitempty"`
+	TaxRegistration    *OECDTaxRegistration `xml:"TaxRegistration,omitempty"`
+}
+
+// OECDAddress of a company.
+type OECDAddress struct {
+	StreetName string `xml:"StreetName,omitempty"`
+	Number     string `xml:"Number,omitempty"`
+	City       string `xml:"City"`
+	PostalCode string `xml:"PostalCode,omitempty"`
+	Country    string `xml:"Country,omitempty"`
+}
+
+// OECDTaxRegistration contains the tax identity of a company.
+type OECDTaxRegistration struct {
+	TaxRegistrationNumber string `xml:"TaxRegistrationNumber"`
+}
+
+// OECDSelectionCriteria defines the period covered by the report.
+type OECDSelectionCriteria struct {
+	SelectionStartDate string `xml:"SelectionStartDate"`
+	SelectionEndDate   string `xml:"SelectionEndDate"`
+}
+
+// OECDMasterFiles lists the customers and taxes referenced by the
+// source documents.
+type OECDMasterFiles struct {
+	Customers *OECDCustomers `xml:"Customers,omitempty"`
+	TaxTable  *OECDTaxTable  `xml:"TaxTable,omitempty"`
+}
+
+// OECDCustomers wraps the list of customers.
+type OECDCustomers struct {
+	Customer []*OECDCustomer `xml:"Customer"`
+}
+
+// OECDCustomer details.
+type OECDCustomer struct {
+	*OECDCompany
+	CustomerID string `xml:"CustomerID"`
+}
+
+// OECDTaxTable wraps the list of tax types.
+type OECDTaxTable struct {
+	TaxTableEntry []*OECDTaxTableEntry `xml:"TaxTableEntry"`
+}
+
+// OECDTaxTableEntry groups the rates of a single tax type.
+type OECDTaxTableEntry struct {
+	TaxType        string            `xml:"TaxType"`
+	Description    string            `xml:"Description"`
+	TaxCodeDetails []*OECDTaxDetails `xml:"TaxCodeDetails"`
+}
+
+// OECDTaxDetails describes a single tax rate.
+type OECDTaxDetails struct {
+	TaxCode       string `xml:"TaxCode"`
+	Description   string `xml:"Description"`
+	TaxPercentage string `xml:"TaxPercentage"`
+	Country       string `xml:"Country"`
+}
+
+// OECDSourceDocuments contains the sales invoices.
+type OECDSourceDocuments struct {
+	SalesInvoices *OECDSalesInvoices `xml:"SalesInvoices"`
+}
+
+// OECDSalesInvoices contains the invoices issued during the period.
+type OECDSalesInvoices struct {
+	NumberOfEntries int            `xml:"NumberOfEntries"`
+	TotalDebit      string         `xml:"TotalDebit"`
+	TotalCredit     string         `xml:"TotalCredit"`
+	Invoice         []*OECDInvoice `xml:"Invoice"`
+}
+
+// OECDInvoice is a single sales document.
+type OECDInvoice struct {
+	InvoiceNo      string              `xml:"InvoiceNo"`
+	CustomerInfo   *OECDCustomerInfo   `xml:"CustomerInfo"`
+	Period         int                 `xml:"Period"`
+	PeriodYear     int                 `xml:"PeriodYear"`
+	InvoiceDate    string              `xml:"InvoiceDate"`
+	InvoiceType    string              `xml:"InvoiceType"`
+	SystemEntry    string              `xml:"SystemEntryDate"`
+	Line           []*OECDLine         `xml:"Line"`
+	DocumentTotals *OECDDocumentTotals `xml:"DocumentTotals"`
+}
+
+// OECDCustomerInfo references the customer in the master files.
+type OECDCustomerInfo struct {
+	CustomerID string `xml:"CustomerID"`
+}
+
+// OECDLine of an invoice.
+type OECDLine struct {
+	LineNumber           int                   `xml:"LineNumber"`
+	ProductCode          string                `xml:"ProductCode"`
+	ProductDescription   string                `xml:"ProductDescription"`
+	Quantity             string                `xml:"Quantity"`
+	InvoiceUOM           string                `xml:"InvoiceUOM,omitempty"`
+	UnitPrice            string                `xml:"UnitPrice"`
+	TaxPointDate         string                `xml:"TaxPointDate"`
+	Description          string                `xml:"Description"`
+	InvoiceLineAmount    *OECDAmount           `xml:"InvoiceLineAmount"`
+	DebitCreditIndicator string                `xml:"DebitCreditIndicator"`
+	TaxInformation       []*OECDTaxInformation `xml:"TaxInformation"`
+}
+
+// OECDAmount in the report's currency.
+type OECDAmount struct {
+	Amount string `xml:"Amount"`
+}
+
+// OECDTaxInformation describes a tax applied to a line or document.
+type OECDTaxInformation struct {
+	TaxType            string      `xml:"TaxType"`
+	TaxCode            string      `xml:"TaxCode"`
+	TaxPercentage      string      `xml:"TaxPercentage"`
+	TaxBase            string      `xml:"TaxBase"`
+	TaxAmount          *OECDAmount `xml:"TaxAmount"`
+	TaxExemptionReason string      `xml:"TaxExemptionReason,omitempty"`
+}
+
+// OECDDocumentTotals of the invoice.
+type OECDDocumentTotals struct {
+	TaxInformationTotals []*OECDTaxInformation `xml:"TaxInformationTotals"`
+	NetTotal             string                `xml:"NetTotal"`
+	GrossTotal           string                `xml:"GrossTotal"`
+}
+
+// Bytes provides the indented XML representation of the audit file.
+func (af *OECDAuditFile) Bytes() ([]byte, error) {
+	return xmlns.Marshal(af)
+}
+
+// FromInvoicesOECD generates an OECD SAF-T 2.0 audit file containing the
+// sales invoices, along with the customers and tax rates they reference.
+func FromInvoicesOECD(invs []*bill.Invoice, opts ...Option) (*OECDAuditFile, error) {
+	r, err := prepare(invs, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	af := &OECDAuditFile{
+		Header: &OECDHeader{
+			AuditFileVersion:     string(LayoutOECD),
+			AuditFileCountry:     r.supplier.TaxID.Country.String(),
+			AuditFileDateCreated: r.created.String(),
+			SoftwareCompanyName:  r.software.CompanyName,
+			SoftwareID:           r.software.ProductID,
+			SoftwareVersion:      r.software.ProductVersion,
+			Company:              newOECDCompany(r.supplier),
+			DefaultCurrencyCode:  r.currency.String(),
+			SelectionCriteria: &OECDSelectionCriteria{
+				SelectionStartDate: r.period.Start.String(),
+				SelectionEndDate:   r.period.End.String(),
+			},
+			TaxAccountingBasis: accountingBasis,
+		},
+		MasterFiles:     new(OECDMasterFiles),
+		SourceDocuments: &OECDSourceDocuments{SalesInvoices: new(OECDSalesInvoices)},
+	}
+	b := &oecdBuilder{
+		af:        af,
+		country:   r.supplier.TaxID.Country.Code(),
+		customers: make(map[string]bool),
+		taxes:     make(map[string]bool),
+	}
+	zero := r.currency.Def().Zero()
+	debit, credit := zero, zero
+	si := af.SourceDocuments.SalesInvoices
+	for _, inv := range r.invoices {
+		if isCredit(inv) {
+			debit = debit.Add(inv.Totals.Total)
+		} else {
+			credit = credit.Add(inv.Totals.Total)
+		}
+		si.Invoice = append(si.Invoice, b.invoice(inv))
+	}
+	si.NumberOfEntries = len(si.Invoice)
+	si.TotalDebit = debit.String()
+	si.TotalCredit = credit.String()
+	return af, nil
+}
+
+type oecdBuilder struct {
+	af        *OECDAuditFile
+	country   l10n.Code
+	customers map[string]bool
+	taxes     map[string]bool
+}
+
+func newOECDCompany(p *org.Party) *OECDCompany {
+	c := &OECDCompany{Name: p.Name}
+	if p.TaxID != nil && p.TaxID.Code != cbc.CodeEmpty {
+		c.RegistrationNumber = p.TaxID.Code.String()
+		c.TaxRegistration = &OECDTaxRegistration{
+			TaxRegistrationNumber: p.TaxID.Country.String() + p.TaxID.Code.String(),
+		}
+	}
+	if len(p.Addresses) > 0 {
+		a := p.Addresses[0]
+		c.Address = &OECDAddress{
+			StreetName: a.Street,
+			Number:     a.Number,
+			City:       withDefault(a.Locality, unknown),
+			PostalCode: a.Code.String(),
+			Country:    a.Country.String(),
+		}
+		if c.Address.Country == "" && p.TaxID != nil {
+			c.Address.Country = p.TaxID.Country.String()
+		}
+	}
+	return c
+}
+
+func (b *oecdBuilder) invoice(inv *bill.Invoice) *OECDInvoice {
+	doc := &OECDInvoice{
+		InvoiceNo:    invoiceNo(inv),
+		CustomerInfo: &OECDCustomerInfo{CustomerID: b.customer(inv.Customer)},
+		Period:       int(inv.IssueDate.Month),
+		PeriodYear:   inv.IssueDate.Year,
+		InvoiceDate:  inv.IssueDate.String(),
+		InvoiceType:  invoiceType(inv),
+		SystemEntry:  systemEntryDate(inv),
+		DocumentTotals: &OECDDocumentTotals{
+			NetTotal:   inv.Totals.Total.String(),
+			GrossTotal: inv.Totals.TotalWithTax.String(),
+		},
+	}
+	for _, l := range inv.Lines {
+		doc.Line = append(doc.Line, b.line(inv, l))
+	}
+	if inv.Totals.Taxes != nil {
+		for _, ct := range inv.Totals.Taxes.Categories {
+			for _, rt := range ct.Rates {
+				doc.DocumentTotals.TaxInformationTotals = append(
+					doc.DocumentTotals.TaxInformationTotals,
+					b.rateTotal(inv, ct, rt),
+				)
+			}
+		}
+	}
+	return doc
+}
+
+func (b *oecdBuilder) customer(p *org.Party) string {
+	id := customerID(p)
+	if b.customers[id] {
+		return id
+	}
+	b.customers[id] = true
+	c := &OECDCustomer{
+		OECDCompany: &OECDCompany{Name: finalConsumer},
+		CustomerID:  id,
+	}
+	if p != nil {
+		c.OECDCompany = newOECDCompany(p)
+	}
+	mf := b.af.MasterFiles
+	if mf.Customers == nil {
+		mf.Customers = new(OECDCustomers)
+	}
+	mf.Customers.Customer = append(mf.Customers.Customer, c)
+	return id
+}
+
+func (b *oecdBuilder) line(inv *bill.Invoice, l *bill.Line) *OECDLine {
+	line := &OECDLine{
+		LineNumber:           l.Index,
+		ProductCode:          productCode(l.Item),
+		ProductDescription:   l.Item.Name,
+		Quantity:             l.Quantity.String(),
+		InvoiceUOM:           l.Item.Unit.UNECE().String(),
+		UnitPrice:            unitPrice(l).String(),
+		TaxPointDate:         inv.IssueDate.String(),
+		Description:          l.Item.Name,
+		InvoiceLineAmount:    &OECDAmount{Amount: l.Total.String()},
+		DebitCreditIndicator: indicatorCredit,
+	}
+	if isCredit(inv) {
+		line.DebitCreditIndicator = indicatorDebit
+	}
+	zero := inv.Currency.Def().Zero()
+	for _, combo := range l.Taxes {
+		ti := newTaxInfo(inv, combo, i18n.EN)
+		b.taxEntry(combo.Category, ti)
+		amount := zero
+		if combo.Percent != nil {
+			amount = combo.Percent.Of(*l.Total).Rescale(zero.Exp())
+		}
+		line.TaxInformation = append(line.TaxInformation, &OECDTaxInformation{
+			TaxType:            combo.Category.String(),
+			TaxCode:            ti.code,
+			TaxPercentage:      ti.percent,
+			TaxBase:            l.Total.String(),
+			TaxAmount:          &OECDAmount{Amount: amount.String()},
+			TaxExemptionReason: ti.exemptionReason,
+		})
+	}
+	return line
+}
+
+// rateTotal summarizes the tax of a single rate in the invoice.
+func (b *oecdBuilder) rateTotal(inv *bill.Invoice, ct *tax.CategoryTotal, rt *tax.RateTotal) *OECDTaxInformation {
+	combo := &tax.Combo{Category: ct.Code, Key: rt.Key, Ext: rt.Ext, Percent: rt.Percent}
+	ti := newTaxInfo(inv, combo, i18n.EN)
+	return &OECDTaxInformation{
+		TaxType:            ct.Code.String(),
+		TaxCode:            ti.code,
+		TaxPercentage:      ti.percent,
+		TaxBase:            rt.Base.String(),
+		TaxAmount:          &OECDAmount{Amount: rt.Amount.String()},
+		TaxExemptionReason: ti.exemptionReason,
+	}
+}
+
+func (b *oecdBuilder) taxEntry(cat cbc.Code, ti *taxInfo) {
+	key := cat.String() + ":" + ti.country + ":" + ti.code + ":" + ti.percent
+	if b.taxes[key] {
+		return
+	}
+	b.taxes[key] = true
+	mf := b.af.MasterFiles
+	if mf.TaxTable == nil {
+		mf.TaxTable = new(OECDTaxTable)
+	}
+	var entry *OECDTaxTableEntry
+	for _, e := range mf.TaxTable.TaxTableEntry {
+		if e.TaxType == cat.String() {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		entry = &OECDTaxTableEntry{TaxType: cat.String(), Description: cat.String()}
+		if cd := tax.RegimeDefFor(b.country).CategoryDef(cat); cd != nil {
+			entry.Description = cd.Name.In(i18n.EN)
+		}
+		mf.TaxTable.TaxTableEntry = append(mf.TaxTable.TaxTableEntry, entry)
+	}
+	entry.TaxCodeDetails = append(entry.TaxCodeDetails, &OECDTaxDetails{
+		TaxCode:       ti.code,
+		Description:   ti.description,
+		TaxPercentage: ti.percent,
+		Country:       ti.country,
+	})
+}
@@ -0,0 +1,460 @@
+package saft
+
+import (
+	"encoding/xml"
+
+	ptsaft "github.com/invopop/gobl/addons/pt/saft"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Constants specific to the PT layout.
+const (
+	taxTypeVAT         = "IVA"
+	finalConsumerTaxID = "999999990"
+	documentStatus     = "N"
+	taxEntityGlobal    = "Global"
+	placeholder        = "0"
+	noCertificate      = "0"
+	noSpecialRegime    = "0"
+	noSelfBilling      = "0"
+	sourceProduced     = "P"
+)
+
+// AuditFile is the root of a SAF-T (PT) 1.04 report.
+type AuditFile struct {
+	XMLName         xml.Name         `xml:"urn:OECD:StandardAuditFile-Tax:PT_1.04_01 AuditFile"`
+	Header          *Header          `xml:"Header"`
+	MasterFiles     *MasterFiles     `xml:"MasterFiles"`
+	SourceDocuments *SourceDocuments `xml:"SourceDocuments"`
+}
+
+// Header contains the details of the company and software.
+type Header struct {
+	AuditFileVersion      string   `xml:"AuditFileVersion"`
+	CompanyID             string   `xml:"CompanyID"`
+	TaxRegistrationNumber string   `xml:"TaxRegistrationNumber"`
+	TaxAccountingBasis    string   `xml:"TaxAccountingBasis"`
+	CompanyName           string   `xml:"CompanyName"`
+	CompanyAddress        *Address `xml:"CompanyAddress"`
+	FiscalYear            int      `xml:"FiscalYear"`
+	StartDate             string   `xml:"StartDate"`
+	EndDate               string   `xml:"EndDate"`
+	CurrencyCode          string   `xml:"CurrencyCode"`
+	DateCreated           string   `xml:"DateCreated"`
+	TaxEntity             string   `xml:"TaxEntity"`
+	ProductCompanyTaxID   string   `xml:"ProductCompanyTaxID"`
+	SoftwareCertificate   string   `xml:"SoftwareCertificateNumber"`
+	ProductID             string   `xml:"ProductID"`
+	ProductVersion        string   `xml:"ProductVersion"`
+}
+
+// Address is used for both company and customer addresses.
+type Address struct {
+	AddressDetail string `xml:"AddressDetail"`
+	City          string `xml:"City"`
+	PostalCode    string `xml:"PostalCode"`
+	Country       string `xml:"Country"`
+}
+
+// MasterFiles lists the customers, products, and taxes referenced by
+// the source documents.
+type MasterFiles struct {
+	Customer []*Customer `xml:"Customer"`
+	Product  []*Product  `xml:"Product"`
+	TaxTable *TaxTable   `xml:"TaxTable,omitempty"`
+}
+
+// Customer details.
+type Customer struct {
+	CustomerID           string   `xml:"CustomerID"`
+	AccountID            string   `xml:"AccountID"`
+	CustomerTaxID        string   `xml:"CustomerTaxID"`
+	CompanyName          string   `xml:"CompanyName"`
+	BillingAddress       *Address `xml:"BillingAddress"`
+	SelfBillingIndicator string   `xml:"SelfBillingIndicator"`
+}
+
+// Product details.
+type Product struct {
+	ProductType        string `xml:"ProductType"`
+	ProductCode        string `xml:"ProductCode"`
+	ProductDescription string `xml:"ProductDescription"`
+	ProductNumberCode  string `xml:"ProductNumberCode"`
+}
+
+// TaxTable lists the tax rates used.
+type TaxTable struct {
+	TaxTableEntry []*TaxTableEntry `xml:"TaxTableEntry"`
+}
+
+// TaxTableEntry describes a single tax rate.
+type TaxTableEntry struct {
+	TaxType          string `xml:"TaxType"`
+	TaxCountryRegion string `xml:"TaxCountryRegion"`
+	TaxCode          string `xml:"TaxCode"`
+	Description      string `xml:"Description"`
+	TaxPercentage    string `xml:"TaxPercentage"`
+}
+
+// SourceDocuments contains the sales invoices.
+type SourceDocuments struct {
+	SalesInvoices *SalesInvoices `xml:"SalesInvoices"`
+}
+
+// SalesInvoices contains the invoices issued during the period, where
+// the total debit is the net sum of credit notes and the total credit
+// that of all other invoices.
+type SalesInvoices struct {
+	NumberOfEntries int        `xml:"NumberOfEntries"`
+	TotalDebit      string     `xml:"TotalDebit"`
+	TotalCredit     string     `xml:"TotalCredit"`
+	Invoice         []*Invoice `xml:"Invoice"`
+}
+
+// Invoice is a single sales document.
+type Invoice struct {
+	InvoiceNo      string          `xml:"InvoiceNo"`
+	ATCUD          string          `xml:"ATCUD"`
+	DocumentStatus *DocumentStatus `xml:"DocumentStatus"`
+	Hash           string          `xml:"Hash"`
+	HashControl    string          `xml:"HashControl"`
+	Period         int             `xml:"Period"`
+	InvoiceDate    string          `xml:"InvoiceDate"`
+	InvoiceType    string          `xml:"InvoiceType"`
+	SpecialRegimes *SpecialRegimes `xml:"SpecialRegimes"`
+	SourceID       string          `xml:"SourceID"`
+	SystemEntry    string          `xml:"SystemEntryDate"`
+	CustomerID     string          `xml:"CustomerID"`
+	Line           []*Line         `xml:"Line"`
+	DocumentTotals *DocumentTotals `xml:"DocumentTotals"`
+}
+
+// DocumentStatus of the invoice.
+type DocumentStatus struct {
+	InvoiceStatus     string `xml:"InvoiceStatus"`
+	InvoiceStatusDate string `xml:"InvoiceStatusDate"`
+	SourceBilling     string `xml:"SourceBilling"`
+	SourceID          string `xml:"SourceID"`
+}
+
+// SpecialRegimes flags.
+type SpecialRegimes struct {
+	SelfBillingIndicator         string `xml:"SelfBillingIndicator"`
+	CashVATSchemeIndicator       string `xml:"CashVATSchemeIndicator"`
+	ThirdPartiesBillingIndicator string `xml:"ThirdPartiesBillingIndicator"`
+}
+
+// Line of an invoice. Only one of the debit or credit amounts will be
+// set, depending on the type of document.
+type Line struct {
+	LineNumber         int        `xml:"LineNumber"`
+	References         *Reference `xml:"References,omitempty"`
+	ProductCode        string     `xml:"ProductCode"`
+	ProductDescription string     `xml:"ProductDescription"`
+	Quantity           string     `xml:"Quantity"`
+	UnitOfMeasure      string     `xml:"UnitOfMeasure"`
+	UnitPrice          string     `xml:"UnitPrice"`
+	TaxPointDate       string     `xml:"TaxPointDate"`
+	Description        string     `xml:"Description"`
+	DebitAmount        string     `xml:"DebitAmount,omitempty"`
+	CreditAmount       string     `xml:"CreditAmount,omitempty"`
+	Tax                *LineTax   `xml:"Tax"`
+	TaxExemptionReason string     `xml:"TaxExemptionReason,omitempty"`
+	TaxExemptionCode   string     `xml:"TaxExemptionCode,omitempty"`
+	SettlementAmount   string     `xml:"SettlementAmount,omitempty"`
+}
+
+// Reference to the document being corrected by a credit note.
+type Reference struct {
+	Reference string `xml:"Reference"`
+	Reason    string `xml:"Reason,omitempty"`
+}
+
+// LineTax describes the tax applied to a line.
+type LineTax struct {
+	TaxType          string `xml:"TaxType"`
+	TaxCountryRegion string `xml:"TaxCountryRegion"`
+	TaxCode          string `xml:"TaxCode"`
+	TaxPercentage    string `xml:"TaxPercentage"`
+}
+
+// DocumentTotals of the invoice.
+type DocumentTotals struct {
+	TaxPayable string `xml:"TaxPayable"`
+	NetTotal   string `xml:"NetTotal"`
+	GrossTotal string `xml:"GrossTotal"`
+}
+
+// Bytes provides the indented XML representation of the audit file.
+func (af *AuditFile) Bytes() ([]byte, error) {
+	return xmlns.Marshal(af)
+}
+
+// FromInvoicesPT generates a SAF-T (PT) audit file containing the
+// sales invoices and the master files they reference.
+func FromInvoicesPT(invs []*bill.Invoice, opts ...Option) (*AuditFile, error) {
+	r, err := prepare(invs, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	af := &AuditFile{
+		Header:          newHeader(r),
+		MasterFiles:     new(MasterFiles),
+		SourceDocuments: &SourceDocuments{SalesInvoices: new(SalesInvoices)},
+	}
+	b := &ptBuilder{
+		af:        af,
+		customers: make(map[string]bool),
+		products:  make(map[string]bool),
+		taxes:     make(map[string]bool),
+	}
+	zero := r.currency.Def().Zero()
+	debit, credit := zero, zero
+	for _, inv := range r.invoices {
+		doc := b.invoice(inv)
+		if isCredit(inv) {
+			debit = debit.Add(inv.Totals.Total)
+		} else {
+			credit = credit.Add(inv.Totals.Total)
+		}
+		af.SourceDocuments.SalesInvoices.Invoice = append(af.SourceDocuments.SalesInvoices.Invoice, doc)
+	}
+	si := af.SourceDocuments.SalesInvoices
+	si.NumberOfEntries = len(si.Invoice)
+	si.TotalDebit = debit.String()
+	si.TotalCredit = credit.String()
+	return af, nil
+}
+
+type ptBuilder struct {
+	af        *AuditFile
+	customers map[string]bool
+	products  map[string]bool
+	taxes     map[string]bool
+}
+
+func newHeader(r *report) *Header {
+	s := r.supplier
+	return &Header{
+		AuditFileVersion:      string(LayoutPT),
+		CompanyID:             s.TaxID.Code.String(),
+		TaxRegistrationNumber: s.TaxID.Code.String(),
+		TaxAccountingBasis:    accountingBasis,
+		CompanyName:           s.Name,
+		CompanyAddress:        newAddress(s),
+		FiscalYear:            r.period.Start.Year,
+		StartDate:             r.period.Start.String(),
+		EndDate:               r.period.End.String(),
+		CurrencyCode:          r.currency.String(),
+		DateCreated:           r.created.String(),
+		TaxEntity:             taxEntityGlobal,
+		ProductCompanyTaxID:   r.software.CompanyTaxID,
+		SoftwareCertificate:   withDefault(r.software.CertificateNumber, noCertificate),
+		ProductID:             r.software.ProductID,
+		ProductVersion:        r.software.ProductVersion,
+	}
+}
+
+func newAddress(p *org.Party) *Address {
+	a := &Address{
+		AddressDetail: unknown,
+		City:          unknown,
+		PostalCode:    unknown,
+		Country:       unknown,
+	}
+	if p == nil {
+		return a
+	}
+	if p.TaxID != nil && p.TaxID.Country != "" {
+		a.Country = p.TaxID.Country.String()
+	}
+	if len(p.Addresses) == 0 {
+		return a
+	}
+	addr := p.Addresses[0]
+	if addr.Street != "" {
+		a.AddressDetail = addr.Street
+		if addr.Number != "" {
+			a.AddressDetail += " " + addr.Number
+		}
+	}
+	a.City = withDefault(addr.Locality, unknown)
+	a.PostalCode = withDefault(addr.Code.String(), unknown)
+	if addr.Country != "" {
+		a.Country = addr.Country.String()
+	}
+	return a
+}
+
+func withDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func (b *ptBuilder) invoice(inv *bill.Invoice) *Invoice {
+	date := inv.IssueDate.String()
+	entry := systemEntryDate(inv)
+	doc := &Invoice{
+		InvoiceNo: invoiceNo(inv),
+		ATCUD:     placeholder,
+		DocumentStatus: &DocumentStatus{
+			InvoiceStatus:     documentStatus,
+			InvoiceStatusDate: entry,
+			SourceBilling:     sourceBilling(inv),
+			SourceID:          placeholder,
+		},
+		Hash:        placeholder,
+		HashControl: placeholder,
+		Period:      int(inv.IssueDate.Month),
+		InvoiceDate: date,
+		InvoiceType: invoiceType(inv),
+		SpecialRegimes: &SpecialRegimes{
+			SelfBillingIndicator:         noSpecialRegime,
+			CashVATSchemeIndicator:       noSpecialRegime,
+			ThirdPartiesBillingIndicator: noSpecialRegime,
+		},
+		SourceID:    placeholder,
+		SystemEntry: entry,
+		CustomerID:  b.customer(inv.Customer),
+		DocumentTotals: &DocumentTotals{
+			TaxPayable: inv.Totals.Tax.String(),
+			NetTotal:   inv.Totals.Total.String(),
+			GrossTotal: inv.Totals.TotalWithTax.String(),
+		},
+	}
+	for _, l := range inv.Lines {
+		doc.Line = append(doc.Line, b.line(inv, l))
+	}
+	return doc
+}
+
+func sourceBilling(inv *bill.Invoice) string {
+	if inv.Tax != nil {
+		if c := inv.Tax.Ext.Get(ptsaft.ExtKeySource); c != cbc.CodeEmpty {
+			return c.String()
+		}
+	}
+	return sourceProduced
+}
+
+func (b *ptBuilder) customer(p *org.Party) string {
+	id := customerID(p)
+	if b.customers[id] {
+		return id
+	}
+	b.customers[id] = true
+	c := &Customer{
+		CustomerID:           id,
+		AccountID:            unknown,
+		CustomerTaxID:        finalConsumerTaxID,
+		CompanyName:          finalConsumer,
+		BillingAddress:       newAddress(p),
+		SelfBillingIndicator: noSelfBilling,
+	}
+	if p != nil {
+		c.CompanyName = withDefault(p.Name, finalConsumer)
+		if p.TaxID != nil && p.TaxID.Code != cbc.CodeEmpty {
+			c.CustomerTaxID = p.TaxID.Code.String()
+		}
+	}
+	b.af.MasterFiles.Customer = append(b.af.MasterFiles.Customer, c)
+	return id
+}
+
+func (b *ptBuilder) product(item *org.Item) string {
+	code := productCode(item)
+	if b.products[code] {
+		return code
+	}
+	b.products[code] = true
+	b.af.MasterFiles.Product = append(b.af.MasterFiles.Product, &Product{
+		ProductType:        productType(item),
+		ProductCode:        code,
+		ProductDescription: item.Name,
+		ProductNumberCode:  code,
+	})
+	return code
+}
+
+func (b *ptBuilder) taxEntry(ti *taxInfo) {
+	key := ti.country + ":" + ti.code + ":" + ti.percent
+	if b.taxes[key] {
+		return
+	}
+	b.taxes[key] = true
+	mf := b.af.MasterFiles
+	if mf.TaxTable == nil {
+		mf.TaxTable = new(TaxTable)
+	}
+	mf.TaxTable.TaxTableEntry = append(mf.TaxTable.TaxTableEntry, &TaxTableEntry{
+		TaxType:          taxTypeVAT,
+		TaxCountryRegion: ti.country,
+		TaxCode:          ti.code,
+		Description:      ti.description,
+		TaxPercentage:    ti.percent,
+	})
+}
+
+func (b *ptBuilder) line(inv *bill.Invoice, l *bill.Line) *Line {
+	line := &Line{
+		LineNumber:         l.Index,
+		ProductCode:        b.product(l.Item),
+		ProductDescription: l.Item.Name,
+		Quantity:           l.Quantity.String(),
+		UnitOfMeasure:      withDefault(string(l.Item.Unit), string(org.UnitOne)),
+		UnitPrice:          unitPrice(l).String(),
+		TaxPointDate:       inv.IssueDate.String(),
+		Description:        l.Item.Name,
+	}
+	if isCredit(inv) {
+		line.DebitAmount = l.Total.String()
+		if len(inv.Preceding) > 0 {
+			line.References = &Reference{
+				Reference: precedingNo(inv.Preceding[0]),
+				Reason:    inv.Preceding[0].Reason,
+			}
+		}
+	} else {
+		line.CreditAmount = l.Total.String()
+	}
+	if combo := l.Taxes.Get(tax.CategoryVAT); combo != nil {
+		ti := newTaxInfo(inv, combo, i18n.PT)
+		b.taxEntry(ti)
+		line.Tax = &LineTax{
+			TaxType:          taxTypeVAT,
+			TaxCountryRegion: ti.country,
+			TaxCode:          ti.code,
+			TaxPercentage:    ti.percent,
+		}
+		line.TaxExemptionCode = ti.exemptionCode
+		line.TaxExemptionReason = ti.exemptionReason
+	}
+	if d := l.Sum.Subtract(*l.Total); !d.IsZero() {
+		line.SettlementAmount = d.String()
+	}
+	return line
+}
+
+// unitPrice provides the price of a single unit after line discounts
+// and charges, as expected by the SAF-T.
+func unitPrice(l *bill.Line) num.Amount {
+	if l.Quantity.IsZero() {
+		return *l.Item.Price
+	}
+	return l.Total.Divide(l.Quantity).Rescale(l.Item.Price.Exp())
+}
+
+func precedingNo(ref *org.DocumentRef) string {
+	if ref.Series == cbc.CodeEmpty {
+		return ref.Code.String()
+	}
+	return ref.Series.String() + "/" + ref.Code.String()
+}
@@ -0,0 +1,351 @@
+// Package saft generates the sales invoices section of SAF-T (Standard
+// Audit File for Tax) reports from a set of GOBL invoices and credit notes
+// issued by a single supplier over a period.
+//
+// Two layouts are supported: the Portuguese SAF-T (PT) 1.04, which relies
+// on the extensions defined by the `pt-saft-v1` addon for document types,
+// tax rate codes, exemptions, and product types, and the OECD SAF-T 2.0
+// standard layout used as the base for most other national variants. Tax
+// descriptions are taken from the supplier's tax regime.
+//
+// The PT layout requires the document hashes and ATCUD codes issued by
+// certified software, which are not part of the invoice itself. These
+// default to "0" and may be updated directly in the resulting audit file.
+package saft
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ptsaft "github.com/invopop/gobl/addons/pt/saft"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/pt"
+	"github.com/invopop/gobl/tax"
+)
+
+// Layout identifies the SAF-T variant to generate.
+type Layout string
+
+// Supported layouts.
+const (
+	LayoutPT   Layout = "PT_1.04_01"
+	LayoutOECD Layout = "2.00"
+)
+
+// Namespaces used by each layout.
+const (
+	NamespacePT   = "urn:OECD:StandardAuditFile-Tax:PT_1.04_01"
+	NamespaceOECD = "urn:StandardAuditFile-Taxation-Financial:2.00"
+)
+
+// Codes used in both layouts.
+const (
+	unknown         = "Desconhecido"
+	finalConsumerID = "CF"
+	finalConsumer   = "Consumidor final"
+	defaultProduct  = "P"
+	dateTimeLayout  = "2006-01-02T15:04:05"
+	accountingBasis = "F"
+)
+
+// Software describes the invoicing application that produced the
+// documents.
+type Software struct {
+	// CompanyName of the software producer.
+	CompanyName string
+	// CompanyTaxID of the company that produced the software.
+	CompanyTaxID string
+	// CertificateNumber assigned by the tax agency, if any.
+	CertificateNumber string
+	// ProductID is the name of the software and its producer, usually
+	// in the format "Product/Company".
+	ProductID string
+	// ProductVersion of the software.
+	ProductVersion string
+}
+
+// Option is used to customize the report generated from the invoices.
+type Option func(*options)
+
+type options struct {
+	layout   Layout
+	period   *cal.Period
+	created  cal.Date
+	software Software
+}
+
+// WithLayout sets the SAF-T variant to generate, which defaults to
+// the Portuguese layout.
+func WithLayout(l Layout) Option {
+	return func(o *options) {
+		o.layout = l
+	}
+}
+
+// WithPeriod sets the period covered by the report, which otherwise
+// spans the issue dates of the invoices provided. Invoices issued outside
+// the period will be rejected.
+func WithPeriod(p cal.Period) Option {
+	return func(o *options) {
+		o.period = &p
+	}
+}
+
+// WithDateCreated overrides the creation date of the report, which
+// defaults to the current date.
+func WithDateCreated(d cal.Date) Option {
+	return func(o *options) {
+		o.created = d
+	}
+}
+
+// WithSoftware sets the details of the invoicing software.
+func WithSoftware(s Software) Option {
+	return func(o *options) {
+		o.software = s
+	}
+}
+
+var now = time.Now
+
+// ConvertInvoices is a convenience method to generate the SAF-T XML for
+// the calculated invoices using the layout defined in the options.
+func ConvertInvoices(invs []*bill.Invoice, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	if o.layout == LayoutOECD {
+		af, err := FromInvoicesOECD(invs, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return af.Bytes()
+	}
+	af, err := FromInvoicesPT(invs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return af.Bytes()
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		layout:  LayoutPT,
+		created: cal.DateOf(now()),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// report contains the details shared by all the invoices included in
+// a single audit file.
+type report struct {
+	supplier *org.Party
+	period   cal.Period
+	currency currency.Code
+	invoices []*bill.Invoice
+	created  cal.Date
+	software Software
+}
+
+// prepare checks the invoices can be included in a single report and
+// determines the period covered. Amounts are reported in the supplier
+// regime's currency, so invoices issued in other currencies will be
+// converted using their exchange rates.
+func prepare(invs []*bill.Invoice, o *options) (*report, error) {
+	if len(invs) == 0 {
+		return nil, fmt.Errorf("%w: no invoices", convert.ErrUnsupported)
+	}
+	r := &report{created: o.created, software: o.software}
+	for i, inv := range invs {
+		if inv == nil || inv.Totals == nil {
+			return nil, fmt.Errorf("invoice %d: %w", i, convert.ErrNotCalculated)
+		}
+		if inv.Supplier == nil || inv.Supplier.TaxID == nil {
+			return nil, fmt.Errorf("invoice %d: %w: supplier tax ID required", i, convert.ErrUnsupported)
+		}
+		if r.supplier == nil {
+			r.supplier = inv.Supplier
+			r.currency = tax.RegimeDefFor(inv.Supplier.TaxID.Country.Code()).GetCurrency()
+			if r.currency == currency.CodeEmpty {
+				r.currency = inv.Currency
+			}
+		} else if !sameIdentity(r.supplier.TaxID, inv.Supplier.TaxID) {
+			return nil, fmt.Errorf("invoice %d: %w: multiple suppliers", i, convert.ErrUnsupported)
+		}
+		if inv.Currency != r.currency {
+			ci, err := inv.ConvertInto(r.currency)
+			if err != nil {
+				return nil, fmt.Errorf("invoice %d: %w", i, err)
+			}
+			inv = ci
+		}
+		r.invoices = append(r.invoices, inv)
+		if o.period != nil {
+			if !inPeriod(o.period, inv.IssueDate) {
+				return nil, fmt.Errorf("invoice %d: issue date %s outside period", i, inv.IssueDate)
+			}
+			continue
+		}
+		if i == 0 || inv.IssueDate.Before(r.period.Start.Date) {
+			r.period.Start = inv.IssueDate
+		}
+		if i == 0 || inv.IssueDate.After(r.period.End.Date) {
+			r.period.End = inv.IssueDate
+		}
+	}
+	if o.period != nil {
+		r.period = *o.period
+	}
+	return r, nil
+}
+
+func inPeriod(p *cal.Period, d cal.Date) bool {
+	return !d.Before(p.Start.Date) && !d.After(p.End.Date)
+}
+
+func sameIdentity(a, b *tax.Identity) bool {
+	return a.Country == b.Country && a.Code == b.Code
+}
+
+func isCredit(inv *bill.Invoice) bool {
+	return inv.Type.In(bill.InvoiceTypeCreditNote)
+}
+
+// invoiceNo provides the document number, which in Portugal is expected
+// to include the document type and series, like "FT SERIES-A/123".
+func invoiceNo(inv *bill.Invoice) string {
+	if inv.Series == cbc.CodeEmpty {
+		return inv.Code.String()
+	}
+	return inv.Series.String() + "/" + inv.Code.String()
+}
+
+func invoiceType(inv *bill.Invoice) string {
+	if inv.Tax != nil {
+		if c := inv.Tax.Ext.Get(ptsaft.ExtKeyInvoiceType); c != cbc.CodeEmpty {
+			return c.String()
+		}
+	}
+	if isCredit(inv) {
+		return ptsaft.InvoiceTypeCreditNote.String()
+	}
+	if inv.Type.In(bill.InvoiceTypeDebitNote) {
+		return ptsaft.InvoiceTypeDebitNote.String()
+	}
+	return ptsaft.InvoiceTypeStandard.String()
+}
+
+func systemEntryDate(inv *bill.Invoice) string {
+	t := cal.MakeTime(0, 0, 0)
+	if inv.IssueTime != nil {
+		t = *inv.IssueTime
+	}
+	return inv.IssueDate.TimeIn(time.UTC).Add(
+		time.Duration(t.Hour)*time.Hour +
+			time.Duration(t.Minute)*time.Minute +
+			time.Duration(t.Second)*time.Second,
+	).Format(dateTimeLayout)
+}
+
+// customerID provides a stable identifier for the customer, using the
+// final consumer code when there are no details.
+func customerID(p *org.Party) string {
+	if p == nil {
+		return finalConsumerID
+	}
+	if p.TaxID != nil && p.TaxID.Code != cbc.CodeEmpty {
+		return p.TaxID.Country.String() + p.TaxID.Code.String()
+	}
+	if p.UUID != "" {
+		return p.UUID.String()
+	}
+	if p.Name != "" {
+		return strings.ToUpper(strings.Join(strings.Fields(p.Name), "-"))
+	}
+	return finalConsumerID
+}
+
+func productCode(item *org.Item) string {
+	if item.Ref != cbc.CodeEmpty {
+		return item.Ref.String()
+	}
+	return item.Name
+}
+
+func productType(item *org.Item) string {
+	if c := item.Ext.Get(ptsaft.ExtKeyProductType); c != cbc.CodeEmpty {
+		return c.String()
+	}
+	return defaultProduct
+}
+
+// taxInfo contains the details of a tax applied to a line.
+type taxInfo struct {
+	country         string
+	code            string
+	percent         string
+	description     string
+	exemptionCode   string
+	exemptionReason string
+}
+
+// newTaxInfo prepares the tax details from the combo, using the PT
+// addon's codes where available and the regime's rate definitions for
+// descriptions in the given language.
+func newTaxInfo(inv *bill.Invoice, combo *tax.Combo, lang i18n.Lang) *taxInfo {
+	ti := &taxInfo{
+		country: inv.Supplier.TaxID.Country.String(),
+		code:    combo.Ext.Get(ptsaft.ExtKeyTaxRate).String(),
+		percent: "0",
+	}
+	if r := combo.Ext.Get(pt.ExtKeyRegion); r != cbc.CodeEmpty {
+		ti.country = r.String()
+	}
+	if ti.code == "" {
+		ti.code = convert.TaxCategoryCode(combo.Category, combo.Key, combo.Ext).String()
+	}
+	if combo.Percent != nil {
+		ti.percent = combo.Percent.Amount().String()
+	}
+	ti.description = taxDescription(inv.Supplier.TaxID.Country, combo, lang)
+	if c := combo.Ext.Get(ptsaft.ExtKeyExemption); c != cbc.CodeEmpty {
+		ti.exemptionCode = c.String()
+		ti.exemptionReason = extName(ptsaft.ExtKeyExemption, c, lang)
+	}
+	return ti
+}
+
+func extName(key cbc.Key, code cbc.Code, lang i18n.Lang) string {
+	ed := tax.ExtensionForKey(key)
+	if ed == nil {
+		return code.String()
+	}
+	if cd := ed.CodeDef(code); cd != nil {
+		return cd.Name.In(lang)
+	}
+	return code.String()
+}
+
+func taxDescription(country l10n.TaxCountryCode, combo *tax.Combo, lang i18n.Lang) string {
+	rd := tax.RegimeDefFor(country.Code())
+	if rd == nil {
+		return combo.Category.String()
+	}
+	cd := rd.CategoryDef(combo.Category)
+	if cd == nil {
+		return combo.Category.String()
+	}
+	if r := cd.RateDef(combo.Key, combo.Rate); r != nil {
+		return r.Name.In(lang)
+	}
+	return cd.Name.In(lang)
+}
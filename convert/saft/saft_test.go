@@ -0,0 +1,276 @@
+package saft_test
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	ptsaft "github.com/invopop/gobl/addons/pt/saft"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/saft"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T, code cbc.Code, day int) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(ptsaft.V1),
+		Series:    "FT SERIES-A",
+		Code:      code,
+		Currency:  "EUR",
+		IssueDate: cal.MakeDate(2024, 3, day),
+		Supplier: &org.Party{
+			Name:  "Hotelzinho",
+			TaxID: &tax.Identity{Country: "PT", Code: "545259045"},
+			Addresses: []*org.Address{
+				{Street: "Rua do Hotelzinho", Code: "1000-000", Locality: "Lisboa"},
+			},
+		},
+		Customer: &org.Party{
+			Name:  "Maria Santos Silva",
+			TaxID: &tax.Identity{Country: "PT", Code: "514329874"},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(2, 0),
+				Item: &org.Item{
+					Ref:   "ROOM-2",
+					Name:  "Noite em quarto duplo",
+					Price: num.NewAmount(10000, 2),
+				},
+				Taxes: tax.Set{{Category: tax.CategoryVAT, Rate: tax.RateGeneral}},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Livro",
+					Price: num.NewAmount(2000, 2),
+				},
+				Taxes: tax.Set{
+					{
+						Category: tax.CategoryVAT,
+						Key:      tax.KeyExempt,
+						Ext:      tax.Extensions{ptsaft.ExtKeyExemption: "M07"},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func testCreditNote(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := testInvoice(t, "2", 20)
+	inv.Type = bill.InvoiceTypeCreditNote
+	inv.Series = "NC SERIES-A"
+	inv.Customer = nil
+	inv.Lines = inv.Lines[:1]
+	inv.Lines[0].Quantity = num.MakeAmount(1, 0)
+	inv.Preceding = []*org.DocumentRef{
+		{Series: "FT SERIES-A", Code: "1", IssueDate: cal.NewDate(2024, 3, 10), Reason: "Cancelamento"},
+	}
+	inv.Tax = nil
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func testInvoices(t *testing.T) []*bill.Invoice {
+	t.Helper()
+	return []*bill.Invoice{testInvoice(t, "1", 10), testCreditNote(t)}
+}
+
+func TestFromInvoicesPT(t *testing.T) {
+	opts := []saft.Option{
+		saft.WithDateCreated(cal.MakeDate(2024, 4, 1)),
+		saft.WithSoftware(saft.Software{
+			CompanyTaxID:      "508025338",
+			CertificateNumber: "9999",
+			ProductID:         "Test/Invopop",
+			ProductVersion:    "1.0",
+		}),
+	}
+
+	t.Run("header", func(t *testing.T) {
+		af, err := saft.FromInvoicesPT(testInvoices(t), opts...)
+		require.NoError(t, err)
+		h := af.Header
+		assert.Equal(t, "PT_1.04_01", h.AuditFileVersion)
+		assert.Equal(t, "545259045", h.TaxRegistrationNumber)
+		assert.Equal(t, "Hotelzinho", h.CompanyName)
+		assert.Equal(t, "Rua do Hotelzinho", h.CompanyAddress.AddressDetail)
+		assert.Equal(t, "PT", h.CompanyAddress.Country)
+		assert.Equal(t, 2024, h.FiscalYear)
+		assert.Equal(t, "2024-03-10", h.StartDate)
+		assert.Equal(t, "2024-03-20", h.EndDate)
+		assert.Equal(t, "EUR", h.CurrencyCode)
+		assert.Equal(t, "2024-04-01", h.DateCreated)
+		assert.Equal(t, "9999", h.SoftwareCertificate)
+	})
+
+	t.Run("master files", func(t *testing.T) {
+		af, err := saft.FromInvoicesPT(testInvoices(t), opts...)
+		require.NoError(t, err)
+		mf := af.MasterFiles
+		require.Len(t, mf.Customer, 2)
+		assert.Equal(t, "PT514329874", mf.Customer[0].CustomerID)
+		assert.Equal(t, "514329874", mf.Customer[0].CustomerTaxID)
+		assert.Equal(t, "CF", mf.Customer[1].CustomerID)
+		assert.Equal(t, "999999990", mf.Customer[1].CustomerTaxID)
+		assert.Equal(t, "Consumidor final", mf.Customer[1].CompanyName)
+
+		require.Len(t, mf.Product, 2)
+		assert.Equal(t, "ROOM-2", mf.Product[0].ProductCode)
+		assert.Equal(t, "Livro", mf.Product[1].ProductCode)
+
+		require.Len(t, mf.TaxTable.TaxTableEntry, 2)
+		e := mf.TaxTable.TaxTableEntry[0]
+		assert.Equal(t, "IVA", e.TaxType)
+		assert.Equal(t, "PT", e.TaxCountryRegion)
+		assert.Equal(t, "NOR", e.TaxCode)
+		assert.Equal(t, "23.0", e.TaxPercentage)
+		assert.NotEmpty(t, e.Description)
+		assert.Equal(t, "ISE", mf.TaxTable.TaxTableEntry[1].TaxCode)
+	})
+
+	t.Run("sales invoices", func(t *testing.T) {
+		af, err := saft.FromInvoicesPT(testInvoices(t), opts...)
+		require.NoError(t, err)
+		si := af.SourceDocuments.SalesInvoices
+		assert.Equal(t, 2, si.NumberOfEntries)
+		assert.Equal(t, "100.00", si.TotalDebit)
+		assert.Equal(t, "220.00", si.TotalCredit)
+
+		inv := si.Invoice[0]
+		assert.Equal(t, "FT SERIES-A/1", inv.InvoiceNo)
+		assert.Equal(t, "FT", inv.InvoiceType)
+		assert.Equal(t, 3, inv.Period)
+		assert.Equal(t, "2024-03-10T00:00:00", inv.SystemEntry)
+		assert.Equal(t, "PT514329874", inv.CustomerID)
+		require.Len(t, inv.Line, 2)
+		assert.Equal(t, "200.00", inv.Line[0].CreditAmount)
+		assert.Empty(t, inv.Line[0].DebitAmount)
+		assert.Equal(t, "100.00", inv.Line[0].UnitPrice)
+		assert.Equal(t, "M07", inv.Line[1].TaxExemptionCode)
+		assert.NotEmpty(t, inv.Line[1].TaxExemptionReason)
+		assert.Equal(t, "46.00", inv.DocumentTotals.TaxPayable)
+		assert.Equal(t, "220.00", inv.DocumentTotals.NetTotal)
+		assert.Equal(t, "266.00", inv.DocumentTotals.GrossTotal)
+
+		nc := si.Invoice[1]
+		assert.Equal(t, "NC SERIES-A/2", nc.InvoiceNo)
+		assert.Equal(t, "NC", nc.InvoiceType)
+		assert.Equal(t, "CF", nc.CustomerID)
+		assert.Equal(t, "100.00", nc.Line[0].DebitAmount)
+		assert.Equal(t, "FT SERIES-A/1", nc.Line[0].References.Reference)
+		assert.Equal(t, "Cancelamento", nc.Line[0].References.Reason)
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		data, err := saft.ConvertInvoices(testInvoices(t), opts...)
+		require.NoError(t, err)
+		out := string(data)
+		assert.Contains(t, out, `<AuditFile xmlns="urn:OECD:StandardAuditFile-Tax:PT_1.04_01">`)
+		assert.Contains(t, out, "<InvoiceNo>FT SERIES-A/1</InvoiceNo>")
+		assert.Contains(t, out, "<TaxExemptionCode>M07</TaxExemptionCode>")
+	})
+}
+
+func TestFromInvoicesOECD(t *testing.T) {
+	opts := []saft.Option{
+		saft.WithLayout(saft.LayoutOECD),
+		saft.WithPeriod(cal.Period{
+			Start: cal.MakeDate(2024, 3, 1),
+			End:   cal.MakeDate(2024, 3, 31),
+		}),
+		saft.WithDateCreated(cal.MakeDate(2024, 4, 1)),
+	}
+
+	t.Run("document", func(t *testing.T) {
+		af, err := saft.FromInvoicesOECD(testInvoices(t), opts...)
+		require.NoError(t, err)
+		h := af.Header
+		assert.Equal(t, "2.00", h.AuditFileVersion)
+		assert.Equal(t, "PT", h.AuditFileCountry)
+		assert.Equal(t, "PT545259045", h.Company.TaxRegistration.TaxRegistrationNumber)
+		assert.Equal(t, "2024-03-01", h.SelectionCriteria.SelectionStartDate)
+		assert.Equal(t, "2024-03-31", h.SelectionCriteria.SelectionEndDate)
+
+		require.Len(t, af.MasterFiles.Customers.Customer, 2)
+		require.Len(t, af.MasterFiles.TaxTable.TaxTableEntry, 1)
+		e := af.MasterFiles.TaxTable.TaxTableEntry[0]
+		assert.Equal(t, "VAT", e.TaxType)
+		assert.Len(t, e.TaxCodeDetails, 2)
+
+		si := af.SourceDocuments.SalesInvoices
+		assert.Equal(t, "100.00", si.TotalDebit)
+		assert.Equal(t, "220.00", si.TotalCredit)
+		inv := si.Invoice[0]
+		assert.Equal(t, 2024, inv.PeriodYear)
+		l := inv.Line[0]
+		assert.Equal(t, "C", l.DebitCreditIndicator)
+		assert.Equal(t, "200.00", l.InvoiceLineAmount.Amount)
+		require.Len(t, l.TaxInformation, 1)
+		assert.Equal(t, "200.00", l.TaxInformation[0].TaxBase)
+		assert.Equal(t, "46.00", l.TaxInformation[0].TaxAmount.Amount)
+		assert.Len(t, inv.DocumentTotals.TaxInformationTotals, 2)
+		assert.Equal(t, "D", si.Invoice[1].Line[0].DebitCreditIndicator)
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		data, err := saft.ConvertInvoices(testInvoices(t), opts...)
+		require.NoError(t, err)
+		assert.True(t, strings.Contains(string(data), `<AuditFile xmlns="urn:StandardAuditFile-Taxation-Financial:2.00">`))
+	})
+}
+
+func TestFromInvoicesErrors(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, err := saft.ConvertInvoices(nil)
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		inv := testInvoice(t, "1", 10)
+		inv.Totals = nil
+		_, err := saft.ConvertInvoices([]*bill.Invoice{inv})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+	})
+	t.Run("multiple suppliers", func(t *testing.T) {
+		inv := testInvoice(t, "2", 12)
+		inv.Supplier.TaxID.Code = "503504564"
+		_, err := saft.ConvertInvoices([]*bill.Invoice{testInvoice(t, "1", 10), inv})
+		assert.ErrorIs(t, err, convert.ErrUnsupported)
+		assert.ErrorContains(t, err, "invoice 1: unsupported: multiple suppliers")
+	})
+	t.Run("outside period", func(t *testing.T) {
+		_, err := saft.ConvertInvoices(testInvoices(t), saft.WithPeriod(cal.Period{
+			Start: cal.MakeDate(2024, 3, 1),
+			End:   cal.MakeDate(2024, 3, 15),
+		}))
+		assert.ErrorContains(t, err, "invoice 1: issue date 2024-03-20 outside period")
+	})
+	t.Run("currency conversion", func(t *testing.T) {
+		inv := testInvoice(t, "1", 10)
+		inv.Currency = "USD"
+		inv.ExchangeRates = []*currency.ExchangeRate{
+			{From: "USD", To: "EUR", Amount: num.MakeAmount(5, 1)},
+		}
+		require.NoError(t, inv.Calculate())
+		af, err := saft.FromInvoicesPT([]*bill.Invoice{inv})
+		require.NoError(t, err)
+		assert.Equal(t, "110.00", af.SourceDocuments.SalesInvoices.TotalCredit)
+
+		inv.ExchangeRates = nil
+		_, err = saft.FromInvoicesPT([]*bill.Invoice{inv})
+		assert.ErrorContains(t, err, "no exchange rate defined")
+	})
+}
@@ -0,0 +1,23 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/invopop/gobl/bill"
+)
+
+// ParseCSV imports lines from CSV data, where the first record contains
+// the column headers. Records may have different numbers of fields.
+func ParseCSV(r io.Reader, opts ...Option) ([]*bill.Line, error) {
+	o := newOptions(opts)
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing csv: %w", err)
+	}
+	return ParseRows(rows, opts...)
+}
@@ -0,0 +1,31 @@
+package tabular_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl/convert/tabular"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("semicolons", func(t *testing.T) {
+		data := "\ufeffName;Price;Quantity\n\"Widget; large\";\"1.000,50\";2\nGadget;5\n"
+		lines, err := tabular.ParseCSV(strings.NewReader(data),
+			tabular.WithComma(';'),
+			tabular.WithDecimalMark(','),
+		)
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "Widget; large", lines[0].Item.Name)
+		assert.Equal(t, "1000.50", lines[0].Item.Price.String())
+		assert.Equal(t, "2", lines[0].Quantity.String())
+		assert.Equal(t, "1", lines[1].Quantity.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := tabular.ParseCSV(strings.NewReader("name,price\n\"bad,1\n"))
+		assert.ErrorContains(t, err, "parsing csv")
+	})
+}
@@ -0,0 +1,459 @@
+// Package tabular imports invoice lines from tabular data such as CSV
+// files or XLSX spreadsheets exported from legacy ERPs.
+//
+// The first row of the data is expected to contain the column headers,
+// which are mapped to line fields using a configurable Mapping. Amounts,
+// percentages, and dates are parsed with the decimal mark and layout
+// provided in the options, units may be provided using GOBL keys, UN/ECE
+// codes, or names, and tax values are resolved to the category's keys,
+// rate keys, or percentages.
+//
+// Rows that cannot be parsed are reported individually in an Errors
+// list, alongside the lines imported from all other rows, so that
+// problems can be fixed in a single pass.
+package tabular
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Field identifies a line property that may be imported from a column.
+type Field string
+
+// Supported fields.
+const (
+	FieldQuantity    Field = "quantity"
+	FieldRef         Field = "ref"
+	FieldName        Field = "name"
+	FieldDescription Field = "description"
+	FieldPrice       Field = "price"
+	FieldUnit        Field = "unit"
+	FieldDiscount    Field = "discount"
+	FieldTaxCategory Field = "tax_category"
+	FieldTax         Field = "tax"
+	FieldPeriodStart Field = "period_start"
+	FieldPeriodEnd   Field = "period_end"
+	FieldOrder       Field = "order"
+	FieldCost        Field = "cost"
+	FieldNote        Field = "note"
+)
+
+// DefaultDateLayout is used to parse dates unless otherwise defined.
+const DefaultDateLayout = "2006-01-02"
+
+// Mapping defines the column header used for each field. Headers are
+// matched ignoring case and surrounding spaces.
+type Mapping map[Field]string
+
+// DefaultMapping expects the column headers to match the field names.
+var DefaultMapping = Mapping{
+	FieldQuantity:    string(FieldQuantity),
+	FieldRef:         string(FieldRef),
+	FieldName:        string(FieldName),
+	FieldDescription: string(FieldDescription),
+	FieldPrice:       string(FieldPrice),
+	FieldUnit:        string(FieldUnit),
+	FieldDiscount:    string(FieldDiscount),
+	FieldTaxCategory: string(FieldTaxCategory),
+	FieldTax:         string(FieldTax),
+	FieldPeriodStart: string(FieldPeriodStart),
+	FieldPeriodEnd:   string(FieldPeriodEnd),
+	FieldOrder:       string(FieldOrder),
+	FieldCost:        string(FieldCost),
+	FieldNote:        string(FieldNote),
+}
+
+// ErrMissingColumn is returned when a required column is not present
+// in the headers.
+var ErrMissingColumn = errors.New("missing column")
+
+// RowError describes a problem found while importing a single row.
+type RowError struct {
+	// Row number in the source data, starting from 1 for the header row
+	// so that it matches the numbering used by spreadsheets.
+	Row int
+	// Field that could not be imported, if any.
+	Field Field
+	// Err contains the underlying problem.
+	Err error
+}
+
+// Error provides a description of the row error.
+func (e *RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d: %s: %s", e.Row, e.Field, e.Err)
+}
+
+// Unwrap provides the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Errors contains the list of problems found while importing rows.
+type Errors []*RowError
+
+// Error joins the descriptions of all the row errors.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, re := range e {
+		msgs[i] = re.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Option is used to customize how rows are imported.
+type Option func(*options)
+
+type options struct {
+	mapping     Mapping
+	comma       rune
+	decimalMark rune
+	dateLayout  string
+	category    cbc.Code
+}
+
+// WithMapping defines the column headers to use for each field, replacing
+// the default mapping. Fields not included will not be imported.
+func WithMapping(m Mapping) Option {
+	return func(o *options) {
+		o.mapping = m
+	}
+}
+
+// WithComma sets the field delimiter used in CSV data, which defaults to
+// a comma.
+func WithComma(r rune) Option {
+	return func(o *options) {
+		o.comma = r
+	}
+}
+
+// WithDecimalMark sets the character used to separate decimals in
+// amounts, which defaults to a point. Any other periods, commas, or
+// spaces will be considered thousands separators and removed.
+func WithDecimalMark(r rune) Option {
+	return func(o *options) {
+		o.decimalMark = r
+	}
+}
+
+// WithDateLayout sets the Go time layout used to parse dates.
+func WithDateLayout(layout string) Option {
+	return func(o *options) {
+		o.dateLayout = layout
+	}
+}
+
+// WithTaxCategory sets the tax category used for rows that do not define
+// one, which defaults to VAT.
+func WithTaxCategory(code cbc.Code) Option {
+	return func(o *options) {
+		o.category = code
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		mapping:     DefaultMapping,
+		comma:       ',',
+		decimalMark: '.',
+		dateLayout:  DefaultDateLayout,
+		category:    tax.CategoryVAT,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ParseRows imports lines from rows of cells, where the first row
+// contains the column headers. Empty rows are ignored. If any row cannot
+// be imported, the lines for the remaining rows will be returned along
+// with an Errors list.
+func ParseRows(rows [][]string, opts ...Option) ([]*bill.Line, error) {
+	o := newOptions(opts)
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no header row", ErrMissingColumn)
+	}
+	cols, err := o.columns(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	var lines []*bill.Line
+	var errs Errors
+	for i, row := range rows[1:] {
+		if emptyRow(row) {
+			continue
+		}
+		r := &reader{opts: o, cols: cols, cells: row, row: i + 2}
+		line := r.line()
+		if len(r.errs) > 0 {
+			errs = append(errs, r.errs...)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(errs) > 0 {
+		return lines, errs
+	}
+	return lines, nil
+}
+
+// columns determines the position of each mapped field in the headers,
+// ensuring the name and price are present.
+func (o *options) columns(headers []string) (map[Field]int, error) {
+	index := make(map[string]int, len(headers))
+	for i, h := range headers {
+		index[normalizeHeader(h)] = i
+	}
+	cols := make(map[Field]int)
+	for f, h := range o.mapping {
+		if i, ok := index[normalizeHeader(h)]; ok {
+			cols[f] = i
+		}
+	}
+	for _, f := range []Field{FieldName, FieldPrice} {
+		if _, ok := cols[f]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, f)
+		}
+	}
+	return cols, nil
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func emptyRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// reader imports a single row, collecting the errors of each field.
+type reader struct {
+	opts  *options
+	cols  map[Field]int
+	cells []string
+	row   int
+	errs  Errors
+}
+
+func (r *reader) get(f Field) string {
+	i, ok := r.cols[f]
+	if !ok || i >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[i])
+}
+
+func (r *reader) fail(f Field, err error) {
+	r.errs = append(r.errs, &RowError{Row: r.row, Field: f, Err: err})
+}
+
+func (r *reader) line() *bill.Line {
+	line := &bill.Line{
+		Quantity: num.MakeAmount(1, 0),
+		Order:    cbc.Code(r.get(FieldOrder)),
+		Cost:     cbc.Code(r.get(FieldCost)),
+		Item: &org.Item{
+			Ref:         cbc.Code(r.get(FieldRef)),
+			Name:        r.get(FieldName),
+			Description: r.get(FieldDescription),
+		},
+	}
+	if line.Item.Name == "" {
+		r.fail(FieldName, errors.New("required"))
+	}
+	if s := r.get(FieldQuantity); s != "" {
+		if a, err := r.amount(s); err != nil {
+			r.fail(FieldQuantity, err)
+		} else {
+			line.Quantity = a
+		}
+	}
+	if a, err := r.amount(r.get(FieldPrice)); err != nil {
+		r.fail(FieldPrice, err)
+	} else {
+		line.Item.Price = &a
+	}
+	if s := r.get(FieldUnit); s != "" {
+		if u, err := parseUnit(s); err != nil {
+			r.fail(FieldUnit, err)
+		} else {
+			line.Item.Unit = u
+		}
+	}
+	if s := r.get(FieldDiscount); s != "" {
+		if d, err := r.discount(s); err != nil {
+			r.fail(FieldDiscount, err)
+		} else {
+			line.Discounts = []*bill.LineDiscount{d}
+		}
+	}
+	if s := r.get(FieldTax); s != "" {
+		if c, err := r.combo(s); err != nil {
+			r.fail(FieldTax, err)
+		} else {
+			line.Taxes = tax.Set{c}
+		}
+	}
+	line.Period = r.period()
+	if s := r.get(FieldNote); s != "" {
+		line.Notes = []*org.Note{{Text: s}}
+	}
+	return line
+}
+
+// amount parses the number after removing thousands separators.
+func (r *reader) amount(s string) (num.Amount, error) {
+	if s == "" {
+		return num.AmountZero, errors.New("required")
+	}
+	clean := strings.Map(func(c rune) rune {
+		switch {
+		case c == r.opts.decimalMark:
+			return '.'
+		case c == '.' || c == ',' || c == ' ' || c == '\u00a0' || c == '\'':
+			return -1
+		}
+		return c
+	}, s)
+	a, err := num.AmountFromString(clean)
+	if err != nil {
+		return a, fmt.Errorf("invalid amount '%s'", s)
+	}
+	return a, nil
+}
+
+func (r *reader) percent(s string) (num.Percentage, error) {
+	a, err := r.amount(strings.TrimSpace(strings.TrimSuffix(s, "%")))
+	if err != nil {
+		return num.Percentage{}, fmt.Errorf("invalid percent '%s'", s)
+	}
+	return num.MakePercentage(a.Value(), a.Exp()+2), nil
+}
+
+// discount parses values ending with a percent sign as a rate, and any
+// other value as a fixed amount.
+func (r *reader) discount(s string) (*bill.LineDiscount, error) {
+	if strings.HasSuffix(s, "%") {
+		p, err := r.percent(s)
+		if err != nil {
+			return nil, err
+		}
+		return &bill.LineDiscount{Percent: &p}, nil
+	}
+	a, err := r.amount(s)
+	if err != nil {
+		return nil, err
+	}
+	return &bill.LineDiscount{Amount: a}, nil
+}
+
+// combo resolves the tax value as one of the category's keys, a
+// percentage, or a rate key that will be checked by the regime when the
+// invoice is calculated. The standard key is treated as a rate, as it
+// is implied by rates and normalized to the general rate.
+func (r *reader) combo(s string) (*tax.Combo, error) {
+	c := &tax.Combo{Category: r.opts.category}
+	if cat := r.get(FieldTaxCategory); cat != "" {
+		c.Category = cbc.Code(strings.ToUpper(cat))
+	}
+	if strings.HasSuffix(s, "%") || isNumeric(s) {
+		p, err := r.percent(s)
+		if err != nil {
+			return nil, err
+		}
+		c.Percent = &p
+		return c, nil
+	}
+	k := cbc.Key(strings.ToLower(s))
+	if err := k.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tax key '%s'", s)
+	}
+	if cd := tax.Category(c.Category); cd != nil && k != tax.KeyStandard {
+		for _, kd := range cd.Keys {
+			if kd.Key == k {
+				c.Key = k
+				return c, nil
+			}
+		}
+	}
+	c.Rate = k
+	return c, nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' && c != ',' {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *reader) period() *cal.Period {
+	start := r.date(FieldPeriodStart)
+	end := r.date(FieldPeriodEnd)
+	if start == nil && end == nil {
+		return nil
+	}
+	p := new(cal.Period)
+	if start != nil {
+		p.Start = *start
+	}
+	if end != nil {
+		p.End = *end
+	}
+	return p
+}
+
+func (r *reader) date(f Field) *cal.Date {
+	s := r.get(f)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(r.opts.dateLayout, s)
+	if err != nil {
+		if d, ok := serialDate(s); ok {
+			return &d
+		}
+		r.fail(f, fmt.Errorf("invalid date '%s'", s))
+		return nil
+	}
+	d := cal.DateOf(t)
+	return &d
+}
+
+// parseUnit resolves the unit from a GOBL key, a UN/ECE code, or the
+// unit's name.
+func parseUnit(s string) (org.Unit, error) {
+	for _, d := range org.UnitDefinitions {
+		if strings.EqualFold(string(d.Unit), s) ||
+			d.UNECE.String() == strings.ToUpper(s) ||
+			strings.EqualFold(d.Name, s) {
+			return d.Unit, nil
+		}
+	}
+	u := org.Unit(s)
+	if err := u.Validate(); err != nil {
+		return org.UnitEmpty, fmt.Errorf("invalid unit '%s'", s)
+	}
+	return u, nil
+}
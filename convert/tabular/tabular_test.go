@@ -0,0 +1,137 @@
+package tabular_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert/tabular"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRows(t *testing.T) {
+	t.Run("all fields", func(t *testing.T) {
+		rows := [][]string{
+			{"Ref", "Name", "Description", "Quantity", "Price", "Unit", "Discount", "Tax", "Period_Start", "Period_End", "Order", "Cost", "Note"},
+			{"SKU-1", "Widget", "Blue widget", "10", "12.50", "KGM", "10%", "standard", "2024-01-01", "2024-01-31", "PO-1", "C-1", "Fragile"},
+			{},
+			{"", "Consulting", "", "2", "1,200.00", "hours", "50", "21%", "", "", "", "", ""},
+			{"", "Books", "", "", "20", "Items", "", "exempt", "", "", "", "", ""},
+		}
+		lines, err := tabular.ParseRows(rows)
+		require.NoError(t, err)
+		require.Len(t, lines, 3)
+
+		l := lines[0]
+		assert.Equal(t, cbc.Code("SKU-1"), l.Item.Ref)
+		assert.Equal(t, "Widget", l.Item.Name)
+		assert.Equal(t, "Blue widget", l.Item.Description)
+		assert.Equal(t, "10", l.Quantity.String())
+		assert.Equal(t, "12.50", l.Item.Price.String())
+		assert.Equal(t, org.UnitKilogram, l.Item.Unit)
+		assert.Equal(t, "10%", l.Discounts[0].Percent.String())
+		assert.Equal(t, tax.CategoryVAT, l.Taxes[0].Category)
+		assert.Equal(t, tax.KeyStandard, l.Taxes[0].Rate)
+		assert.Equal(t, cal.MakeDate(2024, 1, 1), l.Period.Start)
+		assert.Equal(t, cal.MakeDate(2024, 1, 31), l.Period.End)
+		assert.Equal(t, cbc.Code("PO-1"), l.Order)
+		assert.Equal(t, cbc.Code("C-1"), l.Cost)
+		assert.Equal(t, "Fragile", l.Notes[0].Text)
+
+		l = lines[1]
+		assert.Equal(t, "1200.00", l.Item.Price.String())
+		assert.Equal(t, org.UnitHour, l.Item.Unit)
+		assert.Equal(t, "50", l.Discounts[0].Amount.String())
+		assert.Equal(t, "21%", l.Taxes[0].Percent.String())
+		assert.Nil(t, l.Period)
+
+		l = lines[2]
+		assert.Equal(t, "1", l.Quantity.String())
+		assert.Equal(t, org.UnitItem, l.Item.Unit)
+		assert.Equal(t, tax.KeyExempt, l.Taxes[0].Key)
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		rows := [][]string{
+			{"name", "price", "quantity", "unit", "period_start"},
+			{"Valid", "10", "1", "", ""},
+			{"", "abc", "1", "", ""},
+			{"Bad unit", "10", "x", "not a unit!", "01/02/2024"},
+		}
+		lines, err := tabular.ParseRows(rows)
+		require.Len(t, lines, 1)
+		var errs tabular.Errors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 5)
+		assert.Equal(t, 3, errs[0].Row)
+		assert.Equal(t, tabular.FieldName, errs[0].Field)
+		assert.Equal(t, "row 3: price: invalid amount 'abc'", errs[1].Error())
+		assert.Equal(t, 4, errs[2].Row)
+		assert.Equal(t, tabular.FieldQuantity, errs[2].Field)
+		assert.Equal(t, tabular.FieldUnit, errs[3].Field)
+		assert.Equal(t, tabular.FieldPeriodStart, errs[4].Field)
+		assert.Contains(t, err.Error(), "row 3: name: required; row 3: price")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		rows := [][]string{
+			{"Artikel", "Preis", "Menge", "Steuer", "Kategorie", "Von"},
+			{"Schraube", "1.234,5", "3", "19", "", "01.02.2024"},
+			{"Gebühr", "10", "1", "10", "gst", ""},
+		}
+		lines, err := tabular.ParseRows(rows,
+			tabular.WithMapping(tabular.Mapping{
+				tabular.FieldName:        "artikel",
+				tabular.FieldPrice:       "PREIS",
+				tabular.FieldQuantity:    "Menge",
+				tabular.FieldTax:         "Steuer",
+				tabular.FieldTaxCategory: "Kategorie",
+				tabular.FieldPeriodStart: "Von",
+			}),
+			tabular.WithDecimalMark(','),
+			tabular.WithDateLayout("02.01.2006"),
+			tabular.WithTaxCategory("IVA"),
+		)
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "1234.5", lines[0].Item.Price.String())
+		assert.Equal(t, cbc.Code("IVA"), lines[0].Taxes[0].Category)
+		assert.Equal(t, "19%", lines[0].Taxes[0].Percent.String())
+		assert.Equal(t, cal.MakeDate(2024, 2, 1), lines[0].Period.Start)
+		assert.Equal(t, cbc.Code("GST"), lines[1].Taxes[0].Category)
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		_, err := tabular.ParseRows(nil)
+		assert.ErrorIs(t, err, tabular.ErrMissingColumn)
+		_, err = tabular.ParseRows([][]string{{"name", "quantity"}})
+		assert.ErrorIs(t, err, tabular.ErrMissingColumn)
+		assert.ErrorContains(t, err, "missing column: price")
+	})
+
+	t.Run("calculate", func(t *testing.T) {
+		lines, err := tabular.ParseRows([][]string{
+			{"name", "price", "quantity", "tax"},
+			{"Widget", "10.00", "3", "Standard"},
+		})
+		require.NoError(t, err)
+		inv := &bill.Invoice{
+			Currency:  "EUR",
+			IssueDate: cal.MakeDate(2024, 1, 1),
+			Supplier: &org.Party{
+				Name:  "Test Supplier",
+				TaxID: &tax.Identity{Country: "ES", Code: "B98602642"},
+			},
+			Lines: lines,
+		}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, tax.RateGeneral, lines[0].Taxes[0].Rate)
+		assert.Equal(t, num.MakeAmount(3630, 2), inv.Totals.Payable)
+	})
+}
@@ -0,0 +1,236 @@
+package tabular
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+)
+
+// Paths and types used inside XLSX packages.
+const (
+	xlsxWorkbook      = "xl/workbook.xml"
+	xlsxWorkbookRels  = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStrings = "xl/sharedStrings.xml"
+	xlsxBaseDir       = "xl"
+	xlsxCellShared    = "s"
+	xlsxCellInline    = "inlineStr"
+	xlsxCellBool      = "b"
+)
+
+// excelEpoch is the date used as day zero by spreadsheet serial dates,
+// accounting for the 1900 leap year bug.
+var excelEpoch = cal.MakeDate(1899, 12, 30)
+
+// ParseXLSX imports lines from the first worksheet of an XLSX
+// spreadsheet, where the first row contains the column headers. Only
+// cell values are read, so formulas must have been calculated and
+// saved by the application that produced the file.
+func ParseXLSX(r io.ReaderAt, size int64, opts ...Option) ([]*bill.Line, error) {
+	rows, err := ReadXLSX(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRows(rows, opts...)
+}
+
+// ReadXLSX provides the cell values of the first worksheet in an XLSX
+// spreadsheet, with empty strings for any missing cells.
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("parsing xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, fmt.Errorf("parsing xlsx: %w", err)
+	}
+	var sst xlsxSST
+	if f, ok := files[xlsxSharedStrings]; ok {
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, fmt.Errorf("parsing xlsx: %w", err)
+		}
+	}
+	var ws xlsxWorksheet
+	if err := decodeZipXML(sheet, &ws); err != nil {
+		return nil, fmt.Errorf("parsing xlsx: %w", err)
+	}
+	var rows [][]string
+	for _, row := range ws.Rows {
+		n := row.Number
+		if n == 0 {
+			n = len(rows) + 1
+		}
+		for len(rows) < n {
+			rows = append(rows, nil)
+		}
+		cells := rows[n-1]
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			v, err := c.value(sst)
+			if err != nil {
+				return nil, fmt.Errorf("parsing xlsx: cell %s: %w", c.Ref, err)
+			}
+			cells[col] = v
+		}
+		rows[n-1] = cells
+	}
+	return rows, nil
+}
+
+// firstSheet finds the first worksheet defined in the workbook.
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	var wb xlsxWorkbookDoc
+	var rels xlsxRelationships
+	f, ok := files[xlsxWorkbook]
+	if !ok {
+		return nil, errors.New("missing workbook")
+	}
+	if err := decodeZipXML(f, &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, errors.New("no worksheets")
+	}
+	if f, ok := files[xlsxWorkbookRels]; ok {
+		if err := decodeZipXML(f, &rels); err != nil {
+			return nil, err
+		}
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RelID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(xlsxBaseDir, target)
+		}
+		if f, ok := files[target]; ok {
+			return f, nil
+		}
+	}
+	return nil, errors.New("missing worksheet")
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close() //nolint:errcheck
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// columnIndex converts a cell reference like "AB12" into the zero based
+// index of its column.
+func columnIndex(ref string) int {
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		n = n*26 + int(c-'A'+1)
+	}
+	return n - 1
+}
+
+// serialDate converts spreadsheet serial dates, which are used when the
+// cell is formatted as a date, into a calendar date.
+func serialDate(s string) (cal.Date, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 1 {
+		return cal.Date{}, false
+	}
+	return excelEpoch.Add(0, 0, int(f)), true
+}
+
+type xlsxWorkbookDoc struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSST struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText contains either plain text or a list of rich text runs.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int        `xml:"r,attr"`
+		Cells  []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxCell struct {
+	Ref    string    `xml:"r,attr"`
+	Type   string    `xml:"t,attr"`
+	Value  string    `xml:"v"`
+	Inline *xlsxText `xml:"is"`
+}
+
+func (c xlsxCell) value(sst xlsxSST) (string, error) {
+	switch c.Type {
+	case xlsxCellShared:
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(sst.Items) {
+			return "", fmt.Errorf("invalid shared string '%s'", c.Value)
+		}
+		return sst.Items[i].String(), nil
+	case xlsxCellInline:
+		if c.Inline == nil {
+			return "", nil
+		}
+		return c.Inline.String(), nil
+	case xlsxCellBool:
+		if c.Value == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return c.Value, nil
+}
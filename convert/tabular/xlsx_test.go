@@ -0,0 +1,83 @@
+package tabular_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/convert/tabular"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testXLSX(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+var testWorkbook = map[string]string{
+	"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Lines" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+	"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId2" Target="sharedStrings.xml"/>
+  <Relationship Id="rId1" Target="worksheets/data.xml"/>
+</Relationships>`,
+	"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>name</t></si>
+  <si><t>price</t></si>
+  <si><t>period_start</t></si>
+  <si><r><t>Blue </t></r><r><t>widget</t></r></si>
+</sst>`,
+	"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>
+    <row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3"><v>12.5</v></c><c r="D3"><v>45292</v></c></row>
+    <row r="4"><c r="A4" t="inlineStr"><is><t>Gadget</t></is></c><c r="B4"><v>3</v></c></row>
+  </sheetData>
+</worksheet>`,
+}
+
+func TestReadXLSX(t *testing.T) {
+	r := testXLSX(t, testWorkbook)
+	rows, err := tabular.ReadXLSX(r, r.Size())
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, []string{"name", "price", "", "period_start"}, rows[0])
+	assert.Nil(t, rows[1])
+	assert.Equal(t, []string{"Blue widget", "12.5", "", "45292"}, rows[2])
+	assert.Equal(t, []string{"Gadget", "3"}, rows[3])
+
+	t.Run("not a zip", func(t *testing.T) {
+		r := bytes.NewReader([]byte("name,price"))
+		_, err := tabular.ReadXLSX(r, r.Size())
+		assert.ErrorContains(t, err, "parsing xlsx")
+	})
+
+	t.Run("missing workbook", func(t *testing.T) {
+		r := testXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": "<worksheet/>"})
+		_, err := tabular.ReadXLSX(r, r.Size())
+		assert.ErrorContains(t, err, "parsing xlsx: missing workbook")
+	})
+}
+
+func TestParseXLSX(t *testing.T) {
+	r := testXLSX(t, testWorkbook)
+	lines, err := tabular.ParseXLSX(r, r.Size())
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Blue widget", lines[0].Item.Name)
+	assert.Equal(t, "12.5", lines[0].Item.Price.String())
+	assert.Equal(t, cal.MakeDate(2024, 1, 1), lines[0].Period.Start)
+	assert.Equal(t, "Gadget", lines[1].Item.Name)
+}
@@ -0,0 +1,282 @@
+package ubl
+
+import "encoding/xml"
+
+// Document represents a UBL 2.1 Invoice or CreditNote. Both document types
+// share the same structure, except for the root element, type code, and
+// line elements.
+type Document struct {
+	XMLName xml.Name
+	XMLNS   string `xml:"xmlns,attr,omitempty"`
+	CACNS   string `xml:"xmlns:cac,attr,omitempty"`
+	CBCNS   string `xml:"xmlns:cbc,attr,omitempty"`
+
+	CustomizationID      string             `xml:"cbc:CustomizationID,omitempty"`
+	ProfileID            string             `xml:"cbc:ProfileID,omitempty"`
+	ID                   string             `xml:"cbc:ID"`
+	UUID                 string             `xml:"cbc:UUID,omitempty"`
+	IssueDate            string             `xml:"cbc:IssueDate"`
+	IssueTime            string             `xml:"cbc:IssueTime,omitempty"`
+	DueDate              string             `xml:"cbc:DueDate,omitempty"`
+	InvoiceTypeCode      string             `xml:"cbc:InvoiceTypeCode,omitempty"`
+	CreditNoteTypeCode   string             `xml:"cbc:CreditNoteTypeCode,omitempty"`
+	Notes                []string           `xml:"cbc:Note,omitempty"`
+	TaxPointDate         string             `xml:"cbc:TaxPointDate,omitempty"`
+	DocumentCurrencyCode string             `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference       string             `xml:"cbc:BuyerReference,omitempty"`
+	InvoicePeriod        *Period            `xml:"cac:InvoicePeriod,omitempty"`
+	OrderReference       *OrderReference    `xml:"cac:OrderReference,omitempty"`
+	BillingReferences    []*BillingRef      `xml:"cac:BillingReference,omitempty"`
+	ContractReference    *DocumentRef       `xml:"cac:ContractDocumentReference,omitempty"`
+	ProjectReference     *DocumentRef       `xml:"cac:ProjectReference,omitempty"`
+	Supplier             *PartyRole         `xml:"cac:AccountingSupplierParty"`
+	Customer             *PartyRole         `xml:"cac:AccountingCustomerParty,omitempty"`
+	Delivery             *Delivery          `xml:"cac:Delivery,omitempty"`
+	PaymentMeans         []*PaymentMeans    `xml:"cac:PaymentMeans,omitempty"`
+	PaymentTerms         *PaymentTerms      `xml:"cac:PaymentTerms,omitempty"`
+	AllowanceCharges     []*AllowanceCharge `xml:"cac:AllowanceCharge,omitempty"`
+	TaxTotals            []*TaxTotal        `xml:"cac:TaxTotal,omitempty"`
+	LegalMonetaryTotal   *MonetaryTotal     `xml:"cac:LegalMonetaryTotal"`
+	InvoiceLines         []*Line            `xml:"cac:InvoiceLine,omitempty"`
+	CreditNoteLines      []*Line            `xml:"cac:CreditNoteLine,omitempty"`
+}
+
+// Period defines a start and end date.
+type Period struct {
+	StartDate string `xml:"cbc:StartDate,omitempty"`
+	EndDate   string `xml:"cbc:EndDate,omitempty"`
+}
+
+// OrderReference points to the purchase order.
+type OrderReference struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// BillingRef contains a reference to a preceding invoice.
+type BillingRef struct {
+	InvoiceDocumentReference *DocumentRef `xml:"cac:InvoiceDocumentReference"`
+}
+
+// DocumentRef is a generic reference to another document.
+type DocumentRef struct {
+	ID        string `xml:"cbc:ID"`
+	IssueDate string `xml:"cbc:IssueDate,omitempty"`
+}
+
+// PartyRole wraps the party acting as supplier or customer.
+type PartyRole struct {
+	Party *Party `xml:"cac:Party"`
+}
+
+// Party contains the details of a supplier, customer, or payee.
+type Party struct {
+	EndpointID      *Identifier       `xml:"cbc:EndpointID,omitempty"`
+	Identifications []*Identification `xml:"cac:PartyIdentification,omitempty"`
+	Name            *PartyName        `xml:"cac:PartyName,omitempty"`
+	PostalAddress   *Address          `xml:"cac:PostalAddress,omitempty"`
+	TaxSchemes      []*PartyTaxScheme `xml:"cac:PartyTaxScheme,omitempty"`
+	LegalEntity     *PartyLegalEntity `xml:"cac:PartyLegalEntity,omitempty"`
+	Contact         *Contact          `xml:"cac:Contact,omitempty"`
+}
+
+// Identifier is a code with an optional scheme.
+type Identifier struct {
+	SchemeID string `xml:"schemeID,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// Identification wraps a party identifier.
+type Identification struct {
+	ID *Identifier `xml:"cbc:ID"`
+}
+
+// PartyName contains the trading name of the party.
+type PartyName struct {
+	Name string `xml:"cbc:Name"`
+}
+
+// Address is a postal address.
+type Address struct {
+	StreetName           string   `xml:"cbc:StreetName,omitempty"`
+	AdditionalStreetName string   `xml:"cbc:AdditionalStreetName,omitempty"`
+	CityName             string   `xml:"cbc:CityName,omitempty"`
+	PostalZone           string   `xml:"cbc:PostalZone,omitempty"`
+	CountrySubentity     string   `xml:"cbc:CountrySubentity,omitempty"`
+	Country              *Country `xml:"cac:Country,omitempty"`
+}
+
+// Country contains the ISO 3166-1 alpha-2 country code.
+type Country struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+// PartyTaxScheme contains a tax identification number.
+type PartyTaxScheme struct {
+	CompanyID string     `xml:"cbc:CompanyID"`
+	TaxScheme *TaxScheme `xml:"cac:TaxScheme"`
+}
+
+// TaxScheme identifies the type of tax, normally `VAT`.
+type TaxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// PartyLegalEntity contains the registered name and company ID.
+type PartyLegalEntity struct {
+	RegistrationName string      `xml:"cbc:RegistrationName"`
+	CompanyID        *Identifier `xml:"cbc:CompanyID,omitempty"`
+}
+
+// Contact contains the party's contact details.
+type Contact struct {
+	Name           string `xml:"cbc:Name,omitempty"`
+	Telephone      string `xml:"cbc:Telephone,omitempty"`
+	ElectronicMail string `xml:"cbc:ElectronicMail,omitempty"`
+}
+
+// Delivery contains the delivery date and location.
+type Delivery struct {
+	ActualDeliveryDate string `xml:"cbc:ActualDeliveryDate,omitempty"`
+}
+
+// PaymentMeans describes how the payment should be made.
+type PaymentMeans struct {
+	PaymentMeansCode      string            `xml:"cbc:PaymentMeansCode"`
+	PaymentID             string            `xml:"cbc:PaymentID,omitempty"`
+	CardAccount           *CardAccount      `xml:"cac:CardAccount,omitempty"`
+	PayeeFinancialAccount *FinancialAccount `xml:"cac:PayeeFinancialAccount,omitempty"`
+	PaymentMandate        *PaymentMandate   `xml:"cac:PaymentMandate,omitempty"`
+}
+
+// CardAccount contains the card details.
+type CardAccount struct {
+	PrimaryAccountNumberID string `xml:"cbc:PrimaryAccountNumberID"`
+	NetworkID              string `xml:"cbc:NetworkID"`
+	HolderName             string `xml:"cbc:HolderName,omitempty"`
+}
+
+// FinancialAccount contains bank account details.
+type FinancialAccount struct {
+	ID                         string  `xml:"cbc:ID"`
+	Name                       string  `xml:"cbc:Name,omitempty"`
+	FinancialInstitutionBranch *Branch `xml:"cac:FinancialInstitutionBranch,omitempty"`
+}
+
+// Branch identifies the bank by its BIC.
+type Branch struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// PaymentMandate contains the direct debit mandate.
+type PaymentMandate struct {
+	ID                    string            `xml:"cbc:ID,omitempty"`
+	PayerFinancialAccount *FinancialAccount `xml:"cac:PayerFinancialAccount,omitempty"`
+}
+
+// PaymentTerms contains a textual description of the terms.
+type PaymentTerms struct {
+	Note string `xml:"cbc:Note"`
+}
+
+// AllowanceCharge is a discount or charge at document or line level.
+type AllowanceCharge struct {
+	ChargeIndicator           bool           `xml:"cbc:ChargeIndicator"`
+	AllowanceChargeReasonCode string         `xml:"cbc:AllowanceChargeReasonCode,omitempty"`
+	AllowanceChargeReason     string         `xml:"cbc:AllowanceChargeReason,omitempty"`
+	MultiplierFactorNumeric   string         `xml:"cbc:MultiplierFactorNumeric,omitempty"`
+	Amount                    *Amount        `xml:"cbc:Amount"`
+	BaseAmount                *Amount        `xml:"cbc:BaseAmount,omitempty"`
+	TaxCategories             []*TaxCategory `xml:"cac:TaxCategory,omitempty"`
+}
+
+// Amount is a monetary value with currency.
+type Amount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+// Quantity is a value with a unit code.
+type Quantity struct {
+	UnitCode string `xml:"unitCode,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// TaxTotal contains the tax amount and breakdown.
+type TaxTotal struct {
+	TaxAmount    *Amount        `xml:"cbc:TaxAmount"`
+	TaxSubtotals []*TaxSubtotal `xml:"cac:TaxSubtotal,omitempty"`
+}
+
+// TaxSubtotal contains the tax for a single category and rate.
+type TaxSubtotal struct {
+	TaxableAmount *Amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     *Amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   *TaxCategory `xml:"cac:TaxCategory"`
+}
+
+// TaxCategory identifies the tax category and percentage.
+type TaxCategory struct {
+	ID                     string     `xml:"cbc:ID"`
+	Percent                string     `xml:"cbc:Percent,omitempty"`
+	TaxExemptionReasonCode string     `xml:"cbc:TaxExemptionReasonCode,omitempty"`
+	TaxExemptionReason     string     `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme              *TaxScheme `xml:"cac:TaxScheme"`
+}
+
+// MonetaryTotal contains the document totals.
+type MonetaryTotal struct {
+	LineExtensionAmount   *Amount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount    *Amount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount    *Amount `xml:"cbc:TaxInclusiveAmount"`
+	AllowanceTotalAmount  *Amount `xml:"cbc:AllowanceTotalAmount,omitempty"`
+	ChargeTotalAmount     *Amount `xml:"cbc:ChargeTotalAmount,omitempty"`
+	PrepaidAmount         *Amount `xml:"cbc:PrepaidAmount,omitempty"`
+	PayableRoundingAmount *Amount `xml:"cbc:PayableRoundingAmount,omitempty"`
+	PayableAmount         *Amount `xml:"cbc:PayableAmount"`
+}
+
+// Line is an invoice or credit note line.
+type Line struct {
+	ID                  string             `xml:"cbc:ID"`
+	Note                string             `xml:"cbc:Note,omitempty"`
+	InvoicedQuantity    *Quantity          `xml:"cbc:InvoicedQuantity,omitempty"`
+	CreditedQuantity    *Quantity          `xml:"cbc:CreditedQuantity,omitempty"`
+	LineExtensionAmount *Amount            `xml:"cbc:LineExtensionAmount"`
+	AccountingCost      string             `xml:"cbc:AccountingCost,omitempty"`
+	InvoicePeriod       *Period            `xml:"cac:InvoicePeriod,omitempty"`
+	OrderLineReference  *OrderLineRef      `xml:"cac:OrderLineReference,omitempty"`
+	AllowanceCharges    []*AllowanceCharge `xml:"cac:AllowanceCharge,omitempty"`
+	Item                *Item              `xml:"cac:Item"`
+	Price               *Price             `xml:"cac:Price"`
+}
+
+// OrderLineRef points to the line in the purchase order.
+type OrderLineRef struct {
+	LineID string `xml:"cbc:LineID"`
+}
+
+// Item describes the product or service.
+type Item struct {
+	Description                string          `xml:"cbc:Description,omitempty"`
+	Name                       string          `xml:"cbc:Name"`
+	SellersItemIdentification  *ItemID         `xml:"cac:SellersItemIdentification,omitempty"`
+	StandardItemIdentification *ItemStandardID `xml:"cac:StandardItemIdentification,omitempty"`
+	OriginCountry              *Country        `xml:"cac:OriginCountry,omitempty"`
+	ClassifiedTaxCategory      *TaxCategory    `xml:"cac:ClassifiedTaxCategory"`
+}
+
+// ItemID is a simple item identifier.
+type ItemID struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// ItemStandardID is an item identifier with a scheme, like a GTIN.
+type ItemStandardID struct {
+	ID *Identifier `xml:"cbc:ID"`
+}
+
+// Price contains the item's unit price.
+type Price struct {
+	PriceAmount  *Amount   `xml:"cbc:PriceAmount"`
+	BaseQuantity *Quantity `xml:"cbc:BaseQuantity,omitempty"`
+}
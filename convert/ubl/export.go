@@ -0,0 +1,466 @@
+package ubl
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/cef"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+const taxSchemeVAT = "VAT"
+
+// FromInvoice converts the calculated GOBL invoice into a UBL Invoice, or
+// CreditNote if the invoice is a credit note.
+func FromInvoice(inv *bill.Invoice, opts ...Option) (*Document, error) {
+	if inv == nil || inv.Totals == nil {
+		return nil, convert.ErrNotCalculated
+	}
+	if inv.Totals.RetainedTax != nil {
+		return nil, fmt.Errorf("%w: retained taxes", convert.ErrUnsupported)
+	}
+	o := &options{customizationID: convert.GuidelineID(inv)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cur := inv.Currency
+	credit := inv.Type.In(bill.InvoiceTypeCreditNote)
+	doc := newDocument(credit)
+	doc.CustomizationID = o.customizationID
+	doc.ProfileID = o.profileID
+	doc.ID = inv.Series.Join(inv.Code).String()
+	doc.IssueDate = inv.IssueDate.String()
+	if inv.IssueTime != nil && !inv.IssueTime.IsZero() {
+		doc.IssueTime = inv.IssueTime.String()
+	}
+	code := convert.DocumentTypeCode(inv).String()
+	if credit {
+		doc.CreditNoteTypeCode = code
+	} else {
+		doc.InvoiceTypeCode = code
+	}
+	for _, n := range inv.Notes {
+		doc.Notes = append(doc.Notes, n.Text)
+	}
+	if inv.OperationDate != nil {
+		doc.TaxPointDate = inv.OperationDate.String()
+	}
+	doc.DocumentCurrencyCode = cur.String()
+	for _, p := range inv.Preceding {
+		doc.BillingReferences = append(doc.BillingReferences, &BillingRef{
+			InvoiceDocumentReference: newDocumentRef(p),
+		})
+	}
+	if o := inv.Ordering; o != nil {
+		doc.BuyerReference = o.Code.String()
+		doc.InvoicePeriod = newPeriod(o.Period)
+		if len(o.Purchases) > 0 {
+			doc.OrderReference = &OrderReference{ID: o.Purchases[0].Series.Join(o.Purchases[0].Code).String()}
+		}
+		if len(o.Contracts) > 0 {
+			doc.ContractReference = newDocumentRef(o.Contracts[0])
+		}
+		if len(o.Projects) > 0 {
+			doc.ProjectReference = newDocumentRef(o.Projects[0])
+		}
+	}
+	doc.Supplier = &PartyRole{Party: newParty(inv.Supplier)}
+	if inv.Customer != nil {
+		doc.Customer = &PartyRole{Party: newParty(inv.Customer)}
+	}
+	if d := inv.Delivery; d != nil && d.Date != nil {
+		doc.Delivery = &Delivery{ActualDeliveryDate: d.Date.String()}
+	}
+	if p := inv.Payment; p != nil {
+		if p.Terms != nil {
+			if len(p.Terms.DueDates) > 0 && p.Terms.DueDates[0].Date != nil {
+				doc.DueDate = p.Terms.DueDates[0].Date.String()
+			}
+			if note := termsNote(p.Terms); note != "" {
+				doc.PaymentTerms = &PaymentTerms{Note: note}
+			}
+		}
+		doc.PaymentMeans = newPaymentMeans(p.Instructions)
+	}
+	for _, d := range inv.Discounts {
+		doc.AllowanceCharges = append(doc.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator:           false,
+			AllowanceChargeReasonCode: d.Ext.Get(untdid.ExtKeyAllowance).String(),
+			AllowanceChargeReason:     d.Reason,
+			MultiplierFactorNumeric:   percentString(d.Percent),
+			Amount:                    newAmount(d.Amount, cur),
+			BaseAmount:                newBaseAmount(d.Base, d.Percent, inv.Totals.Sum, cur),
+			TaxCategories:             newTaxCategories(d.Taxes),
+		})
+	}
+	for _, c := range inv.Charges {
+		doc.AllowanceCharges = append(doc.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator:           true,
+			AllowanceChargeReasonCode: c.Ext.Get(untdid.ExtKeyCharge).String(),
+			AllowanceChargeReason:     c.Reason,
+			MultiplierFactorNumeric:   percentString(c.Percent),
+			Amount:                    newAmount(c.Amount, cur),
+			BaseAmount:                newBaseAmount(c.Base, c.Percent, inv.Totals.Sum, cur),
+			TaxCategories:             newTaxCategories(c.Taxes),
+		})
+	}
+	doc.TaxTotals = newTaxTotals(inv.Totals, cur)
+	doc.LegalMonetaryTotal = newMonetaryTotal(inv.Totals, cur)
+	for _, l := range inv.Lines {
+		line := newLine(l, cur, credit)
+		if credit {
+			doc.CreditNoteLines = append(doc.CreditNoteLines, line)
+		} else {
+			doc.InvoiceLines = append(doc.InvoiceLines, line)
+		}
+	}
+	if o.peppol {
+		applyPeppol(doc, inv)
+		if err := ValidatePeppol(doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func newDocumentRef(ref *org.DocumentRef) *DocumentRef {
+	dr := &DocumentRef{ID: ref.Series.Join(ref.Code).String()}
+	if ref.IssueDate != nil {
+		dr.IssueDate = ref.IssueDate.String()
+	}
+	return dr
+}
+
+func newPeriod(p *cal.Period) *Period {
+	if p == nil {
+		return nil
+	}
+	return &Period{
+		StartDate: p.Start.String(),
+		EndDate:   p.End.String(),
+	}
+}
+
+func newParty(p *org.Party) *Party {
+	if p == nil {
+		return nil
+	}
+	party := new(Party)
+	if ea := p.ElectronicAddress(); !ea.IsEmpty() {
+		party.EndpointID = &Identifier{SchemeID: ea.Scheme().String(), Value: ea.Code().String()}
+	}
+	for _, id := range p.Identities {
+		party.Identifications = append(party.Identifications, &Identification{
+			ID: &Identifier{
+				SchemeID: id.Ext.Get(iso.ExtKeySchemeID).String(),
+				Value:    id.Code.String(),
+			},
+		})
+	}
+	if p.Alias != "" {
+		party.Name = &PartyName{Name: p.Alias}
+	}
+	if len(p.Addresses) > 0 {
+		party.PostalAddress = newAddress(p.Addresses[0])
+	}
+	if code := convert.TaxIdentityCode(p.TaxID); code != "" {
+		party.TaxSchemes = []*PartyTaxScheme{
+			{CompanyID: code, TaxScheme: &TaxScheme{ID: taxSchemeVAT}},
+		}
+	}
+	party.LegalEntity = &PartyLegalEntity{RegistrationName: p.Name}
+	if party.PostalAddress == nil && p.TaxID != nil && p.TaxID.Country != "" {
+		// the country is required by EN 16931
+		party.PostalAddress = &Address{
+			Country: &Country{IdentificationCode: p.TaxID.Country.String()},
+		}
+	}
+	party.Contact = newContact(p)
+	return party
+}
+
+func newAddress(a *org.Address) *Address {
+	addr := &Address{
+		StreetName:           a.LineOne(),
+		AdditionalStreetName: a.LineTwo(),
+		CityName:             a.Locality,
+		PostalZone:           a.Code.String(),
+		CountrySubentity:     a.Region,
+	}
+	if a.Country != "" {
+		addr.Country = &Country{IdentificationCode: a.Country.String()}
+	}
+	return addr
+}
+
+func newContact(p *org.Party) *Contact {
+	c := new(Contact)
+	if len(p.People) > 0 && p.People[0].Name != nil {
+		n := p.People[0].Name
+		c.Name = joinNonEmpty(n.Given, n.Surname)
+	}
+	if len(p.Telephones) > 0 {
+		c.Telephone = p.Telephones[0].Number
+	}
+	if len(p.Emails) > 0 {
+		c.ElectronicMail = p.Emails[0].Address
+	}
+	if *c == (Contact{}) {
+		return nil
+	}
+	return c
+}
+
+func termsNote(t *pay.Terms) string {
+	if t.Notes != "" {
+		return t.Notes
+	}
+	return t.Detail
+}
+
+func newPaymentMeans(instr *pay.Instructions) []*PaymentMeans {
+	if instr == nil {
+		return nil
+	}
+	code := convert.PaymentMeansCode(instr).String()
+	pm := &PaymentMeans{
+		PaymentMeansCode: code,
+		PaymentID:        instr.Ref.String(),
+	}
+	if c := instr.Card; c != nil {
+		pm.CardAccount = &CardAccount{
+			PrimaryAccountNumberID: c.Last4,
+			NetworkID:              c.Network.String(),
+			HolderName:             c.Holder,
+		}
+		if pm.CardAccount.NetworkID == "" {
+			pm.CardAccount.NetworkID = "NA"
+		}
+	}
+	if dd := instr.DirectDebit; dd != nil {
+		pm.PaymentMandate = &PaymentMandate{ID: dd.Ref}
+		if dd.Account != "" {
+			pm.PaymentMandate.PayerFinancialAccount = &FinancialAccount{ID: dd.Account}
+		}
+	}
+	if len(instr.CreditTransfer) == 0 {
+		return []*PaymentMeans{pm}
+	}
+	list := make([]*PaymentMeans, len(instr.CreditTransfer))
+	for i, ct := range instr.CreditTransfer {
+		m := *pm
+		m.PayeeFinancialAccount = &FinancialAccount{
+			ID:   ct.IBAN,
+			Name: ct.Name,
+		}
+		if ct.IBAN == "" {
+			m.PayeeFinancialAccount.ID = ct.Number
+		}
+		if ct.BIC != "" {
+			m.PayeeFinancialAccount.FinancialInstitutionBranch = &Branch{ID: ct.BIC}
+		}
+		list[i] = &m
+	}
+	return list
+}
+
+func newTaxCategories(ts tax.Set) []*TaxCategory {
+	var list []*TaxCategory
+	for _, tc := range ts {
+		if tc.Category != tax.CategoryVAT {
+			continue
+		}
+		list = append(list, newTaxCategory(tc.Category, tc.Key, tc.Percent, tc.Ext))
+	}
+	return list
+}
+
+func newTaxCategory(cat cbc.Code, key cbc.Key, percent *num.Percentage, ext tax.Extensions) *TaxCategory {
+	c := &TaxCategory{
+		ID:        convert.TaxCategoryCode(cat, key, ext).String(),
+		TaxScheme: &TaxScheme{ID: taxSchemeVAT},
+	}
+	if percent != nil {
+		c.Percent = percent.StringWithoutSymbol()
+	} else if c.ID == convert.TaxCategoryZero.String() {
+		c.Percent = "0"
+	}
+	c.TaxExemptionReasonCode = ext.Get(cef.ExtKeyVATEX).String()
+	return c
+}
+
+func newTaxTotals(t *bill.Totals, cur currency.Code) []*TaxTotal {
+	tt := &TaxTotal{TaxAmount: newAmount(t.Tax, cur)}
+	if t.Taxes != nil {
+		for _, ct := range t.Taxes.Categories {
+			if ct.Retained || ct.Informative {
+				continue
+			}
+			for _, rt := range ct.Rates {
+				tt.TaxSubtotals = append(tt.TaxSubtotals, &TaxSubtotal{
+					TaxableAmount: newAmount(rt.Base, cur),
+					TaxAmount:     newAmount(rt.Amount, cur),
+					TaxCategory:   newTaxCategory(ct.Code, rt.Key, rt.Percent, rt.Ext),
+				})
+			}
+		}
+	}
+	return []*TaxTotal{tt}
+}
+
+func newMonetaryTotal(t *bill.Totals, cur currency.Code) *MonetaryTotal {
+	mt := &MonetaryTotal{
+		LineExtensionAmount:   newAmount(t.Sum, cur),
+		TaxExclusiveAmount:    newAmount(t.Total, cur),
+		TaxInclusiveAmount:    newAmount(t.TotalWithTax, cur),
+		AllowanceTotalAmount:  newAmountPtr(t.Discount, cur),
+		ChargeTotalAmount:     newAmountPtr(t.Charge, cur),
+		PrepaidAmount:         newAmountPtr(t.Advances, cur),
+		PayableRoundingAmount: newAmountPtr(t.Rounding, cur),
+		PayableAmount:         newAmount(t.Payable, cur),
+	}
+	if t.Due != nil {
+		mt.PayableAmount = newAmount(*t.Due, cur)
+	}
+	return mt
+}
+
+func newLine(l *bill.Line, cur currency.Code, credit bool) *Line {
+	line := &Line{
+		ID:             fmt.Sprintf("%d", l.Index),
+		AccountingCost: l.Cost.String(),
+		InvoicePeriod:  newPeriod(l.Period),
+	}
+	if len(l.Notes) > 0 {
+		line.Note = l.Notes[0].Text
+	}
+	if l.Order != cbc.CodeEmpty {
+		line.OrderLineReference = &OrderLineRef{LineID: l.Order.String()}
+	}
+	q := &Quantity{Value: l.Quantity.String()}
+	if l.Item != nil {
+		q.UnitCode = l.Item.Unit.UNECE().String()
+	}
+	if credit {
+		line.CreditedQuantity = q
+	} else {
+		line.InvoicedQuantity = q
+	}
+	if l.Total != nil {
+		line.LineExtensionAmount = newAmount(*l.Total, cur)
+	}
+	for _, d := range l.Discounts {
+		line.AllowanceCharges = append(line.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator:           false,
+			AllowanceChargeReasonCode: d.Ext.Get(untdid.ExtKeyAllowance).String(),
+			AllowanceChargeReason:     d.Reason,
+			MultiplierFactorNumeric:   percentString(d.Percent),
+			Amount:                    newAmount(d.Amount, cur),
+			BaseAmount:                newBaseAmount(d.Base, d.Percent, lineSum(l), cur),
+		})
+	}
+	for _, c := range l.Charges {
+		line.AllowanceCharges = append(line.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator:           true,
+			AllowanceChargeReasonCode: c.Ext.Get(untdid.ExtKeyCharge).String(),
+			AllowanceChargeReason:     c.Reason,
+			MultiplierFactorNumeric:   percentString(c.Percent),
+			Amount:                    newAmount(c.Amount, cur),
+			BaseAmount:                newBaseAmount(c.Base, c.Percent, lineSum(l), cur),
+		})
+	}
+	line.Item = newItem(l)
+	if l.Item != nil && l.Item.Price != nil {
+		line.Price = &Price{PriceAmount: newAmount(*l.Item.Price, cur)}
+	}
+	return line
+}
+
+func newItem(l *bill.Line) *Item {
+	it := &Item{}
+	if l.Item != nil {
+		it.Name = l.Item.Name
+		it.Description = l.Item.Description
+		if l.Item.Ref != cbc.CodeEmpty {
+			it.SellersItemIdentification = &ItemID{ID: l.Item.Ref.String()}
+		}
+		for _, id := range l.Item.Identities {
+			if s := id.Ext.Get(iso.ExtKeySchemeID); s != cbc.CodeEmpty {
+				it.StandardItemIdentification = &ItemStandardID{
+					ID: &Identifier{SchemeID: s.String(), Value: id.Code.String()},
+				}
+				break
+			}
+		}
+		if l.Item.Origin != "" {
+			it.OriginCountry = &Country{IdentificationCode: l.Item.Origin.String()}
+		}
+	}
+	if vat := l.Taxes.Get(tax.CategoryVAT); vat != nil {
+		it.ClassifiedTaxCategory = newTaxCategory(vat.Category, vat.Key, vat.Percent, vat.Ext)
+	} else {
+		it.ClassifiedTaxCategory = &TaxCategory{
+			ID:        convert.TaxCategoryOutsideScope.String(),
+			TaxScheme: &TaxScheme{ID: taxSchemeVAT},
+		}
+	}
+	return it
+}
+
+func newAmount(a num.Amount, cur currency.Code) *Amount {
+	return &Amount{CurrencyID: cur.String(), Value: a.String()}
+}
+
+func newAmountPtr(a *num.Amount, cur currency.Code) *Amount {
+	if a == nil {
+		return nil
+	}
+	return newAmount(*a, cur)
+}
+
+// newBaseAmount provides the base of a percentage allowance or charge,
+// which defaults to the sum it was calculated from.
+func newBaseAmount(base *num.Amount, percent *num.Percentage, sum num.Amount, cur currency.Code) *Amount {
+	if base == nil && percent != nil {
+		base = &sum
+	}
+	return newAmountPtr(base, cur)
+}
+
+func lineSum(l *bill.Line) num.Amount {
+	if l.Sum == nil {
+		return num.AmountZero
+	}
+	return *l.Sum
+}
+
+func percentString(p *num.Percentage) string {
+	if p == nil {
+		return ""
+	}
+	return p.StringWithoutSymbol()
+}
+
+func joinNonEmpty(parts ...string) string {
+	out := ""
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if out != "" {
+			out += " "
+		}
+		out += p
+	}
+	return out
+}
@@ -0,0 +1,518 @@
+package ubl
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+)
+
+// ToInvoice converts the UBL document into a GOBL invoice using the
+// EN 16931 addon, and calculates the result.
+func (d *Document) ToInvoice() (*bill.Invoice, error) {
+	inv, err := d.invoice()
+	if err != nil {
+		return nil, err
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, fmt.Errorf("calculating invoice: %w", err)
+	}
+	return inv, nil
+}
+
+func (d *Document) invoice() (*bill.Invoice, error) {
+	inv := &bill.Invoice{
+		Addons:   tax.WithAddons(en16931.V2017),
+		Code:     cbc.Code(d.ID),
+		Currency: currency.Code(d.DocumentCurrencyCode),
+	}
+	code := d.InvoiceTypeCode
+	if d.IsCreditNote() {
+		code = d.CreditNoteTypeCode
+	}
+	inv.Type = convert.InvoiceType(cbc.Code(code))
+	if code != "" {
+		inv.Tax = &bill.Tax{
+			Ext: tax.Extensions{untdid.ExtKeyDocumentType: cbc.Code(code)},
+		}
+	}
+
+	date, err := convert.ParseDate(d.IssueDate)
+	if err != nil {
+		return nil, fmt.Errorf("issue date: %w", err)
+	}
+	if date != nil {
+		inv.IssueDate = *date
+	}
+	if inv.OperationDate, err = convert.ParseDate(d.TaxPointDate); err != nil {
+		return nil, fmt.Errorf("tax point date: %w", err)
+	}
+	for _, n := range d.Notes {
+		inv.Notes = append(inv.Notes, &org.Note{Text: n})
+	}
+	for _, br := range d.BillingReferences {
+		ref, err := goblDocumentRef(br.InvoiceDocumentReference)
+		if err != nil {
+			return nil, fmt.Errorf("billing reference: %w", err)
+		}
+		if ref != nil {
+			inv.Preceding = append(inv.Preceding, ref)
+		}
+	}
+	if inv.Ordering, err = d.ordering(); err != nil {
+		return nil, err
+	}
+
+	if d.Supplier != nil {
+		inv.Supplier = goblParty(d.Supplier.Party)
+	}
+	if d.Customer != nil {
+		inv.Customer = goblParty(d.Customer.Party)
+	}
+	if d.IsPeppol() {
+		peppolInboxes(inv.Supplier, inv.Customer)
+	}
+	if d.Delivery != nil {
+		dd, err := convert.ParseDate(d.Delivery.ActualDeliveryDate)
+		if err != nil {
+			return nil, fmt.Errorf("delivery date: %w", err)
+		}
+		if dd != nil {
+			inv.Delivery = &bill.DeliveryDetails{Date: dd}
+		}
+	}
+	if inv.Payment, err = d.payment(); err != nil {
+		return nil, err
+	}
+
+	for _, ac := range d.AllowanceCharges {
+		if err := addAllowanceCharge(inv, ac); err != nil {
+			return nil, err
+		}
+	}
+	for _, l := range d.Lines() {
+		line, err := goblLine(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %s: %w", l.ID, err)
+		}
+		inv.Lines = append(inv.Lines, line)
+	}
+	if mt := d.LegalMonetaryTotal; mt != nil && mt.PayableRoundingAmount != nil {
+		r, err := convert.ParseAmount(mt.PayableRoundingAmount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("rounding: %w", err)
+		}
+		inv.Totals = &bill.Totals{Rounding: &r}
+	}
+	return inv, nil
+}
+
+func (d *Document) ordering() (*bill.Ordering, error) {
+	o := &bill.Ordering{Code: cbc.Code(d.BuyerReference)}
+	if d.InvoicePeriod != nil {
+		p, err := goblPeriod(d.InvoicePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invoice period: %w", err)
+		}
+		o.Period = p
+	}
+	if d.OrderReference != nil && d.OrderReference.ID != "" {
+		o.Purchases = []*org.DocumentRef{{Code: cbc.Code(d.OrderReference.ID)}}
+	}
+	if ref, err := goblDocumentRef(d.ContractReference); err != nil {
+		return nil, fmt.Errorf("contract reference: %w", err)
+	} else if ref != nil {
+		o.Contracts = []*org.DocumentRef{ref}
+	}
+	if ref, err := goblDocumentRef(d.ProjectReference); err != nil {
+		return nil, fmt.Errorf("project reference: %w", err)
+	} else if ref != nil {
+		o.Projects = []*org.DocumentRef{ref}
+	}
+	if o.Code == cbc.CodeEmpty && o.Period == nil && o.Purchases == nil &&
+		o.Contracts == nil && o.Projects == nil {
+		return nil, nil
+	}
+	return o, nil
+}
+
+func (d *Document) payment() (*bill.PaymentDetails, error) {
+	p := new(bill.PaymentDetails)
+	due, err := convert.ParseDate(d.DueDate)
+	if err != nil {
+		return nil, fmt.Errorf("due date: %w", err)
+	}
+	if due != nil || d.PaymentTerms != nil {
+		p.Terms = new(pay.Terms)
+		if d.PaymentTerms != nil {
+			p.Terms.Notes = d.PaymentTerms.Note
+		}
+		if due != nil {
+			p.Terms.DueDates = []*pay.DueDate{
+				{Date: due, Percent: num.NewPercentage(100, 2)},
+			}
+		}
+	}
+	if len(d.PaymentMeans) > 0 {
+		p.Instructions = goblInstructions(d.PaymentMeans)
+	}
+	if mt := d.LegalMonetaryTotal; mt != nil && mt.PrepaidAmount != nil {
+		a, err := convert.ParseAmount(mt.PrepaidAmount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("prepaid amount: %w", err)
+		}
+		if !a.IsZero() {
+			p.Advances = []*pay.Advance{
+				{Description: "Prepaid amount", Amount: a},
+			}
+		}
+	}
+	if p.Terms == nil && p.Instructions == nil && p.Advances == nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+func goblInstructions(list []*PaymentMeans) *pay.Instructions {
+	pm := list[0]
+	code := cbc.Code(pm.PaymentMeansCode)
+	instr := &pay.Instructions{
+		Key: convert.PaymentMeansKey(code),
+		Ref: cbc.Code(pm.PaymentID),
+	}
+	if code != cbc.CodeEmpty {
+		instr.Ext = tax.Extensions{untdid.ExtKeyPaymentMeans: code}
+	}
+	if ca := pm.CardAccount; ca != nil {
+		instr.Card = &pay.Card{
+			Last4:  lastDigits(ca.PrimaryAccountNumberID, 4),
+			Holder: ca.HolderName,
+		}
+	}
+	if m := pm.PaymentMandate; m != nil {
+		instr.DirectDebit = &pay.DirectDebit{Ref: m.ID}
+		if m.PayerFinancialAccount != nil {
+			instr.DirectDebit.Account = m.PayerFinancialAccount.ID
+		}
+	}
+	for _, m := range list {
+		fa := m.PayeeFinancialAccount
+		if fa == nil {
+			continue
+		}
+		ct := &pay.CreditTransfer{Name: fa.Name}
+		if convert.IsIBAN(fa.ID) {
+			ct.IBAN = fa.ID
+		} else {
+			ct.Number = fa.ID
+		}
+		if fa.FinancialInstitutionBranch != nil {
+			ct.BIC = fa.FinancialInstitutionBranch.ID
+		}
+		instr.CreditTransfer = append(instr.CreditTransfer, ct)
+	}
+	return instr
+}
+
+func addAllowanceCharge(inv *bill.Invoice, ac *AllowanceCharge) error {
+	amount, base, percent, err := parseAllowanceCharge(ac)
+	if err != nil {
+		return err
+	}
+	taxes, err := goblTaxes(ac.TaxCategories)
+	if err != nil {
+		return fmt.Errorf("allowance or charge tax: %w", err)
+	}
+	if ac.ChargeIndicator {
+		c := &bill.Charge{
+			Reason:  ac.AllowanceChargeReason,
+			Amount:  amount,
+			Base:    base,
+			Percent: percent,
+			Taxes:   taxes,
+		}
+		if ac.AllowanceChargeReasonCode != "" {
+			c.Ext = tax.Extensions{untdid.ExtKeyCharge: cbc.Code(ac.AllowanceChargeReasonCode)}
+		}
+		inv.Charges = append(inv.Charges, c)
+		return nil
+	}
+	dc := &bill.Discount{
+		Reason:  ac.AllowanceChargeReason,
+		Amount:  amount,
+		Base:    base,
+		Percent: percent,
+		Taxes:   taxes,
+	}
+	if ac.AllowanceChargeReasonCode != "" {
+		dc.Ext = tax.Extensions{untdid.ExtKeyAllowance: cbc.Code(ac.AllowanceChargeReasonCode)}
+	}
+	inv.Discounts = append(inv.Discounts, dc)
+	return nil
+}
+
+func parseAllowanceCharge(ac *AllowanceCharge) (num.Amount, *num.Amount, *num.Percentage, error) {
+	var amount num.Amount
+	var err error
+	if ac.Amount != nil {
+		if amount, err = convert.ParseAmount(ac.Amount.Value); err != nil {
+			return amount, nil, nil, fmt.Errorf("allowance or charge: %w", err)
+		}
+	}
+	var base *num.Amount
+	if ac.BaseAmount != nil {
+		b, err := convert.ParseAmount(ac.BaseAmount.Value)
+		if err != nil {
+			return amount, nil, nil, fmt.Errorf("allowance or charge base: %w", err)
+		}
+		base = &b
+	}
+	percent, err := convert.ParsePercent(ac.MultiplierFactorNumeric)
+	if err != nil {
+		return amount, nil, nil, fmt.Errorf("allowance or charge: %w", err)
+	}
+	return amount, base, percent, nil
+}
+
+func goblTaxes(cats []*TaxCategory) (tax.Set, error) {
+	var set tax.Set
+	for _, tc := range cats {
+		c, err := goblTaxCombo(tc)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			set = append(set, c)
+		}
+	}
+	return set, nil
+}
+
+func goblTaxCombo(tc *TaxCategory) (*tax.Combo, error) {
+	if tc == nil {
+		return nil, nil
+	}
+	return convert.VATCombo(cbc.Code(tc.ID), tc.Percent, cbc.Code(tc.TaxExemptionReasonCode))
+}
+
+func goblLine(l *Line) (*bill.Line, error) {
+	line := &bill.Line{
+		Cost: cbc.Code(l.AccountingCost),
+		Item: new(org.Item),
+	}
+	q := l.InvoicedQuantity
+	if q == nil {
+		q = l.CreditedQuantity
+	}
+	if q != nil {
+		a, err := convert.ParseAmount(q.Value)
+		if err != nil {
+			return nil, fmt.Errorf("quantity: %w", err)
+		}
+		line.Quantity = a
+		line.Item.Unit = convert.UnitFromUNECE(q.UnitCode)
+	}
+	if l.Note != "" {
+		line.Notes = []*org.Note{{Text: l.Note}}
+	}
+	if l.OrderLineReference != nil {
+		line.Order = cbc.Code(l.OrderLineReference.LineID)
+	}
+	if l.InvoicePeriod != nil {
+		p, err := goblPeriod(l.InvoicePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("period: %w", err)
+		}
+		line.Period = p
+	}
+	if it := l.Item; it != nil {
+		line.Item.Name = it.Name
+		line.Item.Description = it.Description
+		if it.SellersItemIdentification != nil {
+			line.Item.Ref = cbc.Code(it.SellersItemIdentification.ID)
+		}
+		if s := it.StandardItemIdentification; s != nil && s.ID != nil {
+			line.Item.Identities = []*org.Identity{goblIdentity(s.ID)}
+		}
+		if it.OriginCountry != nil {
+			line.Item.Origin = l10n.ISOCountryCode(it.OriginCountry.IdentificationCode)
+		}
+		c, err := goblTaxCombo(it.ClassifiedTaxCategory)
+		if err != nil {
+			return nil, fmt.Errorf("tax category: %w", err)
+		}
+		if c != nil {
+			line.Taxes = tax.Set{c}
+		}
+	}
+	if l.Price != nil && l.Price.PriceAmount != nil {
+		p, err := convert.ParseAmount(l.Price.PriceAmount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("price: %w", err)
+		}
+		if bq := l.Price.BaseQuantity; bq != nil && bq.Value != "" {
+			// prices may be provided for a base quantity other than 1
+			b, err := convert.ParseAmount(bq.Value)
+			if err != nil {
+				return nil, fmt.Errorf("base quantity: %w", err)
+			}
+			if !b.IsZero() {
+				p = p.Divide(b)
+			}
+		}
+		line.Item.Price = &p
+	}
+	for _, ac := range l.AllowanceCharges {
+		amount, base, percent, err := parseAllowanceCharge(ac)
+		if err != nil {
+			return nil, err
+		}
+		if ac.ChargeIndicator {
+			c := &bill.LineCharge{
+				Reason:  ac.AllowanceChargeReason,
+				Amount:  amount,
+				Base:    base,
+				Percent: percent,
+			}
+			if ac.AllowanceChargeReasonCode != "" {
+				c.Ext = tax.Extensions{untdid.ExtKeyCharge: cbc.Code(ac.AllowanceChargeReasonCode)}
+			}
+			line.Charges = append(line.Charges, c)
+			continue
+		}
+		dc := &bill.LineDiscount{
+			Reason:  ac.AllowanceChargeReason,
+			Amount:  amount,
+			Base:    base,
+			Percent: percent,
+		}
+		if ac.AllowanceChargeReasonCode != "" {
+			dc.Ext = tax.Extensions{untdid.ExtKeyAllowance: cbc.Code(ac.AllowanceChargeReasonCode)}
+		}
+		line.Discounts = append(line.Discounts, dc)
+	}
+	return line, nil
+}
+
+func goblParty(p *Party) *org.Party {
+	if p == nil {
+		return nil
+	}
+	party := new(org.Party)
+	if p.LegalEntity != nil {
+		party.Name = p.LegalEntity.RegistrationName
+	}
+	if p.Name != nil {
+		if party.Name == "" {
+			party.Name = p.Name.Name
+		} else if p.Name.Name != party.Name {
+			party.Alias = p.Name.Name
+		}
+	}
+	if p.EndpointID != nil && p.EndpointID.Value != "" {
+		scheme := cbc.Code(p.EndpointID.SchemeID)
+		if iso.SchemeDefFor(scheme) != nil {
+			party.Endpoint = cbc.NewSchemeCode(scheme, cbc.Code(p.EndpointID.Value))
+		} else {
+			party.Inboxes = []*org.Inbox{{
+				Scheme: scheme,
+				Code:   cbc.Code(p.EndpointID.Value),
+			}}
+		}
+	}
+	for _, id := range p.Identifications {
+		if id.ID != nil && id.ID.Value != "" {
+			party.Identities = append(party.Identities, goblIdentity(id.ID))
+		}
+	}
+	var country l10n.TaxCountryCode
+	if a := p.PostalAddress; a != nil {
+		addr := &org.Address{
+			Street:      a.StreetName,
+			StreetExtra: a.AdditionalStreetName,
+			Locality:    a.CityName,
+			Code:        cbc.Code(a.PostalZone),
+			Region:      a.CountrySubentity,
+		}
+		if a.Country != nil {
+			addr.Country = l10n.ISOCountryCode(a.Country.IdentificationCode)
+			country = l10n.TaxCountryCode(a.Country.IdentificationCode)
+		}
+		if addr.Street != "" || addr.Locality != "" || addr.Code != cbc.CodeEmpty {
+			party.Addresses = []*org.Address{addr}
+		}
+	}
+	for _, ts := range p.TaxSchemes {
+		if ts.TaxScheme != nil && ts.TaxScheme.ID == taxSchemeVAT {
+			party.TaxID = convert.ParseTaxIdentity(ts.CompanyID, country)
+			break
+		}
+	}
+	if party.TaxID == nil && country != "" {
+		party.TaxID = &tax.Identity{Country: country}
+	}
+	if c := p.Contact; c != nil {
+		if c.Name != "" {
+			party.People = []*org.Person{{Name: &org.Name{Given: c.Name}}}
+		}
+		if c.Telephone != "" {
+			party.Telephones = []*org.Telephone{{Number: c.Telephone}}
+		}
+		if c.ElectronicMail != "" {
+			party.Emails = []*org.Email{{Address: c.ElectronicMail}}
+		}
+	}
+	return party
+}
+
+func goblIdentity(id *Identifier) *org.Identity {
+	oid := &org.Identity{Code: cbc.Code(id.Value)}
+	if id.SchemeID != "" {
+		oid.Ext = tax.Extensions{iso.ExtKeySchemeID: cbc.Code(id.SchemeID)}
+	}
+	return oid
+}
+
+func goblDocumentRef(ref *DocumentRef) (*org.DocumentRef, error) {
+	if ref == nil || ref.ID == "" {
+		return nil, nil
+	}
+	d, err := convert.ParseDate(ref.IssueDate)
+	if err != nil {
+		return nil, err
+	}
+	return &org.DocumentRef{Code: cbc.Code(ref.ID), IssueDate: d}, nil
+}
+
+func goblPeriod(p *Period) (*cal.Period, error) {
+	start, err := convert.ParseDate(p.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := convert.ParseDate(p.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if start == nil || end == nil {
+		return nil, nil
+	}
+	return &cal.Period{Start: *start, End: *end}, nil
+}
+
+func lastDigits(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
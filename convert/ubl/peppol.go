@@ -0,0 +1,175 @@
+package ubl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/catalogues/iso"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
+)
+
+// Peppol BIS Billing 3.0 identifiers.
+const (
+	CustomizationPeppol = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	ProfilePeppol       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+)
+
+// ErrPeppol is returned when a document does not comply with the Peppol
+// BIS Billing 3.0 rules.
+var ErrPeppol = errors.New("peppol")
+
+// schemeDUNS is the ICD code used for DUNS identities when the party does
+// not define the scheme explicitly.
+const schemeDUNS = "0060"
+
+// identitySchemes maps identity keys to their ISO 6523 ICD code.
+var identitySchemes = map[cbc.Key]string{
+	org.IdentityKeyDUNS: schemeDUNS,
+}
+
+var peppolSchemeRegexp = regexp.MustCompile(`^\d{4}$`)
+
+// Payment means codes that require a direct debit mandate.
+var directDebitCodes = []string{"49", "59"}
+
+// WithPeppol generates documents following the Peppol BIS Billing 3.0
+// specification. Party endpoints will be taken from the party's electronic
+// address, either the endpoint or an inbox with a scheme, falling back to
+// the party's VAT number, and the result will be checked against the
+// Peppol rules before being returned.
+func WithPeppol() Option {
+	return func(o *options) {
+		o.peppol = true
+		o.customizationID = CustomizationPeppol
+		o.profileID = ProfilePeppol
+	}
+}
+
+// IsPeppol returns true if the document claims to follow the Peppol BIS
+// Billing 3.0 specification.
+func (d *Document) IsPeppol() bool {
+	return strings.HasPrefix(d.CustomizationID, CustomizationPeppol)
+}
+
+// applyPeppol updates the party details to use the identifiers expected
+// by Peppol access points.
+func applyPeppol(doc *Document, inv *bill.Invoice) {
+	applyPeppolParty(doc.Supplier, inv.Supplier)
+	applyPeppolParty(doc.Customer, inv.Customer)
+}
+
+func applyPeppolParty(pr *PartyRole, p *org.Party) {
+	if pr == nil || pr.Party == nil || p == nil {
+		return
+	}
+	pr.Party.EndpointID = peppolEndpoint(p)
+	for i, id := range p.Identities {
+		if i >= len(pr.Party.Identifications) {
+			break
+		}
+		pid := pr.Party.Identifications[i].ID
+		if pid.SchemeID == "" {
+			pid.SchemeID = identitySchemes[id.Key]
+		}
+	}
+}
+
+// peppolEndpoint determines the electronic address of the party.
+func peppolEndpoint(p *org.Party) *Identifier {
+	if ea := p.ElectronicAddress(); !ea.IsEmpty() {
+		return &Identifier{SchemeID: ea.Scheme().String(), Value: ea.Code().String()}
+	}
+	if p.TaxID != nil && p.TaxID.Code != cbc.CodeEmpty {
+		if sd := iso.VATSchemeFor(p.TaxID.Country); sd != nil {
+			return &Identifier{SchemeID: sd.Code.String(), Value: sd.VATCode(p.TaxID.Code).String()}
+		}
+	}
+	return nil
+}
+
+// ValidatePeppol checks the document against the subset of the Peppol
+// BIS Billing 3.0 rules that are not already guaranteed by the EN 16931
+// model, returning an error that lists the rules broken.
+func ValidatePeppol(d *Document) error {
+	var faults []string
+	add := func(rule, msg string) {
+		faults = append(faults, rule+": "+msg)
+	}
+	if d.ProfileID == "" {
+		add("PEPPOL-EN16931-R001", "business process must be provided")
+	}
+	if !d.IsPeppol() {
+		add("PEPPOL-EN16931-R004", "specification identifier must have the value '"+CustomizationPeppol+"'")
+	}
+	if d.BuyerReference == "" && d.OrderReference == nil {
+		add("PEPPOL-EN16931-R003", "a buyer reference or purchase order reference must be provided")
+	}
+	if len(d.TaxTotals) != 1 {
+		add("PEPPOL-EN16931-R053", "only one tax total with tax subtotals must be provided")
+	}
+	if d.Supplier == nil || d.Supplier.Party == nil || d.Supplier.Party.EndpointID == nil {
+		add("PEPPOL-EN16931-R020", "seller electronic address must be provided")
+	} else if f := checkPeppolIdentifier(d.Supplier.Party.EndpointID); f != "" {
+		add("PEPPOL-EN16931-CL008", "seller "+f)
+	}
+	if d.Customer == nil || d.Customer.Party == nil || d.Customer.Party.EndpointID == nil {
+		add("PEPPOL-EN16931-R010", "buyer electronic address must be provided")
+	} else if f := checkPeppolIdentifier(d.Customer.Party.EndpointID); f != "" {
+		add("PEPPOL-EN16931-CL008", "buyer "+f)
+	}
+	for _, pm := range d.PaymentMeans {
+		if !slices.Contains(directDebitCodes, pm.PaymentMeansCode) {
+			continue
+		}
+		if pm.PaymentMandate == nil || pm.PaymentMandate.ID == "" {
+			add("PEPPOL-EN16931-R061", "mandate reference must be provided for direct debit")
+		}
+	}
+	acs := slices.Clone(d.AllowanceCharges)
+	for _, l := range d.Lines() {
+		acs = append(acs, l.AllowanceCharges...)
+	}
+	for _, ac := range acs {
+		if ac.MultiplierFactorNumeric != "" && ac.BaseAmount == nil {
+			add("PEPPOL-EN16931-R041", "allowance or charge base amount must be provided with a percentage")
+			break
+		}
+	}
+	if len(faults) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPeppol, strings.Join(faults, "; "))
+}
+
+// checkPeppolIdentifier ensures the electronic address uses a scheme
+// code, and that codes follow the rules of known schemes, such as the
+// check digit of GLNs.
+func checkPeppolIdentifier(id *Identifier) string {
+	if !peppolSchemeRegexp.MatchString(id.SchemeID) {
+		return "electronic address scheme must be a valid EAS code"
+	}
+	if sd := iso.SchemeDefFor(cbc.Code(id.SchemeID)); sd != nil {
+		if err := sd.ValidateCode(cbc.Code(id.Value)); err != nil {
+			return "electronic address " + err.Error()
+		}
+	}
+	return ""
+}
+
+// peppolInboxes marks the endpoints of parties imported from Peppol
+// documents.
+func peppolInboxes(parties ...*org.Party) {
+	for _, p := range parties {
+		if p == nil {
+			continue
+		}
+		for _, ib := range p.Inboxes {
+			ib.Key = org.InboxKeyPeppol
+		}
+	}
+}
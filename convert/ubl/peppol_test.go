@@ -0,0 +1,101 @@
+package ubl_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert/ubl"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPeppolInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := testInvoice(t)
+	inv.Ordering = &bill.Ordering{Code: "PO-4321"}
+	inv.Supplier.Identities = []*org.Identity{
+		{Key: org.IdentityKeyDUNS, Code: "123456789"},
+	}
+	inv.Customer.Inboxes = []*org.Inbox{
+		{Key: org.InboxKeyPeppol, Code: "0088:4000001123452"},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func TestWithPeppol(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		doc, err := ubl.FromInvoice(testPeppolInvoice(t), ubl.WithPeppol())
+		require.NoError(t, err)
+		assert.True(t, doc.IsPeppol())
+		assert.Equal(t, ubl.CustomizationPeppol, doc.CustomizationID)
+		assert.Equal(t, ubl.ProfilePeppol, doc.ProfileID)
+		assert.Equal(t, "PO-4321", doc.BuyerReference)
+		assert.Equal(t, "0088", doc.Supplier.Party.EndpointID.SchemeID)
+		assert.Equal(t, "0060", doc.Supplier.Party.Identifications[0].ID.SchemeID)
+		assert.Equal(t, "0088", doc.Customer.Party.EndpointID.SchemeID)
+		assert.Equal(t, "4000001123452", doc.Customer.Party.EndpointID.Value)
+		ac := doc.InvoiceLines[0].AllowanceCharges[0]
+		assert.Equal(t, "10", ac.MultiplierFactorNumeric)
+		assert.Equal(t, "1800.00", ac.BaseAmount.Value)
+	})
+	t.Run("vat endpoint", func(t *testing.T) {
+		inv := testPeppolInvoice(t)
+		inv.Customer.Inboxes = nil
+		doc, err := ubl.FromInvoice(inv, ubl.WithPeppol())
+		require.NoError(t, err)
+		assert.Equal(t, "9930", doc.Customer.Party.EndpointID.SchemeID)
+		assert.Equal(t, "DE282741168", doc.Customer.Party.EndpointID.Value)
+	})
+	t.Run("party endpoint", func(t *testing.T) {
+		inv := testPeppolInvoice(t)
+		inv.Customer.Endpoint = "0192:991825827"
+		doc, err := ubl.FromInvoice(inv, ubl.WithPeppol())
+		require.NoError(t, err)
+		assert.Equal(t, "0192", doc.Customer.Party.EndpointID.SchemeID)
+		assert.Equal(t, "991825827", doc.Customer.Party.EndpointID.Value)
+	})
+	t.Run("missing references", func(t *testing.T) {
+		inv := testPeppolInvoice(t)
+		inv.Ordering = nil
+		inv.Customer.TaxID = nil
+		inv.Customer.Inboxes = nil
+		inv.Payment.Instructions = &pay.Instructions{
+			Key:         pay.MeansKeyDirectDebit,
+			DirectDebit: &pay.DirectDebit{Creditor: "DE98ZZZ09999999999"},
+		}
+		require.NoError(t, inv.Calculate())
+		_, err := ubl.FromInvoice(inv, ubl.WithPeppol())
+		assert.ErrorIs(t, err, ubl.ErrPeppol)
+		assert.ErrorContains(t, err, "PEPPOL-EN16931-R003")
+		assert.ErrorContains(t, err, "PEPPOL-EN16931-R010")
+		assert.ErrorContains(t, err, "PEPPOL-EN16931-R061")
+	})
+	t.Run("invalid gln", func(t *testing.T) {
+		inv := testPeppolInvoice(t)
+		inv.Customer.Inboxes[0].Code = "4000001123453"
+		_, err := ubl.FromInvoice(inv, ubl.WithPeppol())
+		assert.ErrorContains(t, err, "PEPPOL-EN16931-CL008: buyer electronic address must be a valid GLN")
+	})
+}
+
+func TestValidatePeppol(t *testing.T) {
+	doc, err := ubl.FromInvoice(testPeppolInvoice(t))
+	require.NoError(t, err)
+	err = ubl.ValidatePeppol(doc)
+	assert.ErrorContains(t, err, "PEPPOL-EN16931-R001")
+	assert.ErrorContains(t, err, "PEPPOL-EN16931-R004")
+}
+
+func TestPeppolRoundTrip(t *testing.T) {
+	data, err := ubl.ConvertInvoice(testPeppolInvoice(t), ubl.WithPeppol())
+	require.NoError(t, err)
+	out, err := ubl.ParseInvoice(data)
+	require.NoError(t, err)
+	assert.Empty(t, out.Customer.Inboxes)
+	assert.Equal(t, cbc.Code("0088"), out.Customer.Endpoint.Scheme())
+	assert.Equal(t, cbc.Code("PO-4321"), out.Ordering.Code)
+}
@@ -0,0 +1,135 @@
+// Package ubl converts GOBL invoices to and from OASIS UBL 2.1 Invoice and
+// CreditNote documents following the EN 16931 semantic model. Documents
+// may optionally be generated using the Peppol BIS Billing 3.0 profile
+// required by Peppol access points.
+package ubl
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/internal/xmlns"
+	"github.com/invopop/gobl/observe"
+)
+
+// Namespaces used in UBL 2.1 documents.
+const (
+	NamespaceInvoice    = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	NamespaceCreditNote = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	NamespaceCAC        = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	NamespaceCBC        = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+)
+
+// CustomizationEN16931 is the customization ID used to indicate
+// compliance with the EN 16931 semantic model.
+const CustomizationEN16931 = convert.GuidelineEN16931
+
+// Root element names.
+const (
+	rootInvoice    = "Invoice"
+	rootCreditNote = "CreditNote"
+)
+
+var prefixes = xmlns.Prefixes{
+	NamespaceInvoice:    "",
+	NamespaceCreditNote: "",
+	NamespaceCAC:        "cac",
+	NamespaceCBC:        "cbc",
+}
+
+// Option is used to customize the document generated from an invoice.
+type Option func(*options)
+
+type options struct {
+	customizationID string
+	profileID       string
+	peppol          bool
+}
+
+// WithCustomizationID overrides the customization ID determined from the
+// invoice's addons.
+func WithCustomizationID(id string) Option {
+	return func(o *options) {
+		o.customizationID = id
+	}
+}
+
+// WithProfileID sets the business process profile ID of the document.
+func WithProfileID(id string) Option {
+	return func(o *options) {
+		o.profileID = id
+	}
+}
+
+// IsCreditNote returns true if the document is a UBL CreditNote.
+func (d *Document) IsCreditNote() bool {
+	return d.XMLName.Local == rootCreditNote
+}
+
+// Lines provides the invoice or credit note lines.
+func (d *Document) Lines() []*Line {
+	if d.IsCreditNote() {
+		return d.CreditNoteLines
+	}
+	return d.InvoiceLines
+}
+
+// Bytes provides the indented XML representation of the document.
+func (d *Document) Bytes() ([]byte, error) {
+	return xmlns.Marshal(d)
+}
+
+// ConvertInvoice is a convenience method to generate the UBL XML for the
+// calculated invoice.
+func ConvertInvoice(inv *bill.Invoice, opts ...Option) (out []byte, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpExport, "ubl")
+	defer func() { s.End(inv, err) }()
+	doc, err := FromInvoice(inv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+// Parse decodes the UBL Invoice or CreditNote XML data.
+func Parse(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := xmlns.Unmarshal(data, prefixes, doc); err != nil {
+		return nil, fmt.Errorf("parsing ubl: %w", err)
+	}
+	switch doc.XMLName.Local {
+	case rootInvoice, rootCreditNote:
+	default:
+		return nil, fmt.Errorf("parsing ubl: unexpected root element '%s'", doc.XMLName.Local)
+	}
+	return doc, nil
+}
+
+// ParseInvoice is a convenience method to parse the UBL XML data and
+// convert it into a calculated GOBL invoice.
+func ParseInvoice(data []byte) (inv *bill.Invoice, err error) {
+	s := observe.StartConversion(context.Background(), observe.OpImport, "ubl")
+	defer func() { s.End(inv, err) }()
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToInvoice()
+}
+
+func newDocument(credit bool) *Document {
+	doc := &Document{
+		XMLNS: NamespaceInvoice,
+		CACNS: NamespaceCAC,
+		CBCNS: NamespaceCBC,
+	}
+	doc.XMLName = xml.Name{Local: rootInvoice}
+	if credit {
+		doc.XMLName = xml.Name{Local: rootCreditNote}
+		doc.XMLNS = NamespaceCreditNote
+	}
+	return doc
+}
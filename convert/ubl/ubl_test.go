@@ -0,0 +1,280 @@
+package ubl_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/addons/eu/en16931"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/catalogues/untdid"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/convert"
+	"github.com/invopop/gobl/convert/ubl"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/observe"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Addons:    tax.WithAddons(en16931.V2017),
+		Series:    "SAMPLE",
+		Code:      "001",
+		Currency:  "EUR",
+		IssueDate: cal.MakeDate(2024, 2, 13),
+		Supplier: &org.Party{
+			Name:  "Provide One GmbH",
+			TaxID: &tax.Identity{Country: "DE", Code: "111111125"},
+			Inboxes: []*org.Inbox{
+				{Scheme: "0088", Code: "4000001123452"},
+			},
+			Addresses: []*org.Address{
+				{
+					Street:   "Dietmar-Hopp-Allee",
+					Locality: "Walldorf",
+					Code:     "69190",
+					Country:  "DE",
+				},
+			},
+			Emails: []*org.Email{{Address: "billing@example.com"}},
+		},
+		Customer: &org.Party{
+			Name:  "Sample Consumer",
+			TaxID: &tax.Identity{Country: "DE", Code: "282741168"},
+			Addresses: []*org.Address{
+				{
+					Street:   "Werner-Heisenberg-Allee",
+					Locality: "München",
+					Code:     "80939",
+					Country:  "DE",
+				},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(20, 0),
+				Item: &org.Item{
+					Name:  "Development services",
+					Price: num.NewAmount(9000, 2),
+					Unit:  org.UnitHour,
+				},
+				Discounts: []*bill.LineDiscount{
+					{Percent: num.NewPercentage(10, 2), Reason: "Special discount"},
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "Training materials",
+					Price: num.NewAmount(5000, 2),
+				},
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Key: tax.KeyExempt, Ext: tax.Extensions{"cef-vatex": "VATEX-EU-132"}},
+				},
+			},
+		},
+		Charges: []*bill.Charge{
+			{
+				Reason: "Handling",
+				Amount: num.MakeAmount(1000, 2),
+				Taxes: tax.Set{
+					{Category: tax.CategoryVAT, Rate: tax.RateGeneral},
+				},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Terms: &pay.Terms{
+				DueDates: []*pay.DueDate{
+					{Date: cal.NewDate(2024, 3, 13), Percent: num.NewPercentage(100, 2)},
+				},
+			},
+			Instructions: &pay.Instructions{
+				Key: "credit-transfer+sepa",
+				CreditTransfer: []*pay.CreditTransfer{
+					{IBAN: "DE89370400440532013000", Name: "Random Bank Co.", BIC: "COBADEFFXXX"},
+				},
+			},
+		},
+		Notes: []*org.Note{{Text: "Thank you for your business."}},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func TestFromInvoice(t *testing.T) {
+	t.Run("standard invoice", func(t *testing.T) {
+		inv := testInvoice(t)
+		doc, err := ubl.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.False(t, doc.IsCreditNote())
+		assert.Equal(t, "SAMPLE-001", doc.ID)
+		assert.Equal(t, "380", doc.InvoiceTypeCode)
+		assert.Equal(t, ubl.CustomizationEN16931, doc.CustomizationID)
+		assert.Equal(t, "2024-03-13", doc.DueDate)
+		assert.Equal(t, "DE111111125", doc.Supplier.Party.TaxSchemes[0].CompanyID)
+		assert.Equal(t, "0088", doc.Supplier.Party.EndpointID.SchemeID)
+		require.Len(t, doc.InvoiceLines, 2)
+		assert.Equal(t, "HUR", doc.InvoiceLines[0].InvoicedQuantity.UnitCode)
+		assert.Equal(t, "1620.00", doc.InvoiceLines[0].LineExtensionAmount.Value)
+		assert.Equal(t, "S", doc.InvoiceLines[0].Item.ClassifiedTaxCategory.ID)
+		assert.Equal(t, "E", doc.InvoiceLines[1].Item.ClassifiedTaxCategory.ID)
+		require.Len(t, doc.TaxTotals, 1)
+		assert.Len(t, doc.TaxTotals[0].TaxSubtotals, 2)
+		assert.Equal(t, "VATEX-EU-132", doc.TaxTotals[0].TaxSubtotals[1].TaxCategory.TaxExemptionReasonCode)
+		require.Len(t, doc.PaymentMeans, 1)
+		assert.Equal(t, "58", doc.PaymentMeans[0].PaymentMeansCode)
+		assert.Equal(t, "COBADEFFXXX", doc.PaymentMeans[0].PayeeFinancialAccount.FinancialInstitutionBranch.ID)
+		assert.Equal(t, inv.Totals.Payable.String(), doc.LegalMonetaryTotal.PayableAmount.Value)
+	})
+	t.Run("credit note", func(t *testing.T) {
+		inv := testInvoice(t)
+		inv.Type = bill.InvoiceTypeCreditNote
+		inv.Tax.Ext = inv.Tax.Ext.Delete(untdid.ExtKeyDocumentType)
+		inv.Preceding = []*org.DocumentRef{
+			{Series: "SAMPLE", Code: "000", IssueDate: cal.NewDate(2024, 1, 10)},
+		}
+		require.NoError(t, inv.Calculate())
+		doc, err := ubl.FromInvoice(inv)
+		require.NoError(t, err)
+		assert.True(t, doc.IsCreditNote())
+		assert.Equal(t, "381", doc.CreditNoteTypeCode)
+		assert.Empty(t, doc.InvoiceLines)
+		assert.Len(t, doc.CreditNoteLines, 2)
+		assert.NotNil(t, doc.CreditNoteLines[0].CreditedQuantity)
+		assert.Equal(t, "SAMPLE-000", doc.BillingReferences[0].InvoiceDocumentReference.ID)
+
+		data, err := doc.Bytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `<CreditNote xmlns="`+ubl.NamespaceCreditNote+`"`)
+	})
+	t.Run("not calculated", func(t *testing.T) {
+		_, err := ubl.FromInvoice(&bill.Invoice{})
+		assert.ErrorIs(t, err, convert.ErrNotCalculated)
+	})
+	t.Run("options", func(t *testing.T) {
+		doc, err := ubl.FromInvoice(testInvoice(t),
+			ubl.WithCustomizationID("urn:custom"),
+			ubl.WithProfileID("urn:profile"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "urn:custom", doc.CustomizationID)
+		assert.Equal(t, "urn:profile", doc.ProfileID)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	inv := testInvoice(t)
+	data, err := ubl.ConvertInvoice(inv)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "<?xml"))
+
+	out, err := ubl.ParseInvoice(data)
+	require.NoError(t, err)
+	assert.Equal(t, cbc.Code("SAMPLE-001"), out.Code)
+	assert.Equal(t, bill.InvoiceTypeStandard, out.Type)
+	assert.Equal(t, inv.IssueDate, out.IssueDate)
+	assert.Equal(t, inv.Supplier.Name, out.Supplier.Name)
+	assert.Equal(t, inv.Supplier.TaxID.Code, out.Supplier.TaxID.Code)
+	assert.Equal(t, inv.Customer.TaxID.Country, out.Customer.TaxID.Country)
+	assert.Equal(t, cbc.SchemeCode("0088:4000001123452"), out.Supplier.Endpoint)
+	require.Len(t, out.Lines, 2)
+	assert.Equal(t, org.UnitHour, out.Lines[0].Item.Unit)
+	assert.Equal(t, "10%", out.Lines[0].Discounts[0].Percent.String())
+	assert.Equal(t, tax.KeyExempt, out.Lines[1].Taxes[0].Key)
+	require.Len(t, out.Charges, 1)
+	assert.Equal(t, "Handling", out.Charges[0].Reason)
+	assert.Equal(t, "DE89370400440532013000", out.Payment.Instructions.CreditTransfer[0].IBAN)
+	assert.Equal(t, "2024-03-13", out.Payment.Terms.DueDates[0].Date.String())
+
+	assert.Equal(t, inv.Totals.Sum.String(), out.Totals.Sum.String())
+	assert.Equal(t, inv.Totals.Tax.String(), out.Totals.Tax.String())
+	assert.Equal(t, inv.Totals.TotalWithTax.String(), out.Totals.TotalWithTax.String())
+	assert.Equal(t, inv.Totals.Payable.String(), out.Totals.Payable.String())
+}
+
+func TestParse(t *testing.T) {
+	t.Run("alternative prefixes", func(t *testing.T) {
+		data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ubl:Invoice xmlns:ubl="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	xmlns:a="urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	xmlns="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+	<ID>INV-123</ID>
+	<IssueDate>2024-02-13</IssueDate>
+	<InvoiceTypeCode>380</InvoiceTypeCode>
+	<DocumentCurrencyCode>EUR</DocumentCurrencyCode>
+	<a:AccountingSupplierParty><a:Party>
+		<a:PostalAddress><a:Country><IdentificationCode>NL</IdentificationCode></a:Country></a:PostalAddress>
+		<a:PartyTaxScheme><CompanyID>NL000099998B57</CompanyID><a:TaxScheme><ID>VAT</ID></a:TaxScheme></a:PartyTaxScheme>
+		<a:PartyLegalEntity><RegistrationName>Seller BV</RegistrationName></a:PartyLegalEntity>
+	</a:Party></a:AccountingSupplierParty>
+	<a:LegalMonetaryTotal>
+		<LineExtensionAmount currencyID="EUR">100.00</LineExtensionAmount>
+		<TaxExclusiveAmount currencyID="EUR">100.00</TaxExclusiveAmount>
+		<TaxInclusiveAmount currencyID="EUR">121.00</TaxInclusiveAmount>
+		<PayableAmount currencyID="EUR">121.00</PayableAmount>
+	</a:LegalMonetaryTotal>
+	<a:InvoiceLine>
+		<ID>1</ID>
+		<InvoicedQuantity unitCode="C62">2</InvoicedQuantity>
+		<LineExtensionAmount currencyID="EUR">100.00</LineExtensionAmount>
+		<a:Item>
+			<Name>Widget</Name>
+			<a:ClassifiedTaxCategory><ID>S</ID><Percent>21</Percent><a:TaxScheme><ID>VAT</ID></a:TaxScheme></a:ClassifiedTaxCategory>
+		</a:Item>
+		<a:Price><PriceAmount currencyID="EUR">50.00</PriceAmount></a:Price>
+	</a:InvoiceLine>
+</ubl:Invoice>`)
+		inv, err := ubl.ParseInvoice(data)
+		require.NoError(t, err)
+		assert.Equal(t, cbc.Code("INV-123"), inv.Code)
+		assert.Equal(t, "Seller BV", inv.Supplier.Name)
+		assert.Equal(t, cbc.Code("000099998B57"), inv.Supplier.TaxID.Code)
+		assert.Equal(t, org.UnitOne, inv.Lines[0].Item.Unit)
+		assert.Equal(t, "121.00", inv.Totals.Payable.String())
+	})
+	t.Run("unexpected root", func(t *testing.T) {
+		_, err := ubl.Parse([]byte(`<Order xmlns="urn:oasis:names:specification:ubl:schema:xsd:Order-2"></Order>`))
+		assert.ErrorContains(t, err, "unexpected root element 'Order'")
+	})
+	t.Run("invalid date", func(t *testing.T) {
+		_, err := ubl.ParseInvoice([]byte(`<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+	<cbc:IssueDate>13/02/2024</cbc:IssueDate></Invoice>`))
+		assert.ErrorContains(t, err, "issue date: invalid date '13/02/2024'")
+	})
+}
+
+func TestObserve(t *testing.T) {
+	var events []*observe.Event
+	t.Cleanup(observe.Register(observe.HookFunc(func(_ context.Context, ev *observe.Event) {
+		events = append(events, ev)
+	})))
+	inv := testInvoice(t)
+	data, err := ubl.ConvertInvoice(inv)
+	require.NoError(t, err)
+	_, err = ubl.ParseInvoice(data)
+	require.NoError(t, err)
+
+	var conv []*observe.Event
+	for _, ev := range events {
+		if ev.Format != "" {
+			conv = append(conv, ev)
+		}
+	}
+	require.Len(t, conv, 2)
+	assert.Equal(t, observe.OpExport, conv[0].Op)
+	assert.Equal(t, observe.OpImport, conv[1].Op)
+	assert.Equal(t, "ubl", conv[1].Format)
+	assert.Equal(t, "DE", conv[1].Regime.String())
+}
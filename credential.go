@@ -0,0 +1,188 @@
+package gobl
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/invopop/gobl/dsig"
+)
+
+// Values used in the W3C Verifiable Credential representation of
+// envelopes.
+const (
+	// CredentialContext is the base JSON-LD context of the W3C Verifiable
+	// Credentials Data Model v1.1.
+	CredentialContext = "https://www.w3.org/2018/credentials/v1"
+	// CredentialType is required in the types of all credentials.
+	CredentialType = "VerifiableCredential"
+	// CredentialTypeEnvelope identifies credentials whose subject is a
+	// signed GOBL envelope.
+	CredentialTypeEnvelope = "GOBLEnvelope"
+
+	credentialJWTType  = "JWT"
+	credentialIDPrefix = "urn:uuid:"
+)
+
+// Credential is the W3C Verifiable Credential containing a signed envelope,
+// which is included in the claims of a JWT-VC.
+type Credential struct {
+	// JSON-LD contexts of the credential.
+	Context []string `json:"@context"`
+	// URI identifying the credential, based on the envelope's UUID.
+	ID string `json:"id,omitempty"`
+	// Types of the credential.
+	Type []string `json:"type"`
+	// URI of the issuer that signed the credential.
+	Issuer string `json:"issuer,omitempty"`
+	// Date and time the credential was issued.
+	IssuanceDate string `json:"issuanceDate,omitempty"`
+	// Subject of the credential containing the envelope.
+	Subject *CredentialSubject `json:"credentialSubject"`
+}
+
+// CredentialSubject contains the envelope exactly as it was signed.
+type CredentialSubject struct {
+	// URI identifying the envelope.
+	ID string `json:"id"`
+	// Signed envelope.
+	Envelope *Envelope `json:"envelope"`
+}
+
+// credentialClaims are the registered JWT claims defined for JWT-VCs.
+type credentialClaims struct {
+	Issuer     string      `json:"iss,omitempty"`
+	Subject    string      `json:"sub,omitempty"`
+	ID         string      `json:"jti,omitempty"`
+	NotBefore  int64       `json:"nbf,omitempty"`
+	IssuedAt   int64       `json:"iat,omitempty"`
+	Credential *Credential `json:"vc"`
+}
+
+// CredentialOption is used to customize the credential issued for an
+// envelope.
+type CredentialOption func(*credentialOptions)
+
+type credentialOptions struct {
+	issuer string
+	issued time.Time
+	signer []dsig.SignerOption
+}
+
+// WithCredentialIssuer sets the URI of the issuer, usually a DID, which
+// defaults to a URN made from the key's ID.
+func WithCredentialIssuer(iss string) CredentialOption {
+	return func(o *credentialOptions) {
+		o.issuer = iss
+	}
+}
+
+// WithCredentialTime sets the time the credential was issued, which
+// defaults to the current time.
+func WithCredentialTime(t time.Time) CredentialOption {
+	return func(o *credentialOptions) {
+		o.issued = t
+	}
+}
+
+// WithCredentialJKU adds the URL of the key set used to verify the
+// credential to the JWT header.
+func WithCredentialJKU(jku string) CredentialOption {
+	return func(o *credentialOptions) {
+		o.signer = append(o.signer, dsig.WithJKU(jku))
+	}
+}
+
+// IssueCredential expresses the signed envelope as a W3C Verifiable
+// Credential using the JWT encoding, signed with the key provided. The
+// envelope, including its original signatures, is kept intact as the
+// credential's subject, so that it can be extracted and verified by
+// GOBL tools, while wallets and credential infrastructure only need to
+// check the JWT.
+func (e *Envelope) IssueCredential(key *dsig.PrivateKey, opts ...CredentialOption) (*dsig.Signature, error) {
+	if !e.Signed() {
+		return nil, ErrSignature.WithReason("envelope not signed")
+	}
+	o := &credentialOptions{issued: time.Now()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.issuer == "" {
+		o.issuer = credentialIDPrefix + key.ID()
+	}
+	env := *e
+	env.hooks = nil
+	id := credentialIDPrefix + e.Head.UUID.String()
+	issued := o.issued.UTC().Truncate(time.Second)
+	claims := &credentialClaims{
+		Issuer:    o.issuer,
+		Subject:   id,
+		ID:        id,
+		NotBefore: issued.Unix(),
+		IssuedAt:  issued.Unix(),
+		Credential: &Credential{
+			Context:      []string{CredentialContext},
+			ID:           id,
+			Type:         []string{CredentialType, CredentialTypeEnvelope},
+			Issuer:       o.issuer,
+			IssuanceDate: issued.Format(time.RFC3339),
+			Subject: &CredentialSubject{
+				ID:       id,
+				Envelope: &env,
+			},
+		},
+	}
+	signer := append([]dsig.SignerOption{dsig.WithType(credentialJWTType)}, o.signer...)
+	sig, err := dsig.NewSignature(key, claims, signer...)
+	if err != nil {
+		return nil, ErrSignature.WithCause(err)
+	}
+	return sig, nil
+}
+
+// ParseCredential extracts the envelope from a JWT-VC issued with
+// IssueCredential. If keys are provided, the credential must have been
+// signed by one of them. The envelope's own signatures are checked against
+// its header, and the document against the header's digest, but the
+// envelope is not validated.
+func ParseCredential(data string, keys ...*dsig.PublicKey) (*Envelope, error) {
+	sig, err := dsig.ParseSignature(data)
+	if err != nil {
+		return nil, ErrSignature.WithCause(err)
+	}
+	claims := new(credentialClaims)
+	if len(keys) == 0 {
+		err = sig.UnsafePayload(claims)
+	} else {
+		err = errors.New("no key match found")
+		for _, k := range keys {
+			if sig.VerifyPayload(k, claims) == nil {
+				err = nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, ErrSignature.WithCause(err)
+	}
+	vc := claims.Credential
+	if vc == nil || !slices.Contains(vc.Type, CredentialTypeEnvelope) {
+		return nil, ErrUnmarshal.WithReason("not a GOBL envelope credential")
+	}
+	if vc.Subject == nil || vc.Subject.Envelope == nil {
+		return nil, ErrNoDocument
+	}
+	env := vc.Subject.Envelope
+	if env.Head == nil {
+		return nil, ErrValidation.WithReason("header required")
+	}
+	if err := env.Verify(); err != nil {
+		return nil, err
+	}
+	if !env.Encrypted() {
+		if err := env.verifyDigest(); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
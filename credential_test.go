@@ -0,0 +1,120 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeIssueCredential(t *testing.T) {
+	issued := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	t.Run("issues JWT-VC", func(t *testing.T) {
+		env := testRedactableEnvelope(t)
+		sig, err := env.IssueCredential(testKey,
+			gobl.WithCredentialIssuer("did:web:example.com"),
+			gobl.WithCredentialTime(issued),
+			gobl.WithCredentialJKU("https://example.com/.well-known/jwks.json"),
+		)
+		require.NoError(t, err)
+		sig, err = dsig.ParseSignature(sig.String())
+		require.NoError(t, err)
+		assert.Equal(t, "JWT", sig.Type())
+		assert.Equal(t, testKey.ID(), sig.KeyID())
+		assert.Equal(t, "https://example.com/.well-known/jwks.json", sig.JKU())
+
+		claims := make(map[string]any)
+		require.NoError(t, sig.VerifyPayload(testKey.Public(), &claims))
+		id := "urn:uuid:" + env.Head.UUID.String()
+		assert.Equal(t, "did:web:example.com", claims["iss"])
+		assert.Equal(t, id, claims["sub"])
+		assert.Equal(t, id, claims["jti"])
+		assert.EqualValues(t, issued.Unix(), claims["nbf"])
+
+		vc := claims["vc"].(map[string]any)
+		assert.Equal(t, []any{gobl.CredentialContext}, vc["@context"])
+		assert.Equal(t, []any{"VerifiableCredential", "GOBLEnvelope"}, vc["type"])
+		assert.Equal(t, "2024-06-01T12:00:00Z", vc["issuanceDate"])
+		subject := vc["credentialSubject"].(map[string]any)
+		assert.Equal(t, id, subject["id"])
+		envelope := subject["envelope"].(map[string]any)
+		assert.NotContains(t, envelope, "salt")
+		assert.Len(t, envelope["sigs"], 1)
+		assert.NotEmpty(t, env.Salt, "original envelope unchanged")
+	})
+	t.Run("default issuer", func(t *testing.T) {
+		env := testRedactableEnvelope(t)
+		sig, err := env.IssueCredential(testKey)
+		require.NoError(t, err)
+		claims := make(map[string]any)
+		require.NoError(t, sig.UnsafePayload(&claims))
+		assert.Equal(t, "urn:uuid:"+testKey.ID(), claims["iss"])
+	})
+	t.Run("requires signatures", func(t *testing.T) {
+		env := gobl.NewEnvelope()
+		require.NoError(t, env.Insert(&note.Message{Content: "Test Message"}))
+		_, err := env.IssueCredential(testKey)
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+		assert.ErrorContains(t, err, "envelope not signed")
+	})
+}
+
+func TestParseCredential(t *testing.T) {
+	env := testRedactableEnvelope(t)
+	issuer := dsig.NewES256Key()
+	sig, err := env.IssueCredential(issuer)
+	require.NoError(t, err)
+	data := sig.String()
+
+	t.Run("with issuer key", func(t *testing.T) {
+		out, err := gobl.ParseCredential(data, testKey.Public(), issuer.Public())
+		require.NoError(t, err)
+		assert.Equal(t, env.Head.UUID, out.Head.UUID)
+		assert.Empty(t, out.Salt)
+		require.NoError(t, out.Verify(testKey.Public()))
+		inv, ok := out.Extract().(*bill.Invoice)
+		require.True(t, ok)
+		assert.Equal(t, "SAMPLE-001", inv.Code.String())
+	})
+	t.Run("without keys", func(t *testing.T) {
+		out, err := gobl.ParseCredential(data)
+		require.NoError(t, err)
+		assert.Equal(t, env.Head.Digest.Value, out.Head.Digest.Value)
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := gobl.ParseCredential(data, testKey.Public())
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+		assert.ErrorContains(t, err, "no key match found")
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		_, err := gobl.ParseCredential("foo")
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+	})
+	t.Run("not a credential", func(t *testing.T) {
+		_, err := gobl.ParseCredential(env.Signatures[0].String())
+		assert.ErrorIs(t, err, gobl.ErrUnmarshal)
+	})
+	t.Run("modified envelope", func(t *testing.T) {
+		claims := make(map[string]any)
+		require.NoError(t, sig.UnsafePayload(&claims))
+		subject := claims["vc"].(map[string]any)["credentialSubject"].(map[string]any)
+		doc := subject["envelope"].(map[string]any)["doc"].(map[string]any)
+		doc["code"] = "999"
+		data, err := json.Marshal(claims)
+		require.NoError(t, err)
+		forged, err := dsig.NewSignature(issuer, json.RawMessage(data))
+		require.NoError(t, err)
+		_, err = gobl.ParseCredential(forged.String(), issuer.Public())
+		assert.ErrorIs(t, err, gobl.ErrDigest)
+	})
+	t.Run("compact form", func(t *testing.T) {
+		assert.Len(t, strings.Split(data, "."), 3)
+	})
+}
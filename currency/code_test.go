@@ -31,6 +31,6 @@ func TestCodeJSONSchema(t *testing.T) {
 	s := c.JSONSchema()
 	assert.Equal(t, "Currency Code", s.Title)
 	assert.Equal(t, "string", s.Type)
-	assert.Len(t, s.OneOf, 180)
+	assert.Len(t, s.OneOf, 205)
 	assert.Equal(t, currency.USD, s.OneOf[0].Const)
 }
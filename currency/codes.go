@@ -364,4 +364,54 @@ const (
 	CNH Code = "CNH"
 	// USD Coin (USDC)
 	USDC Code = "USDC"
+	// Croatian Kuna (kn)
+	HRK Code = "HRK"
+	// Lithuanian Litas (Lt)
+	LTL Code = "LTL"
+	// Latvian Lats (Ls)
+	LVL Code = "LVL"
+	// Estonian Kroon (kr)
+	EEK Code = "EEK"
+	// Cypriot Pound (£)
+	CYP Code = "CYP"
+	// Maltese Lira (Lm)
+	MTL Code = "MTL"
+	// Slovenian Tolar (SIT)
+	SIT Code = "SIT"
+	// Greek Drachma (₯)
+	GRD Code = "GRD"
+	// Austrian Schilling (S)
+	ATS Code = "ATS"
+	// Belgian Franc (fr.)
+	BEF Code = "BEF"
+	// German Mark (DM)
+	DEM Code = "DEM"
+	// Spanish Peseta (Pta)
+	ESP Code = "ESP"
+	// Finnish Markka (mk)
+	FIM Code = "FIM"
+	// French Franc (F)
+	FRF Code = "FRF"
+	// Irish Pound (£)
+	IEP Code = "IEP"
+	// Italian Lira (₤)
+	ITL Code = "ITL"
+	// Luxembourgish Franc (F)
+	LUF Code = "LUF"
+	// Dutch Guilder (ƒ)
+	NLG Code = "NLG"
+	// Portuguese Escudo ($)
+	PTE Code = "PTE"
+	// Ghanaian Cedi (1967) (₵)
+	GHC Code = "GHC"
+	// Mauritanian Ouguiya (1973) (UM)
+	MRO Code = "MRO"
+	// Mozambican Metical (1980) (MTn)
+	MZM Code = "MZM"
+	// Romanian Leu (1952) (Lei)
+	ROL Code = "ROL"
+	// Turkish Lira (1922) (TL)
+	TRL Code = "TRL"
+	// Venezuelan Bolívar Fuerte (Bs.F)
+	VEF Code = "VEF"
 )
\ No newline at end of file
@@ -59,6 +59,9 @@ type Def struct {
 	// NumeralSystem defines how numbers should be printed out, by default this
 	// is 'western'.
 	NumeralSystem num.NumeralSystem `json:"numeral_system"`
+	// Replacement provides details of the currency that superseded this one,
+	// if any, so that historical documents can still be interpreted.
+	Replacement *Replacement `json:"replacement,omitempty"`
 }
 
 // FormatOption defines how to configure the formatter for common
@@ -123,3 +123,40 @@ func CanConvertInto(rates []*ExchangeRate, to Code) validation.Rule {
 		to:    to,
 	}
 }
+
+// DetectConflictingExchangeRates checks a list of exchange rates to ensure
+// that no currency pair is defined more than once for the same moment with
+// different amounts, and that no rate tries to convert a currency into itself,
+// so that cross rates can be determined consistently.
+var DetectConflictingExchangeRates = validation.By(detectConflictingExchangeRates)
+
+func detectConflictingExchangeRates(list any) error {
+	rates, ok := list.([]*ExchangeRate)
+	if !ok {
+		return nil
+	}
+	for i, r := range rates {
+		if r == nil {
+			continue
+		}
+		if r.From != CodeEmpty && r.From == r.To {
+			return fmt.Errorf("exchange rate from '%v' into itself", r.From)
+		}
+		for _, r2 := range rates[i+1:] {
+			if r2 == nil {
+				continue
+			}
+			if r.From == r2.From && r.To == r2.To && sameExchangeRateTime(r.At, r2.At) && !r.Amount.Equals(r2.Amount) {
+				return fmt.Errorf("conflicting exchange rates for '%v' to '%v'", r.From, r.To)
+			}
+		}
+	}
+	return nil
+}
+
+func sameExchangeRateTime(a, b *cal.DateTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,45 @@
+package currency
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/num"
+)
+
+// MinorUnits converts the amount into an integer number of the currency's
+// minor units, such as cents for EUR, fils for KWD (3 decimal places), or
+// whole yen for JPY, as typically expected by payment gateway APIs. Amounts
+// with more precision than the currency allows will be rounded.
+func (d *Def) MinorUnits(a num.Amount) int64 {
+	return d.Rescale(a).Value()
+}
+
+// FromMinorUnits builds a new amount from an integer number of the
+// currency's minor units, with the precision of the currency.
+func (d *Def) FromMinorUnits(v int64) num.Amount {
+	return num.MakeAmount(v, d.Subunits)
+}
+
+// MinorUnits provides the amount as an integer number of the currency's
+// minor units. An error will be returned if the currency is not defined.
+func (a *Amount) MinorUnits() (int64, error) {
+	d := a.Currency.Def()
+	if d == nil {
+		return 0, fmt.Errorf("currency code %s not defined", a.Currency)
+	}
+	return d.MinorUnits(a.Value), nil
+}
+
+// AmountFromMinorUnits builds a new currency amount from an integer number of
+// minor units in the provided currency, for example when parsing responses
+// from payment gateways.
+func AmountFromMinorUnits(c Code, v int64) (*Amount, error) {
+	d := c.Def()
+	if d == nil {
+		return nil, fmt.Errorf("currency code %s not defined", c)
+	}
+	return &Amount{
+		Currency: c,
+		Value:    d.FromMinorUnits(v),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package currency_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefMinorUnits(t *testing.T) {
+	tests := []struct {
+		code   currency.Code
+		amount num.Amount
+		units  int64
+	}{
+		{currency.EUR, num.MakeAmount(12345, 2), 12345},
+		{currency.EUR, num.MakeAmount(123, 0), 12300},
+		{currency.EUR, num.MakeAmount(123456, 3), 12346},
+		{currency.KWD, num.MakeAmount(12345, 2), 123450},
+		{currency.JPY, num.MakeAmount(12345, 0), 12345},
+		{currency.JPY, num.MakeAmount(12350, 2), 124},
+		{currency.EUR, num.MakeAmount(-12345, 2), -12345},
+	}
+	for _, ts := range tests {
+		t.Run(ts.code.String()+" "+ts.amount.String(), func(t *testing.T) {
+			assert.Equal(t, ts.units, ts.code.Def().MinorUnits(ts.amount))
+		})
+	}
+}
+
+func TestDefFromMinorUnits(t *testing.T) {
+	assert.Equal(t, "123.45", currency.EUR.Def().FromMinorUnits(12345).String())
+	assert.Equal(t, "12.345", currency.KWD.Def().FromMinorUnits(12345).String())
+	assert.Equal(t, "12345", currency.JPY.Def().FromMinorUnits(12345).String())
+}
+
+func TestAmountMinorUnits(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		a := &currency.Amount{Currency: currency.USD, Value: num.MakeAmount(1999, 2)}
+		v, err := a.MinorUnits()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1999), v)
+	})
+	t.Run("undefined currency", func(t *testing.T) {
+		a := &currency.Amount{Currency: "FOO", Value: num.MakeAmount(1999, 2)}
+		_, err := a.MinorUnits()
+		assert.ErrorContains(t, err, "currency code FOO not defined")
+	})
+}
+
+func TestAmountFromMinorUnits(t *testing.T) {
+	a, err := currency.AmountFromMinorUnits(currency.BHD, 1500)
+	require.NoError(t, err)
+	assert.Equal(t, currency.BHD, a.Currency)
+	assert.Equal(t, "1.500", a.Value.String())
+
+	_, err = currency.AmountFromMinorUnits("FOO", 1500)
+	assert.ErrorContains(t, err, "currency code FOO not defined")
+}
@@ -0,0 +1,93 @@
+package currency
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/num"
+)
+
+// maxReplacementDepth limits the number of replacements that will be
+// followed when looking for the current currency, to prevent loops in
+// badly defined source data.
+const maxReplacementDepth = 5
+
+// Replacement describes how a currency was superseded by another, either
+// as the result of adopting a common currency like the Euro, or due to
+// a redenomination. The conversion rate is fixed by law and should always
+// be applied by dividing amounts in the old currency, as inverted rates
+// may introduce rounding errors.
+type Replacement struct {
+	// Code of the currency that replaced this one.
+	Code Code `json:"code"`
+	// Date from which the new currency came into effect.
+	Date cal.Date `json:"date"`
+	// Rate is the number of units of the old currency that are equivalent
+	// to a single unit of the new currency.
+	Rate num.Amount `json:"rate"`
+}
+
+// Convert takes an amount in the replaced currency and converts it into
+// the new currency using the fixed conversion rate. The result will be
+// rounded to the precision of the new currency.
+func (r *Replacement) Convert(amount num.Amount) num.Amount {
+	d := r.Code.Def()
+	if d == nil {
+		return amount.Divide(r.Rate)
+	}
+	a := amount.RescaleUp(d.Subunits)
+	return a.Divide(r.Rate).Rescale(d.Subunits)
+}
+
+// Replaced returns true if the currency has been superseded by another.
+func (d *Def) Replaced() bool {
+	return d.Replacement != nil
+}
+
+// ReplacedOn returns true if the currency had already been superseded by
+// another on the provided date. This is useful to determine if a currency
+// was legal tender when an archived document was issued.
+func (d *Def) ReplacedOn(date cal.Date) bool {
+	if d.Replacement == nil {
+		return false
+	}
+	return !date.Before(d.Replacement.Date.Date)
+}
+
+// Current follows the chain of replacements for the currency code and
+// provides the code of the currency that is currently in use. Codes that
+// have not been replaced will be returned as-is.
+func (c Code) Current() Code {
+	for i := 0; i < maxReplacementDepth; i++ {
+		d := c.Def()
+		if d == nil || d.Replacement == nil {
+			break
+		}
+		c = d.Replacement.Code
+	}
+	return c
+}
+
+// ConvertReplaced converts the amount in a currency that has since been
+// replaced into the currency currently in use, following the chain of
+// replacements and applying each of the official fixed conversion rates.
+// The resulting currency code is returned alongside the converted amount.
+// Currencies that have not been replaced will return the original amount.
+func ConvertReplaced(from Code, amount num.Amount) (Code, num.Amount, error) {
+	d := from.Def()
+	if d == nil {
+		return from, amount, fmt.Errorf("currency code %s not defined", from)
+	}
+	for i := 0; d.Replacement != nil; i++ {
+		if i >= maxReplacementDepth {
+			return from, amount, fmt.Errorf("too many replacements for %s", from)
+		}
+		r := d.Replacement
+		if r.Code.Def() == nil {
+			return from, amount, fmt.Errorf("replacement currency code %s not defined", r.Code)
+		}
+		amount = r.Convert(amount)
+		d = r.Code.Def()
+	}
+	return d.ISOCode, amount, nil
+}
@@ -0,0 +1,100 @@
+package currency_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacementDefinitions(t *testing.T) {
+	d := currency.HRK.Def()
+	require.NotNil(t, d)
+	assert.Equal(t, "Croatian Kuna", d.Name)
+	require.NotNil(t, d.Replacement)
+	assert.Equal(t, currency.EUR, d.Replacement.Code)
+	assert.Equal(t, "2023-01-01", d.Replacement.Date.String())
+	assert.Equal(t, "7.53450", d.Replacement.Rate.String())
+	assert.True(t, d.Replaced())
+
+	assert.False(t, currency.EUR.Def().Replaced())
+
+	t.Run("all replacements defined", func(t *testing.T) {
+		for _, d := range currency.Definitions() {
+			if d.Replacement == nil {
+				continue
+			}
+			assert.NotNil(t, d.Replacement.Code.Def(), "replacement for %s", d.ISOCode)
+			assert.True(t, d.Replacement.Rate.IsPositive(), "rate for %s", d.ISOCode)
+			assert.False(t, d.Replacement.Date.IsZero(), "date for %s", d.ISOCode)
+		}
+	})
+}
+
+func TestDefReplacedOn(t *testing.T) {
+	d := currency.HRK.Def()
+	assert.False(t, d.ReplacedOn(cal.MakeDate(2022, 12, 31)))
+	assert.True(t, d.ReplacedOn(cal.MakeDate(2023, 1, 1)))
+	assert.True(t, d.ReplacedOn(cal.MakeDate(2024, 6, 1)))
+	assert.False(t, currency.EUR.Def().ReplacedOn(cal.MakeDate(2024, 6, 1)))
+}
+
+func TestCodeCurrent(t *testing.T) {
+	assert.Equal(t, currency.EUR, currency.HRK.Current())
+	assert.Equal(t, currency.EUR, currency.DEM.Current())
+	assert.Equal(t, currency.VES, currency.VEF.Current())
+	assert.Equal(t, currency.USD, currency.USD.Current())
+	assert.Equal(t, currency.Code("FOO"), currency.Code("FOO").Current())
+}
+
+func TestReplacementConvert(t *testing.T) {
+	t.Run("euro adoption", func(t *testing.T) {
+		r := currency.HRK.Def().Replacement
+		a := r.Convert(num.MakeAmount(100000, 2)) // 1000.00 HRK
+		assert.Equal(t, "132.72", a.String())
+	})
+	t.Run("from currency without subunits", func(t *testing.T) {
+		r := currency.ITL.Def().Replacement
+		a := r.Convert(num.MakeAmount(1000000, 0)) // 1.000.000 ITL
+		assert.Equal(t, "516.46", a.String())
+	})
+	t.Run("redenomination", func(t *testing.T) {
+		r := currency.VEF.Def().Replacement
+		a := r.Convert(num.MakeAmount(25000000, 2)) // 250.000,00 VEF
+		assert.Equal(t, "2.50", a.String())
+	})
+}
+
+func TestConvertReplaced(t *testing.T) {
+	t.Run("replaced", func(t *testing.T) {
+		c, a, err := currency.ConvertReplaced(currency.DEM, num.MakeAmount(1000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, currency.EUR, c)
+		assert.Equal(t, "5.11", a.String())
+	})
+	t.Run("not replaced", func(t *testing.T) {
+		c, a, err := currency.ConvertReplaced(currency.EUR, num.MakeAmount(1000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, currency.EUR, c)
+		assert.Equal(t, "10.00", a.String())
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, _, err := currency.ConvertReplaced(currency.Code("FOO"), num.MakeAmount(1000, 2))
+		assert.ErrorContains(t, err, "currency code FOO not defined")
+	})
+}
+
+func TestReplacementJSON(t *testing.T) {
+	r := &currency.Replacement{
+		Code: currency.EUR,
+		Date: cal.MakeDate(2023, 1, 1),
+		Rate: num.MakeAmount(753450, 5),
+	}
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"code":"EUR","date":"2023-01-01","rate":"7.53450"}`, string(data))
+}
@@ -0,0 +1,172 @@
+package currency
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/validation"
+)
+
+const (
+	// triangulationMinExp is the minimum number of decimal places that must be
+	// kept on intermediate amounts when triangulating through a base currency,
+	// as defined by the EU rules for conversions between national currencies.
+	triangulationMinExp uint32 = 3
+	// triangulationRateExp is the number of decimal places used for cross rates
+	// derived from two rates against a base currency, which matches the
+	// precision used by the ECB for its published reference rates.
+	triangulationRateExp uint32 = 6
+)
+
+// Triangulate will try to determine the exchange rate between the "from" and
+// "to" currencies using the rates each one has against a common base currency.
+// Rates against the base may be defined in either direction, so both "USD to
+// EUR" and "EUR to USD" are valid options when EUR is the base. A direct rate
+// will be returned as-is if available.
+//
+// Cross rates are calculated with 6 decimal places. When converting amounts,
+// prefer ConvertVia which follows the official triangulation rounding rules
+// instead of using a rounded cross rate.
+func Triangulate(rates []*ExchangeRate, base, from, to Code) (*ExchangeRate, error) {
+	if rate := MatchExchangeRate(rates, from, to); rate != nil {
+		return rate, nil
+	}
+	r1, err := rateAmount(rates, from, base)
+	if err != nil {
+		return nil, err
+	}
+	r2, err := rateAmount(rates, base, to)
+	if err != nil {
+		return nil, err
+	}
+	return &ExchangeRate{
+		From:   from,
+		To:     to,
+		Amount: r1.RescaleUp(triangulationRateExp).Multiply(r2).Rescale(triangulationRateExp),
+	}, nil
+}
+
+// ConvertVia converts the amount from one currency into another by first
+// converting into the base currency, rounding the intermediate amount to no
+// fewer than three decimal places, and then converting the result into the
+// target currency. This follows the triangulation rules defined by the ECB
+// for conversions between currencies whose rates are only available against
+// the Euro. Rates defined against the base in the opposite direction will be
+// used to divide instead of multiply, so that inverted rates are never
+// rounded. Direct rates will be used if available.
+func ConvertVia(rates []*ExchangeRate, base, from, to Code, amount num.Amount) (num.Amount, error) {
+	if from == to {
+		return amount, nil
+	}
+	td := to.Def()
+	if td == nil {
+		return amount, fmt.Errorf("currency code %s not defined", to)
+	}
+	if rate := MatchExchangeRate(rates, from, to); rate != nil {
+		return rate.Convert(amount), nil
+	}
+	bd := base.Def()
+	if bd == nil {
+		return amount, fmt.Errorf("currency code %s not defined", base)
+	}
+	exp := bd.Subunits
+	if exp < triangulationMinExp {
+		exp = triangulationMinExp
+	}
+	a, err := exchangeAmount(rates, from, base, amount.RescaleUp(exp))
+	if err != nil {
+		return amount, err
+	}
+	a = a.Rescale(exp)
+	a, err = exchangeAmount(rates, base, to, a.RescaleUp(td.Subunits))
+	if err != nil {
+		return amount, err
+	}
+	return a.Rescale(td.Subunits), nil
+}
+
+// rateAmount provides the amount used to convert between the two currencies,
+// inverting the opposite rate if required.
+func rateAmount(rates []*ExchangeRate, from, to Code) (num.Amount, error) {
+	if from == to {
+		return num.MakeAmount(1, 0), nil
+	}
+	if rate := MatchExchangeRate(rates, from, to); rate != nil {
+		return rate.Amount, nil
+	}
+	if rate := MatchExchangeRate(rates, to, from); rate != nil {
+		one := num.MakeAmount(1, 0).Rescale(triangulationRateExp)
+		return one.Divide(rate.Amount), nil
+	}
+	return num.AmountZero, fmt.Errorf("no exchange rate defined between '%v' and '%v'", from, to)
+}
+
+// exchangeAmount converts the amount between the two currencies, using the
+// opposite rate as a divisor if required. The exponent of the amount will be
+// maintained.
+func exchangeAmount(rates []*ExchangeRate, from, to Code, amount num.Amount) (num.Amount, error) {
+	if from == to {
+		return amount, nil
+	}
+	if rate := MatchExchangeRate(rates, from, to); rate != nil {
+		return amount.Multiply(rate.Amount), nil
+	}
+	if rate := MatchExchangeRate(rates, to, from); rate != nil {
+		return amount.Divide(rate.Amount), nil
+	}
+	return amount, fmt.Errorf("no exchange rate defined between '%v' and '%v'", from, to)
+}
+
+type triangulationValidation struct {
+	rates []*ExchangeRate
+	base  Code
+	to    Code
+}
+
+// Validate checks to see if the currency code being validated can be converted
+// into the target currency either directly or through the base. When validating
+// a list of exchange rates, each of the currencies they refer to will be checked.
+func (tv *triangulationValidation) Validate(val any) error {
+	if rates, ok := val.([]*ExchangeRate); ok {
+		for _, r := range rates {
+			if r == nil {
+				continue
+			}
+			if err := tv.Validate(r.From); err != nil {
+				return err
+			}
+			if err := tv.Validate(r.To); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cur, ok := val.(Code)
+	if !ok || cur == CodeEmpty || tv.to == CodeEmpty || cur == tv.to {
+		return nil
+	}
+	if MatchExchangeRate(tv.rates, cur, tv.to) != nil {
+		return nil
+	}
+	base := tv.base
+	if base == CodeEmpty {
+		base = tv.to
+	}
+	if _, err := Triangulate(tv.rates, base, cur, tv.to); err != nil {
+		return fmt.Errorf("no exchange rate defined for '%v' to '%v' via '%v'", cur, tv.to, base)
+	}
+	return nil
+}
+
+// CanTriangulateInto checks to see if the currency being validated can be
+// converted into the target currency using the provided exchange rates,
+// either directly or by triangulating through the base currency. The rule
+// may also be applied to the list of exchange rates itself, to ensure the
+// set is sufficient to convert every currency it includes.
+func CanTriangulateInto(rates []*ExchangeRate, base, to Code) validation.Rule {
+	return &triangulationValidation{
+		rates: rates,
+		base:  base,
+		to:    to,
+	}
+}
@@ -0,0 +1,163 @@
+package currency_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func triangulationRates() []*currency.ExchangeRate {
+	return []*currency.ExchangeRate{
+		{
+			From:   currency.EUR,
+			To:     currency.USD,
+			Amount: num.MakeAmount(10850, 4), // 1 EUR = 1.0850 USD
+		},
+		{
+			From:   currency.GBP,
+			To:     currency.EUR,
+			Amount: num.MakeAmount(116959, 5), // 1 GBP = 1.16959 EUR
+		},
+		{
+			From:   currency.EUR,
+			To:     currency.JPY,
+			Amount: num.MakeAmount(16235, 2), // 1 EUR = 162.35 JPY
+		},
+	}
+}
+
+func TestTriangulate(t *testing.T) {
+	rates := triangulationRates()
+	t.Run("through base", func(t *testing.T) {
+		r, err := currency.Triangulate(rates, currency.EUR, currency.GBP, currency.USD)
+		require.NoError(t, err)
+		assert.Equal(t, currency.GBP, r.From)
+		assert.Equal(t, currency.USD, r.To)
+		assert.Equal(t, "1.269005", r.Amount.String())
+	})
+	t.Run("with inverted rate", func(t *testing.T) {
+		r, err := currency.Triangulate(rates, currency.EUR, currency.USD, currency.GBP)
+		require.NoError(t, err)
+		assert.Equal(t, "0.788018", r.Amount.String())
+	})
+	t.Run("from base", func(t *testing.T) {
+		r, err := currency.Triangulate(rates, currency.EUR, currency.EUR, currency.JPY)
+		require.NoError(t, err)
+		assert.Equal(t, "162.35", r.Amount.String())
+	})
+	t.Run("missing rate", func(t *testing.T) {
+		_, err := currency.Triangulate(rates, currency.EUR, currency.MXN, currency.USD)
+		assert.ErrorContains(t, err, "no exchange rate defined between 'MXN' and 'EUR'")
+	})
+}
+
+func TestConvertVia(t *testing.T) {
+	rates := triangulationRates()
+	t.Run("through base", func(t *testing.T) {
+		// 100.00 GBP -> 116.959 EUR -> 126.900515 USD
+		a, err := currency.ConvertVia(rates, currency.EUR, currency.GBP, currency.USD, num.MakeAmount(10000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "126.90", a.String())
+	})
+	t.Run("with division", func(t *testing.T) {
+		// 100.00 USD -> 92.166 EUR -> 78.802 GBP
+		a, err := currency.ConvertVia(rates, currency.EUR, currency.USD, currency.GBP, num.MakeAmount(10000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "78.80", a.String())
+	})
+	t.Run("into currency without subunits", func(t *testing.T) {
+		a, err := currency.ConvertVia(rates, currency.EUR, currency.GBP, currency.JPY, num.MakeAmount(10000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "18988", a.String())
+	})
+	t.Run("same currency", func(t *testing.T) {
+		a, err := currency.ConvertVia(rates, currency.EUR, currency.USD, currency.USD, num.MakeAmount(10000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "100.00", a.String())
+	})
+	t.Run("direct", func(t *testing.T) {
+		a, err := currency.ConvertVia(rates, currency.EUR, currency.EUR, currency.USD, num.MakeAmount(10000, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "108.50", a.String())
+	})
+	t.Run("missing rate", func(t *testing.T) {
+		_, err := currency.ConvertVia(rates, currency.EUR, currency.GBP, currency.MXN, num.MakeAmount(10000, 2))
+		assert.ErrorContains(t, err, "no exchange rate defined between 'EUR' and 'MXN'")
+	})
+}
+
+func TestCanTriangulateInto(t *testing.T) {
+	rates := triangulationRates()
+	rule := currency.CanTriangulateInto(rates, currency.EUR, currency.USD)
+	assert.NoError(t, validation.Validate(currency.GBP, rule))
+	assert.NoError(t, validation.Validate(currency.EUR, rule))
+	assert.NoError(t, validation.Validate(currency.USD, rule))
+	assert.NoError(t, validation.Validate(currency.CodeEmpty, rule))
+	err := validation.Validate(currency.MXN, rule)
+	assert.ErrorContains(t, err, "no exchange rate defined for 'MXN' to 'USD' via 'EUR'")
+
+	t.Run("exchange rates", func(t *testing.T) {
+		assert.NoError(t, validation.Validate(rates, rule))
+		rates := append(rates, &currency.ExchangeRate{
+			From:   currency.MXN,
+			To:     currency.COP,
+			Amount: num.MakeAmount(2100, 1),
+		})
+		err := validation.Validate(rates, rule)
+		assert.ErrorContains(t, err, "no exchange rate defined for 'MXN' to 'USD' via 'EUR'")
+	})
+}
+
+func TestDetectConflictingExchangeRates(t *testing.T) {
+	rates := triangulationRates()
+	assert.NoError(t, validation.Validate(rates, currency.DetectConflictingExchangeRates))
+
+	rates = append(rates, &currency.ExchangeRate{
+		From:   currency.EUR,
+		To:     currency.USD,
+		Amount: num.MakeAmount(10850, 4),
+	})
+	assert.NoError(t, validation.Validate(rates, currency.DetectConflictingExchangeRates), "same amount")
+
+	rates = append(rates, &currency.ExchangeRate{
+		From:   currency.EUR,
+		To:     currency.USD,
+		Amount: num.MakeAmount(10900, 4),
+	})
+	err := validation.Validate(rates, currency.DetectConflictingExchangeRates)
+	assert.ErrorContains(t, err, "conflicting exchange rates for 'EUR' to 'USD'")
+
+	rates = []*currency.ExchangeRate{
+		{
+			From:   currency.EUR,
+			To:     currency.USD,
+			At:     cal.NewDateTime(2024, 6, 1, 0, 0, 0),
+			Amount: num.MakeAmount(10850, 4),
+		},
+		{
+			From:   currency.EUR,
+			To:     currency.USD,
+			At:     cal.NewDateTime(2024, 6, 2, 0, 0, 0),
+			Amount: num.MakeAmount(10900, 4),
+		},
+	}
+	assert.NoError(t, validation.Validate(rates, currency.DetectConflictingExchangeRates), "different times")
+	rates[1].At = cal.NewDateTime(2024, 6, 1, 0, 0, 0)
+	err = validation.Validate(rates, currency.DetectConflictingExchangeRates)
+	assert.ErrorContains(t, err, "conflicting exchange rates for 'EUR' to 'USD'")
+
+	rates = []*currency.ExchangeRate{
+		{
+			From:   currency.EUR,
+			To:     currency.EUR,
+			Amount: num.MakeAmount(1, 0),
+		},
+	}
+	err = validation.Validate(rates, currency.DetectConflictingExchangeRates)
+	assert.ErrorContains(t, err, "exchange rate from 'EUR' into itself")
+}
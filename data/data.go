@@ -3,7 +3,7 @@ package data
 
 import "embed"
 
-//go:embed currency regimes schemas addons catalogues
+//go:embed currency regimes schemas addons catalogues l10n
 
 // Content contains the generated regimes and schemes
 // ready to serve as an embed.FS.
@@ -0,0 +1,204 @@
+package gobl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/schema"
+)
+
+// Progress stages reported while decoding.
+const (
+	ProgressRead       cbc.Key = "read"
+	ProgressDecoded    cbc.Key = "decoded"
+	ProgressCalculated cbc.Key = "calculated"
+	ProgressValidated  cbc.Key = "validated"
+)
+
+const (
+	// decoderPeekSize is the maximum amount of data that will be inspected
+	// at the start of the stream to find the schema ID.
+	decoderPeekSize = 64 * 1024
+	// decoderProgressInterval is the number of bytes read between each
+	// progress report.
+	decoderProgressInterval = 1024 * 1024
+)
+
+// Progress describes the state of a decoding process.
+type Progress struct {
+	// Stage reached by the decoder.
+	Stage cbc.Key
+	// Bytes read from the source so far.
+	Bytes int64
+}
+
+// ProgressFunc is called by the decoder to report progress.
+type ProgressFunc func(p Progress)
+
+// DecodeOption is used to configure the decoder.
+type DecodeOption func(*Decoder)
+
+// WithProgress sets the function to call with progress updates.
+func WithProgress(fn ProgressFunc) DecodeOption {
+	return func(d *Decoder) {
+		d.progress = fn
+	}
+}
+
+// WithCalculate will calculate the object after decoding.
+func WithCalculate() DecodeOption {
+	return func(d *Decoder) {
+		d.calculate = true
+	}
+}
+
+// WithValidate will validate the object after decoding, and after
+// calculating if also requested.
+func WithValidate() DecodeOption {
+	return func(d *Decoder) {
+		d.validate = true
+	}
+}
+
+// Decoder reads GOBL documents and envelopes directly from a stream, as an
+// alternative to Parse for very large documents. The data is decoded from
+// the reader into the object identified by the schema in a single pass,
+// without the caller needing to load the source or keep additional copies
+// of it, as long as the `$schema` property is near the start of the data
+// as GOBL always outputs it. Sources with the schema further down or with
+// pending migrations will be read completely and parsed as usual.
+type Decoder struct {
+	r         *bufio.Reader
+	cr        *countingReader
+	progress  ProgressFunc
+	calculate bool
+	validate  bool
+}
+
+type countingReader struct {
+	r    io.Reader
+	n    int64
+	last int64 // bytes at last report
+	fn   ProgressFunc
+}
+
+// NewDecoder prepares a new decoder to read from the provided source.
+func NewDecoder(r io.Reader, opts ...DecodeOption) *Decoder {
+	d := new(Decoder)
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.cr = &countingReader{r: r, fn: d.progress}
+	d.r = bufio.NewReaderSize(d.cr, decoderPeekSize)
+	return d
+}
+
+// Decode reads the next object from the source, performing calculations
+// and validation if requested in the options.
+func (d *Decoder) Decode() (interface{}, error) {
+	obj, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	d.report(ProgressDecoded)
+	if d.calculate {
+		if c, ok := obj.(interface{ Calculate() error }); ok {
+			if err := c.Calculate(); err != nil {
+				if _, ok := err.(*Error); ok {
+					return nil, err
+				}
+				return nil, ErrCalculation.WithCause(err)
+			}
+		}
+		d.report(ProgressCalculated)
+	}
+	if d.validate {
+		if v, ok := obj.(interface {
+			ValidateWithContext(context.Context) error
+		}); ok {
+			if err := v.ValidateWithContext(context.Background()); err != nil {
+				return nil, wrapError(err)
+			}
+		}
+		d.report(ProgressValidated)
+	}
+	return obj, nil
+}
+
+func (d *Decoder) decode() (interface{}, error) {
+	id, err := d.peekSchema()
+	if err != nil {
+		return nil, err
+	}
+	if id == schema.UnknownID || schema.HasMigrations(id) {
+		// fallback to regular parsing with all the data
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return nil, ErrUnmarshal.WithCause(err)
+		}
+		return Parse(data)
+	}
+	obj := id.Interface()
+	if obj == nil {
+		return nil, ErrUnknownSchema
+	}
+	if err := json.NewDecoder(d.r).Decode(obj); err != nil {
+		return nil, ErrUnmarshal.WithCause(err)
+	}
+	return obj, nil
+}
+
+// peekSchema looks for the `$schema` property amongst the top level
+// properties at the start of the source, without consuming any data.
+func (d *Decoder) peekSchema() (schema.ID, error) {
+	data, err := d.r.Peek(decoderPeekSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return schema.UnknownID, ErrUnmarshal.WithCause(err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return schema.UnknownID, ErrUnmarshal.WithReason("no data")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return schema.UnknownID, ErrUnmarshal.WithReason("expected object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			break // beyond the peeked data
+		}
+		if k, _ := t.(string); k == "$schema" {
+			var id schema.ID
+			if err := dec.Decode(&id); err != nil {
+				break
+			}
+			return id, nil
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			break
+		}
+	}
+	return schema.UnknownID, nil
+}
+
+func (d *Decoder) report(stage cbc.Key) {
+	if d.progress != nil {
+		d.progress(Progress{Stage: stage, Bytes: d.cr.n})
+	}
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.fn != nil && (r.n-r.last >= decoderProgressInterval || (err == io.EOF && r.n > r.last)) {
+		r.last = r.n
+		r.fn(Progress{Stage: ProgressRead, Bytes: r.n})
+	}
+	return n, err
+}
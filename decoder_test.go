@@ -0,0 +1,72 @@
+package gobl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	data, err := os.ReadFile("./examples/es/out/invoice-es-es.json")
+	require.NoError(t, err)
+	t.Run("envelope", func(t *testing.T) {
+		stages := []cbc.Key{}
+		dec := gobl.NewDecoder(bytes.NewReader(data),
+			gobl.WithCalculate(),
+			gobl.WithValidate(),
+			gobl.WithProgress(func(p gobl.Progress) {
+				if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+					stages = append(stages, p.Stage)
+				}
+			}),
+		)
+		obj, err := dec.Decode()
+		require.NoError(t, err)
+		env, ok := obj.(*gobl.Envelope)
+		require.True(t, ok)
+		_, ok = env.Extract().(*bill.Invoice)
+		assert.True(t, ok)
+		assert.Equal(t, []cbc.Key{
+			gobl.ProgressRead,
+			gobl.ProgressDecoded,
+			gobl.ProgressCalculated,
+			gobl.ProgressValidated,
+		}, stages)
+	})
+	t.Run("document", func(t *testing.T) {
+		env := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env))
+		doc, err := json.Marshal(env.Document)
+		require.NoError(t, err)
+		obj, err := gobl.NewDecoder(bytes.NewReader(doc)).Decode()
+		require.NoError(t, err)
+		_, ok := obj.(*bill.Invoice)
+		assert.True(t, ok)
+	})
+	t.Run("schema at end", func(t *testing.T) {
+		src := `{"code":"ABC","$schema":"https://gobl.org/draft-0/org/note"}`
+		obj, err := gobl.NewDecoder(strings.NewReader(src)).Decode()
+		require.NoError(t, err)
+		assert.NotNil(t, obj)
+	})
+	t.Run("unknown schema", func(t *testing.T) {
+		_, err := gobl.NewDecoder(strings.NewReader(`{"foo":"bar"}`)).Decode()
+		assert.ErrorIs(t, err, gobl.ErrUnknownSchema)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := gobl.NewDecoder(strings.NewReader(`[]`)).Decode()
+		assert.ErrorIs(t, err, gobl.ErrUnmarshal)
+		_, err = gobl.NewDecoder(strings.NewReader(``)).Decode()
+		assert.ErrorIs(t, err, gobl.ErrUnmarshal)
+		_, err = gobl.NewDecoder(strings.NewReader(`{"$schema":"https://gobl.org/draft-0/org/note","code":`)).Decode()
+		assert.ErrorIs(t, err, gobl.ErrUnmarshal)
+	})
+}
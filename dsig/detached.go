@@ -0,0 +1,63 @@
+package dsig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// NewDetachedSignature signs the digest using the private key, without
+// including the digest itself in the resulting signature's payload, as
+// described in RFC 7515 Appendix F. Detached signatures can only be
+// verified alongside the original digest, which is expected to be
+// transmitted separately, such as in an envelope's header.
+//
+// The payload signed is the digest's JSON representation, so that the
+// algorithm used to create the digest is also covered by the signature.
+func NewDetachedSignature(key *PrivateKey, digest *Digest, opts ...SignerOption) (*Signature, error) {
+	if newSignerOptions(opts).tsa != "" {
+		return nil, errors.New("dsig: timestamps not supported with detached signatures")
+	}
+	p, err := detachedPayload(digest)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signature{detached: true}
+	s.jws, err = signPayload(key, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Detached returns true if the signature does not include its payload.
+func (s *Signature) Detached() bool {
+	return s.detached
+}
+
+// VerifyDetached ensures that the provided key was used to sign the
+// digest in a detached signature.
+func (s *Signature) VerifyDetached(key *PublicKey, digest *Digest) error {
+	if s.jws == nil {
+		return ErrKeyMismatch
+	}
+	if !s.detached {
+		return errors.New("dsig: signature is not detached")
+	}
+	p, err := detachedPayload(digest)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.verifyKeys(p, key)
+	return err
+}
+
+func detachedPayload(digest *Digest) ([]byte, error) {
+	if digest == nil {
+		return nil, errors.New("dsig: digest required")
+	}
+	if err := digest.Validate(); err != nil {
+		return nil, fmt.Errorf("dsig: digest: %w", err)
+	}
+	return json.Marshal(digest)
+}
@@ -0,0 +1,56 @@
+package dsig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDetachedSignature(t *testing.T) {
+	k := dsig.NewES256Key()
+	d := dsig.NewSHA256Digest([]byte("a very large invoice"))
+	sig, err := dsig.NewDetachedSignature(k, d)
+	require.NoError(t, err)
+	assert.True(t, sig.Detached())
+	assert.Equal(t, k.ID(), sig.KeyID())
+	parts := strings.Split(sig.String(), ".")
+	require.Len(t, parts, 3)
+	assert.Empty(t, parts[1])
+
+	t.Run("verify", func(t *testing.T) {
+		assert.NoError(t, sig.VerifyDetached(k.Public(), d))
+		d2 := dsig.NewSHA256Digest([]byte("another invoice"))
+		assert.ErrorIs(t, sig.VerifyDetached(k.Public(), d2), dsig.ErrKeyMismatch)
+		assert.ErrorIs(t, sig.VerifyDetached(dsig.NewES256Key().Public(), d), dsig.ErrKeyMismatch)
+		_, err := sig.Verify(k.Public())
+		assert.ErrorContains(t, err, "detached signature must be verified with digest")
+	})
+	t.Run("parse", func(t *testing.T) {
+		data, err := json.Marshal(sig)
+		require.NoError(t, err)
+		sig2 := new(dsig.Signature)
+		require.NoError(t, json.Unmarshal(data, sig2))
+		assert.True(t, sig2.Detached())
+		assert.Equal(t, sig.String(), sig2.String())
+		assert.NoError(t, sig2.VerifyDetached(k.Public(), d))
+	})
+	t.Run("not detached", func(t *testing.T) {
+		sig2, err := dsig.NewSignature(k, d)
+		require.NoError(t, err)
+		assert.False(t, sig2.Detached())
+		assert.ErrorContains(t, sig2.VerifyDetached(k.Public(), d), "signature is not detached")
+	})
+	t.Run("cannot countersign", func(t *testing.T) {
+		assert.ErrorContains(t, sig.Countersign(dsig.NewES256Key()), "cannot countersign detached signature")
+	})
+	t.Run("missing digest", func(t *testing.T) {
+		_, err := dsig.NewDetachedSignature(k, nil)
+		assert.ErrorContains(t, err, "digest required")
+		_, err = dsig.NewDetachedSignature(k, &dsig.Digest{Algorithm: dsig.DigestSHA256})
+		assert.ErrorContains(t, err, "val: cannot be blank")
+	})
+}
@@ -9,5 +9,6 @@ func init() {
 	schema.Register(schema.GOBL.Add("dsig"),
 		&Digest{},
 		&Signature{},
+		&Encryption{},
 	)
 }
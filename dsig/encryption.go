@@ -0,0 +1,152 @@
+package dsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/invopop/jsonschema"
+)
+
+// Encryption represents a stored JSON Web Encryption object used to keep
+// data confidential so that it may only be read by the holders of the
+// private keys of each of the recipients.
+type Encryption struct {
+	jwe *jose.JSONWebEncryption
+}
+
+const (
+	defaultContentEncryption = jose.A256GCM
+)
+
+var (
+	joseKeyAlgorithms = []jose.KeyAlgorithm{
+		jose.ECDH_ES_A256KW,
+		jose.RSA_OAEP_256,
+	}
+	joseContentEncryptions = []jose.ContentEncryption{
+		defaultContentEncryption,
+	}
+)
+
+// Encrypt will encrypt the provided data so that it can only be decrypted
+// by the private keys of the recipients. The key encryption algorithm is
+// determined automatically from each recipient's key type.
+func Encrypt(data []byte, recipients ...*PublicKey) (*Encryption, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("dsig: at least one recipient required")
+	}
+	rcpts := make([]jose.Recipient, len(recipients))
+	for i, k := range recipients {
+		if err := k.Validate(); err != nil {
+			return nil, ErrKeyInvalid
+		}
+		alg, err := k.keyAlgorithm()
+		if err != nil {
+			return nil, fmt.Errorf("dsig: %w", err)
+		}
+		rcpts[i] = jose.Recipient{
+			Algorithm: alg,
+			Key:       k.jwk.Key,
+			KeyID:     k.ID(),
+		}
+	}
+	enc, err := jose.NewMultiEncrypter(defaultContentEncryption, rcpts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	e := new(Encryption)
+	e.jwe, err = enc.Encrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	return e, nil
+}
+
+// keyAlgorithm determines the key encryption algorithm to use for the
+// public key based on its type. Ed25519 keys may only be used for
+// signatures.
+func (k *PublicKey) keyAlgorithm() (jose.KeyAlgorithm, error) {
+	switch k.jwk.Key.(type) {
+	case *ecdsa.PublicKey:
+		return jose.ECDH_ES_A256KW, nil
+	case *rsa.PublicKey:
+		return jose.RSA_OAEP_256, nil
+	}
+	return "", errors.New("unrecognized key encryption algorithm")
+}
+
+// ParseEncryption converts the raw JWE JSON serialization data into an
+// object that can be decrypted.
+func ParseEncryption(data string) (*Encryption, error) {
+	e := new(Encryption)
+	err := e.parse(data)
+	return e, err
+}
+
+func (e *Encryption) parse(data string) error {
+	o, err := jose.ParseEncrypted(data, joseKeyAlgorithms, joseContentEncryptions)
+	if err != nil {
+		return fmt.Errorf("dsig: %w", err)
+	}
+	e.jwe = o
+	return nil
+}
+
+// Decrypt uses the private key to try and decrypt the original data. If the
+// key does not correspond to any of the recipients, an error will be
+// returned.
+func (e *Encryption) Decrypt(key *PrivateKey) ([]byte, error) {
+	if e.jwe == nil {
+		return nil, ErrDecryptFailed
+	}
+	if err := key.Validate(); err != nil {
+		return nil, ErrKeyInvalid
+	}
+	_, _, data, err := e.jwe.DecryptMulti(key.jwk.Key)
+	if err != nil {
+		// as with signatures, avoid leaking details of the failure
+		return nil, ErrDecryptFailed
+	}
+	return data, nil
+}
+
+// String provides the JSON serialization of the encrypted data.
+func (e *Encryption) String() string {
+	if e.jwe == nil {
+		return ""
+	}
+	return e.jwe.FullSerialize()
+}
+
+// JSONWebEncryption provides the underlying JOSE object.
+func (e *Encryption) JSONWebEncryption() *jose.JSONWebEncryption {
+	return e.jwe
+}
+
+// MarshalJSON provides the JWE JSON serialization object.
+func (e *Encryption) MarshalJSON() ([]byte, error) {
+	if e.jwe == nil {
+		return []byte("null"), nil
+	}
+	return []byte(e.String()), nil
+}
+
+// UnmarshalJSON parses the JWE JSON serialization object.
+func (e *Encryption) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	return e.parse(string(data))
+}
+
+// JSONSchema returns the json schema type.
+func (Encryption) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "object",
+		Title:       "Encryption",
+		Description: "JSON Web Encryption object in the general JSON serialization form.",
+	}
+}
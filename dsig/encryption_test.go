@@ -0,0 +1,97 @@
+package dsig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structWithEnc struct {
+	Name string           `json:"name"`
+	Enc  *dsig.Encryption `json:"enc,omitempty"`
+}
+
+func TestEncrypt(t *testing.T) {
+	k1 := dsig.NewES256Key()
+	k2 := dsig.NewES256Key()
+	data := []byte(`{"foo":"bar"}`)
+
+	t.Run("single recipient", func(t *testing.T) {
+		e, err := dsig.Encrypt(data, k1.Public())
+		require.NoError(t, err)
+		out, err := e.Decrypt(k1)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+
+		_, err = e.Decrypt(k2)
+		assert.ErrorIs(t, err, dsig.ErrDecryptFailed)
+	})
+
+	t.Run("multiple recipients", func(t *testing.T) {
+		e, err := dsig.Encrypt(data, k1.Public(), k2.Public())
+		require.NoError(t, err)
+		out, err := e.Decrypt(k1)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+		out, err = e.Decrypt(k2)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+	})
+
+	t.Run("RSA-OAEP recipients", func(t *testing.T) {
+		k3 := dsig.NewRS256Key()
+		e, err := dsig.Encrypt(data, k1.Public(), k3.Public())
+		require.NoError(t, err)
+		assert.Contains(t, e.String(), `"alg":"RSA-OAEP-256"`)
+		parsed, err := dsig.ParseEncryption(e.String())
+		require.NoError(t, err)
+		for _, k := range []*dsig.PrivateKey{k1, k3} {
+			out, err := parsed.Decrypt(k)
+			require.NoError(t, err)
+			assert.Equal(t, data, out)
+		}
+		_, err = parsed.Decrypt(dsig.NewPS256Key())
+		assert.ErrorIs(t, err, dsig.ErrDecryptFailed)
+	})
+
+	t.Run("Ed25519 recipient", func(t *testing.T) {
+		_, err := dsig.Encrypt(data, dsig.NewEd25519Key().Public())
+		assert.ErrorContains(t, err, "unrecognized key encryption algorithm")
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		_, err := dsig.Encrypt(data)
+		assert.ErrorContains(t, err, "dsig: at least one recipient required")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := dsig.Encrypt(data, new(dsig.PublicKey))
+		assert.ErrorIs(t, err, dsig.ErrKeyInvalid)
+	})
+}
+
+func TestEncryptionJSON(t *testing.T) {
+	k := dsig.NewES256Key()
+	e, err := dsig.Encrypt([]byte("test data"), k.Public())
+	require.NoError(t, err)
+
+	s := &structWithEnc{Name: "test", Enc: e}
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"enc":{`)
+	assert.Contains(t, string(data), `"ciphertext":`)
+	assert.NotContains(t, string(data), "test data")
+
+	s2 := new(structWithEnc)
+	require.NoError(t, json.Unmarshal(data, s2))
+	require.NotNil(t, s2.Enc)
+	out, err := s2.Enc.Decrypt(k)
+	require.NoError(t, err)
+	assert.Equal(t, "test data", string(out))
+
+	_, err = dsig.ParseEncryption(`{"foo":"bar"}`)
+	assert.ErrorContains(t, err, "dsig:")
+}
@@ -6,10 +6,17 @@ type Error string
 
 // Standard error messages
 const (
-	ErrKeyPublic    Error = "cannot sign with public key"
-	ErrKeyInvalid   Error = "key is not valid"
-	ErrKeyMismatch  Error = "key mismatch"
-	ErrVerifyFailed Error = "verification failed"
+	ErrKeyPublic     Error = "cannot sign with public key"
+	ErrKeyInvalid    Error = "key is not valid"
+	ErrKeyMismatch   Error = "key mismatch"
+	ErrVerifyFailed  Error = "verification failed"
+	ErrDecryptFailed Error = "decryption failed"
+
+	ErrKeyAlreadySigned Error = "already signed with key"
+	ErrKeyNotFound      Error = "key not found"
+	ErrJKUMissing       Error = "jku header missing"
+	ErrJKUNotAllowed    Error = "jku not allowed"
+	ErrTimestampMissing Error = "timestamp missing"
 )
 
 // Error provides the standard error response text.
@@ -3,8 +3,10 @@ package dsig
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 
@@ -19,6 +21,9 @@ const (
 	curveAlgorithmP256 = "P-256"
 )
 
+// rsaKeyBits is the size of RSA keys generated by GOBL.
+const rsaKeyBits = 2048
+
 // PrivateKey makes it easy to deal with private keys used to sign data
 // and created signatures.
 // These should obviously be kept secure and be used to generate the public
@@ -41,6 +46,44 @@ func NewES256Key() *PrivateKey {
 	return newKey(pk, string(jose.ES256))
 }
 
+// NewRS256Key provides a new 2048 bit RSA private key that will create
+// RSASSA-PKCS1-v1_5 signatures using SHA-256.
+func NewRS256Key() *PrivateKey {
+	pk, _ := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	return newKey(pk, string(jose.RS256))
+}
+
+// NewPS256Key provides a new 2048 bit RSA private key that will create
+// RSASSA-PSS signatures using SHA-256.
+func NewPS256Key() *PrivateKey {
+	pk, _ := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	return newKey(pk, string(jose.PS256))
+}
+
+// NewEd25519Key provides a new Ed25519 private key that will create EdDSA
+// signatures.
+func NewEd25519Key() *PrivateKey {
+	_, pk, _ := ed25519.GenerateKey(rand.Reader)
+	return newKey(pk, string(jose.EdDSA))
+}
+
+// NewKey provides a new private key for one of the supported signature
+// algorithms: "ES256", "RS256", "PS256", or "EdDSA". An empty algorithm
+// provides the default ES256 key.
+func NewKey(alg string) (*PrivateKey, error) {
+	switch jose.SignatureAlgorithm(alg) {
+	case "", jose.ES256:
+		return NewES256Key(), nil
+	case jose.RS256:
+		return NewRS256Key(), nil
+	case jose.PS256:
+		return NewPS256Key(), nil
+	case jose.EdDSA:
+		return NewEd25519Key(), nil
+	}
+	return nil, fmt.Errorf("dsig: unsupported signature algorithm: %s", alg)
+}
+
 func newKey(pk interface{}, alg string) *PrivateKey {
 	k := new(PrivateKey)
 	k.jwk = new(jose.JSONWebKey)
@@ -66,12 +109,27 @@ func (k *PublicKey) ID() string {
 // optional `alg` property. Algorithm names provided match those
 // required for signatures. Anything not defined here will not be supported
 // for the time being.
+//
+// RSA keys are the exception, as the same key may be used with different
+// padding schemes, so the `alg` property is used to choose PS256 over the
+// more widely supported RS256.
 func (k *PrivateKey) signatureAlgorithm() (jose.SignatureAlgorithm, error) {
-	if pk, ok := k.jwk.Key.(*ecdsa.PrivateKey); ok {
+	switch pk := k.jwk.Key.(type) {
+	case *ecdsa.PrivateKey:
 		switch pk.Params().Name {
 		case curveAlgorithmP256:
 			return jose.ES256, nil
 		}
+	case *rsa.PrivateKey:
+		if pk.N.BitLen() < rsaKeyBits {
+			return "", errors.New("RSA key too short")
+		}
+		if jose.SignatureAlgorithm(k.jwk.Algorithm) == jose.PS256 {
+			return jose.PS256, nil
+		}
+		return jose.RS256, nil
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
 	}
 	return "", errors.New("unrecognized key signature algorithm")
 }
@@ -139,3 +139,66 @@ func TestPrivateKeySignAndVerify(t *testing.T) {
 		assert.Equal(t, "hello world", str)
 	})
 }
+
+func TestNewKey(t *testing.T) {
+	for _, alg := range []string{"ES256", "RS256", "PS256", "EdDSA"} {
+		t.Run(alg, func(t *testing.T) {
+			k, err := dsig.NewKey(alg)
+			require.NoError(t, err)
+			require.NoError(t, k.Validate())
+			assert.NoError(t, k.Public().Validate())
+			assert.Equal(t, k.Thumbprint(), k.Public().Thumbprint())
+
+			p := &payload{Foo: "foo", Bar: 1}
+			sig, err := k.Sign(p)
+			require.NoError(t, err)
+			assert.Equal(t, alg, sig.Algorithm())
+			assert.Equal(t, k.ID(), sig.KeyID())
+
+			// parse and verify after serializing both key and signature
+			data, err := json.Marshal(k.Public())
+			require.NoError(t, err)
+			pub := new(dsig.PublicKey)
+			require.NoError(t, json.Unmarshal(data, pub))
+			sig2, err := dsig.ParseSignature(sig.String())
+			require.NoError(t, err)
+			p2 := new(payload)
+			require.NoError(t, pub.Verify(sig2, p2))
+			assert.Equal(t, p, p2)
+
+			other, err := dsig.NewKey(alg)
+			require.NoError(t, err)
+			assert.ErrorIs(t, sig2.VerifyPayload(other.Public(), p2), dsig.ErrKeyMismatch)
+		})
+	}
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := dsig.NewKey("HS256")
+		assert.ErrorContains(t, err, "unsupported signature algorithm: HS256")
+	})
+}
+
+func TestRSAKeyAlgorithm(t *testing.T) {
+	t.Run("PS256 kept after parsing", func(t *testing.T) {
+		data, err := json.Marshal(dsig.NewPS256Key())
+		require.NoError(t, err)
+		k := new(dsig.PrivateKey)
+		require.NoError(t, json.Unmarshal(data, k))
+		sig, err := k.Sign("test")
+		require.NoError(t, err)
+		assert.Equal(t, "PS256", sig.Algorithm())
+	})
+	t.Run("RS256 without alg", func(t *testing.T) {
+		data, err := json.Marshal(dsig.NewRS256Key())
+		require.NoError(t, err)
+		raw := make(map[string]any)
+		require.NoError(t, json.Unmarshal(data, &raw))
+		delete(raw, "alg")
+		data, err = json.Marshal(raw)
+		require.NoError(t, err)
+		k := new(dsig.PrivateKey)
+		require.NoError(t, json.Unmarshal(data, k))
+		sig, err := k.Sign("test")
+		require.NoError(t, err)
+		assert.Equal(t, "RS256", sig.Algorithm())
+	})
+}
@@ -3,6 +3,15 @@ package dsig
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Prefixes used to differentiate leaf and node hashes in digest trees,
+// as defined by RFC 6962.
+const (
+	treeLeafPrefix byte = 0x00
+	treeNodePrefix byte = 0x01
 )
 
 // NewSHA256Digest creates a SHA256 digest object from the provided byte array.
@@ -15,3 +24,50 @@ func NewSHA256Digest(data []byte) *Digest {
 		Value:     hex.EncodeToString(sum[:]),
 	}
 }
+
+// NewSHA256DigestTree builds a Merkle tree from the list of SHA256 digests
+// and provides the root digest. Leaf and node hashes are calculated following
+// RFC 6962 so that the root for a given ordered list of digests can be
+// reproduced independently. All the digests provided must use the SHA256
+// algorithm.
+func NewSHA256DigestTree(digests []*Digest) (*Digest, error) {
+	if len(digests) == 0 {
+		return nil, errors.New("no digests provided")
+	}
+	level := make([][]byte, len(digests))
+	for i, d := range digests {
+		if d == nil || d.Algorithm != DigestSHA256 {
+			return nil, fmt.Errorf("digest %d: algorithm mismatch", i)
+		}
+		v, err := hex.DecodeString(d.Value)
+		if err != nil {
+			return nil, fmt.Errorf("digest %d: %w", i, err)
+		}
+		level[i] = treeHash(treeLeafPrefix, v)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				// odd node is promoted to the next level
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, treeHash(treeNodePrefix, level[i], level[i+1]))
+		}
+		level = next
+	}
+	return &Digest{
+		Algorithm: DigestSHA256,
+		Value:     hex.EncodeToString(level[0]),
+	}, nil
+}
+
+func treeHash(prefix byte, parts ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
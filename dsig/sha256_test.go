@@ -5,6 +5,7 @@ import (
 
 	"github.com/invopop/gobl/dsig"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSHA256Digest(t *testing.T) {
@@ -15,3 +16,36 @@ func TestNewSHA256Digest(t *testing.T) {
 		assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", d.Value)
 	})
 }
+
+func TestNewSHA256DigestTree(t *testing.T) {
+	a := dsig.NewSHA256Digest([]byte("a"))
+	b := dsig.NewSHA256Digest([]byte("b"))
+	c := dsig.NewSHA256Digest([]byte("c"))
+
+	t.Run("single", func(t *testing.T) {
+		d, err := dsig.NewSHA256DigestTree([]*dsig.Digest{a})
+		require.NoError(t, err)
+		assert.Equal(t, dsig.DigestSHA256, d.Algorithm)
+		assert.Equal(t, "a23bd5b06da9048238a65b3f1d9d0b9e15fae3dde262688e6489aa4c763d1820", d.Value)
+	})
+	t.Run("odd number of leaves", func(t *testing.T) {
+		d, err := dsig.NewSHA256DigestTree([]*dsig.Digest{a, b, c})
+		require.NoError(t, err)
+		assert.Equal(t, "cac3d448d4e20a2ad5eae1f500e63c2a7f9217cd14572ba7fd22e26dc1ec2648", d.Value)
+	})
+	t.Run("order matters", func(t *testing.T) {
+		d1, err := dsig.NewSHA256DigestTree([]*dsig.Digest{a, b})
+		require.NoError(t, err)
+		d2, err := dsig.NewSHA256DigestTree([]*dsig.Digest{b, a})
+		require.NoError(t, err)
+		assert.Error(t, d1.Equals(d2))
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := dsig.NewSHA256DigestTree(nil)
+		assert.ErrorContains(t, err, "no digests provided")
+		_, err = dsig.NewSHA256DigestTree([]*dsig.Digest{a, {Algorithm: "md5", Value: "00"}})
+		assert.ErrorContains(t, err, "digest 1: algorithm mismatch")
+		_, err = dsig.NewSHA256DigestTree([]*dsig.Digest{{Algorithm: dsig.DigestSHA256, Value: "xx"}})
+		assert.ErrorContains(t, err, "digest 0:")
+	})
+}
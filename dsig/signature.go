@@ -1,8 +1,13 @@
 package dsig
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/invopop/jsonschema"
@@ -11,13 +16,18 @@ import (
 // Signature represents a stored JSON Web Signature and provides helper
 // methods to be able to extract and verify contents.
 type Signature struct {
-	jws *jose.JSONWebSignature
+	jws      *jose.JSONWebSignature
+	detached bool
 }
 
 // signerOptions are used to define additional parameters to use when creating
 // signatures.
 type signerOptions struct {
-	jku string
+	jku       string
+	typ       string
+	tsa       string
+	tsaClient HTTPClient
+	tsaCtx    context.Context
 }
 
 // SignerOption defines the callback to be used to define one of the signer options.
@@ -32,6 +42,14 @@ func WithJKU(jku string) SignerOption {
 	}
 }
 
+// WithType sets the "typ" header field of the signature, used to declare
+// the media type of the complete JWS, such as "JWT".
+func WithType(typ string) SignerOption {
+	return func(so *signerOptions) {
+		so.typ = typ
+	}
+}
+
 const (
 	headerJKU jose.HeaderKey = "jku"
 )
@@ -39,6 +57,9 @@ const (
 var (
 	joseSignatureAlgorithms = []jose.SignatureAlgorithm{
 		jose.ES256,
+		jose.RS256,
+		jose.PS256,
+		jose.EdDSA,
 	}
 )
 
@@ -46,14 +67,50 @@ var (
 // data using the private key. The signature will use the same algorithm as
 // defined by the key.
 func NewSignature(key *PrivateKey, data interface{}, opts ...SignerOption) (*Signature, error) {
+	// get a JSON string of the payload
+	p, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	s := new(Signature)
+	s.jws, err = signPayload(key, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Countersign adds an additional signature to the same payload using the
+// private key, so that several parties may sign exactly the same data
+// without invalidating each other's signatures. Signatures with more
+// than one signer are serialized using the JWS JSON form instead of the
+// compact form.
+func (s *Signature) Countersign(key *PrivateKey, opts ...SignerOption) error {
+	if s.jws == nil || len(s.jws.Signatures) == 0 {
+		return errors.New("dsig: no signature to countersign")
+	}
+	if s.detached {
+		return errors.New("dsig: cannot countersign detached signature")
+	}
+	for _, kid := range s.KeyIDs() {
+		if kid == key.ID() {
+			return ErrKeyAlreadySigned
+		}
+	}
+	jws, err := signPayload(key, s.jws.UnsafePayloadWithoutVerification(), opts...)
+	if err != nil {
+		return err
+	}
+	s.jws.Signatures = append(s.jws.Signatures, jws.Signatures...)
+	return nil
+}
+
+func signPayload(key *PrivateKey, payload []byte, opts ...SignerOption) (*jose.JSONWebSignature, error) {
 	if err := key.Validate(); err != nil {
 		return nil, ErrKeyInvalid
 	}
 
-	so := new(signerOptions)
-	for _, opt := range opts {
-		opt(so)
-	}
+	so := newSignerOptions(opts)
 
 	alg, err := key.signatureAlgorithm()
 	if err != nil {
@@ -67,26 +124,44 @@ func NewSignature(key *PrivateKey, data interface{}, opts ...SignerOption) (*Sig
 	if so.jku != "" {
 		joseOpts.WithHeader(headerJKU, so.jku)
 	}
+	if so.typ != "" {
+		joseOpts.WithType(jose.ContentType(so.typ))
+	}
+	if certs := key.Certificates(); len(certs) > 0 {
+		joseOpts.WithHeader(headerX5C, x5c(certs))
+	}
 	signer, err := jose.NewSigner(sk, joseOpts)
 	if err != nil {
 		return nil, fmt.Errorf("dsig: %w", err)
 	}
 
-	// get a JSON string of the payload
-	p, err := json.Marshal(data)
+	jws, err := signer.Sign(payload)
 	if err != nil {
 		return nil, fmt.Errorf("dsig: %w", err)
 	}
-
-	s := new(Signature)
-	s.jws, err = signer.Sign(p)
+	// parse the result so that headers, including any certificates, are
+	// available just like with signatures that have been received
+	jws, err = jose.ParseSigned(jws.FullSerialize(), joseSignatureAlgorithms)
 	if err != nil {
 		return nil, fmt.Errorf("dsig: %w", err)
 	}
-	// correct issue in copying Key ID header
-	s.jws.Signatures[0].Header.KeyID = key.ID()
+	if so.tsa != "" {
+		return addTimestamp(jws, so)
+	}
+	return jws, nil
+}
 
-	return s, nil
+func newSignerOptions(opts []SignerOption) *signerOptions {
+	so := new(signerOptions)
+	for _, opt := range opts {
+		opt(so)
+	}
+	return so
+}
+
+// compact returns true if the signature can be serialized in compact form.
+func (s *Signature) compact() bool {
+	return len(s.jws.Signatures) == 1 && !hasUnprotected(s.jws)
 }
 
 // ParseSignature converts raw signature data into an object that
@@ -103,6 +178,42 @@ func (s *Signature) parse(data string) error {
 		return fmt.Errorf("dsig: %w", err)
 	}
 	s.jws = o
+	if err := restoreUnprotected(o, data); err != nil {
+		return fmt.Errorf("dsig: %w", err)
+	}
+	// compact signatures without a payload section are detached
+	parts := strings.Split(data, ".")
+	s.detached = len(parts) == 3 && parts[1] == ""
+	return nil
+}
+
+// restoreUnprotected ensures the unprotected headers of JWS JSON data with
+// multiple signatures are available, as go-jose will only parse them for
+// single signatures.
+func restoreUnprotected(jws *jose.JSONWebSignature, data string) error {
+	if len(jws.Signatures) < 2 {
+		return nil
+	}
+	raw := new(struct {
+		Signatures []struct {
+			Header map[string]any `json:"header"`
+		} `json:"signatures"`
+	})
+	if err := json.Unmarshal([]byte(data), raw); err != nil {
+		return err
+	}
+	for i, rs := range raw.Signatures {
+		if i >= len(jws.Signatures) || len(rs.Header) == 0 {
+			continue
+		}
+		sig := &jws.Signatures[i]
+		if sig.Unprotected.ExtraHeaders == nil {
+			sig.Unprotected.ExtraHeaders = make(map[jose.HeaderKey]any)
+		}
+		for k, v := range rs.Header {
+			sig.Unprotected.ExtraHeaders[jose.HeaderKey(k)] = v
+		}
+	}
 	return nil
 }
 
@@ -115,6 +226,38 @@ func (s *Signature) KeyID() string {
 	return s.jws.Signatures[0].Header.KeyID
 }
 
+// Algorithm returns the signature's "alg" header property value, like
+// "ES256" or "EdDSA".
+func (s *Signature) Algorithm() string {
+	if s.jws == nil || len(s.jws.Signatures) == 0 {
+		return ""
+	}
+	return s.jws.Signatures[0].Header.Algorithm
+}
+
+// KeyIDs provides the IDs of the keys used to create each of the
+// signatures, in the order they were added. The IDs are taken from the
+// headers without any checks, so VerifyKeys should be used to determine
+// which signers are authentic.
+func (s *Signature) KeyIDs() []string {
+	if s.jws == nil {
+		return nil
+	}
+	kids := make([]string, len(s.jws.Signatures))
+	for i, sig := range s.jws.Signatures {
+		kids[i] = sig.Header.KeyID
+	}
+	return kids
+}
+
+// Len provides the number of signatures over the payload.
+func (s *Signature) Len() int {
+	if s.jws == nil {
+		return 0
+	}
+	return len(s.jws.Signatures)
+}
+
 // JKU returns the signatures JKU header property value.
 func (s *Signature) JKU() string {
 	if s.jws == nil || len(s.jws.Signatures) == 0 {
@@ -127,27 +270,129 @@ func (s *Signature) JKU() string {
 	return jku
 }
 
-// String provides the compact form signature.
+// Type returns the signature's "typ" header property value.
+func (s *Signature) Type() string {
+	if s.jws == nil || len(s.jws.Signatures) == 0 {
+		return ""
+	}
+	typ, ok := s.jws.Signatures[0].Header.ExtraHeaders[jose.HeaderType].(string)
+	if !ok {
+		return ""
+	}
+	return typ
+}
+
+// String provides the compact form signature, or the JWS JSON form if
+// there is more than one signature or unprotected headers are included.
 func (s *Signature) String() string {
 	if s.jws == nil {
 		return ""
 	}
-	d, err := s.jws.CompactSerialize()
+	if !s.compact() {
+		return s.jws.FullSerialize()
+	}
+	serialize := s.jws.CompactSerialize
+	if s.detached {
+		serialize = s.jws.DetachedCompactSerialize
+	}
+	d, err := serialize()
 	if err != nil {
 		return ""
 	}
 	return d
 }
 
-// Verify will ensure that the provided key was used to sign the
-// signature and will provide the raw data that was signed.
+// Verify will ensure that the provided key was used to create one of the
+// signatures and will provide the raw data that was signed. When there are
+// several signatures, each is checked on its own against the key named by
+// its protected "kid" header, and any signature that claims to have been
+// made with the key but does not verify will cause the whole JWS to be
+// rejected.
 func (s *Signature) Verify(key *PublicKey) ([]byte, error) {
-	data, err := s.jws.Verify(key.jwk)
-	if err != nil {
-		// at the risk of hiding useful errors, provide our own
+	if s.jws == nil {
 		return nil, ErrKeyMismatch
 	}
-	return data, nil
+	if s.detached {
+		return nil, errors.New("dsig: detached signature must be verified with digest")
+	}
+	_, data, err := s.verifyKeys(nil, key)
+	return data, err
+}
+
+// VerifyKeys checks each of the signatures against the provided key with
+// the same ID as the signature's protected "kid" header, and provides the
+// IDs of the keys whose signatures are authentic, in the order they were
+// added. Signatures made with keys that were not provided are not
+// included, but an error will be returned if any signature claiming to
+// come from one of the keys does not verify, or if none of the keys match.
+func (s *Signature) VerifyKeys(keys ...*PublicKey) ([]string, error) {
+	if s.detached {
+		return nil, errors.New("dsig: detached signature must be verified with digest")
+	}
+	kids, _, err := s.verifyKeys(nil, keys...)
+	return kids, err
+}
+
+// verifyKeys performs the checks for VerifyKeys, using the detached payload
+// if provided. Single signatures are verified with any of the keys, so that
+// keys without IDs remain usable.
+func (s *Signature) verifyKeys(detached []byte, keys ...*PublicKey) ([]string, []byte, error) {
+	if s.jws == nil || len(s.jws.Signatures) == 0 {
+		return nil, nil, ErrKeyMismatch
+	}
+	multi := len(s.jws.Signatures) > 1
+	var kids []string
+	var data []byte
+	for i := range s.jws.Signatures {
+		kid := s.jws.Signatures[i].Protected.KeyID
+		for _, k := range keys {
+			if k == nil || (multi && (kid == "" || kid != k.ID())) {
+				continue
+			}
+			d, err := s.verifyIndex(i, detached, k)
+			if err != nil {
+				if multi {
+					return nil, nil, fmt.Errorf("%w: signature %d", ErrKeyMismatch, i)
+				}
+				continue
+			}
+			if !slices.Contains(kids, k.ID()) {
+				kids = append(kids, k.ID())
+			}
+			data = d
+			break
+		}
+	}
+	if len(kids) == 0 {
+		return nil, nil, ErrKeyMismatch
+	}
+	return kids, data, nil
+}
+
+// signerIndex provides the position of the signature created with the key,
+// using the same rules as verifyKeys.
+func (s *Signature) signerIndex(key *PublicKey) (int, error) {
+	multi := len(s.jws.Signatures) > 1
+	for i := range s.jws.Signatures {
+		if multi && s.jws.Signatures[i].Protected.KeyID != key.ID() {
+			continue
+		}
+		if _, err := s.verifyIndex(i, nil, key); err == nil {
+			return i, nil
+		}
+	}
+	return -1, ErrKeyMismatch
+}
+
+// verifyIndex checks the signature at the given position on its own, as the
+// JOSE library will otherwise accept a JWS if any of its signatures match.
+func (s *Signature) verifyIndex(i int, detached []byte, key *PublicKey) ([]byte, error) {
+	one := *s.jws
+	one.Signatures = s.jws.Signatures[i : i+1]
+	if detached != nil {
+		return detached, one.DetachedVerify(detached, key.jwk)
+	}
+	return one.Verify(key.jwk)
 }
 
 // VerifyPayload verifies that the provided key was indeed used to
@@ -187,8 +432,12 @@ func (s *Signature) JSONWebSignature() *jose.JSONWebSignature {
 }
 
 // MarshalJSON provides the compact string signature ready to be
-// using as a JSON string.
+// using as a JSON string, or the JWS JSON object when the compact form
+// cannot be used.
 func (s *Signature) MarshalJSON() ([]byte, error) {
+	if s.jws != nil && !s.compact() {
+		return []byte(s.jws.FullSerialize()), nil
+	}
 	data, err := json.Marshal(s.String())
 	if err != nil {
 		return nil, fmt.Errorf("dsig: %w", err)
@@ -196,8 +445,11 @@ func (s *Signature) MarshalJSON() ([]byte, error) {
 	return data, nil
 }
 
-// UnmarshalJSON parses the compact signature string.
+// UnmarshalJSON parses the compact signature string or JWS JSON object.
 func (s *Signature) UnmarshalJSON(data []byte) error {
+	if d := bytes.TrimSpace(data); len(d) > 0 && d[0] == '{' {
+		return s.parse(string(d))
+	}
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
 		return fmt.Errorf("dsig: %w", err)
@@ -211,8 +463,17 @@ func (s *Signature) UnmarshalJSON(data []byte) error {
 // JSONSchema returns the json schema type.
 func (Signature) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type:        "string",
+		OneOf: []*jsonschema.Schema{
+			{
+				Type:        "string",
+				Description: "JSON Web Signature in compact form.",
+			},
+			{
+				Type:        "object",
+				Description: "JSON Web Signature in JSON form, used when there are multiple signatures.",
+			},
+		},
 		Title:       "Signature",
-		Description: "JSON Web Signature in compact form.",
+		Description: "JSON Web Signature in compact form, or JSON form with multiple signatures.",
 	}
 }
@@ -120,6 +120,21 @@ func TestSignaturesWithJKU(t *testing.T) {
 	assert.Equal(t, jku, sig.JKU(), "should be included in signature output")
 }
 
+func TestSignaturesWithType(t *testing.T) {
+	k := dsig.NewES256Key()
+	p := &payload{Foo: "foo", Bar: 1234}
+	s, err := dsig.NewSignature(k, p, dsig.WithType("JWT"))
+	require.NoError(t, err)
+
+	sig, err := dsig.ParseSignature(s.String())
+	require.NoError(t, err)
+	assert.Equal(t, "JWT", sig.Type())
+
+	s, err = dsig.NewSignature(k, p)
+	require.NoError(t, err)
+	assert.Empty(t, s.Type())
+}
+
 func TestJSONSignatures(t *testing.T) {
 	pubData := []byte(`{"use":"sig","kty":"EC","kid":"3500bbee-966c-4b7a-8fbc-c763ae2aec62","crv":"P-256","x":"Fd4a9pj2gtDLnW3GX30S06qXHrkBrAsmg3aHb4kOCL4","y":"_I4ZuddZtZ86kDBvGKcsOPbU0gWh13Kt6R2m6bfWAK4"}`)
 	pub := new(dsig.PublicKey)
@@ -166,3 +181,88 @@ func TestJSONSignatures(t *testing.T) {
 		t.Errorf("expected marshaled struct to include signature")
 	}
 }
+
+func TestSignatureCountersign(t *testing.T) {
+	k1 := dsig.NewES256Key()
+	k2 := dsig.NewEd25519Key()
+	p := &payload{Foo: "foo", Bar: 1234}
+	sig, err := dsig.NewSignature(k1, p)
+	require.NoError(t, err)
+	compact := sig.String()
+
+	require.NoError(t, sig.Countersign(k2, dsig.WithJKU("https://example.com/jwks.json")))
+	assert.Equal(t, 2, sig.Len())
+	assert.Equal(t, []string{k1.ID(), k2.ID()}, sig.KeyIDs())
+	assert.Equal(t, k1.ID(), sig.KeyID())
+	assert.True(t, strings.HasPrefix(sig.String(), "{"))
+
+	t.Run("verifies each signer", func(t *testing.T) {
+		for _, k := range []*dsig.PrivateKey{k1, k2} {
+			p2 := new(payload)
+			require.NoError(t, sig.VerifyPayload(k.Public(), p2))
+			assert.Equal(t, p, p2)
+		}
+		_, err := sig.Verify(dsig.NewES256Key().Public())
+		assert.ErrorIs(t, err, dsig.ErrKeyMismatch)
+	})
+	t.Run("same key twice", func(t *testing.T) {
+		assert.ErrorIs(t, sig.Countersign(k1), dsig.ErrKeyAlreadySigned)
+	})
+	t.Run("original untouched", func(t *testing.T) {
+		orig, err := dsig.ParseSignature(compact)
+		require.NoError(t, err)
+		assert.Equal(t, 1, orig.Len())
+		assert.Equal(t, compact, orig.String())
+	})
+	t.Run("JSON round trip", func(t *testing.T) {
+		data, err := json.Marshal(&structWithSig{Name: "test", Sig: sig})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"sig":{"payload":`)
+		out := new(structWithSig)
+		require.NoError(t, json.Unmarshal(data, out))
+		assert.Equal(t, sig.KeyIDs(), out.Sig.KeyIDs())
+		_, err = out.Sig.Verify(k2.Public())
+		assert.NoError(t, err)
+	})
+	t.Run("verify keys", func(t *testing.T) {
+		kids, err := sig.VerifyKeys(k1.Public(), k2.Public())
+		require.NoError(t, err)
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, kids)
+		kids, err = sig.VerifyKeys(k2.Public())
+		require.NoError(t, err)
+		assert.Equal(t, []string{k2.ID()}, kids)
+	})
+	t.Run("forged signer", func(t *testing.T) {
+		forged, err := dsig.ParseSignature(compact)
+		require.NoError(t, err)
+		require.NoError(t, forged.Countersign(forgedKey(t, k2.ID())))
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, forged.KeyIDs())
+
+		_, err = forged.Verify(k2.Public())
+		assert.ErrorIs(t, err, dsig.ErrKeyMismatch)
+		_, err = forged.VerifyKeys(k1.Public(), k2.Public())
+		assert.ErrorIs(t, err, dsig.ErrKeyMismatch)
+		kids, err := forged.VerifyKeys(k1.Public())
+		require.NoError(t, err)
+		assert.Equal(t, []string{k1.ID()}, kids, "unverified signer not included")
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.ErrorContains(t, new(dsig.Signature).Countersign(k1), "no signature to countersign")
+	})
+}
+
+// forgedKey provides a new private key that claims to have the provided ID.
+func forgedKey(t *testing.T, kid string) *dsig.PrivateKey {
+	t.Helper()
+	data, err := json.Marshal(dsig.NewES256Key())
+	require.NoError(t, err)
+	m := make(map[string]any)
+	require.NoError(t, json.Unmarshal(data, &m))
+	m["kid"] = kid
+	data, err = json.Marshal(m)
+	require.NoError(t, err)
+	k := new(dsig.PrivateKey)
+	require.NoError(t, json.Unmarshal(data, k))
+	require.Equal(t, kid, k.ID())
+	return k
+}
@@ -0,0 +1,513 @@
+package dsig
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// headerTST is the unprotected header used to store RFC 3161 timestamp
+// tokens over the signature value.
+const headerTST jose.HeaderKey = "tst"
+
+// maxTimestampSize limits the amount of data read from time-stamping
+// authorities.
+const maxTimestampSize = 1 << 20
+
+// DefaultTimestampTimeout is the maximum period of time to wait for a
+// time-stamping authority, unless the context provided with
+// WithTimestampContext already defines a deadline.
+const DefaultTimestampTimeout = 30 * time.Second
+
+// ASN.1 object identifiers used in timestamp tokens.
+var (
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// WithTimestamp requests an RFC 3161 timestamp token over the signature
+// value from the time-stamping authority at the URL, and stores it in
+// the signature's unprotected "tst" header. Unprotected headers are not
+// supported by the compact form, so timestamped signatures are always
+// serialized using the JWS JSON form.
+func WithTimestamp(tsaURL string) SignerOption {
+	return func(so *signerOptions) {
+		so.tsa = tsaURL
+	}
+}
+
+// WithTimestampClient sets the HTTP client used to contact the
+// time-stamping authority, which is a client with a 30 second timeout
+// otherwise.
+func WithTimestampClient(c HTTPClient) SignerOption {
+	return func(so *signerOptions) {
+		so.tsaClient = c
+	}
+}
+
+// WithTimestampContext sets the context used for requests to the
+// time-stamping authority, so that they may be cancelled. The
+// DefaultTimestampTimeout will be applied if the context has no deadline.
+func WithTimestampContext(ctx context.Context) SignerOption {
+	return func(so *signerOptions) {
+		so.tsaCtx = ctx
+	}
+}
+
+// Timestamp contains the details of an RFC 3161 timestamp token issued
+// by a time-stamping authority (TSA) over a signature's value.
+type Timestamp struct {
+	// Time at which the token was generated by the TSA.
+	Time time.Time
+	// SerialNumber assigned by the TSA to the token.
+	SerialNumber *big.Int
+	// Policy under which the token was issued.
+	Policy asn1.ObjectIdentifier
+	// Certificates included in the token, usually starting with the
+	// TSA's own certificate.
+	Certificates []*x509.Certificate
+	// Token contains the raw DER encoded timestamp token.
+	Token []byte
+
+	info *tstInfo
+	sd   *cmsSignedData
+}
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type tstAccuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       tstAccuracy   `asn1:"optional"`
+	Ordering       bool          `asn1:"optional"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type cmsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo cmsEncapContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type cmsIssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+// requestTimestamp obtains a timestamp token over the data from the TSA.
+func requestTimestamp(ctx context.Context, client HTTPClient, tsaURL string, data []byte) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimestampTimeout)
+		defer cancel()
+	}
+	sum := sha256.Sum256(data)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: sum[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	hr, err := http.NewRequestWithContext(ctx, http.MethodPost, tsaURL, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	hr.Header.Set("Content-Type", "application/timestamp-query")
+	hr.Header.Set("Accept", "application/timestamp-reply")
+	res, err := client.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxTimestampSize))
+	if err != nil {
+		return nil, err
+	}
+	resp := new(timeStampResp)
+	if _, err := asn1.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Status.Status > 1 {
+		// 0 is granted, 1 granted with modifications
+		return nil, fmt.Errorf("request rejected with status %d", resp.Status.Status)
+	}
+	token := resp.TimeStampToken.FullBytes
+	ts, err := parseTimestamp(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.checkImprint(data); err != nil {
+		return nil, err
+	}
+	if ts.info.Nonce == nil || ts.info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("nonce mismatch")
+	}
+	return token, nil
+}
+
+// addTimestamp requests a timestamp token over the value of the first
+// signature and adds it to the signature's unprotected header.
+func addTimestamp(jws *jose.JSONWebSignature, so *signerOptions) (*jose.JSONWebSignature, error) {
+	client := so.tsaClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	token, err := requestTimestamp(so.tsaCtx, client, so.tsa, jws.Signatures[0].Signature)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: timestamp: %w", err)
+	}
+	// go-jose does not support setting unprotected headers, so they are
+	// added to the JSON form and parsed again.
+	raw := make(map[string]any)
+	if err := json.Unmarshal([]byte(jws.FullSerialize()), &raw); err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	raw["header"] = map[string]any{
+		string(headerTST): base64.StdEncoding.EncodeToString(token),
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	jws, err = jose.ParseSigned(string(data), joseSignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	return jws, nil
+}
+
+// hasUnprotected returns true if any of the signatures include unprotected
+// headers that cannot be represented in the compact form.
+func hasUnprotected(jws *jose.JSONWebSignature) bool {
+	for _, sig := range jws.Signatures {
+		if len(sig.Unprotected.ExtraHeaders) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Timestamp provides the details of the timestamp token included with
+// the signature at index i, where 0 is the original signature and any
+// following indexes are countersignatures, after ensuring it was issued
+// over the signature's value. The token's own signature is not checked:
+// use VerifyTimestamp to also ensure it was issued by a trusted TSA.
+func (s *Signature) Timestamp(i int) (*Timestamp, error) {
+	if s.jws == nil || i < 0 || i >= len(s.jws.Signatures) {
+		return nil, fmt.Errorf("dsig: no signature at index %d", i)
+	}
+	sig := s.jws.Signatures[i]
+	v, ok := sig.Unprotected.ExtraHeaders[headerTST].(string)
+	if !ok {
+		return nil, ErrTimestampMissing
+	}
+	token, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: timestamp: %w", err)
+	}
+	ts, err := parseTimestamp(token)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: timestamp: %w", err)
+	}
+	if err := ts.checkImprint(sig.Signature); err != nil {
+		return nil, fmt.Errorf("dsig: timestamp: %w", err)
+	}
+	return ts, nil
+}
+
+// VerifyTimestamp checks the timestamp token included with the signature
+// at index i was issued over the signature's value by a time-stamping
+// authority whose certificate can be validated using the pool of trusted
+// roots, at the time the token was generated. A nil pool will use the
+// system's roots.
+func (s *Signature) VerifyTimestamp(i int, roots *x509.CertPool) (*Timestamp, error) {
+	ts, err := s.Timestamp(i)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.verify(roots); err != nil {
+		return nil, fmt.Errorf("dsig: timestamp: %w", err)
+	}
+	return ts, nil
+}
+
+func parseTimestamp(token []byte) (*Timestamp, error) {
+	ci := new(cmsContentInfo)
+	if _, err := asn1.Unmarshal(token, ci); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("invalid token: expected signed data")
+	}
+	sd := new(cmsSignedData)
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, sd); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("invalid token: expected timestamp info")
+	}
+	info := new(tstInfo)
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, info); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	ts := &Timestamp{
+		Time:         info.GenTime,
+		SerialNumber: info.SerialNumber,
+		Policy:       info.Policy,
+		Token:        token,
+		info:         info,
+		sd:           sd,
+	}
+	if len(sd.Certificates.Bytes) > 0 {
+		certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+		ts.Certificates = certs
+	}
+	return ts, nil
+}
+
+// checkImprint ensures the timestamp was issued over the data.
+func (ts *Timestamp) checkImprint(data []byte) error {
+	mi := ts.info.MessageImprint
+	h, err := hashFor(mi.HashAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	hh := h.New()
+	hh.Write(data)
+	if !bytes.Equal(hh.Sum(nil), mi.HashedMessage) {
+		return errors.New("message imprint mismatch")
+	}
+	return nil
+}
+
+// verify checks the token's CMS signature and the TSA's certificate chain.
+func (ts *Timestamp) verify(roots *x509.CertPool) error {
+	if len(ts.sd.SignerInfos) != 1 {
+		return errors.New("expected one signer")
+	}
+	si := ts.sd.SignerInfos[0]
+	cert, err := ts.signerCertificate(si.SID)
+	if err != nil {
+		return err
+	}
+	h, err := hashFor(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if len(si.SignedAttrs.Bytes) == 0 {
+		return errors.New("signed attributes required")
+	}
+	// the content type attribute must match the content signed
+	ct, err := signedAttribute(si.SignedAttrs.Bytes, oidContentType)
+	if err != nil {
+		return err
+	}
+	var cto asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(ct, &cto); err != nil {
+		return fmt.Errorf("invalid content type: %w", err)
+	}
+	if !cto.Equal(ts.sd.EncapContentInfo.EContentType) {
+		return errors.New("content type mismatch")
+	}
+	// check the message digest attribute matches the content
+	digest, err := signedAttribute(si.SignedAttrs.Bytes, oidMessageDigest)
+	if err != nil {
+		return err
+	}
+	var md []byte
+	if _, err := asn1.Unmarshal(digest, &md); err != nil {
+		return fmt.Errorf("invalid message digest: %w", err)
+	}
+	hh := h.New()
+	hh.Write(ts.sd.EncapContentInfo.EContent)
+	if !bytes.Equal(hh.Sum(nil), md) {
+		return errors.New("message digest mismatch")
+	}
+	// signatures cover the attributes encoded as a set, not implicitly tagged
+	attrs := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	hh = h.New()
+	hh.Write(attrs)
+	if err := verifyWithKey(cert.PublicKey, h, hh.Sum(nil), si.Signature); err != nil {
+		return err
+	}
+	inter := x509.NewCertPool()
+	for _, c := range ts.Certificates {
+		inter.AddCert(c)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: inter,
+		CurrentTime:   ts.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	return err
+}
+
+func (ts *Timestamp) signerCertificate(sid asn1.RawValue) (*x509.Certificate, error) {
+	for _, c := range ts.Certificates {
+		switch {
+		case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+			ias := new(cmsIssuerAndSerial)
+			if _, err := asn1.Unmarshal(sid.FullBytes, ias); err != nil {
+				return nil, fmt.Errorf("invalid signer identifier: %w", err)
+			}
+			if bytes.Equal(ias.Issuer.FullBytes, c.RawIssuer) && ias.SerialNumber.Cmp(c.SerialNumber) == 0 {
+				return c, nil
+			}
+		case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+			if bytes.Equal(sid.Bytes, c.SubjectKeyId) {
+				return c, nil
+			}
+		}
+	}
+	return nil, errors.New("signer certificate not found")
+}
+
+// signedAttribute provides the first value of the attribute.
+func signedAttribute(data []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	for len(data) > 0 {
+		attr := new(cmsAttribute)
+		rest, err := asn1.Unmarshal(data, attr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed attributes: %w", err)
+		}
+		data = rest
+		if attr.Type.Equal(oid) {
+			var v asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &v); err != nil {
+				return nil, fmt.Errorf("invalid signed attributes: %w", err)
+			}
+			return v.FullBytes, nil
+		}
+	}
+	return nil, fmt.Errorf("missing signed attribute %s", oid)
+}
+
+func hashFor(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported hash algorithm %s", oid)
+}
+
+func verifyWithKey(pub crypto.PublicKey, h crypto.Hash, digest, sig []byte) error {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, h, digest, sig); err != nil {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, sig) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported token signature key")
+}
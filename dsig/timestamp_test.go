@@ -0,0 +1,328 @@
+package dsig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ASN.1 structures used to prepare timestamp responses, as defined in
+// RFC 3161 and RFC 5652.
+type tsMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type tsRequest struct {
+	Version        int
+	MessageImprint tsMessageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type tsInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint tsMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+type tsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type tsIssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type tsSignerInfo struct {
+	Version            int
+	SID                tsIssuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type tsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type tsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo tsEncapContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []tsSignerInfo `asn1:"set"`
+}
+
+type tsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type tsStatus struct {
+	Status int
+}
+
+type tsResponse struct {
+	Status tsStatus
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+var (
+	tsOIDSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	tsOIDECDSASHA256   = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	tsOIDSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	tsOIDTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	tsOIDContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	tsOIDMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+type testTSA struct {
+	*httptest.Server
+	root *testCA
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	// tamper may be used to modify the message imprint before signing
+	tamper func(*tsInfo)
+	// contentType overrides the signed content type attribute
+	contentType asn1.ObjectIdentifier
+}
+
+func newTestTSA(t *testing.T) *testTSA {
+	t.Helper()
+	tsa := &testTSA{root: newTestRoot(t)}
+	var err error
+	tsa.key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tsa.cert = newTestCert(t, "Test TSA", tsa.root, tsa.key.Public(), false, x509.ExtKeyUsageTimeStamping)
+	tsa.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/timestamp-query", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := new(tsRequest)
+		_, err = asn1.Unmarshal(body, req)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, err = w.Write(tsa.respond(t, req))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(tsa.Close)
+	return tsa
+}
+
+func (tsa *testTSA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(tsa.root.cert)
+	return pool
+}
+
+func (tsa *testTSA) respond(t *testing.T, req *tsRequest) []byte {
+	t.Helper()
+	info := &tsInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(42),
+		GenTime:        time.Now().UTC().Truncate(time.Second),
+		Nonce:          req.Nonce,
+	}
+	if tsa.tamper != nil {
+		tsa.tamper(info)
+	}
+	content, err := asn1.Marshal(*info)
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+
+	cto := tsOIDTSTInfo
+	if tsa.contentType != nil {
+		cto = tsa.contentType
+	}
+	ct, err := asn1.Marshal(cto)
+	require.NoError(t, err)
+	md, err := asn1.Marshal(sum[:])
+	require.NoError(t, err)
+	attrs, err := asn1.MarshalWithParams([]tsAttribute{
+		{Type: tsOIDContentType, Values: []asn1.RawValue{{FullBytes: ct}}},
+		{Type: tsOIDMessageDigest, Values: []asn1.RawValue{{FullBytes: md}}},
+	}, "set")
+	require.NoError(t, err)
+	as := sha256.Sum256(attrs)
+	sig, err := ecdsa.SignASN1(rand.Reader, tsa.key, as[:])
+	require.NoError(t, err)
+	implicit := append([]byte{0xa0}, attrs[1:]...)
+
+	sd := tsSignedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: tsOIDSHA256}},
+		EncapContentInfo: tsEncapContentInfo{EContentType: tsOIDTSTInfo, EContent: content},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true,
+			Bytes: tsa.cert.Raw,
+		},
+		SignerInfos: []tsSignerInfo{
+			{
+				Version:            1,
+				SID:                tsIssuerAndSerial{Issuer: asn1.RawValue{FullBytes: tsa.cert.RawIssuer}, SerialNumber: tsa.cert.SerialNumber},
+				DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: tsOIDSHA256},
+				SignedAttrs:        asn1.RawValue{FullBytes: implicit},
+				SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: tsOIDECDSASHA256},
+				Signature:          sig,
+			},
+		},
+	}
+	sdData, err := asn1.Marshal(sd)
+	require.NoError(t, err)
+	token, err := asn1.Marshal(tsContentInfo{
+		ContentType: tsOIDSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdData},
+	})
+	require.NoError(t, err)
+	resp, err := asn1.Marshal(tsResponse{Token: asn1.RawValue{FullBytes: token}})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestSignatureTimestamp(t *testing.T) {
+	tsa := newTestTSA(t)
+	k := dsig.NewES256Key()
+	p := &payload{Foo: "foo", Bar: 1}
+	sig, err := dsig.NewSignature(k, p, dsig.WithTimestamp(tsa.URL), dsig.WithTimestampClient(tsa.Client()))
+	require.NoError(t, err)
+	assert.Contains(t, sig.String(), `"header":{"tst":`)
+
+	t.Run("verify", func(t *testing.T) {
+		parsed, err := dsig.ParseSignature(sig.String())
+		require.NoError(t, err)
+		for _, s := range []*dsig.Signature{sig, parsed} {
+			ts, err := s.VerifyTimestamp(0, tsa.pool())
+			require.NoError(t, err)
+			assert.Equal(t, int64(42), ts.SerialNumber.Int64())
+			assert.WithinDuration(t, time.Now(), ts.Time, time.Minute)
+			assert.Equal(t, "1.2.3.4", ts.Policy.String())
+			require.Len(t, ts.Certificates, 1)
+			assert.Equal(t, "Test TSA", ts.Certificates[0].Subject.CommonName)
+		}
+		p2 := new(payload)
+		require.NoError(t, parsed.VerifyPayload(k.Public(), p2))
+		assert.Equal(t, p, p2)
+	})
+	t.Run("JSON round trip", func(t *testing.T) {
+		data, err := json.Marshal(&structWithSig{Name: "test", Sig: sig})
+		require.NoError(t, err)
+		out := new(structWithSig)
+		require.NoError(t, json.Unmarshal(data, out))
+		_, err = out.Sig.VerifyTimestamp(0, tsa.pool())
+		assert.NoError(t, err)
+	})
+	t.Run("untrusted root", func(t *testing.T) {
+		_, err := sig.VerifyTimestamp(0, x509.NewCertPool())
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+	t.Run("missing", func(t *testing.T) {
+		s, err := dsig.NewSignature(k, p)
+		require.NoError(t, err)
+		_, err = s.Timestamp(0)
+		assert.ErrorIs(t, err, dsig.ErrTimestampMissing)
+	})
+	t.Run("countersigned", func(t *testing.T) {
+		s, err := dsig.NewSignature(k, p, dsig.WithTimestamp(tsa.URL))
+		require.NoError(t, err)
+		require.NoError(t, s.Countersign(dsig.NewES256Key()))
+		parsed, err := dsig.ParseSignature(s.String())
+		require.NoError(t, err)
+		_, err = parsed.VerifyTimestamp(0, tsa.pool())
+		assert.NoError(t, err)
+		_, err = parsed.Timestamp(1)
+		assert.ErrorIs(t, err, dsig.ErrTimestampMissing)
+		_, err = parsed.Timestamp(2)
+		assert.ErrorContains(t, err, "dsig: no signature at index 2")
+	})
+	t.Run("timestamped countersignature", func(t *testing.T) {
+		s, err := dsig.NewSignature(k, p)
+		require.NoError(t, err)
+		opts := []dsig.SignerOption{dsig.WithTimestamp(tsa.URL), dsig.WithTimestampClient(tsa.Client())}
+		require.NoError(t, s.Countersign(dsig.NewES256Key(), opts...))
+		require.NoError(t, s.Countersign(dsig.NewES256Key(), opts...))
+		parsed, err := dsig.ParseSignature(s.String())
+		require.NoError(t, err)
+		_, err = parsed.Timestamp(0)
+		assert.ErrorIs(t, err, dsig.ErrTimestampMissing)
+		for _, i := range []int{1, 2} {
+			ts, err := parsed.VerifyTimestamp(i, tsa.pool())
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), ts.Time, time.Minute)
+		}
+	})
+	t.Run("imprint mismatch", func(t *testing.T) {
+		tsa.tamper = func(info *tsInfo) {
+			info.MessageImprint.HashedMessage = make([]byte, 32)
+		}
+		defer func() { tsa.tamper = nil }()
+		_, err := dsig.NewSignature(k, p, dsig.WithTimestamp(tsa.URL))
+		assert.ErrorContains(t, err, "dsig: timestamp: message imprint mismatch")
+	})
+	t.Run("nonce mismatch", func(t *testing.T) {
+		tsa.tamper = func(info *tsInfo) {
+			info.Nonce = big.NewInt(1)
+		}
+		defer func() { tsa.tamper = nil }()
+		_, err := dsig.NewSignature(k, p, dsig.WithTimestamp(tsa.URL))
+		assert.ErrorContains(t, err, "dsig: timestamp: nonce mismatch")
+	})
+	t.Run("content type mismatch", func(t *testing.T) {
+		tsa.contentType = tsOIDSignedData
+		defer func() { tsa.contentType = nil }()
+		s, err := dsig.NewSignature(k, p, dsig.WithTimestamp(tsa.URL))
+		require.NoError(t, err)
+		_, err = s.VerifyTimestamp(0, tsa.pool())
+		assert.ErrorContains(t, err, "dsig: timestamp: content type mismatch")
+	})
+	t.Run("stalled authority", func(t *testing.T) {
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer srv.Close()
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := dsig.NewSignature(k, p, dsig.WithTimestamp(srv.URL), dsig.WithTimestampContext(ctx))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("detached", func(t *testing.T) {
+		_, err := dsig.NewDetachedSignature(k, dsig.NewSHA256Digest([]byte("x")), dsig.WithTimestamp(tsa.URL))
+		assert.ErrorContains(t, err, "timestamps not supported with detached signatures")
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		_, err := dsig.NewSignature(k, p, dsig.WithTimestamp(srv.URL))
+		assert.ErrorContains(t, err, "dsig: timestamp: unexpected status 404")
+	})
+}
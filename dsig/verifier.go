@@ -0,0 +1,379 @@
+package dsig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeySetTTL is the period of time key sets will be cached for by
+// verifiers, unless defined otherwise.
+const DefaultKeySetTTL = time.Hour
+
+// DefaultKeySetRefetchInterval is the minimum period of time verifiers will
+// wait before fetching the same key set again, unless defined otherwise, so
+// that signatures with unknown key IDs cannot be used to flood key servers.
+const DefaultKeySetRefetchInterval = time.Minute
+
+// defaultHTTPClient is used for requests when no client is provided, as
+// http.DefaultClient will wait indefinitely for slow servers.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxKeySetSize limits the amount of data read when fetching key sets.
+const maxKeySetSize = 1 << 20
+
+// maxKeySetRedirects limits the number of redirects followed when fetching
+// key sets, as with the default HTTP client.
+const maxKeySetRedirects = 10
+
+// KeySet represents a JSON Web Key Set (JWKS) containing public keys, as
+// published at the URLs referenced by the "jku" header of signatures.
+type KeySet struct {
+	Keys []*PublicKey `json:"keys"`
+}
+
+// Key provides the public key with the matching ID, or nil.
+func (ks *KeySet) Key(kid string) *PublicKey {
+	if ks == nil {
+		return nil
+	}
+	for _, k := range ks.Keys {
+		if k != nil && k.ID() == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// HTTPClient defines the methods required to fetch key sets, implemented
+// by *http.Client.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// KeySetCache stores key sets fetched by a verifier, keyed by their URL.
+// Implementations must be safe for concurrent use.
+type KeySetCache interface {
+	// Get provides the key set stored for the URL, or nil if not found
+	// or expired.
+	Get(url string) *KeySet
+	// Set stores the key set for the URL for the period of time provided.
+	Set(url string, ks *KeySet, ttl time.Duration)
+}
+
+// Verifier checks signatures using the public keys published at the
+// URLs referenced by their "jku" headers, as long as they belong to one
+// of the allowed origins. Verifiers are safe for concurrent use.
+type Verifier struct {
+	origins []string
+	client  HTTPClient
+	cache   KeySetCache
+	ttl     time.Duration
+	refetch time.Duration
+
+	mu       sync.Mutex
+	fetched  map[string]time.Time
+	inflight map[string]*keySetFetch
+}
+
+// keySetFetch is a request for a key set shared by concurrent callers.
+type keySetFetch struct {
+	done chan struct{}
+	ks   *KeySet
+	err  error
+}
+
+// VerifierOption is used to configure a verifier.
+type VerifierOption func(*Verifier)
+
+// WithHTTPClient sets the client used to fetch key sets, which is
+// a client with a 30 second timeout otherwise. Redirects to origins that
+// are not allowed will be rejected when using an *http.Client without its
+// own CheckRedirect function; other clients must check redirects
+// themselves.
+func WithHTTPClient(c HTTPClient) VerifierOption {
+	return func(v *Verifier) {
+		v.client = c
+	}
+}
+
+// WithKeySetCache sets the cache used to store key sets, which is kept
+// in memory otherwise.
+func WithKeySetCache(c KeySetCache) VerifierOption {
+	return func(v *Verifier) {
+		v.cache = c
+	}
+}
+
+// WithKeySetTTL sets the period of time key sets will be cached for.
+func WithKeySetTTL(ttl time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.ttl = ttl
+	}
+}
+
+// WithKeySetRefetchInterval sets the minimum period of time to wait before
+// fetching a key set again when it does not contain the key requested.
+func WithKeySetRefetchInterval(d time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.refetch = d
+	}
+}
+
+// NewVerifier prepares a verifier that will only fetch key sets from
+// the allowed origins, like "https://keys.example.com". Signatures
+// referencing any other origin will be rejected.
+func NewVerifier(origins []string, opts ...VerifierOption) *Verifier {
+	v := &Verifier{
+		ttl:      DefaultKeySetTTL,
+		refetch:  DefaultKeySetRefetchInterval,
+		fetched:  make(map[string]time.Time),
+		inflight: make(map[string]*keySetFetch),
+	}
+	for _, o := range origins {
+		v.origins = append(v.origins, strings.TrimSuffix(o, "/"))
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.client == nil {
+		v.client = &http.Client{
+			Timeout:       defaultHTTPClient.Timeout,
+			CheckRedirect: v.checkRedirect,
+		}
+	} else if hc, ok := v.client.(*http.Client); ok && hc.CheckRedirect == nil {
+		c := *hc
+		c.CheckRedirect = v.checkRedirect
+		v.client = &c
+	}
+	if v.cache == nil {
+		v.cache = newMemoryKeySetCache()
+	}
+	return v
+}
+
+// Verify fetches the public keys used to create each of the signatures from
+// the URLs in their protected "jku" headers and provides the raw data that
+// was signed. Every signature must be verified for the data to be provided.
+func (v *Verifier) Verify(ctx context.Context, sig *Signature) ([]byte, error) {
+	if sig.jws == nil || len(sig.jws.Signatures) == 0 {
+		return nil, ErrKeyMismatch
+	}
+	if sig.detached {
+		return nil, errors.New("dsig: detached signature must be verified with digest")
+	}
+	var data []byte
+	for i := range sig.jws.Signatures {
+		h := sig.jws.Signatures[i].Protected
+		jku, _ := h.ExtraHeaders[headerJKU].(string)
+		k, err := v.Key(ctx, jku, h.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = sig.verifyIndex(i, nil, k); err != nil {
+			return nil, fmt.Errorf("%w: signature %d", ErrKeyMismatch, i)
+		}
+	}
+	return data, nil
+}
+
+// VerifyPayload verifies the signature like Verify and parses the data
+// into the payload.
+func (v *Verifier) VerifyPayload(ctx context.Context, sig *Signature, payload any) error {
+	data, err := v.Verify(ctx, sig)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("dsig verify: %w", err)
+	}
+	return nil
+}
+
+// Key provides the public key with the ID from the key set published at
+// the URL, which must belong to one of the allowed origins and may not
+// include a query or fragment. Cached key sets that do not contain the key
+// will be fetched again, in case keys have been rotated, as long as the
+// refetch interval has passed.
+func (v *Verifier) Key(ctx context.Context, jku, kid string) (*PublicKey, error) {
+	if jku == "" {
+		return nil, ErrJKUMissing
+	}
+	u, err := v.canonicalJKU(jku)
+	if err != nil {
+		return nil, err
+	}
+	if k := v.cache.Get(u).Key(kid); k != nil {
+		return k, nil
+	}
+	ks, err := v.keySet(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if k := ks.Key(kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+// keySet fetches the key set from the URL unless it was fetched within the
+// refetch interval, in which case the cached key set will be provided.
+// Concurrent requests for the same URL share a single fetch, while requests
+// for other URLs are not blocked.
+func (v *Verifier) keySet(ctx context.Context, u string) (*KeySet, error) {
+	v.mu.Lock()
+	if f, ok := v.inflight[u]; ok {
+		v.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.ks, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	now := time.Now()
+	if t, ok := v.fetched[u]; ok && now.Sub(t) < v.refetch {
+		v.mu.Unlock()
+		return v.cache.Get(u), nil
+	}
+	for k, t := range v.fetched {
+		if now.Sub(t) >= v.refetch {
+			delete(v.fetched, k)
+		}
+	}
+	v.fetched[u] = now
+	f := &keySetFetch{done: make(chan struct{})}
+	v.inflight[u] = f
+	v.mu.Unlock()
+
+	f.ks, f.err = v.fetch(ctx, u)
+	if f.err == nil {
+		v.cache.Set(u, f.ks, v.ttl)
+	}
+	v.mu.Lock()
+	delete(v.inflight, u)
+	v.mu.Unlock()
+	close(f.done)
+	return f.ks, f.err
+}
+
+// canonicalJKU checks the URL belongs to one of the allowed origins and
+// provides it in a consistent form, so that variations of the same URL are
+// cached and rate limited together.
+func (v *Verifier) canonicalJKU(jku string) (string, error) {
+	u, err := url.Parse(jku)
+	if err != nil || !v.allowed(u) {
+		return "", fmt.Errorf("%w: %s", ErrJKUNotAllowed, jku)
+	}
+	if u.RawQuery != "" || u.ForceQuery || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("%w: %s: query, fragment, and user info not supported", ErrJKUNotAllowed, jku)
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + u.EscapedPath(), nil
+}
+
+func (v *Verifier) allowed(u *url.URL) bool {
+	if u.Host == "" {
+		return false
+	}
+	origin := u.Scheme + "://" + u.Host
+	for _, o := range v.origins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirect ensures key set requests are not redirected away from the
+// allowed origins.
+func (v *Verifier) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxKeySetRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxKeySetRedirects)
+	}
+	if !v.allowed(req.URL) {
+		return fmt.Errorf("%w: redirect to %s", ErrJKUNotAllowed, req.URL.Redacted())
+	}
+	return nil
+}
+
+func (v *Verifier) fetch(ctx context.Context, jku string) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jku, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: fetching key set: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dsig: fetching key set: unexpected status %d", res.StatusCode)
+	}
+	ks := new(KeySet)
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxKeySetSize)).Decode(ks); err != nil {
+		return nil, fmt.Errorf("dsig: parsing key set: %w", err)
+	}
+	for i, k := range ks.Keys {
+		if k == nil {
+			return nil, fmt.Errorf("dsig: key set: key %d missing", i)
+		}
+		if err := k.Validate(); err != nil {
+			return nil, fmt.Errorf("dsig: key set: %s: %w", k.ID(), err)
+		}
+	}
+	return ks, nil
+}
+
+// memoryKeySetCache is the default cache used by verifiers.
+type memoryKeySetCache struct {
+	mu      sync.Mutex
+	entries map[string]*memoryKeySetEntry
+}
+
+type memoryKeySetEntry struct {
+	ks      *KeySet
+	expires time.Time
+}
+
+func newMemoryKeySetCache() *memoryKeySetCache {
+	return &memoryKeySetCache{
+		entries: make(map[string]*memoryKeySetEntry),
+	}
+}
+
+func (c *memoryKeySetCache) Get(url string) *KeySet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, url)
+		return nil
+	}
+	return e.ks
+}
+
+func (c *memoryKeySetCache) Set(url string, ks *KeySet, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[url] = &memoryKeySetEntry{
+		ks:      ks,
+		expires: time.Now().Add(ttl),
+	}
+}
@@ -0,0 +1,216 @@
+package dsig_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeySetServer struct {
+	*httptest.Server
+	keys     *dsig.KeySet
+	requests atomic.Int32
+}
+
+func newTestKeySetServer(t *testing.T, keys ...*dsig.PrivateKey) *testKeySetServer {
+	t.Helper()
+	s := &testKeySetServer{keys: new(dsig.KeySet)}
+	for _, k := range keys {
+		s.keys.Keys = append(s.keys.Keys, k.Public())
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if r.URL.Path != "/jwks.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(s.keys))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+type testKeySetCache struct {
+	sets map[string]*dsig.KeySet
+	ttls map[string]time.Duration
+}
+
+func (c *testKeySetCache) Get(url string) *dsig.KeySet {
+	return c.sets[url]
+}
+
+func (c *testKeySetCache) Set(url string, ks *dsig.KeySet, ttl time.Duration) {
+	c.sets[url] = ks
+	c.ttls[url] = ttl
+}
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+	k1 := dsig.NewES256Key()
+	k2 := dsig.NewEd25519Key()
+	srv := newTestKeySetServer(t, k1)
+	jku := srv.URL + "/jwks.json"
+	p := &payload{Foo: "foo", Bar: 1}
+
+	t.Run("verifies and caches", func(t *testing.T) {
+		v := dsig.NewVerifier([]string{srv.URL + "/"}, dsig.WithHTTPClient(srv.Client()))
+		sig, err := dsig.NewSignature(k1, p, dsig.WithJKU(jku))
+		require.NoError(t, err)
+		p2 := new(payload)
+		require.NoError(t, v.VerifyPayload(ctx, sig, p2))
+		assert.Equal(t, p, p2)
+		_, err = v.Verify(ctx, sig)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), srv.requests.Load())
+	})
+	t.Run("refetches for rotated keys", func(t *testing.T) {
+		srv.requests.Store(0)
+		v := dsig.NewVerifier([]string{srv.URL}, dsig.WithKeySetRefetchInterval(50*time.Millisecond))
+		sig, err := dsig.NewSignature(k2, p, dsig.WithJKU(jku))
+		require.NoError(t, err)
+		_, err = v.Verify(ctx, sig)
+		assert.ErrorIs(t, err, dsig.ErrKeyNotFound)
+
+		srv.keys.Keys = append(srv.keys.Keys, k2.Public())
+		_, err = v.Verify(ctx, sig)
+		assert.ErrorIs(t, err, dsig.ErrKeyNotFound, "refetch too soon")
+		assert.Equal(t, int32(1), srv.requests.Load())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = v.Verify(ctx, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), srv.requests.Load())
+	})
+	t.Run("unknown keys do not flood server", func(t *testing.T) {
+		srv.requests.Store(0)
+		v := dsig.NewVerifier([]string{srv.URL})
+		for i := 0; i < 10; i++ {
+			_, err := v.Key(ctx, jku, "unknown")
+			assert.ErrorIs(t, err, dsig.ErrKeyNotFound)
+		}
+		assert.Equal(t, int32(1), srv.requests.Load())
+	})
+	t.Run("custom cache", func(t *testing.T) {
+		c := &testKeySetCache{sets: map[string]*dsig.KeySet{}, ttls: map[string]time.Duration{}}
+		v := dsig.NewVerifier([]string{srv.URL}, dsig.WithKeySetCache(c), dsig.WithKeySetTTL(time.Minute))
+		k, err := v.Key(ctx, jku, k1.ID())
+		require.NoError(t, err)
+		assert.Equal(t, k1.Thumbprint(), k.Thumbprint())
+		assert.NotNil(t, c.sets[jku])
+		assert.Equal(t, time.Minute, c.ttls[jku])
+	})
+	t.Run("origin not allowed", func(t *testing.T) {
+		v := dsig.NewVerifier([]string{"https://keys.example.com"})
+		sig, err := dsig.NewSignature(k1, p, dsig.WithJKU(jku))
+		require.NoError(t, err)
+		_, err = v.Verify(ctx, sig)
+		assert.ErrorIs(t, err, dsig.ErrJKUNotAllowed)
+	})
+	t.Run("missing jku", func(t *testing.T) {
+		v := dsig.NewVerifier([]string{srv.URL})
+		sig, err := dsig.NewSignature(k1, p)
+		require.NoError(t, err)
+		_, err = v.Verify(ctx, sig)
+		assert.ErrorIs(t, err, dsig.ErrJKUMissing)
+	})
+	t.Run("missing keys in set", func(t *testing.T) {
+		bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"keys":[null]}`))
+		}))
+		t.Cleanup(bad.Close)
+		v := dsig.NewVerifier([]string{bad.URL})
+		_, err := v.Key(ctx, bad.URL+"/jwks.json", k1.ID())
+		assert.ErrorContains(t, err, "key set: key 0 missing")
+	})
+	t.Run("query and fragment variants", func(t *testing.T) {
+		srv.requests.Store(0)
+		v := dsig.NewVerifier([]string{srv.URL})
+		for _, u := range []string{jku + "?x=1", jku + "?x=2", jku + "?", jku + "#a"} {
+			_, err := v.Key(ctx, u, k1.ID())
+			assert.ErrorIs(t, err, dsig.ErrJKUNotAllowed)
+		}
+		assert.Equal(t, int32(0), srv.requests.Load())
+		_, err := v.Key(ctx, strings.ToUpper(srv.URL[:4])+srv.URL[4:]+"/jwks.json", "unknown")
+		assert.ErrorIs(t, err, dsig.ErrKeyNotFound)
+		_, err = v.Key(ctx, jku, "unknown")
+		assert.ErrorIs(t, err, dsig.ErrKeyNotFound)
+		assert.Equal(t, int32(1), srv.requests.Load(), "same key set")
+	})
+	t.Run("redirect to other origin", func(t *testing.T) {
+		other := newTestKeySetServer(t, k1)
+		redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, other.URL+"/jwks.json", http.StatusFound)
+		}))
+		t.Cleanup(redirect.Close)
+		for _, opts := range [][]dsig.VerifierOption{nil, {dsig.WithHTTPClient(redirect.Client())}} {
+			v := dsig.NewVerifier([]string{redirect.URL}, opts...)
+			_, err := v.Key(ctx, redirect.URL+"/jwks.json", k1.ID())
+			assert.ErrorIs(t, err, dsig.ErrJKUNotAllowed)
+		}
+		assert.Equal(t, int32(0), other.requests.Load())
+	})
+	t.Run("slow origin does not block others", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		t.Cleanup(slow.Close)
+		t.Cleanup(func() { close(release) })
+		v := dsig.NewVerifier([]string{slow.URL, srv.URL})
+		go func() {
+			_, _ = v.Key(ctx, slow.URL+"/jwks.json", k1.ID())
+		}()
+		time.Sleep(20 * time.Millisecond)
+		done := make(chan error, 1)
+		go func() {
+			_, err := v.Key(ctx, jku, k1.ID())
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("blocked by slow origin")
+		}
+	})
+	t.Run("protected headers of each signer", func(t *testing.T) {
+		srv2 := newTestKeySetServer(t, k2)
+		v := dsig.NewVerifier([]string{srv.URL, srv2.URL})
+		sig, err := dsig.NewSignature(k1, p, dsig.WithJKU(jku))
+		require.NoError(t, err)
+		require.NoError(t, sig.Countersign(k2, dsig.WithJKU(srv2.URL+"/jwks.json")))
+		_, err = v.Verify(ctx, sig)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), srv2.requests.Load())
+
+		sig, err = dsig.NewSignature(k1, p)
+		require.NoError(t, err)
+		data := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(sig.JSONWebSignature().FullSerialize()), &data))
+		data["header"] = map[string]any{"jku": jku}
+		raw, err := json.Marshal(data)
+		require.NoError(t, err)
+		sig, err = dsig.ParseSignature(string(raw))
+		require.NoError(t, err)
+		assert.Equal(t, jku, sig.JKU(), "unprotected header")
+		_, err = v.Verify(ctx, sig)
+		assert.ErrorIs(t, err, dsig.ErrJKUMissing)
+	})
+	t.Run("fetch failure", func(t *testing.T) {
+		v := dsig.NewVerifier([]string{srv.URL})
+		_, err := v.Key(ctx, srv.URL+"/missing.json", k1.ID())
+		assert.ErrorContains(t, err, "unexpected status 404")
+	})
+}
@@ -0,0 +1,144 @@
+package dsig
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/google/uuid"
+)
+
+// PEM block types supported when loading keys.
+const (
+	pemTypePrivateKey  = "PRIVATE KEY"
+	pemTypeCertificate = "CERTIFICATE"
+)
+
+const headerX5C jose.HeaderKey = "x5c"
+
+// ParsePrivateKeyPEM loads a private key in PKCS #8 PEM format alongside
+// the X.509 certificate chain issued for it, which may be included in the
+// same data as the key or provided separately. Certificates must be
+// ordered starting with the certificate issued for the key, followed by
+// any intermediates.
+//
+// Keys loaded with certificates will embed the chain in the "x5c" header
+// of every signature they create, as required by many national
+// e-invoicing platforms. The key's ID is determined from the public key
+// so that loading the same key will always provide the same ID.
+func ParsePrivateKeyPEM(data []byte, chain ...[]byte) (*PrivateKey, error) {
+	var pk any
+	var certs []*x509.Certificate
+	for _, d := range append([][]byte{data}, chain...) {
+		for {
+			var block *pem.Block
+			block, d = pem.Decode(d)
+			if block == nil {
+				break
+			}
+			switch block.Type {
+			case pemTypePrivateKey:
+				if pk != nil {
+					return nil, errors.New("dsig: multiple private keys")
+				}
+				k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				if err != nil {
+					return nil, fmt.Errorf("dsig: %w", err)
+				}
+				pk = k
+			case pemTypeCertificate:
+				c, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, fmt.Errorf("dsig: %w", err)
+				}
+				certs = append(certs, c)
+			default:
+				return nil, fmt.Errorf("dsig: unsupported PEM block type: %s", block.Type)
+			}
+		}
+	}
+	if pk == nil {
+		return nil, errors.New("dsig: private key not found")
+	}
+
+	k := newKey(pk, "")
+	alg, err := k.signatureAlgorithm()
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	k.jwk.Algorithm = string(alg)
+	if len(certs) > 0 {
+		pub, ok := certs[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !pub.Equal(k.jwk.Public().Key) {
+			return nil, errors.New("dsig: certificate does not match private key")
+		}
+		k.jwk.Certificates = certs
+	}
+	tp, err := k.jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	k.jwk.KeyID = uuid.NewSHA1(uuid.NameSpaceOID, tp).String()
+	return k, nil
+}
+
+// Certificates provides the X.509 certificate chain issued for the key,
+// if any.
+func (k *PrivateKey) Certificates() []*x509.Certificate {
+	return k.jwk.Certificates
+}
+
+// Certificates provides the X.509 certificate chain issued for the key,
+// if any.
+func (k *PublicKey) Certificates() []*x509.Certificate {
+	return k.jwk.Certificates
+}
+
+// x5c provides the certificate chain in the format expected by the "x5c"
+// header.
+func x5c(certs []*x509.Certificate) []string {
+	list := make([]string, len(certs))
+	for i, c := range certs {
+		list[i] = base64.StdEncoding.EncodeToString(c.Raw)
+	}
+	return list
+}
+
+// Certificates validates the certificate chain embedded in the "x5c" header
+// of the signature created with the key against the pool of trusted root
+// certificates, and provides the verified chain starting with the signer's
+// certificate, whose public key must match the key. Detached signatures
+// can only be checked with the key alone, so must also be verified with
+// VerifyDetached. A nil pool will use the system's roots.
+func (s *Signature) Certificates(key *PublicKey, roots *x509.CertPool) ([]*x509.Certificate, error) {
+	if s.jws == nil || len(s.jws.Signatures) == 0 {
+		return nil, errors.New("dsig: no signature")
+	}
+	if key == nil {
+		return nil, errors.New("dsig: key required")
+	}
+	i := 0
+	if !s.detached {
+		var err error
+		if i, err = s.signerIndex(key); err != nil {
+			return nil, err
+		}
+	}
+	chains, err := s.jws.Signatures[i].Header.Certificates(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dsig: %w", err)
+	}
+	chain := chains[0]
+	pub, ok := chain[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(key.jwk.Key) {
+		return nil, ErrKeyMismatch
+	}
+	return chain, nil
+}
@@ -0,0 +1,179 @@
+package dsig_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCert(t *testing.T, name string, parent *testCA, pub crypto.PublicKey, isCA bool, usages ...x509.ExtKeyUsage) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		ExtKeyUsage:           usages,
+	}
+	issuer := parent.cert
+	if issuer == nil {
+		// self-signed
+		issuer = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, pub, parent.key)
+	require.NoError(t, err)
+	c, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return c
+}
+
+func newTestRoot(t *testing.T) *testCA {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca := &testCA{key: k}
+	ca.cert = newTestCert(t, "Test Root", &testCA{key: k}, k.Public(), true)
+	return ca
+}
+
+func pemBlock(t *testing.T, typ string, der []byte) []byte {
+	t.Helper()
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func pkcs8(t *testing.T, k any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	require.NoError(t, err)
+	return pemBlock(t, "PRIVATE KEY", der)
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	root := newTestRoot(t)
+	ik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	inter := &testCA{key: ik, cert: newTestCert(t, "Test Intermediate", root, ik.Public(), true)}
+	_, lk, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	leaf := newTestCert(t, "Test Signer", inter, lk.Public(), false)
+
+	chain := append(pemBlock(t, "CERTIFICATE", leaf.Raw), pemBlock(t, "CERTIFICATE", inter.cert.Raw)...)
+	k, err := dsig.ParsePrivateKeyPEM(pkcs8(t, lk), chain)
+	require.NoError(t, err)
+	require.NoError(t, k.Validate())
+	assert.Len(t, k.Certificates(), 2)
+	assert.Len(t, k.Public().Certificates(), 2)
+
+	t.Run("stable ID", func(t *testing.T) {
+		k2, err := dsig.ParsePrivateKeyPEM(append(pkcs8(t, lk), chain...))
+		require.NoError(t, err)
+		assert.Equal(t, k.ID(), k2.ID())
+		assert.Len(t, k2.Certificates(), 2)
+	})
+
+	t.Run("signature certificates", func(t *testing.T) {
+		sig, err := k.Sign(&payload{Foo: "foo"})
+		require.NoError(t, err)
+		parsed, err := dsig.ParseSignature(sig.String())
+		require.NoError(t, err)
+
+		pool := x509.NewCertPool()
+		pool.AddCert(root.cert)
+		for _, s := range []*dsig.Signature{sig, parsed} {
+			certs, err := s.Certificates(k.Public(), pool)
+			require.NoError(t, err)
+			require.Len(t, certs, 3)
+			assert.Equal(t, "Test Signer", certs[0].Subject.CommonName)
+			assert.Equal(t, "Test Root", certs[2].Subject.CommonName)
+		}
+
+		_, err = parsed.Certificates(k.Public(), x509.NewCertPool())
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+		_, err = parsed.Certificates(dsig.NewES256Key().Public(), pool)
+		assert.ErrorIs(t, err, dsig.ErrKeyMismatch)
+		_, err = parsed.Certificates(nil, pool)
+		assert.ErrorContains(t, err, "key required")
+	})
+
+	t.Run("countersigned certificates", func(t *testing.T) {
+		other := dsig.NewES256Key()
+		sig, err := other.Sign(&payload{Foo: "foo"})
+		require.NoError(t, err)
+		require.NoError(t, sig.Countersign(k))
+		pool := x509.NewCertPool()
+		pool.AddCert(root.cert)
+		certs, err := sig.Certificates(k.Public(), pool)
+		require.NoError(t, err)
+		assert.Equal(t, "Test Signer", certs[0].Subject.CommonName)
+		_, err = sig.Certificates(other.Public(), pool)
+		assert.ErrorContains(t, err, "no x5c header")
+	})
+
+	t.Run("detached certificates", func(t *testing.T) {
+		sig, err := dsig.NewDetachedSignature(k, dsig.NewSHA256Digest([]byte("test")))
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		pool.AddCert(root.cert)
+		certs, err := sig.Certificates(k.Public(), pool)
+		require.NoError(t, err)
+		assert.Len(t, certs, 3)
+		_, err = sig.Certificates(dsig.NewES256Key().Public(), pool)
+		assert.ErrorIs(t, err, dsig.ErrKeyMismatch)
+	})
+
+	t.Run("key JSON includes chain", func(t *testing.T) {
+		data, err := json.Marshal(k.Public())
+		require.NoError(t, err)
+		pub := new(dsig.PublicKey)
+		require.NoError(t, json.Unmarshal(data, pub))
+		assert.Len(t, pub.Certificates(), 2)
+	})
+
+	t.Run("without certificates", func(t *testing.T) {
+		k2, err := dsig.ParsePrivateKeyPEM(pkcs8(t, lk))
+		require.NoError(t, err)
+		assert.Empty(t, k2.Certificates())
+		sig, err := k2.Sign("test")
+		require.NoError(t, err)
+		_, err = sig.Certificates(k2.Public(), nil)
+		assert.ErrorContains(t, err, "no x5c header")
+	})
+
+	t.Run("mismatched certificate", func(t *testing.T) {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		_, err = dsig.ParsePrivateKeyPEM(pkcs8(t, other), chain)
+		assert.ErrorContains(t, err, "certificate does not match private key")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := dsig.ParsePrivateKeyPEM(chain)
+		assert.ErrorContains(t, err, "private key not found")
+	})
+
+	t.Run("unsupported block", func(t *testing.T) {
+		_, err := dsig.ParsePrivateKeyPEM(pemBlock(t, "EC PRIVATE KEY", []byte{1}))
+		assert.ErrorContains(t, err, "unsupported PEM block type: EC PRIVATE KEY")
+	})
+}
@@ -3,16 +3,25 @@ package gobl
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"slices"
 	"strconv"
+	"sync"
 
 	"github.com/invopop/validation"
 
+	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/c14n"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/dsig"
 	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/internal"
+	"github.com/invopop/gobl/observe"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pkg/jsonpatch"
 	"github.com/invopop/gobl/schema"
 	"github.com/invopop/gobl/uuid"
 )
@@ -27,11 +36,30 @@ type Envelope struct {
 	// Details on what the contents are
 	Head *head.Header `json:"head" jsonschema:"title=Header"`
 	// The data inside the envelope
-	Document *schema.Object `json:"doc" jsonschema:"title=Document"`
+	Document *schema.Object `json:"doc,omitempty" jsonschema:"title=Document"`
+	// Encrypted version of the document, used instead of the document when
+	// the contents should only be readable by specific recipients.
+	Encryption *dsig.Encryption `json:"enc,omitempty" jsonschema:"title=Encryption"`
+	// Auxiliary files referenced in the header's attachments
+	Attachments []*Attachment `json:"attachments,omitempty" jsonschema:"title=Attachments"`
 	// JSON Web Signatures of the header
 	Signatures []*dsig.Signature `json:"sigs,omitempty" jsonschema:"title=Signatures"`
+	// Salt used to build the redactable digest when redaction is enabled. It is
+	// never serialized, and must be stored privately by the issuer.
+	Salt string `json:"-"`
+
+	hooks   *signHooks
+	digests *digestCache
 }
 
+var (
+	// errNoKeyMatch is used when none of the keys provided created a signature.
+	errNoKeyMatch = errors.New("no key match found")
+	// errDetachedKeysRequired is used when verifying detached signatures
+	// without keys, as they contain nothing else to check.
+	errDetachedKeysRequired = errors.New("keys required to verify detached signature")
+)
+
 // EnvelopeSchema sets the general definition of the schema ID for this version of the
 // envelope.
 var EnvelopeSchema = schema.GOBL.Add("envelope")
@@ -45,6 +73,7 @@ func NewEnvelope() *Envelope {
 	e.Head = head.NewHeader()
 	e.Document = new(schema.Object)
 	e.Signatures = make([]*dsig.Signature, 0)
+	e.digests = new(digestCache)
 	return e
 }
 
@@ -59,6 +88,12 @@ func Envelop(doc interface{}) (*Envelope, error) {
 	return e, nil
 }
 
+// EnvelopOf is the typed version of Envelop, ensuring at compile time
+// that a document is provided.
+func EnvelopOf[T any](doc T) (*Envelope, error) {
+	return Envelop(doc)
+}
+
 // Validate ensures that the envelope contains everything it should to be considered valid GoBL.
 func (e *Envelope) Validate() error {
 	return e.ValidateWithContext(context.Background())
@@ -67,7 +102,10 @@ func (e *Envelope) Validate() error {
 // Verify checks the envelope's signatures to ensure the headers they contain
 // still matches with the current headers. If a list of public keys are provided,
 // they will be used to ensure that the signatures we're signed by at least
-// one of them. If no keys are provided, only the contents will be checked.
+// one of them. If no keys are provided, only the contents will be checked,
+// and detached signatures will be rejected as they have no contents.
+// Countersigned signatures will be accepted if at least one of their signers
+// match, but every signer that claims to use one of the keys must verify.
 func (e *Envelope) Verify(keys ...*dsig.PublicKey) error {
 	if len(e.Signatures) == 0 {
 		return errors.New("no signatures to verify")
@@ -98,45 +136,116 @@ func (e *Envelope) VerifySignature(sig *dsig.Signature, keys ...*dsig.PublicKey)
 }
 
 func (e *Envelope) verifySignature(sig *dsig.Signature, keys ...*dsig.PublicKey) error {
-	if len(keys) == 0 {
-		// no keys provided, only check the contents
-		h := new(head.Header)
-		if err := sig.UnsafePayload(h); err != nil {
-			return errors.New("invalid signature payload")
+	return verifyHeaderSignature(e.Head, sig, keys...)
+}
+
+// verifyHeaderSignature checks that the signature's payload matches the
+// provided header, and if keys are provided, that one of them was used to
+// create the signature.
+func verifyHeaderSignature(hd *head.Header, sig *dsig.Signature, keys ...*dsig.PublicKey) error {
+	_, err := verifyHeaderSigners(hd, sig, keys...)
+	return err
+}
+
+// verifyHeaderSigners behaves like verifyHeaderSignature, and provides the
+// IDs of the keys whose signatures were verified.
+func verifyHeaderSigners(hd *head.Header, sig *dsig.Signature, keys ...*dsig.PublicKey) ([]string, error) {
+	if sig.Detached() {
+		return verifyDetachedSignature(hd, sig, keys...)
+	}
+	var kids []string
+	if len(keys) > 0 {
+		var err error
+		kids, err = sig.VerifyKeys(keys...)
+		if err == dsig.ErrKeyMismatch {
+			return nil, errNoKeyMatch
 		}
-		if !e.Head.Contains(h) {
-			return errors.New("header mismatch")
+		if err != nil {
+			return nil, err
 		}
-		return nil
+	}
+	// payload only trusted beyond this point if keys were provided
+	h := new(head.Header)
+	if err := sig.UnsafePayload(h); err != nil {
+		return nil, errors.New("invalid signature payload")
+	}
+	if !hd.Contains(h) {
+		return nil, errors.New("header mismatch")
+	}
+	return kids, nil
+}
+
+// verifyDetachedSignature checks a signature over the header's digest. There
+// is no payload to compare with the header, so keys are always required.
+func verifyDetachedSignature(hd *head.Header, sig *dsig.Signature, keys ...*dsig.PublicKey) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, errDetachedKeysRequired
+	}
+	if hd.Digest == nil {
+		return nil, errors.New("header digest required")
 	}
 	for _, k := range keys {
-		h := new(head.Header)
-		if err := sig.VerifyPayload(k, h); err != nil {
-			continue
-		}
-		if e.Head.Contains(h) {
-			return nil
+		if err := sig.VerifyDetached(k, hd.Digest); err == nil {
+			return []string{k.ID()}, nil
 		}
-		return errors.New("header mismatch")
 	}
-	return errors.New("no key match found")
+	return nil, errNoKeyMatch
 }
 
 // ValidateWithContext ensures that the envelope contains everything it should to be considered valid GoBL.
+// Signed envelopes with a signature policy in the header will also be checked
+// to ensure they include signatures from enough of the policy's signers,
+// although only CheckPolicy with the signers' keys can confirm the policy
+// is satisfied.
 func (e *Envelope) ValidateWithContext(ctx context.Context) error {
+	s := observe.Start(ctx, observe.OpValidate)
+	err := e.validate(ctx, true)
+	s.End(e.Document, err)
+	return err
+}
+
+func (e *Envelope) validate(ctx context.Context, policy bool) error {
+	if err := e.validateStructure(ctx); err != nil {
+		return err
+	}
+	if err := e.verifyAttachments(); err != nil {
+		return err
+	}
+	if policy && e.Signed() {
+		if err := e.checkPolicyKeyIDs(); err != nil {
+			return err
+		}
+	}
+	if e.Encryption != nil {
+		// digest can only be checked once decrypted
+		return nil
+	}
+	return wrapError(e.verifyDigest())
+}
+
+// validateStructure checks the envelope's fields and document contents,
+// without comparing digests.
+func (e *Envelope) validateStructure(ctx context.Context) error {
 	if len(e.Signatures) > 0 {
 		ctx = internal.SignedContext(ctx)
 	}
 	err := validation.ValidateStructWithContext(ctx, e,
 		validation.Field(&e.Schema, validation.Required),
 		validation.Field(&e.Head, validation.Required),
-		validation.Field(&e.Document, validation.Required), // this will also check payload
+		validation.Field(&e.Document, // this will also check payload
+			validation.When(
+				e.Encryption == nil,
+				validation.Required,
+			),
+			validation.When(
+				e.Encryption != nil,
+				validation.Nil.Error("must be empty when encrypted"),
+			),
+		),
+		validation.Field(&e.Attachments),
 		validation.Field(&e.Signatures),
 	)
-	if err != nil {
-		return wrapError(err)
-	}
-	return wrapError(e.verifyDigest())
+	return wrapError(err)
 }
 
 func (e *Envelope) verifyDigest() error {
@@ -146,31 +255,285 @@ func (e *Envelope) verifyDigest() error {
 		return err
 	}
 	if err := d1.Equals(d2); err != nil {
+		if data := e.Document.Original(); data != nil {
+			// document was migrated, so compare with the data as received
+			d2, err2 := digestData(e.canonical(), data)
+			if err2 != nil {
+				return err2
+			}
+			if d1.Equals(d2) == nil {
+				return nil
+			}
+		}
 		return ErrDigest.WithCause(err)
 	}
+	if e.Salt != "" {
+		if e.Head.RedactableDigest == nil {
+			return ErrDigest.WithReason("missing redactable digest")
+		}
+		rd, err := e.RedactableDigest()
+		if err != nil {
+			return err
+		}
+		if err := e.Head.RedactableDigest.Equals(rd); err != nil {
+			return ErrDigest.WithCause(err)
+		}
+	}
 	return nil
 }
 
 // Sign uses the private key to sign the envelope headers. Additional validation
 // rules may be applied to signed documents, so the document will be signed,
 // then validated, and if the validation fails, the signature will be removed.
+//
+// Any hooks registered to run before signing will be called first, and the
+// envelope recalculated afterwards if this is the first signature. Hooks
+// registered to run after signing are called once the signature has been
+// added and validated.
 func (e *Envelope) Sign(key *dsig.PrivateKey) error {
+	s := observe.Start(context.Background(), observe.OpSign)
+	err := e.sign(key, false)
+	s.End(e.Document, err)
+	return err
+}
+
+// SignDetached behaves like Sign, but creates a detached signature over the
+// header's digest instead of embedding the complete header in the signature,
+// which helps keep signed envelopes small. Detached signatures only cover the
+// document's digest: the header's signature policy, attachments, stamps, and
+// other properties are not signed, so they should only be used when the
+// digest alone is meaningful to the parties involved. Detached signatures
+// can only be verified with keys.
+func (e *Envelope) SignDetached(key *dsig.PrivateKey) error {
+	s := observe.Start(context.Background(), observe.OpSign)
+	err := e.sign(key, true)
+	s.End(e.Document, err)
+	return err
+}
+
+func (e *Envelope) sign(key *dsig.PrivateKey, detached bool) error {
 	if e.Head == nil {
 		return ErrValidation.WithReason("header required")
 	}
-	sig, err := key.Sign(e.Head)
+	if hooks := e.beforeSignHooks(); len(hooks) > 0 {
+		if err := e.runSignHooks(hooks); err != nil {
+			return err
+		}
+		if !e.Signed() && e.Encryption == nil {
+			// hooks may have modified the document
+			if err := e.Calculate(); err != nil {
+				return err
+			}
+		}
+	}
+	var sig *dsig.Signature
+	var err error
+	if detached {
+		sig, err = dsig.NewDetachedSignature(key, e.Head.Digest)
+	} else {
+		sig, err = key.Sign(e.Head)
+	}
 	if err != nil {
 		return ErrSignature.WithCause(err)
 	}
 	e.Signatures = append(e.Signatures, sig)
-	if err := e.Validate(); err != nil {
-		// invalid envlopes cannot be signed
-		e.Signatures = nil
+	// signature policies may require additional signatures, so are not checked
+	if err := e.validate(context.Background(), false); err != nil {
+		// invalid envlopes cannot be signed, but existing signatures are kept
+		e.Signatures = e.Signatures[:len(e.Signatures)-1]
+		if len(e.Signatures) == 0 {
+			e.Signatures = nil
+		}
+		return err
+	}
+	return e.runSignHooks(e.afterSignHooks())
+}
+
+// Countersign adds a signature using the private key to the envelope's
+// last signature, so that both signers cover exactly the same header. This
+// differs from Sign, which will create a new signature over the current
+// header that may include stamps added after the first signature. Existing
+// signatures are never modified if countersigning fails.
+func (e *Envelope) Countersign(key *dsig.PrivateKey) error {
+	s := observe.Start(context.Background(), observe.OpSign)
+	err := e.countersign(key)
+	s.End(e.Document, err)
+	return err
+}
+
+func (e *Envelope) countersign(key *dsig.PrivateKey) error {
+	if !e.Signed() {
+		return ErrSignature.WithReason("no signature to countersign")
+	}
+	i := len(e.Signatures) - 1
+	orig := e.Signatures[i]
+	if err := e.verifySignature(orig); err != nil {
+		return ErrSignature.WithCause(err)
+	}
+	// work on a copy so that the original remains untouched on failure
+	sig, err := dsig.ParseSignature(orig.String())
+	if err != nil {
+		return ErrSignature.WithCause(err)
+	}
+	if err := sig.Countersign(key); err != nil {
+		return ErrSignature.WithCause(err)
+	}
+	e.Signatures[i] = sig
+	if err := e.validate(context.Background(), false); err != nil {
+		e.Signatures[i] = orig
+		return err
+	}
+	return e.runSignHooks(e.afterSignHooks())
+}
+
+// ResignOptions define how the envelope's signatures should be rotated.
+type ResignOptions struct {
+	// Revoked contains the IDs of the keys whose signatures should be removed,
+	// including countersigned signatures that contain one of them.
+	Revoked []string
+	// Keys used to verify the signatures that will be kept. If empty, only
+	// their contents will be compared with the header, and detached
+	// signatures cannot be kept.
+	Keys []*dsig.PublicKey
+	// Update allows the header's digest to be replaced when the document has
+	// been modified since it was signed. Every existing signature must be
+	// revoked, as they would no longer match the header.
+	Update bool
+}
+
+// Resign rotates the envelope's signatures by removing any created with
+// the revoked key IDs and signing again with the new key.
+//
+// Existing signatures are checked against the current header and its digest
+// against the document before anything is changed, so that tampered
+// envelopes are never signed again. Documents modified since they were
+// signed will only be accepted if the Update option is set. If any step
+// fails, the envelope's header digest and signatures are left exactly as
+// they were.
+func (e *Envelope) Resign(key *dsig.PrivateKey, opts *ResignOptions) error {
+	s := observe.Start(context.Background(), observe.OpSign)
+	err := e.resign(key, opts)
+	s.End(e.Document, err)
+	return err
+}
+
+func (e *Envelope) resign(key *dsig.PrivateKey, opts *ResignOptions) error {
+	if e.Head == nil {
+		return ErrValidation.WithReason("header required")
+	}
+	if opts == nil {
+		opts = new(ResignOptions)
+	}
+	sigs, digest := e.Signatures, e.Head.Digest
+	restore := func() {
+		e.Signatures = sigs
+		e.Head.Digest = digest
+	}
+
+	var kept []*dsig.Signature
+	for i, sig := range sigs {
+		if signedWithAny(sig, opts.Revoked) {
+			if sig.Detached() {
+				// nothing to compare without the revoked key
+				continue
+			}
+			if err := e.verifySignature(sig); err != nil {
+				return ErrSignature.WithReason("signature %d: %s", i, err.Error())
+			}
+			continue
+		}
+		if err := e.verifySignature(sig, opts.Keys...); err != nil {
+			return ErrSignature.WithReason("signature %d: %s", i, err.Error())
+		}
+		kept = append(kept, sig)
+	}
+	if e.Encryption == nil {
+		if err := e.verifyDigest(); err != nil {
+			if !opts.Update {
+				return ErrDigest.WithReason("document modified since signed")
+			}
+			if len(kept) > 0 {
+				return ErrSignature.WithReason("all signatures must be revoked to update the digest")
+			}
+			d, err := e.Digest()
+			if err != nil {
+				return err
+			}
+			e.Head.Digest = d
+		}
+	}
+	e.Signatures = kept
+	if err := e.sign(key, false); err != nil {
+		restore()
 		return err
 	}
 	return nil
 }
 
+// signedWithAny returns true if the signature was created by one of the
+// keys.
+func signedWithAny(sig *dsig.Signature, kids []string) bool {
+	for _, kid := range sig.KeyIDs() {
+		if slices.Contains(kids, kid) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPolicy determines if the signatures in the envelope satisfy the
+// signature policy defined in the header, if any. Only signers whose
+// signatures are verified with the public keys provided will be counted,
+// so the keys of the policy's signers are required.
+func (e *Envelope) CheckPolicy(keys ...*dsig.PublicKey) error {
+	if e.Head == nil || e.Head.Policy == nil {
+		return nil
+	}
+	if len(keys) == 0 {
+		return ErrSignature.WithReason("policy: keys required to verify signers")
+	}
+	kids := make([]string, 0, len(e.Signatures))
+	for i, s := range e.Signatures {
+		if s == nil {
+			continue
+		}
+		ids, err := verifyHeaderSigners(e.Head, s, keys...)
+		if err != nil {
+			if errors.Is(err, errNoKeyMatch) {
+				// signed by someone else
+				continue
+			}
+			return ErrSignature.WithReason("policy: signature %d: %s", i, err.Error())
+		}
+		kids = append(kids, ids...)
+	}
+	return checkPolicySigners(e.Head.Policy, kids)
+}
+
+// checkPolicyKeyIDs compares the policy with the key IDs included in the
+// signature headers. This cannot confirm the policy is satisfied, as the
+// signatures are not verified, but will detect envelopes that are
+// missing signatures.
+func (e *Envelope) checkPolicyKeyIDs() error {
+	if e.Head == nil || e.Head.Policy == nil {
+		return nil
+	}
+	kids := make([]string, 0, len(e.Signatures))
+	for _, s := range e.Signatures {
+		if s != nil {
+			kids = append(kids, s.KeyIDs()...)
+		}
+	}
+	return checkPolicySigners(e.Head.Policy, kids)
+}
+
+func checkPolicySigners(p *head.SignaturePolicy, kids []string) error {
+	if err := p.Check(kids); err != nil {
+		return ErrSignature.WithReason("policy: %s", err.Error())
+	}
+	return nil
+}
+
 // Signed returns true if the envelope has signatures.
 func (e *Envelope) Signed() bool {
 	return len(e.Signatures) > 0
@@ -181,6 +544,70 @@ func (e *Envelope) Unsign() {
 	e.Signatures = nil
 }
 
+// Encrypt will encrypt the envelope's document so that it can only be read
+// by the holders of the private keys of the recipients. The header, including
+// the digest of the original document, remains readable and any signatures
+// will continue to be valid. The plain document will be removed from the
+// envelope.
+func (e *Envelope) Encrypt(recipients ...*dsig.PublicKey) error {
+	if e.Encryption != nil {
+		return ErrEncrypted.WithReason("already encrypted")
+	}
+	if e.Document == nil || e.Document.IsEmpty() {
+		return ErrNoDocument
+	}
+	if e.Head == nil {
+		return ErrValidation.WithReason("header required")
+	}
+	if err := e.verifyDigest(); err != nil {
+		return err
+	}
+	data := e.Document.Original() // keep data matching the digest
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(e.Document); err != nil {
+			return ErrMarshal.WithCause(err)
+		}
+	}
+	enc, err := dsig.Encrypt(data, recipients...)
+	if err != nil {
+		return ErrEncrypted.WithCause(err)
+	}
+	e.Encryption = enc
+	e.Document = nil
+	return nil
+}
+
+// Decrypt uses the private key to restore the envelope's encrypted document,
+// which will be checked against the digest in the header before being
+// accepted.
+func (e *Envelope) Decrypt(key *dsig.PrivateKey) error {
+	if e.Encryption == nil {
+		return ErrEncrypted.WithReason("not encrypted")
+	}
+	data, err := e.Encryption.Decrypt(key)
+	if err != nil {
+		return ErrEncrypted.WithCause(err)
+	}
+	doc := new(schema.Object)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return ErrUnmarshal.WithCause(err)
+	}
+	orig := e.Document
+	e.Document = doc
+	if err := e.verifyDigest(); err != nil {
+		e.Document = orig
+		return err
+	}
+	e.Encryption = nil
+	return nil
+}
+
+// Encrypted returns true if the envelope's document has been encrypted.
+func (e *Envelope) Encrypted() bool {
+	return e.Encryption != nil
+}
+
 // Insert takes the provided document and inserts it into this
 // envelope. Calculate will be called automatically.
 func (e *Envelope) Insert(doc interface{}) error {
@@ -200,6 +627,7 @@ func (e *Envelope) Insert(doc interface{}) error {
 			return wrapError(err)
 		}
 	}
+	e.Encryption = nil
 
 	if err := e.calculate(); err != nil {
 		return wrapError(err)
@@ -208,11 +636,79 @@ func (e *Envelope) Insert(doc interface{}) error {
 	return nil
 }
 
+// Patch applies the JSON Patch (RFC 6902) operations provided in the data
+// to the envelope's document, recalculates, and records the patch in the
+// header's revision history alongside the digests before and after the
+// change. Only draft envelopes that have not been signed or encrypted may
+// be patched. If any step fails, the original document will be restored.
+func (e *Envelope) Patch(data []byte) error {
+	if e.Signed() {
+		return ErrSignature.WithReason("cannot patch signed envelope")
+	}
+	if e.Encryption != nil {
+		return ErrEncrypted
+	}
+	if e.Document == nil || e.Document.IsEmpty() {
+		return ErrNoDocument
+	}
+	if e.Head == nil {
+		return ErrValidation.WithReason("header required")
+	}
+	p, err := jsonpatch.Decode(data)
+	if err != nil {
+		return ErrUnmarshal.WithCause(err)
+	}
+	prev, err := e.Digest()
+	if err != nil {
+		return err
+	}
+	src, err := json.Marshal(e.Document)
+	if err != nil {
+		return ErrMarshal.WithCause(err)
+	}
+	out, err := p.Apply(src)
+	if err != nil {
+		return ErrValidation.WithCause(err)
+	}
+	doc := new(schema.Object)
+	if err := json.Unmarshal(out, doc); err != nil {
+		return ErrUnmarshal.WithCause(err)
+	}
+	orig, dig := e.Document, e.Head.Digest
+	e.Document = doc
+	if err := e.calculate(); err != nil {
+		e.Document, e.Head.Digest = orig, dig
+		return wrapError(err)
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return ErrMarshal.WithCause(err)
+	}
+	at := cal.ThisSecond()
+	e.Head.AddRevision(&head.Revision{
+		Previous: prev,
+		Digest:   e.Head.Digest,
+		Patch:    raw,
+		At:       &at,
+	})
+	return nil
+}
+
 // Calculate is used to perform calculations on the envelope's
 // document contents to ensure everything looks correct.
 // Headers will be refreshed to ensure they have the latest valid
 // digest.
 func (e *Envelope) Calculate() error {
+	s := observe.Start(context.Background(), observe.OpCalculate)
+	err := e.calculateDocument()
+	s.End(e.Document, err)
+	return err
+}
+
+func (e *Envelope) calculateDocument() error {
+	if e.Encryption != nil {
+		return ErrEncrypted
+	}
 	if e.Document == nil {
 		return ErrNoDocument
 	}
@@ -226,6 +722,9 @@ func (e *Envelope) Calculate() error {
 func (e *Envelope) calculate() error {
 	// Always set our schema version
 	e.Schema = EnvelopeSchema
+	if e.digests == nil {
+		e.digests = new(digestCache)
+	}
 
 	// arm doors and cross check
 	if err := e.Document.Calculate(); err != nil {
@@ -244,24 +743,108 @@ func (e *Envelope) calculate() error {
 	if err != nil {
 		return err
 	}
+	if e.Salt != "" {
+		e.Head.RedactableDigest, err = e.RedactableDigest()
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// Digest calculates a digital digest using the canonical JSON of the document.
+// Digest calculates a digital digest using the canonical JSON of the document,
+// serialized according to the canonicalization method defined in the header.
+//
+// The document is always serialized so that any changes made to it are
+// detected, but the canonical form is only determined again when the
+// serialized data differs from the last time a digest was calculated.
 func (e *Envelope) Digest() (*dsig.Digest, error) {
 	data, err := json.Marshal(e.Document)
 	if err != nil {
 		return nil, ErrMarshal.WithCause(err)
 	}
+	method := e.canonical()
+	if d := e.digests.get(method, data); d != nil {
+		return d, nil
+	}
+	d, err := digestData(method, data)
+	if err != nil {
+		return nil, err
+	}
+	e.digests.set(method, data, d)
+	return d, nil
+}
+
+// digestCache keeps the digest of the last serialized document data,
+// identified by a fingerprint of the data instead of a copy.
+type digestCache struct {
+	sync.Mutex
+	method cbc.Key
+	sum    *[sha256.Size]byte
+	digest dsig.Digest
+}
+
+func (c *digestCache) get(method cbc.Key, data []byte) *dsig.Digest {
+	if c == nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	c.Lock()
+	defer c.Unlock()
+	if c.sum == nil || c.method != method || *c.sum != sum {
+		return nil
+	}
+	d := c.digest
+	return &d
+}
+
+func (c *digestCache) set(method cbc.Key, data []byte, d *dsig.Digest) {
+	if c == nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	c.Lock()
+	defer c.Unlock()
+	c.method = method
+	c.sum = &sum
+	c.digest = *d
+}
+
+func (e *Envelope) canonical() cbc.Key {
+	if e.Head == nil {
+		return cbc.KeyEmpty
+	}
+	return e.Head.Canonical
+}
+
+func digestData(method cbc.Key, data []byte) (*dsig.Digest, error) {
+	var cd []byte
+	var err error
 	r := bytes.NewReader(data)
-	cd, err := c14n.CanonicalJSON(r)
+	switch method {
+	case head.CanonicalJCS:
+		cd, err = c14n.JCS(r)
+	default:
+		cd, err = c14n.CanonicalJSON(r)
+	}
 	if err != nil {
 		return nil, ErrInternal.WithReason("canonical JSON error: %w", err)
 	}
 	return dsig.NewSHA256Digest(cd), nil
 }
 
+// Migration provides the report of migrations applied to the envelope's
+// document when it was loaded, or nil if the document was already up to
+// date. Migrated documents will need to be recalculated before they can be
+// signed again.
+func (e *Envelope) Migration() *schema.MigrationReport {
+	if e.Document == nil {
+		return nil
+	}
+	return e.Document.Migration()
+}
+
 // Extract the contents of the envelope into the provided document type.
 func (e *Envelope) Extract() interface{} {
 	if e.Document == nil {
@@ -270,6 +853,29 @@ func (e *Envelope) Extract() interface{} {
 	return e.Document.Instance()
 }
 
+// ExtractAs provides the envelope's document as the requested type, for
+// example:
+//
+//	inv, err := gobl.ExtractAs[*bill.Invoice](env)
+//
+// An error will be provided if the envelope has no document or the document
+// is of a different type.
+func ExtractAs[T any](e *Envelope) (T, error) {
+	var zero T
+	if e.Encryption != nil {
+		return zero, ErrEncrypted
+	}
+	doc := e.Extract()
+	if doc == nil {
+		return zero, ErrNoDocument
+	}
+	out, ok := doc.(T)
+	if !ok {
+		return zero, ErrDocumentType.WithReason("expected %T, found %T", zero, doc)
+	}
+	return out, nil
+}
+
 // Correct will attempt to build a new envelope as a correction of the
 // current envelope contents, if possible.
 func (e *Envelope) Correct(opts ...schema.Option) (*Envelope, error) {
@@ -319,3 +925,48 @@ func (e *Envelope) CorrectionOptionsSchema() (interface{}, error) {
 	}
 	return opts, nil
 }
+
+// InvoiceResolver builds a bill.InvoiceResolver around a function that finds
+// envelopes, so that the correction history of invoices can be traversed
+// using envelopes stored in a repository. References to envelopes that do
+// not contain invoices will result in an error.
+func InvoiceResolver(fn func(ref *org.DocumentRef) (*Envelope, error)) bill.InvoiceResolver {
+	return func(ref *org.DocumentRef) (*bill.Invoice, error) {
+		e, err := fn(ref)
+		if err != nil || e == nil {
+			return nil, err
+		}
+		inv, ok := e.Extract().(*bill.Invoice)
+		if !ok {
+			return nil, ErrValidation.WithReason("envelope '%s' does not contain an invoice", e.Head.UUID)
+		}
+		return inv, nil
+	}
+}
+
+// Relate adds a link to the envelope's header describing the relation with
+// the target envelope, including its UUID and digest. Relations may be added
+// while in the draft state.
+func (e *Envelope) Relate(key, rel cbc.Key, target *Envelope) error {
+	if e.Head == nil || target.Head == nil {
+		return ErrInternal.WithReason("missing head")
+	}
+	l := head.NewRelationLink(key, rel, target.Head)
+	if err := l.Validate(); err != nil {
+		return ErrValidation.WithCause(err)
+	}
+	e.Head.AddLink(l)
+	return nil
+}
+
+// CheckRelations ensures that the relation links between the two envelopes
+// are consistent with each other.
+func CheckRelations(a, b *Envelope) error {
+	if a.Head == nil || b.Head == nil {
+		return ErrInternal.WithReason("missing head")
+	}
+	if err := head.CheckRelations(a.Head, b.Head); err != nil {
+		return ErrValidation.WithCause(err)
+	}
+	return nil
+}
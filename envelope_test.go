@@ -1,6 +1,8 @@
 package gobl_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -14,11 +16,14 @@ import (
 	"github.com/invopop/gobl/addons/co/dian"
 	"github.com/invopop/gobl/addons/es/facturae"
 	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/c14n"
 	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/dsig"
 	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/note"
+	"github.com/invopop/gobl/observe"
+	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/schema"
 	"github.com/invopop/gobl/uuid"
 )
@@ -299,6 +304,16 @@ func TestEnvelopeSign(t *testing.T) {
 		assert.True(t, env.Signed())
 	})
 
+	t.Run("sign with other key types", func(t *testing.T) {
+		for _, key := range []*dsig.PrivateKey{dsig.NewEd25519Key(), dsig.NewPS256Key()} {
+			env := gobl.NewEnvelope()
+			require.NoError(t, env.Insert(&note.Message{Content: "Test Message"}))
+			require.NoError(t, env.Sign(key))
+			assert.NoError(t, env.Verify(key.Public()))
+			assert.ErrorContains(t, env.Verify(testKey.Public()), "no key match found")
+		}
+	})
+
 	t.Run("unsign document", func(t *testing.T) {
 		env := gobl.NewEnvelope()
 		require.NoError(t, env.Insert(&note.Message{Content: "Test Message"}))
@@ -569,3 +584,605 @@ func testNoteExample() *note.Message {
 	m.UUID = uuid.MustParse("e8c70516-0098-11ef-92c8-0242ac120002")
 	return m
 }
+
+func TestEnvelopeEncrypt(t *testing.T) {
+	k1 := dsig.NewES256Key()
+	k2 := dsig.NewES256Key()
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, env.Sign(testKey))
+		dig := env.Head.Digest.String()
+
+		require.NoError(t, env.Encrypt(k1.Public(), k2.Public()))
+		assert.True(t, env.Encrypted())
+		assert.Nil(t, env.Document)
+		assert.Nil(t, env.Extract())
+		assert.Equal(t, dig, env.Head.Digest.String())
+		assert.NoError(t, env.Validate())
+		assert.NoError(t, env.Verify(testKey.Public()))
+		assert.ErrorIs(t, env.Calculate(), gobl.ErrEncrypted)
+
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), testMessageContent)
+		assert.NotContains(t, string(data), `"doc"`)
+
+		env2 := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env2))
+		require.True(t, env2.Encrypted())
+		err = env2.Decrypt(testKey)
+		assert.ErrorContains(t, err, "encrypted: decryption failed")
+
+		require.NoError(t, env2.Decrypt(k2))
+		assert.False(t, env2.Encrypted())
+		assert.NoError(t, env2.Validate())
+		msg, ok := env2.Extract().(*note.Message)
+		require.True(t, ok)
+		assert.Equal(t, testMessageContent, msg.Content)
+	})
+
+	t.Run("RSA-OAEP recipient", func(t *testing.T) {
+		k3 := dsig.NewRS256Key()
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, env.Sign(k3))
+		dig := env.Head.Digest.String()
+		require.NoError(t, env.Encrypt(k3.Public()))
+		assert.Equal(t, dig, env.Head.Digest.String())
+
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+		env2 := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env2))
+		require.NoError(t, env2.Decrypt(k3))
+		assert.NoError(t, env2.Validate())
+		assert.NoError(t, env2.Verify(k3.Public()))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		env := gobl.NewEnvelope()
+		assert.ErrorIs(t, env.Encrypt(k1.Public()), gobl.ErrNoDocument)
+		assert.ErrorContains(t, env.Decrypt(k1), "encrypted: not encrypted")
+
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, env.Encrypt(k1.Public()))
+		assert.ErrorContains(t, env.Encrypt(k1.Public()), "encrypted: already encrypted")
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		env.Head.Digest = dsig.NewSHA256Digest([]byte("foo"))
+		assert.ErrorIs(t, env.Encrypt(k1.Public()), gobl.ErrDigest)
+	})
+}
+
+func TestEnvelopePatch(t *testing.T) {
+	t.Run("apply patches", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		d0 := env.Head.Digest
+
+		err = env.Patch([]byte(`[{"op":"replace","path":"/content","value":"first change"}]`))
+		require.NoError(t, err)
+		msg := env.Extract().(*note.Message)
+		assert.Equal(t, "first change", msg.Content)
+		require.Len(t, env.Head.Revisions, 1)
+		r := env.Head.LastRevision()
+		assert.NoError(t, r.Previous.Equals(d0))
+		assert.NoError(t, r.Digest.Equals(env.Head.Digest))
+		assert.NotNil(t, r.At)
+		assert.Contains(t, string(r.Patch), `"first change"`)
+
+		err = env.Patch([]byte(`[{"op":"add","path":"/title","value":"Title"}]`))
+		require.NoError(t, err)
+		require.Len(t, env.Head.Revisions, 2)
+		assert.NoError(t, env.Head.Revisions[1].Previous.Equals(env.Head.Revisions[0].Digest))
+		assert.NoError(t, env.Validate())
+
+		require.NoError(t, env.Sign(testKey))
+		err = env.Patch([]byte(`[{"op":"remove","path":"/title"}]`))
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+		assert.ErrorContains(t, err, "cannot patch signed envelope")
+	})
+
+	t.Run("failed patch", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		dig := env.Head.Digest.String()
+
+		err = env.Patch([]byte(`[{"op":"test","path":"/content","value":"other"}]`))
+		assert.ErrorContains(t, err, "test failed")
+		err = env.Patch([]byte(`{"op":"add"}`))
+		assert.ErrorIs(t, err, gobl.ErrUnmarshal)
+		err = env.Patch([]byte(`[{"op":"replace","path":"/$schema","value":"https://gobl.org/draft-0/bill/invoice"}]`))
+		assert.Error(t, err)
+
+		assert.Equal(t, testMessageContent, env.Extract().(*note.Message).Content)
+		assert.Equal(t, dig, env.Head.Digest.String())
+		assert.Empty(t, env.Head.Revisions)
+		assert.NoError(t, env.Validate())
+	})
+
+	t.Run("broken chain", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, env.Patch([]byte(`[{"op":"add","path":"/title","value":"A"}]`)))
+		require.NoError(t, env.Patch([]byte(`[{"op":"replace","path":"/title","value":"B"}]`)))
+		env.Head.Revisions[1].Previous = env.Head.Revisions[1].Digest
+		err = env.Validate()
+		assert.ErrorContains(t, err, "revs: revision 1: previous digest mismatch")
+	})
+}
+
+func TestEnvelopeExternal(t *testing.T) {
+	id := schema.ID("https://example.com/schemas/envelope-test")
+	require.NoError(t, schema.RegisterExternal(id, []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://example.com/schemas/envelope-test",
+		"type": "object",
+		"properties": {
+			"code": { "$ref": "https://gobl.org/draft-0/cbc/code" }
+		},
+		"required": ["code"]
+	}`)))
+
+	ext, err := schema.NewExternal(id, []byte(`{"code":"ABC-123"}`))
+	require.NoError(t, err)
+	env, err := gobl.Envelop(ext)
+	require.NoError(t, err)
+	assert.NoError(t, env.Validate())
+	require.NoError(t, env.Sign(testKey))
+
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	env2 := new(gobl.Envelope)
+	require.NoError(t, json.Unmarshal(data, env2))
+	assert.NoError(t, env2.Validate())
+	assert.NoError(t, env2.Verify(testKey.Public()))
+	assert.Equal(t, "ABC-123", env2.Extract().(*schema.External).Get("code"))
+
+	ext, err = schema.NewExternal(id, []byte(`{"code":"  bad  "}`))
+	require.NoError(t, err)
+	env, err = gobl.Envelop(ext)
+	require.NoError(t, err)
+	assert.ErrorContains(t, env.Validate(), "/code")
+}
+
+func TestEnvelopeSignaturePolicy(t *testing.T) {
+	k1 := dsig.NewES256Key()
+	k2 := dsig.NewES256Key()
+	k3 := dsig.NewES256Key()
+
+	env, err := gobl.Envelop(testNoteExample())
+	require.NoError(t, err)
+	env.Head.Policy = &head.SignaturePolicy{
+		Min: 2,
+		Signers: []*head.PolicySigner{
+			{KeyID: k1.ID(), Role: "finance"},
+			{KeyID: k2.ID(), Role: "manager"},
+			{KeyID: k3.ID(), Role: "manager"},
+		},
+		Roles: []cbc.Key{"finance"},
+	}
+	require.NoError(t, env.Validate())
+	assert.ErrorContains(t, env.CheckPolicy(k1.Public()), "found 0")
+	assert.ErrorContains(t, env.CheckPolicy(), "keys required to verify signers")
+
+	require.NoError(t, env.Sign(k2))
+	err = env.Validate()
+	assert.ErrorIs(t, err, gobl.ErrSignature)
+	assert.ErrorContains(t, err, "policy: requires 2 signatures from policy signers, found 1")
+
+	require.NoError(t, env.Sign(k3))
+	assert.ErrorContains(t, env.Validate(), "policy: missing signature for role 'finance'")
+
+	require.NoError(t, env.Sign(k1))
+	assert.NoError(t, env.Validate())
+	assert.NoError(t, env.Verify(k1.Public(), k2.Public(), k3.Public()))
+	assert.NoError(t, env.CheckPolicy(k1.Public(), k2.Public(), k3.Public()))
+	assert.ErrorContains(t, env.CheckPolicy(k2.Public(), k3.Public()), "missing signature for role 'finance'")
+}
+
+func TestInvoiceResolver(t *testing.T) {
+	env := gobl.NewEnvelope()
+	data, err := os.ReadFile("./examples/es/invoice-es-es.env.yaml")
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, env))
+	require.NoError(t, env.Calculate())
+
+	e2, err := env.Correct(
+		bill.Credit,
+		bill.WithReason("refund"),
+		bill.WithExtension(facturae.ExtKeyCorrection, "01"),
+	)
+	require.NoError(t, err)
+	cn := e2.Extract().(*bill.Invoice)
+
+	resolve := gobl.InvoiceResolver(func(ref *org.DocumentRef) (*gobl.Envelope, error) {
+		inv := env.Extract().(*bill.Invoice)
+		if ref.Series == inv.Series && ref.Code == inv.Code {
+			return env, nil
+		}
+		return nil, nil
+	})
+	list, err := cn.CorrectionHistory(resolve)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	b, err := bill.NetCorrectionBalance(list)
+	require.NoError(t, err)
+	assert.True(t, b.Payable.IsZero(), "full refund")
+
+	msg, err := gobl.Envelop(testNoteExample())
+	require.NoError(t, err)
+	resolve = gobl.InvoiceResolver(func(_ *org.DocumentRef) (*gobl.Envelope, error) {
+		return msg, nil
+	})
+	_, err = cn.CorrectionHistory(resolve)
+	assert.ErrorContains(t, err, "does not contain an invoice")
+}
+
+func TestEnvelopeRelations(t *testing.T) {
+	inv := testAttachmentEnvelope(t)
+	cn := testAttachmentEnvelope(t)
+	cn.Head = head.NewHeader()
+	require.NoError(t, cn.Calculate())
+
+	require.NoError(t, cn.Relate("original", head.LinkRelCorrects, inv))
+	require.NoError(t, inv.Relate("correction", head.LinkRelCorrectedBy, cn))
+	assert.NoError(t, gobl.CheckRelations(cn, inv))
+	assert.NoError(t, cn.Validate())
+
+	err := cn.Relate("bad", "unknown", inv)
+	assert.ErrorIs(t, err, gobl.ErrValidation)
+
+	inv.Head.Link("correction").Rel = head.LinkRelPaidBy
+	err = gobl.CheckRelations(cn, inv)
+	assert.ErrorIs(t, err, gobl.ErrValidation)
+	assert.ErrorContains(t, err, "missing inverse")
+}
+
+func TestEnvelopeCanonicalJCS(t *testing.T) {
+	env := testAttachmentEnvelope(t)
+	env.Head.Canonical = head.CanonicalJCS
+	require.NoError(t, env.Calculate())
+
+	// digest must be reproducible using only the JCS output of the document
+	data, err := json.Marshal(env.Document)
+	require.NoError(t, err)
+	cd, err := c14n.JCS(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, dsig.NewSHA256Digest(cd).Value, env.Head.Digest.Value)
+
+	require.NoError(t, env.Sign(testKey))
+	assert.NoError(t, env.Validate())
+
+	env.Head.Canonical = "unknown"
+	assert.ErrorContains(t, env.Validate(), "c14n: must be a valid value")
+}
+
+func TestEnvelopeDigestReuse(t *testing.T) {
+	env, err := gobl.Envelop(&note.Message{Content: "Test message"})
+	require.NoError(t, err)
+	d1, err := env.Digest()
+	require.NoError(t, err)
+	d2, err := env.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, d1, d2)
+	assert.NotSame(t, d1, d2)
+
+	t.Run("canonical method", func(t *testing.T) {
+		env.Head.Canonical = head.CanonicalJCS
+		d3, err := env.Digest()
+		require.NoError(t, err)
+		data, err := json.Marshal(env.Document)
+		require.NoError(t, err)
+		cd, err := c14n.JCS(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, dsig.NewSHA256Digest(cd).Value, d3.Value)
+		env.Head.Canonical = ""
+	})
+
+	t.Run("modified document", func(t *testing.T) {
+		env.Extract().(*note.Message).Content = "Modified message"
+		d4, err := env.Digest()
+		require.NoError(t, err)
+		assert.NotEqual(t, d1.Value, d4.Value)
+		assert.ErrorIs(t, env.Validate(), gobl.ErrDigest)
+		require.NoError(t, env.Calculate())
+		assert.NoError(t, env.Validate())
+	})
+
+	t.Run("modified after marshalling", func(t *testing.T) {
+		data, err := os.ReadFile("./examples/es/invoice-es-es.yaml")
+		require.NoError(t, err)
+		inv := new(bill.Invoice)
+		require.NoError(t, yaml.Unmarshal(data, inv))
+		env, err := gobl.Envelop(inv)
+		require.NoError(t, err)
+		_, err = json.Marshal(env)
+		require.NoError(t, err)
+		inv.Notes = append(inv.Notes, &org.Note{Text: "Out of band note"})
+		assert.ErrorIs(t, env.Validate(), gobl.ErrDigest)
+		data, err = json.Marshal(env)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "Out of band note")
+		assert.ErrorIs(t, env.Sign(testKey), gobl.ErrDigest)
+	})
+}
+
+func TestExtractAs(t *testing.T) {
+	msg := &note.Message{Content: "Test message"}
+	env, err := gobl.EnvelopOf(msg)
+	require.NoError(t, err)
+
+	out, err := gobl.ExtractAs[*note.Message](env)
+	require.NoError(t, err)
+	assert.Equal(t, msg, out)
+
+	_, err = gobl.ExtractAs[*bill.Invoice](env)
+	assert.ErrorIs(t, err, gobl.ErrDocumentType)
+	assert.ErrorContains(t, err, "expected *bill.Invoice, found *note.Message")
+
+	_, err = gobl.ExtractAs[*note.Message](gobl.NewEnvelope())
+	assert.ErrorIs(t, err, gobl.ErrNoDocument)
+
+	require.NoError(t, env.Encrypt(testKey.Public()))
+	_, err = gobl.ExtractAs[*note.Message](env)
+	assert.ErrorIs(t, err, gobl.ErrEncrypted)
+}
+
+func BenchmarkEnvelopeSign(b *testing.B) {
+	data, err := os.ReadFile("examples/es/invoice-es-es.yaml")
+	require.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc := new(schema.Object)
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			b.Fatal(err)
+		}
+		env, err := gobl.Envelop(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := env.Sign(testKey); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(env); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEnvelopeObserve(t *testing.T) {
+	var ops []observe.Operation
+	var last *observe.Event
+	t.Cleanup(observe.Register(observe.HookFunc(func(_ context.Context, ev *observe.Event) {
+		ops = append(ops, ev.Op)
+		last = ev
+	})))
+
+	env := testAttachmentEnvelope(t)
+	require.NoError(t, env.Validate())
+	require.NoError(t, env.Sign(testKey))
+
+	assert.Equal(t, []observe.Operation{
+		observe.OpCalculate,
+		observe.OpValidate,
+		observe.OpSign,
+	}, ops[len(ops)-3:])
+	assert.Equal(t, "ES", last.Regime.String())
+	assert.Equal(t, schema.Lookup(&bill.Invoice{}), last.Schema)
+	assert.True(t, last.Succeeded())
+}
+
+func TestEnvelopeCountersign(t *testing.T) {
+	platform := dsig.NewEd25519Key()
+	t.Run("adds to last signature", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(testKey))
+		require.NoError(t, env.Countersign(platform))
+		require.Len(t, env.Signatures, 1)
+		assert.Equal(t, []string{testKey.ID(), platform.ID()}, env.Signatures[0].KeyIDs())
+		assert.NoError(t, env.Verify(testKey.Public()))
+		assert.NoError(t, env.Verify(platform.Public()))
+
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+		env2 := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env2))
+		assert.NoError(t, env2.Validate())
+		assert.NoError(t, env2.Verify(platform.Public()))
+	})
+	t.Run("satisfies policy", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		env.Head.Policy = &head.SignaturePolicy{
+			Min: 2,
+			Signers: []*head.PolicySigner{
+				{KeyID: testKey.ID()},
+				{KeyID: platform.ID()},
+			},
+		}
+		require.NoError(t, env.Sign(testKey))
+		assert.ErrorContains(t, env.Validate(), "requires 2 signatures")
+		require.NoError(t, env.Countersign(platform))
+		assert.NoError(t, env.Validate())
+		assert.NoError(t, env.CheckPolicy(testKey.Public(), platform.Public()))
+	})
+	t.Run("forged policy signer", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		env.Head.Policy = &head.SignaturePolicy{
+			Min: 2,
+			Signers: []*head.PolicySigner{
+				{KeyID: testKey.ID()},
+				{KeyID: platform.ID()},
+			},
+		}
+		require.NoError(t, env.Sign(testKey))
+		require.NoError(t, env.Countersign(forgedKey(t, platform.ID())))
+		err := env.CheckPolicy(testKey.Public(), platform.Public())
+		assert.ErrorContains(t, err, "policy: signature 0: key mismatch: signature 1")
+		err = env.CheckPolicy(testKey.Public())
+		assert.ErrorContains(t, err, "requires 2 signatures from policy signers, found 1")
+		r := env.VerifyReport()
+		assert.Equal(t, gobl.VerifyStatusUnverified, r.Policy.Status)
+		r = env.VerifyReport(testKey.Public())
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Policy.Status)
+	})
+	t.Run("forged signer", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(testKey))
+		require.NoError(t, env.Countersign(forgedKey(t, platform.ID())))
+		assert.Equal(t, []string{testKey.ID(), platform.ID()}, env.Signatures[0].KeyIDs())
+		err := env.Verify(testKey.Public(), platform.Public())
+		assert.ErrorContains(t, err, "key mismatch: signature 1")
+		assert.ErrorContains(t, env.Verify(platform.Public()), "key mismatch: signature 1")
+		assert.NoError(t, env.Verify(testKey.Public()))
+	})
+	t.Run("unsigned", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		assert.ErrorContains(t, env.Countersign(platform), "no signature to countersign")
+	})
+	t.Run("same key", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(testKey))
+		orig := env.Signatures[0].String()
+		assert.ErrorContains(t, env.Countersign(testKey), "already signed with key")
+		assert.Equal(t, orig, env.Signatures[0].String())
+	})
+}
+
+func TestEnvelopeSignDetached(t *testing.T) {
+	env := testAttachmentEnvelope(t)
+	require.NoError(t, env.SignDetached(testKey))
+	require.Len(t, env.Signatures, 1)
+	assert.True(t, env.Signatures[0].Detached())
+	assert.NoError(t, env.Verify(testKey.Public()))
+	assert.ErrorContains(t, env.Verify(), "keys required to verify detached signature")
+	assert.ErrorContains(t, env.Verify(dsig.NewES256Key().Public()), "no key match found")
+	r := env.VerifyReport()
+	assert.True(t, r.Valid)
+	assert.Equal(t, gobl.VerifyStatusUnverified, r.Signatures[0].Status)
+	assert.Equal(t, "keys required to verify detached signature", r.Signatures[0].Error)
+
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	env2 := new(gobl.Envelope)
+	require.NoError(t, json.Unmarshal(data, env2))
+	assert.NoError(t, env2.Validate())
+	assert.NoError(t, env2.Verify(testKey.Public()))
+
+	env2.Head.Digest = dsig.NewSHA256Digest([]byte("tampered"))
+	assert.ErrorContains(t, env2.Verify(testKey.Public()), "no key match found")
+}
+
+func TestEnvelopeResign(t *testing.T) {
+	k1, k2, k3 := dsig.NewES256Key(), dsig.NewES256Key(), dsig.NewEd25519Key()
+	signed := func(t *testing.T) *gobl.Envelope {
+		t.Helper()
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(k1))
+		require.NoError(t, env.Sign(k2))
+		return env
+	}
+	kids := func(env *gobl.Envelope) []string {
+		var list []string
+		for _, s := range env.Signatures {
+			list = append(list, s.KeyIDs()...)
+		}
+		return list
+	}
+	t.Run("rotates revoked key", func(t *testing.T) {
+		env := signed(t)
+		require.NoError(t, env.Resign(k3, &gobl.ResignOptions{Revoked: []string{k1.ID()}}))
+		assert.Equal(t, []string{k2.ID(), k3.ID()}, kids(env))
+		assert.NoError(t, env.Verify(k2.Public(), k3.Public()))
+		assert.NoError(t, env.Validate())
+	})
+	t.Run("verifies kept with keys", func(t *testing.T) {
+		env := signed(t)
+		err := env.Resign(k3, &gobl.ResignOptions{
+			Revoked: []string{k1.ID()},
+			Keys:    []*dsig.PublicKey{k1.Public()},
+		})
+		assert.ErrorContains(t, err, "signature 1: no key match found")
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, kids(env))
+		require.NoError(t, env.Resign(k3, &gobl.ResignOptions{
+			Revoked: []string{k1.ID()},
+			Keys:    []*dsig.PublicKey{k2.Public()},
+		}))
+		assert.Equal(t, []string{k2.ID(), k3.ID()}, kids(env))
+	})
+	t.Run("removes countersigned", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(k1))
+		require.NoError(t, env.Countersign(k2))
+		require.NoError(t, env.Resign(k3, &gobl.ResignOptions{Revoked: []string{k2.ID()}}))
+		assert.Equal(t, []string{k3.ID()}, kids(env))
+	})
+	t.Run("modified document", func(t *testing.T) {
+		env := signed(t)
+		inv := env.Extract().(*bill.Invoice)
+		inv.Code = "NEW-001"
+		orig := env.Head.Digest
+		err := env.Resign(k3, &gobl.ResignOptions{Revoked: []string{k1.ID(), k2.ID()}})
+		assert.ErrorIs(t, err, gobl.ErrDigest)
+		assert.ErrorContains(t, err, "document modified since signed")
+		assert.Equal(t, orig, env.Head.Digest)
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, kids(env))
+	})
+	t.Run("updates digest", func(t *testing.T) {
+		env := signed(t)
+		inv := env.Extract().(*bill.Invoice)
+		inv.Code = "NEW-001"
+		orig := env.Head.Digest
+		require.NoError(t, env.Resign(k3, &gobl.ResignOptions{
+			Revoked: []string{k1.ID(), k2.ID()},
+			Update:  true,
+		}))
+		assert.NotEqual(t, orig.Value, env.Head.Digest.Value)
+		assert.Equal(t, []string{k3.ID()}, kids(env))
+		assert.NoError(t, env.Validate())
+	})
+	t.Run("update with kept signature", func(t *testing.T) {
+		env := signed(t)
+		inv := env.Extract().(*bill.Invoice)
+		inv.Code = "NEW-001"
+		orig := env.Head.Digest
+		err := env.Resign(k3, &gobl.ResignOptions{Revoked: []string{k1.ID()}, Update: true})
+		assert.ErrorContains(t, err, "all signatures must be revoked to update the digest")
+		assert.Equal(t, orig, env.Head.Digest)
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, kids(env))
+	})
+	t.Run("tampered signature", func(t *testing.T) {
+		env := signed(t)
+		env.Head.Digest = dsig.NewSHA256Digest([]byte("tampered"))
+		err := env.Resign(k3, &gobl.ResignOptions{Revoked: []string{k1.ID(), k2.ID()}, Update: true})
+		assert.ErrorContains(t, err, "signature 0: header mismatch")
+		assert.Equal(t, []string{k1.ID(), k2.ID()}, kids(env))
+	})
+	t.Run("missing header", func(t *testing.T) {
+		env := signed(t)
+		env.Head = nil
+		assert.ErrorContains(t, env.Resign(k3, nil), "header required")
+	})
+}
+
+// forgedKey provides a new private key that claims to have the provided ID.
+func forgedKey(t *testing.T, kid string) *dsig.PrivateKey {
+	t.Helper()
+	data, err := json.Marshal(dsig.NewES256Key())
+	require.NoError(t, err)
+	m := make(map[string]any)
+	require.NoError(t, json.Unmarshal(data, &m))
+	m["kid"] = kid
+	data, err = json.Marshal(m)
+	require.NoError(t, err)
+	k := new(dsig.PrivateKey)
+	require.NoError(t, json.Unmarshal(data, k))
+	return k
+}
@@ -50,12 +50,20 @@ var (
 	// ErrDigest identifies an issue related to the digest.
 	ErrDigest = NewError("digest")
 
+	// ErrEncrypted is provided when attempting to access or modify the
+	// document of an envelope that has been encrypted.
+	ErrEncrypted = NewError("encrypted")
+
 	// ErrInternal is a "catch-all" for errors that are not expected.
 	ErrInternal = NewError("internal")
 
 	// ErrUnknownSchema is provided when we attempt to determine the schema for an object
 	// or from an ID and cannot find a match.
 	ErrUnknownSchema = NewError("unknown-schema")
+
+	// ErrDocumentType is provided when a document does not have the type
+	// expected.
+	ErrDocumentType = NewError("document-type")
 )
 
 // NewError provides a new error with a code that is meant to provide
@@ -128,3 +128,34 @@ func processFile(t *testing.T, path string) error {
 
 	return nil
 }
+
+// TestCalculateExamplesIdempotent ensures that calculating the documents
+// produced from the examples again does not cause any changes.
+func TestCalculateExamplesIdempotent(t *testing.T) {
+	files, err := filepath.Glob("examples/*/out/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, path := range files {
+		// only check output that can be generated from a source example
+		src := filepath.Join(filepath.Dir(path), "..", strings.TrimSuffix(filepath.Base(path), ".json"))
+		if _, err := os.Stat(src + ".yaml"); err != nil {
+			if _, err := os.Stat(src + ".json"); err != nil {
+				continue
+			}
+		}
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			env := new(gobl.Envelope)
+			require.NoError(t, json.Unmarshal(data, env))
+			exp, err := json.Marshal(env.Document)
+			require.NoError(t, err)
+
+			require.NoError(t, env.Calculate())
+			out, err := json.Marshal(env.Document)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(exp), string(out))
+		})
+	}
+}
@@ -1,9 +1,23 @@
 // Package gobl contains all the base models for GOBL.
+//
+// Importing this package will register every tax regime and addon. Builds
+// that only need a few may set the "gobl_selective" build tag and import
+// the packages they need individually, for example:
+//
+//	import (
+//		"github.com/invopop/gobl"
+//		_ "github.com/invopop/gobl/addons/eu/en16931"
+//		_ "github.com/invopop/gobl/regimes/de"
+//	)
+//
+// Regime and addon definitions are only prepared the first time they're
+// used, so the cost of registering those that are not needed is small.
 package gobl
 
 import (
+	"io/fs"
+
 	// import all the dependencies to ensure all init() methods are called.
-	_ "github.com/invopop/gobl/addons"
 	_ "github.com/invopop/gobl/bill"
 	_ "github.com/invopop/gobl/catalogues"
 	_ "github.com/invopop/gobl/currency"
@@ -12,8 +26,8 @@ import (
 	_ "github.com/invopop/gobl/note"
 	_ "github.com/invopop/gobl/num"
 	_ "github.com/invopop/gobl/org"
-	_ "github.com/invopop/gobl/regimes"
 
+	"github.com/invopop/gobl/data"
 	"github.com/invopop/gobl/schema"
 )
 
@@ -26,5 +40,13 @@ import (
 func init() {
 	schema.Register(schema.GOBL,
 		Envelope{},
+		Batch{},
+		RedactedEnvelope{},
 	)
+	// Allow external schemas to reference GOBL's own embedded schemas.
+	schemas, err := fs.Sub(data.Content, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	schema.RegisterLoader(schema.FSLoader(schema.GOBL, schemas))
 }
@@ -0,0 +1,10 @@
+//go:build !gobl_selective
+
+package gobl
+
+import (
+	// Import all the tax regimes and addons so they're ready to use, unless
+	// the "gobl_selective" build tag is set.
+	_ "github.com/invopop/gobl/addons"
+	_ "github.com/invopop/gobl/regimes"
+)
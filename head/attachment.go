@@ -0,0 +1,107 @@
+package head
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/validation"
+)
+
+// AttachmentRef describes a file carried alongside the document inside the
+// envelope, such as the original PDF or an XML surrogate. The digest of the
+// file's contents is included in the header so that it will be covered by
+// each of the envelope's signatures.
+type AttachmentRef struct {
+	// Key used to identify the attachment inside the envelope.
+	Key cbc.Key `json:"key" jsonschema:"title=Key"`
+	// Name of the file including the extension.
+	Name string `json:"name" jsonschema:"title=Name"`
+	// MIME type of the file's contents.
+	MIME string `json:"mime,omitempty" jsonschema:"title=MIME Type,format=mime"`
+	// Size of the file in bytes.
+	Size int64 `json:"size" jsonschema:"title=Size"`
+	// Digest of the file's contents.
+	Digest *dsig.Digest `json:"dig" jsonschema:"title=Digest"`
+}
+
+// Validate checks the attachment reference's details.
+func (a *AttachmentRef) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.Key, validation.Required),
+		validation.Field(&a.Name, validation.Required),
+		validation.Field(&a.MIME),
+		validation.Field(&a.Size, validation.Min(int64(0))),
+		validation.Field(&a.Digest, validation.Required),
+	)
+}
+
+// AttachmentRefByKey finds the attachment reference with the given key from
+// the provided list.
+func AttachmentRefByKey(list []*AttachmentRef, k cbc.Key) *AttachmentRef {
+	for _, a := range list {
+		if a != nil && a.Key == k {
+			return a
+		}
+	}
+	return nil
+}
+
+// AppendAttachmentRef adds the attachment reference to the list, replacing
+// any existing reference with the same key, and returns the updated list.
+func AppendAttachmentRef(list []*AttachmentRef, a *AttachmentRef) []*AttachmentRef {
+	if a == nil {
+		return list
+	}
+	for i, v := range list {
+		if v != nil && v.Key == a.Key {
+			list[i] = a
+			return list
+		}
+	}
+	return append(list, a)
+}
+
+// RemoveAttachmentRef removes the attachment reference with the given key
+// from the list, returning the updated list.
+func RemoveAttachmentRef(list []*AttachmentRef, k cbc.Key) []*AttachmentRef {
+	nl := make([]*AttachmentRef, 0, len(list))
+	for _, v := range list {
+		if v != nil && v.Key == k {
+			continue
+		}
+		nl = append(nl, v)
+	}
+	if len(nl) == 0 {
+		return nil
+	}
+	return nl
+}
+
+// DetectDuplicateAttachmentRefs checks if the list of attachment references
+// contains duplicate keys.
+var DetectDuplicateAttachmentRefs = validation.By(detectDuplicateAttachmentRefs)
+
+func detectDuplicateAttachmentRefs(list any) error {
+	values, ok := list.([]*AttachmentRef)
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	set := make(map[cbc.Key]bool)
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if set[v.Key] {
+			return fmt.Errorf("duplicate key '%v'", v.Key)
+		}
+		set[v.Key] = true
+	}
+	return nil
+}
+
+// Attachment provides the attachment reference with the matching key in the
+// header, or nil.
+func (h *Header) Attachment(key cbc.Key) *AttachmentRef {
+	return AttachmentRefByKey(h.Attachments, key)
+}
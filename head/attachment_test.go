@@ -0,0 +1,49 @@
+package head_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentRefValidate(t *testing.T) {
+	a := &head.AttachmentRef{
+		Key:    "pdf",
+		Name:   "invoice.pdf",
+		MIME:   "application/pdf",
+		Size:   3,
+		Digest: dsig.NewSHA256Digest([]byte("pdf")),
+	}
+	assert.NoError(t, a.Validate())
+	a.Digest = nil
+	assert.ErrorContains(t, a.Validate(), "dig: cannot be blank")
+	a.Name = ""
+	assert.ErrorContains(t, a.Validate(), "name: cannot be blank")
+}
+
+func TestHeaderAttachments(t *testing.T) {
+	h := head.NewHeader()
+	h.Digest = dsig.NewSHA256Digest([]byte("doc"))
+	a1 := &head.AttachmentRef{Key: "pdf", Name: "a.pdf", Size: 1, Digest: dsig.NewSHA256Digest([]byte("a"))}
+	a2 := &head.AttachmentRef{Key: "pdf", Name: "b.pdf", Size: 1, Digest: dsig.NewSHA256Digest([]byte("b"))}
+	h.Attachments = head.AppendAttachmentRef(h.Attachments, a1)
+	h.Attachments = head.AppendAttachmentRef(h.Attachments, a2)
+	assert.Len(t, h.Attachments, 1)
+	assert.Equal(t, "b.pdf", h.Attachment("pdf").Name)
+	assert.NoError(t, h.Validate())
+
+	h2 := head.NewHeader()
+	h2.UUID = h.UUID
+	h2.Attachments = []*head.AttachmentRef{a1}
+	assert.False(t, h.Contains(h2))
+	h2.Attachments = []*head.AttachmentRef{a2}
+	assert.True(t, h.Contains(h2))
+
+	h.Attachments = append(h.Attachments, a1)
+	assert.ErrorContains(t, h.Validate(), "attachments: duplicate key 'pdf'")
+
+	h.Attachments = head.RemoveAttachmentRef(h.Attachments, "pdf")
+	assert.Nil(t, h.Attachments)
+}
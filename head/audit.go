@@ -0,0 +1,87 @@
+package head
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/validation"
+)
+
+// Audit actions that describe the most common envelope state changes. Other
+// keys may be used when needed.
+const (
+	AuditActionCreated    cbc.Key = "created"
+	AuditActionCalculated cbc.Key = "calculated"
+	AuditActionPatched    cbc.Key = "patched"
+	AuditActionSigned     cbc.Key = "signed"
+	AuditActionCorrected  cbc.Key = "corrected"
+)
+
+// AuditEntry records who performed an action on the envelope and when,
+// alongside the digest of the document that resulted.
+type AuditEntry struct {
+	// When the action took place.
+	At cal.DateTime `json:"at" jsonschema:"title=At"`
+	// Identity of the user or system that performed the action.
+	Actor string `json:"actor,omitempty" jsonschema:"title=Actor"`
+	// Key describing the action performed.
+	Action cbc.Key `json:"action" jsonschema:"title=Action"`
+	// Digest of the document after the action.
+	Digest *dsig.Digest `json:"dig,omitempty" jsonschema:"title=Digest"`
+	// Additional details about the action.
+	Notes string `json:"notes,omitempty" jsonschema:"title=Notes"`
+}
+
+// Validate checks the audit entry's contents.
+func (a *AuditEntry) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.At, cal.DateTimeNotZero()),
+		validation.Field(&a.Action, validation.Required),
+		validation.Field(&a.Digest),
+	)
+}
+
+// CheckAuditOrder ensures that the audit trail entries are in chronological
+// order.
+var CheckAuditOrder = validation.By(checkAuditOrder)
+
+func checkAuditOrder(list interface{}) error {
+	values, ok := list.([]*AuditEntry)
+	if !ok {
+		return errors.New("must be an audit entry array")
+	}
+	for i := 1; i < len(values); i++ {
+		p, a := values[i-1], values[i]
+		if p == nil || a == nil {
+			continue // validated elsewhere
+		}
+		if a.At.Before(p.At.DateTime) {
+			return fmt.Errorf("entry %d: not in chronological order", i)
+		}
+	}
+	return nil
+}
+
+// AddAudit appends a new entry to the header's audit trail using the
+// current time and the header's digest.
+func (h *Header) AddAudit(actor string, action cbc.Key) *AuditEntry {
+	a := &AuditEntry{
+		At:     cal.ThisSecond(),
+		Actor:  actor,
+		Action: action,
+		Digest: h.Digest,
+	}
+	h.Audit = append(h.Audit, a)
+	return a
+}
+
+// LastAudit provides the most recent audit trail entry, or nil.
+func (h *Header) LastAudit() *AuditEntry {
+	if len(h.Audit) == 0 {
+		return nil
+	}
+	return h.Audit[len(h.Audit)-1]
+}
@@ -0,0 +1,51 @@
+package head_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEntryValidation(t *testing.T) {
+	a := &head.AuditEntry{
+		At:     cal.MakeDateTime(2025, 1, 1, 12, 0, 0),
+		Actor:  "user@example.com",
+		Action: head.AuditActionSigned,
+	}
+	assert.NoError(t, a.Validate())
+
+	a = &head.AuditEntry{}
+	err := a.Validate()
+	assert.ErrorContains(t, err, "action: cannot be blank")
+	assert.ErrorContains(t, err, "at: required")
+}
+
+func TestHeaderAudit(t *testing.T) {
+	h := head.NewHeader()
+	h.Digest = dsig.NewSHA256Digest([]byte("test"))
+	assert.Nil(t, h.LastAudit())
+
+	a := h.AddAudit("system", head.AuditActionCalculated)
+	require.NotNil(t, a)
+	assert.Equal(t, h.Digest, a.Digest)
+	assert.False(t, a.At.IsZero())
+	h.AddAudit("user@example.com", head.AuditActionSigned)
+	require.Len(t, h.Audit, 2)
+	assert.Equal(t, head.AuditActionSigned, h.LastAudit().Action)
+	assert.NoError(t, h.Validate())
+
+	t.Run("chronological order", func(t *testing.T) {
+		h := head.NewHeader()
+		h.Digest = dsig.NewSHA256Digest([]byte("test"))
+		h.Audit = []*head.AuditEntry{
+			{At: cal.MakeDateTime(2025, 1, 2, 12, 0, 0), Action: head.AuditActionCreated},
+			{At: cal.MakeDateTime(2025, 1, 2, 12, 0, 0), Action: head.AuditActionCalculated},
+			{At: cal.MakeDateTime(2025, 1, 1, 12, 0, 0), Action: head.AuditActionSigned},
+		}
+		assert.ErrorContains(t, h.Validate(), "audit: entry 2: not in chronological order")
+	})
+}
@@ -9,5 +9,9 @@ func init() {
 		Header{},
 		Stamp{},
 		Link{},
+		Revision{},
+		AuditEntry{},
+		SignaturePolicy{},
+		AttachmentRef{},
 	)
 }
@@ -10,6 +10,16 @@ import (
 	"github.com/invopop/validation"
 )
 
+// Canonicalization methods used to serialize documents before calculating
+// their digests.
+const (
+	// CanonicalGOBL is GOBL's original canonical JSON format, used by default
+	// when no method is defined for compatibility with existing digests.
+	CanonicalGOBL cbc.Key = "gobl"
+	// CanonicalJCS is the JSON Canonicalization Scheme defined in RFC 8785.
+	CanonicalJCS cbc.Key = "jcs"
+)
+
 // Header defines the metadata of the body. The header is used as the payload
 // for the JSON Web Signatures, so we want this to be as compact as possible.
 type Header struct {
@@ -19,6 +29,14 @@ type Header struct {
 	// Digest of the canonical JSON body.
 	Digest *dsig.Digest `json:"dig" jsonschema:"title=Digest"`
 
+	// Canonicalization method used to serialize the document before calculating
+	// the digest. GOBL's original format is assumed when empty.
+	Canonical cbc.Key `json:"c14n,omitempty" jsonschema:"title=Canonicalization"`
+
+	// Digest of the salted hashes of each of the document's fields, used to
+	// verify documents with redacted fields.
+	RedactableDigest *dsig.Digest `json:"rdig,omitempty" jsonschema:"title=Redactable Digest"`
+
 	// Seals of approval from other organisations that can only be added to
 	// non-draft envelopes.
 	Stamps []*Stamp `json:"stamps,omitempty" jsonschema:"title=Stamps"`
@@ -27,6 +45,21 @@ type Header struct {
 	// and unlike stamps can be added even in the draft state.
 	Links []*Link `json:"links,omitempty" jsonschema:"title=Links"`
 
+	// History of patches applied to the document while in the draft state,
+	// each including the digest before and after the change.
+	Revisions []*Revision `json:"revs,omitempty" jsonschema:"title=Revisions"`
+
+	// Files included in the envelope alongside the document, each with the
+	// digest of its contents.
+	Attachments []*AttachmentRef `json:"attachments,omitempty" jsonschema:"title=Attachments"`
+
+	// Append-only trail of the actions performed on the envelope, in
+	// chronological order.
+	Audit []*AuditEntry `json:"audit,omitempty" jsonschema:"title=Audit Trail"`
+
+	// Signatures required for the envelope to be considered complete.
+	Policy *SignaturePolicy `json:"policy,omitempty" jsonschema:"title=Signature Policy"`
+
 	// Set of labels that describe but have no influence on the data.
 	Tags []string `json:"tags,omitempty" jsonschema:"title=Tags"`
 
@@ -55,6 +88,8 @@ func (h *Header) ValidateWithContext(ctx context.Context) error {
 	return validation.ValidateStructWithContext(ctx, h,
 		validation.Field(&h.UUID, validation.Required, uuid.HasTimestamp),
 		validation.Field(&h.Digest, validation.Required),
+		validation.Field(&h.Canonical, validation.In(CanonicalGOBL, CanonicalJCS)),
+		validation.Field(&h.RedactableDigest),
 		validation.Field(&h.Stamps,
 			validation.When(
 				!internal.IsSigned(ctx),
@@ -65,6 +100,16 @@ func (h *Header) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&h.Links,
 			DetectDuplicateLinks,
 		),
+		validation.Field(&h.Revisions,
+			CheckRevisionChain,
+		),
+		validation.Field(&h.Attachments,
+			DetectDuplicateAttachmentRefs,
+		),
+		validation.Field(&h.Audit,
+			CheckAuditOrder,
+		),
+		validation.Field(&h.Policy),
 	)
 }
 
@@ -106,6 +151,15 @@ func (h *Header) Contains(h2 *Header) bool {
 	if h2.Digest != nil && h.Digest.String() != h2.Digest.String() {
 		return false
 	}
+	if h.Canonical != h2.Canonical {
+		return false
+	}
+	if (h.RedactableDigest == nil) != (h2.RedactableDigest == nil) {
+		return false
+	}
+	if h2.RedactableDigest != nil && h.RedactableDigest.String() != h2.RedactableDigest.String() {
+		return false
+	}
 	for _, s2 := range h2.Stamps {
 		match := false
 		for _, s := range h.Stamps {
@@ -130,6 +184,12 @@ func (h *Header) Contains(h2 *Header) bool {
 			return false
 		}
 	}
+	for _, a2 := range h2.Attachments {
+		a := h.Attachment(a2.Key)
+		if a == nil || a.Digest == nil || a2.Digest == nil || a.Digest.String() != a2.Digest.String() {
+			return false
+		}
+	}
 	for _, t2 := range h2.Tags {
 		match := false
 		for _, t := range h.Tags {
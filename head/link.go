@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/uuid"
 	"github.com/invopop/validation"
 	"github.com/invopop/validation/is"
 )
@@ -15,27 +17,51 @@ import (
 //
 // Links have a specific advantage over stamps in that they are also allowed while
 // the envelope is still a draft.
+//
+// Links may also describe a typed relation with another envelope, in which case
+// the relation, UUID, and optionally the digest of the target envelope are
+// included, and the URL becomes optional.
 type Link struct {
 	// Key is a unique identifier for the link.
 	Key cbc.Key `json:"key"`
+	// Type of relation with the target envelope.
+	Rel cbc.Key `json:"rel,omitempty" jsonschema:"title=Relation"`
+	// UUID of the target envelope's header.
+	UUID uuid.UUID `json:"uuid,omitempty" jsonschema:"title=UUID"`
+	// Digest of the target envelope's document.
+	Digest *dsig.Digest `json:"dig,omitempty" jsonschema:"title=Digest"`
 	// Title of the resource to use when presenting to users.
 	Title string `json:"title,omitempty" jsonschema:"title=Title"`
 	// Description of the resource to use when presenting to users.
 	Description string `json:"description,omitempty" jsonschema:"title=Description"`
 	// Expected MIME type of the link's content.
 	MIME string `json:"mime,omitempty" jsonschema:"title=MIME Type,format=mime"`
-	// URL of the resource.
-	URL string `json:"url" jsonschema:"title=URL,format=uri"`
+	// URL of the resource, required unless the link refers to an envelope.
+	URL string `json:"url,omitempty" jsonschema:"title=URL,format=uri"`
 }
 
 // Validate checks that the link contains the basic information we need to function.
 func (l *Link) Validate() error {
 	return validation.ValidateStruct(l,
 		validation.Field(&l.Key, validation.Required),
+		validation.Field(&l.Rel, validation.In(linkRelKeys()...)),
+		validation.Field(&l.UUID,
+			validation.When(
+				l.Rel != cbc.KeyEmpty,
+				validation.Required,
+			),
+		),
+		validation.Field(&l.Digest),
 		validation.Field(&l.Title),       // not required
 		validation.Field(&l.Description), // not required
 		validation.Field(&l.MIME),
-		validation.Field(&l.URL, validation.Required, is.URL),
+		validation.Field(&l.URL,
+			validation.When(
+				l.UUID.IsZero(),
+				validation.Required,
+			),
+			is.URL,
+		),
 	)
 }
 
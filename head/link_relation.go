@@ -0,0 +1,106 @@
+package head
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/cbc"
+)
+
+// Relations between envelopes that may be defined in links. Each relation
+// has an inverse that is expected in the target envelope's links when it
+// refers back to the source.
+const (
+	LinkRelCorrects    cbc.Key = "corrects"
+	LinkRelCorrectedBy cbc.Key = "corrected-by"
+	LinkRelPays        cbc.Key = "pays"
+	LinkRelPaidBy      cbc.Key = "paid-by"
+	LinkRelFulfils     cbc.Key = "fulfils"
+	LinkRelFulfilledBy cbc.Key = "fulfilled-by"
+	LinkRelReplaces    cbc.Key = "replaces"
+	LinkRelReplacedBy  cbc.Key = "replaced-by"
+)
+
+var linkRelInverses = map[cbc.Key]cbc.Key{
+	LinkRelCorrects:    LinkRelCorrectedBy,
+	LinkRelCorrectedBy: LinkRelCorrects,
+	LinkRelPays:        LinkRelPaidBy,
+	LinkRelPaidBy:      LinkRelPays,
+	LinkRelFulfils:     LinkRelFulfilledBy,
+	LinkRelFulfilledBy: LinkRelFulfils,
+	LinkRelReplaces:    LinkRelReplacedBy,
+	LinkRelReplacedBy:  LinkRelReplaces,
+}
+
+func linkRelKeys() []any {
+	return []any{
+		LinkRelCorrects, LinkRelCorrectedBy,
+		LinkRelPays, LinkRelPaidBy,
+		LinkRelFulfils, LinkRelFulfilledBy,
+		LinkRelReplaces, LinkRelReplacedBy,
+	}
+}
+
+// LinkRelInverse provides the relation expected in the target envelope
+// when referring back to the source, or an empty key if unknown.
+func LinkRelInverse(rel cbc.Key) cbc.Key {
+	return linkRelInverses[rel]
+}
+
+// NewRelationLink prepares a link describing the relation with the envelope
+// of the target header, including its UUID and digest.
+func NewRelationLink(key, rel cbc.Key, target *Header) *Link {
+	return &Link{
+		Key:    key,
+		Rel:    rel,
+		UUID:   target.UUID,
+		Digest: target.Digest,
+	}
+}
+
+// Relations provides the links in the header that refer to the target
+// envelope's header.
+func (h *Header) Relations(target *Header) []*Link {
+	var list []*Link
+	for _, l := range h.Links {
+		if l != nil && l.Rel != cbc.KeyEmpty && l.UUID == target.UUID {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+// CheckRelations ensures that the relation links between the two headers
+// are consistent. Digests included in links must match the target header,
+// and when both headers refer to each other, each relation must be matched
+// by its inverse in the other header.
+func CheckRelations(a, b *Header) error {
+	if err := checkRelations(a, b); err != nil {
+		return err
+	}
+	return checkRelations(b, a)
+}
+
+func checkRelations(a, b *Header) error {
+	links := a.Relations(b)
+	back := b.Relations(a)
+	for _, l := range links {
+		if l.Digest != nil && (b.Digest == nil || l.Digest.String() != b.Digest.String()) {
+			return fmt.Errorf("link '%s': digest mismatch", l.Key)
+		}
+		if len(back) == 0 {
+			continue // one sided relation
+		}
+		inv := LinkRelInverse(l.Rel)
+		match := false
+		for _, bl := range back {
+			if bl.Rel == inv {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return fmt.Errorf("link '%s': missing inverse '%s' relation", l.Key, inv)
+		}
+	}
+	return nil
+}
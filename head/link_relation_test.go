@@ -0,0 +1,68 @@
+package head_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRelationHeader(doc string) *head.Header {
+	h := head.NewHeader()
+	h.Digest = dsig.NewSHA256Digest([]byte(doc))
+	return h
+}
+
+func TestRelationLinkValidation(t *testing.T) {
+	target := testRelationHeader("target")
+	l := head.NewRelationLink("correction", head.LinkRelCorrects, target)
+	assert.NoError(t, l.Validate())
+	assert.Equal(t, target.UUID, l.UUID)
+
+	l.Rel = "unknown"
+	assert.ErrorContains(t, l.Validate(), "rel: must be a valid value")
+
+	l.Rel = head.LinkRelCorrects
+	l.UUID = uuid.Empty
+	assert.ErrorContains(t, l.Validate(), "uuid: cannot be blank")
+}
+
+func TestLinkRelInverse(t *testing.T) {
+	assert.Equal(t, head.LinkRelCorrectedBy, head.LinkRelInverse(head.LinkRelCorrects))
+	assert.Equal(t, head.LinkRelPays, head.LinkRelInverse(head.LinkRelPaidBy))
+	assert.Equal(t, cbc.KeyEmpty, head.LinkRelInverse("unknown"))
+}
+
+func TestCheckRelations(t *testing.T) {
+	inv := testRelationHeader("invoice")
+	cn := testRelationHeader("credit-note")
+
+	t.Run("one sided", func(t *testing.T) {
+		a, b := *cn, *inv
+		a.Links = []*head.Link{head.NewRelationLink("orig", head.LinkRelCorrects, &b)}
+		assert.NoError(t, head.CheckRelations(&a, &b))
+		assert.Len(t, a.Relations(&b), 1)
+		assert.Empty(t, b.Relations(&a))
+	})
+	t.Run("reciprocal", func(t *testing.T) {
+		a, b := *cn, *inv
+		a.Links = []*head.Link{head.NewRelationLink("orig", head.LinkRelCorrects, &b)}
+		b.Links = []*head.Link{head.NewRelationLink("cn", head.LinkRelCorrectedBy, &a)}
+		assert.NoError(t, head.CheckRelations(&a, &b))
+	})
+	t.Run("inconsistent", func(t *testing.T) {
+		a, b := *cn, *inv
+		a.Links = []*head.Link{head.NewRelationLink("orig", head.LinkRelCorrects, &b)}
+		b.Links = []*head.Link{head.NewRelationLink("cn", head.LinkRelReplacedBy, &a)}
+		assert.ErrorContains(t, head.CheckRelations(&a, &b), "link 'orig': missing inverse 'corrected-by' relation")
+	})
+	t.Run("digest mismatch", func(t *testing.T) {
+		a, b := *cn, *inv
+		a.Links = []*head.Link{head.NewRelationLink("orig", head.LinkRelCorrects, &b)}
+		b.Digest = dsig.NewSHA256Digest([]byte("modified"))
+		assert.ErrorContains(t, head.CheckRelations(&a, &b), "link 'orig': digest mismatch")
+	})
+}
@@ -0,0 +1,126 @@
+package head
+
+import (
+	"fmt"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/validation"
+)
+
+// SignaturePolicy defines the signatures required before an envelope can be
+// considered complete, for example when two approvals from a list of
+// authorized signers are needed to issue an invoice. The policy is included
+// in the header so that each signature will also cover it.
+type SignaturePolicy struct {
+	// Minimum number of signatures required from different signers in the list.
+	Min int `json:"min" jsonschema:"title=Minimum,minimum=1"`
+	// Signers whose signatures count towards the policy.
+	Signers []*PolicySigner `json:"signers" jsonschema:"title=Signers"`
+	// Roles that must each be covered by at least one signature.
+	Roles []cbc.Key `json:"roles,omitempty" jsonschema:"title=Roles"`
+}
+
+// PolicySigner identifies a key that may be used to sign according to
+// the policy.
+type PolicySigner struct {
+	// ID of the key used to sign, as included in the signature.
+	KeyID string `json:"kid" jsonschema:"title=Key ID"`
+	// Role of the signer used to check required roles.
+	Role cbc.Key `json:"role,omitempty" jsonschema:"title=Role"`
+	// Name of the signer for reference.
+	Name string `json:"name,omitempty" jsonschema:"title=Name"`
+}
+
+// Validate checks the policy is coherent.
+func (p *SignaturePolicy) Validate() error {
+	return validation.ValidateStruct(p,
+		validation.Field(&p.Min,
+			validation.Required,
+			validation.Min(1),
+			validation.Max(len(p.Signers)).Error("must not be greater than the number of signers"),
+		),
+		validation.Field(&p.Signers,
+			validation.Required,
+			validation.By(p.detectDuplicateSigners),
+		),
+		validation.Field(&p.Roles,
+			validation.Each(validation.By(p.roleDefined)),
+		),
+	)
+}
+
+// Validate checks the signer's details.
+func (s *PolicySigner) Validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.KeyID, validation.Required),
+		validation.Field(&s.Role),
+	)
+}
+
+func (p *SignaturePolicy) detectDuplicateSigners(_ interface{}) error {
+	set := make(map[string]bool)
+	for _, s := range p.Signers {
+		if s == nil {
+			continue
+		}
+		if set[s.KeyID] {
+			return fmt.Errorf("duplicate signer '%s'", s.KeyID)
+		}
+		set[s.KeyID] = true
+	}
+	return nil
+}
+
+func (p *SignaturePolicy) roleDefined(value interface{}) error {
+	role, _ := value.(cbc.Key)
+	if p.signerWithRole(role) == nil {
+		return fmt.Errorf("no signer with role '%s'", role)
+	}
+	return nil
+}
+
+func (p *SignaturePolicy) signerWithRole(role cbc.Key) *PolicySigner {
+	for _, s := range p.Signers {
+		if s != nil && s.Role == role {
+			return s
+		}
+	}
+	return nil
+}
+
+// Signer provides the policy signer with the matching key ID, or nil.
+func (p *SignaturePolicy) Signer(kid string) *PolicySigner {
+	for _, s := range p.Signers {
+		if s != nil && s.KeyID == kid {
+			return s
+		}
+	}
+	return nil
+}
+
+// Check determines if the list of key IDs used to sign satisfies the
+// policy. Key IDs not included in the list of signers are ignored, as
+// are repeated signatures with the same key.
+func (p *SignaturePolicy) Check(kids []string) error {
+	seen := make(map[string]bool)
+	roles := make(map[cbc.Key]bool)
+	for _, kid := range kids {
+		s := p.Signer(kid)
+		if s == nil || seen[kid] {
+			continue
+		}
+		seen[kid] = true
+		if s.Role != cbc.KeyEmpty {
+			roles[s.Role] = true
+		}
+	}
+	if len(seen) < p.Min {
+		return fmt.Errorf("requires %d signatures from policy signers, found %d", p.Min, len(seen))
+	}
+	for _, r := range p.Roles {
+		if !roles[r] {
+			return fmt.Errorf("missing signature for role '%s'", r)
+		}
+	}
+	return nil
+}
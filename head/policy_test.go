@@ -0,0 +1,55 @@
+package head_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPolicy() *head.SignaturePolicy {
+	return &head.SignaturePolicy{
+		Min: 2,
+		Signers: []*head.PolicySigner{
+			{KeyID: "k1", Role: "finance"},
+			{KeyID: "k2", Role: "manager"},
+			{KeyID: "k3", Role: "manager"},
+		},
+		Roles: []cbc.Key{"finance"},
+	}
+}
+
+func TestSignaturePolicyValidate(t *testing.T) {
+	p := testPolicy()
+	assert.NoError(t, p.Validate())
+
+	p.Min = 4
+	assert.ErrorContains(t, p.Validate(), "min: must not be greater than the number of signers")
+
+	p = testPolicy()
+	p.Signers[2].KeyID = "k2"
+	assert.ErrorContains(t, p.Validate(), "signers: duplicate signer 'k2'")
+
+	p = testPolicy()
+	p.Roles = append(p.Roles, "director")
+	assert.ErrorContains(t, p.Validate(), "roles: (1: no signer with role 'director'.)")
+
+	p = testPolicy()
+	p.Signers[0].KeyID = ""
+	assert.ErrorContains(t, p.Validate(), "signers: (0: (kid: cannot be blank.).)")
+
+	p = &head.SignaturePolicy{}
+	assert.ErrorContains(t, p.Validate(), "min: cannot be blank")
+}
+
+func TestSignaturePolicyCheck(t *testing.T) {
+	p := testPolicy()
+	assert.NoError(t, p.Check([]string{"k1", "k2"}))
+	assert.NoError(t, p.Check([]string{"k3", "other", "k1"}))
+	assert.ErrorContains(t, p.Check([]string{"k1"}), "requires 2 signatures from policy signers, found 1")
+	assert.ErrorContains(t, p.Check([]string{"k1", "k1"}), "found 1")
+	assert.ErrorContains(t, p.Check([]string{"k2", "k3"}), "missing signature for role 'finance'")
+	assert.Equal(t, "manager", p.Signer("k2").Role.String())
+	assert.Nil(t, p.Signer("other"))
+}
@@ -0,0 +1,71 @@
+package head
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/validation"
+)
+
+// Revision records a change made to a draft envelope's document using a
+// JSON Patch (RFC 6902), along with the digests of the document before
+// and after the patch was applied.
+type Revision struct {
+	// Digest of the document before the patch was applied.
+	Previous *dsig.Digest `json:"prev" jsonschema:"title=Previous Digest"`
+	// Digest of the document after the patch was applied.
+	Digest *dsig.Digest `json:"dig" jsonschema:"title=Digest"`
+	// JSON Patch operations applied to the previous document.
+	Patch json.RawMessage `json:"patch" jsonschema:"title=Patch,type=array"`
+	// When the patch was applied.
+	At *cal.DateTime `json:"at,omitempty" jsonschema:"title=At"`
+}
+
+// Validate checks that the revision contains the basic information we need.
+func (r *Revision) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Previous, validation.Required),
+		validation.Field(&r.Digest, validation.Required),
+		validation.Field(&r.Patch, validation.Required),
+		validation.Field(&r.At),
+	)
+}
+
+// CheckRevisionChain ensures that the previous digest of each revision
+// matches the resulting digest of the revision before it, so that the
+// history cannot be silently modified.
+var CheckRevisionChain = validation.By(checkRevisionChain)
+
+func checkRevisionChain(list interface{}) error {
+	values, ok := list.([]*Revision)
+	if !ok {
+		return errors.New("must be a revision array")
+	}
+	for i := 1; i < len(values); i++ {
+		p, r := values[i-1], values[i]
+		if p == nil || r == nil || p.Digest == nil || r.Previous == nil {
+			continue // validated elsewhere
+		}
+		if err := r.Previous.Equals(p.Digest); err != nil {
+			return fmt.Errorf("revision %d: previous digest mismatch", i)
+		}
+	}
+	return nil
+}
+
+// AddRevision appends the revision to the header's history.
+func (h *Header) AddRevision(r *Revision) {
+	h.Revisions = append(h.Revisions, r)
+}
+
+// LastRevision provides the most recent revision in the header's history,
+// or nil.
+func (h *Header) LastRevision() *Revision {
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	return h.Revisions[len(h.Revisions)-1]
+}
@@ -0,0 +1,42 @@
+package head_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionValidation(t *testing.T) {
+	d1 := dsig.NewSHA256Digest([]byte("one"))
+	d2 := dsig.NewSHA256Digest([]byte("two"))
+	d3 := dsig.NewSHA256Digest([]byte("three"))
+	patch := json.RawMessage(`[{"op":"add","path":"/foo","value":"bar"}]`)
+
+	r := &head.Revision{Previous: d1, Digest: d2, Patch: patch}
+	assert.NoError(t, r.Validate())
+
+	r = &head.Revision{Digest: d2}
+	err := r.Validate()
+	assert.ErrorContains(t, err, "patch: cannot be blank")
+	assert.ErrorContains(t, err, "prev: cannot be blank")
+
+	t.Run("chain", func(t *testing.T) {
+		h := head.NewHeader()
+		h.Digest = d3
+		h.AddRevision(&head.Revision{Previous: d1, Digest: d2, Patch: patch})
+		h.AddRevision(&head.Revision{Previous: d2, Digest: d3, Patch: patch})
+		assert.NoError(t, h.Validate())
+		assert.Equal(t, d3, h.LastRevision().Digest)
+
+		h.Revisions[1].Previous = d1
+		assert.ErrorContains(t, h.Validate(), "revs: revision 1: previous digest mismatch")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		h := head.NewHeader()
+		assert.Nil(t, h.LastRevision())
+	})
+}
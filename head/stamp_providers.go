@@ -0,0 +1,87 @@
+package head
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/pkg/pattern"
+)
+
+// StampProviderDef describes a well-known stamp provider, typically a tax
+// agency or clearance platform, alongside the expected format of the values
+// it issues. Regimes and addons register the providers they use so that
+// stamp values can be validated.
+type StampProviderDef struct {
+	// Key used in the stamp's provider field.
+	Key cbc.Key `json:"key" jsonschema:"title=Key"`
+	// Name of the stamp provider.
+	Name i18n.String `json:"name" jsonschema:"title=Name"`
+	// Description of the value issued by the provider.
+	Desc i18n.String `json:"desc,omitempty" jsonschema:"title=Description"`
+	// Regular expression the stamp values must match.
+	Pattern string `json:"pattern,omitempty" jsonschema:"title=Pattern"`
+
+	re *regexp.Regexp
+}
+
+var stampProviders = struct {
+	sync.RWMutex
+	list []*StampProviderDef
+}{}
+
+// RegisterStampProviderDef adds the stamp provider definitions to the
+// global catalogue, replacing any previous definition with the same key.
+// Definitions with invalid patterns will cause a panic.
+func RegisterStampProviderDef(defs ...*StampProviderDef) {
+	stampProviders.Lock()
+	defer stampProviders.Unlock()
+	for _, d := range defs {
+		if d.Pattern != "" {
+			d.re = pattern.MustCompile(d.Pattern)
+		}
+		replaced := false
+		for i, v := range stampProviders.list {
+			if v.Key == d.Key {
+				stampProviders.list[i] = d
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			stampProviders.list = append(stampProviders.list, d)
+		}
+	}
+}
+
+// StampProviderDefFor provides the stamp provider definition for the key,
+// or nil if not registered.
+func StampProviderDefFor(key cbc.Key) *StampProviderDef {
+	stampProviders.RLock()
+	defer stampProviders.RUnlock()
+	for _, d := range stampProviders.list {
+		if d.Key == key {
+			return d
+		}
+	}
+	return nil
+}
+
+// StampProviderDefs provides the list of registered stamp provider
+// definitions.
+func StampProviderDefs() []*StampProviderDef {
+	stampProviders.RLock()
+	defer stampProviders.RUnlock()
+	return append([]*StampProviderDef{}, stampProviders.list...)
+}
+
+// ValidateValue checks that the stamp value matches the provider's
+// expected format.
+func (d *StampProviderDef) ValidateValue(value string) error {
+	if d.re != nil && !d.re.MatchString(value) {
+		return fmt.Errorf("invalid format for '%s'", d.Key)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package head_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStampProviderDefs(t *testing.T) {
+	key := cbc.Key("test-provider")
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key:     key,
+		Name:    i18n.NewString("Test Provider"),
+		Pattern: `^T-\d{4}$`,
+	})
+	d := head.StampProviderDefFor(key)
+	require.NotNil(t, d)
+	assert.Equal(t, "Test Provider", d.Name.String())
+	assert.Contains(t, head.StampProviderDefs(), d)
+	assert.Nil(t, head.StampProviderDefFor("unknown"))
+
+	s := &head.Stamp{Provider: key, Value: "T-1234"}
+	assert.NoError(t, s.Validate())
+	s.Value = "1234"
+	assert.ErrorContains(t, s.Validate(), "val: invalid format for 'test-provider'")
+
+	s = &head.Stamp{Provider: "other", Value: "anything"}
+	assert.NoError(t, s.Validate())
+
+	t.Run("replace", func(t *testing.T) {
+		head.RegisterStampProviderDef(&head.StampProviderDef{
+			Key:  key,
+			Name: i18n.NewString("Test Provider 2"),
+		})
+		assert.Equal(t, "Test Provider 2", head.StampProviderDefFor(key).Name.String())
+		s := &head.Stamp{Provider: key, Value: "1234"}
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		assert.Panics(t, func() {
+			head.RegisterStampProviderDef(&head.StampProviderDef{Key: "bad", Pattern: `(`})
+		})
+	})
+}
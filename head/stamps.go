@@ -21,10 +21,24 @@ type Stamp struct {
 func (s *Stamp) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Provider, validation.Required),
-		validation.Field(&s.Value, validation.Required),
+		validation.Field(&s.Value,
+			validation.Required,
+			validation.By(s.checkProviderFormat),
+		),
 	)
 }
 
+func (s *Stamp) checkProviderFormat(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+	if d := StampProviderDefFor(s.Provider); d != nil {
+		return d.ValidateValue(v)
+	}
+	return nil
+}
+
 // In checks if the stamp is in the list of stamps.
 func (s *Stamp) In(ss []*Stamp) bool {
 	for _, r := range ss {
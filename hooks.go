@@ -0,0 +1,90 @@
+package gobl
+
+import "sync"
+
+// SignHook is a function called with the envelope immediately before or
+// after it is signed. Hooks called before signing may perform final checks
+// or modify the document, for example to assign the final sequential code,
+// while those called afterwards are useful for notifications or archiving.
+type SignHook func(e *Envelope) error
+
+type signHooks struct {
+	before []SignHook
+	after  []SignHook
+}
+
+var globalSignHooks = struct {
+	sync.RWMutex
+	signHooks
+}{}
+
+// RegisterBeforeSign adds a hook that will be called before signing any
+// envelope, in the order of registration, ahead of any hooks defined on
+// the envelope itself.
+func RegisterBeforeSign(fn SignHook) {
+	globalSignHooks.Lock()
+	defer globalSignHooks.Unlock()
+	globalSignHooks.before = append(globalSignHooks.before, fn)
+}
+
+// RegisterAfterSign adds a hook that will be called after successfully
+// signing any envelope, in the order of registration, ahead of any hooks
+// defined on the envelope itself.
+func RegisterAfterSign(fn SignHook) {
+	globalSignHooks.Lock()
+	defer globalSignHooks.Unlock()
+	globalSignHooks.after = append(globalSignHooks.after, fn)
+}
+
+// BeforeSign adds a hook to be called on this envelope only, immediately
+// before signing. If any hook fails, the envelope will not be signed.
+func (e *Envelope) BeforeSign(fn SignHook) {
+	if e.hooks == nil {
+		e.hooks = new(signHooks)
+	}
+	e.hooks.before = append(e.hooks.before, fn)
+}
+
+// AfterSign adds a hook to be called on this envelope only, after it has
+// been signed successfully. Errors from hooks will be returned by Sign, but
+// the envelope will remain signed.
+func (e *Envelope) AfterSign(fn SignHook) {
+	if e.hooks == nil {
+		e.hooks = new(signHooks)
+	}
+	e.hooks.after = append(e.hooks.after, fn)
+}
+
+func (e *Envelope) beforeSignHooks() []SignHook {
+	globalSignHooks.RLock()
+	list := append([]SignHook{}, globalSignHooks.before...)
+	globalSignHooks.RUnlock()
+	if e.hooks != nil {
+		list = append(list, e.hooks.before...)
+	}
+	return list
+}
+
+func (e *Envelope) afterSignHooks() []SignHook {
+	globalSignHooks.RLock()
+	list := append([]SignHook{}, globalSignHooks.after...)
+	globalSignHooks.RUnlock()
+	if e.hooks != nil {
+		list = append(list, e.hooks.after...)
+	}
+	return list
+}
+
+// runSignHooks calls each of the hooks in order, stopping at the first
+// error.
+func (e *Envelope) runSignHooks(hooks []SignHook) error {
+	for _, fn := range hooks {
+		if err := fn(e); err != nil {
+			if _, ok := err.(*Error); ok {
+				return err
+			}
+			return ErrSignature.WithCause(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package gobl_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeSignHooks(t *testing.T) {
+	t.Run("before modifies document", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		calls := []string{}
+		env.BeforeSign(func(e *gobl.Envelope) error {
+			calls = append(calls, "before")
+			inv := e.Extract().(*bill.Invoice)
+			inv.Code = cbc.Code("FINAL-001")
+			return nil
+		})
+		env.AfterSign(func(e *gobl.Envelope) error {
+			calls = append(calls, "after")
+			assert.True(t, e.Signed())
+			return nil
+		})
+		require.NoError(t, env.Sign(testKey))
+		assert.Equal(t, []string{"before", "after"}, calls)
+		assert.Equal(t, "FINAL-001", env.Extract().(*bill.Invoice).Code.String())
+		assert.NoError(t, env.Validate())
+		assert.NoError(t, env.Verify(testKey.Public()))
+	})
+	t.Run("before fails", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		after := false
+		env.BeforeSign(func(_ *gobl.Envelope) error {
+			return errors.New("not approved")
+		})
+		env.AfterSign(func(_ *gobl.Envelope) error {
+			after = true
+			return nil
+		})
+		err := env.Sign(testKey)
+		assert.ErrorIs(t, err, gobl.ErrSignature)
+		assert.ErrorContains(t, err, "not approved")
+		assert.False(t, env.Signed())
+		assert.False(t, after)
+	})
+	t.Run("after fails", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		env.AfterSign(func(_ *gobl.Envelope) error {
+			return gobl.ErrInternal.WithReason("archive unavailable")
+		})
+		err := env.Sign(testKey)
+		assert.ErrorIs(t, err, gobl.ErrInternal)
+		assert.True(t, env.Signed())
+	})
+	t.Run("global", func(t *testing.T) {
+		gobl.RegisterBeforeSign(func(e *gobl.Envelope) error {
+			if slices.Contains(e.Head.Tags, "hook-test") {
+				e.Head.Meta["hook"] = "global"
+			}
+			return nil
+		})
+		env := testAttachmentEnvelope(t)
+		env.Head.Tags = append(env.Head.Tags, "hook-test")
+		order := []string{}
+		env.BeforeSign(func(e *gobl.Envelope) error {
+			order = append(order, e.Head.Meta["hook"])
+			return nil
+		})
+		require.NoError(t, env.Sign(testKey))
+		assert.Equal(t, []string{"global"}, order)
+	})
+}
@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/invopop/validation"
+	"golang.org/x/text/language"
+)
+
+// Error is a validation error with a stable machine readable code and a
+// message template that may be translated into several languages. Errors
+// implement the validation.Error interface, so the English message will
+// be used when converted into a string, while the Localize method may
+// be used to present the message in the language of the user.
+//
+// Templates may refer to parameters using the text/template syntax, for
+// example: `value '{{.value}}' invalid`.
+type Error struct {
+	code   string
+	msg    String
+	params map[string]any
+}
+
+// NewError instantiates a new translatable error with the code and
+// message templates, which must at least include English.
+func NewError(code string, msg String) Error {
+	return Error{code: code, msg: msg}
+}
+
+// Code provides the error's code.
+func (e Error) Code() string {
+	return e.code
+}
+
+// Message provides the default language message template.
+func (e Error) Message() string {
+	return e.msg.String()
+}
+
+// SetMessage replaces the message template with the one provided, which
+// will be used for all languages.
+func (e Error) SetMessage(msg string) validation.Error {
+	e.msg = NewString(msg)
+	return e
+}
+
+// Params provides the parameters used by the message template.
+func (e Error) Params() map[string]any {
+	return e.params
+}
+
+// SetParams provides a copy of the error with the parameters to use in
+// the message template.
+func (e Error) SetParams(params map[string]any) validation.Error {
+	e.params = params
+	return e
+}
+
+// WithParams is a convenience method to provide a copy of the error with
+// the parameters while maintaining the Error type.
+func (e Error) WithParams(params map[string]any) Error {
+	e.params = params
+	return e
+}
+
+// Templates provides the message templates for each language.
+func (e Error) Templates() String {
+	return e.msg
+}
+
+// Error provides the message in the default language.
+func (e Error) Error() string {
+	return e.render(e.msg.String())
+}
+
+// Localize provides the message in the language that best matches the
+// list of language tags, as per String.InLocale.
+func (e Error) Localize(tags ...language.Tag) string {
+	return e.render(e.msg.InLocale(tags...))
+}
+
+func (e Error) render(msg string) string {
+	return RenderTemplate(msg, e.params)
+}
+
+// RenderTemplate executes the message template using the parameters, or
+// provides the message as is if there are no parameters or the template
+// could not be executed.
+func RenderTemplate(msg string, params map[string]any) string {
+	if len(params) == 0 {
+		return msg
+	}
+	t, err := template.New("err").Parse(msg)
+	if err != nil {
+		return msg
+	}
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, params); err != nil {
+		return msg
+	}
+	return buf.String()
+}
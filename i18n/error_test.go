@@ -0,0 +1,50 @@
+package i18n_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/validation"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestError(t *testing.T) {
+	e := i18n.NewError("validation_test", i18n.String{
+		i18n.EN: "value '{{.value}}' invalid",
+		i18n.ES: "valor '{{.value}}' no válido",
+	})
+	assert.Equal(t, "validation_test", e.Code())
+	assert.Equal(t, "value '{{.value}}' invalid", e.Message())
+	assert.Equal(t, "value '{{.value}}' invalid", e.Error())
+	assert.Nil(t, e.Params())
+
+	ep := e.WithParams(map[string]any{"value": "FOO"})
+	assert.Equal(t, "value 'FOO' invalid", ep.Error())
+	assert.Equal(t, "valor 'FOO' no válido", ep.Localize(language.MustParse("es-MX")))
+	assert.Equal(t, "value 'FOO' invalid", ep.Localize(language.Japanese))
+	assert.Nil(t, e.Params(), "original not modified")
+	assert.Equal(t, "valor '{{.value}}' no válido", ep.Templates().In(i18n.ES))
+
+	t.Run("validation error", func(t *testing.T) {
+		var ve validation.Error = e
+		ve = ve.SetParams(map[string]any{"value": "BAR"})
+		assert.Equal(t, "value 'BAR' invalid", ve.Error())
+		ve = ve.SetMessage("custom {{.value}}")
+		assert.Equal(t, "custom BAR", ve.Error())
+		assert.Equal(t, "validation_test", ve.Code())
+		assert.Equal(t, "custom BAR", ve.(i18n.Error).Localize(language.Spanish))
+
+		err := validation.Validate("", validation.By(func(any) error {
+			return e
+		}))
+		assert.True(t, errors.As(err, new(i18n.Error)))
+	})
+}
+
+func TestRenderTemplate(t *testing.T) {
+	assert.Equal(t, "plain", i18n.RenderTemplate("plain", nil))
+	assert.Equal(t, "min 3", i18n.RenderTemplate("min {{.min}}", map[string]any{"min": 3}))
+	assert.Equal(t, "bad {{.min", i18n.RenderTemplate("bad {{.min", map[string]any{"min": 3}))
+}
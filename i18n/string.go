@@ -1,6 +1,11 @@
 package i18n
 
-import "github.com/invopop/jsonschema"
+import (
+	"slices"
+
+	"github.com/invopop/jsonschema"
+	"golang.org/x/text/language"
+)
 
 const (
 	defaultLanguage = EN
@@ -26,15 +31,54 @@ func (s String) In(lang Lang) string {
 	return s.String()
 }
 
-// String returns the default language string or first entry found.
+// InLocale provides the text that best matches the list of language tags in
+// order of preference, as would be parsed from an HTTP Accept-Language header
+// using language.ParseAcceptLanguage. Tags are matched according to BCP 47,
+// so regional variants such as "es-MX" will fall back to their base language,
+// and closely related languages may also be matched. If no match is found,
+// the default language or first entry will be provided, as per String.
+func (s String) InLocale(tags ...language.Tag) string {
+	if len(s) == 0 {
+		return ""
+	}
+	keys := s.langs()
+	supported := make([]language.Tag, 0, len(keys)+1)
+	// the first supported tag is used when there is no match
+	supported = append(supported, language.Und)
+	for _, k := range keys {
+		t, err := language.Parse(string(k))
+		if err != nil {
+			t = language.Und
+		}
+		supported = append(supported, t)
+	}
+	_, i, conf := language.NewMatcher(supported).Match(tags...)
+	if conf == language.No || i == 0 {
+		return s.String()
+	}
+	return s[keys[i-1]]
+}
+
+// String returns the default language string or the entry with the first
+// language code in alphabetical order.
 func (s String) String() string {
 	if v, ok := s[defaultLanguage]; ok {
 		return v
 	}
-	for _, v := range s {
-		return v // provide first entry
+	if len(s) == 0 {
+		return ""
+	}
+	return s[s.langs()[0]]
+}
+
+// langs provides the sorted list of languages in the string.
+func (s String) langs() []Lang {
+	keys := make([]Lang, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
 	}
-	return ""
+	slices.Sort(keys)
+	return keys
 }
 
 // IsEmpty returns true if the string map is empty.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/invopop/gobl/i18n"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
 )
 
 func TestI18nString(t *testing.T) {
@@ -27,3 +28,45 @@ func TestI18nString(t *testing.T) {
 	s2 := i18n.NewString("Test")
 	assert.Equal(t, "Test", s2.In("en"))
 }
+
+func TestI18nStringInLocale(t *testing.T) {
+	s := i18n.String{
+		i18n.EN: "Invoice",
+		i18n.ES: "Factura",
+		i18n.PT: "Fatura",
+		i18n.NO: "Faktura",
+	}
+	tests := []struct {
+		tags string
+		exp  string
+	}{
+		{"es", "Factura"},
+		{"es-MX", "Factura"},
+		{"pt-BR", "Fatura"},
+		{"nb", "Faktura"},
+		{"fr-CA, es;q=0.8, en;q=0.5", "Factura"},
+		{"en-GB, es", "Invoice"},
+		{"de", "Invoice"},
+		{"", "Invoice"},
+	}
+	for _, ts := range tests {
+		t.Run(ts.tags, func(t *testing.T) {
+			tags, _, err := language.ParseAcceptLanguage(ts.tags)
+			assert.NoError(t, err)
+			assert.Equal(t, ts.exp, s.InLocale(tags...))
+		})
+	}
+
+	t.Run("without default", func(t *testing.T) {
+		s := i18n.String{
+			i18n.FR: "Facture",
+			i18n.DE: "Rechnung",
+		}
+		assert.Equal(t, "Rechnung", s.InLocale(language.Japanese))
+		assert.Equal(t, "Facture", s.InLocale(language.CanadianFrench))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, i18n.String{}.InLocale(language.English))
+	})
+}
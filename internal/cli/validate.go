@@ -20,14 +20,14 @@ func Validate(ctx context.Context, r io.Reader) error {
 	}
 
 	if env, ok := obj.(*gobl.Envelope); ok {
-		if err := env.Validate(); err != nil {
+		if err := env.ValidateWithContext(ctx); err != nil {
 			return wrapError(http.StatusUnprocessableEntity, err)
 		}
 		return nil
 	}
 
 	if doc, ok := obj.(*schema.Object); ok {
-		if err := doc.Validate(); err != nil {
+		if err := doc.ValidateWithContext(ctx); err != nil {
 			return wrapError(http.StatusUnprocessableEntity, err)
 		}
 		return nil
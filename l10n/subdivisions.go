@@ -0,0 +1,182 @@
+package l10n
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/invopop/gobl/data"
+	"github.com/invopop/validation"
+)
+
+// subdivisionsFile contains the ISO 3166-2 subdivision codes, prepared from
+// the Debian iso-codes project.
+const subdivisionsFile = "l10n/subdivisions.json"
+
+// SubdivisionDef describes an ISO 3166-2 country subdivision, such as a
+// state, province, or region.
+type SubdivisionDef struct {
+	// Complete ISO 3166-2 code including the country, e.g. `ES-M`
+	Code string `json:"code" jsonschema:"title=Code"`
+	// Name of the subdivision, usually in the local language
+	Name string `json:"name" jsonschema:"title=Name"`
+	// Type of subdivision, e.g. `State`, `Province`, or `Region`
+	Type string `json:"type" jsonschema:"title=Type"`
+	// Complete code of the subdivision this one belongs to, if any
+	Parent string `json:"parent,omitempty" jsonschema:"title=Parent"`
+}
+
+// SubdivisionDefs contains a list of subdivision definitions.
+type SubdivisionDefs []*SubdivisionDef
+
+// Country provides the ISO country code of the subdivision.
+func (sd *SubdivisionDef) Country() ISOCountryCode {
+	c, _, _ := strings.Cut(sd.Code, "-")
+	return ISOCountryCode(c)
+}
+
+// Local provides the part of the code that identifies the subdivision
+// inside the country, e.g. `M` for `ES-M`, as used in address state
+// fields.
+func (sd *SubdivisionDef) Local() Code {
+	_, l, _ := strings.Cut(sd.Code, "-")
+	return Code(l)
+}
+
+// Code finds the subdivision with the local or complete code.
+func (sds SubdivisionDefs) Code(code string) *SubdivisionDef {
+	for _, sd := range sds {
+		if sd.Code == code || string(sd.Local()) == code {
+			return sd
+		}
+	}
+	return nil
+}
+
+// Name finds the subdivision with the name, ignoring case.
+func (sds SubdivisionDefs) Name(name string) *SubdivisionDef {
+	for _, sd := range sds {
+		if strings.EqualFold(sd.Name, name) {
+			return sd
+		}
+	}
+	return nil
+}
+
+// OfType provides the subdivisions whose type matches one of those
+// provided, ignoring case.
+func (sds SubdivisionDefs) OfType(types ...string) SubdivisionDefs {
+	list := make(SubdivisionDefs, 0)
+	for _, sd := range sds {
+		for _, t := range types {
+			if strings.EqualFold(sd.Type, t) {
+				list = append(list, sd)
+				break
+			}
+		}
+	}
+	return list
+}
+
+var subdivisions = struct {
+	once sync.Once
+	list map[ISOCountryCode]SubdivisionDefs
+	err  error
+}{}
+
+func loadSubdivisions() {
+	src, err := data.Content.ReadFile(subdivisionsFile)
+	if err != nil {
+		subdivisions.err = err
+		return
+	}
+	var list SubdivisionDefs
+	if err := json.Unmarshal(src, &list); err != nil {
+		subdivisions.err = err
+		return
+	}
+	subdivisions.list = make(map[ISOCountryCode]SubdivisionDefs)
+	for _, sd := range list {
+		c := sd.Country()
+		subdivisions.list[c] = append(subdivisions.list[c], sd)
+	}
+}
+
+// Subdivisions provides the ISO 3166-2 subdivisions of the country, or an
+// empty list if there are none. The definitions are loaded the first time
+// they're needed.
+func Subdivisions(country ISOCountryCode) SubdivisionDefs {
+	subdivisions.once.Do(loadSubdivisions)
+	if subdivisions.err != nil {
+		panic(subdivisions.err)
+	}
+	return subdivisions.list[country]
+}
+
+// SubdivisionFor provides the subdivision definition for the complete
+// ISO 3166-2 code, e.g. `ES-M`, or nil if there is no match.
+func SubdivisionFor(code string) *SubdivisionDef {
+	c, _, ok := strings.Cut(code, "-")
+	if !ok {
+		return nil
+	}
+	sd := Subdivisions(ISOCountryCode(c)).Code(code)
+	if sd == nil || sd.Code != code {
+		return nil
+	}
+	return sd
+}
+
+// Subdivisions is a convenience method to provide the ISO 3166-2
+// subdivisions of the country.
+func (c ISOCountryCode) Subdivisions() SubdivisionDefs {
+	return Subdivisions(c)
+}
+
+// IsSubdivisionOf provides a validation rule that checks a code, such as an
+// address's state, is the local part of a subdivision of the country,
+// optionally limited to the types provided. Empty values are ignored.
+func IsSubdivisionOf(country ISOCountryCode, types ...string) validation.Rule {
+	return subdivisionRule{country: country, types: types}
+}
+
+// IsSubdivisionNameOf provides a validation rule that checks a name, such
+// as an address's region, matches a subdivision of the country, optionally
+// limited to the types provided. Empty values are ignored.
+func IsSubdivisionNameOf(country ISOCountryCode, types ...string) validation.Rule {
+	return subdivisionRule{country: country, types: types, name: true}
+}
+
+type subdivisionRule struct {
+	country ISOCountryCode
+	types   []string
+	name    bool
+}
+
+func (r subdivisionRule) Validate(value any) error {
+	value, isNil := validation.Indirect(value)
+	if isNil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.String || v.Len() == 0 {
+		return nil
+	}
+	s := v.String()
+	sds := Subdivisions(r.country)
+	if len(r.types) > 0 {
+		sds = sds.OfType(r.types...)
+	}
+	if r.name {
+		if sds.Name(s) == nil {
+			return errors.New("must be the name of a valid subdivision of " + string(r.country))
+		}
+		return nil
+	}
+	if sd := sds.Code(s); sd == nil || sd.Code == s {
+		return errors.New("must be a valid subdivision code of " + string(r.country))
+	}
+	return nil
+}
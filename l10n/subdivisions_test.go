@@ -0,0 +1,50 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubdivisions(t *testing.T) {
+	es := l10n.Subdivisions("ES")
+	assert.Greater(t, len(es), 60)
+	sd := es.Code("M")
+	require.NotNil(t, sd)
+	assert.Equal(t, "ES-M", sd.Code)
+	assert.Equal(t, "Madrid", sd.Name)
+	assert.Equal(t, "Province", sd.Type)
+	assert.Equal(t, "ES-MD", sd.Parent)
+	assert.Equal(t, l10n.ISOCountryCode("ES"), sd.Country())
+	assert.Equal(t, l10n.Code("M"), sd.Local())
+	assert.Same(t, sd, es.Code("ES-M"))
+	assert.Same(t, sd, es.Name("madrid"))
+	assert.Nil(t, es.Code("XX"))
+
+	assert.Len(t, es.OfType("autonomous community"), 17)
+	assert.Len(t, l10n.ISOCountryCode("US").Subdivisions().OfType("State"), 50)
+	assert.Empty(t, l10n.Subdivisions("XX"))
+
+	assert.Same(t, sd, l10n.SubdivisionFor("ES-M"))
+	assert.Nil(t, l10n.SubdivisionFor("M"))
+	assert.Nil(t, l10n.SubdivisionFor("ES-XX"))
+}
+
+func TestIsSubdivisionOf(t *testing.T) {
+	r := l10n.IsSubdivisionOf("MX")
+	assert.NoError(t, r.Validate(cbc.Code("CMX")))
+	assert.NoError(t, r.Validate(""))
+	assert.ErrorContains(t, r.Validate("MX-CMX"), "must be a valid subdivision code of MX")
+	assert.ErrorContains(t, r.Validate(cbc.Code("XX")), "must be a valid subdivision code of MX")
+
+	r = l10n.IsSubdivisionOf("ES", "Province")
+	assert.NoError(t, r.Validate("B"))
+	assert.ErrorContains(t, r.Validate("CT"), "must be a valid subdivision code of ES")
+
+	r = l10n.IsSubdivisionNameOf("PL")
+	assert.NoError(t, r.Validate("Mazowieckie"))
+	assert.ErrorContains(t, r.Validate("Bavaria"), "must be the name of a valid subdivision of PL")
+}
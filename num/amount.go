@@ -195,6 +195,29 @@ func (a Amount) Rescale(exp uint32) Amount {
 	return a
 }
 
+// RescaleHalfEven behaves like Rescale, but when reducing the exponent will
+// round values exactly half way between two options to the nearest even
+// number, also known as banker's rounding.
+func (a Amount) RescaleHalfEven(exp uint32) Amount {
+	if a.exp <= exp {
+		return a.Rescale(exp)
+	}
+	d := intPow(10, a.exp-exp)
+	v := a.value
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	q, r := v/d, v%d
+	if r*2 > d || (r*2 == d && q%2 == 1) {
+		q++
+	}
+	if neg {
+		q = -q
+	}
+	return Amount{q, exp}
+}
+
 // RescaleUp will rescale the exponent value of the amount, but only if it is
 // lower than the current exponent.
 func (a Amount) RescaleUp(exp uint32) Amount {
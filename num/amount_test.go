@@ -268,6 +268,28 @@ func TestAmountDownscale(t *testing.T) {
 	assert.Equal(t, "22", b.String())
 }
 
+func TestAmountRescaleHalfEven(t *testing.T) {
+	tests := []struct {
+		in  num.Amount
+		exp uint32
+		out string
+	}{
+		{num.MakeAmount(12345, 3), 2, "12.34"},
+		{num.MakeAmount(12355, 3), 2, "12.36"},
+		{num.MakeAmount(12346, 3), 2, "12.35"},
+		{num.MakeAmount(123451, 4), 2, "12.35"},
+		{num.MakeAmount(-12345, 3), 2, "-12.34"},
+		{num.MakeAmount(-12355, 3), 2, "-12.36"},
+		{num.MakeAmount(25, 1), 0, "2"},
+		{num.MakeAmount(35, 1), 0, "4"},
+		{num.MakeAmount(1234, 2), 2, "12.34"},
+		{num.MakeAmount(1234, 2), 3, "12.340"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.out, tt.in.RescaleHalfEven(tt.exp).String())
+	}
+}
+
 func TestAmountRescaleUp(t *testing.T) {
 	a := num.MakeAmount(123456, 2)
 	r := a.RescaleUp(4)
@@ -0,0 +1,222 @@
+// Package observe provides hooks that GOBL calls around core operations,
+// like calculating, validating, signing, and converting documents, so that
+// services can trace and meter GOBL's behavior in one place instead of
+// wrapping every call site.
+//
+// Observing is disabled until a hook is registered, at which point each
+// operation will report an Event with the type of document, its regime and
+// add-ons, how long the operation took, and its outcome.
+package observe
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/schema"
+)
+
+// Operation identifies the type of operation being observed.
+type Operation string
+
+// Operations reported by GOBL.
+const (
+	OpCalculate Operation = "calculate"
+	OpValidate  Operation = "validate"
+	OpSign      Operation = "sign"
+	OpExport    Operation = "export"
+	OpImport    Operation = "import"
+)
+
+// Event describes an operation that has completed.
+type Event struct {
+	// Op is the operation performed.
+	Op Operation
+	// Format of the conversion, like "ubl" or "cii", for export and import
+	// operations.
+	Format string
+	// Schema of the document, if known.
+	Schema schema.ID
+	// Regime of the document, if any.
+	Regime l10n.TaxCountryCode
+	// Addons applied to the document.
+	Addons []cbc.Key
+	// Start is when the operation started.
+	Start time.Time
+	// Duration of the operation.
+	Duration time.Duration
+	// Err contains the error returned by the operation, if it failed.
+	Err error
+}
+
+// Hook is implemented by anything that would like to receive events.
+// Hooks are called synchronously from the operation's goroutine, so should
+// return quickly.
+type Hook interface {
+	Observe(ctx context.Context, ev *Event)
+}
+
+// HookFunc allows a function to be used as a Hook.
+type HookFunc func(ctx context.Context, ev *Event)
+
+// Observe calls the function.
+func (fn HookFunc) Observe(ctx context.Context, ev *Event) {
+	fn(ctx, ev)
+}
+
+var (
+	enabled atomic.Bool
+	hooksMu sync.RWMutex
+	hooks   []*hookEntry
+)
+
+type hookEntry struct {
+	Hook
+}
+
+// Register adds a hook that will receive events from all operations,
+// returning a function that removes it again.
+func Register(h Hook) func() {
+	e := &hookEntry{h}
+	hooksMu.Lock()
+	hooks = append(hooks, e)
+	enabled.Store(true)
+	hooksMu.Unlock()
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		for i, x := range hooks {
+			if x == e {
+				hooks = append(hooks[:i:i], hooks[i+1:]...)
+				break
+			}
+		}
+		enabled.Store(len(hooks) > 0)
+	}
+}
+
+// Enabled returns true if any hooks have been registered.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Span tracks an operation in progress. A nil span is returned when no
+// hooks are registered, and is safe to use.
+type Span struct {
+	ctx context.Context
+	ev  Event
+}
+
+// Start begins tracking an operation, and should be followed by a call to
+// End when the operation completes.
+func Start(ctx context.Context, op Operation) *Span {
+	if !Enabled() {
+		return nil
+	}
+	return &Span{
+		ctx: ctx,
+		ev:  Event{Op: op, Start: time.Now()},
+	}
+}
+
+// StartConversion begins tracking an export or import operation with the
+// format's name.
+func StartConversion(ctx context.Context, op Operation, format string) *Span {
+	s := Start(ctx, op)
+	if s != nil {
+		s.ev.Format = format
+	}
+	return s
+}
+
+// End completes the operation with the document it operated on, which
+// may be nil, and error, and passes the event to the hooks. Details are
+// taken from the document at the end of the operation, as calculations
+// and imports will typically only define them along the way.
+func (s *Span) End(doc any, err error) {
+	if s == nil {
+		return
+	}
+	ev := &s.ev
+	ev.Duration = time.Since(ev.Start)
+	ev.Err = err
+	if obj, ok := doc.(*schema.Object); ok {
+		doc = nil
+		if obj != nil {
+			ev.Schema = obj.Schema
+			doc = obj.Instance()
+		}
+	}
+	if doc != nil {
+		if ev.Schema == schema.UnknownID {
+			ev.Schema = schema.Lookup(doc)
+		}
+		if r, ok := doc.(interface{ GetRegime() l10n.TaxCountryCode }); ok {
+			ev.Regime = r.GetRegime()
+		}
+		if a, ok := doc.(interface{ GetAddons() []cbc.Key }); ok {
+			ev.Addons = a.GetAddons()
+		}
+	}
+	hooksMu.RLock()
+	list := hooks
+	hooksMu.RUnlock()
+	for _, h := range list {
+		h.Observe(s.ctx, ev)
+	}
+}
+
+// Succeeded returns true if the operation did not fail.
+func (ev *Event) Succeeded() bool {
+	return ev.Err == nil
+}
+
+// LogAttrs provides the event's details as structured logging attributes.
+func (ev *Event) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("op", string(ev.Op)),
+	}
+	if ev.Format != "" {
+		attrs = append(attrs, slog.String("format", ev.Format))
+	}
+	if ev.Schema != schema.UnknownID {
+		attrs = append(attrs, slog.String("schema", ev.Schema.String()))
+	}
+	if ev.Regime != "" {
+		attrs = append(attrs, slog.String("regime", ev.Regime.String()))
+	}
+	if len(ev.Addons) > 0 {
+		addons := make([]string, len(ev.Addons))
+		for i, k := range ev.Addons {
+			addons[i] = k.String()
+		}
+		attrs = append(attrs, slog.Any("addons", addons))
+	}
+	attrs = append(attrs, slog.Duration("duration", ev.Duration))
+	if ev.Err != nil {
+		attrs = append(attrs, slog.String("error", ev.Err.Error()))
+	}
+	return attrs
+}
+
+// LogValue groups the event's attributes so that it may be logged as a
+// single value.
+func (ev *Event) LogValue() slog.Value {
+	return slog.GroupValue(ev.LogAttrs()...)
+}
+
+// LogHook provides a hook that writes events to the logger, using the
+// info level for successful operations and the error level for failures.
+func LogHook(logger *slog.Logger) Hook {
+	return HookFunc(func(ctx context.Context, ev *Event) {
+		level := slog.LevelInfo
+		if ev.Err != nil {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(ctx, level, "gobl "+string(ev.Op), ev.LogAttrs()...)
+	})
+}
@@ -0,0 +1,104 @@
+package observe_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/observe"
+	"github.com/invopop/gobl/schema"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T) *[]*observe.Event {
+	t.Helper()
+	events := new([]*observe.Event)
+	remove := observe.Register(observe.HookFunc(func(_ context.Context, ev *observe.Event) {
+		*events = append(*events, ev)
+	}))
+	t.Cleanup(remove)
+	return events
+}
+
+func TestRegister(t *testing.T) {
+	assert.False(t, observe.Enabled())
+	assert.Nil(t, observe.Start(context.Background(), observe.OpCalculate))
+
+	events := collect(t)
+	assert.True(t, observe.Enabled())
+	remove := observe.Register(observe.HookFunc(func(context.Context, *observe.Event) {}))
+	remove()
+	assert.True(t, observe.Enabled(), "other hooks remain")
+
+	s := observe.Start(context.Background(), observe.OpValidate)
+	require.NotNil(t, s)
+	s.End(nil, nil)
+	assert.Len(t, *events, 1)
+}
+
+func TestSpanEnd(t *testing.T) {
+	inv := &bill.Invoice{
+		Regime: tax.WithRegime("ES"),
+		Addons: tax.WithAddons("eu-en16931-v2017"),
+	}
+	t.Run("nil span", func(t *testing.T) {
+		var s *observe.Span
+		assert.NotPanics(t, func() { s.End(inv, nil) })
+	})
+	t.Run("with document", func(t *testing.T) {
+		events := collect(t)
+		s := observe.StartConversion(context.Background(), observe.OpExport, "ubl")
+		s.End(inv, errors.New("boom"))
+		require.Len(t, *events, 1)
+		ev := (*events)[0]
+		assert.Equal(t, observe.OpExport, ev.Op)
+		assert.Equal(t, "ubl", ev.Format)
+		assert.Equal(t, schema.Lookup(inv), ev.Schema)
+		assert.Equal(t, "ES", ev.Regime.String())
+		assert.Equal(t, []cbc.Key{"eu-en16931-v2017"}, ev.Addons)
+		assert.False(t, ev.Succeeded())
+		assert.False(t, ev.Start.IsZero())
+	})
+	t.Run("with object", func(t *testing.T) {
+		events := collect(t)
+		obj, err := schema.NewObject(inv)
+		require.NoError(t, err)
+		observe.Start(context.Background(), observe.OpCalculate).End(obj, nil)
+		ev := (*events)[0]
+		assert.Equal(t, schema.Lookup(inv), ev.Schema)
+		assert.Equal(t, "ES", ev.Regime.String())
+		assert.True(t, ev.Succeeded())
+	})
+	t.Run("with nil object", func(t *testing.T) {
+		events := collect(t)
+		var obj *schema.Object
+		observe.Start(context.Background(), observe.OpCalculate).End(obj, nil)
+		assert.Equal(t, schema.UnknownID, (*events)[0].Schema)
+	})
+}
+
+func TestLogHook(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	t.Cleanup(observe.Register(observe.LogHook(logger)))
+
+	inv := &bill.Invoice{Regime: tax.WithRegime("ES")}
+	observe.Start(context.Background(), observe.OpValidate).End(inv, errors.New("invalid"))
+
+	out := make(map[string]any)
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "ERROR", out["level"])
+	assert.Equal(t, "gobl validate", out["msg"])
+	assert.Equal(t, "validate", out["op"])
+	assert.Equal(t, "ES", out["regime"])
+	assert.Equal(t, "invalid", out["error"])
+	assert.Contains(t, out["schema"], "bill/invoice")
+	assert.Contains(t, out, "duration")
+}
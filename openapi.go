@@ -0,0 +1,24 @@
+package gobl
+
+import (
+	"io/fs"
+
+	"github.com/invopop/gobl/data"
+	"github.com/invopop/gobl/schema"
+)
+
+// OpenAPIComponents provides the OpenAPI 3.1 component schemas of all the
+// JSON Schemas embedded in GOBL, ready to be included in the `components`
+// section of an API's specification. See schema.OpenAPIComponentName for
+// details on how the components are named.
+func OpenAPIComponents() (map[string]any, error) {
+	schemas, err := fs.Sub(data.Content, "schemas")
+	if err != nil {
+		return nil, ErrInternal.WithCause(err)
+	}
+	out, err := schema.OpenAPIComponents(schemas)
+	if err != nil {
+		return nil, ErrInternal.WithCause(err)
+	}
+	return out, nil
+}
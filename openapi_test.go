@@ -0,0 +1,38 @@
+package gobl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIComponents(t *testing.T) {
+	comps, err := gobl.OpenAPIComponents()
+	require.NoError(t, err)
+	assert.Contains(t, comps, "envelope")
+	assert.Contains(t, comps, "bill.invoice")
+	assert.Contains(t, comps, "org.party")
+
+	// all internal references must point to existing components
+	var check func(v any)
+	check = func(v any) {
+		switch tv := v.(type) {
+		case map[string]any:
+			if ref, ok := tv["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				assert.Contains(t, comps, name, "reference %s", ref)
+			}
+			for _, sv := range tv {
+				check(sv)
+			}
+		case []any:
+			for _, sv := range tv {
+				check(sv)
+			}
+		}
+	}
+	check(comps)
+}
@@ -96,6 +96,23 @@ func (Address) JSONSchemaExtend(js *jsonschema.Schema) {
 	}
 }
 
+// Subdivision provides the ISO 3166-2 subdivision of the address's country
+// determined from the state code, or if not set, the region's name. Nil is
+// returned if there is no match.
+func (a *Address) Subdivision() *l10n.SubdivisionDef {
+	if a == nil || a.Country.Empty() {
+		return nil
+	}
+	sds := a.Country.Subdivisions()
+	if a.State != cbc.CodeEmpty {
+		return sds.Code(a.State.String())
+	}
+	if a.Region != "" {
+		return sds.Name(a.Region)
+	}
+	return nil
+}
+
 // LineOne returns the first line of the address by automatically
 // combining street, number, and other details into a single line.
 // This is useful for compatibility with other formats that don't
@@ -5,6 +5,7 @@ import (
 
 	"github.com/invopop/gobl/org"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAddressNormalize(t *testing.T) {
@@ -74,6 +75,23 @@ func TestAddressValidation(t *testing.T) {
 	})
 }
 
+func TestAddressSubdivision(t *testing.T) {
+	a := &org.Address{State: "JAL", Country: "MX"}
+	sd := a.Subdivision()
+	require.NotNil(t, sd)
+	assert.Equal(t, "MX-JAL", sd.Code)
+
+	a = &org.Address{Region: "Bayern", Country: "DE"}
+	sd = a.Subdivision()
+	require.NotNil(t, sd)
+	assert.Equal(t, "DE-BY", sd.Code)
+
+	assert.Nil(t, (&org.Address{State: "JAL"}).Subdivision())
+	assert.Nil(t, (&org.Address{State: "XXX", Country: "MX"}).Subdivision())
+	assert.Nil(t, (&org.Address{Country: "MX"}).Subdivision())
+	assert.Nil(t, (*org.Address)(nil).Subdivision())
+}
+
 func TestAddressLineOne(t *testing.T) {
 	t.Run("missing street", func(t *testing.T) {
 		a := &org.Address{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/invopop/gobl/catalogues/iso"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/l10n"
 	"github.com/invopop/gobl/tax"
@@ -90,6 +91,7 @@ func (i *Identity) ValidateWithContext(ctx context.Context) error {
 		),
 		validation.Field(&i.Code,
 			validation.Required,
+			iso.InSchemeFormat(i.Ext.Get(iso.ExtKeySchemeID)),
 		),
 		validation.Field(&i.Ext),
 	)
@@ -131,6 +131,24 @@ func TestIdentityValidate(t *testing.T) {
 		err := id.Validate()
 		assert.ErrorContains(t, err, "type: must be empty when key is set")
 	})
+	t.Run("with valid scheme code", func(t *testing.T) {
+		id := &org.Identity{
+			Code: "5790000435951",
+			Ext: tax.Extensions{
+				iso.ExtKeySchemeID: "0088",
+			},
+		}
+		assert.NoError(t, id.Validate())
+	})
+	t.Run("with invalid scheme code", func(t *testing.T) {
+		id := &org.Identity{
+			Code: "5790000435952",
+			Ext: tax.Extensions{
+				iso.ExtKeySchemeID: "0088",
+			},
+		}
+		assert.ErrorContains(t, id.Validate(), "code: must be a valid GLN")
+	})
 }
 
 func TestIdentitySetValidators(t *testing.T) {
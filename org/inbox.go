@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/invopop/gobl/catalogues/iso"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/gobl/uuid"
@@ -90,6 +91,7 @@ func (i *Inbox) ValidateWithContext(ctx context.Context) error {
 				i.URL == "" && i.Email == "",
 				validation.Required.Error("cannot be blank without url or email"),
 			),
+			iso.InSchemeFormat(i.Scheme),
 		),
 		validation.Field(&i.URL,
 			is.URL,
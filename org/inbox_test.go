@@ -191,5 +191,18 @@ func TestInboxValidate(t *testing.T) {
 		err := id.Validate()
 		assert.ErrorContains(t, err, "email: must be a valid email address")
 	})
-
+	t.Run("with valid scheme code", func(t *testing.T) {
+		id := &org.Inbox{
+			Scheme: "0208",
+			Code:   "0897223868",
+		}
+		assert.NoError(t, id.Validate())
+	})
+	t.Run("with invalid scheme code", func(t *testing.T) {
+		id := &org.Inbox{
+			Scheme: "0208",
+			Code:   "0897223869",
+		}
+		assert.ErrorContains(t, id.Validate(), "code: must be a valid KBO-BCE")
+	})
 }
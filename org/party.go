@@ -2,7 +2,9 @@ package org
 
 import (
 	"context"
+	"strings"
 
+	"github.com/invopop/gobl/catalogues/iso"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/schema"
 	"github.com/invopop/gobl/tax"
@@ -12,6 +14,8 @@ import (
 	"github.com/invopop/validation"
 )
 
+const endpointPeppolPrefix = "iso6523-actorid-upis::"
+
 // Party represents a person or business entity.
 type Party struct {
 	tax.Regime
@@ -32,6 +36,9 @@ type Party struct {
 	People []*Person `json:"people,omitempty" jsonschema:"title=People"`
 	// Digital inboxes used for forwarding electronic versions of documents
 	Inboxes []*Inbox `json:"inboxes,omitempty" jsonschema:"title=Inboxes"`
+	// Electronic address used to route documents to the party, qualified by
+	// the ISO 6523 ICD or EAS code of its scheme, e.g. "0192:991825827".
+	Endpoint cbc.SchemeCode `json:"endpoint,omitempty" jsonschema:"title=Endpoint"`
 	// Regular post addresses for where information should be sent if needed.
 	Addresses []*Address `json:"addresses,omitempty" jsonschema:"title=Postal Addresses"`
 	// Electronic mail addresses
@@ -71,6 +78,7 @@ func (p *Party) Normalize(normalizers tax.Normalizers) {
 	p.Label = cbc.NormalizeString(p.Label)
 	p.Name = cbc.NormalizeString(p.Name)
 	p.Alias = cbc.NormalizeString(p.Alias)
+	p.Endpoint = normalizeEndpoint(p.Endpoint)
 
 	p.Ext = tax.CleanExtensions(p.Ext)
 
@@ -103,6 +111,7 @@ func (p *Party) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&p.Identities),
 		validation.Field(&p.People),
 		validation.Field(&p.Inboxes),
+		validation.Field(&p.Endpoint, iso.ValidSchemeCode),
 		validation.Field(&p.Addresses),
 		validation.Field(&p.Emails),
 		validation.Field(&p.Websites),
@@ -114,6 +123,39 @@ func (p *Party) ValidateWithContext(ctx context.Context) error {
 	)
 }
 
+// ElectronicAddress provides the scheme qualified address that should be
+// used to route documents to the party. The Endpoint is preferred, followed
+// by the Peppol inbox and the first inbox with both a scheme and code.
+func (p *Party) ElectronicAddress() cbc.SchemeCode {
+	if p == nil {
+		return cbc.SchemeCodeEmpty
+	}
+	if !p.Endpoint.IsEmpty() {
+		return p.Endpoint
+	}
+	var addr cbc.SchemeCode
+	for _, i := range p.Inboxes {
+		if i == nil || i.Scheme == cbc.CodeEmpty || i.Code == cbc.CodeEmpty {
+			continue
+		}
+		if i.Key == InboxKeyPeppol {
+			return cbc.NewSchemeCode(i.Scheme, i.Code)
+		}
+		if addr.IsEmpty() {
+			addr = cbc.NewSchemeCode(i.Scheme, i.Code)
+		}
+	}
+	return addr
+}
+
+// normalizeEndpoint removes the Peppol participant identifier scheme prefix
+// that is often copied alongside the address.
+func normalizeEndpoint(sc cbc.SchemeCode) cbc.SchemeCode {
+	s := strings.TrimSpace(sc.String())
+	s = strings.TrimPrefix(s, endpointPeppolPrefix)
+	return cbc.NormalizeSchemeCode(cbc.SchemeCode(s))
+}
+
 // validationContext returns a context with the regime's validation rules.
 func (p *Party) validationContext(ctx context.Context) context.Context {
 	if r := p.RegimeDef(); r != nil {
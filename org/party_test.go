@@ -153,3 +153,45 @@ func TestPartyValidation(t *testing.T) {
 		assert.ErrorContains(t, err, "identities: (0: (code: must be in a valid format.).).")
 	})
 }
+
+func TestPartyEndpoint(t *testing.T) {
+	t.Run("normalize and validate", func(t *testing.T) {
+		party := org.Party{
+			Name:     "Invopop",
+			Endpoint: " iso6523-actorid-upis::0192: 991825827 ",
+		}
+		party.Normalize(nil)
+		assert.Equal(t, "0192:991825827", party.Endpoint.String())
+		assert.NoError(t, party.Validate())
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		party := org.Party{
+			Name:     "Invopop",
+			Endpoint: "0192:991825828",
+		}
+		assert.ErrorContains(t, party.Validate(), "endpoint: must be a valid code for scheme 0192")
+	})
+	t.Run("unknown scheme", func(t *testing.T) {
+		party := org.Party{
+			Name:     "Invopop",
+			Endpoint: "1234:991825827",
+		}
+		assert.ErrorContains(t, party.Validate(), "endpoint: scheme '1234' not recognized")
+	})
+	t.Run("electronic address", func(t *testing.T) {
+		var party *org.Party
+		assert.Empty(t, party.ElectronicAddress())
+		party = &org.Party{
+			Inboxes: []*org.Inbox{
+				{Email: "billing@example.com"},
+				{Scheme: "0088", Code: "5790000435951"},
+				{Key: org.InboxKeyPeppol, Scheme: "0208", Code: "0316597904"},
+			},
+		}
+		assert.Equal(t, "0208:0316597904", party.ElectronicAddress().String())
+		party.Inboxes = party.Inboxes[:2]
+		assert.Equal(t, "0088:5790000435951", party.ElectronicAddress().String())
+		party.Endpoint = "0192:991825827"
+		assert.Equal(t, "0192:991825827", party.ElectronicAddress().String())
+	})
+}
@@ -9,7 +9,8 @@ import (
 // Parse unmarshals the provided data and uses the schema ID
 // to determine what type of object we're dealing with. As long as the
 // provided data contains a schema registered in GOBL, a new
-// object instance will be returned.
+// object instance will be returned. Any schema migrations registered for
+// the document will be applied before unmarshalling.
 func Parse(data []byte) (interface{}, error) {
 	id, err := schema.Extract(data)
 	if err != nil {
@@ -18,6 +19,15 @@ func Parse(data []byte) (interface{}, error) {
 	if id == schema.UnknownID {
 		return nil, ErrUnknownSchema
 	}
+	if schema.HasMigrations(id) {
+		data, _, err = schema.Migrate(data)
+		if err != nil {
+			return nil, ErrUnmarshal.WithCause(err)
+		}
+		if id, err = schema.Extract(data); err != nil {
+			return nil, ErrUnmarshal.WithCause(err)
+		}
+	}
 
 	obj := id.Interface()
 	if err := json.Unmarshal(data, obj); err != nil {
@@ -26,3 +36,20 @@ func Parse(data []byte) (interface{}, error) {
 
 	return obj, nil
 }
+
+// ParseAs parses the data like Parse, and ensures the resulting object is
+// of the requested type, for example:
+//
+//	env, err := gobl.ParseAs[*gobl.Envelope](data)
+func ParseAs[T any](data []byte) (T, error) {
+	var zero T
+	obj, err := Parse(data)
+	if err != nil {
+		return zero, err
+	}
+	out, ok := obj.(T)
+	if !ok {
+		return zero, ErrDocumentType.WithReason("expected %T, found %T", zero, obj)
+	}
+	return out, nil
+}
@@ -1,13 +1,32 @@
 package gobl_test
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/c14n"
+	"github.com/invopop/gobl/dsig"
 	"github.com/invopop/gobl/note"
+	"github.com/invopop/gobl/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func init() {
+	schema.RegisterMigration(&schema.Migration{
+		Key:  "legacy-message-body",
+		From: schema.GOBL.Add("test/legacy-message"),
+		To:   schema.GOBL.Add("note/message"),
+		Func: func(doc map[string]any) (bool, error) {
+			doc["content"] = doc["body"]
+			delete(doc, "body")
+			return true, nil
+		},
+	})
+}
+
 var parseExampleDoc = `{
 	"$schema": "https://gobl.org/draft-0/note/message",
 	"title": "Test Message",
@@ -70,3 +89,51 @@ func TestParse(t *testing.T) {
 		assert.ErrorContains(t, err, "unknown-schema")
 	})
 }
+
+func TestParseMigration(t *testing.T) {
+	legacy := `{"$schema":"https://gobl.org/draft-0/test/legacy-message","title":"Test","body":"Legacy content"}`
+
+	t.Run("document", func(t *testing.T) {
+		doc, err := gobl.Parse([]byte(legacy))
+		require.NoError(t, err)
+		n, ok := doc.(*note.Message)
+		require.True(t, ok)
+		assert.Equal(t, "Legacy content", n.Content)
+	})
+
+	t.Run("envelope", func(t *testing.T) {
+		cd, err := c14n.CanonicalJSON(bytes.NewReader([]byte(legacy)))
+		require.NoError(t, err)
+		dig := dsig.NewSHA256Digest(cd)
+		data := fmt.Sprintf(`{"$schema":"https://gobl.org/draft-0/envelope","head":{"uuid":"0190f4f5-0c3e-7000-8000-000000000000","dig":{"alg":"sha256","val":"%s"}},"doc":%s}`, dig.Value, legacy)
+
+		doc, err := gobl.Parse([]byte(data))
+		require.NoError(t, err)
+		env := doc.(*gobl.Envelope)
+		require.NotNil(t, env.Migration())
+		assert.Equal(t, []string{"legacy-message-body"}, env.Migration().Applied)
+		assert.Equal(t, "Legacy content", env.Extract().(*note.Message).Content)
+		assert.NoError(t, env.Validate(), "digest should match original data")
+
+		require.NoError(t, env.Calculate())
+		assert.NotEqual(t, dig.Value, env.Head.Digest.Value)
+		assert.NoError(t, env.Validate())
+	})
+}
+
+func TestParseAs(t *testing.T) {
+	msg, err := gobl.ParseAs[*note.Message]([]byte(parseExampleDoc))
+	require.NoError(t, err)
+	assert.Equal(t, "Test Message", msg.Title)
+
+	env, err := gobl.ParseAs[*gobl.Envelope]([]byte(parseExampleEnvelope))
+	require.NoError(t, err)
+	assert.NotNil(t, env.Head)
+
+	_, err = gobl.ParseAs[*gobl.Envelope]([]byte(parseExampleDoc))
+	assert.ErrorIs(t, err, gobl.ErrDocumentType)
+	assert.ErrorContains(t, err, "expected *gobl.Envelope, found *note.Message")
+
+	_, err = gobl.ParseAs[*note.Message]([]byte(`{}`))
+	assert.ErrorIs(t, err, gobl.ErrUnknownSchema)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/currency"
 	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/gobl/uuid"
 	"github.com/invopop/jsonschema"
@@ -42,6 +43,11 @@ type Advance struct {
 	Card *Card `json:"card,omitempty" jsonschema:"title=Card"`
 	// Details about how the payment was made by credit (bank) transfer.
 	CreditTransfer *CreditTransfer `json:"credit_transfer,omitempty" jsonschema:"title=Credit Transfer"`
+	// Reference to the payment document or receipt that evidences the advance.
+	Receipt *org.DocumentRef `json:"receipt,omitempty" jsonschema:"title=Receipt"`
+	// Reference to the advance invoice issued for the payment, including the
+	// taxes it already settled.
+	Invoice *org.DocumentRef `json:"invoice,omitempty" jsonschema:"title=Invoice"`
 	// Tax extensions required by tax regimes or addons.
 	Ext tax.Extensions `json:"ext,omitempty" jsonschema:"title=Extensions"`
 	// Additional details useful for the parties involved.
@@ -57,6 +63,10 @@ func (a *Advance) Normalize() {
 	a.Ref = cbc.NormalizeString(a.Ref)
 	a.Description = cbc.NormalizeString(a.Description)
 	a.Ext = tax.CleanExtensions(a.Ext)
+	a.Card.Normalize()
+	a.CreditTransfer.Normalize()
+	a.Receipt.Normalize(nil)
+	a.Invoice.Normalize(nil)
 }
 
 // Validate checks the advance looks okay
@@ -76,6 +86,8 @@ func (a *Advance) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&a.Currency),
 		validation.Field(&a.Card),
 		validation.Field(&a.CreditTransfer),
+		validation.Field(&a.Receipt),
+		validation.Field(&a.Invoice),
 		validation.Field(&a.Ext),
 		validation.Field(&a.Meta),
 	)
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/gobl/uuid"
@@ -81,6 +83,21 @@ func TestAdvanceValidate(t *testing.T) {
 		}
 		assert.NoError(t, a.Validate())
 	})
+	t.Run("with receipt", func(t *testing.T) {
+		a := &pay.Advance{
+			Description: "Deposit",
+			Amount:      num.MakeAmount(5000, 2),
+			Receipt: &org.DocumentRef{
+				Code:      " R-001 ",
+				IssueDate: cal.NewDate(2024, 3, 1),
+			},
+		}
+		a.Normalize()
+		assert.Equal(t, "R-001", a.Receipt.Code.String())
+		assert.NoError(t, a.Validate())
+		a.Receipt.Code = ""
+		assert.ErrorContains(t, a.Validate(), "receipt: (code: cannot be blank.)")
+	})
 	t.Run("invalid means key", func(t *testing.T) {
 		a := &pay.Advance{
 			Description: "Test advance",
@@ -102,6 +119,6 @@ func TestAdvanceJSONSchemaExtend(t *testing.T) {
 	a.JSONSchemaExtend(schema)
 	prop, ok := schema.Properties.Get("key")
 	require.True(t, ok)
-	assert.Len(t, prop.AnyOf, 15)
+	assert.Len(t, prop.AnyOf, 16)
 	assert.Equal(t, cbc.Key("any"), prop.AnyOf[0].Const)
 }
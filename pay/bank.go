@@ -0,0 +1,92 @@
+package pay
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ibanLengths contains the expected length of IBANs in each country, as
+// defined in the SWIFT IBAN registry.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16,
+	"BG": 22, "BH": 22, "BI": 27, "BR": 29, "BY": 28, "CH": 21, "CR": 22,
+	"CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28, "EE": 20,
+	"EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22,
+	"GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30,
+	"KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21,
+	"LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27,
+	"MT": 31, "MU": 30, "NI": 28, "NL": 18, "NO": 15, "OM": 23, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27,
+	"SO": 23, "ST": 25, "SV": 28, "TL": 23, "TN": 24, "TR": 26, "UA": 29,
+	"VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+var (
+	ibanRegexp = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicRegexp  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// NormalizeIBAN removes any spaces and converts the IBAN to upper case,
+// providing the electronic format.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(removeSpaces(iban))
+}
+
+// NormalizeBIC removes any spaces and converts the BIC to upper case.
+func NormalizeBIC(bic string) string {
+	return strings.ToUpper(removeSpaces(bic))
+}
+
+func removeSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// CheckIBAN ensures the IBAN has the correct structure and length for the
+// country, and that the check digits are correct. Spaces and lower case
+// letters are accepted so that documents issued before normalization was
+// introduced remain valid.
+func CheckIBAN(iban string) error {
+	iban = NormalizeIBAN(iban)
+	if !ibanRegexp.MatchString(iban) {
+		return errors.New("invalid format")
+	}
+	l, ok := ibanLengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("unknown country '%s'", iban[:2])
+	}
+	if len(iban) != l {
+		return fmt.Errorf("invalid length for '%s', expected %d", iban[:2], l)
+	}
+	// Move the first four characters to the end and calculate the mod 97
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// mod97 calculates the ISO 7064 mod 97 of the alphanumeric code, converting
+// letters to numbers (A=10, B=11, ...).
+func mod97(code string) int {
+	r := 0
+	for _, c := range code {
+		if c >= 'A' {
+			r = (r*100 + int(c-'A'+10)) % 97
+		} else {
+			r = (r*10 + int(c-'0')) % 97
+		}
+	}
+	return r
+}
+
+// CheckBIC ensures the BIC (ISO 9362) has the correct structure.
+func CheckBIC(bic string) error {
+	bic = NormalizeBIC(bic)
+	if !bicRegexp.MatchString(bic) {
+		return errors.New("invalid format")
+	}
+	return nil
+}
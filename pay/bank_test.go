@@ -0,0 +1,70 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIBAN(t *testing.T) {
+	assert.Equal(t, "ES0601280011390100081391", pay.NormalizeIBAN(" es06 0128 0011 3901 0008 1391\t"))
+	assert.Equal(t, "", pay.NormalizeIBAN(""))
+}
+
+func TestCheckIBAN(t *testing.T) {
+	valid := []string{
+		"ES0601280011390100081391",
+		"DE89370400440532013000",
+		"IT60X0542811101000000123456",
+		"GB82WEST12345698765432",
+		"NO9386011117947",
+		"es06 0128 0011 3901 0008 1391",
+	}
+	for _, v := range valid {
+		assert.NoError(t, pay.CheckIBAN(v), v)
+	}
+	tests := []struct {
+		iban string
+		err  string
+	}{
+		{"ES06-0128-0011-3901-0008-1391", "invalid format"},
+		{"ES0701280011390100081391", "checksum mismatch"},
+		{"DE8937040044053201300", "invalid length for 'DE', expected 22"},
+		{"ZZ0601280011390100081391", "unknown country 'ZZ'"},
+		{"12", "invalid format"},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, pay.CheckIBAN(tt.iban), tt.err, tt.iban)
+	}
+}
+
+func TestCheckBIC(t *testing.T) {
+	assert.NoError(t, pay.CheckBIC("DEUTDEFF"))
+	assert.NoError(t, pay.CheckBIC("BKBKESMMXXX"))
+	assert.ErrorContains(t, pay.CheckBIC("DEUTDEF"), "invalid format")
+	assert.ErrorContains(t, pay.CheckBIC("DEUT1EFF"), "invalid format")
+	assert.NoError(t, pay.CheckBIC("deut deff"))
+}
+
+func TestCreditTransferValidation(t *testing.T) {
+	ct := &pay.CreditTransfer{
+		IBAN: "es06 0128 0011 3901 0008 1391",
+		BIC:  "bkbk esmm xxx",
+		Name: " Bankinter ",
+	}
+	ct.Normalize()
+	assert.Equal(t, "ES0601280011390100081391", ct.IBAN)
+	assert.Equal(t, "BKBKESMMXXX", ct.BIC)
+	assert.Equal(t, "Bankinter", ct.Name)
+	assert.NoError(t, ct.Validate())
+
+	ct.IBAN = "ES0701280011390100081391"
+	assert.ErrorContains(t, ct.Validate(), "iban: checksum mismatch")
+
+	ct = &pay.CreditTransfer{Number: "1234", BIC: "X"}
+	assert.ErrorContains(t, ct.Validate(), "bic: invalid format")
+
+	ct = nil
+	assert.NotPanics(t, func() { ct.Normalize() })
+}
@@ -0,0 +1,155 @@
+package pay
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/jsonschema"
+	"github.com/invopop/validation"
+)
+
+// Card networks, also known as card schemes, that may be used to identify
+// the type of card used for a payment.
+const (
+	CardNetworkVisa            cbc.Key = "visa"
+	CardNetworkMastercard      cbc.Key = "mastercard"
+	CardNetworkMaestro         cbc.Key = "maestro"
+	CardNetworkAmex            cbc.Key = "amex"
+	CardNetworkDiscover        cbc.Key = "discover"
+	CardNetworkDiners          cbc.Key = "diners"
+	CardNetworkJCB             cbc.Key = "jcb"
+	CardNetworkUnionPay        cbc.Key = "unionpay"
+	CardNetworkCartesBancaires cbc.Key = "cartes-bancaires"
+	CardNetworkInterac         cbc.Key = "interac"
+	CardNetworkEftpos          cbc.Key = "eftpos"
+	CardNetworkRuPay           cbc.Key = "rupay"
+	CardNetworkElo             cbc.Key = "elo"
+	CardNetworkMir             cbc.Key = "mir"
+)
+
+// CardNetworkDefinitions contains the list of card networks accepted by
+// GOBL.
+var CardNetworkDefinitions = []*cbc.Definition{
+	{Key: CardNetworkVisa, Name: i18n.NewString("Visa")},
+	{Key: CardNetworkMastercard, Name: i18n.NewString("Mastercard")},
+	{Key: CardNetworkMaestro, Name: i18n.NewString("Maestro")},
+	{Key: CardNetworkAmex, Name: i18n.NewString("American Express")},
+	{Key: CardNetworkDiscover, Name: i18n.NewString("Discover")},
+	{Key: CardNetworkDiners, Name: i18n.NewString("Diners Club")},
+	{Key: CardNetworkJCB, Name: i18n.NewString("JCB")},
+	{Key: CardNetworkUnionPay, Name: i18n.NewString("UnionPay")},
+	{Key: CardNetworkCartesBancaires, Name: i18n.NewString("Cartes Bancaires")},
+	{Key: CardNetworkInterac, Name: i18n.NewString("Interac")},
+	{Key: CardNetworkEftpos, Name: i18n.NewString("eftpos")},
+	{Key: CardNetworkRuPay, Name: i18n.NewString("RuPay")},
+	{Key: CardNetworkElo, Name: i18n.NewString("Elo")},
+	{Key: CardNetworkMir, Name: i18n.NewString("Mir")},
+}
+
+// Card contains simplified card holder data as a reference for the customer.
+// PCI compliance requires only the first 6 and last 4 digits of the card number
+// to be stored openly, so validation will reject any field that appears to
+// contain a full card number.
+type Card struct {
+	// First 6 digits of the card's Primary Account Number (PAN).
+	First6 string `json:"first6,omitempty" jsonschema:"title=First 6"`
+	// Last 4 digits of the card's Primary Account Number (PAN).
+	Last4 string `json:"last4,omitempty" jsonschema:"title=Last 4"`
+	// Name of the person whom the card belongs to.
+	Holder string `json:"holder,omitempty" jsonschema:"title=Holder Name"`
+	// Card network or scheme used to process the payment.
+	Network cbc.Key `json:"network,omitempty" jsonschema:"title=Network"`
+	// Token issued by the payment processor to reference the card.
+	Token string `json:"token,omitempty" jsonschema:"title=Token"`
+	// Expiry month and year of the card in the `MM/YY` format.
+	Expiry string `json:"expiry,omitempty" jsonschema:"title=Expiry,pattern=^(0[1-9]|1[0-2])/[0-9]{2}$"`
+	// Authorization code provided by the card issuer.
+	AuthCode string `json:"auth_code,omitempty" jsonschema:"title=Authorization Code"`
+	// Reference assigned to the transaction by the payment processor.
+	Ref string `json:"ref,omitempty" jsonschema:"title=Reference"`
+}
+
+var (
+	cardFirst6Regexp = regexp.MustCompile(`^[0-9]{6}$`)
+	cardLast4Regexp  = regexp.MustCompile(`^[0-9]{4}$`)
+	cardExpiryRegexp = regexp.MustCompile(`^(0[1-9]|1[0-2])/[0-9]{2}$`)
+	cardPANRegexp    = regexp.MustCompile(`[0-9][0-9 \-]{11,}[0-9]`)
+)
+
+// Normalize cleans up the card's details.
+func (c *Card) Normalize() {
+	if c == nil {
+		return
+	}
+	c.First6 = cbc.NormalizeString(c.First6)
+	c.Last4 = cbc.NormalizeString(c.Last4)
+	c.Holder = cbc.NormalizeString(c.Holder)
+	c.Token = cbc.NormalizeString(c.Token)
+	c.Expiry = cbc.NormalizeString(c.Expiry)
+	c.AuthCode = cbc.NormalizeString(c.AuthCode)
+	c.Ref = cbc.NormalizeString(c.Ref)
+}
+
+// Validate ensures the card details are valid and do not include a full
+// card number.
+func (c *Card) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.First6, validation.Match(cardFirst6Regexp)),
+		validation.Field(&c.Last4, validation.Match(cardLast4Regexp)),
+		validation.Field(&c.Holder, noCardNumber),
+		validation.Field(&c.Network, cbc.InKeyDefs(CardNetworkDefinitions)),
+		validation.Field(&c.Token, noCardNumber),
+		validation.Field(&c.Expiry, validation.Match(cardExpiryRegexp)),
+		validation.Field(&c.AuthCode, noCardNumber),
+		validation.Field(&c.Ref, noCardNumber),
+	)
+}
+
+// JSONSchemaExtend adds the list of card networks to the schema.
+func (Card) JSONSchemaExtend(schema *jsonschema.Schema) {
+	prop, ok := schema.Properties.Get("network")
+	if ok {
+		prop.OneOf = make([]*jsonschema.Schema, len(CardNetworkDefinitions))
+		for i, v := range CardNetworkDefinitions {
+			prop.OneOf[i] = &jsonschema.Schema{
+				Const: v.Key,
+				Title: v.Name.String(),
+			}
+		}
+	}
+}
+
+var noCardNumber = validation.By(checkNoCardNumber)
+
+// checkNoCardNumber looks for sequences of digits that could be a Primary
+// Account Number (PAN), using the Luhn algorithm to avoid false positives.
+func checkNoCardNumber(value any) error {
+	s, _ := value.(string)
+	for _, m := range cardPANRegexp.FindAllString(s, -1) {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(m)
+		if len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits) {
+			return errors.New("must not contain a card number")
+		}
+	}
+	return nil
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,70 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardValidation(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		c := &pay.Card{}
+		assert.NoError(t, c.Validate())
+	})
+	t.Run("complete", func(t *testing.T) {
+		c := &pay.Card{
+			First6:   "424242",
+			Last4:    " 4242 ",
+			Holder:   "Jane Doe",
+			Network:  pay.CardNetworkVisa,
+			Token:    "tok_1N3T00LkdIwHu7ixt44h1F8k",
+			Expiry:   "09/28",
+			AuthCode: "A1B2C3",
+			Ref:      "TXN-00001234",
+		}
+		c.Normalize()
+		assert.Equal(t, "4242", c.Last4)
+		assert.NoError(t, c.Validate())
+	})
+	t.Run("invalid formats", func(t *testing.T) {
+		c := &pay.Card{
+			First6:  "42424",
+			Last4:   "42a2",
+			Network: "unknown",
+			Expiry:  "13/28",
+		}
+		err := c.Validate()
+		assert.ErrorContains(t, err, "first6: must be in a valid format")
+		assert.ErrorContains(t, err, "last4: must be in a valid format")
+		assert.ErrorContains(t, err, "network: must be a valid value")
+		assert.ErrorContains(t, err, "expiry: must be in a valid format")
+	})
+	t.Run("rejects card numbers", func(t *testing.T) {
+		c := &pay.Card{
+			Token:    "4242424242424242",
+			Holder:   "Card 4000 0566 5566 5556",
+			AuthCode: "5555-5555-5555-4444",
+			Ref:      "Order 1234567890123456", // not a valid Luhn number
+		}
+		err := c.Validate()
+		assert.ErrorContains(t, err, "token: must not contain a card number")
+		assert.ErrorContains(t, err, "holder: must not contain a card number")
+		assert.ErrorContains(t, err, "auth_code: must not contain a card number")
+		assert.NotContains(t, err.Error(), "ref:")
+	})
+	t.Run("nil", func(t *testing.T) {
+		var c *pay.Card
+		assert.NotPanics(t, func() { c.Normalize() })
+	})
+}
+
+func TestInstructionsCard(t *testing.T) {
+	i := &pay.Instructions{
+		Key: pay.MeansKeyCard,
+		Card: &pay.Card{
+			Token: "4242424242424242",
+		},
+	}
+	assert.ErrorContains(t, i.Validate(), "card: (token: must not contain a card number.)")
+}
@@ -0,0 +1,346 @@
+package pay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/jsonschema"
+	"github.com/invopop/validation"
+	"golang.org/x/crypto/sha3"
+)
+
+// Blockchain networks that may be used to settle crypto payments.
+const (
+	CryptoChainBitcoin   cbc.Key = "bitcoin"
+	CryptoChainEthereum  cbc.Key = "ethereum"
+	CryptoChainPolygon   cbc.Key = "polygon"
+	CryptoChainArbitrum  cbc.Key = "arbitrum"
+	CryptoChainOptimism  cbc.Key = "optimism"
+	CryptoChainBase      cbc.Key = "base"
+	CryptoChainBSC       cbc.Key = "bsc"
+	CryptoChainAvalanche cbc.Key = "avalanche"
+	CryptoChainSolana    cbc.Key = "solana"
+	CryptoChainTron      cbc.Key = "tron"
+	CryptoChainStellar   cbc.Key = "stellar"
+)
+
+// CryptoChainDefinitions contains the list of blockchain networks accepted
+// by GOBL for crypto settlement.
+var CryptoChainDefinitions = []*cbc.Definition{
+	{Key: CryptoChainBitcoin, Name: i18n.NewString("Bitcoin")},
+	{Key: CryptoChainEthereum, Name: i18n.NewString("Ethereum")},
+	{Key: CryptoChainPolygon, Name: i18n.NewString("Polygon PoS")},
+	{Key: CryptoChainArbitrum, Name: i18n.NewString("Arbitrum One")},
+	{Key: CryptoChainOptimism, Name: i18n.NewString("OP Mainnet")},
+	{Key: CryptoChainBase, Name: i18n.NewString("Base")},
+	{Key: CryptoChainBSC, Name: i18n.NewString("BNB Smart Chain")},
+	{Key: CryptoChainAvalanche, Name: i18n.NewString("Avalanche C-Chain")},
+	{Key: CryptoChainSolana, Name: i18n.NewString("Solana")},
+	{Key: CryptoChainTron, Name: i18n.NewString("Tron")},
+	{Key: CryptoChainStellar, Name: i18n.NewString("Stellar")},
+}
+
+// Crypto contains the details required to settle a payment by transferring
+// a crypto asset, such as a stablecoin, to a wallet address on a specific
+// blockchain network.
+type Crypto struct {
+	// Blockchain network on which the transfer should be made.
+	Chain cbc.Key `json:"chain" jsonschema:"title=Chain"`
+	// Code of the asset to transfer, like `USDC` or `BTC`.
+	Asset cbc.Code `json:"asset" jsonschema:"title=Asset"`
+	// Wallet address of the receiver.
+	Address string `json:"address" jsonschema:"title=Address"`
+	// Memo or destination tag to include with the transfer, required by
+	// some exchanges to identify the receiver.
+	Memo string `json:"memo,omitempty" jsonschema:"title=Memo"`
+}
+
+// Normalize cleans up the crypto settlement details.
+func (c *Crypto) Normalize() {
+	if c == nil {
+		return
+	}
+	c.Asset = cbc.NormalizeAlphanumericalCode(c.Asset)
+	c.Address = strings.TrimSpace(c.Address)
+	c.Memo = cbc.NormalizeString(c.Memo)
+}
+
+// Validate ensures the crypto settlement details are complete and that the
+// address is valid for the chain.
+func (c *Crypto) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Chain,
+			validation.Required,
+			cbc.InKeyDefs(CryptoChainDefinitions),
+		),
+		validation.Field(&c.Asset, validation.Required),
+		validation.Field(&c.Address,
+			validation.Required,
+			validation.By(c.checkAddress),
+		),
+	)
+}
+
+func (c *Crypto) checkAddress(value any) error {
+	addr, _ := value.(string)
+	if addr == "" {
+		return nil
+	}
+	return CheckCryptoAddress(c.Chain, addr)
+}
+
+// CheckCryptoAddress ensures the address is valid for the chain, including
+// any checksums the chain's address format might define. Addresses for
+// unknown chains are not checked.
+func CheckCryptoAddress(chain cbc.Key, addr string) error {
+	switch chain {
+	case CryptoChainBitcoin:
+		return checkBitcoinAddress(addr)
+	case CryptoChainEthereum, CryptoChainPolygon, CryptoChainArbitrum,
+		CryptoChainOptimism, CryptoChainBase, CryptoChainBSC,
+		CryptoChainAvalanche:
+		return checkEVMAddress(addr)
+	case CryptoChainSolana:
+		return checkSolanaAddress(addr)
+	case CryptoChainTron:
+		return checkTronAddress(addr)
+	case CryptoChainStellar:
+		return checkStellarAddress(addr)
+	}
+	return nil
+}
+
+// checkEVMAddress validates Ethereum style addresses, including the EIP-55
+// mixed case checksum when upper and lower case letters are used.
+func checkEVMAddress(addr string) error {
+	if len(addr) != 42 || !strings.HasPrefix(addr, "0x") {
+		return errors.New("must be 0x followed by 40 hex characters")
+	}
+	body := addr[2:]
+	if _, err := hex.DecodeString(body); err != nil {
+		return errors.New("must be 0x followed by 40 hex characters")
+	}
+	if body == strings.ToLower(body) || body == strings.ToUpper(body) {
+		return nil
+	}
+	if body != eip55Checksum(body) {
+		return errors.New("invalid checksum")
+	}
+	return nil
+}
+
+func eip55Checksum(body string) string {
+	lower := strings.ToLower(body)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] = c - 32
+		}
+	}
+	return string(out)
+}
+
+func checkBitcoinAddress(addr string) error {
+	if strings.HasPrefix(strings.ToLower(addr), "bc1") {
+		return checkSegwitAddress("bc", addr)
+	}
+	payload, err := base58CheckDecode(addr)
+	if err != nil {
+		return err
+	}
+	if len(payload) != 21 || (payload[0] != 0x00 && payload[0] != 0x05) {
+		return errors.New("invalid address version")
+	}
+	return nil
+}
+
+func checkTronAddress(addr string) error {
+	payload, err := base58CheckDecode(addr)
+	if err != nil {
+		return err
+	}
+	if len(payload) != 21 || payload[0] != 0x41 {
+		return errors.New("invalid address version")
+	}
+	return nil
+}
+
+func checkSolanaAddress(addr string) error {
+	data, err := base58Decode(addr)
+	if err != nil {
+		return err
+	}
+	if len(data) != 32 {
+		return errors.New("must be a 32 byte public key")
+	}
+	return nil
+}
+
+// checkStellarAddress validates account IDs, which are base32 encoded
+// public keys prefixed with a version byte and suffixed with a CRC16
+// checksum.
+func checkStellarAddress(addr string) error {
+	data, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(addr)
+	if err != nil || len(data) != 35 {
+		return errors.New("must be a base32 account ID")
+	}
+	if data[0] != 6<<3 {
+		return errors.New("invalid address version")
+	}
+	if binary.LittleEndian.Uint16(data[33:]) != crc16XModem(data[:33]) {
+		return errors.New("invalid checksum")
+	}
+	return nil
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("invalid base58 encoding")
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return nil, errors.New("invalid base58 encoding")
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	out := n.Bytes()
+	// leading '1' characters represent zero bytes
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), out...), nil
+}
+
+func base58CheckDecode(s string) ([]byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 5 {
+		return nil, errors.New("invalid base58 encoding")
+	}
+	payload, sum := data[:len(data)-4], data[len(data)-4:]
+	h := sha256.Sum256(payload)
+	h = sha256.Sum256(h[:])
+	if !bytes.Equal(h[:4], sum) {
+		return nil, errors.New("invalid checksum")
+	}
+	return payload, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// checkSegwitAddress validates a bech32 (witness version 0) or bech32m
+// (witness version 1 and above) address as defined in BIP-173 and BIP-350.
+func checkSegwitAddress(hrp, addr string) error {
+	if addr != strings.ToLower(addr) && addr != strings.ToUpper(addr) {
+		return errors.New("must not use mixed case")
+	}
+	addr = strings.ToLower(addr)
+	pos := strings.LastIndexByte(addr, '1')
+	if pos < 1 || addr[:pos] != hrp || len(addr)-pos < 8 || len(addr) > 90 {
+		return errors.New("invalid segwit address")
+	}
+	data := make([]byte, 0, len(addr)-pos-1)
+	for _, c := range addr[pos+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return errors.New("invalid segwit address")
+		}
+		data = append(data, byte(i))
+	}
+	expected := uint32(bech32Const)
+	if data[0] > 0 {
+		expected = bech32mConst
+	}
+	if bech32Polymod(append(bech32ExpandHRP(hrp), data...)) != expected {
+		return errors.New("invalid checksum")
+	}
+	return nil
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// JSONSchemaExtend adds the list of chains to the schema.
+func (Crypto) JSONSchemaExtend(schema *jsonschema.Schema) {
+	prop, ok := schema.Properties.Get("chain")
+	if ok {
+		prop.OneOf = make([]*jsonschema.Schema, len(CryptoChainDefinitions))
+		for i, v := range CryptoChainDefinitions {
+			prop.OneOf[i] = &jsonschema.Schema{
+				Const: v.Key,
+				Title: v.Name.String(),
+			}
+		}
+	}
+}
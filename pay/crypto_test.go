@@ -0,0 +1,98 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCryptoAddress(t *testing.T) {
+	tests := []struct {
+		chain cbc.Key
+		addr  string
+		err   string
+	}{
+		{pay.CryptoChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ""},
+		{pay.CryptoChainPolygon, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ""},
+		{pay.CryptoChainBase, "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "invalid checksum"},
+		{pay.CryptoChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", "must be 0x followed by 40 hex characters"},
+		{pay.CryptoChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", "must be 0x followed by 40 hex characters"},
+		{pay.CryptoChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", ""},
+		{pay.CryptoChainBitcoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", ""},
+		{pay.CryptoChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "invalid checksum"},
+		{pay.CryptoChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", ""},
+		{pay.CryptoChainBitcoin, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", ""},
+		{pay.CryptoChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "invalid checksum"},
+		{pay.CryptoChainBitcoin, "bc1Qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "must not use mixed case"},
+		{pay.CryptoChainBitcoin, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", ""},
+		{pay.CryptoChainTron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", ""},
+		{pay.CryptoChainTron, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "invalid address version"},
+		{pay.CryptoChainSolana, "So11111111111111111111111111111111111111112", ""},
+		{pay.CryptoChainSolana, "So1111111111111111111111111111111111111111O", "invalid base58 encoding"},
+		{pay.CryptoChainStellar, "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7", ""},
+		{pay.CryptoChainStellar, "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN6", "invalid checksum"},
+		{"other", "anything", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.chain.String()+"/"+tt.addr, func(t *testing.T) {
+			err := pay.CheckCryptoAddress(tt.chain, tt.addr)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCryptoValidation(t *testing.T) {
+	c := &pay.Crypto{
+		Chain:   pay.CryptoChainEthereum,
+		Asset:   "usdc",
+		Address: " 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed ",
+		Memo:    " invoice 123 ",
+	}
+	instr := &pay.Instructions{
+		Key:    pay.MeansKeyCrypto,
+		Crypto: []*pay.Crypto{c},
+	}
+	instr.Normalize()
+	assert.Equal(t, cbc.Code("USDC"), c.Asset)
+	assert.Equal(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", c.Address)
+	assert.Equal(t, "invoice 123", c.Memo)
+	assert.NoError(t, instr.Validate())
+
+	c.Chain = "dogecoin"
+	assert.ErrorContains(t, c.Validate(), "chain: must be a valid value")
+
+	c = new(pay.Crypto)
+	err := c.Validate()
+	assert.ErrorContains(t, err, "address: cannot be blank")
+	assert.ErrorContains(t, err, "asset: cannot be blank")
+	assert.ErrorContains(t, err, "chain: cannot be blank")
+
+	c = &pay.Crypto{
+		Chain:   pay.CryptoChainBitcoin,
+		Asset:   "BTC",
+		Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb",
+	}
+	assert.ErrorContains(t, c.Validate(), "address: invalid checksum")
+}
+
+func TestCryptoJSONSchemaExtend(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Properties: jsonschema.NewProperties(),
+	}
+	schema.Properties.Set("chain", &jsonschema.Schema{
+		Type: "string",
+	})
+	pay.Crypto{}.JSONSchemaExtend(schema)
+	prop, ok := schema.Properties.Get("chain")
+	require.True(t, ok)
+	assert.Len(t, prop.OneOf, len(pay.CryptoChainDefinitions))
+	assert.Equal(t, pay.CryptoChainBitcoin, prop.OneOf[0].Const)
+}
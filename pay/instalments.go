@@ -0,0 +1,124 @@
+package pay
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/validation"
+)
+
+// InstalmentOptions defines how a set of instalments should be generated
+// from a total amount.
+type InstalmentOptions struct {
+	// Count is the number of instalments to generate.
+	Count int
+	// Start is the due date of the first instalment.
+	Start cal.Date
+	// Months between each instalment. If both Months and Days are zero,
+	// instalments will be generated monthly.
+	Months int
+	// Days between each instalment, added to any months.
+	Days int
+	// BusinessDays when true will move any due dates that fall on a
+	// weekend or holiday to the next business day.
+	BusinessDays bool
+	// Holidays to avoid when BusinessDays is enabled.
+	Holidays []cal.Date
+}
+
+// GenerateInstalments replaces the terms' due dates with the number of
+// instalments defined in the options, splitting the total so that the
+// sum of the instalments always matches exactly. Any remainder from the
+// division will be added to the last instalment. Dates are always
+// calculated from the start date so that month ends are respected.
+func (t *Terms) GenerateInstalments(total num.Amount, opts InstalmentOptions) error {
+	if t == nil {
+		return errors.New("missing terms")
+	}
+	if opts.Count < 1 {
+		return errors.New("instalment count must be at least 1")
+	}
+	if opts.Start.IsZero() {
+		return errors.New("instalment start date required")
+	}
+	months, days := opts.Months, opts.Days
+	if months == 0 && days == 0 {
+		months = 1
+	}
+	amount, last := total.Split(opts.Count)
+	t.DueDates = make([]*DueDate, opts.Count)
+	for i := range t.DueDates {
+		d := opts.Start.Add(0, months*i, days*i)
+		if opts.BusinessDays {
+			d = nextBusinessDay(d, opts.Holidays)
+		}
+		dd := &DueDate{
+			Date:   &d,
+			Amount: amount,
+		}
+		if i == opts.Count-1 {
+			dd.Amount = last
+		}
+		t.DueDates[i] = dd
+	}
+	return nil
+}
+
+func nextBusinessDay(d cal.Date, holidays []cal.Date) cal.Date {
+	for {
+		switch d.Time().Weekday() {
+		case time.Saturday:
+			d = d.Add(0, 0, 2)
+			continue
+		case time.Sunday:
+			d = d.Add(0, 0, 1)
+			continue
+		}
+		if !dateInList(d, holidays) {
+			return d
+		}
+		d = d.Add(0, 0, 1)
+	}
+}
+
+func dateInList(d cal.Date, list []cal.Date) bool {
+	for _, h := range list {
+		if h == d {
+			return true
+		}
+	}
+	return false
+}
+
+// DueDatesMatchTotal provides a validation rule for payment terms that
+// ensures the amounts of the due dates add up to the total provided. Due
+// dates in a currency other than the one provided cannot be compared, so
+// the check will be skipped if any are present.
+func DueDatesMatchTotal(total num.Amount, cur currency.Code) validation.Rule {
+	return validation.By(func(value any) error {
+		t, ok := value.(*Terms)
+		if !ok || t == nil || len(t.DueDates) == 0 {
+			return nil
+		}
+		sum := num.MakeAmount(0, total.Exp())
+		for _, dd := range t.DueDates {
+			if dd == nil {
+				continue
+			}
+			if dd.Currency != currency.CodeEmpty && dd.Currency != cur {
+				return nil
+			}
+			sum = sum.Add(dd.Amount)
+		}
+		if !sum.Equals(total) {
+			return validation.Errors{
+				"due_dates": fmt.Errorf("sum %s does not match total %s", sum, total),
+			}
+		}
+		return nil
+	})
+}
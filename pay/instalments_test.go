@@ -0,0 +1,86 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermsGenerateInstalments(t *testing.T) {
+	t.Run("monthly with remainder", func(t *testing.T) {
+		tm := new(pay.Terms)
+		err := tm.GenerateInstalments(num.MakeAmount(10000, 2), pay.InstalmentOptions{
+			Count: 3,
+			Start: cal.MakeDate(2024, 1, 31),
+		})
+		require.NoError(t, err)
+		require.Len(t, tm.DueDates, 3)
+		assert.Equal(t, "33.33", tm.DueDates[0].Amount.String())
+		assert.Equal(t, "33.33", tm.DueDates[1].Amount.String())
+		assert.Equal(t, "33.34", tm.DueDates[2].Amount.String())
+		assert.Equal(t, "2024-01-31", tm.DueDates[0].Date.String())
+		assert.Equal(t, "2024-03-02", tm.DueDates[1].Date.String())
+		assert.Equal(t, "2024-03-31", tm.DueDates[2].Date.String())
+		assert.NoError(t, tm.Validate())
+	})
+	t.Run("business days", func(t *testing.T) {
+		tm := new(pay.Terms)
+		err := tm.GenerateInstalments(num.MakeAmount(9000, 2), pay.InstalmentOptions{
+			Count:        3,
+			Start:        cal.MakeDate(2024, 6, 1), // Saturday
+			Days:         30,
+			BusinessDays: true,
+			Holidays:     []cal.Date{cal.MakeDate(2024, 7, 1)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-06-03", tm.DueDates[0].Date.String())
+		assert.Equal(t, "2024-07-02", tm.DueDates[1].Date.String())
+		assert.Equal(t, "2024-07-31", tm.DueDates[2].Date.String())
+		assert.Equal(t, "30.00", tm.DueDates[2].Amount.String())
+	})
+	t.Run("errors", func(t *testing.T) {
+		tm := new(pay.Terms)
+		err := tm.GenerateInstalments(num.MakeAmount(100, 2), pay.InstalmentOptions{
+			Start: cal.MakeDate(2024, 6, 1),
+		})
+		assert.ErrorContains(t, err, "instalment count must be at least 1")
+		err = tm.GenerateInstalments(num.MakeAmount(100, 2), pay.InstalmentOptions{Count: 2})
+		assert.ErrorContains(t, err, "instalment start date required")
+	})
+}
+
+func TestDueDatesMatchTotal(t *testing.T) {
+	tm := &pay.Terms{
+		DueDates: []*pay.DueDate{
+			{Date: cal.NewDate(2024, 1, 1), Amount: num.MakeAmount(4000, 2)},
+			{Date: cal.NewDate(2024, 2, 1), Amount: num.MakeAmount(6000, 2)},
+		},
+	}
+	rule := pay.DueDatesMatchTotal(num.MakeAmount(10000, 2), currency.EUR)
+	assert.NoError(t, rule.Validate(tm))
+
+	rule = pay.DueDatesMatchTotal(num.MakeAmount(12000, 2), currency.EUR)
+	assert.ErrorContains(t, rule.Validate(tm), "due_dates: sum 100.00 does not match total 120.00")
+
+	tm.DueDates[0].Currency = currency.USD
+	assert.NoError(t, rule.Validate(tm), "other currencies skipped")
+}
+
+func TestTermsCalculateDuesRemainder(t *testing.T) {
+	tm := &pay.Terms{
+		DueDates: []*pay.DueDate{
+			{Date: cal.NewDate(2024, 1, 1), Percent: num.NewPercentage(3333, 4)},
+			{Date: cal.NewDate(2024, 2, 1), Percent: num.NewPercentage(3333, 4)},
+			{Date: cal.NewDate(2024, 3, 1), Percent: num.NewPercentage(3334, 4)},
+		},
+	}
+	tm.CalculateDues(num.MakeAmount(0, 2), num.MakeAmount(10001, 2))
+	assert.Equal(t, "33.33", tm.DueDates[0].Amount.String())
+	assert.Equal(t, "33.33", tm.DueDates[1].Amount.String())
+	assert.Equal(t, "33.35", tm.DueDates[2].Amount.String())
+}
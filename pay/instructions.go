@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/org"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/jsonschema"
@@ -30,6 +33,8 @@ type Instructions struct {
 	DirectDebit *DirectDebit `json:"direct_debit,omitempty" jsonschema:"title=Direct Debit"`
 	// Array of online payment options
 	Online []*Online `json:"online,omitempty" jsonschema:"title=Online"`
+	// Wallet addresses that may be used to settle the payment with crypto assets.
+	Crypto []*Crypto `json:"crypto,omitempty" jsonschema:"title=Crypto"`
 	// Any additional instructions that may be required to make the payment.
 	Notes string `json:"notes,omitempty" jsonschema:"title=Notes"`
 	// Extension key-pairs values defined by a tax regime.
@@ -38,18 +43,6 @@ type Instructions struct {
 	Meta cbc.Meta `json:"meta,omitempty" jsonschema:"title=Meta"`
 }
 
-// Card contains simplified card holder data as a reference for the customer.
-// PCI compliance requires only the first 6 and last 4 digits of the card number
-// to be stored openly.
-type Card struct {
-	// First 6 digits of the card's Primary Account Number (PAN).
-	First6 string `json:"first6,omitempty" jsonschema:"title=First 6"`
-	// Last 4 digits of the card's Primary Account Number (PAN).
-	Last4 string `json:"last4,omitempty" jsonschema:"title=Last 4"`
-	// Name of the person whom the card belongs to.
-	Holder string `json:"holder,omitempty" jsonschema:"title=Holder Name"`
-}
-
 // DirectDebit defines the data that will be used to make the direct debit.
 type DirectDebit struct {
 	// Unique identifier assigned by the payee for referencing the direct debit.
@@ -58,6 +51,13 @@ type DirectDebit struct {
 	Creditor string `json:"creditor,omitempty" jsonschema:"title=Creditor ID"`
 	// Account identifier to be debited by the direct debit.
 	Account string `json:"account,omitempty" jsonschema:"title=Account"`
+	// SEPA direct debit scheme, either `CORE` or `B2B`. When provided, the
+	// mandate details will be validated according to the SEPA rules.
+	Scheme cbc.Code `json:"scheme,omitempty" jsonschema:"title=Scheme" jsonschema_extras:"enum=CORE,enum=B2B"`
+	// Date the mandate was signed by the debtor.
+	SignedOn *cal.Date `json:"signed_on,omitempty" jsonschema:"title=Signed On"`
+	// Sequence type of the collection: `FRST`, `RCUR`, `FNAL`, or `OOFF`.
+	Sequence cbc.Code `json:"sequence,omitempty" jsonschema:"title=Sequence Type" jsonschema_extras:"enum=FRST,enum=RCUR,enum=FNAL,enum=OOFF"`
 }
 
 // CreditTransfer contains fields that can be used for making payments via
@@ -76,13 +76,25 @@ type CreditTransfer struct {
 }
 
 // Online provides the details required to make a payment online using a website
+// or payment link, optionally bound to a specific amount.
 type Online struct {
 	// Key identifier for this online payment method.
 	Key cbc.Key `json:"key,omitempty" jsonschema:"title=Key"`
+	// Key of the payment service provider that issued the link, like `stripe`.
+	Provider cbc.Key `json:"provider,omitempty" jsonschema:"title=Provider"`
 	// Descriptive label for the online provider.
 	Label string `json:"label,omitempty" jsonschema:"title=Label"`
-	// URL to be used for payment.
-	URL string `json:"url" jsonschema:"title=URL"`
+	// URL to be used for payment, required unless a QR payload is provided.
+	URL string `json:"url,omitempty" jsonschema:"title=URL"`
+	// When the payment link will stop accepting payments.
+	Expires *cal.DateTime `json:"expires,omitempty" jsonschema:"title=Expires"`
+	// Amount that will be requested by the payment link.
+	Amount *num.Amount `json:"amount,omitempty" jsonschema:"title=Amount"`
+	// Currency of the amount, if different from the document's currency.
+	Currency currency.Code `json:"currency,omitempty" jsonschema:"title=Currency"`
+	// Payload to encode in a QR code that can be scanned to make the
+	// payment, like those defined by the EPC or Swiss QR-bill standards.
+	QR string `json:"qr,omitempty" jsonschema:"title=QR Payload"`
 }
 
 // Normalize will try to normalize the instructions.
@@ -94,14 +106,81 @@ func (i *Instructions) Normalize() {
 	i.Detail = cbc.NormalizeString(i.Detail)
 	i.Notes = cbc.NormalizeString(i.Notes)
 	i.Ext = tax.CleanExtensions(i.Ext)
+	for _, ct := range i.CreditTransfer {
+		ct.Normalize()
+	}
+	for _, o := range i.Online {
+		o.Normalize()
+	}
+	for _, c := range i.Crypto {
+		c.Normalize()
+	}
+	i.Card.Normalize()
+	i.DirectDebit.Normalize()
+}
+
+// Normalize removes spaces from the bank codes and converts them to upper
+// case.
+func (ct *CreditTransfer) Normalize() {
+	if ct == nil {
+		return
+	}
+	ct.IBAN = NormalizeIBAN(ct.IBAN)
+	ct.BIC = NormalizeBIC(ct.BIC)
+	ct.Number = cbc.NormalizeString(ct.Number)
+	ct.Name = cbc.NormalizeString(ct.Name)
+}
+
+// Validate ensures the credit transfer's bank codes are valid.
+func (ct *CreditTransfer) Validate() error {
+	return validation.ValidateStruct(ct,
+		validation.Field(&ct.IBAN, validation.By(checkIBAN)),
+		validation.Field(&ct.BIC, validation.By(checkBIC)),
+		validation.Field(&ct.Branch),
+	)
+}
+
+func checkIBAN(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return CheckIBAN(s)
+}
+
+func checkBIC(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return CheckBIC(s)
+}
+
+// Normalize cleans up the online payment details.
+func (u *Online) Normalize() {
+	if u == nil {
+		return
+	}
+	u.Label = cbc.NormalizeString(u.Label)
+	u.URL = cbc.NormalizeString(u.URL)
 }
 
 // Validate ensures the Online method details look correct.
 func (u *Online) Validate() error {
 	return validation.ValidateStruct(u,
 		validation.Field(&u.Key),
+		validation.Field(&u.Provider),
 		validation.Field(&u.Label),
-		validation.Field(&u.URL, validation.Required, is.URL),
+		validation.Field(&u.URL,
+			validation.When(
+				u.QR == "",
+				validation.Required,
+			),
+			is.URL,
+		),
+		validation.Field(&u.Expires),
+		validation.Field(&u.Amount, num.Positive),
+		validation.Field(&u.Currency),
 	)
 }
 
@@ -139,8 +218,10 @@ func (i *Instructions) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&i.Key, validation.Required, HasValidMeansKey),
 		validation.Field(&i.Ref),
 		validation.Field(&i.CreditTransfer),
+		validation.Field(&i.Card),
 		validation.Field(&i.DirectDebit),
 		validation.Field(&i.Online),
+		validation.Field(&i.Crypto),
 		validation.Field(&i.Ext),
 	)
 }
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
 	"github.com/invopop/gobl/pay"
 	"github.com/invopop/gobl/tax"
 	"github.com/stretchr/testify/assert"
@@ -51,3 +54,44 @@ func TestOnline(t *testing.T) {
 	assert.Equal(t, "Test", inst.Online[0].Label)
 	assert.Equal(t, "https://example.com", inst.Online[0].URL)
 }
+
+func TestOnlinePaymentLink(t *testing.T) {
+	amount := num.MakeAmount(12050, 2)
+	o := &pay.Online{
+		Key:      "card",
+		Provider: "stripe",
+		Label:    " Pay now ",
+		URL:      "https://pay.example.com/link/123",
+		Expires:  cal.NewDateTime(2025, 12, 31, 23, 59, 59),
+		Amount:   &amount,
+		Currency: currency.EUR,
+	}
+	instr := &pay.Instructions{
+		Key:    pay.MeansKeyOnline,
+		Online: []*pay.Online{o},
+	}
+	instr.Normalize()
+	assert.Equal(t, "Pay now", o.Label)
+	assert.NoError(t, instr.Validate())
+
+	t.Run("QR without URL", func(t *testing.T) {
+		o := &pay.Online{
+			Key: "epc",
+			QR:  "BCD\n002\n1\nSCT\n",
+		}
+		assert.NoError(t, o.Validate())
+		o.QR = ""
+		assert.ErrorContains(t, o.Validate(), "url: cannot be blank")
+	})
+	t.Run("invalid amount and currency", func(t *testing.T) {
+		neg := num.MakeAmount(-100, 2)
+		o := &pay.Online{
+			URL:      "https://pay.example.com/link/123",
+			Amount:   &neg,
+			Currency: "XYZ",
+		}
+		err := o.Validate()
+		assert.ErrorContains(t, err, "amount: must be greater than 0")
+		assert.ErrorContains(t, err, "currency: currency code XYZ not defined")
+	})
+}
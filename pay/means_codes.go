@@ -0,0 +1,190 @@
+package pay
+
+import (
+	"sync"
+
+	"github.com/invopop/gobl/cbc"
+)
+
+// Additional payment means keys used to extend the base keys so that
+// more specific means of payment can be represented.
+const (
+	MeansKeyACH            cbc.Key = "ach" // Automated Clearing House
+	MeansKeyBookEntry      cbc.Key = "book-entry"
+	MeansKeyClearing       cbc.Key = "clearing" // National or regional clearing
+	MeansKeyHold           cbc.Key = "hold"
+	MeansKeyCertified      cbc.Key = "certified"
+	MeansKeyLocal          cbc.Key = "local"
+	MeansKeyBillOfExchange cbc.Key = "bill-of-exchange"
+	MeansKeyHomeBanking    cbc.Key = "home-banking"
+	MeansKeyGiro           cbc.Key = "giro"
+	MeansKeyUrgent         cbc.Key = "urgent"
+	MeansKeyCredit         cbc.Key = "credit"
+	MeansKeyDebit          cbc.Key = "debit"
+	MeansKeyElectronic     cbc.Key = "electronic"
+)
+
+// MeansCode associates a payment means key with the code used to
+// represent it in an external code list.
+type MeansCode struct {
+	// Key of the payment means.
+	Key cbc.Key
+	// Code in the external list.
+	Code cbc.Code
+}
+
+// MeansCodes is an ordered list of payment means key and code pairs
+// used to translate between GOBL payment means keys and an external
+// code list. The same key may be associated with multiple codes, in
+// which case the first in the list is preferred.
+type MeansCodes []*MeansCode
+
+// Code provides the code associated with the payment means key. If there
+// is no exact match, the key's extensions will be removed one at a time
+// until a match is found, so that for example "credit-transfer+foo" will
+// use the same code as "credit-transfer".
+func (mc MeansCodes) Code(key cbc.Key) cbc.Code {
+	for k := key; k != cbc.KeyEmpty; k = k.Pop() {
+		for _, c := range mc {
+			if c.Key == k {
+				return c.Code
+			}
+		}
+	}
+	return cbc.CodeEmpty
+}
+
+// Key provides the payment means key associated with the code, or an
+// empty key if not found.
+func (mc MeansCodes) Key(code cbc.Code) cbc.Key {
+	for _, c := range mc {
+		if c.Code == code {
+			return c.Key
+		}
+	}
+	return cbc.KeyEmpty
+}
+
+var meansCodes = struct {
+	sync.RWMutex
+	lists map[cbc.Key]MeansCodes
+}{
+	lists: make(map[cbc.Key]MeansCodes),
+}
+
+// RegisterMeansCodes makes the list of payment means codes available
+// using the extension key that will hold the codes, replacing any
+// previous list. Addons and catalogues use this so that converters can
+// translate payment means without their own lookup tables.
+func RegisterMeansCodes(ext cbc.Key, list MeansCodes) {
+	meansCodes.Lock()
+	defer meansCodes.Unlock()
+	meansCodes.lists[ext] = list
+}
+
+// MeansCodesFor provides the list of payment means codes registered for
+// the extension key, or nil.
+func MeansCodesFor(ext cbc.Key) MeansCodes {
+	meansCodes.RLock()
+	defer meansCodes.RUnlock()
+	return meansCodes.lists[ext]
+}
+
+// MeansCode provides the code from the list registered with the extension
+// key that represents the instructions' payment means.
+func (i *Instructions) MeansCode(ext cbc.Key) cbc.Code {
+	if i == nil {
+		return cbc.CodeEmpty
+	}
+	return MeansCodesFor(ext).Code(i.Key)
+}
+
+// UNTDID4461 maps every code of the UNTDID 4461 payment means list,
+// as used by EN 16931 (BT-81), to a GOBL payment means key. Where
+// multiple codes share a key, the most generic code is listed first.
+var UNTDID4461 = MeansCodes{
+	{MeansKeyAny, "1"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "2"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "3"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "4"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "5"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "6"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "7"},
+	{MeansKeyOther.With(MeansKeyHold), "8"},
+	{MeansKeyCreditTransfer.With(MeansKeyClearing), "9"},
+	{MeansKeyCash, "10"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "11"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "12"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "13"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "14"},
+	{MeansKeyCreditTransfer.With(MeansKeyBookEntry), "15"},
+	{MeansKeyDebitTransfer.With(MeansKeyBookEntry), "16"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "17"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "18"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "19"},
+	{MeansKeyCheque, "20"},
+	{MeansKeyBankDraft, "21"},
+	{MeansKeyBankDraft.With(MeansKeyCertified), "22"},
+	{MeansKeyBankDraft, "23"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "24"},
+	{MeansKeyCheque.With(MeansKeyCertified), "25"},
+	{MeansKeyCheque.With(MeansKeyLocal), "26"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "27"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "28"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "29"},
+	{MeansKeyCreditTransfer, "30"},
+	{MeansKeyDebitTransfer, "31"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "32"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "33"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "34"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "35"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "36"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "37"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "38"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "39"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "40"},
+	{MeansKeyCreditTransfer.With(MeansKeyACH), "41"},
+	{MeansKeyCreditTransfer, "42"},
+	{MeansKeyDebitTransfer.With(MeansKeyACH), "43"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "44"},
+	{MeansKeyCreditTransfer.With(MeansKeyHomeBanking), "45"},
+	{MeansKeyDebitTransfer, "46"},
+	{MeansKeyDebitTransfer.With(MeansKeyHomeBanking), "47"},
+	{MeansKeyCard, "48"},
+	{MeansKeyDirectDebit, "49"},
+	{MeansKeyCreditTransfer.With(MeansKeyGiro), "50"},
+	{MeansKeyDebitTransfer, "51"},
+	{MeansKeyCreditTransfer.With(MeansKeyUrgent), "52"},
+	{MeansKeyCreditTransfer.With(MeansKeyUrgent), "53"},
+	{MeansKeyCard.With(MeansKeyCredit), "54"},
+	{MeansKeyCard.With(MeansKeyDebit), "55"},
+	{MeansKeyCreditTransfer.With(MeansKeyGiro), "56"},
+	{MeansKeyDirectDebit, "57"},
+	{MeansKeyCreditTransfer.With(MeansKeySEPA), "58"},
+	{MeansKeyDirectDebit.With(MeansKeySEPA), "59"},
+	{MeansKeyPromissoryNote, "60"},
+	{MeansKeyPromissoryNote, "61"},
+	{MeansKeyPromissoryNote, "62"},
+	{MeansKeyPromissoryNote, "63"},
+	{MeansKeyPromissoryNote, "64"},
+	{MeansKeyPromissoryNote, "65"},
+	{MeansKeyPromissoryNote, "66"},
+	{MeansKeyPromissoryNote, "67"},
+	{MeansKeyOnline, "68"},
+	{MeansKeyCreditTransfer, "69"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "70"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "74"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "75"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "76"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "77"},
+	{MeansKeyPromissoryNote.With(MeansKeyBillOfExchange), "78"},
+	{MeansKeyBankDraft, "91"},
+	{MeansKeyCheque.With(MeansKeyLocal), "92"},
+	{MeansKeyCreditTransfer.With(MeansKeyGiro), "93"},
+	{MeansKeyCreditTransfer.With(MeansKeyGiro), "94"},
+	{MeansKeyCreditTransfer.With(MeansKeyGiro), "95"},
+	{MeansKeyAny, "96"},
+	{MeansKeyNetting, "97"},
+	{MeansKeyPromissoryNote.With(MeansKeyElectronic), "98"},
+	{MeansKeyOther, "ZZZ"},
+}
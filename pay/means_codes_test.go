@@ -0,0 +1,60 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUNTDID4461(t *testing.T) {
+	tests := []struct {
+		key  cbc.Key
+		code cbc.Code
+	}{
+		{pay.MeansKeyAny, "1"},
+		{pay.MeansKeyCash, "10"},
+		{pay.MeansKeyCreditTransfer, "30"},
+		{pay.MeansKeyCreditTransfer.With(pay.MeansKeySEPA), "58"},
+		{pay.MeansKeyCreditTransfer.With("foo"), "30"},
+		{pay.MeansKeyCard.With(pay.MeansKeyDebit), "55"},
+		{pay.MeansKeyDirectDebit, "49"},
+		{pay.MeansKeyPromissoryNote, "60"},
+		{pay.MeansKeyOther, "ZZZ"},
+		{"unknown", cbc.CodeEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key.String(), func(t *testing.T) {
+			assert.Equal(t, tt.code, pay.UNTDID4461.Code(tt.key))
+		})
+	}
+
+	t.Run("reverse", func(t *testing.T) {
+		assert.Equal(t, pay.MeansKeyCreditTransfer, pay.UNTDID4461.Key("42"))
+		assert.Equal(t, pay.MeansKeyCreditTransfer.With(pay.MeansKeyACH), pay.UNTDID4461.Key("2"))
+		assert.Equal(t, cbc.KeyEmpty, pay.UNTDID4461.Key("71"))
+	})
+
+	t.Run("unique preferred codes", func(t *testing.T) {
+		for _, mc := range pay.UNTDID4461 {
+			key := pay.UNTDID4461.Key(pay.UNTDID4461.Code(mc.Key))
+			assert.Equal(t, mc.Key, key, "code %s", mc.Code)
+		}
+	})
+}
+
+func TestMeansCodesRegistry(t *testing.T) {
+	list := pay.MeansCodes{
+		{Key: pay.MeansKeyCash, Code: "C"},
+	}
+	pay.RegisterMeansCodes("test-means", list)
+	assert.Equal(t, list, pay.MeansCodesFor("test-means"))
+	assert.Nil(t, pay.MeansCodesFor("missing"))
+
+	instr := &pay.Instructions{Key: pay.MeansKeyCash}
+	assert.Equal(t, cbc.Code("C"), instr.MeansCode("test-means"))
+	assert.Equal(t, cbc.CodeEmpty, instr.MeansCode("missing"))
+	instr = nil
+	assert.Equal(t, cbc.CodeEmpty, instr.MeansCode("test-means"))
+}
@@ -21,6 +21,7 @@ const (
 	MeansKeyBankDraft      cbc.Key = "bank-draft"
 	MeansKeyDirectDebit    cbc.Key = "direct-debit" // aka. Mandate
 	MeansKeyOnline         cbc.Key = "online"       // Website from which payment can be made
+	MeansKeyCrypto         cbc.Key = "crypto"       // Transfer of crypto assets
 	MeansKeySEPA           cbc.Key = "sepa"         // extension for SEPA payments
 	MeansKeyOther          cbc.Key = "other"
 )
@@ -83,6 +84,11 @@ var MeansKeyDefinitions = []*cbc.Definition{
 		Name: i18n.NewString("Online"),
 		Desc: i18n.NewString("Online or web payment."),
 	},
+	{
+		Key:  MeansKeyCrypto,
+		Name: i18n.NewString("Crypto"),
+		Desc: i18n.NewString("Transfer of crypto assets to a wallet address."),
+	},
 	{
 		Key:  MeansKeyPromissoryNote,
 		Name: i18n.NewString("Promissory Note"),
@@ -0,0 +1,160 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/validation"
+)
+
+// EPC QR code constants defined by EPC069-12.
+const (
+	EPCServiceTag     = "BCD"
+	EPCVersion        = "002"
+	EPCCharacterSet   = "1" // UTF-8
+	EPCIdentification = "SCT"
+	EPCMaxLength      = 331
+)
+
+var epcMaxAmount = num.MakeAmount(99999999999, 2)
+
+// EPC contains the data encoded in an EPC QR code used to initiate a
+// SEPA Credit Transfer.
+type EPC struct {
+	// BIC of the beneficiary's bank, optional within the EEA.
+	BIC string
+	// Name of the beneficiary.
+	Name string
+	// IBAN of the beneficiary's account.
+	IBAN string
+	// Amount to transfer in euros, left empty for the payer to complete.
+	Amount *num.Amount
+	// Purpose code of the transfer, if any.
+	Purpose string
+	// Reference is the ISO 11649 structured creditor reference.
+	Reference string
+	// Text with unstructured remittance information, used when no
+	// reference is provided.
+	Text string
+	// Info for the payer, usually shown by the banking application.
+	Info string
+}
+
+// NewEPC prepares the EPC QR code data for the invoice, which must be
+// issued in euros. Payment references that are valid ISO 11649 creditor
+// references are included as structured references, and any others as
+// remittance text, falling back to the invoice's series and code.
+func NewEPC(inv *bill.Invoice) (*EPC, error) {
+	if inv.Currency != currency.EUR {
+		return nil, fmt.Errorf("%w: currency must be EUR", ErrUnsupported)
+	}
+	s, err := newSource(inv)
+	if err != nil {
+		return nil, err
+	}
+	e := &EPC{
+		BIC:  pay.NormalizeBIC(s.transfer.BIC),
+		Name: truncate(s.transfer.Name, 70),
+		IBAN: pay.NormalizeIBAN(s.transfer.IBAN),
+	}
+	if e.Name == "" {
+		e.Name = truncate(s.payee.Name, 70)
+	}
+	if s.amount.IsPositive() {
+		e.Amount = &s.amount
+	}
+	switch {
+	case pay.CheckCreditorReference(s.ref) == nil:
+		e.Reference = pay.NormalizeBIC(s.ref)
+	case s.ref != "":
+		e.Text = truncate(s.ref, 140)
+	default:
+		e.Text = truncate(s.notes, 140)
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Validate checks the data against the EPC069-12 rules.
+func (e *EPC) Validate() error {
+	err := validation.ValidateStruct(e,
+		validation.Field(&e.BIC, validation.By(checkBIC)),
+		validation.Field(&e.Name, validation.Required, validation.RuneLength(0, 70)),
+		validation.Field(&e.IBAN, validation.Required, validation.By(checkIBAN)),
+		validation.Field(&e.Amount,
+			num.Min(num.MakeAmount(1, 2)),
+			num.Max(epcMaxAmount),
+		),
+		validation.Field(&e.Purpose, validation.Length(4, 4)),
+		validation.Field(&e.Reference,
+			validation.By(checkCreditorReference),
+			validation.When(e.Text != "", validation.Empty.Error("must be blank with text")),
+		),
+		validation.Field(&e.Text, validation.RuneLength(0, 140)),
+		validation.Field(&e.Info, validation.RuneLength(0, 70)),
+	)
+	if err != nil {
+		return err
+	}
+	if len(e.String()) > EPCMaxLength {
+		return fmt.Errorf("payload exceeds %d bytes", EPCMaxLength)
+	}
+	return nil
+}
+
+// String provides the payload to encode in the QR code, omitting any
+// trailing empty elements.
+func (e *EPC) String() string {
+	amount := ""
+	if e.Amount != nil {
+		amount = string(currency.EUR) + e.Amount.Rescale(2).String()
+	}
+	lines := []string{
+		EPCServiceTag,
+		EPCVersion,
+		EPCCharacterSet,
+		EPCIdentification,
+		e.BIC,
+		e.Name,
+		e.IBAN,
+		amount,
+		e.Purpose,
+		e.Reference,
+		e.Text,
+		e.Info,
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkIBAN(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return pay.CheckIBAN(s)
+}
+
+func checkBIC(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return pay.CheckBIC(s)
+}
+
+func checkCreditorReference(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return pay.CheckCreditorReference(s)
+}
@@ -0,0 +1,69 @@
+package qr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/pay/qr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEPC(t *testing.T) {
+	t.Run("structured reference", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "de89 3704 0044 0532 0130 00", "RF18 5390 0754 7034")
+		inv.Payment.Instructions.CreditTransfer[0].BIC = "COBADEFFXXX"
+		e, err := qr.NewEPC(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "BCD\n002\n1\nSCT\nCOBADEFFXXX\nRobert Schneider AG\nDE89370400440532013000\nEUR199.50\n\nRF18539007547034", e.String())
+	})
+
+	t.Run("unstructured text", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Instructions.CreditTransfer[0].Name = "Schneider Konto"
+		e, err := qr.NewEPC(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "BCD\n002\n1\nSCT\n\nSchneider Konto\nDE89370400440532013000\nEUR199.50\n\n\nINV-001", e.String())
+
+		inv.Payment.Instructions.Ref = "ORDER 123"
+		e, err = qr.NewEPC(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "ORDER 123", e.Text)
+		assert.Empty(t, e.Reference)
+	})
+
+	t.Run("currency", func(t *testing.T) {
+		inv := testInvoice(t, "CH", currency.CHF, "CH9300762011623852957", "")
+		_, err := qr.NewEPC(inv)
+		assert.ErrorIs(t, err, qr.ErrUnsupported)
+	})
+
+	t.Run("invalid iban", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "DE89370400440532013001", "")
+		_, err := qr.NewEPC(inv)
+		assert.ErrorContains(t, err, "IBAN: checksum mismatch")
+	})
+}
+
+func TestEPCValidate(t *testing.T) {
+	e := &qr.EPC{
+		Name:      "Test",
+		IBAN:      "DE89370400440532013000",
+		Amount:    num.NewAmount(0, 2),
+		Reference: "RF18539007547034",
+		Text:      "Both",
+	}
+	err := e.Validate()
+	assert.ErrorContains(t, err, "Amount: must be no less than 0.01")
+	assert.ErrorContains(t, err, "Reference: must be blank with text")
+
+	e = &qr.EPC{
+		Name: strings.Repeat("n", 70),
+		IBAN: "DE89370400440532013000",
+		Text: strings.Repeat("x", 140),
+		Info: strings.Repeat("ü", 70),
+	}
+	assert.ErrorContains(t, e.Validate(), "payload exceeds 331 bytes")
+}
@@ -0,0 +1,88 @@
+// Package qr generates the payloads of payment QR codes from invoices,
+// so that renderers only need to draw the code.
+//
+// Two standards are supported: the European Payments Council's QR code
+// for SEPA Credit Transfers (EPC069-12), popularly known as the
+// "GiroCode", and the Swiss QR-bill used for payments in Switzerland and
+// Liechtenstein.
+//
+// Both take the beneficiary from the invoice's payee, or the supplier if
+// none is set, the amount from the total due or payable, and the account
+// and payment reference from the first credit transfer of the payment
+// instructions.
+package qr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+)
+
+// ErrUnsupported is returned when the invoice does not contain the
+// details required to generate the payload.
+var ErrUnsupported = errors.New("unsupported")
+
+// source contains the invoice details used in payment payloads.
+type source struct {
+	payee    *org.Party
+	transfer *pay.CreditTransfer
+	ref      string
+	amount   num.Amount
+	notes    string
+}
+
+func newSource(inv *bill.Invoice) (*source, error) {
+	if inv.Totals == nil {
+		return nil, fmt.Errorf("%w: invoice not calculated", ErrUnsupported)
+	}
+	pd := inv.Payment
+	if pd == nil || pd.Instructions == nil || len(pd.Instructions.CreditTransfer) == 0 {
+		return nil, fmt.Errorf("%w: credit transfer instructions required", ErrUnsupported)
+	}
+	s := &source{
+		payee:    inv.Supplier,
+		transfer: pd.Instructions.CreditTransfer[0],
+		ref:      pd.Instructions.Ref.String(),
+		amount:   inv.Totals.Payable,
+		notes:    invoiceCode(inv),
+	}
+	if pd.Payee != nil {
+		s.payee = pd.Payee
+	}
+	if s.payee == nil {
+		return nil, fmt.Errorf("%w: payee required", ErrUnsupported)
+	}
+	if inv.Totals.Due != nil {
+		s.amount = *inv.Totals.Due
+	}
+	s.amount = s.amount.Rescale(2)
+	return s, nil
+}
+
+func invoiceCode(inv *bill.Invoice) string {
+	if inv.Series == cbc.CodeEmpty {
+		return inv.Code.String()
+	}
+	return inv.Series.String() + "-" + inv.Code.String()
+}
+
+func (s *source) address() *org.Address {
+	if len(s.payee.Addresses) == 0 {
+		return nil
+	}
+	return s.payee.Addresses[0]
+}
+
+// truncate limits the text to the maximum number of characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,88 @@
+package qr_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/gobl/pay/qr"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(t *testing.T, country l10n.TaxCountryCode, cur currency.Code, iban string, ref cbc.Code) *bill.Invoice {
+	t.Helper()
+	inv := &bill.Invoice{
+		Series:    "INV",
+		Code:      "001",
+		Currency:  cur,
+		IssueDate: cal.MakeDate(2024, 6, 1),
+		Supplier: &org.Party{
+			Name:  "Robert Schneider AG",
+			TaxID: &tax.Identity{Country: country},
+			Addresses: []*org.Address{
+				{Street: "Rue du Lac", Number: "1268", Code: "2501", Locality: "Biel"},
+			},
+		},
+		Customer: &org.Party{
+			Name: "Pia-Maria Rutschmann-Schnyder",
+			Addresses: []*org.Address{
+				{Street: "Grosse Marktgasse", Number: "28", Code: "9400", Locality: "Rorschach", Country: "CH"},
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item:     &org.Item{Name: "Gartenarbeit", Price: num.NewAmount(19950, 2)},
+			},
+		},
+		Payment: &bill.PaymentDetails{
+			Instructions: &pay.Instructions{
+				Key: pay.MeansKeyCreditTransfer,
+				Ref: ref,
+				CreditTransfer: []*pay.CreditTransfer{
+					{IBAN: iban},
+				},
+			},
+		},
+	}
+	require.NoError(t, inv.Calculate())
+	return inv
+}
+
+func TestSource(t *testing.T) {
+	t.Run("not calculated", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "DE89370400440532013000", "")
+		inv.Totals = nil
+		_, err := qr.NewEPC(inv)
+		assert.ErrorIs(t, err, qr.ErrUnsupported)
+		assert.ErrorContains(t, err, "invoice not calculated")
+	})
+	t.Run("missing instructions", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Instructions.CreditTransfer = nil
+		_, err := qr.NewSwissQRBill(inv)
+		assert.ErrorIs(t, err, qr.ErrUnsupported)
+		assert.ErrorContains(t, err, "credit transfer instructions required")
+	})
+	t.Run("payee and due amount", func(t *testing.T) {
+		inv := testInvoice(t, "DE", currency.EUR, "DE89370400440532013000", "")
+		inv.Payment.Payee = &org.Party{Name: "Factoring GmbH"}
+		inv.Payment.Advances = []*pay.Advance{
+			{Description: "Deposit", Amount: num.MakeAmount(5000, 2)},
+		}
+		require.NoError(t, inv.Calculate())
+		e, err := qr.NewEPC(inv)
+		require.NoError(t, err)
+		assert.Equal(t, "Factoring GmbH", e.Name)
+		assert.Equal(t, "149.50", e.Amount.String())
+	})
+}
@@ -0,0 +1,291 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/pay"
+	"github.com/invopop/validation"
+)
+
+// Swiss QR-bill constants defined by the Swiss Implementation Guidelines
+// for the QR-bill, version 2.3.
+const (
+	SwissQRType        = "SPC"
+	SwissVersion       = "0200"
+	SwissCoding        = "1" // UTF-8, restricted to the Latin character set
+	SwissTrailer       = "EPD"
+	SwissAddressType   = "S" // structured
+	SwissMaxLength     = 997
+	swissQRRLength     = 27
+	swissAddressFields = 7
+)
+
+// Swiss QR-bill reference types.
+const (
+	SwissRefQR       cbc.Code = "QRR"
+	SwissRefCreditor cbc.Code = "SCOR"
+	SwissRefNone     cbc.Code = "NON"
+)
+
+var (
+	swissQRRRegexp = regexp.MustCompile(`^[0-9]{27}$`)
+	swissMaxAmount = num.MakeAmount(99999999999, 2)
+	swissMod10     = [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+)
+
+// SwissQRBill contains the data encoded in the Swiss QR code of a
+// QR-bill payment part.
+type SwissQRBill struct {
+	// Account is the IBAN or QR-IBAN of the creditor, which must be
+	// Swiss or from Liechtenstein.
+	Account string
+	// Creditor receiving the payment.
+	Creditor *SwissAddress
+	// Amount to pay, left empty for the debtor to complete.
+	Amount *num.Amount
+	// Currency of the payment, either CHF or EUR.
+	Currency currency.Code
+	// Debtor making the payment, if known.
+	Debtor *SwissAddress
+	// ReferenceType determines the format of the reference.
+	ReferenceType cbc.Code
+	// Reference provided to the creditor with the payment.
+	Reference string
+	// Message with unstructured information for the creditor.
+	Message string
+	// BillInformation with structured data for the debtor's software.
+	BillInformation string
+}
+
+// SwissAddress describes a party using a structured address.
+type SwissAddress struct {
+	Name     string
+	Street   string
+	Number   string
+	PostCode string
+	Town     string
+	Country  string
+}
+
+// NewSwissQRBill prepares the Swiss QR-bill data for the invoice, which
+// must be issued in francs or euros. When the account is a QR-IBAN, the
+// payment reference must be numeric and will be completed as a QR
+// reference with its check digit. Otherwise, valid ISO 11649 creditor
+// references are used as structured references, and any others as the
+// unstructured message, falling back to the invoice's series and code.
+func NewSwissQRBill(inv *bill.Invoice) (*SwissQRBill, error) {
+	if inv.Currency != currency.CHF && inv.Currency != currency.EUR {
+		return nil, fmt.Errorf("%w: currency must be CHF or EUR", ErrUnsupported)
+	}
+	s, err := newSource(inv)
+	if err != nil {
+		return nil, err
+	}
+	q := &SwissQRBill{
+		Account:       pay.NormalizeIBAN(s.transfer.IBAN),
+		Creditor:      newSwissAddress(s.payee, s.address()),
+		Currency:      inv.Currency,
+		ReferenceType: SwissRefNone,
+	}
+	if s.amount.IsPositive() {
+		q.Amount = &s.amount
+	}
+	if inv.Customer != nil && len(inv.Customer.Addresses) > 0 {
+		q.Debtor = newSwissAddress(inv.Customer, inv.Customer.Addresses[0])
+	}
+	switch {
+	case isQRIBAN(q.Account):
+		q.ReferenceType = SwissRefQR
+		q.Reference = SwissQRReference(s.ref)
+		q.Message = truncate(s.notes, 140)
+	case pay.CheckCreditorReference(s.ref) == nil:
+		q.ReferenceType = SwissRefCreditor
+		q.Reference = pay.NormalizeBIC(s.ref)
+		q.Message = truncate(s.notes, 140)
+	case s.ref != "":
+		q.Message = truncate(s.ref, 140)
+	default:
+		q.Message = truncate(s.notes, 140)
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func newSwissAddress(p *org.Party, a *org.Address) *SwissAddress {
+	sa := &SwissAddress{Name: truncate(p.Name, 70)}
+	if a == nil {
+		return sa
+	}
+	sa.Street = truncate(a.Street, 70)
+	sa.Number = truncate(a.Number, 16)
+	sa.PostCode = truncate(a.Code.String(), 16)
+	sa.Town = truncate(a.Locality, 35)
+	sa.Country = a.Country.String()
+	if sa.Country == "" && p.TaxID != nil {
+		sa.Country = p.TaxID.Country.String()
+	}
+	return sa
+}
+
+// SwissQRReference completes the numeric reference with leading zeros
+// and the modulo 10 recursive check digit to form a 27 digit QR
+// reference. References that are not numeric or are too long are
+// returned as they are, to be reported when validating.
+func SwissQRReference(ref string) string {
+	ref = strings.ReplaceAll(ref, " ", "")
+	if ref == "" || len(ref) >= swissQRRLength || !isDigits(ref) {
+		return ref
+	}
+	ref = strings.Repeat("0", swissQRRLength-1-len(ref)) + ref
+	return ref + strconv.Itoa(swissCheckDigit(ref))
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func swissCheckDigit(digits string) int {
+	carry := 0
+	for _, c := range digits {
+		carry = swissMod10[(carry+int(c-'0'))%10]
+	}
+	return (10 - carry) % 10
+}
+
+// isQRIBAN checks if the IBAN's institution identifier is in the range
+// reserved for QR-IBANs.
+func isQRIBAN(iban string) bool {
+	if len(iban) < 9 {
+		return false
+	}
+	iid, err := strconv.Atoi(iban[4:9])
+	return err == nil && iid >= 30000 && iid <= 31999
+}
+
+// Validate checks the data against the Swiss QR-bill rules.
+func (q *SwissQRBill) Validate() error {
+	qrIBAN := isQRIBAN(q.Account)
+	err := validation.ValidateStruct(q,
+		validation.Field(&q.Account,
+			validation.Required,
+			validation.By(checkSwissIBAN),
+		),
+		validation.Field(&q.Creditor, validation.Required),
+		validation.Field(&q.Amount,
+			num.Min(num.MakeAmount(1, 2)),
+			num.Max(swissMaxAmount),
+		),
+		validation.Field(&q.Currency,
+			validation.Required,
+			validation.In(currency.CHF, currency.EUR),
+		),
+		validation.Field(&q.Debtor),
+		validation.Field(&q.ReferenceType,
+			validation.Required,
+			validation.When(qrIBAN,
+				validation.In(SwissRefQR).Error("must be QRR with a QR-IBAN"),
+			).Else(
+				validation.In(SwissRefCreditor, SwissRefNone),
+			),
+		),
+		validation.Field(&q.Reference,
+			validation.When(q.ReferenceType == SwissRefQR,
+				validation.Required,
+				validation.By(checkSwissQRReference),
+			),
+			validation.When(q.ReferenceType == SwissRefCreditor,
+				validation.Required,
+				validation.By(checkCreditorReference),
+			),
+			validation.When(q.ReferenceType == SwissRefNone, validation.Empty),
+		),
+		validation.Field(&q.Message, validation.RuneLength(0, 140)),
+		validation.Field(&q.BillInformation, validation.RuneLength(0, 140)),
+	)
+	if err != nil {
+		return err
+	}
+	if n := len([]rune(q.String())); n > SwissMaxLength {
+		return fmt.Errorf("payload exceeds %d characters", SwissMaxLength)
+	}
+	return nil
+}
+
+// Validate checks the structured address.
+func (a *SwissAddress) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.Name, validation.Required, validation.RuneLength(0, 70)),
+		validation.Field(&a.Street, validation.RuneLength(0, 70)),
+		validation.Field(&a.Number, validation.RuneLength(0, 16)),
+		validation.Field(&a.PostCode, validation.Required, validation.RuneLength(0, 16)),
+		validation.Field(&a.Town, validation.Required, validation.RuneLength(0, 35)),
+		validation.Field(&a.Country, validation.Required, validation.Length(2, 2)),
+	)
+}
+
+// String provides the payload to encode in the QR code, with elements
+// separated by line feeds.
+func (q *SwissQRBill) String() string {
+	amount := ""
+	if q.Amount != nil {
+		amount = q.Amount.Rescale(2).String()
+	}
+	lines := []string{SwissQRType, SwissVersion, SwissCoding, q.Account}
+	lines = append(lines, q.Creditor.lines()...)
+	lines = append(lines, make([]string, swissAddressFields)...) // ultimate creditor
+	lines = append(lines, amount, q.Currency.String())
+	lines = append(lines, q.Debtor.lines()...)
+	lines = append(lines, q.ReferenceType.String(), q.Reference, q.Message, SwissTrailer)
+	if q.BillInformation != "" {
+		lines = append(lines, q.BillInformation)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (a *SwissAddress) lines() []string {
+	if a == nil {
+		return make([]string, swissAddressFields)
+	}
+	return []string{SwissAddressType, a.Name, a.Street, a.Number, a.PostCode, a.Town, a.Country}
+}
+
+func checkSwissIBAN(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if err := pay.CheckIBAN(s); err != nil {
+		return err
+	}
+	if c := s[:2]; c != "CH" && c != "LI" {
+		return errors.New("must be a Swiss or Liechtenstein account")
+	}
+	return nil
+}
+
+func checkSwissQRReference(value any) error {
+	s, _ := value.(string)
+	if !swissQRRRegexp.MatchString(s) {
+		return errors.New("invalid format")
+	}
+	if swissCheckDigit(s[:swissQRRLength-1]) != int(s[swissQRRLength-1]-'0') {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
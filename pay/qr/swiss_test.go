@@ -0,0 +1,90 @@
+package qr_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/pay/qr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwissQRReference(t *testing.T) {
+	assert.Equal(t, "210000000003139471430009017", qr.SwissQRReference("21000000000313947143000901"))
+	assert.Equal(t, "000000000000000000000012347", qr.SwissQRReference("1234"))
+	assert.Equal(t, "ABC", qr.SwissQRReference("ABC"))
+	assert.Empty(t, qr.SwissQRReference(""))
+}
+
+func TestNewSwissQRBill(t *testing.T) {
+	t.Run("QR reference", func(t *testing.T) {
+		inv := testInvoice(t, "CH", currency.CHF, "CH44 3199 9123 0008 8901 2", "21000000000313947143000901")
+		q, err := qr.NewSwissQRBill(inv)
+		require.NoError(t, err)
+		assert.Equal(t, qr.SwissRefQR, q.ReferenceType)
+		assert.Equal(t, "210000000003139471430009017", q.Reference)
+		assert.Equal(t, "CH", q.Creditor.Country)
+		assert.Equal(t, ""+
+			"SPC\n0200\n1\nCH4431999123000889012\n"+
+			"S\nRobert Schneider AG\nRue du Lac\n1268\n2501\nBiel\nCH\n"+
+			"\n\n\n\n\n\n\n"+
+			"199.50\nCHF\n"+
+			"S\nPia-Maria Rutschmann-Schnyder\nGrosse Marktgasse\n28\n9400\nRorschach\nCH\n"+
+			"QRR\n210000000003139471430009017\nINV-001\nEPD",
+			q.String(),
+		)
+	})
+
+	t.Run("creditor reference", func(t *testing.T) {
+		inv := testInvoice(t, "CH", currency.EUR, "CH9300762011623852957", "RF18539007547034")
+		inv.Customer = nil
+		q, err := qr.NewSwissQRBill(inv)
+		require.NoError(t, err)
+		assert.Equal(t, qr.SwissRefCreditor, q.ReferenceType)
+		assert.Nil(t, q.Debtor)
+		assert.Contains(t, q.String(), "199.50\nEUR\n\n\n\n\n\n\n\nSCOR\nRF18539007547034\nINV-001\nEPD")
+	})
+
+	t.Run("without reference", func(t *testing.T) {
+		inv := testInvoice(t, "CH", currency.CHF, "CH9300762011623852957", "Order 55")
+		q, err := qr.NewSwissQRBill(inv)
+		require.NoError(t, err)
+		assert.Equal(t, qr.SwissRefNone, q.ReferenceType)
+		assert.Equal(t, "Order 55", q.Message)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		inv := testInvoice(t, "CH", currency.USD, "CH9300762011623852957", "")
+		_, err := qr.NewSwissQRBill(inv)
+		assert.ErrorIs(t, err, qr.ErrUnsupported)
+
+		inv = testInvoice(t, "DE", currency.EUR, "DE89370400440532013000", "")
+		_, err = qr.NewSwissQRBill(inv)
+		assert.ErrorContains(t, err, "Account: must be a Swiss or Liechtenstein account")
+
+		inv = testInvoice(t, "CH", currency.CHF, "CH4431999123000889012", "INV-1")
+		_, err = qr.NewSwissQRBill(inv)
+		assert.ErrorContains(t, err, "Reference: invalid format")
+
+		inv = testInvoice(t, "CH", currency.CHF, "CH9300762011623852957", "")
+		inv.Supplier.Addresses = nil
+		_, err = qr.NewSwissQRBill(inv)
+		assert.ErrorContains(t, err, "Creditor: (Country: cannot be blank; PostCode: cannot be blank; Town: cannot be blank.)")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		q := &qr.SwissQRBill{
+			Account:       "CH9300762011623852957",
+			Creditor:      &qr.SwissAddress{Name: "Test", PostCode: "8000", Town: "Zürich", Country: "CH"},
+			Currency:      currency.CHF,
+			ReferenceType: qr.SwissRefQR,
+			Reference:     "210000000003139471430009017",
+		}
+		assert.ErrorContains(t, q.Validate(), "ReferenceType: must be a valid value")
+		q.ReferenceType = cbc.Code("NON")
+		assert.ErrorContains(t, q.Validate(), "Reference: must be blank")
+		q.Reference = ""
+		assert.NoError(t, q.Validate())
+	})
+}
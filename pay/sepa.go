@@ -0,0 +1,138 @@
+package pay
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/validation"
+)
+
+// SEPA direct debit schemes.
+const (
+	SEPASchemeCore cbc.Code = "CORE"
+	SEPASchemeB2B  cbc.Code = "B2B"
+)
+
+// SEPA direct debit sequence types.
+const (
+	SEPASequenceFirst     cbc.Code = "FRST"
+	SEPASequenceRecurring cbc.Code = "RCUR"
+	SEPASequenceFinal     cbc.Code = "FNAL"
+	SEPASequenceOneOff    cbc.Code = "OOFF"
+)
+
+var (
+	sepaCreditorIDRegexp = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{3}[A-Z0-9]{1,28}$`)
+	sepaMandateRefRegexp = regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]{1,35}$`)
+	creditorRefRegexp    = regexp.MustCompile(`^RF[0-9]{2}[A-Z0-9]{1,21}$`)
+)
+
+// CheckSEPACreditorID ensures the SEPA Creditor Identifier has the correct
+// structure and check digits. The identifier is formed by the country code,
+// two check digits, a three character business code that is ignored in
+// the checksum, and the national identifier.
+func CheckSEPACreditorID(id string) error {
+	id = NormalizeBIC(id) // same rules: no spaces, upper case
+	if !sepaCreditorIDRegexp.MatchString(id) {
+		return errors.New("invalid format")
+	}
+	if mod97(id[7:]+id[:4]) != 1 {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// CheckCreditorReference ensures the ISO 11649 Structured Creditor
+// Reference, which starts with "RF" followed by two check digits, has the
+// correct structure and check digits. Spaces and lower case letters are
+// accepted as the reference is often printed in groups of four.
+func CheckCreditorReference(ref string) error {
+	ref = NormalizeBIC(ref)
+	if !creditorRefRegexp.MatchString(ref) {
+		return errors.New("invalid format")
+	}
+	if mod97(ref[4:]+ref[:4]) != 1 {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// IsSEPA returns true when the direct debit is defined with a SEPA scheme.
+func (dd *DirectDebit) IsSEPA() bool {
+	return dd != nil && dd.Scheme != cbc.CodeEmpty
+}
+
+// Normalize cleans up the direct debit's codes.
+func (dd *DirectDebit) Normalize() {
+	if dd == nil {
+		return
+	}
+	dd.Ref = cbc.NormalizeString(dd.Ref)
+	dd.Creditor = cbc.NormalizeString(dd.Creditor)
+	dd.Account = cbc.NormalizeString(dd.Account)
+	dd.Scheme = cbc.NormalizeAlphanumericalCode(dd.Scheme)
+	dd.Sequence = cbc.NormalizeAlphanumericalCode(dd.Sequence)
+	if dd.IsSEPA() {
+		dd.Creditor = NormalizeBIC(dd.Creditor)
+		dd.Account = NormalizeIBAN(dd.Account)
+	}
+}
+
+// Validate ensures the direct debit details are valid, applying the SEPA
+// mandate rules when a scheme is provided.
+func (dd *DirectDebit) Validate() error {
+	sepa := dd.IsSEPA()
+	return validation.ValidateStruct(dd,
+		validation.Field(&dd.Ref,
+			validation.When(
+				sepa,
+				validation.Required,
+				validation.Match(sepaMandateRefRegexp),
+			),
+		),
+		validation.Field(&dd.Creditor,
+			validation.When(
+				sepa,
+				validation.Required,
+				validation.By(checkSEPACreditorID),
+			),
+		),
+		validation.Field(&dd.Account,
+			validation.When(
+				sepa,
+				validation.Required,
+				validation.By(checkIBAN),
+			),
+		),
+		validation.Field(&dd.Scheme,
+			validation.In(SEPASchemeCore, SEPASchemeB2B),
+		),
+		validation.Field(&dd.SignedOn,
+			validation.When(
+				sepa,
+				validation.Required,
+			),
+		),
+		validation.Field(&dd.Sequence,
+			validation.When(
+				sepa,
+				validation.Required,
+			),
+			validation.In(
+				SEPASequenceFirst,
+				SEPASequenceRecurring,
+				SEPASequenceFinal,
+				SEPASequenceOneOff,
+			),
+		),
+	)
+}
+
+func checkSEPACreditorID(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	return CheckSEPACreditorID(s)
+}
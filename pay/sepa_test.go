@@ -0,0 +1,78 @@
+package pay_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/pay"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSEPACreditorID(t *testing.T) {
+	assert.NoError(t, pay.CheckSEPACreditorID("DE98ZZZ09999999999"))
+	assert.NoError(t, pay.CheckSEPACreditorID("de98 zzz 09999999999"))
+	assert.NoError(t, pay.CheckSEPACreditorID("ES97000B12345678"))
+	assert.ErrorContains(t, pay.CheckSEPACreditorID("DE97ZZZ09999999999"), "checksum mismatch")
+	assert.ErrorContains(t, pay.CheckSEPACreditorID("DE98"), "invalid format")
+}
+
+func TestCheckCreditorReference(t *testing.T) {
+	assert.NoError(t, pay.CheckCreditorReference("RF18539007547034"))
+	assert.NoError(t, pay.CheckCreditorReference("rf18 5390 0754 7034"))
+	assert.ErrorContains(t, pay.CheckCreditorReference("RF19539007547034"), "checksum mismatch")
+	assert.ErrorContains(t, pay.CheckCreditorReference("RF18"), "invalid format")
+	assert.ErrorContains(t, pay.CheckCreditorReference("539007547034"), "invalid format")
+}
+
+func TestDirectDebitValidation(t *testing.T) {
+	t.Run("non SEPA", func(t *testing.T) {
+		dd := &pay.DirectDebit{
+			Ref:      "123",
+			Creditor: "ANY-CREDITOR",
+		}
+		assert.NoError(t, dd.Validate())
+		assert.False(t, dd.IsSEPA())
+	})
+	t.Run("SEPA mandate", func(t *testing.T) {
+		dd := &pay.DirectDebit{
+			Ref:      " MANDATE-001 ",
+			Creditor: "de98 zzz 09999999999",
+			Account:  "de89 3704 0044 0532 0130 00",
+			Scheme:   "core",
+			SignedOn: cal.NewDate(2025, 1, 15),
+			Sequence: pay.SEPASequenceFirst,
+		}
+		dd.Normalize()
+		assert.True(t, dd.IsSEPA())
+		assert.Equal(t, "MANDATE-001", dd.Ref)
+		assert.Equal(t, "DE98ZZZ09999999999", dd.Creditor)
+		assert.Equal(t, "DE89370400440532013000", dd.Account)
+		assert.Equal(t, pay.SEPASchemeCore, dd.Scheme)
+		assert.NoError(t, dd.Validate())
+	})
+	t.Run("SEPA missing data", func(t *testing.T) {
+		dd := &pay.DirectDebit{Scheme: pay.SEPASchemeB2B}
+		err := dd.Validate()
+		assert.ErrorContains(t, err, "ref: cannot be blank")
+		assert.ErrorContains(t, err, "creditor: cannot be blank")
+		assert.ErrorContains(t, err, "account: cannot be blank")
+		assert.ErrorContains(t, err, "signed_on: cannot be blank")
+		assert.ErrorContains(t, err, "sequence: cannot be blank")
+	})
+	t.Run("SEPA invalid data", func(t *testing.T) {
+		dd := &pay.DirectDebit{
+			Ref:      "MANDATE#001",
+			Creditor: "DE97ZZZ09999999999",
+			Account:  "DE89370400440532013001",
+			Scheme:   "SEPA",
+			SignedOn: cal.NewDate(2025, 1, 15),
+			Sequence: "NEXT",
+		}
+		err := dd.Validate()
+		assert.ErrorContains(t, err, "ref: must be in a valid format")
+		assert.ErrorContains(t, err, "creditor: checksum mismatch")
+		assert.ErrorContains(t, err, "account: checksum mismatch")
+		assert.ErrorContains(t, err, "scheme: must be a valid value")
+		assert.ErrorContains(t, err, "sequence: must be a valid value")
+	})
+}
@@ -108,16 +108,29 @@ func (t *Terms) UNTDID4279() cbc.Code {
 }
 
 // CalculateDues goes through each DueDate. If it has a percentage
-// value set, it'll be used to calculate the amount.
+// value set, it'll be used to calculate the amount. When the percentages
+// of all the due dates cover the complete sum, any rounding difference
+// will be added to the last due date.
 func (t *Terms) CalculateDues(zero num.Amount, sum num.Amount) {
 	if t == nil {
 		return
 	}
+	total := zero
+	pcs := num.MakeAmount(0, 0)
+	complete := len(t.DueDates) > 0
 	for _, dd := range t.DueDates {
 		if dd.Percent != nil && !dd.Percent.IsZero() {
 			dd.Amount = dd.Percent.Of(sum)
+			pcs = pcs.MatchPrecision(dd.Percent.Base()).Add(dd.Percent.Base())
+		} else {
+			complete = false
 		}
 		dd.Amount = dd.Amount.Rescale(zero.Exp())
+		total = total.Add(dd.Amount)
+	}
+	if complete && pcs.Equals(num.MakeAmount(1, 0)) {
+		last := t.DueDates[len(t.DueDates)-1]
+		last.Amount = last.Amount.Add(sum.Rescale(zero.Exp()).Subtract(total))
 	}
 }
 
@@ -0,0 +1,325 @@
+// Package jsonpatch provides a minimal implementation of JSON Patch (RFC 6902)
+// operations that can be applied to JSON documents.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Supported operation types.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation represents a single JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is an ordered list of operations to apply to a document.
+type Patch []*Operation
+
+// Decode parses the raw JSON Patch data.
+func Decode(data []byte) (Patch, error) {
+	p := make(Patch, 0)
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("decoding patch: %w", err)
+	}
+	for i, op := range p {
+		if err := op.check(); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return p, nil
+}
+
+func (op *Operation) check() error {
+	if op == nil {
+		return errors.New("missing")
+	}
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		if op.Value == nil {
+			return fmt.Errorf("%s: value required", op.Op)
+		}
+	case OpMove, OpCopy:
+		if _, err := parsePointer(op.From); err != nil {
+			return fmt.Errorf("%s: from: %w", op.Op, err)
+		}
+	case OpRemove:
+		// nothing extra
+	default:
+		return fmt.Errorf("unknown operation '%s'", op.Op)
+	}
+	if _, err := parsePointer(op.Path); err != nil {
+		return fmt.Errorf("%s: path: %w", op.Op, err)
+	}
+	return nil
+}
+
+// Apply performs each of the patch operations in order on the JSON document
+// and provides the resulting JSON. The original data will not be modified,
+// and if any operation fails, an error will be returned and no result
+// provided.
+func (p Patch) Apply(data []byte) ([]byte, error) {
+	doc, err := decodeValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	for i, op := range p {
+		if err := op.check(); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %s %s: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func (op *Operation) apply(doc any) (any, error) {
+	path, _ := parsePointer(op.Path)
+	switch op.Op {
+	case OpAdd:
+		v, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case OpRemove:
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case OpReplace:
+		v, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case OpMove:
+		from, _ := parsePointer(op.From)
+		if len(path) > len(from) && isPrefix(from, path) {
+			return nil, errors.New("cannot move into own child")
+		}
+		doc, v, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case OpCopy:
+		from, _ := parsePointer(op.From)
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		v, err = deepCopy(v)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case OpTest:
+		v, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		cur, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(cur, v) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid pointer '%s'", ptr)
+	}
+	parts := strings.Split(ptr[1:], "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, "~1", "/")
+		parts[i] = strings.ReplaceAll(p, "~0", "~")
+	}
+	return parts, nil
+}
+
+func isPrefix(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc any, path []string) (any, error) {
+	cur := doc
+	for _, key := range path {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("key '%s' not found", key)
+			}
+			cur = v
+		case []any:
+			i, err := arrayIndex(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("cannot traverse into '%s'", key)
+		}
+	}
+	return cur, nil
+}
+
+func add(doc any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[key] = v
+		return doc, nil
+	case []any:
+		var i int
+		if key == "-" {
+			i = len(node)
+		} else if i, err = arrayIndex(key, len(node)); err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = v
+		return set(doc, path[:len(path)-1], node)
+	}
+	return nil, fmt.Errorf("cannot add to '%s'", key)
+}
+
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove root")
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		v, ok := node[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("key '%s' not found", key)
+		}
+		delete(node, key)
+		return doc, v, nil
+	case []any:
+		i, err := arrayIndex(key, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = set(doc, path[:len(path)-1], node)
+		return doc, v, err
+	}
+	return nil, nil, fmt.Errorf("cannot remove from '%s'", key)
+}
+
+// set replaces the value at the path, which is required when arrays are
+// resized.
+func set(doc any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[key] = v
+	case []any:
+		i, err := arrayIndex(key, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = v
+	}
+	return doc, nil
+}
+
+func arrayIndex(key string, maximum int) (int, error) {
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid array index '%s'", key)
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i > maximum {
+		return 0, fmt.Errorf("invalid array index '%s'", key)
+	}
+	return i, nil
+}
+
+func decodeValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func deepCopy(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValue(data)
+}
+
+func equal(a, b any) bool {
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, err1 := na.Float64()
+			fb, err2 := nb.Float64()
+			if err1 == nil && err2 == nil {
+				return fa == fb
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
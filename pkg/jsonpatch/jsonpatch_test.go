@@ -0,0 +1,114 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/pkg/jsonpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	p, err := jsonpatch.Decode([]byte(`[{"op":"add","path":"/foo","value":1}]`))
+	require.NoError(t, err)
+	require.Len(t, p, 1)
+	assert.Equal(t, jsonpatch.OpAdd, p[0].Op)
+
+	_, err = jsonpatch.Decode([]byte(`[{"op":"bad","path":"/foo"}]`))
+	assert.ErrorContains(t, err, "operation 0: unknown operation 'bad'")
+	_, err = jsonpatch.Decode([]byte(`[{"op":"add","path":"/foo"}]`))
+	assert.ErrorContains(t, err, "operation 0: add: value required")
+	_, err = jsonpatch.Decode([]byte(`[{"op":"remove","path":"foo"}]`))
+	assert.ErrorContains(t, err, "operation 0: remove: path: invalid pointer 'foo'")
+	_, err = jsonpatch.Decode([]byte(`{}`))
+	assert.ErrorContains(t, err, "decoding patch")
+}
+
+func TestApply(t *testing.T) {
+	doc := `{"name":"Test","lines":[{"i":1},{"i":2}],"a/b":{"~x":1}}`
+	tests := []struct {
+		name  string
+		patch string
+		out   string
+		err   string
+	}{
+		{
+			name:  "add field",
+			patch: `[{"op":"add","path":"/code","value":"123"}]`,
+			out:   `{"name":"Test","code":"123","lines":[{"i":1},{"i":2}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "add to array",
+			patch: `[{"op":"add","path":"/lines/1","value":{"i":3}}]`,
+			out:   `{"name":"Test","lines":[{"i":1},{"i":3},{"i":2}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "append to array",
+			patch: `[{"op":"add","path":"/lines/-","value":{"i":3}}]`,
+			out:   `{"name":"Test","lines":[{"i":1},{"i":2},{"i":3}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "remove from array",
+			patch: `[{"op":"remove","path":"/lines/0"}]`,
+			out:   `{"name":"Test","lines":[{"i":2}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/name","value":"New"}]`,
+			out:   `{"name":"New","lines":[{"i":1},{"i":2}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "escaped pointer",
+			patch: `[{"op":"replace","path":"/a~1b/~0x","value":2}]`,
+			out:   `{"name":"Test","lines":[{"i":1},{"i":2}],"a/b":{"~x":2}}`,
+		},
+		{
+			name:  "move",
+			patch: `[{"op":"move","from":"/name","path":"/title"}]`,
+			out:   `{"title":"Test","lines":[{"i":1},{"i":2}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "copy",
+			patch: `[{"op":"copy","from":"/lines/0","path":"/lines/-"}]`,
+			out:   `{"name":"Test","lines":[{"i":1},{"i":2},{"i":1}],"a/b":{"~x":1}}`,
+		},
+		{
+			name:  "test success",
+			patch: `[{"op":"test","path":"/lines/1/i","value":2.0},{"op":"remove","path":"/a~1b"}]`,
+			out:   `{"name":"Test","lines":[{"i":1},{"i":2}]}`,
+		},
+		{
+			name:  "test failure",
+			patch: `[{"op":"test","path":"/name","value":"Other"}]`,
+			err:   "operation 0: test /name: test failed",
+		},
+		{
+			name:  "missing key",
+			patch: `[{"op":"remove","path":"/foo"}]`,
+			err:   "operation 0: remove /foo: key 'foo' not found",
+		},
+		{
+			name:  "bad index",
+			patch: `[{"op":"add","path":"/lines/5","value":1}]`,
+			err:   "operation 0: add /lines/5: invalid array index '5'",
+		},
+		{
+			name:  "move into child",
+			patch: `[{"op":"move","from":"/lines","path":"/lines/0"}]`,
+			err:   "cannot move into own child",
+		},
+	}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			p, err := jsonpatch.Decode([]byte(ts.patch))
+			require.NoError(t, err)
+			out, err := p.Apply([]byte(doc))
+			if ts.err != "" {
+				assert.ErrorContains(t, err, ts.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, ts.out, string(out))
+		})
+	}
+}
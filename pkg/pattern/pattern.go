@@ -0,0 +1,46 @@
+// Package pattern provides a shared registry of compiled regular
+// expressions, so that patterns defined in data, such as those of
+// extension definitions, are compiled once and re-used by every
+// validation instead of on each call.
+package pattern
+
+import (
+	"regexp"
+	"sync"
+)
+
+var compiled sync.Map // map[string]*regexp.Regexp
+
+// Compile provides the compiled regular expression for the pattern, which
+// will only be compiled the first time it is requested. Invalid patterns
+// are not stored, so will return the same error on each call.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiled.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := compiled.LoadOrStore(pattern, re)
+	return actual.(*regexp.Regexp), nil
+}
+
+// MustCompile is like Compile but panics if the pattern is invalid.
+func MustCompile(pattern string) *regexp.Regexp {
+	re, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// MatchString reports whether the string matches the pattern, returning
+// an error if the pattern is invalid.
+func MatchString(pattern, s string) (bool, error) {
+	re, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
@@ -0,0 +1,48 @@
+package pattern_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/pkg/pattern"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	re, err := pattern.Compile(`^\d{5}$`)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("12345"))
+	re2, err := pattern.Compile(`^\d{5}$`)
+	require.NoError(t, err)
+	assert.Same(t, re, re2)
+
+	_, err = pattern.Compile(`^[`)
+	assert.ErrorContains(t, err, "missing closing ]")
+}
+
+func TestMustCompile(t *testing.T) {
+	assert.NotNil(t, pattern.MustCompile(`^A`))
+	assert.Panics(t, func() {
+		pattern.MustCompile(`(`)
+	})
+}
+
+func TestMatchString(t *testing.T) {
+	ok, err := pattern.MatchString(`^[A-Z]{2}$`, "ES")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = pattern.MatchString(`^[A-Z]{2}$`, "ESP")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = pattern.MatchString(`(`, "ES")
+	assert.Error(t, err)
+}
+
+func BenchmarkMatchString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := pattern.MatchString(`^[0-9]{9}[A-Z]$`, "123456789A"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package gobl
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/validation"
+	"golang.org/x/text/language"
+)
+
+// A Problem describes a single issue found in a document in a structured
+// format that may be returned by APIs without needing to parse error
+// strings. Problems are usually extracted from validation errors using
+// the Error's Problems method.
+type Problem struct {
+	// Code is a stable machine readable identifier of the type of problem.
+	Code string `json:"code"`
+	// Path is the RFC 6901 JSON Pointer to the offending field, if any.
+	Path string `json:"path"`
+	// Message is the human readable description of the problem.
+	Message string `json:"message"`
+	// Params used to build the message from a template, if any.
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// validationMessages provides translations of the messages for the error
+// codes used by the validation rules.
+var validationMessages = map[string]i18n.String{
+	"validation_required": {
+		i18n.EN: "cannot be blank",
+		i18n.ES: "no puede estar vacío",
+		i18n.FR: "ne peut pas être vide",
+		i18n.DE: "darf nicht leer sein",
+	},
+	"validation_nil_or_not_empty_required": {
+		i18n.EN: "cannot be blank",
+		i18n.ES: "no puede estar vacío",
+		i18n.FR: "ne peut pas être vide",
+		i18n.DE: "darf nicht leer sein",
+	},
+	"validation_not_nil_required": {
+		i18n.EN: "is required",
+		i18n.ES: "es obligatorio",
+		i18n.FR: "est obligatoire",
+		i18n.DE: "ist erforderlich",
+	},
+	"validation_nil": {
+		i18n.EN: "must be blank",
+		i18n.ES: "debe estar vacío",
+		i18n.FR: "doit être vide",
+		i18n.DE: "muss leer sein",
+	},
+	"validation_empty": {
+		i18n.EN: "must be blank",
+		i18n.ES: "debe estar vacío",
+		i18n.FR: "doit être vide",
+		i18n.DE: "muss leer sein",
+	},
+	"validation_in_invalid": {
+		i18n.EN: "must be a valid value",
+		i18n.ES: "debe ser un valor válido",
+		i18n.FR: "doit être une valeur valide",
+		i18n.DE: "muss ein gültiger Wert sein",
+	},
+	"validation_not_in_invalid": {
+		i18n.EN: "must not be in list",
+		i18n.ES: "no debe estar en la lista",
+		i18n.FR: "ne doit pas figurer dans la liste",
+		i18n.DE: "darf nicht in der Liste sein",
+	},
+	"validation_match_invalid": {
+		i18n.EN: "must be in a valid format",
+		i18n.ES: "debe tener un formato válido",
+		i18n.FR: "doit être dans un format valide",
+		i18n.DE: "muss ein gültiges Format haben",
+	},
+	"validation_length_too_long": {
+		i18n.EN: "the length must be no more than {{.max}}",
+		i18n.ES: "la longitud no debe ser mayor que {{.max}}",
+		i18n.FR: "la longueur ne doit pas dépasser {{.max}}",
+		i18n.DE: "die Länge darf höchstens {{.max}} betragen",
+	},
+	"validation_length_too_short": {
+		i18n.EN: "the length must be no less than {{.min}}",
+		i18n.ES: "la longitud no debe ser menor que {{.min}}",
+		i18n.FR: "la longueur doit être d'au moins {{.min}}",
+		i18n.DE: "die Länge muss mindestens {{.min}} betragen",
+	},
+	"validation_length_invalid": {
+		i18n.EN: "the length must be exactly {{.min}}",
+		i18n.ES: "la longitud debe ser exactamente {{.min}}",
+		i18n.FR: "la longueur doit être exactement {{.min}}",
+		i18n.DE: "die Länge muss genau {{.min}} betragen",
+	},
+	"validation_length_out_of_range": {
+		i18n.EN: "the length must be between {{.min}} and {{.max}}",
+		i18n.ES: "la longitud debe estar entre {{.min}} y {{.max}}",
+		i18n.FR: "la longueur doit être comprise entre {{.min}} et {{.max}}",
+		i18n.DE: "die Länge muss zwischen {{.min}} und {{.max}} liegen",
+	},
+	"validation_min_greater_equal_than_required": {
+		i18n.EN: "must be no less than {{.threshold}}",
+		i18n.ES: "no debe ser menor que {{.threshold}}",
+		i18n.FR: "ne doit pas être inférieur à {{.threshold}}",
+		i18n.DE: "darf nicht kleiner als {{.threshold}} sein",
+	},
+	"validation_max_less_equal_than_required": {
+		i18n.EN: "must be no greater than {{.threshold}}",
+		i18n.ES: "no debe ser mayor que {{.threshold}}",
+		i18n.FR: "ne doit pas être supérieur à {{.threshold}}",
+		i18n.DE: "darf nicht größer als {{.threshold}} sein",
+	},
+	"validation_min_greater_than_required": {
+		i18n.EN: "must be greater than {{.threshold}}",
+		i18n.ES: "debe ser mayor que {{.threshold}}",
+		i18n.FR: "doit être supérieur à {{.threshold}}",
+		i18n.DE: "muss größer als {{.threshold}} sein",
+	},
+	"validation_max_less_than_required": {
+		i18n.EN: "must be less than {{.threshold}}",
+		i18n.ES: "debe ser menor que {{.threshold}}",
+		i18n.FR: "doit être inférieur à {{.threshold}}",
+		i18n.DE: "muss kleiner als {{.threshold}} sein",
+	},
+	"validation_date_invalid": {
+		i18n.EN: "must be a valid date",
+		i18n.ES: "debe ser una fecha válida",
+		i18n.FR: "doit être une date valide",
+		i18n.DE: "muss ein gültiges Datum sein",
+	},
+	"validation_is_zero": {
+		i18n.EN: "must not be zero",
+		i18n.ES: "no debe ser cero",
+		i18n.FR: "ne doit pas être zéro",
+		i18n.DE: "darf nicht null sein",
+	},
+}
+
+// Problems provides the list of individual problems contained in the
+// error, sorted by path. Messages will be translated into the language
+// that best matches the tags provided, when available, or use the same
+// English text as the Error method otherwise. Problems without a more
+// specific code will use the error's key.
+func (e *Error) Problems(tags ...language.Tag) []*Problem {
+	list := make([]*Problem, 0)
+	if e.cause == nil {
+		return append(list, &Problem{Code: e.key.String(), Message: e.key.String()})
+	}
+	list = appendProblems(list, e.key.String(), nil, e.cause, tags)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+// Warnings provides the list of problems found in the envelope's document
+// that do not make it invalid but should be reviewed, such as tax rates
+// that will change shortly after an invoice's issue date. Documents may
+// report warnings by implementing a `Warnings() error` method that
+// provides errors structured in the same way as validation errors.
+// Messages are translated in the same way as the Error's Problems.
+func (e *Envelope) Warnings(tags ...language.Tag) []*Problem {
+	list := make([]*Problem, 0)
+	w, ok := e.Extract().(interface{ Warnings() error })
+	if !ok {
+		return list
+	}
+	err := w.Warnings()
+	if err == nil {
+		return list
+	}
+	list = appendProblems(list, "warning", []string{"doc"}, err, tags)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+func appendProblems(list []*Problem, code string, path []string, err error, tags []language.Tag) []*Problem {
+	switch te := err.(type) {
+	case FieldErrors:
+		for k, fe := range te {
+			list = appendProblems(list, code, append(path, k), fe, tags)
+		}
+		return list
+	case validation.Errors:
+		for k, fe := range te {
+			list = appendProblems(list, code, append(path, k), fe, tags)
+		}
+		return list
+	}
+	p := &Problem{
+		Code:    code,
+		Path:    jsonPointer(path),
+		Message: err.Error(),
+	}
+	switch te := err.(type) {
+	case i18n.Error:
+		p.Code = te.Code()
+		p.Params = te.Params()
+		if len(tags) > 0 {
+			p.Message = te.Localize(tags...)
+		}
+	case validation.Error:
+		if te.Code() != "" {
+			p.Code = te.Code()
+			p.Params = te.Params()
+			if len(tags) > 0 {
+				p.Message = localizeValidationError(te, tags)
+			}
+		}
+	default:
+		// wrapped errors keep their messages, but may provide a code
+		var ve validation.Error
+		if errors.As(err, &ve) && ve.Code() != "" {
+			p.Code = ve.Code()
+		}
+	}
+	return append(list, p)
+}
+
+// localizeValidationError translates the validation error's message if
+// it has not been replaced with a custom one.
+func localizeValidationError(ve validation.Error, tags []language.Tag) string {
+	msg, ok := validationMessages[ve.Code()]
+	if !ok || msg.String() != ve.Message() {
+		return ve.Error()
+	}
+	return i18n.RenderTemplate(msg.InLocale(tags...), ve.Params())
+}
+
+// jsonPointer builds an RFC 6901 JSON Pointer from the list of keys.
+func jsonPointer(path []string) string {
+	var b strings.Builder
+	for _, k := range path {
+		b.WriteByte('/')
+		k = strings.ReplaceAll(k, "~", "~0")
+		k = strings.ReplaceAll(k, "/", "~1")
+		b.WriteString(k)
+	}
+	return b.String()
+}
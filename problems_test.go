@@ -0,0 +1,133 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/note"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+	"github.com/invopop/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestErrorProblems(t *testing.T) {
+	t.Run("document", func(t *testing.T) {
+		env := gobl.NewEnvelope()
+		require.NoError(t, env.Insert(&note.Message{}))
+		var ge *gobl.Error
+		require.True(t, errors.As(env.Validate(), &ge))
+
+		ps := ge.Problems()
+		require.Len(t, ps, 1)
+		assert.Equal(t, &gobl.Problem{
+			Code:    "validation_required",
+			Path:    "/doc/content",
+			Message: "cannot be blank",
+		}, ps[0])
+
+		ps = ge.Problems(language.MustParse("es-ES"))
+		assert.Equal(t, "no puede estar vacío", ps[0].Message)
+		data, err := json.Marshal(ps)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"code":"validation_required","path":"/doc/content","message":"no puede estar vacío"}]`, string(data))
+	})
+
+	t.Run("invoice", func(t *testing.T) {
+		data, err := os.ReadFile("./examples/es/invoice-es-es.yaml")
+		require.NoError(t, err)
+		inv := new(bill.Invoice)
+		require.NoError(t, yaml.Unmarshal(data, inv))
+		require.NoError(t, inv.Calculate())
+		inv.Tax = &bill.Tax{Ext: tax.Extensions{"xx-undefined": "FOO"}}
+		inv.Lines[0].Item.Name = ""
+		err = gobl.ErrValidation.WithCause(inv.Validate())
+		var ge *gobl.Error
+		require.True(t, errors.As(err, &ge))
+
+		ps := ge.Problems(language.French)
+		require.Len(t, ps, 2, err.Error())
+		assert.Equal(t, "/lines/0/item/name", ps[0].Path)
+		assert.Equal(t, "validation_required", ps[0].Code)
+		assert.Equal(t, "ne peut pas être vide", ps[0].Message)
+		assert.Equal(t, "/tax/ext/xx-undefined", ps[1].Path)
+		assert.Equal(t, "validation_ext_undefined", ps[1].Code)
+		assert.Equal(t, "non défini", ps[1].Message)
+	})
+
+	t.Run("params", func(t *testing.T) {
+		ve := validation.Errors{
+			"ext": validation.Errors{
+				"a/b": tax.ErrExtValueInvalid.WithParams(map[string]any{"value": "X"}),
+			},
+			"name": validation.Validate("abcd", validation.Length(1, 3)),
+			"code": validation.Validate("abcd", validation.Length(1, 3).Error("too long")),
+			"note": errors.New("plain"),
+		}
+		ge := gobl.ErrValidation.WithCause(ve)
+		assert.Equal(t, "validation: (code: too long; ext: (a/b: value 'X' invalid.); name: the length must be between 1 and 3; note: plain.).", ge.Error())
+
+		ps := ge.Problems(language.German)
+		require.Len(t, ps, 4)
+		assert.Equal(t, &gobl.Problem{
+			Code:    "validation_length_out_of_range",
+			Path:    "/code",
+			Message: "too long",
+			Params:  map[string]any{"min": 1, "max": 3},
+		}, ps[0])
+		assert.Equal(t, &gobl.Problem{
+			Code:    "validation_ext_value_invalid",
+			Path:    "/ext/a~1b",
+			Message: "Wert 'X' ungültig",
+			Params:  map[string]any{"value": "X"},
+		}, ps[1])
+		assert.Equal(t, "die Länge muss zwischen 1 und 3 liegen", ps[2].Message)
+		assert.Equal(t, &gobl.Problem{
+			Code:    "validation",
+			Path:    "/note",
+			Message: "plain",
+		}, ps[3])
+	})
+
+	t.Run("without fields", func(t *testing.T) {
+		ps := gobl.ErrNoDocument.Problems()
+		assert.Equal(t, []*gobl.Problem{{Code: "no-document", Message: "no-document"}}, ps)
+		ps = gobl.ErrSignature.WithReason("bad %s", "key").Problems()
+		assert.Equal(t, []*gobl.Problem{{Code: "signature", Message: "bad key"}}, ps)
+	})
+}
+
+func TestEnvelopeWarnings(t *testing.T) {
+	t.Run("invoice", func(t *testing.T) {
+		data, err := os.ReadFile("./examples/es/invoice-es-es.yaml")
+		require.NoError(t, err)
+		inv := new(bill.Invoice)
+		require.NoError(t, yaml.Unmarshal(data, inv))
+		inv.IssueDate = cal.MakeDate(2012, 8, 20)
+		env, err := gobl.Envelop(inv)
+		require.NoError(t, err)
+
+		ps := env.Warnings(language.Spanish)
+		require.NotEmpty(t, ps)
+		assert.Equal(t, &gobl.Problem{
+			Code:    "warning_rate_change_upcoming",
+			Path:    "/doc/lines/0/taxes/0",
+			Message: "el tipo cambia a 21.0% el 2012-09-01",
+			Params:  map[string]any{"date": "2012-09-01", "percent": "21.0%"},
+		}, ps[0])
+	})
+
+	t.Run("none", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		assert.Empty(t, env.Warnings())
+		assert.Empty(t, gobl.NewEnvelope().Warnings())
+	})
+}
@@ -0,0 +1,406 @@
+package gobl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/validation"
+
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/invopop/gobl/internal"
+	"github.com/invopop/gobl/schema"
+)
+
+// RedactedEnvelope contains a copy of an envelope's document where some of the
+// fields have been removed, typically to share with third parties without
+// including personal information. The header and signatures are kept intact,
+// and the header's redactable digest can be used to verify that the visible
+// fields were not modified, even though the redacted values are unknown.
+type RedactedEnvelope struct {
+	// Schema identifies the schema that should be used to understand this document
+	Schema schema.ID `json:"$schema" jsonschema:"title=JSON Schema ID"`
+	// Details on what the contents are
+	Head *head.Header `json:"head" jsonschema:"title=Header"`
+	// Document data with redacted fields replaced by null values
+	Document json.RawMessage `json:"doc" jsonschema:"title=Document"`
+	// Salts for each of the visible fields, indexed by JSON Pointer
+	Salts map[string]string `json:"salts" jsonschema:"title=Salts"`
+	// Hashes of each of the redacted fields, indexed by JSON Pointer
+	Redacted map[string]string `json:"redacted" jsonschema:"title=Redacted"`
+	// JSON Web Signatures of the header
+	Signatures []*dsig.Signature `json:"sigs,omitempty" jsonschema:"title=Signatures"`
+}
+
+// RedactedEnvelopeSchema sets the general definition of the schema ID for
+// redacted envelopes.
+var RedactedEnvelopeSchema = schema.GOBL.Add("redacted-envelope")
+
+// EnableRedaction prepares the envelope so that a redacted copy can be made
+// at a later date, by adding a random salt and including the redactable
+// digest in the header. This must be called before signing. The salt is
+// never serialized with the envelope, so the issuer must store it privately
+// in order to redact the envelope later.
+func (e *Envelope) EnableRedaction() error {
+	if e.Signed() {
+		return ErrSignature.WithReason("cannot enable redaction on signed envelope")
+	}
+	if e.Salt == "" {
+		salt := make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return ErrInternal.WithCause(err)
+		}
+		e.Salt = hex.EncodeToString(salt)
+	}
+	return e.Calculate()
+}
+
+// RedactableDigest calculates the root digest of the tree built from the
+// salted hashes of each of the document's fields.
+func (e *Envelope) RedactableDigest() (*dsig.Digest, error) {
+	salt, err := decodeRedactionSalt(e.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return e.redactableDigest(salt)
+}
+
+func (e *Envelope) redactableDigest(salt []byte) (*dsig.Digest, error) {
+	doc, err := e.genericDocument()
+	if err != nil {
+		return nil, err
+	}
+	leaves := make(map[string]*dsig.Digest)
+	err = walkRedactableFields(doc, "", func(ptr string, v any) error {
+		d, err := redactableFieldDigest(redactableFieldSalt(salt, ptr), ptr, v)
+		leaves[ptr] = d
+		return err
+	})
+	if err != nil {
+		return nil, ErrDigest.WithCause(err)
+	}
+	return redactableDigestTree(leaves)
+}
+
+// Redact builds a redacted copy of the envelope where the values at each of
+// the JSON Pointer paths are replaced by null. Redaction must have been
+// enabled before signing, and the salt provided must be the one generated
+// by EnableRedaction.
+func (e *Envelope) Redact(salt string, paths ...string) (*RedactedEnvelope, error) {
+	if e.Head == nil || e.Head.RedactableDigest == nil {
+		return nil, ErrValidation.WithReason("redaction not enabled")
+	}
+	if err := e.verifyDigest(); err != nil {
+		return nil, err
+	}
+	s, err := decodeRedactionSalt(salt)
+	if err != nil {
+		return nil, err
+	}
+	rd, err := e.redactableDigest(s)
+	if err != nil {
+		return nil, err
+	}
+	if e.Head.RedactableDigest.Equals(rd) != nil {
+		return nil, ErrDigest.WithReason("salt does not match redactable digest")
+	}
+	doc, err := e.genericDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	re := &RedactedEnvelope{
+		Schema:     RedactedEnvelopeSchema,
+		Head:       e.Head,
+		Salts:      make(map[string]string),
+		Redacted:   make(map[string]string),
+		Signatures: e.Signatures,
+	}
+	sort.Strings(paths) // parents first
+	for _, p := range paths {
+		if re.covered(p) {
+			continue
+		}
+		if p == "" || p == "/$schema" {
+			return nil, ErrValidation.WithReason("cannot redact '%s'", p)
+		}
+		v, err := redactField(doc, p)
+		if err != nil {
+			return nil, ErrValidation.WithReason("redact '%s': %s", p, err.Error())
+		}
+		err = walkRedactableFields(v, p, func(ptr string, v any) error {
+			d, err := redactableFieldDigest(redactableFieldSalt(s, ptr), ptr, v)
+			if err != nil {
+				return err
+			}
+			re.Redacted[ptr] = d.Value
+			return nil
+		})
+		if err != nil {
+			return nil, ErrDigest.WithCause(err)
+		}
+	}
+	err = walkRedactableFields(doc, "", func(ptr string, _ any) error {
+		if !re.covered(ptr) {
+			re.Salts[ptr] = hex.EncodeToString(redactableFieldSalt(s, ptr))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, ErrDigest.WithCause(err)
+	}
+	if re.Document, err = json.Marshal(doc); err != nil {
+		return nil, ErrMarshal.WithCause(err)
+	}
+	return re, nil
+}
+
+func decodeRedactionSalt(salt string) ([]byte, error) {
+	s, err := hex.DecodeString(salt)
+	if err != nil || len(s) == 0 {
+		return nil, ErrDigest.WithReason("invalid salt")
+	}
+	return s, nil
+}
+
+func (e *Envelope) genericDocument() (any, error) {
+	if e.Document == nil || e.Document.IsEmpty() {
+		return nil, ErrNoDocument
+	}
+	data, err := json.Marshal(e.Document)
+	if err != nil {
+		return nil, ErrMarshal.WithCause(err)
+	}
+	return decodeGeneric(data)
+}
+
+// Validate ensures the redacted envelope contains the expected data and that
+// the redactable digest in the header matches.
+func (re *RedactedEnvelope) Validate() error {
+	return re.ValidateWithContext(context.Background())
+}
+
+// ValidateWithContext ensures the redacted envelope contains the expected data
+// and that the redactable digest in the header matches.
+func (re *RedactedEnvelope) ValidateWithContext(ctx context.Context) error {
+	if len(re.Signatures) > 0 {
+		ctx = internal.SignedContext(ctx)
+	}
+	err := validation.ValidateStructWithContext(ctx, re,
+		validation.Field(&re.Schema, validation.Required),
+		validation.Field(&re.Head, validation.Required),
+		validation.Field(&re.Document, validation.Required),
+		validation.Field(&re.Signatures),
+	)
+	if err != nil {
+		return wrapError(err)
+	}
+	return wrapError(re.verifyDigest())
+}
+
+func (re *RedactedEnvelope) verifyDigest() error {
+	if re.Head.RedactableDigest == nil {
+		return ErrDigest.WithReason("missing redactable digest")
+	}
+	d, err := re.Digest()
+	if err != nil {
+		return err
+	}
+	if err := re.Head.RedactableDigest.Equals(d); err != nil {
+		return ErrDigest.WithCause(err)
+	}
+	return nil
+}
+
+// Digest rebuilds the redactable digest using the visible fields and their
+// salts, alongside the hashes of the redacted fields.
+func (re *RedactedEnvelope) Digest() (*dsig.Digest, error) {
+	doc, err := decodeGeneric(re.Document)
+	if err != nil {
+		return nil, ErrUnmarshal.WithCause(err)
+	}
+	leaves := make(map[string]*dsig.Digest)
+	for ptr, v := range re.Redacted {
+		leaves[ptr] = &dsig.Digest{Algorithm: dsig.DigestSHA256, Value: v}
+	}
+	err = walkRedactableFields(doc, "", func(ptr string, v any) error {
+		if re.covered(ptr) {
+			return nil
+		}
+		s, ok := re.Salts[ptr]
+		if !ok {
+			return fmt.Errorf("missing salt for '%s'", ptr)
+		}
+		salt, err := hex.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("invalid salt for '%s'", ptr)
+		}
+		leaves[ptr], err = redactableFieldDigest(salt, ptr, v)
+		return err
+	})
+	if err != nil {
+		return nil, ErrDigest.WithCause(err)
+	}
+	return redactableDigestTree(leaves)
+}
+
+// Verify checks the signatures of the redacted envelope's header, using the
+// public keys if provided, and ensures the visible document data matches the
+// redactable digest.
+func (re *RedactedEnvelope) Verify(keys ...*dsig.PublicKey) error {
+	if len(re.Signatures) == 0 {
+		return errors.New("no signatures to verify")
+	}
+	ve := make(validation.Errors)
+	for i, s := range re.Signatures {
+		if err := verifyHeaderSignature(re.Head, s, keys...); err != nil {
+			ve[strconv.Itoa(i)] = err
+		}
+	}
+	if len(ve) > 0 {
+		return ErrValidation.WithCause(validation.Errors{
+			"signatures": ve,
+		})
+	}
+	return wrapError(re.verifyDigest())
+}
+
+// covered returns true if the pointer was redacted, or is the parent of a
+// redacted field and thus replaced by null.
+func (re *RedactedEnvelope) covered(ptr string) bool {
+	if _, ok := re.Redacted[ptr]; ok {
+		return true
+	}
+	for p := range re.Redacted {
+		if strings.HasPrefix(p, ptr+"/") || strings.HasPrefix(ptr, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeGeneric(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// walkRedactableFields calls the function with the JSON Pointer of each
+// scalar value, empty object, or empty array inside the document.
+func walkRedactableFields(v any, ptr string, fn func(ptr string, v any) error) error {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			return fn(ptr, val)
+		}
+		for k, c := range val {
+			k = strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+			if err := walkRedactableFields(c, ptr+"/"+k, fn); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if len(val) == 0 {
+			return fn(ptr, val)
+		}
+		for i, c := range val {
+			if err := walkRedactableFields(c, ptr+"/"+strconv.Itoa(i), fn); err != nil {
+				return err
+			}
+		}
+	default:
+		return fn(ptr, val)
+	}
+	return nil
+}
+
+// redactField replaces the value at the JSON Pointer path with null and
+// returns the original value.
+func redactField(doc any, path string) (any, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("invalid path")
+	}
+	parts := strings.Split(path[1:], "/")
+	cur := doc
+	for i, p := range parts {
+		p = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+		last := i == len(parts)-1
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[p]
+			if !ok {
+				return nil, errors.New("not found")
+			}
+			if last {
+				node[p] = nil
+				return v, nil
+			}
+			cur = v
+		case []any:
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 || n >= len(node) {
+				return nil, errors.New("not found")
+			}
+			if last {
+				v := node[n]
+				node[n] = nil
+				return v, nil
+			}
+			cur = node[n]
+		default:
+			return nil, errors.New("not found")
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func redactableFieldSalt(salt []byte, ptr string) []byte {
+	h := hmac.New(sha256.New, salt)
+	h.Write([]byte(ptr)) //nolint:errcheck
+	return h.Sum(nil)[:16]
+}
+
+func redactableFieldDigest(salt []byte, ptr string, v any) (*dsig.Digest, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(salt)+len(ptr)+len(data)+2)
+	buf = append(buf, salt...)
+	buf = append(buf, 0)
+	buf = append(buf, ptr...)
+	buf = append(buf, 0)
+	buf = append(buf, data...)
+	return dsig.NewSHA256Digest(buf), nil
+}
+
+func redactableDigestTree(leaves map[string]*dsig.Digest) (*dsig.Digest, error) {
+	ptrs := make([]string, 0, len(leaves))
+	for p := range leaves {
+		ptrs = append(ptrs, p)
+	}
+	sort.Strings(ptrs)
+	list := make([]*dsig.Digest, len(ptrs))
+	for i, p := range ptrs {
+		list[i] = leaves[p]
+	}
+	d, err := dsig.NewSHA256DigestTree(list)
+	if err != nil {
+		return nil, ErrDigest.WithCause(err)
+	}
+	return d, nil
+}
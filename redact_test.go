@@ -0,0 +1,124 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRedactableEnvelope(t *testing.T) *gobl.Envelope {
+	t.Helper()
+	env := gobl.NewEnvelope()
+	data, err := os.ReadFile("./examples/es/invoice-es-es.env.yaml")
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, env))
+	require.NoError(t, env.EnableRedaction())
+	require.NoError(t, env.Sign(testKey))
+	return env
+}
+
+func TestEnvelopeEnableRedaction(t *testing.T) {
+	env := testRedactableEnvelope(t)
+	assert.Len(t, env.Salt, 64)
+	require.NotNil(t, env.Head.RedactableDigest)
+	assert.NoError(t, env.Validate())
+
+	err := env.EnableRedaction()
+	assert.ErrorIs(t, err, gobl.ErrSignature)
+
+	env.Salt = "00"
+	assert.ErrorIs(t, env.Validate(), gobl.ErrDigest)
+}
+
+func TestEnvelopeRedact(t *testing.T) {
+	env := testRedactableEnvelope(t)
+
+	re, err := env.Redact(env.Salt, "/customer", "/lines/0/item/price", "/customer/name")
+	require.NoError(t, err)
+	assert.Equal(t, gobl.RedactedEnvelopeSchema, re.Schema)
+	assert.NotContains(t, string(re.Document), "Sample Consumer")
+	assert.Contains(t, string(re.Document), `"customer":null`)
+	assert.Contains(t, re.Redacted, "/customer/name")
+	assert.Contains(t, re.Redacted, "/lines/0/item/price")
+	assert.NotContains(t, re.Salts, "/customer/name")
+	assert.Contains(t, re.Salts, "/supplier/name")
+	assert.NoError(t, re.Validate())
+	assert.NoError(t, re.Verify(testKey.Public()))
+
+	data, err := json.Marshal(re)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), env.Salt)
+	re2 := new(gobl.RedactedEnvelope)
+	require.NoError(t, json.Unmarshal(data, re2))
+	assert.NoError(t, re2.Verify(testKey.Public()))
+
+	t.Run("modified visible field", func(t *testing.T) {
+		re, err := env.Redact(env.Salt, "/customer")
+		require.NoError(t, err)
+		re.Document = []byte(string(re.Document[:len(re.Document)-1]) + `,"notes":null}`)
+		err = re.Verify(testKey.Public())
+		assert.ErrorContains(t, err, "missing salt for '/notes'")
+
+		re, err = env.Redact(env.Salt, "/customer")
+		require.NoError(t, err)
+		re.Redacted["/customer/name"] = re.Salts["/supplier/name"] + re.Salts["/supplier/name"]
+		assert.ErrorIs(t, re.Verify(testKey.Public()), gobl.ErrDigest)
+	})
+
+	t.Run("invalid paths", func(t *testing.T) {
+		_, err := env.Redact(env.Salt, "/unknown")
+		assert.ErrorContains(t, err, "redact '/unknown': not found")
+		_, err = env.Redact(env.Salt, "/$schema")
+		assert.ErrorContains(t, err, "cannot redact '/$schema'")
+		_, err = env.Redact(env.Salt, "customer")
+		assert.ErrorContains(t, err, "invalid path")
+	})
+
+	t.Run("stored salt", func(t *testing.T) {
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), env.Salt)
+		e2 := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, e2))
+		assert.Empty(t, e2.Salt)
+		re, err := e2.Redact(env.Salt, "/customer")
+		require.NoError(t, err)
+		assert.NoError(t, re.Verify(testKey.Public()))
+	})
+
+	t.Run("wrong salt", func(t *testing.T) {
+		_, err := env.Redact("", "/customer")
+		assert.ErrorContains(t, err, "invalid salt")
+		_, err = env.Redact(strings.Repeat("00", 32), "/customer")
+		assert.ErrorIs(t, err, gobl.ErrDigest)
+		assert.ErrorContains(t, err, "salt does not match redactable digest")
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		_, err = env.Redact(env.Salt, "/content")
+		assert.ErrorContains(t, err, "redaction not enabled")
+	})
+
+	t.Run("unsigned redactable digest", func(t *testing.T) {
+		env, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, env.Sign(testKey))
+		env.Unsign()
+		require.NoError(t, env.EnableRedaction())
+		e2, err := gobl.Envelop(testNoteExample())
+		require.NoError(t, err)
+		require.NoError(t, e2.Sign(testKey))
+		re, err := env.Redact(env.Salt, "/content")
+		require.NoError(t, err)
+		re.Signatures = e2.Signatures
+		assert.ErrorContains(t, re.Verify(testKey.Public()), "header mismatch")
+	})
+}
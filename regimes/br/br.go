@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("BR", New)
 }
 
 // New provides the tax region definition
@@ -12,7 +12,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("CA", New)
 }
 
 // Tax categories specific for Canada.
@@ -10,7 +10,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("CO", New)
 }
 
 // New provides the tax region definition
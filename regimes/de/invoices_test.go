@@ -61,6 +61,16 @@ func TestInvoiceValidation(t *testing.T) {
 		assert.ErrorContains(t, inv.Validate(), "supplier: (identities: missing key 'de-tax-number'; tax_id: cannot be blank.).")
 	})
 
+	t.Run("cash basis", func(t *testing.T) {
+		inv := validInvoice()
+		inv.SetTags(tax.TagCashBasis)
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+		require.Len(t, inv.Notes, 1)
+		assert.Equal(t, "Besteuerung nach vereinnahmten Entgelten (Ist-Versteuerung).", inv.Notes[0].Text)
+		assert.True(t, inv.Totals.CashBasis)
+	})
+
 	t.Run("simplified invoice - no tax details", func(t *testing.T) {
 		inv := validInvoice()
 		inv.SetTags("simplified")
@@ -20,5 +20,14 @@ var invoiceScenarios = &tax.ScenarioSet{
 				Text: "Reverse Charge / Umkehr der Steuerschuld.",
 			},
 		},
+		// Cash accounting (Ist-Versteuerung)
+		{
+			Tags: []cbc.Key{tax.TagCashBasis},
+			Note: &tax.ScenarioNote{
+				Key:  org.NoteKeyLegal,
+				Src:  tax.TagCashBasis,
+				Text: "Besteuerung nach vereinnahmten Entgelten (Ist-Versteuerung).",
+			},
+		},
 	},
 }
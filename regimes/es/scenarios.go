@@ -18,7 +18,9 @@ const (
 	TagSecondHandGoods  cbc.Key = "second-hand-goods"
 	TagArt              cbc.Key = "art"
 	TagAntiques         cbc.Key = "antiques"
-	TagCashBasis        cbc.Key = "cash-basis"
+
+	// Deprecated: use tax.TagCashBasis instead.
+	TagCashBasis = tax.TagCashBasis
 )
 
 func invoiceTags() *tax.TagSet {
@@ -82,14 +84,6 @@ func invoiceTags() *tax.TagSet {
 					i18n.ES: "Régimen especial de las antigüedades y objetos de colección",
 				},
 			},
-			// Special Regime of "Cash Criteria"
-			{
-				Key: TagCashBasis,
-				Name: i18n.String{
-					i18n.EN: "Special scheme on cash basis",
-					i18n.ES: "Régimen especial del criterio de caja",
-				},
-			},
 		},
 	}
 }
@@ -164,10 +158,10 @@ func invoiceScenarios() *tax.ScenarioSet {
 			},
 			// Special Regime of "Cash Criteria"
 			{
-				Tags: []cbc.Key{TagCashBasis},
+				Tags: []cbc.Key{tax.TagCashBasis},
 				Note: &tax.ScenarioNote{
 					Key:  org.NoteKeyLegal,
-					Src:  TagCashBasis,
+					Src:  tax.TagCashBasis,
 					Text: "Régimen especial del criterio de caja.",
 				},
 			},
@@ -110,6 +110,14 @@ func TestInvoiceDocumentScenarios(t *testing.T) {
 	assert.Equal(t, i.Notes[0].Src, es.TagTravelAgency)
 	assert.Equal(t, i.Notes[0].Text, "Régimen especial de las agencias de viajes.")
 
+	i = testInvoiceStandard(t)
+	i.SetTags(tax.TagCashBasis)
+	require.NoError(t, i.Calculate())
+	require.NoError(t, i.Validate())
+	require.Len(t, i.Notes, 1)
+	assert.Equal(t, i.Notes[0].Text, "Régimen especial del criterio de caja.")
+	assert.True(t, i.Totals.CashBasis)
+
 	i = testInvoiceSimplified(t)
 	require.NoError(t, i.Calculate())
 	require.NoError(t, i.Validate())
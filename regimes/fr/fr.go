@@ -21,7 +21,7 @@ const (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("FR", New)
 }
 
 // New provides the tax region definition
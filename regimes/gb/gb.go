@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("GB", New, altCountryCodes...)
 }
 
 // Identification code types unique to the United Kingdom.
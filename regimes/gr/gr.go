@@ -10,7 +10,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("EL", New, "GR")
 }
 
 // Official IAPR codes to include in stamps.
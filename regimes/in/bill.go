@@ -1,6 +1,17 @@
 package in
 
-import "github.com/invopop/gobl/cbc"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
 
 const (
 	// ChargeKeyCompensationCess is used for addtional charges added to an invoice for the special
@@ -11,3 +22,193 @@ const (
 	// harmful to the environment or society.
 	ChargeKeyCompensationCess cbc.Key = "compensation-cess"
 )
+
+// unionTerritoryStateCodes lists the GST state codes of union territories
+// without their own legislature, where UTGST is applied instead of SGST.
+var unionTerritoryStateCodes = []cbc.Code{
+	"04", // Chandigarh
+	"25", // Daman and Diu
+	"26", // Dadra and Nagar Haveli and Daman and Diu
+	"31", // Lakshadweep
+	"35", // Andaman and Nicobar Islands
+	"38", // Ladakh
+}
+
+// supplyPlace describes where a supply takes place in relation to the
+// supplier, which determines the GST categories to apply.
+type supplyPlace struct {
+	interstate bool
+	territory  bool // intra-state supply inside a union territory
+}
+
+// normalizeBillInvoice splits or merges the GST applied to each line
+// according to the state codes of the supplier and customer. Intra-state
+// supplies use CGST with SGST or UTGST at half the rate each, while
+// inter-state supplies and exports use IGST at the combined rate.
+func normalizeBillInvoice(inv *bill.Invoice) {
+	sp := invoiceSupplyPlace(inv)
+	if sp == nil {
+		return
+	}
+	for _, l := range inv.Lines {
+		if l == nil {
+			continue
+		}
+		if sp.interstate {
+			l.Taxes = mergeGST(l.Taxes)
+		} else {
+			l.Taxes = splitGST(l.Taxes, sp.territory)
+		}
+	}
+}
+
+func validateBillInvoice(inv *bill.Invoice) error {
+	sp := invoiceSupplyPlace(inv)
+	if sp == nil {
+		return nil
+	}
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Lines,
+			validation.Each(
+				validation.By(func(value any) error {
+					l, ok := value.(*bill.Line)
+					if !ok || l == nil {
+						return nil
+					}
+					return validation.ValidateStruct(l,
+						validation.Field(&l.Taxes,
+							validation.By(validateGSTSupply(sp)),
+							validation.Skip,
+						),
+					)
+				}),
+			),
+			validation.Skip,
+		),
+	)
+}
+
+func validateGSTSupply(sp *supplyPlace) func(value any) error {
+	return func(value any) error {
+		set, ok := value.(tax.Set)
+		if !ok {
+			return nil
+		}
+		if sp.interstate {
+			for _, cat := range []cbc.Code{TaxCategoryCGST, TaxCategorySGST, TaxCategoryUTGST} {
+				if set.Get(cat) != nil {
+					return fmt.Errorf("%s not allowed for inter-state supplies, use IGST", cat)
+				}
+			}
+			return nil
+		}
+		if set.Get(TaxCategoryIGST) != nil {
+			return errors.New("IGST not allowed for intra-state supplies, use CGST and SGST or UTGST")
+		}
+		if sp.territory && set.Get(TaxCategorySGST) != nil {
+			return errors.New("SGST not allowed in union territories, use UTGST")
+		}
+		if !sp.territory && set.Get(TaxCategoryUTGST) != nil {
+			return errors.New("UTGST only allowed in union territories, use SGST")
+		}
+		return nil
+	}
+}
+
+// invoiceSupplyPlace determines if the supply is inter-state using the
+// state codes included in the GSTINs of the supplier and customer, or the
+// customer's country for exports. Nil is returned if the place of supply
+// cannot be determined.
+func invoiceSupplyPlace(inv *bill.Invoice) *supplyPlace {
+	sup := partyStateCode(inv.Supplier)
+	if sup == cbc.CodeEmpty {
+		return nil
+	}
+	if inv.Customer == nil || inv.Customer.TaxID == nil {
+		return nil
+	}
+	if c := inv.Customer.TaxID.Country; c != "" && c != l10n.IN.Tax() {
+		return &supplyPlace{interstate: true}
+	}
+	cus := partyStateCode(inv.Customer)
+	if cus == cbc.CodeEmpty {
+		return nil
+	}
+	if sup != cus {
+		return &supplyPlace{interstate: true}
+	}
+	return &supplyPlace{territory: sup.In(unionTerritoryStateCodes...)}
+}
+
+// partyStateCode provides the state code from the first two digits of
+// the party's GSTIN.
+func partyStateCode(p *org.Party) cbc.Code {
+	if p == nil || p.TaxID == nil || p.TaxID.Country != l10n.IN.Tax() {
+		return cbc.CodeEmpty
+	}
+	if len(p.TaxID.Code) < 2 {
+		return cbc.CodeEmpty
+	}
+	return p.TaxID.Code[:2]
+}
+
+// splitGST replaces any IGST combo with CGST and SGST or UTGST combos at
+// half the rate each.
+func splitGST(set tax.Set, territory bool) tax.Set {
+	igst := set.Get(TaxCategoryIGST)
+	if igst == nil || igst.Percent == nil {
+		return set
+	}
+	state := TaxCategorySGST
+	if territory {
+		state = TaxCategoryUTGST
+	}
+	half := halfPercentage(*igst.Percent)
+	ns := make(tax.Set, 0, len(set)+1)
+	for _, c := range set {
+		if c == igst {
+			ns = append(ns,
+				&tax.Combo{Category: TaxCategoryCGST, Percent: &half},
+				&tax.Combo{Category: state, Percent: num.NewPercentage(half.Value(), half.Exp())},
+			)
+			continue
+		}
+		ns = append(ns, c)
+	}
+	return ns
+}
+
+// mergeGST replaces CGST and SGST or UTGST combos with a single IGST
+// combo at the combined rate.
+func mergeGST(set tax.Set) tax.Set {
+	cgst := set.Get(TaxCategoryCGST)
+	state := set.Get(TaxCategorySGST)
+	if state == nil {
+		state = set.Get(TaxCategoryUTGST)
+	}
+	if cgst == nil || state == nil || cgst.Percent == nil || state.Percent == nil {
+		return set
+	}
+	sum := cgst.Percent.Base().Add(state.Percent.Base())
+	ns := make(tax.Set, 0, len(set))
+	for _, c := range set {
+		switch c {
+		case cgst:
+			ns = append(ns, &tax.Combo{
+				Category: TaxCategoryIGST,
+				Percent:  num.NewPercentage(sum.Value(), sum.Exp()),
+			})
+		case state:
+			// removed
+		default:
+			ns = append(ns, c)
+		}
+	}
+	return ns
+}
+
+func halfPercentage(p num.Percentage) num.Percentage {
+	b := p.Base()
+	b = b.Rescale(b.Exp() + 1).Divide(num.MakeAmount(2, 0))
+	return num.MakePercentage(b.Value(), b.Exp())
+}
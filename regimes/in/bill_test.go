@@ -0,0 +1,109 @@
+package in_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/regimes/in"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceGSTSupply(t *testing.T) {
+	t.Run("intra-state", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Len(t, inv.Lines[0].Taxes, 2)
+		assert.Equal(t, "18.00", inv.Totals.Tax.String())
+	})
+
+	t.Run("intra-state split from IGST", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Lines[0].Taxes = tax.Set{
+			{Category: in.TaxCategoryIGST, Percent: num.NewPercentage(18, 2)},
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		lt := inv.Lines[0].Taxes
+		require.Len(t, lt, 2)
+		assert.Equal(t, in.TaxCategoryCGST, lt[0].Category)
+		assert.Equal(t, "9.0%", lt[0].Percent.String())
+		assert.Equal(t, in.TaxCategorySGST, lt[1].Category)
+		assert.Equal(t, "9.0%", lt[1].Percent.String())
+		assert.Equal(t, "18.00", inv.Totals.Tax.String())
+	})
+
+	t.Run("union territory", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Supplier.TaxID.Code = "04AAPFU0939F1Z3"
+		inv.Customer.TaxID.Code = "04AAPFU0939F1Z3"
+		inv.Lines[0].Taxes = tax.Set{
+			{Category: in.TaxCategoryIGST, Percent: num.NewPercentage(5, 2)},
+		}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		lt := inv.Lines[0].Taxes
+		require.Len(t, lt, 2)
+		assert.Equal(t, in.TaxCategoryCGST, lt[0].Category)
+		assert.Equal(t, "2.5%", lt[0].Percent.String())
+		assert.Equal(t, in.TaxCategoryUTGST, lt[1].Category)
+		assert.Equal(t, "2.5%", lt[1].Percent.String())
+	})
+
+	t.Run("inter-state", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Customer.TaxID.Code = "29AAPFU0939F1ZR"
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		lt := inv.Lines[0].Taxes
+		require.Len(t, lt, 1)
+		assert.Equal(t, in.TaxCategoryIGST, lt[0].Category)
+		assert.Equal(t, "18%", lt[0].Percent.String())
+		assert.Equal(t, "18.00", inv.Totals.Tax.String())
+	})
+
+	t.Run("export", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Customer.TaxID = &tax.Identity{Country: "US"}
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		lt := inv.Lines[0].Taxes
+		require.Len(t, lt, 1)
+		assert.Equal(t, in.TaxCategoryIGST, lt[0].Category)
+	})
+
+	t.Run("unknown place of supply", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Customer.TaxID = nil
+		require.NoError(t, inv.Calculate())
+		require.NoError(t, inv.Validate())
+		assert.Len(t, inv.Lines[0].Taxes, 2)
+	})
+
+	t.Run("invalid categories", func(t *testing.T) {
+		inv := testInvoiceStandard(t)
+		inv.Customer.TaxID.Code = "29AAPFU0939F1ZR"
+		require.NoError(t, inv.Calculate())
+		inv.Lines[0].Taxes = append(inv.Lines[0].Taxes,
+			&tax.Combo{Category: in.TaxCategoryCGST, Percent: num.NewPercentage(9, 2)},
+		)
+		err := inv.Validate()
+		assert.ErrorContains(t, err, "CGST not allowed for inter-state supplies, use IGST")
+
+		inv = testInvoiceStandard(t)
+		require.NoError(t, inv.Calculate())
+		inv.Lines[0].Taxes = append(inv.Lines[0].Taxes,
+			&tax.Combo{Category: in.TaxCategoryIGST, Percent: num.NewPercentage(18, 2)},
+		)
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "IGST not allowed for intra-state supplies")
+
+		inv = testInvoiceStandard(t)
+		require.NoError(t, inv.Calculate())
+		inv.Lines[0].Taxes[1].Category = in.TaxCategoryUTGST
+		err = inv.Validate()
+		assert.ErrorContains(t, err, "UTGST only allowed in union territories, use SGST")
+	})
+}
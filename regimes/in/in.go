@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("IN", New)
 }
 
 // New provides the tax region definition for India.
@@ -45,6 +45,8 @@ func New() *tax.RegimeDef {
 // Validate function assesses the document type to determine if validation is required.
 func Validate(doc interface{}) error {
 	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateBillInvoice(obj)
 	case *tax.Identity:
 		return validateTaxIdentity(obj)
 	case *org.Identity:
@@ -58,6 +60,8 @@ func Validate(doc interface{}) error {
 // Normalize attempts to clean up the object passed to it.
 func Normalize(doc interface{}) {
 	switch obj := doc.(type) {
+	case *bill.Invoice:
+		normalizeBillInvoice(obj)
 	case *tax.Identity:
 		normalizeTaxIdentity(obj)
 	case *org.Identity:
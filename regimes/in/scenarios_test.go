@@ -48,11 +48,11 @@ func testInvoiceStandard(t *testing.T) *bill.Invoice {
 				Taxes: tax.Set{
 					{
 						Category: "CGST",
-						Percent:  num.NewPercentage(9, 0),
+						Percent:  num.NewPercentage(9, 2),
 					},
 					{
 						Category: "SGST",
-						Percent:  num.NewPercentage(9, 0),
+						Percent:  num.NewPercentage(9, 2),
 					},
 				},
 			},
@@ -0,0 +1,47 @@
+package jp
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// validateInvoice applies the requirements of the Qualified Invoice System
+// (適格請求書等保存方式) introduced on 1 October 2023 to invoices issued by
+// registered suppliers. The per-rate subtotals and tax amounts required are
+// always included in the invoice's tax totals.
+func validateInvoice(inv *bill.Invoice) error {
+	if !qualifiedIssuer(inv.Supplier) {
+		return nil
+	}
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Customer,
+			validation.When(
+				!inv.HasTags(tax.TagSimplified),
+				validation.Required.Error("required for qualified invoices, unless simplified"),
+				validation.By(validateInvoiceCustomer),
+			),
+			validation.Skip,
+		),
+	)
+}
+
+func validateInvoiceCustomer(value any) error {
+	p, ok := value.(*org.Party)
+	if !ok || p == nil {
+		return nil
+	}
+	return validation.ValidateStruct(p,
+		validation.Field(&p.Name, validation.Required),
+	)
+}
+
+// qualifiedIssuer returns true if the party is a Japanese supplier with
+// a qualified invoice issuer registration number.
+func qualifiedIssuer(p *org.Party) bool {
+	return p != nil && p.TaxID != nil &&
+		p.TaxID.Country == l10n.JP.Tax() &&
+		p.TaxID.Code != ""
+}
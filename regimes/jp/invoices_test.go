@@ -0,0 +1,110 @@
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validInvoice() *bill.Invoice {
+	return &bill.Invoice{
+		Series: "TEST",
+		Code:   "0002",
+		Supplier: &org.Party{
+			Name: "株式会社テスト",
+			TaxID: &tax.Identity{
+				Country: "JP",
+				Code:    "T1180301018771",
+			},
+		},
+		Customer: &org.Party{
+			Name: "株式会社サンプル",
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(3, 0),
+				Item: &org.Item{
+					Name:  "コーヒー豆",
+					Price: num.NewAmount(1234, 0),
+				},
+				Taxes: tax.Set{
+					{
+						Category: "VAT",
+						Rate:     "reduced",
+					},
+				},
+			},
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "コーヒーカップ",
+					Price: num.NewAmount(2999, 0),
+				},
+				Taxes: tax.Set{
+					{
+						Category: "VAT",
+						Rate:     "general",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceCalculation(t *testing.T) {
+	inv := validInvoice()
+	require.NoError(t, inv.Calculate())
+	require.NoError(t, inv.Validate())
+	assert.Equal(t, "JPY", inv.Currency.String())
+
+	// one subtotal and tax amount per rate
+	rates := inv.Totals.Taxes.Categories[0].Rates
+	require.Len(t, rates, 2)
+	assert.Equal(t, "8%", rates[0].Percent.String())
+	assert.Equal(t, "3702", rates[0].Base.String())
+	assert.Equal(t, "296", rates[0].Amount.String())
+	assert.Equal(t, "10%", rates[1].Percent.String())
+	assert.Equal(t, "2999", rates[1].Base.String())
+	assert.Equal(t, "300", rates[1].Amount.String())
+	assert.Equal(t, "7297", inv.Totals.Payable.String())
+}
+
+func TestInvoiceValidation(t *testing.T) {
+	t.Run("missing customer", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Customer = nil
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "customer: required for qualified invoices, unless simplified")
+
+		inv.SetTags(tax.TagSimplified)
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+	})
+
+	t.Run("missing customer name", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Customer.Name = ""
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "customer: (name: cannot be blank.)")
+	})
+
+	t.Run("not a qualified issuer", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Supplier.TaxID.Code = ""
+		inv.Customer = nil
+		require.NoError(t, inv.Calculate())
+		assert.NoError(t, inv.Validate())
+	})
+
+	t.Run("line rounding", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Tax = &bill.Tax{Rounding: tax.RoundingRuleLine}
+		require.NoError(t, inv.Calculate())
+		assert.ErrorContains(t, inv.Validate(), "tax: (rounding: not allowed by regime 'JP'.)")
+	})
+}
@@ -0,0 +1,71 @@
+// Package jp provides the tax region definition for Japan.
+package jp
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/tax"
+)
+
+func init() {
+	tax.RegisterRegimeDefLoader("JP", New)
+}
+
+// New provides the tax region definition for JP.
+func New() *tax.RegimeDef {
+	return &tax.RegimeDef{
+		Country:   "JP",
+		Currency:  currency.JPY,
+		TaxScheme: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "Japan",
+			i18n.JA: "日本",
+		},
+		TimeZone: "Asia/Tokyo",
+		// Amounts are presented in whole yen, so lines are rounded before
+		// calculating the per-rate subtotals.
+		CalculatorRoundingRule: tax.RoundingRuleCurrency,
+		// Qualified invoices may only round the consumption tax once per
+		// rate, so taxes cannot be calculated for each line.
+		RoundingRules: []cbc.Key{
+			tax.RoundingRulePrecise,
+			tax.RoundingRuleCurrency,
+			tax.RoundingRuleHalfEven,
+		},
+		Scenarios: []*tax.ScenarioSet{
+			bill.InvoiceScenarios(),
+		},
+		Corrections: []*tax.CorrectionDefinition{
+			{
+				Schema: bill.ShortSchemaInvoice,
+				Types: []cbc.Key{
+					bill.InvoiceTypeCreditNote,
+				},
+			},
+		},
+		Validator:  Validate,
+		Normalizer: Normalize,
+		Categories: taxCategories,
+	}
+}
+
+// Validate checks the document type and determines if it can be validated.
+func Validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	case *tax.Identity:
+		return validateTaxIdentity(obj)
+	}
+	return nil
+}
+
+// Normalize attempts to clean up the object passed to it.
+func Normalize(doc any) {
+	switch obj := doc.(type) {
+	case *tax.Identity:
+		normalizeTaxIdentity(obj)
+	}
+}
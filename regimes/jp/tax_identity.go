@@ -0,0 +1,62 @@
+package jp
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// Qualified invoice issuers are assigned a registration number
+// (適格請求書発行事業者登録番号) consisting of the letter "T" followed by
+// 13 digits. For corporations, the digits are the corporate number
+// (法人番号), whose first digit is a check digit.
+var registrationNumberRegex = regexp.MustCompile(`^T\d{13}$`)
+
+// normalizeTaxIdentity removes the country prefix and ensures the "T"
+// prefix is present when the code contains only the 13 digits.
+func normalizeTaxIdentity(tID *tax.Identity) {
+	if tID == nil {
+		return
+	}
+	tax.NormalizeIdentity(tID)
+	if len(tID.Code) == 13 && cbc.NormalizeNumericalCode(tID.Code) == tID.Code {
+		tID.Code = "T" + tID.Code
+	}
+}
+
+// validateTaxIdentity checks to ensure the qualified invoice issuer
+// registration number looks okay.
+func validateTaxIdentity(tID *tax.Identity) error {
+	return validation.ValidateStruct(tID,
+		validation.Field(&tID.Code, validation.By(validateRegistrationNumber)),
+	)
+}
+
+func validateRegistrationNumber(value any) error {
+	code, ok := value.(cbc.Code)
+	if !ok || code == cbc.CodeEmpty {
+		return nil
+	}
+	val := code.String()
+	if !registrationNumberRegex.MatchString(val) {
+		return errors.New("must be a 'T' followed by 13 digits")
+	}
+	// The check digit is calculated from the remaining 12 digits, starting
+	// from the right, with weights alternating between 1 and 2.
+	digits := val[2:]
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+		}
+		sum += d
+	}
+	if int(val[1]-'0') != 9-sum%9 {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/regimes/jp"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTaxIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		code cbc.Code
+		err  string
+	}{
+		{name: "good 1", code: "T1180301018771"},
+		{name: "good 2", code: "T7000012050002"},
+		{name: "empty", code: ""},
+
+		{name: "missing prefix", code: "1180301018771", err: "must be a 'T' followed by 13 digits"},
+		{name: "too short", code: "T118030101877", err: "must be a 'T' followed by 13 digits"},
+		{name: "too long", code: "T11803010187710", err: "must be a 'T' followed by 13 digits"},
+		{name: "bad checksum", code: "T1180301018772", err: "checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tID := &tax.Identity{Country: "JP", Code: tt.code}
+			err := jp.Validate(tID)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTaxIdentity(t *testing.T) {
+	tests := []struct {
+		code     cbc.Code
+		expected cbc.Code
+	}{
+		{code: "T1180301018771", expected: "T1180301018771"},
+		{code: "t1180-3010-1877-1", expected: "T1180301018771"},
+		{code: "1180301018771", expected: "T1180301018771"},
+		{code: "JP T1180301018771", expected: "T1180301018771"},
+		{code: "118030101877", expected: "118030101877"},
+	}
+	for _, tt := range tests {
+		tID := &tax.Identity{Country: "JP", Code: tt.code}
+		jp.Normalize(tID)
+		assert.Equal(t, tt.expected, tID.Code)
+	}
+}
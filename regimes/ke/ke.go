@@ -0,0 +1,60 @@
+// Package ke provides the tax region definition for Kenya.
+package ke
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/tax"
+)
+
+func init() {
+	tax.RegisterRegimeDefLoader("KE", New)
+}
+
+// New provides the tax region definition for KE.
+func New() *tax.RegimeDef {
+	return &tax.RegimeDef{
+		Country:   "KE",
+		Currency:  currency.KES,
+		TaxScheme: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "Kenya",
+			i18n.SW: "Kenya",
+		},
+		TimeZone: "Africa/Nairobi",
+		Scenarios: []*tax.ScenarioSet{
+			bill.InvoiceScenarios(),
+		},
+		Corrections: []*tax.CorrectionDefinition{
+			{
+				Schema: bill.ShortSchemaInvoice,
+				Types: []cbc.Key{
+					bill.InvoiceTypeCreditNote,
+				},
+				ReasonRequired: true,
+			},
+		},
+		Validator:  Validate,
+		Normalizer: Normalize,
+		Categories: taxCategories,
+	}
+}
+
+// Validate checks the document type and determines if it can be validated.
+func Validate(doc any) error {
+	switch obj := doc.(type) {
+	case *tax.Identity:
+		return validateTaxIdentity(obj)
+	}
+	return nil
+}
+
+// Normalize attempts to clean up the object passed to it.
+func Normalize(doc any) {
+	switch obj := doc.(type) {
+	case *tax.Identity:
+		tax.NormalizeIdentity(obj)
+	}
+}
@@ -0,0 +1,62 @@
+package ke
+
+import (
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+)
+
+var taxCategories = []*tax.CategoryDef{
+	//
+	// VAT
+	//
+	{
+		Code: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "VAT",
+		},
+		Title: i18n.String{
+			i18n.EN: "Value Added Tax",
+			i18n.SW: "Kodi ya Ongezeko la Thamani",
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "Value Added Tax | Kenya Revenue Authority",
+				},
+				URL: "https://www.kra.go.ke/individual/filing-paying/types-of-taxes/value-added-tax",
+			},
+		},
+		Retained: false,
+		Keys:     tax.GlobalVATKeys(),
+		Rates: []*tax.RateDef{
+			{
+				Keys: []cbc.Key{tax.KeyStandard},
+				Rate: tax.RateGeneral,
+				Name: i18n.String{
+					i18n.EN: "General Rate",
+				},
+				Description: i18n.String{
+					i18n.EN: "Applies to most goods and services unless specified otherwise.",
+				},
+				Values: []*tax.RateValueDef{
+					{
+						Since:   cal.NewDate(2021, 1, 1),
+						Percent: num.MakePercentage(16, 2),
+					},
+					{
+						// Temporary reduction during the COVID-19 pandemic.
+						Since:   cal.NewDate(2020, 4, 1),
+						Percent: num.MakePercentage(14, 2),
+					},
+					{
+						Since:   cal.NewDate(2013, 9, 2),
+						Percent: num.MakePercentage(16, 2),
+					},
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,33 @@
+package ke
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// KRA Personal Identification Numbers (PIN) are 11 characters long, starting
+// with "A" for individuals or "P" for non-individuals such as companies,
+// followed by 9 digits and a check letter.
+var pinRegex = regexp.MustCompile(`^[AP]\d{9}[A-Z]$`)
+
+// validateTaxIdentity checks to ensure the KRA PIN format is correct.
+func validateTaxIdentity(tID *tax.Identity) error {
+	return validation.ValidateStruct(tID,
+		validation.Field(&tID.Code, validation.By(validatePINCode)),
+	)
+}
+
+func validatePINCode(value any) error {
+	code, ok := value.(cbc.Code)
+	if !ok || code == cbc.CodeEmpty {
+		return nil
+	}
+	if !pinRegex.MatchString(code.String()) {
+		return errors.New("must be an 'A' or 'P' followed by 9 digits and a letter")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package ke_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/regimes/ke"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTaxIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		code cbc.Code
+		err  string
+	}{
+		{name: "company", code: "P051234567Q"},
+		{name: "individual", code: "A012345678Z"},
+		{name: "empty", code: ""},
+
+		{name: "bad prefix", code: "B051234567Q", err: "must be an 'A' or 'P' followed by 9 digits and a letter"},
+		{name: "too short", code: "P05123456Q", err: "must be an 'A' or 'P' followed by 9 digits and a letter"},
+		{name: "missing letter", code: "P0512345678", err: "must be an 'A' or 'P' followed by 9 digits and a letter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tID := &tax.Identity{Country: "KE", Code: tt.code}
+			err := ke.Validate(tID)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTaxIdentity(t *testing.T) {
+	tID := &tax.Identity{Country: "KE", Code: "p051-234-567q"}
+	ke.Normalize(tID)
+	assert.Equal(t, "P051234567Q", tID.Code.String())
+}
@@ -5,12 +5,21 @@ import (
 	"github.com/invopop/gobl/bill"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/head"
 	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/tax"
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("MX", New)
+	head.RegisterStampProviderDef(&head.StampProviderDef{
+		Key: StampSATUUID,
+		Name: i18n.String{
+			i18n.EN: "SAT Fiscal Folio",
+			i18n.ES: "Folio Fiscal del SAT",
+		},
+		Pattern: `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+	})
 }
 
 // Official SAT codes to include in stamps.
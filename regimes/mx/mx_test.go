@@ -0,0 +1,16 @@
+package mx_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/regimes/mx"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampFormat(t *testing.T) {
+	s := &head.Stamp{Provider: mx.StampSATUUID, Value: "1fac4464-1111-0000-1111-cd37179db12e"}
+	assert.NoError(t, s.Validate())
+	s.Value = "1fac4464"
+	assert.ErrorContains(t, s.Validate(), "val: invalid format")
+}
@@ -0,0 +1,81 @@
+package no
+
+import (
+	"errors"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/validation"
+)
+
+const (
+	// RegistrationOffice is the name of the Norwegian Register of Business
+	// Enterprises, which limited companies and other enterprises registered
+	// in it must state on their invoices.
+	RegistrationOffice = "Foretaksregisteret"
+
+	// NoteSrcRegistration is used as the source of the legal note added to
+	// invoices issued by suppliers registered in the Foretaksregisteret.
+	NoteSrcRegistration cbc.Key = "foretaksregisteret"
+)
+
+// normalizeInvoice will ensure that suppliers registered in the
+// Foretaksregisteret include the mandatory legal note.
+func normalizeInvoice(inv *bill.Invoice) {
+	if !supplierRegistered(inv.Supplier) {
+		return
+	}
+	if inv.Supplier.Registration.Office == "" {
+		inv.Supplier.Registration.Office = RegistrationOffice
+	}
+	if registrationNote(inv) != nil {
+		return
+	}
+	inv.Notes = append(inv.Notes, &org.Note{
+		Key:  org.NoteKeyLegal,
+		Src:  NoteSrcRegistration,
+		Text: RegistrationOffice,
+	})
+}
+
+func validateInvoice(inv *bill.Invoice) error {
+	return validation.ValidateStruct(inv,
+		validation.Field(&inv.Notes,
+			validation.When(
+				supplierRegistered(inv.Supplier),
+				validation.Required.Error("missing Foretaksregisteret legal note"),
+				validation.By(validateRegistrationNote(inv)),
+			),
+			validation.Skip,
+		),
+	)
+}
+
+func validateRegistrationNote(inv *bill.Invoice) func(any) error {
+	return func(_ any) error {
+		if registrationNote(inv) == nil {
+			return errors.New("missing Foretaksregisteret legal note")
+		}
+		return nil
+	}
+}
+
+// supplierRegistered returns true when the supplier is a Norwegian party
+// with registration details.
+func supplierRegistered(p *org.Party) bool {
+	if p == nil || p.Registration == nil {
+		return false
+	}
+	return p.TaxID != nil && p.TaxID.Country == l10n.NO.Tax()
+}
+
+func registrationNote(inv *bill.Invoice) *org.Note {
+	for _, n := range inv.Notes {
+		if n != nil && n.Key == org.NoteKeyLegal && n.Src == NoteSrcRegistration {
+			return n
+		}
+	}
+	return nil
+}
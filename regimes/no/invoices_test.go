@@ -0,0 +1,104 @@
+package no_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/no"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validInvoice() *bill.Invoice {
+	return &bill.Invoice{
+		Series: "TEST",
+		Code:   "0002",
+		Supplier: &org.Party{
+			Name: "Test Supplier AS",
+			TaxID: &tax.Identity{
+				Country: "NO",
+				Code:    "974760673",
+			},
+		},
+		Customer: &org.Party{
+			Name: "Test Customer AS",
+			TaxID: &tax.Identity{
+				Country: "NO",
+				Code:    "923609016",
+			},
+		},
+		Lines: []*bill.Line{
+			{
+				Quantity: num.MakeAmount(1, 0),
+				Item: &org.Item{
+					Name:  "bogus",
+					Price: num.NewAmount(10000, 2),
+				},
+				Taxes: tax.Set{
+					{
+						Category: "VAT",
+						Rate:     "general",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInvoiceCalculation(t *testing.T) {
+	inv := validInvoice()
+	require.NoError(t, inv.Calculate())
+	assert.Equal(t, "NOK", inv.Currency.String())
+	assert.Equal(t, "25%", inv.Lines[0].Taxes[0].Percent.String())
+	assert.Equal(t, "125.00", inv.Totals.Payable.String())
+	assert.Empty(t, inv.Notes)
+	assert.NoError(t, inv.Validate())
+}
+
+func TestInvoiceRegistrationNote(t *testing.T) {
+	t.Run("added when registered", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Supplier.Registration = &org.Registration{}
+		require.NoError(t, inv.Calculate())
+		assert.Equal(t, no.RegistrationOffice, inv.Supplier.Registration.Office)
+		require.Len(t, inv.Notes, 1)
+		assert.Equal(t, org.NoteKeyLegal, inv.Notes[0].Key)
+		assert.Equal(t, no.NoteSrcRegistration, inv.Notes[0].Src)
+		assert.Equal(t, "Foretaksregisteret", inv.Notes[0].Text)
+		assert.NoError(t, inv.Validate())
+
+		// not duplicated
+		require.NoError(t, inv.Calculate())
+		assert.Len(t, inv.Notes, 1)
+	})
+
+	t.Run("missing note", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Supplier.Registration = &org.Registration{}
+		require.NoError(t, inv.Calculate())
+		inv.Notes = nil
+		assert.ErrorContains(t, inv.Validate(), "notes: missing Foretaksregisteret legal note")
+
+		inv.Notes = []*org.Note{{Key: org.NoteKeyGeneral, Text: "Thanks"}}
+		assert.ErrorContains(t, inv.Validate(), "notes: missing Foretaksregisteret legal note")
+	})
+
+	t.Run("foreign supplier", func(t *testing.T) {
+		inv := validInvoice()
+		inv.Regime = tax.WithRegime("NO")
+		inv.Supplier.TaxID = &tax.Identity{Country: "SE", Code: "556036079301"}
+		inv.Supplier.Registration = &org.Registration{}
+		require.NoError(t, inv.Calculate())
+		assert.Empty(t, inv.Notes)
+	})
+}
+
+func TestLowRateHistory(t *testing.T) {
+	inv := validInvoice()
+	inv.Lines[0].Taxes[0].Rate = tax.RateSuperReduced
+	require.NoError(t, inv.Calculate())
+	assert.Equal(t, "12%", inv.Lines[0].Taxes[0].Percent.String())
+}
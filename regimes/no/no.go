@@ -0,0 +1,68 @@
+// Package no provides the tax region definition for Norway.
+package no
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+func init() {
+	tax.RegisterRegimeDefLoader("NO", New)
+}
+
+// New provides the tax region definition for NO.
+func New() *tax.RegimeDef {
+	return &tax.RegimeDef{
+		Country:   "NO",
+		Currency:  currency.NOK,
+		TaxScheme: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "Norway",
+			i18n.NB: "Norge",
+		},
+		TimeZone: "Europe/Oslo",
+		Scenarios: []*tax.ScenarioSet{
+			invoiceScenarios,
+		},
+		Corrections: []*tax.CorrectionDefinition{
+			{
+				Schema: bill.ShortSchemaInvoice,
+				Types: []cbc.Key{
+					bill.InvoiceTypeCreditNote,
+				},
+			},
+		},
+		Validator:  Validate,
+		Normalizer: Normalize,
+		Categories: taxCategories,
+	}
+}
+
+// Validate checks the document type and determines if it can be validated.
+func Validate(doc any) error {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		return validateInvoice(obj)
+	case *tax.Identity:
+		return validateTaxIdentity(obj)
+	case *org.Identity:
+		return validateOrgIdentity(obj)
+	}
+	return nil
+}
+
+// Normalize attempts to clean up the object passed to it.
+func Normalize(doc any) {
+	switch obj := doc.(type) {
+	case *bill.Invoice:
+		normalizeInvoice(obj)
+	case *tax.Identity:
+		normalizeTaxIdentity(obj)
+	case *org.Identity:
+		normalizeOrgIdentity(obj)
+	}
+}
@@ -0,0 +1,38 @@
+package no
+
+import (
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/validation"
+)
+
+const (
+	// IdentityTypeOrgNr represents the Norwegian organisation number
+	// (organisasjonsnummer) assigned to every legal entity by the
+	// Brønnøysund Register Centre. It is the same number used for VAT
+	// purposes, but is required on invoices even by businesses that are
+	// not VAT registered.
+	IdentityTypeOrgNr cbc.Code = "ORGNR"
+)
+
+func normalizeOrgIdentity(id *org.Identity) {
+	if id == nil || id.Type != IdentityTypeOrgNr {
+		return
+	}
+	id.Code = cbc.NormalizeNumericalCode(id.Code)
+}
+
+func validateOrgIdentity(id *org.Identity) error {
+	if id == nil {
+		return nil
+	}
+	return validation.ValidateStruct(id,
+		validation.Field(&id.Code,
+			validation.When(
+				id.Type == IdentityTypeOrgNr,
+				validation.By(validateOrgNumber),
+			),
+			validation.Skip,
+		),
+	)
+}
@@ -0,0 +1,102 @@
+package no
+
+import (
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+)
+
+var taxCategories = []*tax.CategoryDef{
+	//
+	// VAT
+	//
+	{
+		Code: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "VAT",
+			i18n.NB: "MVA",
+		},
+		Title: i18n.String{
+			i18n.EN: "Value Added Tax",
+			i18n.NB: "Merverdiavgift",
+		},
+		Sources: []*cbc.Source{
+			{
+				Title: i18n.String{
+					i18n.EN: "VAT rates | The Norwegian Tax Administration",
+					i18n.NB: "Merverdiavgiftssatser | Skatteetaten",
+				},
+				URL: "https://www.skatteetaten.no/en/rates/value-added-tax/",
+			},
+		},
+		Retained: false,
+		Keys:     tax.GlobalVATKeys(),
+		Rates: []*tax.RateDef{
+			{
+				Keys: []cbc.Key{tax.KeyStandard},
+				Rate: tax.RateGeneral,
+				Name: i18n.String{
+					i18n.EN: "General Rate",
+					i18n.NB: "Alminnelig sats",
+				},
+				Description: i18n.String{
+					i18n.EN: "Applies to most goods and services unless specified otherwise.",
+					i18n.NB: "Gjelder for de fleste varer og tjenester.",
+				},
+				Values: []*tax.RateValueDef{
+					{
+						Since:   cal.NewDate(2005, 1, 1),
+						Percent: num.MakePercentage(25, 2),
+					},
+				},
+			},
+			{
+				Keys: []cbc.Key{tax.KeyStandard},
+				Rate: tax.RateReduced,
+				Name: i18n.String{
+					i18n.EN: "Reduced Rate",
+					i18n.NB: "Redusert sats",
+				},
+				Description: i18n.String{
+					i18n.EN: "Applies to food and drink, excluding alcohol, tobacco, and water supplies.",
+					i18n.NB: "Gjelder for næringsmidler, unntatt alkohol, tobakk og vannforsyning.",
+				},
+				Values: []*tax.RateValueDef{
+					{
+						Since:   cal.NewDate(2012, 1, 1),
+						Percent: num.MakePercentage(15, 2),
+					},
+				},
+			},
+			{
+				Keys: []cbc.Key{tax.KeyStandard},
+				Rate: tax.RateSuperReduced,
+				Name: i18n.String{
+					i18n.EN: "Low Rate",
+					i18n.NB: "Lav sats",
+				},
+				Description: i18n.String{
+					i18n.EN: "Applies to passenger transport, accommodation, cinema tickets, and some cultural and sporting events.",
+					i18n.NB: "Gjelder for persontransport, overnatting, kinobilletter og enkelte kultur- og idrettsarrangementer.",
+				},
+				Values: []*tax.RateValueDef{
+					{
+						Since:   cal.NewDate(2021, 10, 1),
+						Percent: num.MakePercentage(12, 2),
+					},
+					{
+						// Temporary reduction during the COVID-19 pandemic.
+						Since:   cal.NewDate(2020, 4, 1),
+						Percent: num.MakePercentage(6, 2),
+					},
+					{
+						Since:   cal.NewDate(2018, 1, 1),
+						Percent: num.MakePercentage(12, 2),
+					},
+				},
+			},
+		},
+	},
+}
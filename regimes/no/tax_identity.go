@@ -0,0 +1,60 @@
+package no
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+// Norwegian VAT numbers are the 9 digit organisation number
+// (organisasjonsnummer) of the business, usually presented with the
+// "NO" prefix and "MVA" suffix, e.g. "NO 974 760 673 MVA".
+var orgNumberRegex = regexp.MustCompile(`^\d{9}$`)
+
+// orgNumberWeights are applied to the first 8 digits of the organisation
+// number to calculate the mod 11 check digit.
+var orgNumberWeights = []int{3, 2, 7, 6, 5, 4, 3, 2}
+
+// normalizeTaxIdentity removes the country prefix and the "MVA" suffix
+// from the code.
+func normalizeTaxIdentity(tID *tax.Identity) {
+	if tID == nil {
+		return
+	}
+	tax.NormalizeIdentity(tID)
+	tID.Code = cbc.Code(strings.TrimSuffix(tID.Code.String(), "MVA"))
+}
+
+// validateTaxIdentity checks to ensure the NO code looks okay.
+func validateTaxIdentity(tID *tax.Identity) error {
+	return validation.ValidateStruct(tID,
+		validation.Field(&tID.Code, validation.By(validateOrgNumber)),
+	)
+}
+
+func validateOrgNumber(value any) error {
+	code, ok := value.(cbc.Code)
+	if !ok || code == cbc.CodeEmpty {
+		return nil
+	}
+	val := code.String()
+	if !orgNumberRegex.MatchString(val) {
+		return errors.New("must be a 9-digit number")
+	}
+	sum := 0
+	for i, w := range orgNumberWeights {
+		sum += int(val[i]-'0') * w
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	if check == 10 || check != int(val[8]-'0') {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package no_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/regimes/no"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTaxIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		code cbc.Code
+		err  string
+	}{
+		{name: "good 1", code: "974760673"},
+		{name: "good 2", code: "923609016"},
+		{name: "good 3", code: "982463718"},
+		{name: "empty", code: ""},
+
+		{name: "too short", code: "97476067", err: "must be a 9-digit number"},
+		{name: "too long", code: "9747606731", err: "must be a 9-digit number"},
+		{name: "non-numeric", code: "97476067A", err: "must be a 9-digit number"},
+		{name: "bad checksum", code: "974760674", err: "checksum mismatch"},
+		{name: "check digit 10", code: "100000060", err: "checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tID := &tax.Identity{Country: "NO", Code: tt.code}
+			err := no.Validate(tID)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTaxIdentity(t *testing.T) {
+	tests := []struct {
+		code     cbc.Code
+		expected cbc.Code
+	}{
+		{code: "974760673", expected: "974760673"},
+		{code: "NO 974 760 673 MVA", expected: "974760673"},
+		{code: "974.760.673mva", expected: "974760673"},
+	}
+	for _, tt := range tests {
+		tID := &tax.Identity{Country: "NO", Code: tt.code}
+		no.Normalize(tID)
+		assert.Equal(t, tt.expected, tID.Code)
+	}
+}
+
+func TestOrgIdentity(t *testing.T) {
+	id := &org.Identity{Type: no.IdentityTypeOrgNr, Code: "974 760 673"}
+	no.Normalize(id)
+	assert.Equal(t, "974760673", id.Code.String())
+	assert.NoError(t, no.Validate(id))
+
+	id.Code = "974760674"
+	assert.ErrorContains(t, no.Validate(id), "code: checksum mismatch")
+
+	id = &org.Identity{Type: "OTHER", Code: "974760674"}
+	assert.NoError(t, no.Validate(id))
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("PL", New)
 }
 
 // New instantiates a new Polish regime.
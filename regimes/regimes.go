@@ -20,9 +20,13 @@ import (
 	_ "github.com/invopop/gobl/regimes/gr"
 	_ "github.com/invopop/gobl/regimes/in"
 	_ "github.com/invopop/gobl/regimes/it"
+	_ "github.com/invopop/gobl/regimes/jp"
+	_ "github.com/invopop/gobl/regimes/ke"
 	_ "github.com/invopop/gobl/regimes/mx"
 	_ "github.com/invopop/gobl/regimes/nl"
+	_ "github.com/invopop/gobl/regimes/no"
 	_ "github.com/invopop/gobl/regimes/pl"
 	_ "github.com/invopop/gobl/regimes/pt"
+	_ "github.com/invopop/gobl/regimes/sa"
 	_ "github.com/invopop/gobl/regimes/us"
 )
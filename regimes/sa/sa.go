@@ -0,0 +1,61 @@
+// Package sa provides the tax region definition for Saudi Arabia.
+package sa
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/currency"
+	"github.com/invopop/gobl/i18n"
+	"github.com/invopop/gobl/tax"
+)
+
+func init() {
+	tax.RegisterRegimeDefLoader("SA", New)
+}
+
+// New provides the tax region definition for SA.
+func New() *tax.RegimeDef {
+	return &tax.RegimeDef{
+		Country:   "SA",
+		Currency:  currency.SAR,
+		TaxScheme: tax.CategoryVAT,
+		Name: i18n.String{
+			i18n.EN: "Saudi Arabia",
+			i18n.AR: "المملكة العربية السعودية",
+		},
+		TimeZone: "Asia/Riyadh",
+		Scenarios: []*tax.ScenarioSet{
+			invoiceScenarios,
+		},
+		Corrections: []*tax.CorrectionDefinition{
+			{
+				Schema: bill.ShortSchemaInvoice,
+				Types: []cbc.Key{
+					bill.InvoiceTypeCreditNote,
+					bill.InvoiceTypeDebitNote,
+				},
+				ReasonRequired: true,
+			},
+		},
+		Validator:  Validate,
+		Normalizer: Normalize,
+		Categories: taxCategories,
+	}
+}
+
+// Validate checks the document type and determines if it can be validated.
+func Validate(doc interface{}) error {
+	switch obj := doc.(type) {
+	case *tax.Identity:
+		return validateTaxIdentity(obj)
+	}
+	return nil
+}
+
+// Normalize attempts to clean up the object passed to it.
+func Normalize(doc any) {
+	switch obj := doc.(type) {
+	case *tax.Identity:
+		tax.NormalizeIdentity(obj)
+	}
+}
@@ -0,0 +1,32 @@
+package sa
+
+import (
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+var invoiceScenarios = &tax.ScenarioSet{
+	Schema: bill.ShortSchemaInvoice,
+	List: []*tax.Scenario{
+		// Reverse Charges
+		{
+			Tags: []cbc.Key{tax.TagReverseCharge},
+			Note: &tax.ScenarioNote{
+				Key:  org.NoteKeyLegal,
+				Src:  tax.TagReverseCharge,
+				Text: "Reverse Charge - التكليف العكسي",
+			},
+		},
+		// Simplified Tax Invoice
+		{
+			Tags: []cbc.Key{tax.TagSimplified},
+			Note: &tax.ScenarioNote{
+				Key:  org.NoteKeyLegal,
+				Src:  tax.TagSimplified,
+				Text: "Simplified Tax Invoice - فاتورة ضريبية مبسطة",
+			},
+		},
+	},
+}
@@ -0,0 +1,34 @@
+package sa
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/validation"
+)
+
+var (
+	// VAT registration numbers are 15 digits long, starting and ending
+	// with a 3.
+	tinRegex = regexp.MustCompile(`^3\d{13}3$`)
+)
+
+// validateTaxIdentity checks to ensure the Saudi VAT number format is correct.
+func validateTaxIdentity(tID *tax.Identity) error {
+	return validation.ValidateStruct(tID,
+		validation.Field(&tID.Code, validation.By(validateTINCode)),
+	)
+}
+
+func validateTINCode(value interface{}) error {
+	code, ok := value.(cbc.Code)
+	if !ok || code == "" {
+		return nil
+	}
+	if !tinRegex.MatchString(code.String()) {
+		return errors.New("must be a 15-digit number starting and ending with 3")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package sa_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/regimes/sa"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTaxIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		code cbc.Code
+		err  string
+	}{
+		{name: "good 1", code: "300000000000003"},
+		{name: "good 2", code: "399999999900003"},
+		{name: "good 3", code: "310122393500003"},
+		{name: "empty", code: ""},
+
+		{name: "too short", code: "30000000000003", err: "must be a 15-digit number starting and ending with 3"},
+		{name: "too long", code: "3000000000000003", err: "must be a 15-digit number starting and ending with 3"},
+		{name: "bad start", code: "100000000000003", err: "must be a 15-digit number starting and ending with 3"},
+		{name: "bad end", code: "300000000000001", err: "must be a 15-digit number starting and ending with 3"},
+		{name: "non-numeric", code: "3000000000ABC03", err: "must be a 15-digit number starting and ending with 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tID := &tax.Identity{Country: "SA", Code: tt.code}
+			err := sa.Validate(tID)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTaxIdentity(t *testing.T) {
+	tID := &tax.Identity{Country: "SA", Code: "3000-0000-0000-003"}
+	sa.Normalize(tID)
+	assert.Equal(t, "300000000000003", tID.Code.String())
+}
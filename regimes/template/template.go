@@ -15,7 +15,7 @@ import (
 )
 
 func init() {
-	tax.RegisterRegimeDef(New())
+	tax.RegisterRegimeDefLoader("XX", New)
 }
 
 func New() *tax.RegimeDef {
@@ -0,0 +1,297 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Loader is used to fetch the JSON Schema source of schema IDs that
+// are not defined in the external registry, typically as a result of
+// a `$ref` from an external schema.
+type Loader interface {
+	// Load provides the raw JSON Schema data for the ID, or an
+	// ErrUnknownSchema error if the loader is not able to handle it.
+	Load(id ID) ([]byte, error)
+}
+
+// LoaderFunc allows a regular function to be used as a Loader.
+type LoaderFunc func(id ID) ([]byte, error)
+
+// Load calls the underlying function.
+func (fn LoaderFunc) Load(id ID) ([]byte, error) {
+	return fn(id)
+}
+
+// FSLoader provides a loader that will try to find schemas whose IDs start
+// with the base inside the file system, using the rest of the ID as the
+// path to a `.json` file.
+func FSLoader(base ID, fsys fs.FS) Loader {
+	prefix := base.Base().String() + "/"
+	return LoaderFunc(func(id ID) ([]byte, error) {
+		s := id.Base().String()
+		if !strings.HasPrefix(s, prefix) {
+			return nil, ErrUnknownSchema
+		}
+		data, err := fs.ReadFile(fsys, strings.TrimPrefix(s, prefix)+".json")
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrUnknownSchema
+		}
+		return data, err
+	})
+}
+
+// HTTPLoader provides a loader that will fetch schemas using their ID as
+// the URL, caching the successful responses in memory. If no client is
+// provided, the default HTTP client will be used.
+func HTTPLoader(client *http.Client) Loader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var mu sync.Mutex
+	cache := make(map[ID][]byte)
+	return LoaderFunc(func(id ID) ([]byte, error) {
+		id = id.Base()
+		s := id.String()
+		if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+			return nil, ErrUnknownSchema
+		}
+		mu.Lock()
+		data, ok := cache[id]
+		mu.Unlock()
+		if ok {
+			return data, nil
+		}
+		res, err := client.Get(s)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close() //nolint:errcheck
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("loading '%s': status %d", s, res.StatusCode)
+		}
+		if data, err = io.ReadAll(res.Body); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		cache[id] = data
+		mu.Unlock()
+		return data, nil
+	})
+}
+
+// externals contains the JSON Schema sources of document types registered
+// at runtime, alongside the loaders used to resolve references. Compiled
+// schemas are kept and shared between goroutines, while a single compiler
+// ensures each referenced schema is only loaded once.
+type externals struct {
+	sync.RWMutex
+	sources  map[ID][]byte
+	loaders  []Loader
+	compiled map[ID]*jsonschema.Schema
+
+	compiling sync.Mutex // serializes use of the compiler
+	compiler  *jsonschema.Compiler
+}
+
+var external = &externals{
+	sources:  make(map[ID][]byte),
+	compiled: make(map[ID]*jsonschema.Schema),
+}
+
+// RegisterExternal adds a JSON Schema definition for a document type that
+// is not defined by a Go struct, such as company internal document types.
+// Objects with the external ID as their `$schema` will be unmarshalled into
+// an External payload and validated against the schema.
+func RegisterExternal(id ID, data []byte) error {
+	id = id.Base()
+	if id == UnknownID {
+		return errors.New("external schema ID required")
+	}
+	if Type(id) != nil {
+		return fmt.Errorf("schema '%s' already registered", id)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("external schema '%s': invalid JSON", id)
+	}
+	external.compiling.Lock()
+	defer external.compiling.Unlock()
+	external.Lock()
+	defer external.Unlock()
+	external.sources[id] = data
+	external.reset()
+	return nil
+}
+
+// RegisterLoader adds a loader that will be used to resolve references in
+// external schemas. Loaders are tried in the order they were registered.
+func RegisterLoader(l Loader) {
+	external.compiling.Lock()
+	defer external.compiling.Unlock()
+	external.Lock()
+	defer external.Unlock()
+	external.loaders = append(external.loaders, l)
+	external.reset()
+}
+
+// reset discards any compiled schemas after the sources have changed. Both
+// locks must be held.
+func (ex *externals) reset() {
+	ex.compiled = make(map[ID]*jsonschema.Schema)
+	ex.compiler = nil
+}
+
+// IsExternal returns true if the ID has been registered as an external
+// schema.
+func IsExternal(id ID) bool {
+	external.RLock()
+	defer external.RUnlock()
+	_, ok := external.sources[id.Base()]
+	return ok
+}
+
+// Load provides the raw JSON Schema for the ID, either from the external
+// registry or using the registered loaders.
+func Load(id ID) ([]byte, error) {
+	id = id.Base()
+	external.RLock()
+	data, ok := external.sources[id]
+	loaders := external.loaders
+	external.RUnlock()
+	if ok {
+		return data, nil
+	}
+	for _, l := range loaders {
+		data, err := l.Load(id)
+		if errors.Is(err, ErrUnknownSchema) {
+			continue
+		}
+		return data, err
+	}
+	return nil, ErrUnknownSchema
+}
+
+type compilerLoader struct{}
+
+func (compilerLoader) Load(url string) (any, error) {
+	data, err := Load(ID(url))
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+func compileExternal(id ID) (*jsonschema.Schema, error) {
+	external.RLock()
+	sch, ok := external.compiled[id]
+	external.RUnlock()
+	if ok {
+		return sch, nil
+	}
+
+	external.compiling.Lock()
+	defer external.compiling.Unlock()
+	// another goroutine may have compiled the schema while waiting
+	external.RLock()
+	sch, ok = external.compiled[id]
+	external.RUnlock()
+	if ok {
+		return sch, nil
+	}
+	if external.compiler == nil {
+		external.compiler = jsonschema.NewCompiler()
+		external.compiler.UseLoader(compilerLoader{})
+	}
+	sch, err := external.compiler.Compile(id.String())
+	if err != nil {
+		// start afresh next time in case the compiler was left with
+		// partially loaded resources
+		external.compiler = nil
+		return nil, err
+	}
+	external.Lock()
+	external.compiled[id] = sch
+	external.Unlock()
+	return sch, nil
+}
+
+// External is used as the payload of objects whose schema was registered
+// at runtime using RegisterExternal. The data is kept in its generic
+// form and validated using the JSON Schema.
+type External struct {
+	id   ID
+	data map[string]any
+}
+
+// NewExternal prepares a new external payload using the registered
+// schema ID and raw JSON data.
+func NewExternal(id ID, data []byte) (*External, error) {
+	id = id.Base()
+	if !IsExternal(id) {
+		return nil, ErrUnknownSchema
+	}
+	e := &External{id: id}
+	if err := e.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Schema provides the external schema ID.
+func (e *External) Schema() ID {
+	return e.id
+}
+
+// Data provides the generic map of data contained in the payload.
+func (e *External) Data() map[string]any {
+	return e.data
+}
+
+// Get provides the value of the top level property, or nil.
+func (e *External) Get(key string) any {
+	return e.data[key]
+}
+
+// ValidateWithContext checks the data against the external JSON Schema.
+func (e *External) ValidateWithContext(_ context.Context) error {
+	sch, err := compileExternal(e.id)
+	if err != nil {
+		return err
+	}
+	// The data was decoded using numbers so can be validated directly,
+	// in a shallow copy that includes the schema.
+	inst := make(map[string]any, len(e.data)+1)
+	for k, v := range e.data {
+		inst[k] = v
+	}
+	inst["$schema"] = e.id.String()
+	return sch.Validate(inst)
+}
+
+// UnmarshalJSON prepares the generic data, removing the `$schema` property
+// which is handled by the object.
+func (e *External) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	m := make(map[string]any)
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	delete(m, "$schema")
+	e.data = m
+	return nil
+}
+
+// MarshalJSON provides the generic data.
+func (e *External) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.data)
+}
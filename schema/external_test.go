@@ -0,0 +1,172 @@
+package schema_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/invopop/gobl/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExpenseSchema schema.ID = "https://example.com/schemas/expense-report"
+
+func init() {
+	err := schema.RegisterExternal(testExpenseSchema, []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://example.com/schemas/expense-report",
+		"type": "object",
+		"properties": {
+			"$schema": { "type": "string" },
+			"employee": { "$ref": "https://gobl.org/draft-0/org/party" },
+			"total": { "$ref": "https://gobl.org/draft-0/num/amount" }
+		},
+		"required": ["employee", "total"],
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterExternal(t *testing.T) {
+	assert.True(t, schema.IsExternal(testExpenseSchema))
+	assert.False(t, schema.IsExternal(schema.GOBL.Add("note/message")))
+
+	err := schema.RegisterExternal(schema.GOBL.Add("note/message"), []byte(`{}`))
+	assert.ErrorContains(t, err, "schema 'https://gobl.org/draft-0/note/message' already registered")
+	err = schema.RegisterExternal("https://example.com/schemas/bad", []byte(`{`))
+	assert.ErrorContains(t, err, "invalid JSON")
+	err = schema.RegisterExternal("", []byte(`{}`))
+	assert.ErrorContains(t, err, "external schema ID required")
+}
+
+func TestExternalObject(t *testing.T) {
+	data := []byte(`{"$schema":"https://example.com/schemas/expense-report","employee":{"name":"Test Employee"},"total":"12.50"}`)
+	obj := new(schema.Object)
+	require.NoError(t, json.Unmarshal(data, obj))
+	assert.Equal(t, testExpenseSchema, obj.Schema)
+	ext, ok := obj.Instance().(*schema.External)
+	require.True(t, ok)
+	assert.Equal(t, "12.50", ext.Get("total"))
+	assert.NoError(t, obj.Validate())
+
+	out, err := json.Marshal(obj)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(out))
+
+	t.Run("invalid data", func(t *testing.T) {
+		ext, err := schema.NewExternal(testExpenseSchema, []byte(`{"employee":{"name":123},"total":"12.50","other":true}`))
+		require.NoError(t, err)
+		obj, err := schema.NewObject(ext)
+		require.NoError(t, err)
+		assert.Equal(t, testExpenseSchema, obj.Schema)
+		err = obj.Validate()
+		assert.ErrorContains(t, err, "additional properties 'other' not allowed")
+		assert.ErrorContains(t, err, "/employee/name")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := schema.NewExternal("https://example.com/schemas/unknown", []byte(`{}`))
+		assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+	})
+}
+
+func TestExternalConcurrentValidation(t *testing.T) {
+	require.NoError(t, schema.RegisterExternal("https://example.com/schemas/concurrent", []byte(`{
+		"type": "object",
+		"properties": {
+			"total": { "$ref": "https://gobl.org/draft-0/num/amount" }
+		},
+		"required": ["total"]
+	}`)))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ext, err := schema.NewExternal("https://example.com/schemas/concurrent", []byte(`{"total":"10.00"}`))
+			if assert.NoError(t, err) {
+				assert.NoError(t, ext.ValidateWithContext(context.Background()))
+			}
+			ext, err = schema.NewExternal("https://example.com/schemas/concurrent", []byte(`{}`))
+			if assert.NoError(t, err) {
+				assert.ErrorContains(t, ext.ValidateWithContext(context.Background()), "missing property 'total'")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkExternalValidate(b *testing.B) {
+	data := []byte(`{"employee":{"name":"Test Employee"},"total":"12.50"}`)
+	ext, err := schema.NewExternal(testExpenseSchema, data)
+	require.NoError(b, err)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ext.ValidateWithContext(ctx); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func TestLoaders(t *testing.T) {
+	t.Run("fs", func(t *testing.T) {
+		l := schema.FSLoader("https://example.com/fs", fstest.MapFS{
+			"test/item.json": {Data: []byte(`{"type":"object"}`)},
+		})
+		data, err := l.Load("https://example.com/fs/test/item")
+		require.NoError(t, err)
+		assert.Equal(t, `{"type":"object"}`, string(data))
+		_, err = l.Load("https://example.com/fs/test/missing")
+		assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+		_, err = l.Load("https://example.com/other/test/item")
+		assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			if r.URL.Path != "/schemas/item" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"type":"string"}`))
+		}))
+		defer srv.Close()
+
+		l := schema.HTTPLoader(srv.Client())
+		id := schema.ID(srv.URL + "/schemas/item")
+		for range 2 {
+			data, err := l.Load(id)
+			require.NoError(t, err)
+			assert.Equal(t, `{"type":"string"}`, string(data))
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		_, err := l.Load(schema.ID(srv.URL + "/schemas/missing"))
+		assert.ErrorContains(t, err, "status 404")
+		_, err = l.Load("urn:example")
+		assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+	})
+
+	t.Run("load", func(t *testing.T) {
+		data, err := schema.Load(schema.GOBL.Add("org/party"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"$id": "https://gobl.org/draft-0/org/party"`)
+		_, err = schema.Load("https://example.com/none")
+		assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+	})
+}
@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// maxMigrationSteps limits the number of migrations that may be applied to
+// a single document to avoid loops between schema IDs.
+const maxMigrationSteps = 100
+
+// MigrationFunc is called with the generic map representation of a JSON
+// document and should modify it in place. The function must return true
+// only if changes were made, so that migrations may be safely run against
+// documents that have already been upgraded.
+type MigrationFunc func(doc map[string]any) (bool, error)
+
+// Migration describes how to upgrade documents with a specific schema ID
+// so that they can be understood by the current version of the models.
+type Migration struct {
+	// Key used to identify the migration in reports.
+	Key string
+	// From is the schema ID of the documents the migration applies to.
+	From ID
+	// To is the schema ID documents will have after the migration, if
+	// different.
+	To ID
+	// Func performs the migration.
+	Func MigrationFunc
+}
+
+// MigrationReport describes the migrations applied to a document.
+type MigrationReport struct {
+	// From is the original schema ID of the document.
+	From ID `json:"from"`
+	// To is the schema ID of the document after migrations.
+	To ID `json:"to"`
+	// Applied contains the keys of each migration applied, in order.
+	Applied []string `json:"applied"`
+}
+
+var migrations []*Migration
+
+// RegisterMigration adds the migrations to the global list. Migrations for
+// the same schema ID will be applied in the order they were registered.
+func RegisterMigration(ms ...*Migration) {
+	for _, m := range ms {
+		if m == nil || m.From == UnknownID || m.Func == nil {
+			panic("schema: invalid migration")
+		}
+		migrations = append(migrations, m)
+	}
+}
+
+// HasMigrations returns true if there are migrations registered for the
+// schema ID.
+func HasMigrations(id ID) bool {
+	for _, m := range migrations {
+		if m.From == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate applies any registered migrations to the JSON document according
+// to its `$schema` property. When migrations change the schema ID, the
+// migrations registered for the new ID will also be applied. If no
+// migrations were applied, the original data is returned alongside a nil
+// report.
+func Migrate(data []byte) ([]byte, *MigrationReport, error) {
+	id, err := Extract(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !HasMigrations(id) {
+		return data, nil, nil
+	}
+
+	doc := make(map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	rep := &MigrationReport{From: id, To: id}
+	steps := 0
+	for next := true; next; {
+		next = false
+		for _, m := range migrations {
+			if m.From != rep.To {
+				continue
+			}
+			if steps++; steps > maxMigrationSteps {
+				return nil, nil, fmt.Errorf("migration: too many steps for '%s'", rep.From)
+			}
+			ok, err := m.Func(doc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("migration '%s': %w", m.Key, err)
+			}
+			if !ok {
+				continue
+			}
+			rep.Applied = append(rep.Applied, m.Key)
+			if m.To != UnknownID && m.To != rep.To {
+				rep.To = m.To
+				doc["$schema"] = m.To.String()
+				next = true
+				break
+			}
+		}
+	}
+	if len(rep.Applied) == 0 {
+		return data, nil, nil
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, rep, nil
+}
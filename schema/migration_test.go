@@ -0,0 +1,102 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/invopop/gobl/note"
+	"github.com/invopop/gobl/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOldMessageSchema = schema.GOBL + "/test/old-message"
+
+func init() {
+	schema.RegisterMigration(
+		&schema.Migration{
+			Key:  "old-message-body",
+			From: testOldMessageSchema,
+			To:   schema.GOBL.Add("note/message"),
+			Func: func(doc map[string]any) (bool, error) {
+				if v, ok := doc["body"]; ok {
+					doc["content"] = v
+					delete(doc, "body")
+				}
+				return true, nil
+			},
+		},
+		&schema.Migration{
+			Key:  "message-text",
+			From: schema.GOBL.Add("note/message"),
+			Func: func(doc map[string]any) (bool, error) {
+				v, ok := doc["text"]
+				if !ok {
+					return false, nil
+				}
+				if _, ok := v.(string); !ok {
+					return false, errors.New("text must be a string")
+				}
+				doc["title"] = v
+				delete(doc, "text")
+				return true, nil
+			},
+		},
+	)
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		data := []byte(`{"$schema":"https://gobl.org/draft-0/test/old-message","body":"content","text":"title","amount":"10.00"}`)
+		out, rep, err := schema.Migrate(data)
+		require.NoError(t, err)
+		require.NotNil(t, rep)
+		assert.Equal(t, testOldMessageSchema, rep.From)
+		assert.Equal(t, schema.GOBL.Add("note/message"), rep.To)
+		assert.Equal(t, []string{"old-message-body", "message-text"}, rep.Applied)
+		assert.JSONEq(t, `{"$schema":"https://gobl.org/draft-0/note/message","content":"content","title":"title","amount":"10.00"}`, string(out))
+	})
+
+	t.Run("not required", func(t *testing.T) {
+		data := []byte(`{"$schema":"https://gobl.org/draft-0/note/message","content":"content"}`)
+		out, rep, err := schema.Migrate(data)
+		require.NoError(t, err)
+		assert.Nil(t, rep)
+		assert.Equal(t, data, out)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		data := []byte(`{"$schema":"https://gobl.org/draft-0/note/message","text":123}`)
+		_, _, err := schema.Migrate(data)
+		assert.ErrorContains(t, err, "migration 'message-text': text must be a string")
+	})
+
+	t.Run("invalid registration", func(t *testing.T) {
+		assert.Panics(t, func() {
+			schema.RegisterMigration(&schema.Migration{Key: "bad"})
+		})
+	})
+}
+
+func TestObjectMigration(t *testing.T) {
+	data := []byte(`{"$schema":"https://gobl.org/draft-0/test/old-message","body":"content"}`)
+	obj := new(schema.Object)
+	require.NoError(t, json.Unmarshal(data, obj))
+	assert.Equal(t, schema.GOBL.Add("note/message"), obj.Schema)
+	msg, ok := obj.Instance().(*note.Message)
+	require.True(t, ok)
+	assert.Equal(t, "content", msg.Content)
+	require.NotNil(t, obj.Migration())
+	assert.Equal(t, []string{"old-message-body"}, obj.Migration().Applied)
+	assert.Equal(t, data, obj.Original())
+
+	require.NoError(t, obj.Calculate())
+	assert.Nil(t, obj.Original())
+	assert.NotNil(t, obj.Migration())
+
+	obj = new(schema.Object)
+	require.NoError(t, json.Unmarshal([]byte(`{"$schema":"https://gobl.org/draft-0/note/message","content":"x"}`), obj))
+	assert.Nil(t, obj.Migration())
+	assert.Nil(t, obj.Original())
+}
@@ -30,6 +30,11 @@ const (
 type Object struct {
 	Schema  ID `json:"$schema"`
 	payload any
+
+	// migration details when the payload was upgraded during unmarshalling,
+	// alongside the original data so that digests may still be checked.
+	migration *MigrationReport
+	original  []byte
 }
 
 // Calculable defines the methods expected of a document payload that contains a `Calculate`
@@ -77,6 +82,7 @@ func (d *Object) Instance() interface{} {
 // document payload. If the object implements the Identifiable
 // interface, it will also ensure the UUID is set.
 func (d *Object) Calculate() error {
+	d.original = nil // accept any migrations
 	if ident, ok := d.payload.(Identifiable); ok {
 		id := ident.GetUUID()
 		if id.IsZero() {
@@ -158,6 +164,11 @@ func (d *Object) Replicate() error {
 // Insert places the provided object inside the document and looks up the schema
 // information to ensure it is known.
 func (d *Object) insert(payload interface{}) error {
+	if ext, ok := payload.(*External); ok {
+		d.Schema = ext.Schema()
+		d.payload = payload
+		return nil
+	}
 	d.Schema = Lookup(payload)
 	if d.Schema == UnknownID {
 		return ErrUnknownSchema
@@ -190,18 +201,47 @@ func (d *Object) Clone() (*Object, error) {
 	return d2, nil
 }
 
+// Migration provides the report of migrations applied to the payload when it
+// was unmarshalled, or nil if no migrations were required.
+func (d *Object) Migration() *MigrationReport {
+	return d.migration
+}
+
+// Original provides the raw JSON data received before any migrations were
+// applied. The original data will be removed after calculating the object,
+// at which point the migrated version is considered definitive.
+func (d *Object) Original() []byte {
+	return d.original
+}
+
 // UnmarshalJSON satisfies the json.Unmarshaler interface.
 func (d *Object) UnmarshalJSON(data []byte) error {
 	var err error
+	d.migration, d.original = nil, nil
 	if d.Schema, err = Extract(data); err != nil {
 		return err
 	}
+	if d.Schema != UnknownID {
+		md, rep, err := Migrate(data)
+		if err != nil {
+			return err
+		}
+		if rep != nil {
+			d.migration = rep
+			d.original = data
+			d.Schema = rep.To
+			data = md
+		}
+	}
 	if d.Schema == UnknownID {
 		return nil // return silently
 	}
 
 	// Map the schema to an instance of the payload, or fail if we don't know what it is
 	d.payload = d.Schema.Interface()
+	if d.payload == nil && IsExternal(d.Schema) {
+		d.payload = &External{id: d.Schema.Base()}
+	}
 	if d.payload == nil {
 		return ErrUnknownSchema
 	}
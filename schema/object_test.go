@@ -1,6 +1,7 @@
 package schema_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/invopop/gobl/bill"
@@ -69,6 +70,45 @@ func TestObjectReplicate(t *testing.T) {
 	assert.Empty(t, inv.Code, "should remove code")
 }
 
+func TestObjectMarshalJSON(t *testing.T) {
+	inv := exampleInvoice()
+	obj, err := schema.NewObject(inv)
+	require.NoError(t, err)
+	require.NoError(t, obj.Calculate())
+
+	d1, err := json.Marshal(obj)
+	require.NoError(t, err)
+	d2, err := json.Marshal(obj)
+	require.NoError(t, err)
+	assert.Equal(t, d1, d2)
+
+	t.Run("modified through instance", func(t *testing.T) {
+		obj.Instance().(*bill.Invoice).Code = "CHANGED"
+		d3, err := json.Marshal(obj)
+		require.NoError(t, err)
+		assert.Contains(t, string(d3), `"code":"CHANGED"`)
+	})
+
+	t.Run("modified through held pointer", func(t *testing.T) {
+		inv.Code = "HELD"
+		d3, err := json.Marshal(obj)
+		require.NoError(t, err)
+		assert.Contains(t, string(d3), `"code":"HELD"`)
+	})
+
+	t.Run("modified by calculation", func(t *testing.T) {
+		obj2 := new(schema.Object)
+		require.NoError(t, json.Unmarshal(d1, obj2))
+		_, err := json.Marshal(obj2)
+		require.NoError(t, err)
+		obj2.Instance().(*bill.Invoice).Lines[0].Quantity = num.MakeAmount(2, 0)
+		require.NoError(t, obj2.Calculate())
+		d4, err := json.Marshal(obj2)
+		require.NoError(t, err)
+		assert.NotEqual(t, d1, d4)
+	})
+}
+
 // exampleInvoice defines a simple invoice example pre-calculations.
 func exampleInvoice() *bill.Invoice {
 	return &bill.Invoice{
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification targeted
+// when generating component schemas.
+const OpenAPIVersion = "3.1.0"
+
+const (
+	openAPIRefPrefix = "#/components/schemas/"
+	defsRefPrefix    = "#/$defs/"
+)
+
+// OpenAPIComponentName provides the name used in OpenAPI components for the
+// schema ID, which is based on the ID's path relative to the GOBL base with
+// slashes replaced by dots, for example `bill.invoice`. Definitions other
+// than the main type of the schema have their name appended, for example
+// `bill.line.SubLine`.
+func OpenAPIComponentName(id ID, def string) string {
+	name := strings.TrimPrefix(id.Base().String(), GOBL.String()+"/")
+	name = strings.ReplaceAll(name, "/", ".")
+	if def != "" {
+		name = name + "." + def
+	}
+	return name
+}
+
+// OpenAPIComponents converts the JSON Schema files in the file system, like
+// the ones generated for all the registered GOBL types, into a map of
+// OpenAPI 3.1 component schemas. Paths in the file system are expected to
+// match the schema IDs relative to the GOBL base, and references between
+// schemas are replaced with references to the components.
+func OpenAPIComponents(fsys fs.FS) (map[string]any, error) {
+	out := make(map[string]any)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		id := ID(GOBL.String() + "/" + strings.TrimSuffix(p, ".json"))
+		return addOpenAPIComponents(out, id, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func addOpenAPIComponents(out map[string]any, id ID, data []byte) error {
+	doc := make(map[string]any)
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", id, err)
+	}
+	main := ""
+	if ref, ok := doc["$ref"].(string); ok {
+		main = strings.TrimPrefix(ref, defsRefPrefix)
+	}
+	defs, _ := doc["$defs"].(map[string]any)
+	if len(defs) == 0 {
+		// schema defined directly
+		delete(doc, "$schema")
+		delete(doc, "$id")
+		out[OpenAPIComponentName(id, "")] = openAPIRefs(id, main, doc)
+		return nil
+	}
+	for name, def := range defs {
+		cn := name
+		if name == main {
+			cn = ""
+		}
+		out[OpenAPIComponentName(id, cn)] = openAPIRefs(id, main, def)
+	}
+	return nil
+}
+
+// openAPIRefs recursively updates the references in the schema to point
+// to the component schemas.
+func openAPIRefs(id ID, main string, v any) any {
+	switch tv := v.(type) {
+	case map[string]any:
+		for k, sv := range tv {
+			if k == "$ref" {
+				if ref, ok := sv.(string); ok {
+					tv[k] = openAPIRef(id, main, ref)
+				}
+				continue
+			}
+			tv[k] = openAPIRefs(id, main, sv)
+		}
+	case []any:
+		for i, sv := range tv {
+			tv[i] = openAPIRefs(id, main, sv)
+		}
+	}
+	return v
+}
+
+func openAPIRef(id ID, main, ref string) string {
+	if strings.HasPrefix(ref, defsRefPrefix) {
+		def := strings.TrimPrefix(ref, defsRefPrefix)
+		if def == main {
+			def = ""
+		}
+		return openAPIRefPrefix + OpenAPIComponentName(id, def)
+	}
+	if !strings.HasPrefix(ref, GOBL.String()+"/") {
+		return ref // leave external references alone
+	}
+	rid := ID(ref)
+	def := ""
+	if i := strings.Index(ref, "#"); i != -1 {
+		def = strings.TrimPrefix(ref[i:], defsRefPrefix)
+	}
+	return openAPIRefPrefix + OpenAPIComponentName(rid, def)
+}
@@ -0,0 +1,75 @@
+package schema_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/invopop/gobl/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIComponentName(t *testing.T) {
+	assert.Equal(t, "bill.invoice", schema.OpenAPIComponentName("https://gobl.org/draft-0/bill/invoice", ""))
+	assert.Equal(t, "bill.line.SubLine", schema.OpenAPIComponentName("https://gobl.org/draft-0/bill/line", "SubLine"))
+	assert.Equal(t, "envelope", schema.OpenAPIComponentName("https://gobl.org/draft-0/envelope#/$defs/Envelope", ""))
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bill/line.json": &fstest.MapFile{Data: []byte(`{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://gobl.org/draft-0/bill/line",
+			"$ref": "#/$defs/Line",
+			"$defs": {
+				"Line": {
+					"type": "object",
+					"properties": {
+						"sum": { "$ref": "https://gobl.org/draft-0/num/amount" },
+						"breakdown": { "type": "array", "items": { "$ref": "#/$defs/SubLine" } },
+						"other": { "$ref": "https://example.com/other" }
+					}
+				},
+				"SubLine": {
+					"type": "object",
+					"properties": {
+						"line": { "$ref": "#/$defs/Line" }
+					}
+				}
+			}
+		}`)},
+		"num/amount.json": &fstest.MapFile{Data: []byte(`{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://gobl.org/draft-0/num/amount",
+			"type": "string"
+		}`)},
+		"README.md": &fstest.MapFile{Data: []byte(`ignored`)},
+	}
+	comps, err := schema.OpenAPIComponents(fsys)
+	require.NoError(t, err)
+	assert.Len(t, comps, 3)
+
+	line := comps["bill.line"].(map[string]any)
+	props := line["properties"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/num.amount", props["sum"].(map[string]any)["$ref"])
+	assert.Equal(t, "https://example.com/other", props["other"].(map[string]any)["$ref"])
+	items := props["breakdown"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/bill.line.SubLine", items["$ref"])
+
+	sub := comps["bill.line.SubLine"].(map[string]any)
+	props = sub["properties"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/bill.line", props["line"].(map[string]any)["$ref"])
+
+	amount := comps["num.amount"].(map[string]any)
+	assert.Equal(t, "string", amount["type"])
+	assert.NotContains(t, amount, "$schema")
+	assert.NotContains(t, amount, "$id")
+
+	t.Run("invalid", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"bad.json": &fstest.MapFile{Data: []byte(`{`)},
+		}
+		_, err := schema.OpenAPIComponents(fsys)
+		assert.ErrorContains(t, err, "https://gobl.org/draft-0/bad")
+	})
+}
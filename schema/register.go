@@ -2,11 +2,13 @@ package schema
 
 import (
 	"reflect"
+	"sync"
 )
 
 // registry contains all the schemas that we can possibly know about from either
-// inside or outside GOBL.
+// inside or outside GOBL. It is safe for concurrent use.
 type registry struct {
+	mu      sync.RWMutex
 	entries []*entry
 }
 
@@ -28,6 +30,8 @@ func (r *registry) addType(id ID, typ reflect.Type) error {
 		id:  id,
 		typ: typ,
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.entries = append(r.entries, e)
 	return nil
 }
@@ -46,6 +50,8 @@ func (r *registry) addWithAnchor(base ID, obj interface{}) error {
 
 func (r *registry) lookup(obj interface{}) ID {
 	typ := baseTypeOf(obj)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	for _, e := range r.entries {
 		if typ == e.typ {
 			return e.id
@@ -55,6 +61,8 @@ func (r *registry) lookup(obj interface{}) ID {
 }
 
 func (r *registry) typeFor(id ID) reflect.Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	for _, e := range r.entries {
 		if id == e.id {
 			return e.typ
@@ -64,6 +72,8 @@ func (r *registry) typeFor(id ID) reflect.Type {
 }
 
 func (r *registry) ids() []ID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	ids := make([]ID, len(r.entries))
 	for i, e := range r.entries {
 		ids[i] = e.id
@@ -114,6 +124,8 @@ func Type(id ID) reflect.Type {
 
 // Types provides a complete map of types to schema IDs that have been registered.
 func Types() map[reflect.Type]ID {
+	schemas.mu.RLock()
+	defer schemas.mu.RUnlock()
 	l := make(map[reflect.Type]ID)
 	for _, e := range schemas.entries {
 		l[e.typ] = e.id
@@ -123,9 +135,5 @@ func Types() map[reflect.Type]ID {
 
 // List of known schema IDs. Mainly used for debugging.
 func List() []ID {
-	l := make([]ID, len(schemas.entries))
-	for i, e := range schemas.entries {
-		l[i] = e.id
-	}
-	return l
+	return schemas.ids()
 }
@@ -70,7 +70,10 @@ func Insert(id ID, data []byte) ([]byte, error) {
 	// We manually create and add the JSON as this is just simply the quickest
 	// way to do it.
 	data = bytes.TrimLeft(data, "{")
-	sdata = append(bytes.TrimRight(sdata, "}"), byte(','))
+	sdata = bytes.TrimRight(sdata, "}")
+	if len(bytes.TrimSpace(data)) > 0 && bytes.TrimSpace(data)[0] != '}' {
+		sdata = append(sdata, byte(','))
+	}
 	data = append(sdata, data...)
 
 	return data, nil
@@ -51,4 +51,8 @@ func TestInsert(t *testing.T) {
 	data, err = schema.Insert(id, data)
 	assert.NoError(t, err)
 	assert.Equal(t, "{\"$schema\":\"https://gobl.org/draft-0/test/bar\",\"random\":\"message\"}", string(data))
+
+	data, err = schema.Insert(id, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"$schema\":\"https://gobl.org/draft-0/test/bar\"}", string(data))
 }
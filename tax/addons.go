@@ -2,8 +2,10 @@ package tax
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/i18n"
@@ -64,6 +66,10 @@ type AddonDef struct {
 	// Corrections is used to provide a map of correction definitions that
 	// are supported by the add-on.
 	Corrections CorrectionSet `json:"corrections" jsonschema:"title=Corrections"`
+
+	// RoundingRules limits the rounding rules that documents using the
+	// add-on may use. Any rule may be used when empty.
+	RoundingRules []cbc.Key `json:"rounding_rules,omitempty" jsonschema:"title=Rounding Rules"`
 }
 
 // WithAddons prepares the Addons struct with the provided list of keys.
@@ -113,48 +119,143 @@ func (as Addons) Validate() error {
 }
 
 type addonCollection struct {
+	mu   sync.RWMutex
 	keys []cbc.Key // ordered list
-	list map[cbc.Key]*AddonDef
+	list map[cbc.Key]*addonEntry
+}
+
+// addonEntry holds an add-on definition that may only be prepared the
+// first time it is needed.
+type addonEntry struct {
+	once   sync.Once
+	loader func() *AddonDef
+	def    *AddonDef
+}
+
+func (e *addonEntry) load() *AddonDef {
+	e.once.Do(func() {
+		e.def = e.loader()
+		for _, ext := range e.def.Extensions {
+			RegisterExtension(ext)
+		}
+		e.loader = nil
+	})
+	return e.def
 }
 
 var addons = newAddonCollection()
 
 func newAddonCollection() *addonCollection {
 	return &addonCollection{
-		list: make(map[cbc.Key]*AddonDef),
+		list: make(map[cbc.Key]*addonEntry),
 	}
 }
 
 // add will register the addon in the collection
-func (c *addonCollection) add(ad *AddonDef) {
-	c.keys = append(c.keys, ad.Key)
-	sort.Slice(c.keys, func(i, j int) bool {
-		return c.keys[i].String() < c.keys[j].String()
-	})
-	c.list[ad.Key] = ad
+func (c *addonCollection) add(key cbc.Key, e *addonEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, e)
+}
+
+// addNew registers the entry only if the key is not already in use.
+func (c *addonCollection) addNew(key cbc.Key, e *addonEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.list[key]; ok {
+		return fmt.Errorf("%w: addon '%s'", ErrAlreadyRegistered, key)
+	}
+	c.set(key, e)
+	return nil
+}
+
+func (c *addonCollection) set(key cbc.Key, e *addonEntry) {
+	if _, ok := c.list[key]; !ok {
+		c.keys = append(c.keys, key)
+		sort.Slice(c.keys, func(i, j int) bool {
+			return c.keys[i].String() < c.keys[j].String()
+		})
+	}
+	c.list[key] = e
 }
 
-// RegisterAddonDef adds a new add-on to the shared global list of tax add-on definitions.
-// This is expected to be called from module init functions.
+func (c *addonCollection) get(key cbc.Key) *AddonDef {
+	c.mu.RLock()
+	e, ok := c.list[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return e.load()
+}
+
+func (c *addonCollection) all() []*AddonDef {
+	c.mu.RLock()
+	entries := make([]*addonEntry, len(c.keys))
+	for i, k := range c.keys {
+		entries[i] = c.list[k]
+	}
+	c.mu.RUnlock()
+	all := make([]*AddonDef, len(entries))
+	for i, e := range entries {
+		all[i] = e.load()
+	}
+	return all
+}
+
+// RegisterAddonDef adds a new add-on to the shared global list of tax add-on definitions,
+// replacing any previous add-on with the same key. This is expected to be called
+// from module init functions, but is safe to use concurrently. Use AddAddonDef
+// to detect conflicts instead.
 func RegisterAddonDef(addon *AddonDef) {
-	for _, ext := range addon.Extensions {
-		RegisterExtension(ext)
+	e := &addonEntry{def: addon}
+	e.once.Do(func() {
+		for _, ext := range addon.Extensions {
+			RegisterExtension(ext)
+		}
+	})
+	addons.add(addon.Key, e)
+}
+
+// RegisterAddonDefLoader adds an add-on to the global list whose definition
+// will only be prepared by the loader the first time it is requested.
+func RegisterAddonDefLoader(key cbc.Key, loader func() *AddonDef) {
+	addons.add(key, &addonEntry{loader: loader})
+}
+
+// AddAddonDef is used by external modules to add an add-on to the global
+// list. Unlike RegisterAddonDef, an error will be returned if the key has
+// already been registered.
+func AddAddonDef(addon *AddonDef) error {
+	if addon == nil || addon.Key == cbc.KeyEmpty {
+		return errors.New("addon key required")
 	}
-	addons.add(addon)
+	e := &addonEntry{loader: func() *AddonDef { return addon }}
+	if err := addons.addNew(addon.Key, e); err != nil {
+		return err
+	}
+	e.load() // register extensions
+	return nil
 }
 
-// AddonForKey provides the add-on for the given key.
+// AddAddonDefLoader is the lazy loading equivalent of AddAddonDef, where
+// the definition will only be prepared the first time it is requested.
+func AddAddonDefLoader(key cbc.Key, loader func() *AddonDef) error {
+	if key == cbc.KeyEmpty || loader == nil {
+		return errors.New("addon key and loader required")
+	}
+	return addons.addNew(key, &addonEntry{loader: loader})
+}
+
+// AddonForKey provides the add-on for the given key. It is safe to call
+// concurrently with registration.
 func AddonForKey(key cbc.Key) *AddonDef {
-	return addons.list[key]
+	return addons.get(key)
 }
 
 // AllAddonDefs provides a slice of all the addons defined.
 func AllAddonDefs() []*AddonDef {
-	all := make([]*AddonDef, len(addons.list))
-	for i, ao := range addons.keys {
-		all[i] = addons.list[ao]
-	}
-	return all
+	return addons.all()
 }
 
 // WithContext adds this addon to the given context, alongside
@@ -186,8 +287,14 @@ func (addonValidation) Validate(value interface{}) error {
 
 // Validate checks that the add-on has been defined correctly.
 func (ad *AddonDef) Validate() error {
+	return ad.validate(AddonRegistered)
+}
+
+// validate checks the add-on's definition applying the additional rules
+// to the key, as the add-on may not have been registered yet.
+func (ad *AddonDef) validate(keyRules ...validation.Rule) error {
 	return validation.ValidateStruct(ad,
-		validation.Field(&ad.Key, validation.Required, AddonRegistered),
+		validation.Field(&ad.Key, append([]validation.Rule{validation.Required}, keyRules...)...),
 		validation.Field(&ad.Name, validation.Required),
 		validation.Field(&ad.Extensions),
 		validation.Field(&ad.Identities),
@@ -195,9 +302,21 @@ func (ad *AddonDef) Validate() error {
 		validation.Field(&ad.Tags),
 		validation.Field(&ad.Scenarios),
 		validation.Field(&ad.Corrections),
+		validation.Field(&ad.RoundingRules,
+			validation.Each(cbc.InKeyDefs(RoundingRules)),
+		),
 	)
 }
 
+// AllowsRoundingRule returns true if documents using the add-on may use
+// the rounding rule.
+func (ad *AddonDef) AllowsRoundingRule(rr cbc.Key) bool {
+	if ad == nil || len(ad.RoundingRules) == 0 {
+		return true
+	}
+	return rr.In(ad.RoundingRules...)
+}
+
 // JSONSchemaExtend will add the addon options to the JSON list.
 func (as Addons) JSONSchemaExtend(js *jsonschema.Schema) {
 	props := js.Properties
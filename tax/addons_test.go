@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/tax"
 	"github.com/invopop/jsonschema"
 	"github.com/stretchr/testify/assert"
@@ -61,6 +62,16 @@ func TestAllAddonDefs(t *testing.T) {
 	assert.NotEmpty(t, as)
 }
 
+func TestAddonAllowsRoundingRule(t *testing.T) {
+	ad := new(tax.AddonDef)
+	assert.True(t, ad.AllowsRoundingRule(tax.RoundingRuleHalfEven))
+	ad.RoundingRules = []cbc.Key{tax.RoundingRuleLine}
+	assert.True(t, ad.AllowsRoundingRule(tax.RoundingRuleLine))
+	assert.False(t, ad.AllowsRoundingRule(tax.RoundingRuleHalfEven))
+	ad = nil
+	assert.True(t, ad.AllowsRoundingRule(tax.RoundingRuleHalfEven))
+}
+
 func TestAddonWithContext(t *testing.T) {
 	t.Run("with validator", func(t *testing.T) {
 		ad := tax.AddonForKey("mx-cfdi-v4")
@@ -105,3 +116,40 @@ func TestAddonsJSONSchemaEmbed(t *testing.T) {
 	ao := tax.AllAddonDefs()[0]
 	assert.Equal(t, ao.Key.String(), prop.Items.OneOf[0].Const)
 }
+
+func TestAddAddonDef(t *testing.T) {
+	ad := &tax.AddonDef{
+		Key:  "xx-added-v1",
+		Name: i18n.NewString("Test"),
+		Extensions: []*cbc.Definition{
+			{Key: "xx-added-ext", Name: i18n.NewString("Test")},
+		},
+	}
+	require.NoError(t, tax.AddAddonDef(ad))
+	assert.Same(t, ad, tax.AddonForKey("xx-added-v1"))
+	assert.NotNil(t, tax.ExtensionForKey("xx-added-ext"))
+
+	err := tax.AddAddonDef(ad)
+	assert.ErrorIs(t, err, tax.ErrAlreadyRegistered)
+	assert.ErrorContains(t, err, "addon 'xx-added-v1'")
+	assert.ErrorIs(t, tax.AddAddonDef(&tax.AddonDef{Key: "mx-cfdi-v4"}), tax.ErrAlreadyRegistered)
+	assert.ErrorContains(t, tax.AddAddonDef(&tax.AddonDef{}), "addon key required")
+}
+
+func TestAddAddonDefLoader(t *testing.T) {
+	calls := 0
+	loader := func() *tax.AddonDef {
+		calls++
+		return &tax.AddonDef{
+			Key:  "xx-lazy-v1",
+			Name: i18n.NewString("Test"),
+		}
+	}
+	require.NoError(t, tax.AddAddonDefLoader("xx-lazy-v1", loader))
+	assert.Equal(t, 0, calls, "not loaded on registration")
+	assert.ErrorIs(t, tax.AddAddonDefLoader("xx-lazy-v1", loader), tax.ErrAlreadyRegistered)
+	require.NotNil(t, tax.AddonForKey("xx-lazy-v1"))
+	assert.Equal(t, 1, calls)
+
+	assert.ErrorContains(t, tax.AddAddonDefLoader("", loader), "addon key and loader required")
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"path"
 	"sort"
+	"sync"
 
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/data"
@@ -42,23 +43,23 @@ func RegisterCatalogueDef(filename string) {
 	if err := json.Unmarshal(out, catalogue); err != nil {
 		panic(err)
 	}
+	registerCatalogue(catalogue)
+}
 
+func registerCatalogue(catalogue *CatalogueDef) {
 	for _, ext := range catalogue.Extensions {
 		RegisterExtension(ext)
 	}
 	catalogues.add(catalogue)
 }
 
-// AllCatalogueDefs provides a slice of all the addons defined.
+// AllCatalogueDefs provides a slice of all the catalogues defined.
 func AllCatalogueDefs() []*CatalogueDef {
-	all := make([]*CatalogueDef, len(catalogues.list))
-	for i, ao := range catalogues.keys {
-		all[i] = catalogues.list[ao]
-	}
-	return all
+	return catalogues.all()
 }
 
 type catalogueCollection struct {
+	mu   sync.RWMutex
 	keys []cbc.Key // ordered list
 	list map[cbc.Key]*CatalogueDef
 }
@@ -71,11 +72,32 @@ func newCatalogueCollection() *catalogueCollection {
 	}
 }
 
-// add will register the catalogye in the collection
+// add will register the catalogue in the collection, replacing any
+// previous catalogue with the same key.
 func (c *catalogueCollection) add(cd *CatalogueDef) {
-	c.keys = append(c.keys, cd.Key)
-	sort.Slice(c.keys, func(i, j int) bool {
-		return c.keys[i].String() < c.keys[j].String()
-	})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.list[cd.Key]; !ok {
+		c.keys = append(c.keys, cd.Key)
+		sort.Slice(c.keys, func(i, j int) bool {
+			return c.keys[i].String() < c.keys[j].String()
+		})
+	}
 	c.list[cd.Key] = cd
 }
+
+func (c *catalogueCollection) get(key cbc.Key) *CatalogueDef {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list[key]
+}
+
+func (c *catalogueCollection) all() []*CatalogueDef {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	all := make([]*CatalogueDef, len(c.keys))
+	for i, k := range c.keys {
+		all[i] = c.list[k]
+	}
+	return all
+}
@@ -39,6 +39,15 @@ type Combo struct {
 	informative bool `json:"-"`
 }
 
+// Legacy exempt rates replaced by keys during normalization, prepared
+// in advance to avoid building them for every combo.
+var (
+	legacyKeyReverseCharge = KeyExempt.With("reverse-charge")
+	legacyKeyExport        = KeyExempt.With("export")
+	legacyKeyEEA           = KeyExempt.With("eea")
+	legacyKeyExportEEA     = KeyExempt.With("export").With("eea")
+)
+
 // ValidateWithContext ensures the Combo has the correct details.
 func (c *Combo) ValidateWithContext(ctx context.Context) error {
 	// First perform combo validation with the regime from the context,
@@ -93,14 +102,14 @@ func (c *Combo) Normalize(normalizers Normalizers) {
 			// rate was used too widely. Addons will need to try and account for this.
 			c.Key = KeyExempt
 			c.Rate = cbc.KeyEmpty
-		case KeyExempt.With("reverse-charge"):
+		case legacyKeyReverseCharge:
 			c.Key = KeyReverseCharge
 			c.Rate = cbc.KeyEmpty
 			c.Percent = nil
-		case KeyExempt.With("export"):
+		case legacyKeyExport:
 			c.Key = KeyExport
 			c.Rate = cbc.KeyEmpty
-		case KeyExempt.With("eea"), KeyExempt.With("export").With("eea"):
+		case legacyKeyEEA, legacyKeyExportEEA:
 			c.Key = KeyIntraCommunity
 			c.Rate = cbc.KeyEmpty
 		default:
@@ -192,12 +201,17 @@ func (c *Combo) prepareRate(cd *CategoryDef, date cal.Date) error {
 		return ErrInvalidDate.WithMessage("rate value unavailable for '%s' in '%s' on '%s'", c.Rate.String(), c.Category.String(), date.String())
 	}
 
-	p := value.Percent // copy
-	c.Percent = &p
+	// Avoid allocating a new percent when recalculating with the same value
+	if c.Percent == nil || *c.Percent != value.Percent {
+		p := value.Percent // copy
+		c.Percent = &p
+	}
 
 	if value.Surcharge != nil {
-		s := *value.Surcharge // copy
-		c.Surcharge = &s
+		if c.Surcharge == nil || *c.Surcharge != *value.Surcharge {
+			s := *value.Surcharge // copy
+			c.Surcharge = &s
+		}
 	} else {
 		c.Surcharge = nil
 	}
@@ -21,6 +21,7 @@ const (
 	KeyExport         cbc.Key = "export"
 	KeyIntraCommunity cbc.Key = "intra-community"
 	KeyOutsideScope   cbc.Key = "outside-scope"
+	KeyMargin         cbc.Key = "margin"
 )
 
 // Most commonly used rates. Local regions may add their own rate
@@ -47,6 +48,7 @@ const (
 	TagB2G           cbc.Key = "b2g"
 	TagExport        cbc.Key = "export"
 	TagEEA           cbc.Key = "eea" // European Economic Area
+	TagCashBasis     cbc.Key = "cash-basis"
 )
 
 // globalCategories defines the tax categories that can be applied anywhere that
@@ -95,6 +97,11 @@ var globalCategories = []*CategoryDef{
 				Name:      i18n.NewString("Outside scope"),
 				NoPercent: true,
 			},
+			{
+				Key:       KeyMargin,
+				Name:      i18n.NewString("Margin scheme"),
+				NoPercent: true,
+			},
 		},
 	},
 }
@@ -1,9 +1,11 @@
 package tax
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 )
 
 // Error is a general wrapper around tax errors produced during run
@@ -21,6 +23,10 @@ const (
 	ErrInvalidPricesInclude Error = "invalid-prices-include"
 )
 
+// ErrAlreadyRegistered is returned when adding a regime or add-on whose
+// country code or key is already in use.
+var ErrAlreadyRegistered = errors.New("already registered")
+
 // Error serializes the error's message.
 func (e Error) Error() string {
 	return string(e)
@@ -32,3 +38,77 @@ func (e Error) WithMessage(msg string, s ...interface{}) error {
 	msg = fmt.Sprintf(msg, s...)
 	return fmt.Errorf("%w: %v", e, msg)
 }
+
+// Validation errors with stable codes and translatable messages, used
+// when checking extensions, tags, and tax sets.
+var (
+	// ErrExtUndefined is used when an extension key has not been defined.
+	ErrExtUndefined = i18n.NewError("validation_ext_undefined", i18n.String{
+		i18n.EN: "undefined",
+		i18n.ES: "no definido",
+		i18n.FR: "non défini",
+		i18n.DE: "nicht definiert",
+	})
+	// ErrExtValueInvalid is used when an extension's value is not one of
+	// those defined, with the `value` parameter.
+	ErrExtValueInvalid = i18n.NewError("validation_ext_value_invalid", i18n.String{
+		i18n.EN: "value '{{.value}}' invalid",
+		i18n.ES: "valor '{{.value}}' no válido",
+		i18n.FR: "valeur '{{.value}}' non valide",
+		i18n.DE: "Wert '{{.value}}' ungültig",
+	})
+	// ErrExtValueNotAllowed is used when an extension's value has been
+	// explicitly excluded, with the `value` parameter.
+	ErrExtValueNotAllowed = i18n.NewError("validation_ext_value_not_allowed", i18n.String{
+		i18n.EN: "value '{{.value}}' not allowed",
+		i18n.ES: "valor '{{.value}}' no permitido",
+		i18n.FR: "valeur '{{.value}}' non autorisée",
+		i18n.DE: "Wert '{{.value}}' nicht erlaubt",
+	})
+	// ErrExtPattern is used when an extension's value does not match the
+	// pattern defined.
+	ErrExtPattern = i18n.NewError("validation_ext_pattern", i18n.String{
+		i18n.EN: "does not match pattern",
+		i18n.ES: "no coincide con el patrón",
+		i18n.FR: "ne correspond pas au modèle",
+		i18n.DE: "entspricht nicht dem Muster",
+	})
+	// ErrExtRequired is used when an extension is missing.
+	ErrExtRequired = i18n.NewError("validation_ext_required", i18n.String{
+		i18n.EN: "required",
+		i18n.ES: "obligatorio",
+		i18n.FR: "obligatoire",
+		i18n.DE: "erforderlich",
+	})
+	// ErrExtBlank is used when an extension is present but not expected.
+	ErrExtBlank = i18n.NewError("validation_ext_blank", i18n.String{
+		i18n.EN: "must be blank",
+		i18n.ES: "debe estar vacío",
+		i18n.FR: "doit être vide",
+		i18n.DE: "muss leer sein",
+	})
+	// ErrExtCodeInvalid is used when an extension's value is not one of
+	// those expected by a rule.
+	ErrExtCodeInvalid = i18n.NewError("validation_ext_code_invalid", i18n.String{
+		i18n.EN: "invalid value",
+		i18n.ES: "valor no válido",
+		i18n.FR: "valeur non valide",
+		i18n.DE: "ungültiger Wert",
+	})
+	// ErrTagUndefined is used when a tag is not defined for the document,
+	// with the `tag` parameter.
+	ErrTagUndefined = i18n.NewError("validation_tag_undefined", i18n.String{
+		i18n.EN: "'{{.tag}}' undefined",
+		i18n.ES: "'{{.tag}}' no definido",
+		i18n.FR: "'{{.tag}}' non défini",
+		i18n.DE: "'{{.tag}}' nicht definiert",
+	})
+	// ErrCategoryDuplicated is used when the same category appears more
+	// than once in a set, with the `category` parameter.
+	ErrCategoryDuplicated = i18n.NewError("validation_category_duplicated", i18n.String{
+		i18n.EN: "category {{.category}} is duplicated",
+		i18n.ES: "la categoría {{.category}} está duplicada",
+		i18n.FR: "la catégorie {{.category}} est en double",
+		i18n.DE: "Kategorie {{.category}} ist doppelt vorhanden",
+	})
+)
@@ -1,11 +1,10 @@
 package tax
 
 import (
-	"errors"
-	"fmt"
-	"regexp"
+	"sync"
 
 	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/pkg/pattern"
 	"github.com/invopop/jsonschema"
 	"github.com/invopop/validation"
 )
@@ -36,6 +35,7 @@ import (
 type Extensions map[cbc.Key]cbc.Code
 
 type extensionCollection struct {
+	mu   sync.RWMutex
 	list map[cbc.Key]*cbc.Definition
 }
 
@@ -50,9 +50,17 @@ func newExtensionCollection() *extensionCollection {
 }
 
 func (c *extensionCollection) add(kd *cbc.Definition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.list[kd.Key] = kd
 }
 
+func (c *extensionCollection) get(key cbc.Key) *cbc.Definition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list[key]
+}
+
 // RegisterExtension is used to add any extension definitions to the global
 // register. This is not expected to be called directly, but rather will
 // be used by the regimes and addons during their registration processes.
@@ -62,7 +70,14 @@ func RegisterExtension(kd *cbc.Definition) {
 
 // ExtensionForKey returns the extension definition for the given key or nil.
 func ExtensionForKey(key cbc.Key) *cbc.Definition {
-	return extensionDefs.list[key]
+	if kd := extensionDefs.get(key); kd != nil {
+		return kd
+	}
+	// The extension may belong to a regime or addon that has not been
+	// loaded yet, so ensure they're all ready before giving up.
+	regimes.All()
+	addons.all()
+	return extensionDefs.get(key)
 }
 
 // Validate ensures the extension map data looks correct and that all keys
@@ -83,20 +98,20 @@ func (em Extensions) Validate() error {
 		ks := k.String()
 		kd := ExtensionForKey(k)
 		if kd == nil {
-			err[ks] = errors.New("undefined")
+			err[ks] = ErrExtUndefined
 			continue
 		}
 		if len(kd.Values) > 0 && !kd.HasCode(ev) {
-			err[ks] = fmt.Errorf("value '%s' invalid", ev)
+			err[ks] = ErrExtValueInvalid.WithParams(map[string]any{"value": ev})
 		}
 		if kd.Pattern != "" {
-			re, rerr := regexp.Compile(kd.Pattern)
+			re, rerr := pattern.Compile(kd.Pattern)
 			if rerr != nil {
 				err[ks] = rerr
 				continue
 			}
 			if !re.MatchString(string(ev)) {
-				err[ks] = errors.New("does not match pattern")
+				err[ks] = ErrExtPattern
 			}
 		}
 	}
@@ -330,13 +345,13 @@ func (v validateExtCodeMap) Validate(value interface{}) error {
 	case extCodeOpAnd:
 		for _, k := range v.keys {
 			if _, ok := em[k]; !ok {
-				err[k.String()] = errors.New("required")
+				err[k.String()] = ErrExtRequired
 			}
 		}
 	case extCodeOpNot:
 		for _, k := range v.keys {
 			if _, ok := em[k]; ok {
-				err[k.String()] = errors.New("must be blank")
+				err[k.String()] = ErrExtBlank
 			}
 		}
 	case extCodeOpXNOr:
@@ -349,7 +364,7 @@ func (v validateExtCodeMap) Validate(value interface{}) error {
 		if present > 0 && present != len(v.keys) {
 			for _, k := range v.keys {
 				if _, ok := em[k]; !ok {
-					err[k.String()] = errors.New("required")
+					err[k.String()] = ErrExtRequired
 				}
 			}
 		}
@@ -405,13 +420,13 @@ func (v validateExtCodes) Validate(value interface{}) error {
 				}
 			}
 			if !match {
-				err[v.key.String()] = errors.New("invalid value")
+				err[v.key.String()] = ErrExtCodeInvalid
 			}
 		} else {
 			// Exclusion mode: value must NOT be in the list
 			for _, val := range v.values {
 				if ev == val {
-					err[v.key.String()] = fmt.Errorf("value '%s' not allowed", ev)
+					err[v.key.String()] = ErrExtValueNotAllowed.WithParams(map[string]any{"value": ev})
 					break
 				}
 			}
@@ -0,0 +1,79 @@
+package tax
+
+import (
+	"sort"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/cbc"
+)
+
+// RateChange describes a scheduled change in the value of a category's rate,
+// taken from the rate value definitions of a regime.
+type RateChange struct {
+	// Category code of the rate that changes.
+	Category cbc.Code `json:"cat"`
+	// Rate key whose value changes.
+	Rate cbc.Key `json:"rate"`
+	// Ext filter the values apply to, if any.
+	Ext Extensions `json:"ext,omitempty"`
+	// Date from which the new value will be applied.
+	Date cal.Date `json:"date"`
+	// From contains the value applied before the change, if any.
+	From *RateValueDef `json:"from,omitempty"`
+	// To contains the value applied from the date of the change.
+	To *RateValueDef `json:"to"`
+}
+
+// RateChanges provides the list of changes to the rate values of the category
+// that take place between the two dates, inclusive, ordered by date. This is
+// useful to detect documents that may soon need to be issued with new rates.
+func (r *RegimeDef) RateChanges(cat cbc.Code, from, to cal.Date) []*RateChange {
+	cd := r.CategoryDef(cat)
+	if cd == nil {
+		return nil
+	}
+	list := make([]*RateChange, 0)
+	for _, rd := range cd.Rates {
+		for i, rv := range rd.Values {
+			if rv.Since == nil || !rv.Since.IsValid() {
+				continue
+			}
+			if rv.Since.Before(from.Date) || rv.Since.After(to.Date) {
+				continue
+			}
+			list = append(list, &RateChange{
+				Category: cd.Code,
+				Rate:     rd.Rate,
+				Ext:      rv.Ext,
+				Date:     *rv.Since,
+				From:     previousRateValue(rd.Values[i+1:], rv.Ext),
+				To:       rv,
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Date.Before(list[j].Date.Date)
+	})
+	return list
+}
+
+// Applies returns true if the change affects the rate of the provided combo.
+func (rc *RateChange) Applies(c *Combo) bool {
+	if rc == nil || c == nil {
+		return false
+	}
+	if c.Category != rc.Category || c.Rate != rc.Rate {
+		return false
+	}
+	return len(rc.Ext) == 0 || c.Ext.Contains(rc.Ext)
+}
+
+// previousRateValue finds the first older value with the same extensions.
+func previousRateValue(values []*RateValueDef, ext Extensions) *RateValueDef {
+	for _, rv := range values {
+		if rv.Ext.Equals(ext) {
+			return rv
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package tax_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegimeDefRateChanges(t *testing.T) {
+	r := tax.RegimeDefFor("ES")
+	require.NotNil(t, r)
+
+	t.Run("within range", func(t *testing.T) {
+		list := r.RateChanges(tax.CategoryVAT, cal.MakeDate(2012, 8, 1), cal.MakeDate(2012, 9, 1))
+		require.NotEmpty(t, list)
+		rc := list[0]
+		assert.Equal(t, tax.CategoryVAT, rc.Category)
+		assert.Equal(t, tax.RateGeneral, rc.Rate)
+		assert.Equal(t, "2012-09-01", rc.Date.String())
+		require.NotNil(t, rc.From)
+		assert.Equal(t, "18.0%", rc.From.Percent.String())
+		assert.Equal(t, "21.0%", rc.To.Percent.String())
+		for _, rc := range list {
+			assert.Equal(t, "2012-09-01", rc.Date.String())
+		}
+	})
+
+	t.Run("ordered by date", func(t *testing.T) {
+		list := r.RateChanges(tax.CategoryVAT, cal.MakeDate(2010, 1, 1), cal.MakeDate(2012, 12, 31))
+		require.NotEmpty(t, list)
+		assert.Equal(t, "2010-07-01", list[0].Date.String())
+		assert.Equal(t, "2012-09-01", list[len(list)-1].Date.String())
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, r.RateChanges(tax.CategoryVAT, cal.MakeDate(2012, 9, 2), cal.MakeDate(2013, 1, 1)))
+		assert.Nil(t, r.RateChanges("FOO", cal.MakeDate(2010, 1, 1), cal.MakeDate(2013, 1, 1)))
+	})
+
+	t.Run("applies", func(t *testing.T) {
+		list := r.RateChanges(tax.CategoryVAT, cal.MakeDate(2012, 9, 1), cal.MakeDate(2012, 9, 1))
+		require.NotEmpty(t, list)
+		rc := list[0]
+		assert.True(t, rc.Applies(&tax.Combo{Category: tax.CategoryVAT, Rate: tax.RateGeneral}))
+		assert.False(t, rc.Applies(&tax.Combo{Category: tax.CategoryVAT, Rate: tax.RateReduced}))
+		assert.False(t, rc.Applies(nil))
+	})
+}
@@ -95,6 +95,8 @@ func (r *RateDef) HasKey(key cbc.Key) bool {
 }
 
 // Value determines the tax rate value for the provided date and zone, if applicable.
+// Values apply from and including their Since date, and nil will be returned if
+// no value covers the date.
 func (r *RateDef) Value(date cal.Date, ext Extensions) *RateValueDef {
 	for _, rv := range r.Values {
 		if len(rv.Ext) > 0 {
@@ -102,7 +104,7 @@ func (r *RateDef) Value(date cal.Date, ext Extensions) *RateValueDef {
 				continue
 			}
 		}
-		if rv.Since == nil || !rv.Since.IsValid() || rv.Since.Before(date.Date) {
+		if rv.Since == nil || !rv.Since.IsValid() || !date.Before(rv.Since.Date) {
 			return rv
 		}
 	}
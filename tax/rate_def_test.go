@@ -0,0 +1,46 @@
+package tax_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateDefValue(t *testing.T) {
+	rd := &tax.RateDef{
+		Rate: tax.RateGeneral,
+		Values: []*tax.RateValueDef{
+			{
+				Since:   cal.NewDate(2012, 9, 1),
+				Percent: num.MakePercentage(210, 3),
+			},
+			{
+				Since:   cal.NewDate(2010, 7, 1),
+				Percent: num.MakePercentage(180, 3),
+			},
+		},
+	}
+	t.Run("within range", func(t *testing.T) {
+		v := rd.Value(cal.MakeDate(2011, 1, 1), nil)
+		require.NotNil(t, v)
+		assert.Equal(t, "18.0%", v.Percent.String())
+		v = rd.Value(cal.MakeDate(2024, 1, 1), nil)
+		require.NotNil(t, v)
+		assert.Equal(t, "21.0%", v.Percent.String())
+	})
+	t.Run("on since date", func(t *testing.T) {
+		v := rd.Value(cal.MakeDate(2012, 9, 1), nil)
+		require.NotNil(t, v)
+		assert.Equal(t, "21.0%", v.Percent.String())
+		v = rd.Value(cal.MakeDate(2012, 8, 31), nil)
+		require.NotNil(t, v)
+		assert.Equal(t, "18.0%", v.Percent.String())
+	})
+	t.Run("no coverage", func(t *testing.T) {
+		assert.Nil(t, rd.Value(cal.MakeDate(2010, 6, 30), nil))
+	})
+}
@@ -57,6 +57,10 @@ type RegimeDef struct {
 	// `sum-then-round`.
 	CalculatorRoundingRule cbc.Key `json:"calculator_rounding_rule,omitempty" jsonschema:"title=Calculator Rounding Rule"`
 
+	// RoundingRules limits the rounding rules that documents in the regime
+	// may use. Any rule may be used when empty.
+	RoundingRules []cbc.Key `json:"rounding_rules,omitempty" jsonschema:"title=Rounding Rules"`
+
 	// Tags that can be applied at the document level to identify additional
 	// considerations.
 	Tags []*TagSet `json:"tags,omitempty" jsonschema:"title=Tags"`
@@ -153,6 +157,15 @@ func (r *RegimeDef) GetRoundingRule() cbc.Key {
 	return RoundingRulePrecise
 }
 
+// AllowsRoundingRule returns true if documents in the regime may use the
+// rounding rule.
+func (r *RegimeDef) AllowsRoundingRule(rr cbc.Key) bool {
+	if r == nil || len(r.RoundingRules) == 0 {
+		return true
+	}
+	return rr.In(r.RoundingRules...)
+}
+
 // ValidateObject performs validation on the provided object in the context
 // of the regime.
 func (r *RegimeDef) ValidateObject(value interface{}) error {
@@ -212,6 +225,12 @@ func (r *RegimeDef) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&r.Zone),
 		validation.Field(&r.Currency),
 		validation.Field(&r.TaxScheme),
+		validation.Field(&r.CalculatorRoundingRule,
+			cbc.InKeyDefs(RoundingRules),
+		),
+		validation.Field(&r.RoundingRules,
+			validation.Each(cbc.InKeyDefs(RoundingRules)),
+		),
 		validation.Field(&r.Tags),
 		validation.Field(&r.Identities),
 		validation.Field(&r.Extensions),
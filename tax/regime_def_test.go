@@ -95,6 +95,23 @@ func TestRegimeGetRoundingRule(t *testing.T) {
 	})
 }
 
+func TestRegimeAllowsRoundingRule(t *testing.T) {
+	t.Run("any", func(t *testing.T) {
+		r := new(tax.RegimeDef)
+		assert.True(t, r.AllowsRoundingRule(tax.RoundingRuleLine))
+	})
+	t.Run("limited", func(t *testing.T) {
+		r := new(tax.RegimeDef)
+		r.RoundingRules = []cbc.Key{tax.RoundingRuleCurrency, tax.RoundingRuleLine}
+		assert.True(t, r.AllowsRoundingRule(tax.RoundingRuleLine))
+		assert.False(t, r.AllowsRoundingRule(tax.RoundingRuleHalfEven))
+	})
+	t.Run("nil", func(t *testing.T) {
+		var r *tax.RegimeDef
+		assert.True(t, r.AllowsRoundingRule(tax.RoundingRuleHalfEven))
+	})
+}
+
 func TestRegimeInCategoryRates(t *testing.T) {
 	var r *tax.RegimeDef // nil regime
 	rate := cbc.Key("general")
@@ -1,7 +1,10 @@
 package tax
 
 import (
+	"errors"
+	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/invopop/gobl/l10n"
 )
@@ -12,9 +15,33 @@ var regimes = newRegimeCollection()
 // currently stored. Currently only a single tax regime per country is
 // supported as we've not yet come across situations where multiple
 // regimes exist within a single country.
+//
+// The collection is safe for concurrent use, so regimes may be registered
+// while others are being looked up, although registration is normally
+// expected to happen during initialization.
 type RegimeDefCollection struct {
+	mu    sync.RWMutex
 	codes []l10n.Code // ordered list of main country codes
-	list  map[l10n.Code]*RegimeDef
+	list  map[l10n.Code]*regimeEntry
+}
+
+// regimeEntry holds a regime definition that may only be prepared the
+// first time it is needed.
+type regimeEntry struct {
+	once   sync.Once
+	loader func() *RegimeDef
+	def    *RegimeDef
+}
+
+func (e *regimeEntry) load() *RegimeDef {
+	e.once.Do(func() {
+		e.def = e.loader()
+		for _, ext := range e.def.Extensions {
+			RegisterExtension(ext)
+		}
+		e.loader = nil
+	})
+	return e.def
 }
 
 // Regimes provides the current global regime collection object.
@@ -24,50 +51,120 @@ func Regimes() *RegimeDefCollection {
 
 func newRegimeCollection() *RegimeDefCollection {
 	c := new(RegimeDefCollection)
-	c.list = make(map[l10n.Code]*RegimeDef)
+	c.list = make(map[l10n.Code]*regimeEntry)
 	return c
 }
 
-func (c *RegimeDefCollection) add(r *RegimeDef) {
-	c.codes = append(c.codes, r.Country.Code())
-	sort.Slice(c.codes, func(i, j int) bool {
-		return c.codes[i].String() < c.codes[j].String()
-	})
-	c.list[r.Country.Code()] = r
-	for _, cc := range r.AltCountryCodes {
-		c.list[cc] = r
+func (c *RegimeDefCollection) add(country l10n.Code, alt []l10n.Code, e *regimeEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(country, alt, e)
+}
+
+// addNew registers the entry only if none of the country codes are already
+// in use.
+func (c *RegimeDefCollection) addNew(country l10n.Code, alt []l10n.Code, e *regimeEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cc := range append([]l10n.Code{country}, alt...) {
+		if _, ok := c.list[cc]; ok {
+			return fmt.Errorf("%w: regime '%s'", ErrAlreadyRegistered, cc)
+		}
+	}
+	c.set(country, alt, e)
+	return nil
+}
+
+func (c *RegimeDefCollection) set(country l10n.Code, alt []l10n.Code, e *regimeEntry) {
+	if _, ok := c.list[country]; !ok {
+		c.codes = append(c.codes, country)
+		sort.Slice(c.codes, func(i, j int) bool {
+			return c.codes[i].String() < c.codes[j].String()
+		})
+	}
+	c.list[country] = e
+	for _, cc := range alt {
+		c.list[cc] = e
 	}
 }
 
 // For provides a single matching regime from the collection, or nil if
 // no match is found.
 func (c *RegimeDefCollection) For(country l10n.Code) *RegimeDef {
-	r, ok := c.list[country]
+	c.mu.RLock()
+	e, ok := c.list[country]
+	c.mu.RUnlock()
 	if !ok {
 		return nil
 	}
-	return r
+	return e.load()
 }
 
 // All provides a list of all the registered Regimes.
 func (c *RegimeDefCollection) All() []*RegimeDef {
-	all := make([]*RegimeDef, len(c.codes))
+	c.mu.RLock()
+	entries := make([]*regimeEntry, len(c.codes))
 	for i, code := range c.codes {
-		all[i] = RegimeDefFor(code)
+		entries[i] = c.list[code]
+	}
+	c.mu.RUnlock()
+	all := make([]*RegimeDef, len(entries))
+	for i, e := range entries {
+		all[i] = e.load()
 	}
 	return all
 }
 
-// RegisterRegimeDef adds a new regime to the shared global list of tax regimes.
+// RegisterRegimeDef adds a new regime to the shared global list of tax regimes,
+// replacing any previous regime registered for the same country. Use
+// AddRegimeDef to detect conflicts instead.
 func RegisterRegimeDef(regime *RegimeDef) {
-	for _, ext := range regime.Extensions {
-		RegisterExtension(ext)
+	e := &regimeEntry{def: regime}
+	e.once.Do(func() {
+		for _, ext := range regime.Extensions {
+			RegisterExtension(ext)
+		}
+	})
+	regimes.add(regime.Country.Code(), regime.AltCountryCodes, e)
+}
+
+// RegisterRegimeDefLoader adds a regime to the global list whose definition
+// will only be prepared by the loader the first time it is requested,
+// avoiding the cost of building every regime's data when only a few are
+// used. Any alternative country codes the regime responds to must be
+// provided upfront, as they're needed before the definition is loaded.
+func RegisterRegimeDefLoader(country l10n.TaxCountryCode, loader func() *RegimeDef, alt ...l10n.Code) {
+	regimes.add(country.Code(), alt, &regimeEntry{loader: loader})
+}
+
+// AddRegimeDef is used by external modules to add a regime to the global
+// list. Unlike RegisterRegimeDef, an error will be returned if the country
+// or any of the alternative codes have already been registered, so that
+// private regimes cannot silently replace those included with GOBL.
+func AddRegimeDef(regime *RegimeDef) error {
+	if regime == nil || regime.Country == "" {
+		return errors.New("regime country required")
+	}
+	e := &regimeEntry{loader: func() *RegimeDef { return regime }}
+	if err := regimes.addNew(regime.Country.Code(), regime.AltCountryCodes, e); err != nil {
+		return err
+	}
+	e.load() // register extensions
+	return nil
+}
+
+// AddRegimeDefLoader is the lazy loading equivalent of AddRegimeDef, where
+// the definition will only be prepared the first time it is requested.
+func AddRegimeDefLoader(country l10n.TaxCountryCode, loader func() *RegimeDef, alt ...l10n.Code) error {
+	if country == "" || loader == nil {
+		return errors.New("regime country and loader required")
 	}
-	regimes.add(regime)
+	return regimes.addNew(country.Code(), alt, &regimeEntry{loader: loader})
 }
 
 // RegimeDefFor returns the regime definition for country and locality combination
-// or nil if no match was found.
+// or nil if no match was found. It is safe to call concurrently with
+// registration.
 func RegimeDefFor(country l10n.Code) *RegimeDef {
 	return regimes.For(country)
 }
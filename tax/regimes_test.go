@@ -1,11 +1,15 @@
 package tax_test
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/i18n"
 	"github.com/invopop/gobl/l10n"
 	"github.com/invopop/gobl/tax"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAllRegimes(t *testing.T) {
@@ -39,3 +43,107 @@ func TestSetRegime(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterRegimeDefLoader(t *testing.T) {
+	calls := 0
+	tax.RegisterRegimeDefLoader("XY", func() *tax.RegimeDef {
+		calls++
+		return &tax.RegimeDef{
+			Country:         "XY",
+			AltCountryCodes: []l10n.Code{"XZ"},
+			Currency:        "EUR",
+			Name:            i18n.NewString("Test"),
+			TimeZone:        "UTC",
+			Extensions: []*cbc.Definition{
+				{Key: "xy-test", Name: i18n.NewString("Test")},
+			},
+		}
+	}, "XZ")
+	assert.Equal(t, 0, calls, "not loaded on registration")
+	assert.NotNil(t, tax.ExtensionForKey("xy-test"), "loaded for extensions")
+	assert.Equal(t, 1, calls)
+
+	r := tax.RegimeDefFor("XZ")
+	require.NotNil(t, r)
+	assert.Equal(t, "XY", r.Country.String())
+	assert.Same(t, r, tax.RegimeDefFor("XY"))
+	assert.Equal(t, 1, calls, "loaded once")
+}
+
+func TestRegimesConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tax.RegisterRegimeDef(&tax.RegimeDef{
+				Country:  "XW",
+				Currency: "EUR",
+				Name:     i18n.NewString("Test"),
+				TimeZone: "UTC",
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NotNil(t, tax.RegimeDefFor("ES"))
+			assert.NotEmpty(t, tax.AllRegimeDefs())
+			assert.NotEmpty(t, tax.AllAddonDefs())
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	for _, r := range tax.AllRegimeDefs() {
+		if r.Country == "XW" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count, "replaced on re-registration")
+}
+
+func TestAddRegimeDef(t *testing.T) {
+	rd := &tax.RegimeDef{
+		Country:         "XV",
+		AltCountryCodes: []l10n.Code{"XQ"},
+		Currency:        "EUR",
+		Name:            i18n.NewString("Test"),
+		TimeZone:        "UTC",
+		Extensions: []*cbc.Definition{
+			{Key: "xv-test", Name: i18n.NewString("Test")},
+		},
+	}
+	require.NoError(t, tax.AddRegimeDef(rd))
+	assert.Same(t, rd, tax.RegimeDefFor("XQ"))
+	assert.NotNil(t, tax.ExtensionForKey("xv-test"))
+
+	err := tax.AddRegimeDef(rd)
+	assert.ErrorIs(t, err, tax.ErrAlreadyRegistered)
+	assert.ErrorContains(t, err, "regime 'XV'")
+
+	err = tax.AddRegimeDef(&tax.RegimeDef{Country: "XT", AltCountryCodes: []l10n.Code{"GR"}})
+	assert.ErrorContains(t, err, "already registered: regime 'GR'")
+	assert.Nil(t, tax.RegimeDefFor("XT"), "not registered on conflict")
+
+	assert.ErrorContains(t, tax.AddRegimeDef(nil), "regime country required")
+}
+
+func TestAddRegimeDefLoader(t *testing.T) {
+	calls := 0
+	loader := func() *tax.RegimeDef {
+		calls++
+		return &tax.RegimeDef{
+			Country:  "XS",
+			Currency: "EUR",
+			Name:     i18n.NewString("Test"),
+			TimeZone: "UTC",
+		}
+	}
+	require.NoError(t, tax.AddRegimeDefLoader("XS", loader))
+	assert.Equal(t, 0, calls, "not loaded on registration")
+	assert.ErrorIs(t, tax.AddRegimeDefLoader("XS", loader), tax.ErrAlreadyRegistered)
+	assert.ErrorIs(t, tax.AddRegimeDefLoader("ES", loader), tax.ErrAlreadyRegistered)
+	require.NotNil(t, tax.RegimeDefFor("XS"))
+	assert.Equal(t, 1, calls)
+
+	assert.ErrorContains(t, tax.AddRegimeDefLoader("XR", nil), "regime country and loader required")
+}
@@ -25,6 +25,16 @@ const (
 	// to and from prices that include taxes, but is a common approach in other digital
 	// invoicing formats.
 	RoundingRuleCurrency cbc.Key = "currency"
+
+	// RoundingRuleLine extends the currency rounding rule so that taxes are
+	// also calculated and rounded for each line before being added to the
+	// totals, as expected by systems that present the tax of every line.
+	RoundingRuleLine cbc.Key = "line"
+
+	// RoundingRuleHalfEven is the same as the currency rounding rule, but
+	// amounts exactly half way between two values are rounded to the nearest
+	// even number instead of away from zero.
+	RoundingRuleHalfEven cbc.Key = "half-even"
 )
 
 // RoundingRules defines the list of supported rounding rules.
@@ -56,6 +66,33 @@ var RoundingRules = []*cbc.Definition{
 			`),
 		},
 	},
+	{
+		Key: RoundingRuleLine,
+		Name: i18n.String{
+			i18n.EN: "Line",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Rounds amounts to the currency's precision like the currency rule, but also
+				calculates and rounds the taxes of each line individually before summing them
+				in the totals. This matches systems that determine the tax per line, but totals
+				may differ slightly from applying the rates to the sum of the lines.
+			`),
+		},
+	},
+	{
+		Key: RoundingRuleHalfEven,
+		Name: i18n.String{
+			i18n.EN: "Half Even",
+		},
+		Desc: i18n.String{
+			i18n.EN: here.Doc(`
+				Rounds amounts to the currency's precision before making the sums like the currency
+				rule, but values exactly half way between two options will be rounded to the nearest
+				even number, also known as banker's rounding.
+			`),
+		},
+	},
 }
 
 // ApplyRoundingRule applies the given rounding rule to the amount
@@ -63,8 +100,10 @@ var RoundingRules = []*cbc.Definition{
 func ApplyRoundingRule(rr cbc.Key, cur currency.Code, amount num.Amount) num.Amount {
 	exp := cur.Def().Subunits
 	switch rr {
-	case RoundingRuleCurrency:
+	case RoundingRuleCurrency, RoundingRuleLine:
 		return amount.Rescale(exp)
+	case RoundingRuleHalfEven:
+		return amount.RescaleHalfEven(exp)
 	default:
 		return amount.RescaleUp(exp)
 	}
@@ -18,6 +18,10 @@ type ScenarioSet struct {
 	List []*Scenario `json:"list" jsonschema:"title=List"`
 }
 
+// ScenarioScopeLine is used in scenarios that should be matched against and
+// applied to each of the document's lines individually.
+const ScenarioScopeLine cbc.Key = "line"
+
 // ScenarioDocument is used to determine if scenarios can be applied to a document.
 type ScenarioDocument interface {
 	// GetType returns a type associated with the document.
@@ -28,6 +32,14 @@ type ScenarioDocument interface {
 	GetExtensions() []Extensions
 }
 
+// ScenarioLine is used to determine if line scoped scenarios can be applied
+// to one of the document's lines.
+type ScenarioLine interface {
+	// GetExtensions provides the extensions used in the line, including those
+	// of its taxes.
+	GetExtensions() []Extensions
+}
+
 // Scenario is used to describe a tax scenario of a document based on the combination
 // of document type and tag used.
 //
@@ -41,12 +53,20 @@ type Scenario struct {
 	// Description of the scenario for documentation purposes.
 	Desc i18n.String `json:"desc,omitempty" jsonschema:"title=Description"`
 
+	// Scope determines what the scenario applies to. When empty the scenario
+	// is matched against the document, while "line" implies each of the
+	// document's lines will be checked using their own extensions, and any
+	// notes or extensions will be added to the matching lines.
+	Scope cbc.Key `json:"scope,omitempty" jsonschema:"title=Scope"`
+
 	/* Filters */
 
-	// Type of document, if present.
+	// Type of document, if present. Types may be patterns with wildcards
+	// or alternatives, as supported by `cbc.Key.Match`.
 	Types []cbc.Key `json:"type,omitempty" jsonschema:"title=Type"`
 
-	// Array of tags that have been applied to the document.
+	// Array of tags that have been applied to the document. Each tag may be
+	// a pattern with wildcards or alternatives, as supported by `cbc.Key.Match`.
 	Tags []cbc.Key `json:"tags,omitempty" jsonschema:"title=Tags"`
 
 	// Extension key that must be present in the document.
@@ -58,7 +78,7 @@ type Scenario struct {
 	ExtCode cbc.Code `json:"ext_code,omitempty" jsonschema:"title=Extension Code"`
 
 	// Filter defines a custom filter method for when the regular basic filters
-	// are not sufficient.
+	// are not sufficient. Line scoped scenarios will receive the line.
 	Filter func(doc any) bool `json:"-"`
 
 	/* Outputs */
@@ -144,23 +164,50 @@ func (ss *ScenarioSet) ExtensionKeys() []cbc.Key {
 func (ss *ScenarioSet) Notes() []*ScenarioNote {
 	notes := make([]*ScenarioNote, 0)
 	for _, row := range ss.List {
-		if row.Note != nil {
+		if row.Scope == cbc.KeyEmpty && row.Note != nil {
 			notes = append(notes, row.Note)
 		}
 	}
 	return notes
 }
 
-// SummaryFor returns a summary by applying the scenarios to the
-// supplied document.
+// LineNotes extracts all the possible notes that could be applied to the
+// document's lines, including the codes copied from extensions.
+func (ss *ScenarioSet) LineNotes() []*ScenarioNote {
+	notes := make([]*ScenarioNote, 0)
+	for _, row := range ss.List {
+		if row.Scope == ScenarioScopeLine && row.Note != nil {
+			notes = append(notes, row.Note.withCode(row.ExtCode))
+		}
+	}
+	return notes
+}
+
+// SummaryFor returns a summary by applying the document scoped scenarios
+// to the supplied document.
 func (ss *ScenarioSet) SummaryFor(doc ScenarioDocument) *ScenarioSummary {
+	return ss.summary(cbc.KeyEmpty, func(s *Scenario) bool {
+		return s.match(doc, doc.GetExtensions(), doc)
+	})
+}
+
+// LineSummaryFor returns a summary by applying the line scoped scenarios
+// to one of the document's lines. Types and tags are taken from the document,
+// while extensions and filters are checked against the line.
+func (ss *ScenarioSet) LineSummaryFor(doc ScenarioDocument, line ScenarioLine) *ScenarioSummary {
+	return ss.summary(ScenarioScopeLine, func(s *Scenario) bool {
+		return s.match(doc, line.GetExtensions(), line)
+	})
+}
+
+func (ss *ScenarioSet) summary(scope cbc.Key, match func(s *Scenario) bool) *ScenarioSummary {
 	summary := &ScenarioSummary{
 		Notes: make([]*ScenarioNote, 0),
 		Codes: make(cbc.CodeMap),
 		Ext:   make(Extensions),
 	}
 	for _, s := range ss.List {
-		if s.match(doc) {
+		if s.Scope == scope && match(s) {
 			if s.Note != nil {
 				summary.addNote(s.Note.withCode(s.ExtCode))
 			}
@@ -189,8 +236,8 @@ func (ss *ScenarioSummary) addNote(note *ScenarioNote) {
 // match checks if the scenario has a matching doc type or set of tags.
 // Empty types or tags in the scenario implies that all values are valid.
 // The list of extensions can contain duplicate extension maps to make recompilation
-// of the array easier.
-func (s *Scenario) match(doc ScenarioDocument) bool {
+// of the array easier. The object is passed to custom filters.
+func (s *Scenario) match(doc ScenarioDocument, exts []Extensions, obj any) bool {
 	if len(s.Types) > 0 {
 		if !s.hasType(doc.GetType()) {
 			return false
@@ -205,7 +252,7 @@ func (s *Scenario) match(doc ScenarioDocument) bool {
 		// For extensions we need to find a complete match
 		// and reject if none found. We intentionally don't try
 		// to combine extensions from the document.
-		for _, ext := range doc.GetExtensions() {
+		for _, ext := range exts {
 			v, ok := ext[s.ExtKey]
 			if !ok {
 				continue // try next extension
@@ -221,7 +268,7 @@ func (s *Scenario) match(doc ScenarioDocument) bool {
 		return false
 	}
 	if s.Filter != nil {
-		if !s.Filter(doc) {
+		if !s.Filter(obj) {
 			return false
 		}
 	}
@@ -230,7 +277,7 @@ func (s *Scenario) match(doc ScenarioDocument) bool {
 
 // hasType returns true if the scenario has the specified document type.
 func (s *Scenario) hasType(docType cbc.Key) bool {
-	return docType.In(s.Types...)
+	return docType.MatchAny(s.Types...)
 }
 
 // hasTags returns true if the the provided document tags is a subset of the
@@ -238,7 +285,7 @@ func (s *Scenario) hasType(docType cbc.Key) bool {
 func (s *Scenario) hasTags(docTags []cbc.Key) bool {
 	if len(s.Tags) > 0 {
 		for _, t := range s.Tags {
-			if !t.In(docTags...) {
+			if !matchesAnyKey(t, docTags) {
 				return false
 			}
 		}
@@ -247,12 +294,23 @@ func (s *Scenario) hasTags(docTags []cbc.Key) bool {
 	return false
 }
 
+// matchesAnyKey returns true if the pattern matches at least one of the keys.
+func matchesAnyKey(pattern cbc.Key, keys []cbc.Key) bool {
+	for _, k := range keys {
+		if k.Match(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateWithContext checks the scenario for errors, using the regime in the context
 // to validate the list of tags.
 func (s *Scenario) ValidateWithContext(ctx context.Context) error {
 	err := validation.ValidateStructWithContext(ctx, s,
-		validation.Field(&s.Types),
-		validation.Field(&s.Tags), // consider validating tags in context
+		validation.Field(&s.Scope, validation.In(ScenarioScopeLine)),
+		validation.Field(&s.Types, cbc.InKeyMatchFormat, validation.Skip),
+		validation.Field(&s.Tags, cbc.InKeyMatchFormat, validation.Skip), // consider validating tags in context
 		validation.Field(&s.Name),
 		validation.Field(&s.Note),
 		validation.Field(&s.Codes),
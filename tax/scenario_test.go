@@ -1,6 +1,7 @@
 package tax_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/invopop/gobl/bill"
@@ -30,6 +31,53 @@ func (d *scenarioTestDocument) GetExtensions() []tax.Extensions {
 	return d.exts
 }
 
+func TestScenarioMatchPatterns(t *testing.T) {
+	ss := &tax.ScenarioSet{
+		Schema: bill.ShortSchemaInvoice,
+		List: []*tax.Scenario{
+			{
+				Types: []cbc.Key{"standard|corrective"},
+				Tags:  []cbc.Key{"reverse-charge|export"},
+				Codes: cbc.CodeMap{
+					"reason": "exempt",
+				},
+			},
+			{
+				Tags: []cbc.Key{"b2*"},
+				Codes: cbc.CodeMap{
+					"party": "business",
+				},
+			},
+		},
+	}
+	require.NoError(t, ss.ValidateWithContext(context.Background()))
+
+	sum := ss.SummaryFor(&scenarioTestDocument{
+		typ:  bill.InvoiceTypeStandard,
+		tags: []cbc.Key{"export", "b2g"},
+	})
+	assert.Equal(t, "exempt", sum.Codes["reason"].String())
+	assert.Equal(t, "business", sum.Codes["party"].String())
+
+	sum = ss.SummaryFor(&scenarioTestDocument{
+		typ:  bill.InvoiceTypeCorrective,
+		tags: []cbc.Key{"reverse-charge"},
+	})
+	assert.Equal(t, cbc.CodeMap{"reason": "exempt"}, sum.Codes)
+
+	sum = ss.SummaryFor(&scenarioTestDocument{
+		typ:  bill.InvoiceTypeCreditNote,
+		tags: []cbc.Key{"export"},
+	})
+	assert.Empty(t, sum.Codes)
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		s := &tax.Scenario{Tags: []cbc.Key{"export||b2b"}}
+		err := s.ValidateWithContext(context.Background())
+		assert.ErrorContains(t, err, "tags: (0: must be a valid key pattern")
+	})
+}
+
 func TestScenarioSetSummary(t *testing.T) {
 	ss := &tax.ScenarioSet{
 		Schema: bill.ShortSchemaInvoice,
@@ -221,3 +269,71 @@ func scenariosInvoiceExample() *bill.Invoice {
 	}
 	return i
 }
+
+type scenarioTestLine struct {
+	exts []tax.Extensions
+}
+
+func (l *scenarioTestLine) GetExtensions() []tax.Extensions {
+	return l.exts
+}
+
+func TestScenarioSetLineSummary(t *testing.T) {
+	ss := &tax.ScenarioSet{
+		Schema: bill.ShortSchemaInvoice,
+		List: []*tax.Scenario{
+			{
+				Scope:   tax.ScenarioScopeLine,
+				Types:   []cbc.Key{bill.InvoiceTypeStandard},
+				ExtKey:  "xx-exempt",
+				ExtCode: "E1",
+				Note: &tax.ScenarioNote{
+					Key:  org.NoteKeyLegal,
+					Src:  "xx-exempt",
+					Text: "Exempt line",
+				},
+				Ext: tax.Extensions{"xx-line": "exempt"},
+			},
+			{
+				Ext: tax.Extensions{"xx-doc": "normal"},
+			},
+		},
+	}
+	doc := &scenarioTestDocument{
+		typ:  bill.InvoiceTypeStandard,
+		exts: []tax.Extensions{{"xx-exempt": "E1"}},
+	}
+	sum := ss.SummaryFor(doc)
+	assert.Empty(t, sum.Notes, "line scenarios ignored for document")
+	assert.Equal(t, tax.Extensions{"xx-doc": "normal"}, sum.Ext)
+
+	sum = ss.LineSummaryFor(doc, &scenarioTestLine{
+		exts: []tax.Extensions{{"xx-exempt": "E1"}},
+	})
+	require.Len(t, sum.Notes, 1)
+	assert.Equal(t, "E1", sum.Notes[0].Code.String())
+	assert.Equal(t, tax.Extensions{"xx-line": "exempt"}, sum.Ext)
+
+	sum = ss.LineSummaryFor(doc, &scenarioTestLine{
+		exts: []tax.Extensions{{"xx-exempt": "E2"}},
+	})
+	assert.Empty(t, sum.Notes)
+	assert.Empty(t, sum.Ext)
+
+	doc.typ = bill.InvoiceTypeCreditNote
+	sum = ss.LineSummaryFor(doc, &scenarioTestLine{
+		exts: []tax.Extensions{{"xx-exempt": "E1"}},
+	})
+	assert.Empty(t, sum.Notes, "document type must match")
+
+	notes := ss.LineNotes()
+	require.Len(t, notes, 1)
+	assert.Equal(t, "E1", notes[0].Code.String())
+	assert.Empty(t, ss.Notes())
+
+	t.Run("invalid scope", func(t *testing.T) {
+		s := &tax.Scenario{Scope: "item"}
+		err := s.ValidateWithContext(context.Background())
+		assert.ErrorContains(t, err, "scope: must be a valid value")
+	})
+}
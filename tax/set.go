@@ -35,7 +35,7 @@ func (s Set) ValidateWithContext(ctx context.Context) error {
 	for i, c := range s {
 		if _, ok := combos[c.Category]; ok {
 			return validation.Errors{
-				fmt.Sprintf("%d", i): fmt.Errorf("category %v is duplicated", c.Category),
+				fmt.Sprintf("%d", i): ErrCategoryDuplicated.WithParams(map[string]any{"category": c.Category}),
 			}
 		}
 		if err := c.ValidateWithContext(ctx); err != nil {
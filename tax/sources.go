@@ -0,0 +1,326 @@
+package tax
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/schema"
+	"github.com/invopop/validation"
+	"github.com/invopop/yaml"
+)
+
+// Directories inside a definitions source, matching the layout of the
+// embedded data.
+const (
+	sourceCataloguesDir = "catalogues"
+	sourceAddonsDir     = "addons"
+	sourceRegimesDir    = "regimes"
+)
+
+// sourceFileExts lists the supported definition file extensions.
+var sourceFileExts = []string{".json", ".yaml", ".yml"}
+
+// LoadOption is used to customize how definitions are loaded from an
+// external source.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	digests map[string]string
+}
+
+// WithDigests provides the hex encoded SHA-256 digests of the files
+// expected in the source, indexed by their path, such as
+// `regimes/es.json`. Files without a matching digest will be rejected.
+func WithDigests(digests map[string]string) LoadOption {
+	return func(o *loadOptions) {
+		o.digests = digests
+	}
+}
+
+// LoadDefs overrides or extends the registered catalogue, add-on, and regime
+// definitions with the JSON or YAML files found in the `catalogues`, `addons`,
+// and `regimes` directories of the source, using the same layout and format as
+// the embedded data. This makes it possible, for example, to correct a tax
+// rate without upgrading the library. Files may include a `$schema` property
+// which must match that of the definition, e.g. `https://gobl.org/draft-0/tax/regime-def`.
+//
+// Definitions are matched by key or country, and the top-level properties
+// present in a file replace those of the registered definition, while those
+// missing are kept. Behavior defined in code, like validators, normalizers,
+// and scenario filters, cannot be provided in JSON and is kept from the
+// registered definition. Definitions that do not match an existing one are
+// added.
+//
+// The precedence order is simple: definitions registered by the library are
+// replaced by those loaded, and each call to LoadDefs replaces definitions
+// from previous calls, so the last source loaded wins.
+//
+// Every file is read, checked, and validated before any definitions are
+// registered, so a source with a single problem will be rejected entirely.
+// Extensions referenced by definitions must already be registered.
+func LoadDefs(fsys fs.FS, opts ...LoadOption) error {
+	o := new(loadOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	l := &defsLoader{fsys: fsys, opts: o}
+	if err := l.prepare(); err != nil {
+		return err
+	}
+	l.register()
+	return nil
+}
+
+// LoadDefsFromDir is a convenience method to load the definitions from a
+// directory in the file system.
+func LoadDefsFromDir(dir string, opts ...LoadOption) error {
+	return LoadDefs(os.DirFS(dir), opts...)
+}
+
+type defsLoader struct {
+	fsys       fs.FS
+	opts       *loadOptions
+	catalogues []*CatalogueDef
+	addons     []*AddonDef
+	regimes    []*RegimeDef
+}
+
+func (l *defsLoader) prepare() error {
+	err := l.each(sourceCataloguesDir, func(name string, raw map[string]json.RawMessage) error {
+		cd := new(CatalogueDef)
+		if ex := catalogues.get(cbc.Key(name)); ex != nil {
+			*cd = *ex
+		}
+		if err := overlayDef(cd, raw); err != nil {
+			return err
+		}
+		if cd.Key.String() != name {
+			return fmt.Errorf("key '%s' does not match file name", cd.Key)
+		}
+		if err := validation.ValidateStruct(cd,
+			validation.Field(&cd.Key, validation.Required),
+			validation.Field(&cd.Name, validation.Required),
+			validation.Field(&cd.Extensions),
+		); err != nil {
+			return err
+		}
+		l.catalogues = append(l.catalogues, cd)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = l.each(sourceAddonsDir, func(name string, raw map[string]json.RawMessage) error {
+		ad := new(AddonDef)
+		if ex := AddonForKey(cbc.Key(name)); ex != nil {
+			*ad = *ex
+		}
+		if err := overlayDef(ad, raw); err != nil {
+			return err
+		}
+		if ad.Key.String() != name {
+			return fmt.Errorf("key '%s' does not match file name", ad.Key)
+		}
+		if err := ad.validate(); err != nil {
+			return err
+		}
+		l.addons = append(l.addons, ad)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return l.each(sourceRegimesDir, func(name string, raw map[string]json.RawMessage) error {
+		rd := new(RegimeDef)
+		if ex := RegimeDefFor(l10n.Code(strings.ToUpper(name))); ex != nil {
+			*rd = *ex
+		}
+		if err := overlayDef(rd, raw); err != nil {
+			return err
+		}
+		if !strings.EqualFold(rd.Country.String(), name) {
+			return fmt.Errorf("country '%s' does not match file name", rd.Country)
+		}
+		if err := rd.Validate(); err != nil {
+			return err
+		}
+		l.regimes = append(l.regimes, rd)
+		return nil
+	})
+}
+
+// each reads and checks every JSON or YAML file in the directory, if present.
+func (l *defsLoader) each(dir string, fn func(name string, raw map[string]json.RawMessage) error) error {
+	entries, err := fs.ReadDir(l.fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	names := make(map[string]string)
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || !slices.Contains(sourceFileExts, ext) {
+			continue
+		}
+		p := path.Join(dir, e.Name())
+		name := strings.TrimSuffix(e.Name(), ext)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("%s: duplicates %s", p, prev)
+		}
+		names[name] = p
+		data, err := fs.ReadFile(l.fsys, p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		if err := l.checkDigest(p, data); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		raw, err := parseDefData(data, ext != ".json")
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		if err := fn(name, raw); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// parseDefData prepares the top-level properties of a definition file,
+// converting YAML to JSON first if needed.
+func parseDefData(data []byte, isYAML bool) (map[string]json.RawMessage, error) {
+	if isYAML {
+		var err error
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// ParseRegimeDef builds a new regime definition from the JSON or YAML data
+// provided, using the same format as the embedded data, and validates it.
+// Unlike LoadDefs, the definition is not registered and no behavior defined
+// in code is included, so it may be used to check files or prepare regimes
+// for RegisterRegimeDef or AddRegimeDef.
+func ParseRegimeDef(data []byte) (*RegimeDef, error) {
+	rd := new(RegimeDef)
+	if err := parseDef(data, rd); err != nil {
+		return nil, err
+	}
+	if err := rd.Validate(); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// ParseAddonDef builds a new add-on definition from the JSON or YAML data
+// provided and validates it, in the same way as ParseRegimeDef.
+func ParseAddonDef(data []byte) (*AddonDef, error) {
+	ad := new(AddonDef)
+	if err := parseDef(data, ad); err != nil {
+		return nil, err
+	}
+	if err := ad.validate(); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+func parseDef(data []byte, def any) error {
+	raw, err := parseDefData(data, !json.Valid(data))
+	if err != nil {
+		return err
+	}
+	return overlayDef(def, raw)
+}
+
+func (l *defsLoader) checkDigest(p string, data []byte) error {
+	if l.opts.digests == nil {
+		return nil
+	}
+	expected, ok := l.opts.digests[p]
+	if !ok {
+		return errors.New("missing digest")
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), expected) {
+		return errors.New("digest mismatch")
+	}
+	return nil
+}
+
+func (l *defsLoader) register() {
+	for _, cd := range l.catalogues {
+		registerCatalogue(cd)
+	}
+	for _, ad := range l.addons {
+		RegisterAddonDef(ad)
+	}
+	for _, rd := range l.regimes {
+		RegisterRegimeDef(rd)
+	}
+}
+
+// overlayDef replaces the fields of the definition with the properties
+// provided. Fields are reset before being decoded so that data shared with
+// the original definition is never modified.
+func overlayDef(def any, raw map[string]json.RawMessage) error {
+	v := reflect.ValueOf(def).Elem()
+	t := v.Type()
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = v.Field(i)
+	}
+	for k, data := range raw {
+		if k == "$schema" {
+			if err := checkDefSchema(def, data); err != nil {
+				return err
+			}
+			continue
+		}
+		f, ok := fields[k]
+		if !ok {
+			return fmt.Errorf("unknown property '%s'", k)
+		}
+		f.SetZero()
+		if err := json.Unmarshal(data, f.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// checkDefSchema ensures the schema declared in a definition file, if any,
+// matches the type of definition being loaded.
+func checkDefSchema(def any, data json.RawMessage) error {
+	var id schema.ID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return fmt.Errorf("$schema: %w", err)
+	}
+	if exp := schema.Lookup(def); id != exp {
+		return fmt.Errorf("$schema: '%s' does not match '%s'", id, exp)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package tax_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/data"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// esRegimeWithRate provides the embedded Spanish regime data with a new
+// general VAT rate.
+func esRegimeWithRate(t *testing.T, percent string) []byte {
+	t.Helper()
+	src, err := data.Content.ReadFile("regimes/es.json")
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(src, &doc))
+	cat := doc["categories"].([]any)[0].(map[string]any)
+	require.Equal(t, "VAT", cat["code"])
+	rate := cat["rates"].([]any)[0].(map[string]any)
+	require.Equal(t, "general", rate["rate"])
+	rate["values"] = append([]any{
+		map[string]any{"since": "2030-01-01", "percent": percent},
+	}, rate["values"].([]any)...)
+	out, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return out
+}
+
+func generalRate(r *tax.RegimeDef, date cal.Date) string {
+	rd := r.CategoryDef(tax.CategoryVAT).RateDef(tax.KeyStandard, tax.RateGeneral)
+	return rd.Value(date, nil).Percent.String()
+}
+
+func TestLoadDefs(t *testing.T) {
+	orig := tax.RegimeDefFor("ES")
+	require.NotNil(t, orig)
+	t.Cleanup(func() {
+		tax.RegisterRegimeDef(orig)
+	})
+	date := cal.MakeDate(2030, 6, 1)
+
+	t.Run("override regime", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"regimes/es.json": {Data: esRegimeWithRate(t, "22.0%")},
+		}
+		require.NoError(t, tax.LoadDefs(fsys))
+		r := tax.RegimeDefFor("ES")
+		assert.NotSame(t, orig, r)
+		assert.Equal(t, "22.0%", generalRate(r, date))
+		assert.NotNil(t, r.Validator)
+		assert.NotNil(t, r.Normalizer)
+		assert.Equal(t, "21.0%", generalRate(orig, date), "original unchanged")
+	})
+
+	t.Run("partial override", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"regimes/es.json": {Data: []byte(`{"country":"ES","name":{"en":"Spain (patched)"}}`)},
+		}
+		require.NoError(t, tax.LoadDefs(fsys))
+		r := tax.RegimeDefFor("ES")
+		assert.Equal(t, "Spain (patched)", r.Name.String())
+		assert.Equal(t, "22.0%", generalRate(r, date), "previous load kept")
+		assert.Equal(t, orig.Scenarios, r.Scenarios)
+	})
+
+	t.Run("yaml regime", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"regimes/es.yaml": {Data: []byte("$schema: https://gobl.org/draft-0/tax/regime-def\ncountry: ES\nname:\n  en: Spain (yaml)\n")},
+		}
+		require.NoError(t, tax.LoadDefs(fsys))
+		assert.Equal(t, "Spain (yaml)", tax.RegimeDefFor("ES").Name.String())
+	})
+
+	t.Run("new addon", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"addons/xx-test-v1.json": {Data: []byte(`{"key":"xx-test-v1","name":{"en":"Test"}}`)},
+		}
+		require.NoError(t, tax.LoadDefs(fsys))
+		ad := tax.AddonForKey("xx-test-v1")
+		require.NotNil(t, ad)
+		assert.Equal(t, "Test", ad.Name.String())
+	})
+
+	t.Run("override catalogue", func(t *testing.T) {
+		orig := tax.AllCatalogueDefs()[0]
+		t.Cleanup(func() {
+			require.NoError(t, tax.LoadDefs(fstest.MapFS{
+				"catalogues/" + orig.Key.String() + ".json": {Data: []byte(`{"name":` + mustJSON(t, orig.Name) + `}`)},
+			}))
+		})
+		fsys := fstest.MapFS{
+			"catalogues/" + orig.Key.String() + ".json": {Data: []byte(`{"name":{"en":"Patched"}}`)},
+		}
+		require.NoError(t, tax.LoadDefs(fsys))
+		cd := tax.AllCatalogueDefs()[0]
+		assert.Equal(t, "Patched", cd.Name.String())
+		assert.Equal(t, orig.Extensions, cd.Extensions)
+	})
+
+	t.Run("with digests", func(t *testing.T) {
+		src := []byte(`{"country":"ES","name":{"en":"Spain"}}`)
+		sum := sha256.Sum256(src)
+		fsys := fstest.MapFS{
+			"regimes/es.json": {Data: src},
+		}
+		err := tax.LoadDefs(fsys, tax.WithDigests(map[string]string{
+			"regimes/es.json": hex.EncodeToString(sum[:]),
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "Spain", tax.RegimeDefFor("ES").Name.String())
+
+		err = tax.LoadDefs(fsys, tax.WithDigests(map[string]string{
+			"regimes/es.json": "00",
+		}))
+		assert.ErrorContains(t, err, "regimes/es.json: digest mismatch")
+		err = tax.LoadDefs(fsys, tax.WithDigests(map[string]string{}))
+		assert.ErrorContains(t, err, "regimes/es.json: missing digest")
+	})
+
+	t.Run("rejects invalid sources", func(t *testing.T) {
+		r := tax.RegimeDefFor("ES")
+		tests := []struct {
+			name string
+			fsys fstest.MapFS
+			err  string
+		}{
+			{
+				name: "unknown property",
+				fsys: fstest.MapFS{"regimes/es.json": {Data: []byte(`{"rates":[]}`)}},
+				err:  "regimes/es.json: unknown property 'rates'",
+			},
+			{
+				name: "country mismatch",
+				fsys: fstest.MapFS{"regimes/es.json": {Data: []byte(`{"country":"PT"}`)}},
+				err:  "regimes/es.json: country 'PT' does not match file name",
+			},
+			{
+				name: "invalid json",
+				fsys: fstest.MapFS{"regimes/es.json": {Data: []byte(`{"country":`)}},
+				err:  "regimes/es.json: unexpected end of JSON input",
+			},
+			{
+				name: "schema mismatch",
+				fsys: fstest.MapFS{"regimes/es.json": {Data: []byte(`{"$schema":"https://gobl.org/draft-0/tax/addon-def"}`)}},
+				err:  "regimes/es.json: $schema: 'https://gobl.org/draft-0/tax/addon-def' does not match 'https://gobl.org/draft-0/tax/regime-def'",
+			},
+			{
+				name: "duplicate files",
+				fsys: fstest.MapFS{
+					"regimes/es.json": {Data: []byte(`{"country":"ES"}`)},
+					"regimes/es.yml":  {Data: []byte("country: ES\n")},
+				},
+				err: "regimes/es.yml: duplicates regimes/es.json",
+			},
+			{
+				name: "invalid yaml",
+				fsys: fstest.MapFS{"regimes/es.yaml": {Data: []byte("country: [ES\n")}},
+				err:  "regimes/es.yaml: yaml:",
+			},
+			{
+				name: "invalid definition",
+				fsys: fstest.MapFS{"regimes/es.json": {Data: []byte(`{"categories":[]}`)}},
+				err:  "regimes/es.json: categories: cannot be blank",
+			},
+			{
+				name: "one invalid file",
+				fsys: fstest.MapFS{
+					"addons/xx-test-v2.json": {Data: []byte(`{"key":"xx-test-v2","name":{"en":"Test"}}`)},
+					"regimes/es.json":        {Data: []byte(`{"country":"PT"}`)},
+				},
+				err: "does not match file name",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.ErrorContains(t, tax.LoadDefs(tt.fsys), tt.err)
+				assert.Same(t, r, tax.RegimeDefFor("ES"))
+			})
+		}
+		assert.Nil(t, tax.AddonForKey("xx-test-v2"), "nothing registered")
+	})
+
+	t.Run("from directory", func(t *testing.T) {
+		assert.NoError(t, tax.LoadDefsFromDir(t.TempDir()))
+	})
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestParseRegimeDef(t *testing.T) {
+	// compare the embedded data with the definitions built in Go
+	files, err := fs.Glob(data.Content, "regimes/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		t.Run(f, func(t *testing.T) {
+			src, err := data.Content.ReadFile(f)
+			require.NoError(t, err)
+			rd, err := tax.ParseRegimeDef(src)
+			require.NoError(t, err)
+			orig := tax.RegimeDefFor(rd.Country.Code())
+			require.NotNil(t, orig)
+			assert.JSONEq(t, mustJSON(t, orig), mustJSON(t, rd))
+		})
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		orig := tax.RegimeDefFor("ES")
+		data, err := yaml.JSONToYAML([]byte(mustJSON(t, orig)))
+		require.NoError(t, err)
+		rd, err := tax.ParseRegimeDef(data)
+		require.NoError(t, err)
+		assert.JSONEq(t, mustJSON(t, orig), mustJSON(t, rd))
+		assert.Nil(t, rd.Validator, "no behavior")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := tax.ParseRegimeDef([]byte(`{"country":"ES"}`))
+		assert.ErrorContains(t, err, "categories: cannot be blank")
+		_, err = tax.ParseRegimeDef([]byte(`{"rates":[]}`))
+		assert.ErrorContains(t, err, "unknown property 'rates'")
+	})
+}
+
+func TestParseAddonDef(t *testing.T) {
+	files, err := fs.Glob(data.Content, "addons/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		t.Run(f, func(t *testing.T) {
+			src, err := data.Content.ReadFile(f)
+			require.NoError(t, err)
+			ad, err := tax.ParseAddonDef(src)
+			require.NoError(t, err)
+			orig := tax.AddonForKey(ad.Key)
+			require.NotNil(t, orig)
+			assert.JSONEq(t, mustJSON(t, orig), mustJSON(t, ad))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := tax.ParseAddonDef([]byte("key: xx-test-v9\n"))
+		assert.ErrorContains(t, err, "name: cannot be blank")
+	})
+}
@@ -1,7 +1,6 @@
 package tax
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/invopop/gobl/cbc"
@@ -136,7 +135,7 @@ func (tv *tagValidation) Validate(val interface{}) error {
 	for i, k := range list {
 		if !k.In(tv.keys...) {
 			return validation.Errors{
-				strconv.Itoa(i): fmt.Errorf("'%s' undefined", k),
+				strconv.Itoa(i): ErrTagUndefined.WithParams(map[string]any{"tag": k}),
 			}
 		}
 	}
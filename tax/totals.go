@@ -37,6 +37,14 @@ type RateTotal struct {
 	Surcharge *RateTotalSurcharge `json:"surcharge,omitempty" jsonschema:"title=Surcharge"`
 	// Total amount of rate, excluding surcharges
 	Amount num.Amount `json:"amount" jsonschema:"title=Amount"`
+
+	lines *rateLineTotals // when rounding per line
+}
+
+// rateLineTotals contains the sums of tax amounts rounded for each line.
+type rateLineTotals struct {
+	amount    num.Amount
+	surcharge num.Amount
 }
 
 // RateTotalSurcharge reflects the sum surcharges inside the rate.
@@ -393,11 +401,25 @@ func (t *Total) calculateBaseCategoryTotal(ct *CategoryTotal, zero num.Amount, r
 			continue // exempt, nothing else to do
 		}
 		base := rt.Base
-		rt.Amount = rt.Percent.Of(rt.Base)
+		switch {
+		case rr == RoundingRuleLine && rt.lines != nil:
+			rt.Amount = rt.lines.amount
+		case rr == RoundingRuleHalfEven:
+			rt.Amount = rt.Percent.Of(base.RescaleUp(zero.Exp() + 2)).RescaleHalfEven(zero.Exp())
+		default:
+			rt.Amount = rt.Percent.Of(base)
+		}
 		ct.Amount = matchRoundingPrecision(rr, ct.Amount, rt.Amount)
 		ct.Amount = ct.Amount.Add(rt.Amount)
 		if rt.Surcharge != nil {
-			rt.Surcharge.Amount = rt.Surcharge.Percent.Of(base)
+			switch {
+			case rr == RoundingRuleLine && rt.lines != nil:
+				rt.Surcharge.Amount = rt.lines.surcharge
+			case rr == RoundingRuleHalfEven:
+				rt.Surcharge.Amount = rt.Surcharge.Percent.Of(base.RescaleUp(zero.Exp() + 2)).RescaleHalfEven(zero.Exp())
+			default:
+				rt.Surcharge.Amount = rt.Surcharge.Percent.Of(base)
+			}
 			if ct.Surcharge == nil {
 				ct.Surcharge = &zero
 			}
@@ -414,7 +436,7 @@ func (t *Total) calculateBaseCategoryTotal(ct *CategoryTotal, zero num.Amount, r
 // the rounding rule.
 func matchRoundingPrecision(rr cbc.Key, a, b num.Amount) num.Amount {
 	switch rr {
-	case RoundingRuleCurrency:
+	case RoundingRuleCurrency, RoundingRuleLine, RoundingRuleHalfEven:
 		return a // maintain original precision
 	}
 	return a.MatchPrecision(b)
@@ -1,6 +1,8 @@
 package tax
 
 import (
+	"iter"
+
 	"github.com/invopop/gobl/cal"
 	"github.com/invopop/gobl/cbc"
 	"github.com/invopop/gobl/currency"
@@ -18,7 +20,8 @@ type TotalCalculator struct {
 	Lines    []TaxableLine
 	Includes cbc.Code // Tax included in price
 
-	zero num.Amount
+	zero     num.Amount
+	taxLines []taxLine // reused between calculations
 }
 
 // TaxableLine defines what we expect from a line in order to subsequently calculate
@@ -30,14 +33,11 @@ type TaxableLine interface {
 
 // Calculate the totals
 func (tc *TotalCalculator) Calculate(t *Total) error {
-	tc.zero = tc.Currency.Def().Zero()
-
-	// reset
-	t.Categories = make([]*CategoryTotal, 0)
-	t.Sum = tc.zero
+	tc.Reset(t)
 
 	// get simplified list of lines
-	taxLines := mapTaxLines(tc.Lines)
+	tc.taxLines = mapTaxLines(tc.taxLines, tc.Lines)
+	taxLines := tc.taxLines
 	if err := tc.prepareLines(taxLines); err != nil {
 		return err
 	}
@@ -48,73 +48,160 @@ func (tc *TotalCalculator) Calculate(t *Total) error {
 	}
 
 	tc.calculateBaseRateTotals(taxLines, t)
-	t.Calculate(tc.Currency, tc.Rounding)
+	tc.Complete(t)
+
+	return nil
+}
+
+// CalculateSeq performs the same calculations as Calculate, but using the
+// lines provided by the iterator instead of the Lines property, so that
+// very large documents do not need to be kept in memory.
+func (tc *TotalCalculator) CalculateSeq(t *Total, lines iter.Seq[TaxableLine]) error {
+	tc.Reset(t)
+	for l := range lines {
+		if err := tc.Add(t, l); err != nil {
+			return err
+		}
+	}
+	tc.Complete(t)
+	return nil
+}
+
+// Reset prepares the total so that lines can be included one at a time
+// using Add, after which Complete must be called.
+func (tc *TotalCalculator) Reset(t *Total) {
+	tc.zero = tc.Currency.Def().Zero()
+	t.Categories = make([]*CategoryTotal, 0)
+	t.Sum = tc.zero
+}
 
+// Add includes the line's taxes in the total's base amounts. The line is
+// not retained, so may be discarded or re-used afterwards.
+func (tc *TotalCalculator) Add(t *Total, line TaxableLine) error {
+	tl := taxLine{
+		total: line.GetTotal(),
+		taxes: line.GetTaxes(),
+	}
+	if err := tc.prepareLine(&tl); err != nil {
+		return err
+	}
+	if err := tc.removeIncludedTax(&tl); err != nil {
+		return err
+	}
+	tc.addBaseRateTotals(&tl, t)
 	return nil
 }
 
-func (tc *TotalCalculator) prepareLines(taxLines []*taxLine) error {
+// Complete calculates the tax amounts and sums from the base amounts of
+// the lines added to the total.
+func (tc *TotalCalculator) Complete(t *Total) {
+	t.Calculate(tc.Currency, tc.Rounding)
+}
+
+func (tc *TotalCalculator) prepareLines(taxLines []taxLine) error {
 	// First, prepare all tax combos using the country and date
-	for _, tl := range taxLines {
-		for _, combo := range tl.taxes {
-			if err := combo.calculate(tc.Country, tc.Date); err != nil {
-				return err
-			}
-			// always add 2 decimal places for all tax calculations
-			tl.total = tl.total.RescaleUp(tc.zero.Exp() + 2)
+	for i := range taxLines {
+		if err := tc.prepareLine(&taxLines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *TotalCalculator) prepareLine(tl *taxLine) error {
+	for _, combo := range tl.taxes {
+		if err := combo.calculate(tc.Country, tc.Date); err != nil {
+			return err
 		}
+		// always add 2 decimal places for all tax calculations
+		tl.total = tl.total.RescaleUp(tc.zero.Exp() + 2)
 	}
 	return nil
 }
 
-func (tc *TotalCalculator) removeIncludedTaxes(taxLines []*taxLine) error {
+func (tc *TotalCalculator) removeIncludedTaxes(taxLines []taxLine) error {
 	// If prices include a tax, perform a pre-loop to update all the line prices with
 	// the price minus the defined tax.
+	for i := range taxLines {
+		if err := tc.removeIncludedTax(&taxLines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *TotalCalculator) removeIncludedTax(tl *taxLine) error {
 	if tc.Includes.IsEmpty() {
 		return nil
 	}
-	for _, tl := range taxLines {
-		if c := tl.taxes.Get(tc.Includes); c != nil {
-			if c.retained {
-				return ErrInvalidPricesInclude.WithMessage("cannot include retained category '%s'", tc.Includes.String())
-			}
-			if c.informative {
-				return ErrInvalidPricesInclude.WithMessage("cannot include informative category '%s'", tc.Includes.String())
-			}
-			if c.Percent == nil {
-				// no taxes, skip
-				continue
-			}
-			tl.total = tl.total.Remove(*c.Percent)
+	if c := tl.taxes.Get(tc.Includes); c != nil {
+		if c.retained {
+			return ErrInvalidPricesInclude.WithMessage("cannot include retained category '%s'", tc.Includes.String())
+		}
+		if c.informative {
+			return ErrInvalidPricesInclude.WithMessage("cannot include informative category '%s'", tc.Includes.String())
+		}
+		if c.Percent == nil {
+			// no taxes, skip
+			return nil
 		}
+		tl.total = tl.total.Remove(*c.Percent)
 	}
 	return nil
 }
 
-func (tc *TotalCalculator) calculateBaseRateTotals(taxLines []*taxLine, t *Total) {
+func (tc *TotalCalculator) calculateBaseRateTotals(taxLines []taxLine, t *Total) {
 	// Go through each line and add the total to the base of each tax
-	for _, tl := range taxLines {
-		for _, c := range tl.taxes {
-			rt := t.rateTotalFor(c, tc.zero)
-			rt.Base = matchRoundingPrecision(tc.Rounding, rt.Base, tl.total)
-			rt.Base = rt.Base.Add(tl.total)
+	for i := range taxLines {
+		tc.addBaseRateTotals(&taxLines[i], t)
+	}
+}
+
+func (tc *TotalCalculator) addBaseRateTotals(tl *taxLine, t *Total) {
+	for _, c := range tl.taxes {
+		rt := t.rateTotalFor(c, tc.zero)
+		rt.Base = matchRoundingPrecision(tc.Rounding, rt.Base, tl.total)
+		rt.Base = rt.Base.Add(tl.total)
+		if tc.Rounding == RoundingRuleLine {
+			tc.addLineAmounts(rt, c, tl.total)
 		}
 	}
 }
 
+// addLineAmounts calculates and rounds the tax amounts of a single line
+// so they can be summed in the rate total.
+func (tc *TotalCalculator) addLineAmounts(rt *RateTotal, c *Combo, total num.Amount) {
+	if c.Percent == nil {
+		return
+	}
+	if rt.lines == nil {
+		rt.lines = &rateLineTotals{amount: tc.zero, surcharge: tc.zero}
+	}
+	exp := tc.zero.Exp()
+	rt.lines.amount = rt.lines.amount.Add(c.Percent.Of(total).Rescale(exp))
+	if c.Surcharge != nil {
+		rt.lines.surcharge = rt.lines.surcharge.Add(c.Surcharge.Of(total).Rescale(exp))
+	}
+}
+
 // taxLine is used to replace
 type taxLine struct {
 	total num.Amount
 	taxes Set
 }
 
-func mapTaxLines(lines []TaxableLine) []*taxLine {
-	tls := make([]*taxLine, len(lines))
+// mapTaxLines prepares the simplified list of lines, reusing the provided
+// buffer if it has enough capacity to avoid allocations.
+func mapTaxLines(buf []taxLine, lines []TaxableLine) []taxLine {
+	if cap(buf) < len(lines) {
+		buf = make([]taxLine, len(lines))
+	}
+	buf = buf[:len(lines)]
 	for i, v := range lines {
-		tls[i] = &taxLine{
+		buf[i] = taxLine{
 			total: v.GetTotal(),
 			taxes: v.GetTaxes(),
 		}
 	}
-	return tls
+	return buf
 }
@@ -2,6 +2,8 @@ package tax_test
 
 import (
 	"encoding/json"
+	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/invopop/gobl/addons/es/tbai"
@@ -1326,6 +1328,88 @@ func TestTotalBySumCalculate(t *testing.T) {
 				Sum: num.MakeAmount(348, 2), // with sum-then-round this would be 3.49
 			},
 		},
+		{
+			desc:     "line rounding calculation",
+			rounding: tax.RoundingRuleLine,
+			lines: []tax.TaxableLine{
+				&taxableLine{
+					taxes: tax.Set{
+						{
+							Category: tax.CategoryVAT,
+							Rate:     tax.RateReduced,
+						},
+					},
+					amount: num.MakeAmount(5, 2),
+				},
+				&taxableLine{
+					taxes: tax.Set{
+						{
+							Category: tax.CategoryVAT,
+							Rate:     tax.RateReduced,
+						},
+					},
+					amount: num.MakeAmount(5, 2),
+				},
+				&taxableLine{
+					taxes: tax.Set{
+						{
+							Category: tax.CategoryVAT,
+							Rate:     tax.RateReduced,
+						},
+					},
+					amount: num.MakeAmount(5, 2),
+				},
+			},
+			want: &tax.Total{
+				Categories: []*tax.CategoryTotal{
+					{
+						Code: tax.CategoryVAT,
+						Rates: []*tax.RateTotal{
+							{
+								Key:     tax.KeyStandard,
+								Base:    num.MakeAmount(15, 2),
+								Percent: num.NewPercentage(100, 3),
+								Amount:  num.MakeAmount(3, 2), // 0.02 with other rules
+							},
+						},
+						Amount: num.MakeAmount(3, 2),
+					},
+				},
+				Sum: num.MakeAmount(3, 2),
+			},
+		},
+		{
+			desc:     "half even rounding calculation",
+			rounding: tax.RoundingRuleHalfEven,
+			lines: []tax.TaxableLine{
+				&taxableLine{
+					taxes: tax.Set{
+						{
+							Category: tax.CategoryVAT,
+							Rate:     tax.RateReduced,
+						},
+					},
+					amount: num.MakeAmount(25, 2),
+				},
+			},
+			want: &tax.Total{
+				Categories: []*tax.CategoryTotal{
+					{
+						Code: tax.CategoryVAT,
+						Rates: []*tax.RateTotal{
+							{
+								Key:     tax.KeyStandard,
+								Base:    num.MakeAmount(25, 2),
+								Percent: num.NewPercentage(100, 3),
+								Amount:  num.MakeAmount(2, 2), // 0.03 with currency rounding
+							},
+						},
+						Amount: num.MakeAmount(2, 2),
+					},
+				},
+				Sum: num.MakeAmount(2, 2),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -1371,3 +1455,69 @@ func TestTotalBySumCalculate(t *testing.T) {
 	}
 
 }
+
+func benchmarkTaxableLines(count int) []tax.TaxableLine {
+	lines := make([]tax.TaxableLine, count)
+	rates := []cbc.Key{tax.RateGeneral, tax.RateReduced, tax.RateSuperReduced}
+	for i := range lines {
+		lines[i] = &taxableLine{
+			taxes: tax.Set{
+				{Category: tax.CategoryVAT, Rate: rates[i%len(rates)]},
+				{Category: es.TaxCategoryIRPF, Rate: es.TaxRatePro},
+			},
+			amount: num.MakeAmount(int64(1000+i), 2),
+		}
+	}
+	return lines
+}
+
+func TestTotalCalculatorCalculateSeq(t *testing.T) {
+	lines := benchmarkTaxableLines(500)
+	tc := &tax.TotalCalculator{
+		Country:  "ES",
+		Currency: currency.EUR,
+		Date:     cal.MakeDate(2024, 1, 1),
+		Lines:    lines,
+		Includes: tax.CategoryVAT,
+	}
+	exp := new(tax.Total)
+	require.NoError(t, tc.Calculate(exp))
+
+	tc.Lines = nil
+	res := new(tax.Total)
+	require.NoError(t, tc.CalculateSeq(res, slices.Values(lines)))
+	assert.Equal(t, exp, res)
+	assert.Equal(t, "631.5521", res.Sum.String())
+
+	t.Run("with error", func(t *testing.T) {
+		bad := &taxableLine{
+			taxes:  tax.Set{{Category: "VAT", Rate: "invalid"}},
+			amount: num.MakeAmount(100, 2),
+		}
+		seq := slices.Values([]tax.TaxableLine{lines[0], bad})
+		assert.ErrorContains(t, tc.CalculateSeq(new(tax.Total), seq), "invalid")
+	})
+}
+
+func BenchmarkTotalCalculator(b *testing.B) {
+	for _, count := range []int{100, 10000} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			lines := benchmarkTaxableLines(count)
+			tc := &tax.TotalCalculator{
+				Country:  "ES",
+				Currency: currency.EUR,
+				Date:     cal.MakeDate(2024, 1, 1),
+				Lines:    lines,
+				Includes: tax.CategoryVAT,
+			}
+			t := new(tax.Total)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := tc.Calculate(t); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package tax
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 
 	"github.com/invopop/validation"
 )
@@ -10,6 +12,7 @@ type contextKey string
 
 const (
 	validtorsKey contextKey = "validators"
+	errorsMaxKey contextKey = "errors-max"
 )
 
 // Validator is used for functions that will validate the provided object
@@ -40,14 +43,80 @@ func Validators(ctx context.Context) []Validator {
 // method to add an additional tax specific validation checks from the context. See
 // also the ContextWithValidator method.
 func ValidateStructWithContext(ctx context.Context, obj any, fields ...*validation.FieldRules) error {
+	eb := errorBudgetFromContext(ctx)
+	if eb.exhausted() {
+		// enough errors have already been found elsewhere in the document
+		return nil
+	}
 	// First run regular validation
 	if err := validation.ValidateStructWithContext(ctx, obj, fields...); err != nil {
+		eb.spend(err)
 		return err
 	}
 	for _, validator := range Validators(ctx) {
 		if err := validator(obj); err != nil {
+			eb.spend(err)
 			return err
 		}
 	}
 	return nil
 }
+
+// ContextWithFailFast prepares a context that will stop validating a
+// document as soon as the first error is found. This is useful when all
+// that is needed is to know if the document is valid, as invalid documents
+// will not be inspected completely.
+func ContextWithFailFast(ctx context.Context) context.Context {
+	return ContextWithMaxErrors(ctx, 1)
+}
+
+// ContextWithMaxErrors prepares a context that will stop validating the
+// objects of a document once at least the given number of errors have been
+// found. Objects already being validated will still report all of their
+// field errors, so the final number may be higher. A value of zero or less
+// implies no limit.
+func ContextWithMaxErrors(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return context.WithValue(ctx, errorsMaxKey, (*errorBudget)(nil))
+	}
+	return context.WithValue(ctx, errorsMaxKey, &errorBudget{max: int64(n)})
+}
+
+// errorBudget keeps track of the errors found during validation, which may
+// run concurrently.
+type errorBudget struct {
+	max   int64
+	count atomic.Int64
+}
+
+func errorBudgetFromContext(ctx context.Context) *errorBudget {
+	if ctx == nil {
+		return nil
+	}
+	eb, _ := ctx.Value(errorsMaxKey).(*errorBudget)
+	return eb
+}
+
+func (eb *errorBudget) exhausted() bool {
+	return eb != nil && eb.count.Load() >= eb.max
+}
+
+// spend counts the field errors directly reported by an object. Errors
+// from nested objects will have been counted when they were validated.
+func (eb *errorBudget) spend(err error) {
+	if eb == nil {
+		return
+	}
+	var n int64 = 1
+	var ve validation.Errors
+	if errors.As(err, &ve) {
+		n = 0
+		for _, e := range ve {
+			var nested validation.Errors
+			if !errors.As(e, &nested) {
+				n++
+			}
+		}
+	}
+	eb.count.Add(n)
+}
@@ -31,3 +31,52 @@ func TestValidateStructWithContext(t *testing.T) {
 
 	})
 }
+
+type failFastItem struct {
+	Name string `json:"name"`
+}
+
+func (i *failFastItem) ValidateWithContext(ctx context.Context) error {
+	return tax.ValidateStructWithContext(ctx, i,
+		validation.Field(&i.Name, validation.Required),
+	)
+}
+
+type failFastDoc struct {
+	Items []*failFastItem `json:"items"`
+}
+
+func (d *failFastDoc) ValidateWithContext(ctx context.Context) error {
+	return tax.ValidateStructWithContext(ctx, d,
+		validation.Field(&d.Items),
+	)
+}
+
+func TestContextWithMaxErrors(t *testing.T) {
+	doc := &failFastDoc{
+		Items: []*failFastItem{{}, {Name: "ok"}, {}, {}},
+	}
+	t.Run("all errors", func(t *testing.T) {
+		err := doc.ValidateWithContext(context.Background())
+		assert.EqualError(t, err, "items: (0: (name: cannot be blank.); 2: (name: cannot be blank.); 3: (name: cannot be blank.).).")
+	})
+	t.Run("fail fast", func(t *testing.T) {
+		ctx := tax.ContextWithFailFast(context.Background())
+		err := doc.ValidateWithContext(ctx)
+		assert.EqualError(t, err, "items: (0: (name: cannot be blank.).).")
+	})
+	t.Run("max errors", func(t *testing.T) {
+		ctx := tax.ContextWithMaxErrors(context.Background(), 2)
+		err := doc.ValidateWithContext(ctx)
+		assert.EqualError(t, err, "items: (0: (name: cannot be blank.); 2: (name: cannot be blank.).).")
+	})
+	t.Run("no limit", func(t *testing.T) {
+		ctx := tax.ContextWithMaxErrors(tax.ContextWithFailFast(context.Background()), 0)
+		err := doc.ValidateWithContext(ctx)
+		assert.ErrorContains(t, err, "3: (name: cannot be blank.)")
+	})
+	t.Run("valid", func(t *testing.T) {
+		ctx := tax.ContextWithFailFast(context.Background())
+		assert.NoError(t, (&failFastDoc{}).ValidateWithContext(ctx))
+	})
+}
@@ -0,0 +1,192 @@
+package testutil
+
+import (
+	"strings"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// Item provides a random item with a price.
+func (g *Generator) Item() *org.Item {
+	price := g.amount(1, 500, 2)
+	item := &org.Item{
+		Name:  g.pick(productNames),
+		Price: &price,
+		Unit:  units[g.rnd.IntN(len(units))],
+	}
+	if typ, ok := itemIdentityTypes[g.regime]; ok {
+		item.Identities = []*org.Identity{
+			{Type: typ, Code: cbc.Code(g.digits(8))},
+		}
+	}
+	return item
+}
+
+// Taxes provides a random tax combination using one of the rates of the
+// regime's main category that applies on the generator's date. An empty
+// set is provided if the regime has no rates.
+func (g *Generator) Taxes() tax.Set {
+	r := g.Regime()
+	if r == nil {
+		return nil
+	}
+	for _, cat := range r.Categories {
+		if cat.Retained || cat.Informative {
+			continue
+		}
+		rates := make([]*tax.RateDef, 0, len(cat.Rates))
+		for _, rd := range cat.Rates {
+			if strings.Contains(rd.Rate.String(), cbc.KeySeparator) {
+				// combined rates, like surcharges, require additional conditions
+				continue
+			}
+			if len(rd.Keys) == 0 {
+				// rates must be associated with a key to be used
+				continue
+			}
+			if rv := rd.Value(g.date, nil); rv != nil && !rv.Disabled && rv.Surcharge == nil {
+				rates = append(rates, rd)
+			}
+		}
+		if len(rates) == 0 {
+			continue
+		}
+		rd := rates[g.rnd.IntN(len(rates))]
+		combo := &tax.Combo{Category: cat.Code, Rate: rd.Rate}
+		if !tax.KeyStandard.In(rd.Keys...) {
+			combo.Key = rd.Keys[0]
+		}
+		return tax.Set{combo}
+	}
+	return nil
+}
+
+// Line provides a random invoice line, which may include a discount.
+func (g *Generator) Line() *bill.Line {
+	l := &bill.Line{
+		Quantity: g.quantity(),
+		Item:     g.Item(),
+		Taxes:    g.Taxes(),
+	}
+	if g.chance(0.2) {
+		l.Discounts = []*bill.LineDiscount{
+			{
+				Reason:  "Promotion",
+				Percent: num.NewPercentage(int64(g.between(1, 30)), 2),
+			},
+		}
+	}
+	return l
+}
+
+// Lines provides between 1 and the maximum number of random lines.
+func (g *Generator) Lines(maximum int) []*bill.Line {
+	lines := make([]*bill.Line, g.between(1, maximum))
+	for i := range lines {
+		lines[i] = g.Line()
+	}
+	return lines
+}
+
+// Invoice provides a random standard invoice between two parties of the
+// regime's country that has been calculated and validated. The error
+// reports any problems found, which would usually indicate the regime or
+// add-ons require data the generator does not yet provide.
+func (g *Generator) Invoice() (*bill.Invoice, error) {
+	inv := &bill.Invoice{
+		Series:    g.code("S"),
+		Code:      g.code(""),
+		IssueDate: g.date,
+		Supplier:  g.Party(),
+		Customer:  g.Party(),
+		Lines:     g.Lines(10),
+	}
+	inv.SetRegime(g.regime)
+	inv.SetAddons(g.addons...)
+	if g.chance(0.2) {
+		inv.Discounts = []*bill.Discount{
+			{
+				Reason:  "Volume discount",
+				Percent: num.NewPercentage(int64(g.between(1, 10)), 2),
+				Taxes:   g.Taxes(),
+			},
+		}
+	}
+	if g.chance(0.1) {
+		inv.Charges = []*bill.Charge{
+			{
+				Reason: "Handling",
+				Amount: g.amount(1, 50, 2),
+				Taxes:  g.Taxes(),
+			},
+		}
+	}
+	if g.chance(0.3) {
+		inv.Notes = []*org.Note{
+			{Key: org.NoteKeyGeneral, Text: "Thank you for your business."},
+		}
+	}
+	if err := inv.Calculate(); err != nil {
+		return nil, err
+	}
+	if err := inv.Validate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Order provides a random purchase order that has been calculated and
+// validated.
+func (g *Generator) Order() (*bill.Order, error) {
+	ord := &bill.Order{
+		Type:      bill.OrderTypePurchase,
+		Code:      g.code("PO-"),
+		IssueDate: g.date,
+		Supplier:  g.Party(),
+		Customer:  g.Party(),
+		Lines:     g.Lines(10),
+	}
+	ord.SetRegime(g.regime)
+	ord.SetAddons(g.addons...)
+	if err := ord.Calculate(); err != nil {
+		return nil, err
+	}
+	if err := ord.Validate(); err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+// Delivery provides a random delivery note that has been calculated and
+// validated.
+func (g *Generator) Delivery() (*bill.Delivery, error) {
+	dlv := &bill.Delivery{
+		Type:      bill.DeliveryTypeNote,
+		Code:      g.code("DN-"),
+		IssueDate: g.date,
+		Supplier:  g.Party(),
+		Customer:  g.Party(),
+		Lines:     g.Lines(10),
+	}
+	dlv.SetRegime(g.regime)
+	dlv.SetAddons(g.addons...)
+	if err := dlv.Calculate(); err != nil {
+		return nil, err
+	}
+	if err := dlv.Validate(); err != nil {
+		return nil, err
+	}
+	return dlv, nil
+}
+
+// quantity provides a random whole or fractional quantity.
+func (g *Generator) quantity() num.Amount {
+	if g.chance(0.2) {
+		return g.amount(0, 20, 2).Add(num.MakeAmount(1, 0))
+	}
+	return num.MakeAmount(int64(g.between(1, 50)), 0)
+}
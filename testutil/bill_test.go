@@ -0,0 +1,110 @@
+package testutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/num"
+	"github.com/invopop/gobl/tax"
+	"github.com/invopop/gobl/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDate = cal.MakeDate(2025, 3, 1)
+
+func l10nCode(c string) l10n.TaxCountryCode {
+	return l10n.TaxCountryCode(c)
+}
+
+func TestInvoice(t *testing.T) {
+	for _, r := range tax.AllRegimeDefs() {
+		t.Run(r.Country.String(), func(t *testing.T) {
+			for seed := uint64(0); seed < 20; seed++ {
+				g := testutil.NewGenerator(seed, testutil.WithRegime(r.Country), testutil.WithDate(testDate))
+				inv, err := g.Invoice()
+				require.NoError(t, err, "seed %d", seed)
+				assertInvoiceTotals(t, inv)
+			}
+		})
+	}
+	t.Run("with addons", func(t *testing.T) {
+		for seed := uint64(0); seed < 20; seed++ {
+			g := testutil.NewGenerator(seed,
+				testutil.WithRegime("DE"),
+				testutil.WithAddons("eu-en16931-v2017"),
+				testutil.WithDate(testDate),
+			)
+			inv, err := g.Invoice()
+			require.NoError(t, err, "seed %d", seed)
+			assert.Equal(t, "eu-en16931-v2017", inv.GetAddons()[0].String())
+		}
+	})
+}
+
+func TestOrder(t *testing.T) {
+	g := testutil.NewGenerator(1, testutil.WithRegime("FR"), testutil.WithDate(testDate))
+	for i := 0; i < 10; i++ {
+		ord, err := g.Order()
+		require.NoError(t, err)
+		assert.Equal(t, bill.OrderTypePurchase, ord.Type)
+		assert.NotNil(t, ord.Totals)
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	g := testutil.NewGenerator(1, testutil.WithRegime("PT"), testutil.WithDate(testDate))
+	for i := 0; i < 10; i++ {
+		dlv, err := g.Delivery()
+		require.NoError(t, err)
+		assert.Equal(t, bill.DeliveryTypeNote, dlv.Type)
+	}
+}
+
+// FuzzInvoiceRecalculate ensures calculating an invoice a second time after
+// serializing it provides the same totals.
+func FuzzInvoiceRecalculate(f *testing.F) {
+	for _, seed := range []uint64{0, 1, 2, 3} {
+		f.Add(seed, "ES")
+	}
+	f.Add(uint64(5), "DE")
+	f.Add(uint64(6), "IT")
+	f.Fuzz(func(t *testing.T, seed uint64, country string) {
+		r := tax.RegimeDefFor(l10n.Code(country))
+		if r == nil {
+			t.Skip()
+		}
+		g := testutil.NewGenerator(seed, testutil.WithRegime(r.Country), testutil.WithDate(testDate))
+		inv, err := g.Invoice()
+		require.NoError(t, err)
+		data, err := json.Marshal(inv)
+		require.NoError(t, err)
+		inv2 := new(bill.Invoice)
+		require.NoError(t, json.Unmarshal(data, inv2))
+		require.NoError(t, inv2.Calculate())
+		assert.Equal(t, inv.Totals, inv2.Totals)
+	})
+}
+
+// assertInvoiceTotals checks the invariants of the invoice's totals, which
+// allow for differences caused by rounding.
+func assertInvoiceTotals(t *testing.T, inv *bill.Invoice) {
+	t.Helper()
+	sum := num.MakeAmount(0, 2)
+	for _, l := range inv.Lines {
+		sum = sum.Add(*l.Total)
+	}
+	tt := inv.Totals
+	assertClose(t, sum, tt.Sum, len(inv.Lines), "line sum")
+	assertClose(t, tt.Total.Add(tt.Tax), tt.TotalWithTax, 1, "total with tax")
+	assert.True(t, tt.Payable.Compare(tt.TotalWithTax) == 0 || tt.Rounding != nil, "payable")
+}
+
+func assertClose(t *testing.T, expected, actual num.Amount, cents int, msg string) {
+	t.Helper()
+	diff := expected.Subtract(actual).Abs()
+	assert.True(t, diff.Compare(num.MakeAmount(int64(cents), 2)) <= 0, "%s: %s != %s", msg, expected, actual)
+}
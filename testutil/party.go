@@ -0,0 +1,118 @@
+package testutil
+
+import (
+	"strings"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/l10n"
+	"github.com/invopop/gobl/org"
+	"github.com/invopop/gobl/tax"
+)
+
+// taxCodes contains valid tax identity codes for each country that
+// parties may be assigned. Checksums make generating codes for every
+// country impractical, so a pool of known valid codes is used instead.
+var taxCodes = map[l10n.TaxCountryCode][]cbc.Code{
+	"AE": {"123456789012345", "070332055905639", "030796402086184", "025782207666741"},
+	"AT": {"U41760171", "U28267078", "U83417857", "U54889777"},
+	"AU": {"51824753556", "53004085616", "18409070836", "12115883128"},
+	"BE": {"0387883994", "0521247118", "0542115380", "0413172884"},
+	"CH": {"E708447704", "E306948896", "E768338390", "E994677522"},
+	"DE": {"111111125", "282741168", "971861516", "105713563"},
+	"EL": {"177472438", "841442160", "196839688", "666583322"},
+	"ES": {"B98602642", "54387763P", "58109902B", "93077783A"},
+	"FR": {"44732829320", "39356000000", "32356000000", "46356000000"},
+	"GB": {"350983637", "000472631", "469959071", "186980801"},
+	"IT": {"12345678903", "13029381004", "31059336722", "60876130539"},
+	"NL": {"808661863B01", "000099995B57", "548653033B01", "070293612B01"},
+	"PL": {"9876543210", "4396373840", "1903223481", "1509832303"},
+	"PT": {"514329874", "545259045", "317391496", "181342014"},
+}
+
+// isoCountries maps tax country codes to their ISO equivalent when they
+// differ.
+var isoCountries = map[l10n.TaxCountryCode]l10n.ISOCountryCode{
+	"EL": "GR",
+}
+
+// itemIdentityTypes defines the type of the numeric classification codes
+// items require in some countries.
+var itemIdentityTypes = map[l10n.TaxCountryCode]cbc.Code{
+	"IN": "HSN",
+}
+
+// postCodeLengths defines the number of digits used by postal codes in
+// each country, when not 5.
+var postCodeLengths = map[l10n.TaxCountryCode]int{
+	"AT": 4,
+	"AU": 4,
+	"BE": 4,
+	"BR": 8,
+	"CH": 4,
+	"IN": 6,
+	"NL": 4,
+}
+
+var (
+	companyPrefixes = []string{"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Soylent", "Cyberdyne"}
+	companySuffixes = []string{"Industries", "Consulting", "Trading", "Logistics", "Software", "Foods", "Systems", "Partners"}
+	streets         = []string{"Main Street", "High Street", "Station Road", "Church Lane", "Market Square", "Park Avenue"}
+	localities      = []string{"Springfield", "Riverside", "Fairview", "Greenville", "Kingston", "Lakeside"}
+	productNames    = []string{"Consulting services", "Development hours", "Office chair", "Laptop stand", "Printer paper", "Coffee beans", "Software license", "Maintenance plan", "Training session", "Delivery"}
+	units           = []org.Unit{org.UnitHour, org.UnitItem, org.UnitKilogram, org.UnitPackage, org.UnitService}
+)
+
+// TaxIDCodes provides the pool of valid tax identity codes used for the
+// country, if any.
+func TaxIDCodes(country l10n.TaxCountryCode) []cbc.Code {
+	return taxCodes[country]
+}
+
+// TaxID provides a random tax identity for the country, which will only
+// contain a code if valid codes are known for the country.
+func (g *Generator) TaxID(country l10n.TaxCountryCode) *tax.Identity {
+	tID := &tax.Identity{Country: country}
+	if codes := taxCodes[country]; len(codes) > 0 {
+		tID.Code = codes[g.rnd.IntN(len(codes))]
+	}
+	return tID
+}
+
+// Party provides a random company from the generator's regime country
+// with a tax identity, address, and email.
+func (g *Generator) Party() *org.Party {
+	return g.PartyFrom(g.regime)
+}
+
+// PartyFrom provides a random company from the country.
+func (g *Generator) PartyFrom(country l10n.TaxCountryCode) *org.Party {
+	name := g.pick(companyPrefixes) + " " + g.pick(companySuffixes)
+	iso, ok := isoCountries[country]
+	if !ok {
+		iso = l10n.ISOCountryCode(country)
+	}
+	domain := strings.ToLower(strings.ReplaceAll(name, " ", "")) + ".example.com"
+	return &org.Party{
+		Name:  name,
+		TaxID: g.TaxID(country),
+		Addresses: []*org.Address{
+			{
+				Number:   g.digits(2),
+				Street:   g.pick(streets),
+				Locality: g.pick(localities),
+				Code:     cbc.Code(g.digits(postCodeLength(country))),
+				Country:  iso,
+			},
+		},
+		Emails: []*org.Email{
+			{Address: "billing@" + domain},
+		},
+	}
+}
+
+func postCodeLength(country l10n.TaxCountryCode) int {
+	if n, ok := postCodeLengths[country]; ok {
+		return n
+	}
+	return 5
+}
@@ -0,0 +1,32 @@
+package testutil_test
+
+import (
+	"testing"
+
+	"github.com/invopop/gobl/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxIDCodes(t *testing.T) {
+	for _, c := range []string{"AE", "AT", "AU", "BE", "CH", "DE", "EL", "ES", "FR", "GB", "IT", "NL", "PL", "PT"} {
+		g := testutil.NewGenerator(1)
+		codes := testutil.TaxIDCodes(l10nCode(c))
+		require.NotEmpty(t, codes, c)
+		for range codes {
+			tID := g.TaxID(l10nCode(c))
+			assert.NoError(t, tID.Validate(), "%s: %s", c, tID.Code)
+		}
+	}
+}
+
+func TestParty(t *testing.T) {
+	g := testutil.NewGenerator(1, testutil.WithRegime("EL"))
+	for i := 0; i < 20; i++ {
+		p := g.Party()
+		assert.NotEmpty(t, p.Name)
+		assert.Equal(t, "EL", p.TaxID.Country.String())
+		assert.Equal(t, "GR", p.Addresses[0].Country.String())
+		assert.NoError(t, p.Validate())
+	}
+}
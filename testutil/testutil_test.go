@@ -0,0 +1,30 @@
+package testutil_test
+
+import (
+	"testing"
+
+	_ "github.com/invopop/gobl"
+	"github.com/invopop/gobl/cal"
+	"github.com/invopop/gobl/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGenerator(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		g := testutil.NewGenerator(1)
+		require.NotNil(t, g.Regime())
+		assert.Equal(t, "ES", g.Regime().Country.String())
+	})
+	t.Run("deterministic", func(t *testing.T) {
+		date := cal.MakeDate(2025, 3, 1)
+		a := testutil.NewGenerator(42, testutil.WithDate(date))
+		b := testutil.NewGenerator(42, testutil.WithDate(date))
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, a.Party(), b.Party())
+			assert.Equal(t, a.Line(), b.Line())
+		}
+		c := testutil.NewGenerator(43, testutil.WithDate(date))
+		assert.NotEqual(t, a.Party(), c.Party())
+	})
+}
@@ -0,0 +1,103 @@
+package uuid
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Standard namespaces defined in RFC 9562 for name-based UUIDs.
+var (
+	NamespaceDNS  = UUID(uuid.NameSpaceDNS.String())
+	NamespaceURL  = UUID(uuid.NameSpaceURL.String())
+	NamespaceOID  = UUID(uuid.NameSpaceOID.String())
+	NamespaceX500 = UUID(uuid.NameSpaceX500.String())
+)
+
+// V8 generates a new name-based UUIDv8 using the provided namespace and data
+// hashed with SHA-256, as described in the RFC 9562 examples. Like V5, the
+// same inputs will always generate the same UUID, but with a stronger hash
+// that is better suited to deriving identifiers from sensitive data.
+func V8(space UUID, data []byte) UUID {
+	h := sha256.New()
+	ns := parse(space)
+	h.Write(ns[:])
+	h.Write(data)
+	var id uuid.UUID
+	copy(id[:], h.Sum(nil))
+	id[6] = (id[6] & 0x0f) | 0x80 // version 8
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 9562 variant
+	return UUID(id.String())
+}
+
+// DeriveV5 generates a UUIDv5 from a list of parts, such as the supplier's
+// tax ID, series, and code of an invoice, so that systems that share the
+// namespace will always produce the same UUID for the same document and
+// can use it to detect duplicates. Parts are length prefixed before hashing
+// to ensure that different combinations, like "AB"+"C" and "A"+"BC",
+// cannot produce the same result.
+func DeriveV5(space UUID, parts ...string) UUID {
+	return V5(space, deriveData(parts))
+}
+
+// DeriveV8 generates a UUIDv8 from a list of parts using SHA-256. See
+// DeriveV5 for details.
+func DeriveV8(space UUID, parts ...string) UUID {
+	return V8(space, deriveData(parts))
+}
+
+func deriveData(parts []string) []byte {
+	n := 0
+	for _, p := range parts {
+		n += binary.MaxVarintLen64 + len(p)
+	}
+	data := make([]byte, 0, n)
+	for _, p := range parts {
+		data = binary.AppendUvarint(data, uint64(len(p)))
+		data = append(data, p...)
+	}
+	return data
+}
+
+// UnixMilli provides the number of milliseconds since the Unix epoch
+// contained in a UUIDv7, or zero for any other version. Unlike Timestamp,
+// the value is read directly from the first 48 bits of the UUID so can be
+// compared without any conversions.
+func (u UUID) UnixMilli() int64 {
+	id := parse(u)
+	if id.Version() != 7 {
+		return 0
+	}
+	var ms int64
+	for _, b := range id[:6] {
+		ms = ms<<8 | int64(b)
+	}
+	return ms
+}
+
+// V7Range provides the lowest and highest possible UUIDv7 values for the
+// millisecond of the provided time. As UUIDv7s sort by their timestamp,
+// these can be used to find documents generated within a period with
+// simple string comparisons.
+func V7Range(from, to time.Time) (UUID, UUID) {
+	return v7At(from, 0x00), v7At(to, 0xff)
+}
+
+func v7At(t time.Time, fill byte) UUID {
+	var id uuid.UUID
+	ms := uint64(t.UnixMilli())
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+	for i := 6; i < Size; i++ {
+		id[i] = fill
+	}
+	id[6] = (id[6] & 0x0f) | 0x70 // version 7
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 9562 variant
+	return UUID(id.String())
+}
@@ -0,0 +1,65 @@
+package uuid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/invopop/gobl/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV8(t *testing.T) {
+	// Example from RFC 9562, Appendix B.2
+	u := uuid.V8(uuid.NamespaceDNS, []byte("www.example.com"))
+	assert.Equal(t, "5c146b14-3c52-8afd-938a-375d0df1fbf6", u.String())
+	assert.Equal(t, uuid.Version(8), u.Version())
+	assert.NoError(t, u.Validate())
+	assert.True(t, u.Timestamp().IsZero())
+}
+
+func TestDerive(t *testing.T) {
+	ns := uuid.MustParse("0654a3f4-8ad5-44c8-828e-c25f7ccd6550")
+
+	u5 := uuid.DeriveV5(ns, "ESB12345678", "SAMPLE", "001")
+	assert.Equal(t, uuid.Version(5), u5.Version())
+	assert.Equal(t, u5, uuid.DeriveV5(ns, "ESB12345678", "SAMPLE", "001"))
+	assert.NotEqual(t, u5, uuid.DeriveV5(ns, "ESB12345678", "SAMPLE", "002"))
+	assert.NotEqual(t, u5, uuid.DeriveV5(ns, "ESB12345678", "SAMPLE0", "01"))
+	assert.NotEqual(t, u5, uuid.DeriveV5(uuid.NamespaceURL, "ESB12345678", "SAMPLE", "001"))
+
+	u8 := uuid.DeriveV8(ns, "ESB12345678", "SAMPLE", "001")
+	assert.Equal(t, uuid.Version(8), u8.Version())
+	assert.Equal(t, u8, uuid.DeriveV8(ns, "ESB12345678", "SAMPLE", "001"))
+	assert.NotEqual(t, u8, uuid.DeriveV8(ns, "ESB12345678SAMPLE", "001"))
+}
+
+func TestUUIDUnixMilli(t *testing.T) {
+	u := uuid.MustParse("01929d4b-3b8f-7a4c-9d2e-3f4a5b6c7d8e")
+	assert.Equal(t, int64(0x01929d4b3b8f), u.UnixMilli())
+	assert.Equal(t, u.Timestamp().UnixMilli(), u.UnixMilli())
+
+	u = uuid.V7()
+	assert.InDelta(t, time.Now().UnixMilli(), u.UnixMilli(), 10000)
+
+	assert.Zero(t, uuid.V4().UnixMilli())
+	assert.Zero(t, uuid.V1().UnixMilli())
+}
+
+func TestV7Range(t *testing.T) {
+	from := time.Now().Add(-time.Minute)
+	to := time.Now().Add(time.Minute)
+	lo, hi := uuid.V7Range(from, to)
+	assert.Equal(t, uuid.Version(7), lo.Version())
+	assert.Equal(t, uuid.Version(7), hi.Version())
+	assert.Equal(t, from.UnixMilli(), lo.UnixMilli())
+	assert.Equal(t, to.UnixMilli(), hi.UnixMilli())
+
+	u := uuid.V7()
+	assert.Less(t, lo.String(), u.String())
+	assert.Greater(t, hi.String(), u.String())
+
+	lo, hi = uuid.V7Range(to, to)
+	assert.Less(t, lo.String(), hi.String())
+	assert.NoError(t, lo.Validate())
+	assert.NoError(t, hi.Validate())
+}
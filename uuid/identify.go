@@ -50,3 +50,8 @@ func IdentifyV6() Identify {
 func IdentifyV7() Identify {
 	return Identify{UUID: V7()}
 }
+
+// IdentifyV8 is a helper method to generate a version 8 uuid ready to embed.
+func IdentifyV8(ns UUID, data []byte) Identify {
+	return Identify{UUID: V8(ns, data)}
+}
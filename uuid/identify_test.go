@@ -104,3 +104,14 @@ func TestIdentifyV7(t *testing.T) {
 	assert.NotEmpty(t, doc.GetUUID())
 	assert.False(t, doc.UUID.Timestamp().IsZero())
 }
+
+func TestIdentifyV8(t *testing.T) {
+	doc := struct {
+		uuid.Identify
+		Name string
+	}{
+		Identify: uuid.IdentifyV8(uuid.NamespaceDNS, []byte("www.example.com")),
+		Name:     "test",
+	}
+	assert.Equal(t, doc.GetUUID(), uuid.UUID("5c146b14-3c52-8afd-938a-375d0df1fbf6"))
+}
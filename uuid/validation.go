@@ -23,6 +23,8 @@ var (
 	IsV6 = versionRule{version: 6}
 	// IsV7 confirms the UUID is version 7
 	IsV7 = versionRule{version: 7}
+	// IsV8 confirms the UUID is version 8
+	IsV8 = versionRule{version: 8}
 	// HasTimestamp confirms the UUID is based on a timestamp version
 	HasTimestamp = versionRule{hasTimestamp: true}
 	// Timeless confirms the UUID is not based on a timestamp version
@@ -97,7 +99,7 @@ func (r versionRule) Validate(value interface{}) error {
 	}
 	if r.timeless {
 		switch id.Version() {
-		case 3, 4, 5:
+		case 3, 4, 5, 8:
 			// good
 		default:
 			return errors.New("has timestamp")
@@ -128,6 +128,16 @@ func TestUUIDValidation(t *testing.T) {
 			uuid: uuid.V4(),
 			rule: uuid.Timeless,
 		},
+		{
+			name: "timeless v8",
+			uuid: uuid.DeriveV8(uuid.NamespaceURL, "https://example.com"),
+			rule: uuid.Timeless,
+		},
+		{
+			name: "v8",
+			uuid: uuid.V8(uuid.NamespaceDNS, []byte("example.com")),
+			rule: uuid.IsV8,
+		},
 		{
 			name: "not timeless",
 			uuid: uuid.V7(),
@@ -0,0 +1,174 @@
+package gobl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/tax"
+)
+
+// Statuses used in verification reports.
+const (
+	// VerifyStatusValid indicates the check passed.
+	VerifyStatusValid cbc.Key = "valid"
+	// VerifyStatusInvalid indicates the check failed.
+	VerifyStatusInvalid cbc.Key = "invalid"
+	// VerifyStatusUnverified is used for signatures whose contents match the
+	// header, but that could not be checked against any of the keys, and
+	// for policies whose signers could not be verified.
+	VerifyStatusUnverified cbc.Key = "unverified"
+	// VerifyStatusSkipped is used for checks that could not be performed,
+	// like the digest of an encrypted document.
+	VerifyStatusSkipped cbc.Key = "skipped"
+)
+
+// VerifyReport provides a breakdown of the checks performed to verify an
+// envelope, ready to be presented to users.
+type VerifyReport struct {
+	// True when none of the checks are invalid.
+	Valid bool `json:"valid"`
+	// Validation of the envelope and document structure.
+	Schema *VerifyCheck `json:"schema"`
+	// Comparison of the header digest with the document.
+	Digest *VerifyCheck `json:"digest"`
+	// Comparison of attachment digests with the header, when present.
+	Attachments *VerifyCheck `json:"attachments,omitempty"`
+	// Signature policy check, when defined in the header.
+	Policy *VerifyCheck `json:"policy,omitempty"`
+	// Results for each of the envelope's signatures.
+	Signatures []*SignatureCheck `json:"signatures,omitempty"`
+	// Results of each addon's validation rules applied to the document.
+	Addons []*AddonCheck `json:"addons,omitempty"`
+}
+
+// VerifyCheck describes the outcome of a single check.
+type VerifyCheck struct {
+	// Status of the check.
+	Status cbc.Key `json:"status"`
+	// Error message when the check is invalid.
+	Error string `json:"error,omitempty"`
+}
+
+// SignatureCheck describes the outcome of verifying a single signature.
+type SignatureCheck struct {
+	VerifyCheck
+	// Position of the signature in the envelope.
+	Index int `json:"index"`
+	// ID of the key used to sign.
+	KeyID string `json:"kid,omitempty"`
+	// URL of the key set used to sign, if provided.
+	JKU string `json:"jku,omitempty"`
+}
+
+// AddonCheck describes the outcome of an addon's validation rules.
+type AddonCheck struct {
+	VerifyCheck
+	// Key of the addon.
+	Key cbc.Key `json:"key"`
+}
+
+// VerifyReport performs all the checks used to validate and verify the
+// envelope and provides a report with the outcome of each, rather than a
+// single error. Signatures are checked against the keys provided, or just
+// compared with the header if none are given, in which case the signature
+// policy will not be reported as valid either. Addon rules are applied to
+// the document directly, so nested objects will only be reflected in the
+// schema check.
+func (e *Envelope) VerifyReport(keys ...*dsig.PublicKey) *VerifyReport {
+	r := new(VerifyReport)
+	r.Schema = newVerifyCheck(e.validateStructure(context.Background()))
+	if e.Head == nil {
+		r.Digest = &VerifyCheck{Status: VerifyStatusSkipped}
+		r.Valid = r.valid()
+		return r
+	}
+	if e.Encryption != nil || e.Document == nil {
+		r.Digest = &VerifyCheck{Status: VerifyStatusSkipped}
+	} else {
+		r.Digest = newVerifyCheck(e.verifyDigest())
+	}
+	if len(e.Attachments) > 0 || len(e.Head.Attachments) > 0 {
+		r.Attachments = newVerifyCheck(e.verifyAttachments())
+	}
+	if e.Head.Policy != nil {
+		if len(keys) == 0 {
+			// signers cannot be confirmed from the headers alone
+			r.Policy = newVerifyCheck(e.checkPolicyKeyIDs())
+			if r.Policy.Status == VerifyStatusValid {
+				r.Policy.Status = VerifyStatusUnverified
+			}
+		} else {
+			r.Policy = newVerifyCheck(e.CheckPolicy(keys...))
+		}
+	}
+	for i, sig := range e.Signatures {
+		sc := &SignatureCheck{
+			Index: i,
+			KeyID: sig.KeyID(),
+			JKU:   sig.JKU(),
+		}
+		err := e.verifySignature(sig, keys...)
+		sc.VerifyCheck = *newVerifyCheck(err)
+		if errors.Is(err, errDetachedKeysRequired) {
+			// nothing to compare, but not invalid either
+			sc.Status = VerifyStatusUnverified
+		} else if len(keys) == 0 && sc.Status == VerifyStatusValid {
+			sc.Status = VerifyStatusUnverified
+		}
+		r.Signatures = append(r.Signatures, sc)
+	}
+	r.Addons = e.addonChecks()
+	r.Valid = r.valid()
+	return r
+}
+
+func (e *Envelope) addonChecks() []*AddonCheck {
+	if e.Document == nil || e.Document.IsEmpty() {
+		return nil
+	}
+	doc := e.Document.Instance()
+	ad, ok := doc.(interface{ GetAddons() []cbc.Key })
+	if !ok {
+		return nil
+	}
+	var list []*AddonCheck
+	for _, k := range ad.GetAddons() {
+		ac := &AddonCheck{Key: k}
+		def := tax.AddonForKey(k)
+		switch {
+		case def == nil:
+			ac.VerifyCheck = VerifyCheck{Status: VerifyStatusInvalid, Error: "addon not registered"}
+		case def.Validator == nil:
+			ac.VerifyCheck = VerifyCheck{Status: VerifyStatusValid}
+		default:
+			ac.VerifyCheck = *newVerifyCheck(def.Validator(doc))
+		}
+		list = append(list, ac)
+	}
+	return list
+}
+
+func (r *VerifyReport) valid() bool {
+	checks := []*VerifyCheck{r.Schema, r.Digest, r.Attachments, r.Policy}
+	for _, s := range r.Signatures {
+		checks = append(checks, &s.VerifyCheck)
+	}
+	for _, a := range r.Addons {
+		checks = append(checks, &a.VerifyCheck)
+	}
+	for _, c := range checks {
+		if c != nil && c.Status == VerifyStatusInvalid {
+			return false
+		}
+	}
+	return true
+}
+
+func newVerifyCheck(err error) *VerifyCheck {
+	if err != nil {
+		return &VerifyCheck{Status: VerifyStatusInvalid, Error: err.Error()}
+	}
+	return &VerifyCheck{Status: VerifyStatusValid}
+}
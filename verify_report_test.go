@@ -0,0 +1,81 @@
+package gobl_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/invopop/gobl"
+	"github.com/invopop/gobl/bill"
+	"github.com/invopop/gobl/cbc"
+	"github.com/invopop/gobl/dsig"
+	"github.com/invopop/gobl/head"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeVerifyReport(t *testing.T) {
+	t.Run("valid signed", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(testKey))
+		r := env.VerifyReport(testKey.Public())
+		assert.True(t, r.Valid)
+		assert.Equal(t, gobl.VerifyStatusValid, r.Schema.Status)
+		assert.Equal(t, gobl.VerifyStatusValid, r.Digest.Status)
+		assert.Nil(t, r.Attachments)
+		assert.Nil(t, r.Policy)
+		require.Len(t, r.Signatures, 1)
+		assert.Equal(t, gobl.VerifyStatusValid, r.Signatures[0].Status)
+		assert.Equal(t, testKey.ID(), r.Signatures[0].KeyID)
+	})
+	t.Run("without keys", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Sign(testKey))
+		r := env.VerifyReport()
+		assert.True(t, r.Valid)
+		assert.Equal(t, gobl.VerifyStatusUnverified, r.Signatures[0].Status)
+	})
+	t.Run("wrong key and modified", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		require.NoError(t, env.Attach("pdf", "invoice.pdf", "application/pdf", []byte("%PDF")))
+		require.NoError(t, env.Sign(testKey))
+		env.Attachment("pdf").Data = []byte("%PDX")
+		inv := env.Extract().(*bill.Invoice)
+		inv.Code = "CHANGED"
+		r := env.VerifyReport(dsig.NewES256Key().Public())
+		assert.False(t, r.Valid)
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Digest.Status)
+		assert.Contains(t, r.Digest.Error, "digest")
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Attachments.Status)
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Signatures[0].Status)
+		assert.Equal(t, "no key match found", r.Signatures[0].Error)
+	})
+	t.Run("policy", func(t *testing.T) {
+		env := testAttachmentEnvelope(t)
+		env.Head.Policy = &head.SignaturePolicy{
+			Min:     1,
+			Signers: []*head.PolicySigner{{KeyID: "other"}},
+		}
+		require.NoError(t, env.Sign(testKey))
+		r := env.VerifyReport(testKey.Public())
+		assert.False(t, r.Valid)
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Policy.Status)
+	})
+	t.Run("addons", func(t *testing.T) {
+		data, err := os.ReadFile("./examples/es/out/invoice-es-es-verifactu.json")
+		require.NoError(t, err)
+		env := new(gobl.Envelope)
+		require.NoError(t, json.Unmarshal(data, env))
+		r := env.VerifyReport()
+		assert.True(t, r.Valid)
+		require.NotEmpty(t, r.Addons)
+		assert.Equal(t, cbc.Key("es-verifactu-v1"), r.Addons[0].Key)
+		assert.Equal(t, gobl.VerifyStatusValid, r.Addons[0].Status)
+
+		inv := env.Extract().(*bill.Invoice)
+		inv.Tax = nil
+		r = env.VerifyReport()
+		assert.False(t, r.Valid)
+		assert.Equal(t, gobl.VerifyStatusInvalid, r.Addons[0].Status)
+	})
+}